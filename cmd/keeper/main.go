@@ -1,14 +1,18 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"gophkeeper/internal/keeper"
 	"gophkeeper/internal/keeper/api"
 	"gophkeeper/internal/keeper/api/grpc"
+	"gophkeeper/internal/keeper/cli"
 	"gophkeeper/internal/keeper/config"
 	"gophkeeper/internal/keeper/tui/app"
 	"gophkeeper/internal/keeper/tui/top"
 	"gophkeeper/internal/keeper/utils"
 	"log"
+	"os"
 
 	"go.uber.org/dig"
 )
@@ -20,11 +24,24 @@ var (
 )
 
 func main() {
-	cfg := config.New()
+	cfg, err := config.New()
+	if err != nil {
+		log.Fatal("failed to load config: ", err)
+	}
 	cfg.BuildDate = buildDate
 	cfg.BuildVersion = buildVersion
 
-	err := runApp(cfg)
+	// Non-interactive command, e.g. "keeper search -vault secret.db mail"
+	if len(os.Args) > 1 {
+		env := cli.Env{Config: cfg, Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+		if err := cli.Run(context.Background(), env, os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	err = runApp(cfg)
 	if err != nil {
 		log.Fatal("failed to start app: ", err)
 	}
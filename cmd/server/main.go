@@ -77,6 +77,10 @@ func buildDepContainer(cfg *config.Config) *dig.Container {
 	_ = container.Provide(service.NewHealthService, dig.As(new(service.HealthManager)))
 	_ = container.Provide(service.NewSecretsService, dig.As(new(service.SecretsManager)))
 	_ = container.Provide(service.NewUsersService, dig.As(new(service.UsersManager)))
+	_ = container.Provide(service.NewSessionsService, dig.As(new(service.SessionsManager)))
+	_ = container.Provide(service.NewTwoFactorService, dig.As(new(service.TwoFactorManager)))
+	_ = container.Provide(service.NewLoginAttemptsService, dig.As(new(service.LoginAttemptsManager)))
+	_ = container.Provide(service.NewTrashPurger)
 
 	return container
 }
@@ -92,6 +96,10 @@ func addAppSpecificDependencies(container *dig.Container, cfg *config.Config) *d
 		// Postgres repos
 		_ = container.Provide(pgRepo.NewUsersRepository, dig.As(new(repository.UsersRepository)))
 		_ = container.Provide(pgRepo.NewSecretsRepository, dig.As(new(repository.SecretsRepository)))
+		_ = container.Provide(pgRepo.NewSessionsRepository, dig.As(new(repository.SessionsRepository)))
+		_ = container.Provide(pgRepo.NewTwoFactorRepository, dig.As(new(repository.TwoFactorRepository)))
+		_ = container.Provide(pgRepo.NewLoginAttemptsRepository, dig.As(new(repository.LoginAttemptsRepository)))
+		_ = container.Provide(pgRepo.NewSRPHandshakesRepository, dig.As(new(repository.SRPHandshakesRepository)))
 	}
 
 	return container
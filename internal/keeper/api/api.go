@@ -3,6 +3,7 @@ package api
 
 import (
 	"context"
+	"gophkeeper/internal/keeper/crypto"
 	"gophkeeper/pkg/models"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -12,17 +13,45 @@ import (
 type IApiClient interface {
 	Register(ctx context.Context, login string, password string) (string, error)
 	Login(ctx context.Context, login string, password string) (string, error)
+	// Second step of login that failed with ErrTOTPRequired, code is TOTP or recovery code
+	LoginTOTP(ctx context.Context, code string) (string, error)
+
+	// TOTP second factor of account
+	TwoFactor(ctx context.Context) (models.TwoFactorStatus, error)
+	EnrollTOTP(ctx context.Context) (models.TOTPEnrollment, error)
+	ConfirmTOTP(ctx context.Context, code string) ([]string, error)
+	DisableTOTP(ctx context.Context, code string) error
+
+	// Login sessions of account on server, one per device
+	ListSessions(ctx context.Context) ([]models.Session, error)
+	RevokeSession(ctx context.Context, ID uint64) error
+	Logout(ctx context.Context) error
 
 	LoadSecrets(ctx context.Context) ([]*models.Secret, error)
 	LoadSecret(ctx context.Context, ID uint64) (*models.Secret, error)
 	SaveSecret(ctx context.Context, secret *models.Secret) error
 	DeleteSecret(ctx context.Context, ID uint64) error
+	SyncSecrets(ctx context.Context, cursor string) (*models.SecretChanges, error)
+	LoadRevisions(ctx context.Context, ID uint64) ([]*models.Secret, error)
+	LoadRevision(ctx context.Context, ID uint64, revision uint64) (*models.Secret, error)
+	LoadTrash(ctx context.Context) ([]*models.Secret, error)
+	RestoreSecret(ctx context.Context, ID uint64) error
+	PurgeSecret(ctx context.Context, ID uint64) error
 
 	SetToken(token string)
 	GetToken() string
+	GetLogin() string
 
 	SetPassword(password string)
 	GetPassword() string
 
+	// Key encrypting payloads: derived from password at login, password itself for legacy accounts
+	SetEncryptionKey(key string)
+	GetEncryptionKey() string
+
+	// KDF params of logged in account, empty for legacy accounts
+	GetKDF() models.AccountKDF
+	UpgradeAuth(ctx context.Context, kdf models.AccountKDF, keys crypto.AccountKeys, secrets []models.SecretPayload) error
+
 	Notifications(p *tea.Program)
 }
@@ -1,23 +1,31 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"gophkeeper/cert"
 	"gophkeeper/internal/keeper/api"
 	"gophkeeper/internal/keeper/api/grpc/interceptor"
 	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/crypto"
 	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/pkg/convert"
 	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/srp"
 	"log"
 	"math"
 	"math/rand/v2"
+	"os"
+	"runtime"
 	"sync"
 	"time"
 
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials"
@@ -39,9 +47,27 @@ type GRPCClient struct {
 	secretsClient pb.SecretsClient
 	notifyClient  pb.NotificationClient
 	accessToken   string
-	password      string // passw to encrypt payload
+	login         string // login of last successful authentication
+	password      string // master password, to log in again
+	encryptionKey string // key to encrypt payload, never sent to server
+	account       storage.AccountParams
 	clientID      uint64 // Unique ID to distinguish between multiple running clients for same user
 	previews      sync.Map
+
+	refreshToken string     // exchanged for new access token once it expires
+	refreshMu    sync.Mutex // one refresh at a time, reuse of refresh token ends session
+
+	pending *pendingLogin // login waiting for one-time code
+}
+
+// Login that passed password check and waits for one-time code, account is set once code is accepted
+type pendingLogin struct {
+	challenge string
+	login     string
+	password  string
+	key       string
+	account   storage.AccountParams
+	authKey   []byte // account is switched to SRP after login when set
 }
 
 var _ api.IApiClient = &GRPCClient{}
@@ -49,24 +75,26 @@ var _ api.IApiClient = &GRPCClient{}
 func NewGRPCClient(cfg *config.Config) (*GRPCClient, error) {
 	var opts []grpc.DialOption
 
-	newClient := GRPCClient{
+	newClient := &GRPCClient{
 		config:   cfg,
 		clientID: uint64(rand.IntN(math.MaxInt32)),
 	}
+	device := deviceName()
 
 	// Unary interceptors
 	opts = append(
 		opts,
 		grpc.WithChainUnaryInterceptor(
 			interceptor.Timeout(DefaultClientTimeout),
-			interceptor.AddAuth(&newClient.accessToken, uint32(newClient.clientID)),
+			interceptor.RefreshToken(&newClient.accessToken, newClient.refresh),
+			interceptor.AddAuth(&newClient.accessToken, uint32(newClient.clientID), device),
 		),
 	)
 
 	// Stream interceptor
 	opts = append(
 		opts,
-		grpc.WithStreamInterceptor(interceptor.AddAuthStream(&newClient.accessToken, newClient.clientID)),
+		grpc.WithStreamInterceptor(interceptor.AddAuthStream(&newClient.accessToken, newClient.clientID, device)),
 	)
 
 	// TLS
@@ -92,29 +120,160 @@ func NewGRPCClient(cfg *config.Config) (*GRPCClient, error) {
 	newClient.secretsClient = pb.NewSecretsClient(c)
 	newClient.notifyClient = pb.NewNotificationClient(c)
 
-	return &newClient, nil
+	return newClient, nil
 }
 
+// Whether params differ in salt from saved ones or cost less in any of time, memory or threads
+func weakerKDF(params models.AccountKDF, saved models.AccountKDF) bool {
+	return !bytes.Equal(params.Salt, saved.Salt) ||
+		params.Time < saved.Time || params.Memory < saved.Memory || params.Threads < saved.Threads
+}
+
+// Log in with auth key derived from password with KDF params fetched from server. SRP accounts prove
+// knowledge of auth key without sending it, other accounts send it and are switched to SRP right away.
+// Password of legacy account is sent as is. Server asking for less than account is known to use is refused.
+// Accounts with second factor fail with ErrTOTPRequired, login is finished by LoginTOTP.
 func (c *GRPCClient) Login(ctx context.Context, login string, password string) (string, error) {
-	req := &pb.LoginRequestV1{
-		Login:    login,
-		Password: password,
+	c.pending = nil
+
+	account, err := c.getAccount(ctx, login)
+	if err != nil {
+		return "", err
+	}
+
+	saved, savedErr := storage.LoadAccountParams(c.kdfPath(login))
+
+	if account.Legacy() {
+		if savedErr == nil {
+			return "", entities.ErrAuthDowngrade
+		}
+
+		response, err := c.usersClient.LoginV1(ctx, &pb.LoginRequestV1{Login: login, Password: password})
+		if err != nil {
+			return "", parseError(err)
+		}
+
+		if response.TotpChallenge != "" {
+			return "", c.awaitCode(pendingLogin{challenge: response.TotpChallenge, login: login, password: password, key: password, account: account})
+		}
+
+		c.setAccount(response.AccessToken, response.RefreshToken, login, password, password, account)
+
+		return response.AccessToken, nil
+	}
+
+	if saved.SRP && !account.SRP {
+		return "", entities.ErrAuthDowngrade
+	}
+
+	if savedErr == nil && weakerKDF(account.AccountKDF, saved.AccountKDF) {
+		return "", entities.ErrAuthDowngrade
+	}
+
+	keys, err := crypto.DeriveAccountKeys(password, account.AccountKDF)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive account keys: %w", err)
+	}
+
+	if account.SRP {
+		finish, err := c.loginSRP(ctx, login, keys.Auth)
+		if err != nil {
+			return "", err
+		}
+
+		if finish.TotpChallenge != "" {
+			return "", c.awaitCode(pendingLogin{challenge: finish.TotpChallenge, login: login, password: password, key: keys.Encryption, account: account})
+		}
+
+		c.setAccount(finish.AccessToken, finish.RefreshToken, login, password, keys.Encryption, account)
+
+		return finish.AccessToken, nil
 	}
 
-	response, err := c.usersClient.LoginV1(ctx, req)
+	response, err := c.usersClient.LoginV1(ctx, &pb.LoginRequestV1{Login: login, AuthKey: keys.Auth})
 	if err != nil {
 		return "", parseError(err)
 	}
 
-	c.accessToken = response.AccessToken
+	if response.TotpChallenge != "" {
+		return "", c.awaitCode(pendingLogin{
+			challenge: response.TotpChallenge, login: login, password: password, key: keys.Encryption, account: account, authKey: keys.Auth,
+		})
+	}
+
+	c.setAccount(response.AccessToken, response.RefreshToken, login, password, keys.Encryption, account)
+	c.enrollSRP(ctx, keys.Auth)
 
 	return response.AccessToken, nil
 }
 
+// Finish login waiting for one-time code. Wrong code may be retried, ErrLoginExpired means login has to start over.
+func (c *GRPCClient) LoginTOTP(ctx context.Context, code string) (string, error) {
+	p := c.pending
+	if p == nil {
+		return "", entities.ErrLoginExpired
+	}
+
+	response, err := c.usersClient.LoginTOTPV1(ctx, &pb.LoginTOTPRequestV1{Challenge: p.challenge, Code: code})
+
+	switch status.Code(err) {
+	case codes.OK:
+	case codes.Unauthenticated:
+		return "", entities.ErrBadTOTPCode
+	case codes.FailedPrecondition:
+		c.pending = nil
+		return "", entities.ErrLoginExpired
+	default:
+		return "", parseError(err)
+	}
+
+	c.pending = nil
+	c.setAccount(response.AccessToken, response.RefreshToken, p.login, p.password, p.key, p.account)
+	if p.authKey != nil {
+		c.enrollSRP(ctx, p.authKey)
+	}
+
+	return response.AccessToken, nil
+}
+
+// Keep login for LoginTOTP
+func (c *GRPCClient) awaitCode(p pendingLogin) error {
+	c.pending = &p
+
+	return entities.ErrTOTPRequired
+}
+
+// Register account with auth key derived from password with fresh salt and KDF params of config.
+// Servers supporting SRP get only verifier of auth key.
 func (c *GRPCClient) Register(ctx context.Context, login string, password string) (string, error) {
+	server, err := c.getAccount(ctx, login)
+	if err != nil {
+		return "", err
+	}
+
+	kdf, err := crypto.NewAccountKDF(c.config.KDF)
+	if err != nil {
+		return "", fmt.Errorf("failed to prepare account KDF: %w", err)
+	}
+
+	keys, err := crypto.DeriveAccountKeys(password, kdf)
+	if err != nil {
+		return "", fmt.Errorf("failed to derive account keys: %w", err)
+	}
+
 	req := &pb.RegisterRequestV1{
-		Login:    login,
-		Password: password,
+		Login: login,
+		Kdf:   convert.KDFToProto(kdf),
+	}
+
+	if server.SRP {
+		salt, verifier, err := srp.NewVerifier(login, keys.Auth)
+		if err != nil {
+			return "", fmt.Errorf("failed to prepare SRP verifier: %w", err)
+		}
+		req.Srp = convert.SRPToProto(models.SRPVerifier{Salt: salt, Verifier: verifier})
+	} else {
+		req.AuthKey = keys.Auth
 	}
 
 	response, err := c.usersClient.RegisterV1(ctx, req)
@@ -122,11 +281,238 @@ func (c *GRPCClient) Register(ctx context.Context, login string, password string
 		return "", parseError(err)
 	}
 
-	c.accessToken = response.AccessToken
+	c.setAccount(response.AccessToken, response.RefreshToken, login, password, keys.Encryption, storage.AccountParams{AccountKDF: kdf, SRP: server.SRP})
 
 	return response.AccessToken, nil
 }
 
+// Switch legacy account of logged in user to auth key, secrets are re-encrypted with keys.Encryption.
+// Server checks legacy password again and ends sessions on other devices.
+func (c *GRPCClient) UpgradeAuth(ctx context.Context, kdf models.AccountKDF, keys crypto.AccountKeys, secrets []models.SecretPayload) error {
+	req := &pb.UpgradeAuthRequestV1{
+		AuthKey:  keys.Auth,
+		Kdf:      convert.KDFToProto(kdf),
+		Secrets:  convert.PayloadsToProto(secrets),
+		Password: c.password,
+	}
+
+	response, err := c.usersClient.UpgradeAuthV1(ctx, req)
+	if status.Code(err) == codes.Aborted {
+		return fmt.Errorf("%w: %s", entities.ErrConflict, status.Convert(err).Message())
+	}
+	if err != nil {
+		return parseError(err)
+	}
+
+	c.setAccount(response.AccessToken, c.refreshToken, c.login, c.password, keys.Encryption, storage.AccountParams{AccountKDF: kdf})
+	c.enrollSRP(ctx, keys.Auth)
+
+	return nil
+}
+
+// SRP-6a login: server proof is checked before tokens are accepted, so server not knowing verifier is detected
+func (c *GRPCClient) loginSRP(ctx context.Context, login string, authKey []byte) (*pb.LoginSRPFinishResponseV1, error) {
+	client, err := srp.NewClient(login, authKey)
+	if err != nil {
+		return nil, err
+	}
+
+	start, err := c.usersClient.LoginSRPStartV1(ctx, &pb.LoginSRPStartRequestV1{Login: login, A: client.A})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	proof, err := client.Proof(start.Salt, start.B)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", entities.ErrServerImpostor, err)
+	}
+
+	finish, err := c.usersClient.LoginSRPFinishV1(ctx, &pb.LoginSRPFinishRequestV1{SessionId: start.SessionId, M1: proof})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	if !client.Verify(finish.M2) {
+		return nil, entities.ErrServerImpostor
+	}
+
+	return finish, nil
+}
+
+// Replace hash of auth key on server with SRP verifier, so auth key is not sent again. Auth key goes along
+// as proof of credentials. Failure is not fatal, enrollment is retried at next login.
+func (c *GRPCClient) enrollSRP(ctx context.Context, authKey []byte) {
+	salt, verifier, err := srp.NewVerifier(c.login, authKey)
+	if err != nil {
+		log.Println("failed to prepare SRP verifier:", err)
+		return
+	}
+
+	req := &pb.EnrollSRPRequestV1{Srp: convert.SRPToProto(models.SRPVerifier{Salt: salt, Verifier: verifier}), AuthKey: authKey}
+	if _, err := c.usersClient.EnrollSRPV1(ctx, req); err != nil {
+		if err := parseError(err); !errors.Is(err, entities.ErrNotSupported) {
+			log.Println("failed to switch account to SRP:", err)
+		}
+		return
+	}
+
+	c.account.SRP = true
+	c.saveAccount()
+}
+
+// Params of account, servers without them only know legacy accounts
+func (c *GRPCClient) getAccount(ctx context.Context, login string) (storage.AccountParams, error) {
+	response, err := c.usersClient.GetKDFV1(ctx, &pb.GetKDFRequestV1{Login: login})
+	err = parseError(err)
+	if errors.Is(err, entities.ErrNotSupported) {
+		return storage.AccountParams{}, nil
+	}
+	if err != nil {
+		return storage.AccountParams{}, err
+	}
+
+	if response.Legacy {
+		return storage.AccountParams{}, nil
+	}
+
+	return storage.AccountParams{AccountKDF: convert.ProtoToKDF(response.Kdf), SRP: response.Srp}, nil
+}
+
+// Remember logged in account, its params are saved for offline use
+func (c *GRPCClient) setAccount(token string, refreshToken string, login string, password string, key string, account storage.AccountParams) {
+	c.refreshMu.Lock()
+	c.accessToken, c.refreshToken = token, refreshToken
+	c.refreshMu.Unlock()
+
+	c.login = login
+	c.password = password
+	c.encryptionKey = key
+	c.account = account
+
+	c.saveAccount()
+}
+
+func (c *GRPCClient) saveAccount() {
+	if c.account.Legacy() {
+		return
+	}
+
+	if err := storage.SaveAccountParams(c.kdfPath(c.login), c.account); err != nil {
+		log.Println("failed to save account KDF params:", err)
+	}
+}
+
+// Exchange refresh token for new tokens once access token stale expired. Calls failed with the same token
+// wait for one refresh and go on with its result. Ended session makes client forget credentials,
+// so it is not logged in again behind user's back.
+func (c *GRPCClient) refresh(ctx context.Context, stale string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+
+	if c.accessToken != stale {
+		return nil
+	}
+
+	// Servers without sessions issue no refresh token, caller logs in again
+	if c.refreshToken == "" {
+		return status.Error(codes.Unauthenticated, "no refresh token")
+	}
+
+	response, err := c.usersClient.RefreshV1(ctx, &pb.RefreshRequestV1{RefreshToken: c.refreshToken})
+	if status.Code(err) == codes.PermissionDenied {
+		c.forget()
+	}
+	if err != nil {
+		return err
+	}
+
+	c.accessToken, c.refreshToken = response.AccessToken, response.RefreshToken
+
+	return nil
+}
+
+// Active sessions of account, the current one marked
+func (c *GRPCClient) ListSessions(ctx context.Context) ([]models.Session, error) {
+	response, err := c.usersClient.ListSessionsV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return convert.ProtoToSessions(response.Sessions), nil
+}
+
+// End session of another device, it has to log in again
+func (c *GRPCClient) RevokeSession(ctx context.Context, id uint64) error {
+	_, err := c.usersClient.RevokeSessionV1(ctx, &pb.RevokeSessionRequestV1{Id: id})
+	if status.Code(err) == codes.NotFound {
+		return entities.ErrSessionNotFound
+	}
+
+	return parseError(err)
+}
+
+// End current session on server. Credentials are forgotten even if server could not be reached.
+func (c *GRPCClient) Logout(ctx context.Context) error {
+	_, err := c.usersClient.LogoutV1(ctx, &emptypb.Empty{})
+
+	c.refreshMu.Lock()
+	c.forget()
+	c.refreshMu.Unlock()
+
+	if status.Code(err) == codes.NotFound {
+		return nil // session ended already
+	}
+
+	return parseError(err)
+}
+
+// Whether account has second factor enabled
+func (c *GRPCClient) TwoFactor(ctx context.Context) (models.TwoFactorStatus, error) {
+	response, err := c.usersClient.GetTwoFactorV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return models.TwoFactorStatus{}, parseError(err)
+	}
+
+	return models.TwoFactorStatus{Enabled: response.Enabled, RecoveryCodes: int(response.RecoveryCodes)}, nil
+}
+
+// New TOTP secret to add to authenticator app, second factor is enabled by ConfirmTOTP
+func (c *GRPCClient) EnrollTOTP(ctx context.Context) (models.TOTPEnrollment, error) {
+	response, err := c.usersClient.EnrollTOTPV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return models.TOTPEnrollment{}, parseTwoFactorError(err)
+	}
+
+	return models.TOTPEnrollment{Secret: response.Secret, URI: response.Uri}, nil
+}
+
+// Enable second factor with code of authenticator app, returns recovery codes
+func (c *GRPCClient) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	response, err := c.usersClient.ConfirmTOTPV1(ctx, &pb.ConfirmTOTPRequestV1{Code: code})
+	if err != nil {
+		return nil, parseTwoFactorError(err)
+	}
+
+	return response.RecoveryCodes, nil
+}
+
+// Turn second factor off with TOTP code or recovery code
+func (c *GRPCClient) DisableTOTP(ctx context.Context, code string) error {
+	_, err := c.usersClient.DisableTOTPV1(ctx, &pb.DisableTOTPRequestV1{Code: code})
+
+	return parseTwoFactorError(err)
+}
+
+// Drop tokens and password, must be called with refreshMu held
+func (c *GRPCClient) forget() {
+	c.accessToken, c.refreshToken = "", ""
+	c.password = ""
+}
+
+func (c *GRPCClient) kdfPath(login string) string {
+	return storage.AccountKDFPath(c.config.ReplicaDir, string(c.config.ServerAddress), login)
+}
+
 func (c *GRPCClient) LoadSecrets(ctx context.Context) ([]*models.Secret, error) {
 	// form gRPC request
 	request := emptypb.Empty{}
@@ -166,6 +552,9 @@ func (c *GRPCClient) SaveSecret(ctx context.Context, secret *models.Secret) erro
 		Payload:    secret.Payload,
 		CreatedAt:  timestamppb.New(secret.CreatedAt),
 		UpdatedAt:  timestamppb.New(secret.UpdatedAt),
+		Revision:   secret.Revision,
+		Folder:     secret.Folder,
+		Tags:       secret.Tags,
 	}
 
 	if secret.ID > 0 {
@@ -173,9 +562,22 @@ func (c *GRPCClient) SaveSecret(ctx context.Context, secret *models.Secret) erro
 	}
 
 	request := &pb.SaveUserSecretRequestV1{Secret: sec}
-	_, err := c.secretsClient.SaveUserSecretV1(ctx, request)
+	if secret.ID == 0 {
+		request.CreateKey = secret.CreateKey
+	}
 
-	return parseError(err)
+	response, err := c.secretsClient.SaveUserSecretV1(ctx, request)
+	if err != nil {
+		return parseError(err)
+	}
+
+	// Server assigns ID and revision
+	if response.Secret != nil {
+		secret.ID = response.Secret.Id
+		secret.Revision = response.Secret.Revision
+	}
+
+	return nil
 }
 
 func (c *GRPCClient) DeleteSecret(ctx context.Context, id uint64) error {
@@ -185,6 +587,80 @@ func (c *GRPCClient) DeleteSecret(ctx context.Context, id uint64) error {
 	return parseError(err)
 }
 
+// Request one page of changes since cursor, empty cursor requests full listing.
+// Servers without delta sync yield entities.ErrNotSupported.
+func (c *GRPCClient) SyncSecrets(ctx context.Context, cursor string) (*models.SecretChanges, error) {
+	request := &pb.SyncRequestV1{Cursor: cursor}
+
+	response, err := c.secretsClient.SyncV1(ctx, request)
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return &models.SecretChanges{
+		Updated: convert.ProtoToSecrets(response.Secrets),
+		Deleted: response.DeletedIds,
+		Cursor:  response.Cursor,
+		HasMore: response.HasMore,
+		Reset:   response.FullResync,
+	}, nil
+}
+
+// List previous revisions of secret, newest first, without payloads.
+// Servers without history yield entities.ErrNotSupported.
+func (c *GRPCClient) LoadRevisions(ctx context.Context, id uint64) ([]*models.Secret, error) {
+	response, err := c.secretsClient.ListSecretRevisionsV1(ctx, &pb.ListSecretRevisionsRequestV1{Id: id})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return convert.ProtoToSecrets(response.Revisions), nil
+}
+
+// Load previous revision of secret with its payload
+func (c *GRPCClient) LoadRevision(ctx context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	response, err := c.secretsClient.GetSecretRevisionV1(ctx, &pb.GetSecretRevisionRequestV1{Id: id, Revision: revision})
+	if status.Code(err) == codes.NotFound {
+		return nil, entities.ErrRevisionNotFound
+	}
+
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return convert.ProtoToSecret(response.Secret), nil
+}
+
+// Load secrets in trash, most recently deleted first
+func (c *GRPCClient) LoadTrash(ctx context.Context) ([]*models.Secret, error) {
+	response, err := c.secretsClient.ListTrashV1(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, parseError(err)
+	}
+
+	return convert.ProtoToSecrets(response.Secrets), nil
+}
+
+// Take secret out of trash
+func (c *GRPCClient) RestoreSecret(ctx context.Context, id uint64) error {
+	_, err := c.secretsClient.RestoreSecretV1(ctx, &pb.RestoreSecretRequestV1{Id: id})
+	if status.Code(err) == codes.NotFound {
+		return entities.ErrNotInTrash
+	}
+
+	return parseError(err)
+}
+
+// Permanently delete secret in trash
+func (c *GRPCClient) PurgeSecret(ctx context.Context, id uint64) error {
+	_, err := c.secretsClient.PurgeSecretV1(ctx, &pb.PurgeSecretRequestV1{Id: id})
+	if status.Code(err) == codes.NotFound {
+		return entities.ErrNotInTrash
+	}
+
+	return parseError(err)
+}
+
 func (c *GRPCClient) SetToken(token string) {
 	c.accessToken = token
 }
@@ -193,6 +669,10 @@ func (c *GRPCClient) GetToken() string {
 	return c.accessToken
 }
 
+func (c *GRPCClient) GetLogin() string {
+	return c.login
+}
+
 func (c *GRPCClient) SetPassword(password string) {
 	c.password = password
 }
@@ -201,6 +681,18 @@ func (c *GRPCClient) GetPassword() string {
 	return c.password
 }
 
+func (c *GRPCClient) SetEncryptionKey(key string) {
+	c.encryptionKey = key
+}
+
+func (c *GRPCClient) GetEncryptionKey() string {
+	return c.encryptionKey
+}
+
+func (c *GRPCClient) GetKDF() models.AccountKDF {
+	return c.account.AccountKDF
+}
+
 func parseError(err error) error {
 	if err == nil {
 		return nil
@@ -217,13 +709,60 @@ func parseError(err error) error {
 		return entities.ErrServerUnavailable
 	case codes.Unauthenticated:
 		return entities.ErrUnauthenticated
+	case codes.PermissionDenied:
+		return entities.ErrSessionRevoked
 	case codes.AlreadyExists:
 		return entities.ErrAlreadyExist
+	case codes.Aborted:
+		return parseConflict(st)
+	case codes.ResourceExhausted:
+		return parseLocked(st, err)
+	case codes.Unimplemented:
+		return entities.ErrNotSupported
 	default:
 		return err
 	}
 }
 
+// Errors of second factor settings, the rest as parseError does
+func parseTwoFactorError(err error) error {
+	switch status.Code(err) {
+	case codes.InvalidArgument:
+		return entities.ErrBadTOTPCode
+	case codes.AlreadyExists:
+		return entities.ErrTwoFactorEnabled
+	case codes.FailedPrecondition:
+		return entities.ErrTwoFactorDisabled
+	default:
+		return parseError(err)
+	}
+}
+
+// Conflict error with server copy of secret from status details
+func parseConflict(st *status.Status) error {
+	conflict := &entities.ConflictError{}
+
+	for _, detail := range st.Details() {
+		if theirs, ok := detail.(*pb.Secret); ok {
+			conflict.Theirs = convert.ProtoToSecret(theirs)
+		}
+	}
+
+	return conflict
+}
+
+// Lockout of login with retry delay from status details, other exhausted resources as is
+func parseLocked(st *status.Status, err error) error {
+	for _, detail := range st.Details() {
+		if retry, ok := detail.(*errdetails.RetryInfo); ok {
+			after := retry.RetryDelay.AsDuration()
+			return &entities.LoginLockedError{RetryAfter: after, Until: time.Now().Add(after)}
+		}
+	}
+
+	return err
+}
+
 func loadTLSConfig(caCertFile, clientCertFile, clientKeyFile string) (credentials.TransportCredentials, error) {
 	// Read CA cert
 	caPem, err := cert.Cert.ReadFile(caCertFile)
@@ -263,3 +802,13 @@ func loadTLSConfig(caCertFile, clientCertFile, clientKeyFile string) (credential
 
 	return credentials.NewTLS(config), nil
 }
+
+// Name of this device shown in list of sessions
+func deviceName() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "unknown host"
+	}
+
+	return fmt.Sprintf("%s (%s/%s)", host, runtime.GOOS, runtime.GOARCH)
+}
@@ -1,18 +1,31 @@
 package grpc
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"testing"
 	"time"
 
+	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/convert"
 	"gophkeeper/pkg/models"
 	pb "gophkeeper/pkg/proto/keeper/grpcapi"
+	"gophkeeper/pkg/srp"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // MockUsersClient is a mock implementation of pb.UsersClient.
@@ -28,6 +41,22 @@ func (m *MockUsersClient) LoginV1(ctx context.Context, req *pb.LoginRequestV1, o
 	return args.Get(0).(*pb.LoginResponseV1), args.Error(1)
 }
 
+func (m *MockUsersClient) GetKDFV1(ctx context.Context, req *pb.GetKDFRequestV1, opts ...grpc.CallOption) (*pb.GetKDFResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetKDFResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) UpgradeAuthV1(ctx context.Context, req *pb.UpgradeAuthRequestV1, opts ...grpc.CallOption) (*pb.UpgradeAuthResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.UpgradeAuthResponseV1), args.Error(1)
+}
+
 func (m *MockUsersClient) RegisterV1(ctx context.Context, req *pb.RegisterRequestV1, opts ...grpc.CallOption) (*pb.RegisterResponseV1, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
@@ -36,6 +65,134 @@ func (m *MockUsersClient) RegisterV1(ctx context.Context, req *pb.RegisterReques
 	return args.Get(0).(*pb.RegisterResponseV1), args.Error(1)
 }
 
+func (m *MockUsersClient) LoginSRPStartV1(ctx context.Context, req *pb.LoginSRPStartRequestV1, opts ...grpc.CallOption) (*pb.LoginSRPStartResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.LoginSRPStartResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) LoginSRPFinishV1(ctx context.Context, req *pb.LoginSRPFinishRequestV1, opts ...grpc.CallOption) (*pb.LoginSRPFinishResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.LoginSRPFinishResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) EnrollSRPV1(ctx context.Context, req *pb.EnrollSRPRequestV1, opts ...grpc.CallOption) (*pb.EnrollSRPResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.EnrollSRPResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) RefreshV1(ctx context.Context, req *pb.RefreshRequestV1, opts ...grpc.CallOption) (*pb.RefreshResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.RefreshResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) ListSessionsV1(ctx context.Context, req *emptypb.Empty, opts ...grpc.CallOption) (*pb.ListSessionsResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListSessionsResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) RevokeSessionV1(ctx context.Context, req *pb.RevokeSessionRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
+func (m *MockUsersClient) LogoutV1(ctx context.Context, req *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
+func (m *MockUsersClient) LoginTOTPV1(ctx context.Context, req *pb.LoginTOTPRequestV1, opts ...grpc.CallOption) (*pb.LoginResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.LoginResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) GetTwoFactorV1(ctx context.Context, req *emptypb.Empty, opts ...grpc.CallOption) (*pb.TwoFactorStatusV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.TwoFactorStatusV1), args.Error(1)
+}
+
+func (m *MockUsersClient) EnrollTOTPV1(ctx context.Context, req *emptypb.Empty, opts ...grpc.CallOption) (*pb.EnrollTOTPResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.EnrollTOTPResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) ConfirmTOTPV1(ctx context.Context, req *pb.ConfirmTOTPRequestV1, opts ...grpc.CallOption) (*pb.ConfirmTOTPResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ConfirmTOTPResponseV1), args.Error(1)
+}
+
+func (m *MockUsersClient) DisableTOTPV1(ctx context.Context, req *pb.DisableTOTPRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
+// Users client talking to SRP server side, m2 replaces proof of server when set
+type srpUsersClient struct {
+	*MockUsersClient
+
+	salt     []byte
+	verifier []byte
+	m2       []byte
+	server   *srp.Server
+}
+
+func (c *srpUsersClient) LoginSRPStartV1(_ context.Context, req *pb.LoginSRPStartRequestV1, _ ...grpc.CallOption) (*pb.LoginSRPStartResponseV1, error) {
+	server, err := srp.NewServer(req.Login, c.salt, c.verifier, req.A)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	c.server = server
+
+	return &pb.LoginSRPStartResponseV1{SessionId: "session", Salt: c.salt, B: server.B}, nil
+}
+
+func (c *srpUsersClient) LoginSRPFinishV1(_ context.Context, req *pb.LoginSRPFinishRequestV1, _ ...grpc.CallOption) (*pb.LoginSRPFinishResponseV1, error) {
+	proof, err := c.server.Verify(req.M1)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if c.m2 != nil {
+		proof = c.m2
+	}
+
+	return &pb.LoginSRPFinishResponseV1{M2: proof, AccessToken: "test-token", RefreshToken: "test-refresh"}, nil
+}
+
 // MockSecretsClient is a mock implementation of pb.SecretsClient.
 type MockSecretsClient struct {
 	mock.Mock
@@ -57,13 +214,13 @@ func (m *MockSecretsClient) GetUserSecretV1(ctx context.Context, req *pb.GetUser
 	return args.Get(0).(*pb.GetUserSecretResponseV1), args.Error(1)
 }
 
-func (m *MockSecretsClient) SaveUserSecretV1(ctx context.Context, req *pb.SaveUserSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (m *MockSecretsClient) SaveUserSecretV1(ctx context.Context, req *pb.SaveUserSecretRequestV1, opts ...grpc.CallOption) (*pb.SaveUserSecretResponseV1, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 
-	return args.Get(0).(*emptypb.Empty), args.Error(1)
+	return args.Get(0).(*pb.SaveUserSecretResponseV1), args.Error(1)
 }
 
 func (m *MockSecretsClient) DeleteUserSecretV1(ctx context.Context, req *pb.DeleteUserSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
@@ -74,24 +231,216 @@ func (m *MockSecretsClient) DeleteUserSecretV1(ctx context.Context, req *pb.Dele
 	return args.Get(0).(*emptypb.Empty), args.Error(1)
 }
 
+func (m *MockSecretsClient) SyncV1(ctx context.Context, req *pb.SyncRequestV1, opts ...grpc.CallOption) (*pb.SyncResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.SyncResponseV1), args.Error(1)
+}
+
+func (m *MockSecretsClient) ListSecretRevisionsV1(ctx context.Context, req *pb.ListSecretRevisionsRequestV1, opts ...grpc.CallOption) (*pb.ListSecretRevisionsResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListSecretRevisionsResponseV1), args.Error(1)
+}
+
+func (m *MockSecretsClient) GetSecretRevisionV1(ctx context.Context, req *pb.GetSecretRevisionRequestV1, opts ...grpc.CallOption) (*pb.GetSecretRevisionResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.GetSecretRevisionResponseV1), args.Error(1)
+}
+
+func (m *MockSecretsClient) ListTrashV1(ctx context.Context, req *emptypb.Empty, opts ...grpc.CallOption) (*pb.ListTrashResponseV1, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*pb.ListTrashResponseV1), args.Error(1)
+}
+
+func (m *MockSecretsClient) RestoreSecretV1(ctx context.Context, req *pb.RestoreSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
+func (m *MockSecretsClient) PurgeSecretV1(ctx context.Context, req *pb.PurgeSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*emptypb.Empty), args.Error(1)
+}
+
+// Cheapest KDF params accepted by server
+var testKDF = crypto.KDFParams{Time: 1, Memory: models.MinKDFMemory, Threads: 1}
+
+func newTestUsersClient(t *testing.T, users pb.UsersClient) *GRPCClient {
+	return &GRPCClient{
+		usersClient: users,
+		config:      &config.Config{ReplicaDir: t.TempDir(), ServerAddress: "localhost:50051", KDF: testKDF},
+	}
+}
+
 func TestGRPCClient_Login(t *testing.T) {
+	kdf, err := crypto.NewAccountKDF(testKDF)
+	require.NoError(t, err)
+
+	keys, err := crypto.DeriveAccountKeys("testpass", kdf)
+	require.NoError(t, err)
 
 	t.Run("Success", func(t *testing.T) {
 		mockUsersClient := new(MockUsersClient)
-		client := &GRPCClient{usersClient: mockUsersClient}
+		client := newTestUsersClient(t, mockUsersClient)
 
-		mockUsersClient.On("LoginV1", mock.Anything, mock.Anything).Return(&pb.LoginResponseV1{AccessToken: "test-token"}, nil)
+		mockUsersClient.On("GetKDFV1", mock.Anything, &pb.GetKDFRequestV1{Login: "testuser"}).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
+		mockUsersClient.On("LoginV1", mock.Anything, mock.MatchedBy(func(req *pb.LoginRequestV1) bool {
+			return req.Password == "" && bytes.Equal(req.AuthKey, keys.Auth)
+		})).Return(&pb.LoginResponseV1{AccessToken: "test-token"}, nil)
+
+		// Account is switched to SRP with verifier of auth key
+		mockUsersClient.On("EnrollSRPV1", mock.Anything, mock.MatchedBy(func(req *pb.EnrollSRPRequestV1) bool {
+			return bytes.Equal(req.Srp.Verifier, srp.Verifier("testuser", keys.Auth, req.Srp.Salt)) && bytes.Equal(req.AuthKey, keys.Auth)
+		})).Return(&pb.EnrollSRPResponseV1{}, nil)
 
 		token, err := client.Login(context.Background(), "testuser", "testpass")
 
 		assert.NoError(t, err)
 		assert.Equal(t, "test-token", token)
+		assert.Equal(t, keys.Encryption, client.GetEncryptionKey())
+		assert.Equal(t, "testpass", client.GetPassword())
+		assert.Equal(t, kdf, client.GetKDF())
+
+		saved, err := storage.LoadAccountParams(client.kdfPath("testuser"))
+		assert.NoError(t, err)
+		assert.Equal(t, storage.AccountParams{AccountKDF: kdf, SRP: true}, saved)
+	})
+
+	t.Run("Legacy account", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Legacy: true}, nil)
+		mockUsersClient.On("LoginV1", mock.Anything, &pb.LoginRequestV1{Login: "testuser", Password: "testpass"}).Return(&pb.LoginResponseV1{AccessToken: "test-token"}, nil)
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "testpass", client.GetEncryptionKey())
+		assert.True(t, client.GetKDF().Legacy())
+	})
+
+	t.Run("Server without KDF params", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unimplemented, "unknown method"))
+		mockUsersClient.On("LoginV1", mock.Anything, &pb.LoginRequestV1{Login: "testuser", Password: "testpass"}).Return(&pb.LoginResponseV1{AccessToken: "test-token"}, nil)
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.NoError(t, err)
+		assert.True(t, client.GetKDF().Legacy())
+	})
+
+	t.Run("Downgrade refused", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		require.NoError(t, storage.SaveAccountParams(client.kdfPath("testuser"), storage.AccountParams{AccountKDF: kdf}))
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Legacy: true}, nil)
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.ErrorIs(t, err, entities.ErrAuthDowngrade)
+		mockUsersClient.AssertNotCalled(t, "LoginV1", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Weak params refused", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		weak := kdf
+		weak.Memory = 1024
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(weak)}, nil)
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.ErrorIs(t, err, models.ErrBadKDF)
+		mockUsersClient.AssertNotCalled(t, "LoginV1", mock.Anything, mock.Anything)
+	})
+
+	for name, change := range map[string]func(*models.AccountKDF){
+		"Other salt":    func(k *models.AccountKDF) { k.Salt = append([]byte{}, k.Salt...); k.Salt[0]++ },
+		"Less time":     func(k *models.AccountKDF) { k.Time-- },
+		"Less memory":   func(k *models.AccountKDF) { k.Memory -= 1024 },
+		"Fewer threads": func(k *models.AccountKDF) { k.Threads-- },
+	} {
+		t.Run(name+" refused", func(t *testing.T) {
+			mockUsersClient := new(MockUsersClient)
+			client := newTestUsersClient(t, mockUsersClient)
+
+			saved := kdf
+			saved.Time++
+			saved.Memory += 1024
+			saved.Threads++
+			require.NoError(t, storage.SaveAccountParams(client.kdfPath("testuser"), storage.AccountParams{AccountKDF: saved, SRP: true}))
+
+			sent := saved
+			change(&sent)
+			mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(sent), Srp: true}, nil)
+
+			_, err := client.Login(context.Background(), "testuser", "testpass")
+
+			assert.ErrorIs(t, err, entities.ErrAuthDowngrade)
+			mockUsersClient.AssertNotCalled(t, "LoginSRPStartV1", mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("Locked out", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		st, err := status.New(codes.ResourceExhausted, "too many failed logins").
+			WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(90 * time.Second)})
+		require.NoError(t, err)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
+		mockUsersClient.On("LoginV1", mock.Anything, mock.Anything).Return(nil, st.Err())
+
+		_, err = client.Login(context.Background(), "testuser", "testpass")
+
+		var locked *entities.LoginLockedError
+		require.ErrorAs(t, err, &locked)
+		assert.ErrorIs(t, err, entities.ErrLoginLocked)
+		assert.Equal(t, 90*time.Second, locked.RetryAfter)
+		assert.WithinDuration(t, time.Now().Add(90*time.Second), locked.Until, time.Second)
+	})
+
+	t.Run("Too many logins in progress", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
+		mockUsersClient.On("LoginV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.ResourceExhausted, "too many logins"))
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.NotErrorIs(t, err, entities.ErrLoginLocked)
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		mockUsersClient := new(MockUsersClient)
-		client := &GRPCClient{usersClient: mockUsersClient}
+		client := newTestUsersClient(t, mockUsersClient)
 
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
 		mockUsersClient.On("LoginV1", mock.Anything, mock.Anything).Return(nil, errors.New("login error"))
 
 		token, err := client.Login(context.Background(), "testuser", "testpass")
@@ -105,20 +454,60 @@ func TestGRPCClient_Register(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		mockUsersClient := new(MockUsersClient)
-		client := &GRPCClient{usersClient: mockUsersClient}
+		client := newTestUsersClient(t, mockUsersClient)
 
-		mockUsersClient.On("RegisterV1", mock.Anything, mock.Anything).Return(&pb.RegisterResponseV1{AccessToken: "test-token"}, nil)
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{}, nil)
+
+		var sent *pb.RegisterRequestV1
+		mockUsersClient.On("RegisterV1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*pb.RegisterRequestV1)
+		}).Return(&pb.RegisterResponseV1{AccessToken: "test-token"}, nil)
 
 		token, err := client.Register(context.Background(), "testuser", "testpass")
 
-		assert.NoError(t, err)
+		require.NoError(t, err)
 		assert.Equal(t, "test-token", token)
+
+		// Server gets auth key and KDF params, encryption key stays on client
+		kdf := convert.ProtoToKDF(sent.Kdf)
+		keys, err := crypto.DeriveAccountKeys("testpass", kdf)
+		require.NoError(t, err)
+		assert.Equal(t, keys.Auth, sent.AuthKey)
+		assert.Equal(t, keys.Encryption, client.GetEncryptionKey())
+		assert.NotContains(t, sent.String(), "testpass")
+		assert.Nil(t, sent.Srp)
+	})
+
+	t.Run("Server with SRP", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Srp: true}, nil)
+
+		var sent *pb.RegisterRequestV1
+		mockUsersClient.On("RegisterV1", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent = args.Get(1).(*pb.RegisterRequestV1)
+		}).Return(&pb.RegisterResponseV1{AccessToken: "test-token"}, nil)
+
+		_, err := client.Register(context.Background(), "testuser", "testpass")
+		require.NoError(t, err)
+
+		// Server gets only verifier of auth key
+		keys, err := crypto.DeriveAccountKeys("testpass", convert.ProtoToKDF(sent.Kdf))
+		require.NoError(t, err)
+		assert.Empty(t, sent.AuthKey)
+		assert.Equal(t, srp.Verifier("testuser", keys.Auth, sent.Srp.Salt), sent.Srp.Verifier)
+
+		saved, err := storage.LoadAccountParams(client.kdfPath("testuser"))
+		require.NoError(t, err)
+		assert.True(t, saved.SRP)
 	})
 
 	t.Run("Error", func(t *testing.T) {
 		mockUsersClient := new(MockUsersClient)
-		client := &GRPCClient{usersClient: mockUsersClient}
+		client := newTestUsersClient(t, mockUsersClient)
 
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Srp: true}, nil)
 		mockUsersClient.On("RegisterV1", mock.Anything, mock.Anything).Return(nil, errors.New("register error"))
 
 		token, err := client.Register(context.Background(), "testuser", "testpass")
@@ -128,6 +517,279 @@ func TestGRPCClient_Register(t *testing.T) {
 	})
 }
 
+func TestGRPCClient_UpgradeAuth(t *testing.T) {
+	kdf, err := crypto.NewAccountKDF(testKDF)
+	require.NoError(t, err)
+
+	keys, err := crypto.DeriveAccountKeys("testpass", kdf)
+	require.NoError(t, err)
+
+	payloads := []models.SecretPayload{{ID: 1, Revision: 2, Payload: []byte("encrypted")}}
+
+	t.Run("Success", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		client.login, client.password, client.encryptionKey = "testuser", "testpass", "testpass"
+
+		mockUsersClient.On("UpgradeAuthV1", mock.Anything, &pb.UpgradeAuthRequestV1{
+			AuthKey:  keys.Auth,
+			Kdf:      convert.KDFToProto(kdf),
+			Secrets:  []*pb.SecretPayload{{Id: 1, Revision: 2, Payload: []byte("encrypted")}},
+			Password: "testpass",
+		}).Return(&pb.UpgradeAuthResponseV1{AccessToken: "new-token"}, nil)
+		mockUsersClient.On("EnrollSRPV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unimplemented, "unknown method"))
+
+		err := client.UpgradeAuth(context.Background(), kdf, keys, payloads)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "new-token", client.GetToken())
+		assert.Equal(t, keys.Encryption, client.GetEncryptionKey())
+		assert.False(t, client.GetKDF().Legacy())
+	})
+
+	t.Run("Secrets changed", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		client.encryptionKey = "testpass"
+
+		mockUsersClient.On("UpgradeAuthV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Aborted, "secrets were changed"))
+
+		err := client.UpgradeAuth(context.Background(), kdf, keys, payloads)
+
+		assert.ErrorIs(t, err, entities.ErrConflict)
+		assert.Equal(t, "testpass", client.GetEncryptionKey())
+	})
+}
+
+func TestGRPCClient_LoginSRP(t *testing.T) {
+	kdf, err := crypto.NewAccountKDF(testKDF)
+	require.NoError(t, err)
+
+	keys, err := crypto.DeriveAccountKeys("testpass", kdf)
+	require.NoError(t, err)
+
+	salt, verifier, err := srp.NewVerifier("testuser", keys.Auth)
+	require.NoError(t, err)
+
+	serve := func(users *MockUsersClient, m2 []byte) *srpUsersClient {
+		users.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf), Srp: true}, nil)
+		return &srpUsersClient{MockUsersClient: users, salt: salt, verifier: verifier, m2: m2}
+	}
+
+	t.Run("Mutual authentication", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, serve(mockUsersClient, nil))
+
+		token, err := client.Login(context.Background(), "testuser", "testpass")
+
+		require.NoError(t, err)
+		assert.Equal(t, "test-token", token)
+		assert.Equal(t, "test-refresh", client.refreshToken)
+		assert.Equal(t, keys.Encryption, client.GetEncryptionKey())
+		mockUsersClient.AssertNotCalled(t, "LoginV1", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, serve(mockUsersClient, nil))
+
+		_, err := client.Login(context.Background(), "testuser", "wrongpass")
+
+		assert.ErrorIs(t, err, entities.ErrUnauthenticated)
+	})
+
+	t.Run("Impostor server", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, serve(mockUsersClient, make([]byte, 32)))
+
+		token, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.ErrorIs(t, err, entities.ErrServerImpostor)
+		assert.Empty(t, token)
+		assert.Empty(t, client.GetToken())
+	})
+
+	t.Run("Downgrade refused", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		require.NoError(t, storage.SaveAccountParams(client.kdfPath("testuser"), storage.AccountParams{AccountKDF: kdf, SRP: true}))
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
+
+		_, err := client.Login(context.Background(), "testuser", "testpass")
+
+		assert.ErrorIs(t, err, entities.ErrAuthDowngrade)
+		mockUsersClient.AssertNotCalled(t, "LoginV1", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGRPCClient_Refresh(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Rotated once for concurrent calls", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		client.setAccount("stale", "refresh", "testuser", "testpass", "key", storage.AccountParams{})
+
+		mockUsersClient.On("RefreshV1", mock.Anything, &pb.RefreshRequestV1{RefreshToken: "refresh"}).
+			Return(&pb.RefreshResponseV1{AccessToken: "fresh", RefreshToken: "refresh2"}, nil).Once()
+
+		require.NoError(t, client.refresh(ctx, "stale"))
+		assert.Equal(t, "fresh", client.GetToken())
+		assert.Equal(t, "refresh2", client.refreshToken)
+
+		// Another call failed with the same stale token, token is fresh already
+		require.NoError(t, client.refresh(ctx, "stale"))
+		mockUsersClient.AssertNumberOfCalls(t, "RefreshV1", 1)
+	})
+
+	t.Run("No refresh token", func(t *testing.T) {
+		client := newTestUsersClient(t, new(MockUsersClient))
+		client.setAccount("stale", "", "testuser", "testpass", "key", storage.AccountParams{})
+
+		err := client.refresh(ctx, "stale")
+		assert.ErrorIs(t, parseError(err), entities.ErrUnauthenticated)
+	})
+
+	t.Run("Session ended", func(t *testing.T) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+		client.setAccount("stale", "refresh", "testuser", "testpass", "key", storage.AccountParams{})
+
+		mockUsersClient.On("RefreshV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.PermissionDenied, "session was ended"))
+
+		err := client.refresh(ctx, "stale")
+		assert.ErrorIs(t, parseError(err), entities.ErrSessionRevoked)
+
+		// Credentials are forgotten, client is not logged in again silently
+		assert.Empty(t, client.GetToken())
+		assert.Empty(t, client.GetPassword())
+	})
+}
+
+func TestGRPCClient_Sessions(t *testing.T) {
+	ctx := context.Background()
+	mockUsersClient := new(MockUsersClient)
+	client := newTestUsersClient(t, mockUsersClient)
+	client.setAccount("token", "refresh", "testuser", "testpass", "key", storage.AccountParams{})
+
+	mockUsersClient.On("ListSessionsV1", mock.Anything, mock.Anything).Return(&pb.ListSessionsResponseV1{Sessions: []*pb.Session{
+		{Id: 1, Device: "laptop", Current: true, LastSeenAt: timestamppb.Now()},
+		{Id: 2, Device: "phone", LastSeenAt: timestamppb.Now()},
+	}}, nil)
+	mockUsersClient.On("RevokeSessionV1", mock.Anything, &pb.RevokeSessionRequestV1{Id: 2}).Return(&emptypb.Empty{}, nil)
+	mockUsersClient.On("RevokeSessionV1", mock.Anything, &pb.RevokeSessionRequestV1{Id: 3}).Return(nil, status.Error(codes.NotFound, "session not found"))
+	mockUsersClient.On("LogoutV1", mock.Anything, mock.Anything).Return(&emptypb.Empty{}, nil)
+
+	sessions, err := client.ListSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.True(t, sessions[0].Current)
+	assert.Equal(t, "phone", sessions[1].Device)
+
+	assert.NoError(t, client.RevokeSession(ctx, 2))
+	assert.ErrorIs(t, client.RevokeSession(ctx, 3), entities.ErrSessionNotFound)
+
+	require.NoError(t, client.Logout(ctx))
+	assert.Empty(t, client.GetToken())
+	assert.Empty(t, client.refreshToken)
+	assert.Empty(t, client.GetPassword())
+}
+
+func TestGRPCClient_LoginTOTP(t *testing.T) {
+	ctx := context.Background()
+
+	kdf, err := crypto.NewAccountKDF(testKDF)
+	require.NoError(t, err)
+
+	keys, err := crypto.DeriveAccountKeys("testpass", kdf)
+	require.NoError(t, err)
+
+	challenged := func(t *testing.T) (*GRPCClient, *MockUsersClient) {
+		mockUsersClient := new(MockUsersClient)
+		client := newTestUsersClient(t, mockUsersClient)
+
+		mockUsersClient.On("GetKDFV1", mock.Anything, mock.Anything).Return(&pb.GetKDFResponseV1{Kdf: convert.KDFToProto(kdf)}, nil)
+		mockUsersClient.On("LoginV1", mock.Anything, mock.Anything).Return(&pb.LoginResponseV1{TotpChallenge: "challenge"}, nil)
+
+		_, err := client.Login(ctx, "testuser", "testpass")
+		require.ErrorIs(t, err, entities.ErrTOTPRequired)
+
+		// Nothing is known about account before code is accepted
+		assert.Empty(t, client.GetToken())
+		assert.Empty(t, client.GetPassword())
+		mockUsersClient.AssertNotCalled(t, "EnrollSRPV1", mock.Anything, mock.Anything)
+
+		return client, mockUsersClient
+	}
+
+	t.Run("Success after wrong code", func(t *testing.T) {
+		client, mockUsersClient := challenged(t)
+
+		mockUsersClient.On("LoginTOTPV1", mock.Anything, &pb.LoginTOTPRequestV1{Challenge: "challenge", Code: "000000"}).
+			Return(nil, status.Error(codes.Unauthenticated, "wrong one-time code"))
+		mockUsersClient.On("LoginTOTPV1", mock.Anything, &pb.LoginTOTPRequestV1{Challenge: "challenge", Code: "123456"}).
+			Return(&pb.LoginResponseV1{AccessToken: "test-token", RefreshToken: "test-refresh"}, nil)
+		mockUsersClient.On("EnrollSRPV1", mock.Anything, mock.Anything).Return(&pb.EnrollSRPResponseV1{}, nil)
+
+		_, err := client.LoginTOTP(ctx, "000000")
+		assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+
+		token, err := client.LoginTOTP(ctx, "123456")
+		require.NoError(t, err)
+		assert.Equal(t, "test-token", token)
+		assert.Equal(t, "test-refresh", client.refreshToken)
+		assert.Equal(t, keys.Encryption, client.GetEncryptionKey())
+		assert.Equal(t, "testpass", client.GetPassword())
+
+		// Account is switched to SRP once logged in
+		mockUsersClient.AssertCalled(t, "EnrollSRPV1", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Challenge expired", func(t *testing.T) {
+		client, mockUsersClient := challenged(t)
+
+		mockUsersClient.On("LoginTOTPV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.FailedPrecondition, "login expired"))
+
+		_, err := client.LoginTOTP(ctx, "123456")
+		assert.ErrorIs(t, err, entities.ErrLoginExpired)
+
+		// Login has to start over
+		_, err = client.LoginTOTP(ctx, "123456")
+		assert.ErrorIs(t, err, entities.ErrLoginExpired)
+		mockUsersClient.AssertNumberOfCalls(t, "LoginTOTPV1", 1)
+	})
+}
+
+func TestGRPCClient_TwoFactor(t *testing.T) {
+	ctx := context.Background()
+	mockUsersClient := new(MockUsersClient)
+	client := newTestUsersClient(t, mockUsersClient)
+
+	mockUsersClient.On("GetTwoFactorV1", mock.Anything, mock.Anything).Return(&pb.TwoFactorStatusV1{Enabled: true, RecoveryCodes: 9}, nil)
+	mockUsersClient.On("EnrollTOTPV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.AlreadyExists, "enabled"))
+	mockUsersClient.On("ConfirmTOTPV1", mock.Anything, &pb.ConfirmTOTPRequestV1{Code: "000000"}).Return(nil, status.Error(codes.InvalidArgument, "wrong code"))
+	mockUsersClient.On("ConfirmTOTPV1", mock.Anything, &pb.ConfirmTOTPRequestV1{Code: "123456"}).
+		Return(&pb.ConfirmTOTPResponseV1{RecoveryCodes: []string{"abcde-fghij"}}, nil)
+	mockUsersClient.On("DisableTOTPV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.FailedPrecondition, "not enabled"))
+
+	st, err := client.TwoFactor(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, models.TwoFactorStatus{Enabled: true, RecoveryCodes: 9}, st)
+
+	_, err = client.EnrollTOTP(ctx)
+	assert.ErrorIs(t, err, entities.ErrTwoFactorEnabled)
+
+	_, err = client.ConfirmTOTP(ctx, "000000")
+	assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+
+	recoveryCodes, err := client.ConfirmTOTP(ctx, "123456")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abcde-fghij"}, recoveryCodes)
+
+	assert.ErrorIs(t, client.DisableTOTP(ctx, "123456"), entities.ErrTwoFactorDisabled)
+}
+
 func TestGRPCClient_LoadSecrets(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
@@ -163,12 +825,46 @@ func TestGRPCClient_SaveSecret(t *testing.T) {
 		mockSecretsClient := new(MockSecretsClient)
 		client := &GRPCClient{secretsClient: mockSecretsClient}
 
-		mockSecretsClient.On("SaveUserSecretV1", mock.Anything, mock.Anything).Return(&emptypb.Empty{}, nil)
+		mockSecretsClient.On("SaveUserSecretV1", mock.Anything, mock.Anything).Return(&pb.SaveUserSecretResponseV1{Secret: &pb.Secret{Id: 1, Revision: 3}}, nil)
 
-		secret := &models.Secret{ID: 1, Title: "Test Secret", CreatedAt: time.Now(), UpdatedAt: time.Now()}
+		secret := &models.Secret{ID: 1, Title: "Test Secret", CreatedAt: time.Now(), UpdatedAt: time.Now(), Revision: 2}
 		err := client.SaveSecret(context.Background(), secret)
 
 		assert.NoError(t, err)
+		assert.Equal(t, uint64(3), secret.Revision)
+	})
+
+	t.Run("Create key", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("SaveUserSecretV1", mock.Anything, mock.MatchedBy(func(req *pb.SaveUserSecretRequestV1) bool {
+			return req.CreateKey == "key"
+		})).Return(&pb.SaveUserSecretResponseV1{Secret: &pb.Secret{Id: 5, Revision: 1}}, nil)
+
+		secret := &models.Secret{Title: "New Secret", CreateKey: "key"}
+		require.NoError(t, client.SaveSecret(context.Background(), secret))
+		assert.Equal(t, uint64(5), secret.ID)
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		st, err := status.New(codes.Aborted, "stale revision").WithDetails(&pb.Secret{Id: 1, Title: "theirs", Revision: 5})
+		assert.NoError(t, err)
+
+		mockSecretsClient.On("SaveUserSecretV1", mock.Anything, mock.Anything).Return(nil, st.Err())
+
+		secret := &models.Secret{ID: 1, Title: "mine", Revision: 4}
+		err = client.SaveSecret(context.Background(), secret)
+
+		var conflict *entities.ConflictError
+		if assert.ErrorAs(t, err, &conflict) {
+			assert.Equal(t, "theirs", conflict.Theirs.Title)
+			assert.Equal(t, uint64(5), conflict.Theirs.Revision)
+		}
+		assert.ErrorIs(t, err, entities.ErrConflict)
 	})
 
 	t.Run("Error", func(t *testing.T) {
@@ -207,3 +903,103 @@ func TestGRPCClient_DeleteSecret(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestGRPCClient_SyncSecrets(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("SyncV1", mock.Anything, &pb.SyncRequestV1{Cursor: "7"}).Return(&pb.SyncResponseV1{
+			Secrets:    []*pb.Secret{{Id: 1, Title: "changed"}},
+			DeletedIds: []uint64{2},
+			Cursor:     "9",
+			HasMore:    true,
+		}, nil)
+
+		changes, err := client.SyncSecrets(context.Background(), "7")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "changed", changes.Updated[0].Title)
+		assert.Equal(t, []uint64{2}, changes.Deleted)
+		assert.Equal(t, "9", changes.Cursor)
+		assert.True(t, changes.HasMore)
+		assert.False(t, changes.Reset)
+	})
+
+	t.Run("Old server", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("SyncV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.Unimplemented, "unknown method SyncV1"))
+
+		_, err := client.SyncSecrets(context.Background(), "")
+
+		assert.ErrorIs(t, err, entities.ErrNotSupported)
+	})
+}
+
+func TestGRPCClient_Revisions(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("ListSecretRevisionsV1", mock.Anything, &pb.ListSecretRevisionsRequestV1{Id: 1}).Return(&pb.ListSecretRevisionsResponseV1{
+			Revisions: []*pb.Secret{{Id: 1, Revision: 2}, {Id: 1, Revision: 1}},
+		}, nil)
+
+		revisions, err := client.LoadRevisions(context.Background(), 1)
+
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+		assert.Equal(t, uint64(2), revisions[0].Revision)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("GetSecretRevisionV1", mock.Anything, mock.Anything).Return(nil, status.Error(codes.NotFound, "secret revision not found"))
+
+		_, err := client.LoadRevision(context.Background(), 1, 9)
+
+		assert.ErrorIs(t, err, entities.ErrRevisionNotFound)
+	})
+}
+
+func TestGRPCClient_Trash(t *testing.T) {
+	t.Run("List", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		deletedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		mockSecretsClient.On("ListTrashV1", mock.Anything, mock.Anything).Return(&pb.ListTrashResponseV1{
+			Secrets: []*pb.Secret{{Id: 3, DeletedAt: timestamppb.New(deletedAt)}},
+		}, nil)
+
+		secrets, err := client.LoadTrash(context.Background())
+
+		assert.NoError(t, err)
+		assert.Len(t, secrets, 1)
+		assert.Equal(t, deletedAt, *secrets[0].DeletedAt)
+	})
+
+	t.Run("Restore not in trash", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("RestoreSecretV1", mock.Anything, &pb.RestoreSecretRequestV1{Id: 3}).Return(nil, status.Error(codes.NotFound, "secret not found in trash"))
+
+		err := client.RestoreSecret(context.Background(), 3)
+
+		assert.ErrorIs(t, err, entities.ErrNotInTrash)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		mockSecretsClient := new(MockSecretsClient)
+		client := &GRPCClient{secretsClient: mockSecretsClient}
+
+		mockSecretsClient.On("PurgeSecretV1", mock.Anything, &pb.PurgeSecretRequestV1{Id: 3}).Return(&emptypb.Empty{}, nil)
+
+		assert.NoError(t, client.PurgeSecret(context.Background(), 3))
+	})
+}
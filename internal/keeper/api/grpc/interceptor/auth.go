@@ -5,45 +5,69 @@ import (
 	"context"
 	"gophkeeper/pkg/constants"
 	"strconv"
+	"strings"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
-// Unary gRPC interceptor which adds auth token to metadata
-func AddAuth(token *string, clientID uint32) grpc.UnaryClientInterceptor {
+// Unary gRPC interceptor which adds auth token and device name to metadata
+func AddAuth(token *string, clientID uint32, device string) grpc.UnaryClientInterceptor {
 	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		// pass request if token is empty
-		if len(*token) == 0 {
-			return invoker(ctx, method, req, reply, cc, opts...)
+		return invoker(authMetadata(ctx, *token, uint64(clientID), device), method, req, reply, cc, opts...)
+	}
+}
+
+// Stream gRPC interceptor which adds auth token and device name to metadata
+func AddAuthStream(token *string, clientID uint64, device string) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(authMetadata(ctx, *token, clientID, device), desc, cc, method, opts...)
+	}
+}
+
+// Unary gRPC interceptor which gets new access token with refresh once access token expired, and repeats the call.
+// Login methods are left alone, they fail with codes.Unauthenticated on bad credentials.
+func RefreshToken(token *string, refresh func(ctx context.Context, stale string) error) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		stale := *token
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) != codes.Unauthenticated || stale == "" || public(method) {
+			return err
 		}
 
-		// add access token to metadata
-		md := metadata.New(map[string]string{
-			constants.AccessTokenHeader: *token,
-			constants.ClientIDHeader:    strconv.Itoa(int(clientID)),
-		})
+		if err := refresh(ctx, stale); err != nil {
+			return err
+		}
 
-		mdCtx := metadata.NewOutgoingContext(ctx, md)
-		return invoker(mdCtx, method, req, reply, cc, opts...)
+		return invoker(ctx, method, req, reply, cc, opts...)
 	}
 }
 
-// Stream gRPC interceptor which adds auth token to metadata
-func AddAuthStream(token *string, clientID uint64) grpc.StreamClientInterceptor {
-	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
-		// pass request if token is empty
-		if len(*token) == 0 {
-			return streamer(ctx, desc, cc, method, opts...)
-		}
+// Device name is sent always, to be shown in sessions started by login
+func authMetadata(ctx context.Context, token string, clientID uint64, device string) context.Context {
+	md := metadata.New(map[string]string{
+		constants.DeviceHeader: device,
+	})
+
+	// add access token to metadata
+	if len(token) > 0 {
+		md.Set(constants.AccessTokenHeader, token)
+		md.Set(constants.ClientIDHeader, strconv.Itoa(int(clientID)))
+	}
 
-		// add access token to metadata
-		md := metadata.New(map[string]string{
-			constants.AccessTokenHeader: *token,
-			constants.ClientIDHeader:    strconv.Itoa(int(clientID)),
-		})
+	return metadata.NewOutgoingContext(ctx, md)
+}
 
-		mdCtx := metadata.NewOutgoingContext(ctx, md)
-		return streamer(mdCtx, desc, cc, method, opts...)
+// Methods server serves without access token
+func public(method string) bool {
+	for _, name := range []string{"RegisterV1", "LoginV1", "LoginSRP", "LoginTOTPV1", "GetKDFV1", "RefreshV1"} {
+		if strings.Contains(method, name) {
+			return true
+		}
 	}
+
+	return false
 }
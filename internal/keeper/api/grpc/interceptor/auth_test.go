@@ -8,7 +8,9 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 var (
@@ -41,7 +43,7 @@ func TestAddAuth(t *testing.T) {
 
 	t.Run("empty token", func(t *testing.T) {
 		token := ""
-		interceptor := AddAuth(&token, 11)
+		interceptor := AddAuth(&token, 11, "laptop")
 
 		err := interceptor(context.Background(), "SomeMethod", nil, nil, nil, invoker)
 
@@ -50,10 +52,67 @@ func TestAddAuth(t *testing.T) {
 
 	t.Run("pass token", func(t *testing.T) {
 		token := testToken
-		interceptor := AddAuth(&token, 11)
+		interceptor := AddAuth(&token, 11, "laptop")
 
 		err := interceptor(context.Background(), "SomeMethod", nil, nil, nil, invoker)
 
 		assert.NoError(t, err)
 	})
 }
+
+func TestAddAuthDevice(t *testing.T) {
+	token := ""
+	interceptor := AddAuth(&token, 11, "laptop")
+
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		assert.Equal(t, []string{"laptop"}, md.Get(constants.DeviceHeader))
+		return nil
+	}
+
+	assert.NoError(t, interceptor(context.Background(), "/proto.keeper.grpcapi.Users/LoginV1", nil, nil, nil, invoker))
+}
+
+func TestRefreshToken(t *testing.T) {
+	expired := status.Error(codes.Unauthenticated, "token is expired")
+
+	// Server accepting only current token
+	token := "stale"
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if token != "fresh" {
+			return expired
+		}
+		return nil
+	}
+
+	t.Run("refreshed and repeated", func(t *testing.T) {
+		token = "stale"
+		interceptor := RefreshToken(&token, func(_ context.Context, stale string) error {
+			assert.Equal(t, "stale", stale)
+			token = "fresh"
+			return nil
+		})
+
+		assert.NoError(t, interceptor(context.Background(), "/proto.keeper.grpcapi.Secrets/GetUserSecretsV1", nil, nil, nil, invoker))
+	})
+
+	t.Run("refresh failed", func(t *testing.T) {
+		token = "stale"
+		revoked := status.Error(codes.PermissionDenied, "session was ended")
+		interceptor := RefreshToken(&token, func(context.Context, string) error { return revoked })
+
+		err := interceptor(context.Background(), "/proto.keeper.grpcapi.Secrets/GetUserSecretsV1", nil, nil, nil, invoker)
+		assert.Equal(t, revoked, err)
+	})
+
+	t.Run("login methods left alone", func(t *testing.T) {
+		token = "stale"
+		interceptor := RefreshToken(&token, func(context.Context, string) error {
+			t.Fatal("refresh must not be called")
+			return nil
+		})
+
+		err := interceptor(context.Background(), "/proto.keeper.grpcapi.Users/LoginSRPFinishV1", nil, nil, nil, invoker)
+		assert.Equal(t, expired, err)
+	})
+}
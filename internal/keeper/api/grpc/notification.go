@@ -9,18 +9,22 @@ import (
 	pb "gophkeeper/pkg/proto/keeper/grpcapi"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // Subscribes for notifications and sending signal to tea program to reload list
 func (c *GRPCClient) Notifications(p *tea.Program) {
 	var (
 		stream pb.Notification_SubscribeV1Client
+		token  string // access token stream was opened with
 		err    error
 	)
 
 	for {
 		// Subscribe to notifications
 		if stream == nil {
+			token = c.accessToken
 			if stream, err = c.subscribe(); err != nil {
 				log.Printf("failed to subscribe: %v\n", err)
 				c.sleep()
@@ -32,6 +36,13 @@ func (c *GRPCClient) Notifications(p *tea.Program) {
 		if err != nil {
 			log.Printf("failed to recv msg: %v\n", err)
 			stream = nil
+
+			// Stream is authenticated when opened, expired token is refreshed before opening it again
+			if status.Code(err) == codes.Unauthenticated {
+				if err := c.refresh(context.Background(), token); err != nil {
+					log.Printf("failed to refresh token: %v\n", err)
+				}
+			}
 			c.sleep()
 
 			// Retry
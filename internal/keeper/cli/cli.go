@@ -0,0 +1,98 @@
+// Non-interactive commands of keeper, e.g. "keeper search -vault secret.db mail"
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/storage"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+)
+
+// Environment variable with master password of local vault, read from stdin when unset
+const PasswordEnv = "GOPH_VAULT_PASSWORD"
+
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+	ErrNoVault        = errors.New("path to vault is required, use -vault")
+)
+
+// Streams and config of running command
+type Env struct {
+	Config *config.Config
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
+type command func(ctx context.Context, env Env, args []string) error
+
+var commands = map[string]command{
+	"generate": runGenerate,
+	"search":   runSearch,
+}
+
+// Run command named by first argument
+func Run(ctx context.Context, env Env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w, available: %s", ErrUnknownCommand, commandNames())
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w %q, available: %s", ErrUnknownCommand, args[0], commandNames())
+	}
+
+	return cmd(ctx, env, args[1:])
+}
+
+func commandNames() string {
+	names := maps.Keys(commands)
+	slices.Sort(names)
+
+	return strings.Join(names, ", ")
+}
+
+// Flag set printing usage to stderr and returning parse errors instead of exiting
+func newFlagSet(env Env, name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(env.Stderr)
+
+	return fs
+}
+
+// Open local vault read-only, so vault opened in TUI is not disturbed
+func openVault(env Env, path string) (storage.Storage, error) {
+	if path == "" {
+		return nil, ErrNoVault
+	}
+
+	password, err := readPassword(env)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.OpenLocal(path, password, crypto.NewVaultEncrypter(env.Config.KDF), storage.WithReadOnly())
+}
+
+func readPassword(env Env) (string, error) {
+	if password, ok := os.LookupEnv(PasswordEnv); ok {
+		return password, nil
+	}
+
+	line, err := bufio.NewReader(env.Stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/passgen"
+)
+
+// Print random password or passphrase, its entropy goes to stderr so output can be piped
+func runGenerate(ctx context.Context, env Env, args []string) error {
+	defaults := passgen.DefaultOptions()
+	phraseDefaults := passgen.DefaultPassphraseOptions()
+
+	fs := newFlagSet(env, "generate")
+	length := fs.Int("length", defaults.Length, "password length")
+	noLower := fs.Bool("no-lower", false, "without lowercase letters")
+	noUpper := fs.Bool("no-upper", false, "without uppercase letters")
+	noDigits := fs.Bool("no-digits", false, "without digits")
+	noSymbols := fs.Bool("no-symbols", false, "without symbols")
+	ambiguous := fs.Bool("ambiguous", false, "allow ambiguous characters like l, 1, O and 0")
+	words := fs.Int("words", 0, "generate passphrase of this many words instead of password")
+	separator := fs.String("separator", phraseDefaults.Separator, "separator of passphrase words")
+	capitalize := fs.Bool("capitalize", false, "capitalize passphrase words")
+	digit := fs.Bool("digit", false, "append digit to random passphrase word")
+	count := fs.Int("count", 1, "number of passwords")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: keeper generate [-length n] [-no-symbols] ... | keeper generate -words n [-separator s]\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		generate func() (string, error)
+		entropy  float64
+	)
+
+	if *words > 0 {
+		opts := passgen.PassphraseOptions{Words: *words, Separator: *separator, Capitalize: *capitalize, Digit: *digit}
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+		generate = func() (string, error) { return passgen.Passphrase(opts) }
+		entropy = opts.Entropy()
+	} else {
+		opts := passgen.Options{
+			Length:           *length,
+			Lower:            !*noLower,
+			Upper:            !*noUpper,
+			Digits:           !*noDigits,
+			Symbols:          !*noSymbols,
+			ExcludeAmbiguous: !*ambiguous,
+		}
+		if err := opts.Validate(); err != nil {
+			return err
+		}
+		generate = func() (string, error) { return passgen.Password(opts) }
+		entropy = opts.Entropy()
+	}
+
+	for range max(*count, 1) {
+		password, err := generate()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(env.Stdout, password)
+	}
+
+	fmt.Fprintf(env.Stderr, "entropy: %s\n", passgen.Describe(entropy))
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/keeper/passgen"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	out, err := runCmd(t, "", "generate", "-length", "12", "-no-symbols", "-count", "3")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.Len(t, line, 12)
+		assert.False(t, strings.ContainsAny(line, passgen.Symbols))
+	}
+
+	out, err = runCmd(t, "", "generate", "-words", "5", "-separator", ".")
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "."), 5)
+
+	_, err = runCmd(t, "", "generate", "-no-lower", "-no-upper", "-no-digits", "-no-symbols")
+	assert.ErrorIs(t, err, passgen.ErrNoClasses)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gophkeeper/internal/keeper/search"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Found secret printed with -json, without secret contents
+type searchResult struct {
+	ID        uint64    `json:"id"`
+	Title     string    `json:"title"`
+	Type      string    `json:"type"`
+	Folder    string    `json:"folder,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Print secrets of vault matching query, best matches first
+func runSearch(ctx context.Context, env Env, args []string) error {
+	fs := newFlagSet(env, "search")
+	vault := fs.String("vault", "", "path to local vault")
+	asJSON := fs.Bool("json", false, "print results as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: keeper search -vault path [-json] query\n")
+		fmt.Fprintf(fs.Output(), "query example: mail type:cred,card tag:work updated:<30d -folder:archive\n")
+		fs.PrintDefaults()
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	query, err := search.Parse(strings.Join(fs.Args(), " "))
+	if err != nil {
+		return err
+	}
+
+	store, err := openVault(env, *vault)
+	if err != nil {
+		return fmt.Errorf("failed to open vault: %w", err)
+	}
+	defer store.Close(ctx)
+
+	secrets, err := store.GetAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	found := query.Filter(secrets, time.Now())
+
+	if *asJSON {
+		results := make([]searchResult, 0, len(found))
+		for _, s := range found {
+			results = append(results, searchResult{
+				ID:        s.ID,
+				Title:     s.Title,
+				Type:      s.SecretType,
+				Folder:    s.Folder,
+				Tags:      s.Tags,
+				UpdatedAt: s.UpdatedAt,
+			})
+		}
+
+		enc := json.NewEncoder(env.Stdout)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(results)
+	}
+
+	w := tabwriter.NewWriter(env.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTYPE\tFOLDER\tTITLE\tTAGS\tUPDATED")
+	for _, s := range found {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", s.ID, s.SecretType, s.Folder, s.Title, s.Tags.String(), s.UpdatedAt.Local().Format("02 Jan 06 15:04"))
+	}
+
+	return w.Flush()
+}
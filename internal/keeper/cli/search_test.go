@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/search"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKDF = crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1}
+
+func newTestVault(t *testing.T, secrets ...*models.Secret) string {
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	store, err := storage.NewLocal(storage.EngineVault, path, "password", crypto.NewVaultEncrypter(testKDF))
+	require.NoError(t, err)
+
+	for _, s := range secrets {
+		require.NoError(t, store.Create(context.Background(), s))
+	}
+	require.NoError(t, store.Close(context.Background()))
+
+	return path
+}
+
+func runCmd(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	env := Env{
+		Config: &config.Config{KDF: testKDF},
+		Stdin:  strings.NewReader(stdin),
+		Stdout: &stdout,
+		Stderr: &stderr,
+	}
+
+	err := Run(context.Background(), env, args)
+
+	return stdout.String(), err
+}
+
+func TestSearch(t *testing.T) {
+	now := time.Now()
+	path := newTestVault(t,
+		&models.Secret{Title: "Gmail", SecretType: string(models.CredSecret), Folder: "mail", UpdatedAt: now,
+			Creds: &models.Credentials{Login: "john", Password: "p"}},
+		&models.Secret{Title: "Old mail", SecretType: string(models.CredSecret), UpdatedAt: now.Add(-90 * 24 * time.Hour),
+			Creds: &models.Credentials{Login: "john", Password: "p"}},
+		&models.Secret{Title: "Visa", SecretType: string(models.CardSecret), Tags: models.Tags{"bank"}, UpdatedAt: now,
+			Card: &models.Card{Number: "4111111111111111"}},
+	)
+
+	out, err := runCmd(t, "password\n", "search", "-vault", path, "mail", "updated:<30d")
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], "TITLE")
+	assert.Contains(t, lines[1], "Gmail")
+
+	out, err = runCmd(t, "password", "search", "-vault", path, "-json", "type:card")
+	require.NoError(t, err)
+
+	var results []searchResult
+	require.NoError(t, json.Unmarshal([]byte(out), &results))
+	require.Len(t, results, 1)
+	assert.Equal(t, "Visa", results[0].Title)
+	assert.Equal(t, []string{"bank"}, results[0].Tags)
+	assert.NotContains(t, out, "4111")
+
+	_, err = runCmd(t, "wrong\n", "search", "-vault", path, "mail")
+	assert.Error(t, err)
+
+	_, err = runCmd(t, "password\n", "search", "mail")
+	assert.ErrorIs(t, err, ErrNoVault)
+
+	_, err = runCmd(t, "password\n", "search", "-vault", path, "updated:<3q")
+	assert.ErrorIs(t, err, search.ErrBadFilter)
+}
+
+func TestRun_UnknownCommand(t *testing.T) {
+	_, err := runCmd(t, "", "frobnicate")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+
+	_, err = runCmd(t, "")
+	assert.ErrorIs(t, err, ErrUnknownCommand)
+}
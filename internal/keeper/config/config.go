@@ -2,7 +2,14 @@
 package config
 
 import (
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
 	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/health"
+	"gophkeeper/internal/keeper/sshagent"
+	"gophkeeper/internal/keeper/storage"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -15,11 +22,34 @@ type Config struct {
 	LogLevel      string
 	BuildDate     string
 	BuildVersion  string
+
+	KDF     crypto.KDFParams // work factor for new local vaults and server accounts
+	Backups int              // number of backup generations kept next to local vaults
+	History int              // number of previous versions kept per secret in local vaults
+
+	ReplicaDir string // where encrypted replicas of remote storages are kept
+
+	SSHAgentSocket string // where ssh-agent started from storage listens
+
+	HIBPPath       string // downloaded Have I Been Pwned passwords, empty disables breach check
+	PasswordMaxAge int    // months after which password is reported by health check, 0 disables
 }
 
-func New() *Config {
+// Config from defaults and GOPH_* environment, KDF params are checked so bad ones fail at start
+func New() (*Config, error) {
 	viper.SetDefault("address", "127.0.0.1:50051")
 	viper.SetDefault("verbose", false)
+	viper.SetDefault("backups", storage.DefaultBackups)
+	viper.SetDefault("history", storage.DefaultHistory)
+	viper.SetDefault("replica-dir", defaultReplicaDir())
+	viper.SetDefault("ssh-auth-sock", sshagent.DefaultSocket())
+	viper.SetDefault("hibp-path", "")
+	viper.SetDefault("password-max-age", health.DefaultMaxAge)
+
+	kdf := crypto.DefaultKDFParams()
+	viper.SetDefault("kdf-time", kdf.Time)
+	viper.SetDefault("kdf-memory", kdf.Memory)
+	viper.SetDefault("kdf-threads", kdf.Threads)
 
 	viper.SetEnvPrefix("GOPH")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -29,7 +59,33 @@ func New() *Config {
 		ServerAddress: entities.Address(viper.GetString("address")),
 		Verbose:       viper.GetBool("verbose"),
 		EnableTLS:     true,
+		KDF: crypto.KDFParams{
+			Time:    viper.GetUint32("kdf-time"),
+			Memory:  viper.GetUint32("kdf-memory"),
+			Threads: uint8(viper.GetUint("kdf-threads")),
+		},
+		Backups:    viper.GetInt("backups"),
+		History:    viper.GetInt("history"),
+		ReplicaDir: viper.GetString("replica-dir"),
+
+		SSHAgentSocket: viper.GetString("ssh-auth-sock"),
+
+		HIBPPath:       viper.GetString("hibp-path"),
+		PasswordMaxAge: viper.GetInt("password-max-age"),
+	}
+
+	if err := cfg.KDF.Validate(); err != nil {
+		return nil, fmt.Errorf("GOPH_KDF_*: %w", err)
+	}
+
+	return cfg, nil
+}
+
+func defaultReplicaDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ".gophkeeper"
 	}
 
-	return cfg
+	return filepath.Join(dir, "gophkeeper")
 }
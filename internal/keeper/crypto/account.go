@@ -0,0 +1,77 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"io"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	accountSaltLen = 16
+	authKeyLen     = 32
+
+	authKeyInfo       = "gophkeeper account auth key"
+	encryptionKeyInfo = "gophkeeper account encryption key"
+)
+
+// Keys of server account derived from master password. Only Auth is sent to server,
+// Encryption never leaves client and is used in place of password to encrypt payloads and replica.
+type AccountKeys struct {
+	Auth       []byte
+	Encryption string // hex encoded
+}
+
+// KDF params with fresh salt for new account
+func NewAccountKDF(params KDFParams) (models.AccountKDF, error) {
+	salt := make([]byte, accountSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return models.AccountKDF{}, err
+	}
+
+	kdf := models.AccountKDF{Salt: salt, Time: params.Time, Memory: params.Memory, Threads: params.Threads}
+	if err := kdf.Validate(); err != nil {
+		return models.AccountKDF{}, err
+	}
+
+	return kdf, nil
+}
+
+// Stretch password with argon2id once, then split result into independent auth and encryption keys
+// with HKDF, so knowing auth key tells nothing about encryption key. Params are validated
+// since they come from server.
+func DeriveAccountKeys(password string, kdf models.AccountKDF) (AccountKeys, error) {
+	if err := kdf.Validate(); err != nil {
+		return AccountKeys{}, err
+	}
+
+	master, err := KDFArgon2id.deriveKey(password, kdf.Salt, KDFParams{Time: kdf.Time, Memory: kdf.Memory, Threads: kdf.Threads})
+	if err != nil {
+		return AccountKeys{}, err
+	}
+
+	auth, err := expand(master, authKeyInfo, authKeyLen)
+	if err != nil {
+		return AccountKeys{}, err
+	}
+
+	encryption, err := expand(master, encryptionKeyInfo, keyLen)
+	if err != nil {
+		return AccountKeys{}, err
+	}
+
+	return AccountKeys{Auth: auth, Encryption: hex.EncodeToString(encryption)}, nil
+}
+
+func expand(master []byte, info string, size int) ([]byte, error) {
+	key := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.Expand(sha256.New, master, []byte(info)), key); err != nil {
+		return nil, fmt.Errorf("failed to expand key: %w", err)
+	}
+
+	return key, nil
+}
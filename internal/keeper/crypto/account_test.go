@@ -0,0 +1,61 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveAccountKeys(t *testing.T) {
+	kdf, err := NewAccountKDF(KDFParams{Time: 1, Memory: models.MinKDFMemory, Threads: 1})
+	require.NoError(t, err)
+	assert.Len(t, kdf.Salt, accountSaltLen)
+
+	keys, err := DeriveAccountKeys("password", kdf)
+	require.NoError(t, err)
+	assert.Len(t, keys.Auth, authKeyLen)
+
+	encryption, err := hex.DecodeString(keys.Encryption)
+	require.NoError(t, err)
+	assert.Len(t, encryption, keyLen)
+	assert.NotEqual(t, keys.Auth, encryption[:authKeyLen])
+
+	t.Run("Stable", func(t *testing.T) {
+		again, err := DeriveAccountKeys("password", kdf)
+		require.NoError(t, err)
+		assert.Equal(t, keys, again)
+	})
+
+	t.Run("Depends on password and salt", func(t *testing.T) {
+		other, err := DeriveAccountKeys("other password", kdf)
+		require.NoError(t, err)
+		assert.NotEqual(t, keys.Auth, other.Auth)
+
+		salted := kdf
+		salted.Salt = make([]byte, accountSaltLen)
+		other, err = DeriveAccountKeys("password", salted)
+		require.NoError(t, err)
+		assert.NotEqual(t, keys.Encryption, other.Encryption)
+	})
+
+	t.Run("Weak params refused", func(t *testing.T) {
+		weak := kdf
+		weak.Memory = 1024
+		_, err := DeriveAccountKeys("password", weak)
+		assert.ErrorIs(t, err, models.ErrBadKDF)
+
+		_, err = NewAccountKDF(testKDFParams)
+		assert.ErrorIs(t, err, models.ErrBadKDF)
+	})
+}
+
+func TestKDFParamsValidate(t *testing.T) {
+	assert.NoError(t, DefaultKDFParams().Validate())
+	assert.ErrorIs(t, KDFParams{Time: 1, Memory: models.MinKDFMemory - 1, Threads: 1}.Validate(), models.ErrBadKDF)
+	assert.ErrorIs(t, KDFParams{Memory: models.MinKDFMemory, Threads: 1}.Validate(), models.ErrBadKDF)
+	assert.ErrorIs(t, KDFParams{Time: 1, Memory: models.MinKDFMemory}.Validate(), models.ErrBadKDF)
+}
@@ -5,13 +5,10 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"fmt"
 	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/internal/keeper/utils"
 	"strings"
-
-	"golang.org/x/crypto/pbkdf2"
 )
 
 const saltLen = 8
@@ -112,5 +109,7 @@ func (e KeeperEncrypter) deriveKey(password string, salt []byte) ([]byte, []byte
 			return nil, nil, err
 		}
 	}
-	return pbkdf2.Key([]byte(password), salt, 4096, 32, sha256.New), salt, nil
+
+	key, err := KDFPBKDF2.deriveKey(password, salt, KDFParams{Time: legacyIterations})
+	return key, salt, err
 }
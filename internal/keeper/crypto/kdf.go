@@ -0,0 +1,100 @@
+package crypto
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Key derivation function identifier, stored in vault header
+type KDF uint8
+
+const (
+	KDFPBKDF2   KDF = 1 // PBKDF2-SHA256, used by legacy vaults
+	KDFArgon2id KDF = 2
+)
+
+const (
+	keyLen = 32 // AES-256
+
+	legacyIterations = 4096
+	maxIterations    = 10_000_000 // pbkdf2, well above any sane work factor
+
+	defaultArgonTime    = 3
+	defaultArgonMemory  = 64 * 1024 // KiB
+	defaultArgonThreads = 4
+)
+
+// Work factor of key derivation.
+// For PBKDF2 only Time is used and means number of iterations.
+type KDFParams struct {
+	Time    uint32 // passes over memory (argon2id) or iterations (pbkdf2)
+	Memory  uint32 // memory in KiB (argon2id)
+	Threads uint8  // parallelism (argon2id)
+}
+
+// Recommended argon2id params
+func DefaultKDFParams() KDFParams {
+	return KDFParams{
+		Time:    defaultArgonTime,
+		Memory:  defaultArgonMemory,
+		Threads: defaultArgonThreads,
+	}
+}
+
+// Check params of new vaults and accounts, they have to suit server accounts too
+func (p KDFParams) Validate() error {
+	return models.AccountKDF{Salt: make([]byte, models.MinKDFSaltLen), Time: p.Time, Memory: p.Memory, Threads: p.Threads}.Validate()
+}
+
+func (k KDF) String() string {
+	switch k {
+	case KDFPBKDF2:
+		return "pbkdf2-sha256"
+	case KDFArgon2id:
+		return "argon2id"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(k))
+	}
+}
+
+// Check params before deriving key, they may come from untrusted vault header.
+// Argon2id limits are shared with account KDF, so crafted file can't make us allocate gigabytes.
+func (k KDF) validate(params KDFParams) error {
+	switch k {
+	case KDFPBKDF2:
+		if params.Time == 0 || params.Time > maxIterations {
+			return fmt.Errorf("%w: pbkdf2 iterations must be 1-%d", entities.ErrUnsupportedVault, maxIterations)
+		}
+	case KDFArgon2id:
+		switch {
+		case params.Time == 0 || params.Time > models.MaxKDFTime:
+			return fmt.Errorf("%w: argon2id time must be 1-%d", entities.ErrUnsupportedVault, models.MaxKDFTime)
+		case params.Memory == 0 || params.Memory > models.MaxKDFMemory:
+			return fmt.Errorf("%w: argon2id memory must be 1-%d KiB", entities.ErrUnsupportedVault, models.MaxKDFMemory)
+		case params.Threads == 0:
+			return fmt.Errorf("%w: argon2id threads must be positive", entities.ErrUnsupportedVault)
+		}
+	default:
+		return fmt.Errorf("%w: kdf %s", entities.ErrUnsupportedVault, k)
+	}
+
+	return nil
+}
+
+// Derive key of keyLen size from password and salt
+func (k KDF) deriveKey(password string, salt []byte, params KDFParams) ([]byte, error) {
+	if err := k.validate(params); err != nil {
+		return nil, fmt.Errorf("deriveKey(): %w", err)
+	}
+
+	if k == KDFPBKDF2 {
+		return pbkdf2.Key([]byte(password), salt, int(params.Time), keyLen, sha256.New), nil
+	}
+
+	return argon2.IDKey([]byte(password), salt, params.Time, params.Memory, params.Threads, keyLen), nil
+}
@@ -0,0 +1,66 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/utils"
+)
+
+// Size of random data key used by RecordCipher
+const DataKeyLen = keyLen
+
+// AES-256-GCM with a ready key, for encrypting many small records
+// without running KDF for each of them
+type RecordCipher struct {
+	aead cipher.AEAD
+}
+
+func NewRecordCipher(key []byte) (*RecordCipher, error) {
+	if len(key) != DataKeyLen {
+		return nil, fmt.Errorf("NewRecordCipher(): bad key length %d", len(key))
+	}
+
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RecordCipher{aead: aead}, nil
+}
+
+// Size added by Seal to plaintext: nonce and authentication tag
+func (c RecordCipher) Overhead() int {
+	return c.aead.NonceSize() + c.aead.Overhead()
+}
+
+// Generate random data key
+func NewDataKey() ([]byte, error) {
+	return utils.GenerateRandom(DataKeyLen)
+}
+
+// Encrypt plaintext, binding it to additional data. Returns nonce + ciphertext
+func (c RecordCipher) Seal(plaintext []byte, ad []byte) ([]byte, error) {
+	nonce, err := utils.GenerateRandom(c.aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, plaintext, ad), nil
+}
+
+// Decrypt output of Seal with the same additional data
+func (c RecordCipher) Open(sealed []byte, ad []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize()+c.aead.Overhead() {
+		return nil, entities.ErrBadEncryption
+	}
+
+	nonce, body := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+
+	plaintext, err := c.aead.Open(nil, nonce, body, ad)
+	if err != nil {
+		return nil, entities.ErrBadEncryption
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,217 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/utils"
+)
+
+// Vault container layout (all integers are big-endian):
+//
+//	offset  size  field
+//	0       4     magic "GPHK"
+//	4       1     format version
+//	5       1     cipher (1 = AES-256-GCM)
+//	6       1     kdf (1 = PBKDF2-SHA256, 2 = Argon2id)
+//	7       1     kdf threads
+//	8       4     kdf time (iterations for PBKDF2)
+//	12      4     kdf memory, KiB
+//	16      1     salt length N
+//	17      N     salt
+//	17+N    ...   nonce + GCM ciphertext
+//
+// Whole header is authenticated as GCM additional data.
+// Files without magic are treated as legacy KeeperEncrypter output.
+
+// Cipher identifier, stored in vault header
+type Cipher uint8
+
+const (
+	CipherAES256GCM Cipher = 1
+)
+
+const (
+	VaultVersion = 1
+
+	vaultSaltLen     = 16
+	vaultFixedHeader = 17
+)
+
+var vaultMagic = []byte("GPHK")
+
+var _ Encrypter = (*VaultEncrypter)(nil)
+
+// Self-describing vault header
+type Header struct {
+	Version uint8
+	Cipher  Cipher
+	KDF     KDF
+	Params  KDFParams
+	Salt    []byte
+}
+
+// Encrypter producing versioned vault containers.
+// Decrypt also accepts legacy headerless data.
+type VaultEncrypter struct {
+	params KDFParams
+	legacy *KeeperEncrypter
+}
+
+func NewVaultEncrypter(params KDFParams) *VaultEncrypter {
+	return &VaultEncrypter{
+		params: params,
+		legacy: NewKeeperEncrypter(),
+	}
+}
+
+func (e VaultEncrypter) Encrypt(plaintext []byte, password string) ([]byte, error) {
+	salt, err := utils.GenerateRandom(vaultSaltLen)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := Header{
+		Version: VaultVersion,
+		Cipher:  CipherAES256GCM,
+		KDF:     KDFArgon2id,
+		Params:  e.params,
+		Salt:    salt,
+	}
+
+	key, err := hdr.KDF.deriveKey(password, hdr.Salt, hdr.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	GCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := utils.GenerateRandom(GCM.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	header := hdr.marshal()
+
+	encrypted := make([]byte, 0, len(header)+len(nonce)+len(plaintext)+GCM.Overhead())
+	encrypted = append(encrypted, header...)
+	encrypted = append(encrypted, nonce...)
+	encrypted = GCM.Seal(encrypted, nonce, plaintext, header)
+
+	return encrypted, nil
+}
+
+func (e VaultEncrypter) Decrypt(encrypted []byte, password string) ([]byte, error) {
+	if !IsVault(encrypted) {
+		return e.legacy.Decrypt(encrypted, password)
+	}
+
+	hdr, headerLen, err := ParseHeader(encrypted)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := hdr.KDF.deriveKey(password, hdr.Salt, hdr.Params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+
+	GCM, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header, body := encrypted[:headerLen], encrypted[headerLen:]
+	if len(body) < GCM.NonceSize()+GCM.Overhead() {
+		return nil, entities.ErrBadEncryption
+	}
+
+	nonce, body := body[:GCM.NonceSize()], body[GCM.NonceSize():]
+
+	decrypted, err := GCM.Open(nil, nonce, body, header)
+	if err != nil {
+		// GCM does not distinguish wrong key from tampered data
+		return nil, entities.ErrBadPassword
+	}
+
+	return decrypted, nil
+}
+
+// Whether data starts with vault magic
+func IsVault(data []byte) bool {
+	return bytes.HasPrefix(data, vaultMagic)
+}
+
+// Parse vault header, returns header and its size in bytes
+func ParseHeader(data []byte) (*Header, int, error) {
+	if !IsVault(data) {
+		return nil, 0, entities.ErrBadVaultFormat
+	}
+
+	if len(data) < vaultFixedHeader {
+		return nil, 0, entities.ErrBadVaultFormat
+	}
+
+	hdr := &Header{
+		Version: data[4],
+		Cipher:  Cipher(data[5]),
+		KDF:     KDF(data[6]),
+		Params: KDFParams{
+			Threads: data[7],
+			Time:    binary.BigEndian.Uint32(data[8:12]),
+			Memory:  binary.BigEndian.Uint32(data[12:16]),
+		},
+	}
+
+	if hdr.Version != VaultVersion {
+		return nil, 0, fmt.Errorf("%w: version %d", entities.ErrUnsupportedVault, hdr.Version)
+	}
+
+	if hdr.Cipher != CipherAES256GCM {
+		return nil, 0, fmt.Errorf("%w: cipher %d", entities.ErrUnsupportedVault, hdr.Cipher)
+	}
+
+	if err := hdr.KDF.validate(hdr.Params); err != nil {
+		return nil, 0, err
+	}
+
+	saltLen := int(data[16])
+	headerLen := vaultFixedHeader + saltLen
+	if len(data) < headerLen {
+		return nil, 0, entities.ErrBadVaultFormat
+	}
+
+	hdr.Salt = data[vaultFixedHeader:headerLen]
+
+	return hdr, headerLen, nil
+}
+
+func (h Header) marshal() []byte {
+	buf := make([]byte, vaultFixedHeader, vaultFixedHeader+len(h.Salt))
+
+	copy(buf[0:4], vaultMagic)
+	buf[4] = h.Version
+	buf[5] = byte(h.Cipher)
+	buf[6] = byte(h.KDF)
+	buf[7] = h.Params.Threads
+	binary.BigEndian.PutUint32(buf[8:12], h.Params.Time)
+	binary.BigEndian.PutUint32(buf[12:16], h.Params.Memory)
+	buf[16] = byte(len(h.Salt))
+
+	return append(buf, h.Salt...)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	AESBlock, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(AESBlock)
+}
@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"gophkeeper/internal/keeper/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testKDFParams = KDFParams{Time: 1, Memory: 1024, Threads: 1}
+
+func TestVaultEncrypter(t *testing.T) {
+	encrypter := NewVaultEncrypter(testKDFParams)
+
+	password := "password"
+	plaintext := []byte{0x55, 0x44, 0x33, 0x22}
+
+	encrypted, err := encrypter.Encrypt(plaintext, password)
+	require.NoError(t, err)
+	assert.True(t, IsVault(encrypted))
+
+	t.Run("Header", func(t *testing.T) {
+		hdr, _, err := ParseHeader(encrypted)
+		require.NoError(t, err)
+
+		assert.Equal(t, uint8(VaultVersion), hdr.Version)
+		assert.Equal(t, CipherAES256GCM, hdr.Cipher)
+		assert.Equal(t, KDFArgon2id, hdr.KDF)
+		assert.Equal(t, testKDFParams, hdr.Params)
+		assert.Len(t, hdr.Salt, vaultSaltLen)
+	})
+
+	t.Run("Decrypt", func(t *testing.T) {
+		decrypted, err := encrypter.Decrypt(encrypted, password)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("Decrypt with other params", func(t *testing.T) {
+		// params are read from header, not from encrypter
+		other := NewVaultEncrypter(DefaultKDFParams())
+
+		decrypted, err := other.Decrypt(encrypted, password)
+		require.NoError(t, err)
+		assert.Equal(t, plaintext, decrypted)
+	})
+
+	t.Run("Bad password", func(t *testing.T) {
+		_, err := encrypter.Decrypt(encrypted, "wrong")
+		assert.ErrorIs(t, err, entities.ErrBadPassword)
+	})
+
+	t.Run("Tampered header", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[vaultFixedHeader]++ // first salt byte
+
+		_, err := encrypter.Decrypt(tampered, password)
+		assert.ErrorIs(t, err, entities.ErrBadPassword)
+	})
+
+	t.Run("Unsupported version", func(t *testing.T) {
+		tampered := append([]byte{}, encrypted...)
+		tampered[4] = VaultVersion + 1
+
+		_, err := encrypter.Decrypt(tampered, password)
+		assert.ErrorIs(t, err, entities.ErrUnsupportedVault)
+	})
+
+	t.Run("Oversized kdf params", func(t *testing.T) {
+		for name, offset := range map[string]int{"time": 8, "memory": 12} {
+			tampered := append([]byte{}, encrypted...)
+			binary.BigEndian.PutUint32(tampered[offset:offset+4], math.MaxUint32)
+
+			_, _, err := ParseHeader(tampered)
+			assert.ErrorIs(t, err, entities.ErrUnsupportedVault, name)
+
+			_, err = encrypter.Decrypt(tampered, password)
+			assert.ErrorIs(t, err, entities.ErrUnsupportedVault, name)
+		}
+	})
+
+	t.Run("Truncated", func(t *testing.T) {
+		_, err := encrypter.Decrypt(encrypted[:vaultFixedHeader+2], password)
+		assert.ErrorIs(t, err, entities.ErrBadVaultFormat)
+	})
+}
+
+func TestVaultEncrypterLegacy(t *testing.T) {
+	password := "password"
+	plaintext := []byte("legacy vault")
+
+	legacy, err := NewKeeperEncrypter().Encrypt(plaintext, password)
+	require.NoError(t, err)
+	assert.False(t, IsVault(legacy))
+
+	decrypted, err := NewVaultEncrypter(testKDFParams).Decrypt(legacy, password)
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, decrypted)
+}
@@ -2,6 +2,9 @@ package entities
 
 import (
 	"errors"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"time"
 )
 
 var (
@@ -10,9 +13,63 @@ var (
 	ErrSecretNotFound    = errors.New("secret not found in storage")
 	ErrBadFileStorePath  = errors.New("file at store path was not found")
 	ErrBadPassword       = errors.New("incorrect password")
+	ErrEmptyPassword     = errors.New("password must not be empty")
+	ErrPasswordMismatch  = errors.New("passwords do not match")
+	ErrNotSupported      = errors.New("operation not supported by storage")
 	ErrBadEncryption     = errors.New("failed to decrypt file")
+	ErrBadVaultFormat    = errors.New("malformed vault header")
+	ErrUnsupportedVault  = errors.New("unsupported vault format")
+	ErrVaultCorrupted    = errors.New("vault contents are damaged")
+	ErrNoBackups         = errors.New("no valid backups found")
+	ErrVaultInUse        = errors.New("vault is in use by another process")
+	ErrVaultChanged      = errors.New("vault file changed on disk since it was loaded")
+	ErrReadOnly          = errors.New("storage is opened read-only")
 	ErrServerUnavailable = errors.New("server unavailable")
 	ErrUnauthenticated   = errors.New("failed to authenticate")
 	ErrAlreadyExist      = errors.New("user already exists")
+	ErrConflict          = errors.New("secret was changed on another device")
+	ErrRevisionNotFound  = errors.New("secret revision not found")
+	ErrNotInTrash        = errors.New("secret not found in trash")
+	ErrUnknownFormat     = errors.New("unknown import format")
+	ErrEncryptedExport   = errors.New("encrypted exports are not supported, export without encryption")
+	ErrFileExists        = errors.New("file already exists")
+	ErrAuthDowngrade     = errors.New("server asks for weaker login than account uses, refusing to send credentials")
+	ErrServerImpostor    = errors.New("server failed to prove it knows account, it may be an impostor")
+	ErrSessionRevoked    = errors.New("session was ended on server, log in again")
+	ErrSessionNotFound   = errors.New("session not found")
+	ErrTOTPRequired      = errors.New("account requires one-time code")
+	ErrBadTOTPCode       = errors.New("wrong one-time code")
+	ErrLoginExpired      = errors.New("login took too long or too many wrong codes, log in again")
+	ErrTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+	ErrLoginLocked       = errors.New("too many failed logins")
 	// ErrNoSubscribers   = errors.New("no clients subscribed")
 )
+
+// Save rejected because secret was changed elsewhere since it was read.
+// Theirs holds current server copy, nil if server did not send it.
+type ConflictError struct {
+	Theirs *models.Secret
+}
+
+func (e *ConflictError) Error() string {
+	return ErrConflict.Error()
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// Server refuses logins for a while after too many failures of login or from this address
+type LoginLockedError struct {
+	RetryAfter time.Duration
+	Until      time.Time // local time lockout ends
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%v, try again in %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
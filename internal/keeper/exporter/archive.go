@@ -0,0 +1,141 @@
+package exporter
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Document inside archive
+const manifestName = "manifest.json"
+
+// Write secrets as zip of JSON manifest and one file per blob, encrypted with password.
+// Archive is a vault-format blob, so it carries its own KDF parameters and salt and can be
+// read by any gophkeeper regardless of its settings.
+func WriteArchive(w io.Writer, secrets []*models.Secret, password string, encrypter crypto.Encrypter) error {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	doc := newDocument(make([]*models.Secret, 0, len(secrets)))
+	doc.Files = make(map[uint64]string)
+
+	for _, s := range secrets {
+		secret := *s
+		secret.Payload = nil
+
+		if s.Blob != nil {
+			name := archiveFileName(s)
+
+			f, err := zw.Create(name)
+			if err != nil {
+				return fmt.Errorf("WriteArchive(): %w", err)
+			}
+
+			if _, err := f.Write(s.Blob.FileBytes); err != nil {
+				return fmt.Errorf("WriteArchive(): %w", err)
+			}
+
+			secret.Blob = &models.Blob{FileName: s.Blob.FileName}
+			doc.Files[s.ID] = name
+		}
+
+		doc.Secrets = append(doc.Secrets, &secret)
+	}
+
+	f, err := zw.Create(manifestName)
+	if err != nil {
+		return fmt.Errorf("WriteArchive(): %w", err)
+	}
+
+	if err := json.NewEncoder(f).Encode(doc); err != nil {
+		return fmt.Errorf("WriteArchive(): %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("WriteArchive(): %w", err)
+	}
+
+	encrypted, err := encrypter.Encrypt(buf.Bytes(), password)
+	if err != nil {
+		return fmt.Errorf("WriteArchive(): %w", err)
+	}
+
+	if _, err := w.Write(encrypted); err != nil {
+		return fmt.Errorf("WriteArchive(): %w", err)
+	}
+
+	return nil
+}
+
+// Read archive written by WriteArchive, with blob contents restored
+func ReadArchive(r io.Reader, password string) (*Document, error) {
+	encrypted, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("ReadArchive(): %w", err)
+	}
+
+	if !crypto.IsVault(encrypted) {
+		return nil, fmt.Errorf("%w: not a gophkeeper archive", entities.ErrUnknownFormat)
+	}
+
+	// Parameters are read from archive header, configured ones do not matter
+	data, err := crypto.NewVaultEncrypter(crypto.DefaultKDFParams()).Decrypt(encrypted, password)
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed archive: %w", err)
+	}
+
+	manifest, err := zr.Open(manifestName)
+	if err != nil {
+		return nil, fmt.Errorf("malformed archive: %w", err)
+	}
+	defer manifest.Close()
+
+	doc, err := ReadJSON(manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, s := range doc.Secrets {
+		name, ok := doc.Files[s.ID]
+		if !ok || s.Blob == nil {
+			continue
+		}
+
+		f, err := zr.Open(name)
+		if err != nil {
+			return nil, fmt.Errorf("malformed archive: file of %q: %w", s.Title, err)
+		}
+
+		s.Blob.FileBytes, err = io.ReadAll(f)
+		f.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("malformed archive: file of %q: %w", s.Title, err)
+		}
+	}
+
+	return doc, nil
+}
+
+// Path of blob in archive, unique by secret ID and keeping original file name
+func archiveFileName(s *models.Secret) string {
+	name := path.Base(strings.ReplaceAll(s.Blob.FileName, "\\", "/"))
+	if name == "." || name == "/" || name == ".." {
+		name = "file"
+	}
+
+	return path.Join("files", strconv.FormatUint(s.ID, 10), name)
+}
@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"io"
+	"strconv"
+	"time"
+)
+
+// Columns of CSV export. Names match ones recognized by CSV importer.
+var csvHeader = []string{
+	"type", "title", "login", "password", "text", "card number", "exp month", "exp year", "cvv",
+	"file name", "metadata", "created at", "updated at", "folder", "tags", "otpauth",
+}
+
+// Write secrets as CSV with header row. Only names of files are written, not their contents.
+func WriteCSV(w io.Writer, secrets []*models.Secret) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(csvHeader); err != nil {
+		return fmt.Errorf("WriteCSV(): %w", err)
+	}
+
+	for _, s := range secrets {
+		if err := cw.Write(csvRecord(s)); err != nil {
+			return fmt.Errorf("WriteCSV(): %w", err)
+		}
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("WriteCSV(): %w", err)
+	}
+
+	return nil
+}
+
+func csvRecord(s *models.Secret) []string {
+	record := make([]string, len(csvHeader))
+	record[0] = s.SecretType
+	record[1] = s.Title
+
+	switch {
+	case s.Creds != nil:
+		record[2], record[3] = s.Creds.Login, s.Creds.Password
+	case s.Text != nil:
+		record[4] = s.Text.Content
+	case s.Card != nil:
+		record[5] = s.Card.Number
+		record[6] = formatUint(s.Card.ExpMonth)
+		record[7] = formatUint(s.Card.ExpYear)
+		record[8] = formatUint(s.Card.CVV)
+	case s.Blob != nil:
+		record[9] = s.Blob.FileName
+	case s.TOTP != nil:
+		record[15] = s.TOTP.Key().URI()
+	}
+
+	record[10] = s.Metadata
+	record[11] = s.CreatedAt.Format(time.RFC3339)
+	record[12] = s.UpdatedAt.Format(time.RFC3339)
+	record[13] = s.Folder
+	record[14] = s.Tags.String()
+
+	return record
+}
+
+func formatUint(n uint32) string {
+	if n == 0 {
+		return ""
+	}
+
+	return strconv.FormatUint(uint64(n), 10)
+}
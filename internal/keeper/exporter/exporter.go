@@ -0,0 +1,105 @@
+// Writes secrets to portable files: plaintext JSON and CSV, and password-encrypted archive
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+	"io"
+	"strings"
+	"time"
+)
+
+// Value of Document.Format, tells gophkeeper exports from other JSON files
+const documentFormat = "gophkeeper"
+
+// Version of Document layout
+const documentVersion = 1
+
+// Export format
+type Format string
+
+const (
+	FormatJSON    Format = "json"    // plaintext JSON document
+	FormatCSV     Format = "csv"     // plaintext CSV, without file contents
+	FormatArchive Format = "archive" // encrypted archive, see WriteArchive
+)
+
+// Supported formats, in order shown to user
+func Formats() []Format {
+	return []Format{FormatArchive, FormatJSON, FormatCSV}
+}
+
+// Parse format name as typed by user
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatJSON, FormatCSV, FormatArchive:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w %q, use archive, json or csv", entities.ErrUnknownFormat, s)
+	}
+}
+
+// Whether exported secrets are readable without password
+func (f Format) Plaintext() bool {
+	return f != FormatArchive
+}
+
+// Default file extension
+func (f Format) Ext() string {
+	if f == FormatArchive {
+		return ".gpkx"
+	}
+
+	return "." + string(f)
+}
+
+// Exported secrets. Secrets are serialized with models.Secret.MarshalJSON.
+type Document struct {
+	Format     string            `json:"format"`
+	Version    int               `json:"version"`
+	ExportedAt time.Time         `json:"exported_at"`
+	Secrets    []*models.Secret  `json:"secrets"`
+	Files      map[uint64]string `json:"files,omitempty"` // archive only, blob secret ID to file in archive
+}
+
+func newDocument(secrets []*models.Secret) *Document {
+	return &Document{
+		Format:     documentFormat,
+		Version:    documentVersion,
+		ExportedAt: time.Now().UTC(),
+		Secrets:    secrets,
+	}
+}
+
+// Write secrets as indented JSON document, blobs are embedded as base64
+func WriteJSON(w io.Writer, secrets []*models.Secret) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	if err := enc.Encode(newDocument(secrets)); err != nil {
+		return fmt.Errorf("WriteJSON(): %w", err)
+	}
+
+	return nil
+}
+
+// Read JSON document written by WriteJSON
+func ReadJSON(r io.Reader) (*Document, error) {
+	var doc Document
+
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("malformed export: %w", err)
+	}
+
+	if doc.Format != documentFormat {
+		return nil, fmt.Errorf("%w: not a gophkeeper export", entities.ErrUnknownFormat)
+	}
+
+	if doc.Version > documentVersion {
+		return nil, fmt.Errorf("export version %d is newer than supported %d", doc.Version, documentVersion)
+	}
+
+	return &doc, nil
+}
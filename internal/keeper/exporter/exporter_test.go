@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSecrets() []*models.Secret {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	return []*models.Secret{
+		{ID: 1, Title: "mail", SecretType: string(models.CredSecret), Metadata: "work", CreatedAt: created, UpdatedAt: updated,
+			Folder: "work/mail", Tags: models.Tags{"mail", "personal"},
+			Creds: &models.Credentials{Login: "alice", Password: "secret"}},
+		{ID: 2, Title: "visa", SecretType: string(models.CardSecret), CreatedAt: created, UpdatedAt: updated,
+			Card: &models.Card{Number: "4111111111111111", ExpMonth: 7, ExpYear: 2031, CVV: 123}},
+		{ID: 3, Title: "photo", SecretType: string(models.BlobSecret), CreatedAt: created, UpdatedAt: updated,
+			Blob: &models.Blob{FileName: "../me.jpg", FileBytes: []byte{0xff, 0xd8, 0x00}}},
+		{ID: 4, Title: "github", SecretType: string(models.TOTPSecret), CreatedAt: created, UpdatedAt: updated,
+			TOTP: &models.TOTP{Secret: "JBSWY3DPEHPK3PXP", Issuer: "GitHub", Account: "alice"}},
+	}
+}
+
+func TestJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, testSecrets()))
+
+	doc, err := ReadJSON(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, documentVersion, doc.Version)
+	assert.Equal(t, testSecrets(), doc.Secrets)
+
+	_, err = ReadJSON(bytes.NewBufferString(`{"encrypted": false, "items": []}`))
+	assert.ErrorIs(t, err, entities.ErrUnknownFormat)
+}
+
+func TestCSV(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, testSecrets()))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 5)
+
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, []string{"credential", "mail", "alice", "secret", "", "", "", "", "", "", "work", "2024-01-01T00:00:00Z", "2024-02-01T00:00:00Z", "work/mail", "mail, personal", ""}, records[1])
+	assert.Equal(t, []string{"4111111111111111", "7", "2031", "123"}, records[2][5:9])
+	assert.Equal(t, "../me.jpg", records[3][9])
+	assert.Equal(t, "otpauth://totp/GitHub:alice?algorithm=SHA1&digits=6&issuer=GitHub&period=30&secret=JBSWY3DPEHPK3PXP", records[4][15])
+}
+
+func TestArchive(t *testing.T) {
+	encrypter := crypto.NewVaultEncrypter(crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1})
+
+	var buf bytes.Buffer
+	require.NoError(t, WriteArchive(&buf, testSecrets(), "archive password", encrypter))
+	assert.NotContains(t, buf.String(), "alice")
+
+	_, err := ReadArchive(bytes.NewReader(buf.Bytes()), "wrong")
+	assert.ErrorIs(t, err, entities.ErrBadPassword)
+
+	doc, err := ReadArchive(bytes.NewReader(buf.Bytes()), "archive password")
+	require.NoError(t, err)
+
+	assert.Equal(t, testSecrets(), doc.Secrets)
+	assert.Equal(t, map[uint64]string{3: "files/3/me.jpg"}, doc.Files)
+
+	_, err = ReadArchive(bytes.NewBufferString("PK\x03\x04"), "archive password")
+	assert.ErrorIs(t, err, entities.ErrUnknownFormat)
+}
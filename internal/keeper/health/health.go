@@ -0,0 +1,219 @@
+// Security report over secrets of storage: weak, reused, old and breached passwords and expired cards
+package health
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/passgen"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Kind of problem found, in order of importance
+type Kind string
+
+const (
+	Breached Kind = "breached"
+	Weak     Kind = "weak"
+	Reused   Kind = "reused"
+	Expired  Kind = "expired card"
+	Old      Kind = "old"
+)
+
+var kinds = []Kind{Breached, Weak, Reused, Expired, Old}
+
+// Default number of months after which password is reported as old
+const DefaultMaxAge = 12
+
+// How many titles of secrets with the same password are listed
+const maxReusedTitles = 3
+
+// Source of breached passwords, e.g. HIBP
+type BreachChecker interface {
+	Count(password string) (int, error)
+}
+
+type Options struct {
+	MaxAge   int           // months since last change, 0 disables check of old passwords
+	MinScore passgen.Score // passwords scored lower are weak
+	Breaches BreachChecker // nil disables check of breached passwords
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxAge:   DefaultMaxAge,
+		MinScore: passgen.GoodScore,
+	}
+}
+
+// Problem with secret
+type Issue struct {
+	SecretID uint64
+	Title    string
+	Kind     Kind
+	Detail   string
+}
+
+type Report struct {
+	Checked int // number of credentials and cards
+	Issues  []Issue
+}
+
+// Number of issues of kind
+func (r Report) Count(kind Kind) int {
+	n := 0
+	for _, i := range r.Issues {
+		if i.Kind == kind {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Counts of found issues, e.g. "1 breached, 3 weak, 2 reused"
+func (r Report) Summary() string {
+	var parts []string
+	for _, kind := range kinds {
+		if n := r.Count(kind); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, kind))
+		}
+	}
+
+	if len(parts) == 0 {
+		return "no issues found"
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Check secrets of storage
+func Check(ctx context.Context, strg storage.Storage, opts Options, now time.Time) (Report, error) {
+	secrets, err := strg.GetAll(ctx)
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	return CheckSecrets(secrets, opts, now)
+}
+
+// Issues of credentials and cards, most important first
+func CheckSecrets(secrets []*models.Secret, opts Options, now time.Time) (Report, error) {
+	var report Report
+
+	add := func(s *models.Secret, kind Kind, detail string) {
+		report.Issues = append(report.Issues, Issue{SecretID: s.ID, Title: s.Title, Kind: kind, Detail: detail})
+	}
+
+	byPassword := make(map[string][]*models.Secret)
+
+	for _, s := range secrets {
+		switch {
+		case s.Card != nil:
+			report.Checked++
+			if expired(s.Card, now) {
+				add(s, Expired, fmt.Sprintf("expired %02d/%d", s.Card.ExpMonth, expYear(s.Card)))
+			}
+
+		case s.Creds != nil && s.Creds.Password != "":
+			report.Checked++
+			password := s.Creds.Password
+			byPassword[password] = append(byPassword[password], s)
+
+			if opts.Breaches != nil {
+				count, err := opts.Breaches.Count(password)
+				if err != nil {
+					return Report{}, fmt.Errorf("failed to check breaches: %w", err)
+				}
+				if count > 0 {
+					add(s, Breached, fmt.Sprintf("seen %d times in data breaches", count))
+				}
+			}
+
+			if e := passgen.Evaluate(password); e.Score < opts.MinScore {
+				detail := e.Score.String()
+				if e.Pattern != "" {
+					detail += ", " + e.Pattern
+				}
+				add(s, Weak, detail)
+			}
+
+			if months := monthsSince(s.UpdatedAt, now); opts.MaxAge > 0 && months >= opts.MaxAge {
+				add(s, Old, fmt.Sprintf("not changed for %d months", months))
+			}
+		}
+	}
+
+	for _, same := range byPassword {
+		if len(same) < 2 {
+			continue
+		}
+
+		for _, s := range same {
+			add(s, Reused, "same password as "+otherTitles(s, same))
+		}
+	}
+
+	slices.SortStableFunc(report.Issues, func(a, b Issue) int {
+		return cmp.Or(
+			cmp.Compare(slices.Index(kinds, a.Kind), slices.Index(kinds, b.Kind)),
+			strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title)),
+			cmp.Compare(a.SecretID, b.SecretID),
+		)
+	})
+
+	return report, nil
+}
+
+// Card is valid through the last day of its expiration month
+func expired(card *models.Card, now time.Time) bool {
+	if card.ExpYear == 0 || card.ExpMonth == 0 {
+		return false
+	}
+
+	return expYear(card)*12+int(card.ExpMonth) < now.Year()*12+int(now.Month())
+}
+
+// Four-digit year, cards store "27" as well as "2027"
+func expYear(card *models.Card) int {
+	if card.ExpYear < 100 {
+		return 2000 + int(card.ExpYear)
+	}
+
+	return int(card.ExpYear)
+}
+
+// Whole months passed since t
+func monthsSince(t, now time.Time) int {
+	if t.IsZero() {
+		return 0
+	}
+
+	months := (now.Year()-t.Year())*12 + int(now.Month()) - int(t.Month())
+	if now.Day() < t.Day() {
+		months--
+	}
+
+	return max(months, 0)
+}
+
+// Titles of other secrets sharing password, e.g. "Gmail, Work and 2 more"
+func otherTitles(s *models.Secret, same []*models.Secret) string {
+	var titles []string
+	for _, other := range same {
+		if other != s {
+			titles = append(titles, other.Title)
+		}
+	}
+	slices.Sort(titles)
+
+	if len(titles) > maxReusedTitles {
+		return fmt.Sprintf("%s and %d more", strings.Join(titles[:maxReusedTitles], ", "), len(titles)-maxReusedTitles)
+	}
+
+	return strings.Join(titles, ", ")
+}
@@ -0,0 +1,113 @@
+package health
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type breaches map[string]int
+
+func (b breaches) Count(password string) (int, error) {
+	if password == "broken" {
+		return 0, errors.New("read failed")
+	}
+
+	return b[password], nil
+}
+
+func creds(id uint64, title, password string, updated time.Time) *models.Secret {
+	return &models.Secret{
+		ID:         id,
+		Title:      title,
+		SecretType: string(models.CredSecret),
+		UpdatedAt:  updated,
+		Creds:      &models.Credentials{Login: "john", Password: password},
+	}
+}
+
+func card(id uint64, title string, year, month uint32) *models.Secret {
+	return &models.Secret{
+		ID:         id,
+		Title:      title,
+		SecretType: string(models.CardSecret),
+		Card:       &models.Card{Number: "4111111111111111", ExpYear: year, ExpMonth: month},
+	}
+}
+
+func TestCheckSecrets(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+	strong := "k#8Vq!zR2m@xP5"
+
+	secrets := []*models.Secret{
+		creds(1, "Gmail", "password1", now),
+		creds(2, "Work", strong, now),
+		creds(3, "Bank", strong, now),
+		creds(4, "Forum", "Tr0ub4dor&3-horse-staple", now.AddDate(-2, 0, 0)),
+		creds(5, "Empty", "", now),
+		card(6, "Visa", 26, 2),
+		card(7, "Master", 2026, 3),
+		card(8, "Old card", 2025, 12),
+		{ID: 9, Title: "Note", SecretType: string(models.TextSecret), Text: &models.Text{Content: "password1"}},
+	}
+
+	opts := DefaultOptions()
+	opts.Breaches = breaches{"password1": 2254650}
+
+	report, err := CheckSecrets(secrets, opts, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, 7, report.Checked)
+	assert.Equal(t, []Issue{
+		{SecretID: 1, Title: "Gmail", Kind: Breached, Detail: "seen 2254650 times in data breaches"},
+		{SecretID: 1, Title: "Gmail", Kind: Weak, Detail: "too guessable, dictionary"},
+		{SecretID: 3, Title: "Bank", Kind: Reused, Detail: "same password as Work"},
+		{SecretID: 2, Title: "Work", Kind: Reused, Detail: "same password as Bank"},
+		{SecretID: 8, Title: "Old card", Kind: Expired, Detail: "expired 12/2025"},
+		{SecretID: 6, Title: "Visa", Kind: Expired, Detail: "expired 02/2026"},
+		{SecretID: 4, Title: "Forum", Kind: Old, Detail: "not changed for 24 months"},
+	}, report.Issues)
+
+	assert.Equal(t, 2, report.Count(Reused))
+	assert.Equal(t, "1 breached, 1 weak, 2 reused, 2 expired card, 1 old", report.Summary())
+}
+
+func TestCheckSecrets_Options(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 12, 0, 0, 0, time.UTC)
+	secrets := []*models.Secret{creds(1, "Forum", "Tr0ub4dor&3-horse-staple", now.AddDate(-5, 0, 0))}
+
+	report, err := CheckSecrets(secrets, Options{MinScore: 0}, now)
+	require.NoError(t, err)
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, "no issues found", report.Summary())
+
+	_, err = CheckSecrets([]*models.Secret{creds(1, "Forum", "broken", now)}, Options{Breaches: breaches{}}, now)
+	assert.ErrorContains(t, err, "read failed")
+}
+
+func TestOtherTitles(t *testing.T) {
+	same := []*models.Secret{
+		creds(1, "e", "x", time.Time{}),
+		creds(2, "d", "x", time.Time{}),
+		creds(3, "c", "x", time.Time{}),
+		creds(4, "b", "x", time.Time{}),
+		creds(5, "a", "x", time.Time{}),
+	}
+
+	assert.Equal(t, "a, b, c and 1 more", otherTitles(same[1], same))
+	assert.Equal(t, "a, b, d", otherTitles(same[2], same[1:]))
+}
+
+func TestMonthsSince(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, monthsSince(time.Time{}, now))
+	assert.Equal(t, 0, monthsSince(now.AddDate(0, 0, 1), now))
+	assert.Equal(t, 11, monthsSince(time.Date(2025, time.March, 16, 0, 0, 0, 0, time.UTC), now))
+	assert.Equal(t, 12, monthsSince(time.Date(2025, time.March, 15, 0, 0, 0, 0, time.UTC), now))
+}
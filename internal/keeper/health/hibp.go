@@ -0,0 +1,181 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var ErrBadHIBPLine = errors.New("malformed line of HIBP file, expected HASH:COUNT")
+
+// Offline copy of Have I Been Pwned passwords, as saved by PwnedPasswordsDownloader: either
+// directory of range files named by 5 hex characters of SHA-1 prefix with SUFFIX:COUNT lines,
+// or single file of HASH:COUNT lines ordered by hash. Passwords never leave the machine.
+type HIBP struct {
+	dir  string
+	file *os.File
+	size int64
+}
+
+func OpenHIBP(path string) (*HIBP, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HIBP data: %w", err)
+	}
+
+	if info.IsDir() {
+		return &HIBP{dir: path}, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open HIBP data: %w", err)
+	}
+
+	return &HIBP{file: f, size: info.Size()}, nil
+}
+
+func (h *HIBP) Close() error {
+	if h.file == nil {
+		return nil
+	}
+
+	return h.file.Close()
+}
+
+// How many times password was seen in breaches, 0 when never
+func (h *HIBP) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	if h.file != nil {
+		return h.search(hash)
+	}
+
+	return h.lookupRange(hash[:5], hash[5:])
+}
+
+// Scan range file of prefix for suffix
+func (h *HIBP) lookupRange(prefix, suffix string) (int, error) {
+	var (
+		f   *os.File
+		err error
+	)
+	for _, name := range []string{prefix + ".txt", prefix, strings.ToLower(prefix) + ".txt", strings.ToLower(prefix)} {
+		f, err = os.Open(filepath.Join(h.dir, name))
+		if err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil // range was not downloaded
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to open HIBP range %s: %w", prefix, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hash, count, err := parseLine(scanner.Text())
+		if err != nil {
+			return 0, err
+		}
+		if strings.EqualFold(hash, suffix) {
+			return count, nil
+		}
+	}
+
+	return 0, scanner.Err()
+}
+
+// Binary search of ordered file by byte offsets, lo is always start of line
+func (h *HIBP) search(hash string) (int, error) {
+	lo, hi := int64(0), h.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+
+		start, line, err := h.lineFrom(mid)
+		if errors.Is(err, io.EOF) || (err == nil && start >= hi) {
+			hi = mid
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		lineHash, count, err := parseLine(line)
+		if err != nil {
+			return 0, err
+		}
+
+		switch strings.Compare(strings.ToUpper(lineHash), hash) {
+		case 0:
+			return count, nil
+		case -1:
+			lo = start + int64(len(line)) + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return 0, nil
+}
+
+// First line starting at or after offset, with its start
+func (h *HIBP) lineFrom(offset int64) (int64, string, error) {
+	const maxLine = 128
+
+	from := max(offset-1, 0)
+	buf := make([]byte, 2*maxLine+1)
+	n, err := h.file.ReadAt(buf, from)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, "", fmt.Errorf("failed to read HIBP file: %w", err)
+	}
+	buf = buf[:n]
+
+	start := 0
+	if offset > 0 {
+		i := bytes.IndexByte(buf, '\n')
+		if i < 0 {
+			return 0, "", io.EOF
+		}
+		start = i + 1
+	}
+	if start >= len(buf) {
+		return 0, "", io.EOF
+	}
+
+	line := buf[start:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	} else if from+int64(n) < h.size {
+		return 0, "", ErrBadHIBPLine // longer than any HASH:COUNT line
+	}
+
+	return from + int64(start), string(line), nil
+}
+
+func parseLine(line string) (string, int, error) {
+	line = strings.TrimRight(line, "\r")
+
+	hash, count, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", 0, ErrBadHIBPLine
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(count))
+	if err != nil {
+		return "", 0, ErrBadHIBPLine
+	}
+
+	return hash, n, nil
+}
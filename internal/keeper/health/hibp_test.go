@@ -0,0 +1,115 @@
+package health
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+var pwned = map[string]int{
+	"password":  9545824,
+	"123456":    37359195,
+	"qwerty":    10556095,
+	"letmein":   619000,
+	"iloveyou":  2330100,
+	"monkey123": 2000,
+}
+
+func TestHIBP_RangeDir(t *testing.T) {
+	dir := t.TempDir()
+
+	ranges := make(map[string][]string)
+	for password, count := range pwned {
+		hash := sha1Hex(password)
+		ranges[hash[:5]] = append(ranges[hash[:5]], fmt.Sprintf("%s:%d", hash[5:], count))
+	}
+	// Unrelated suffixes around real ones
+	for prefix, lines := range ranges {
+		lines = append(lines, strings.Repeat("0", 35)+":1", strings.Repeat("F", 35)+":3")
+		slices.Sort(lines)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(strings.Join(lines, "\r\n")), 0o600))
+	}
+
+	h, err := OpenHIBP(dir)
+	require.NoError(t, err)
+	defer h.Close()
+
+	for password, count := range pwned {
+		n, err := h.Count(password)
+		require.NoError(t, err)
+		assert.Equal(t, count, n, password)
+	}
+
+	n, err := h.Count("k#8Vq!zR2m@xP5")
+	require.NoError(t, err)
+	assert.Zero(t, n, "range not downloaded")
+}
+
+func TestHIBP_SortedFile(t *testing.T) {
+	var lines []string
+	for password, count := range pwned {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(password), count))
+	}
+	for i := range 200 {
+		lines = append(lines, fmt.Sprintf("%s:%d", sha1Hex(fmt.Sprintf("filler%d", i)), i+1))
+	}
+	lines = append(lines, strings.Repeat("0", 40)+":5", strings.Repeat("F", 40)+":7")
+	slices.Sort(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords-sha1-ordered-by-hash.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600))
+
+	h, err := OpenHIBP(path)
+	require.NoError(t, err)
+	defer h.Close()
+
+	for password, count := range pwned {
+		n, err := h.Count(password)
+		require.NoError(t, err)
+		assert.Equal(t, count, n, password)
+	}
+	for i := range 200 {
+		n, err := h.Count(fmt.Sprintf("filler%d", i))
+		require.NoError(t, err)
+		assert.Equal(t, i+1, n)
+	}
+
+	// First and last lines
+	for hash, count := range map[string]int{strings.Repeat("0", 40): 5, strings.Repeat("F", 40): 7} {
+		n, err := h.search(hash)
+		require.NoError(t, err)
+		assert.Equal(t, count, n)
+	}
+
+	n, err := h.Count("k#8Vq!zR2m@xP5")
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestHIBP_Errors(t *testing.T) {
+	_, err := OpenHIBP(filepath.Join(t.TempDir(), "missing"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "bad.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not a hash\n"), 0o600))
+
+	h, err := OpenHIBP(path)
+	require.NoError(t, err)
+	defer h.Close()
+
+	_, err = h.Count("password")
+	assert.ErrorIs(t, err, ErrBadHIBPLine)
+}
@@ -0,0 +1,149 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+	"io"
+	"strings"
+	"time"
+)
+
+// Bitwarden item types
+const (
+	bwLogin      = 1
+	bwSecureNote = 2
+	bwCard       = 3
+	bwIdentity   = 4
+)
+
+// Field names of Bitwarden identity, in order of its form
+var bwIdentityFields = []string{
+	"title", "firstName", "middleName", "lastName", "username", "company", "email", "phone",
+	"address1", "address2", "address3", "city", "state", "postalCode", "country",
+	"ssn", "passportNumber", "licenseNumber",
+}
+
+// Imports Bitwarden unencrypted JSON export. Logins become credentials, cards become cards,
+// secure notes and identities become texts. Folders are kept, URIs and custom fields go to metadata.
+type BitwardenImporter struct{}
+
+type bwExport struct {
+	Encrypted bool `json:"encrypted"`
+	Folders   []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"folders"`
+	Items []bwItem `json:"items"`
+}
+
+type bwItem struct {
+	FolderID string `json:"folderId"`
+	Type     int    `json:"type"`
+	Name     string `json:"name"`
+	Notes    string `json:"notes"`
+	Login    *struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+		Totp     string `json:"totp"`
+		URIs     []struct {
+			URI string `json:"uri"`
+		} `json:"uris"`
+	} `json:"login"`
+	Card *struct {
+		CardholderName string `json:"cardholderName"`
+		Brand          string `json:"brand"`
+		Number         string `json:"number"`
+		ExpMonth       string `json:"expMonth"`
+		ExpYear        string `json:"expYear"`
+		Code           string `json:"code"`
+	} `json:"card"`
+	Identity map[string]*string `json:"identity"`
+	Fields   []struct {
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	} `json:"fields"`
+	CreationDate time.Time `json:"creationDate"`
+	RevisionDate time.Time `json:"revisionDate"`
+}
+
+func (imp BitwardenImporter) Parse(r io.Reader) (*Result, error) {
+	var export bwExport
+
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return nil, fmt.Errorf("malformed JSON: %w", err)
+	}
+
+	if export.Encrypted {
+		return nil, entities.ErrEncryptedExport
+	}
+
+	folders := make(map[string]string, len(export.Folders))
+	for _, f := range export.Folders {
+		folders[f.ID] = f.Name
+	}
+
+	result := &Result{}
+
+	for _, item := range export.Items {
+		s, err := imp.convert(item)
+		if err != nil {
+			result.Skipped = append(result.Skipped, Skipped{Title: item.Name, Reason: err.Error()})
+			continue
+		}
+
+		setTimes(s, item.CreationDate, item.RevisionDate)
+		s.Folder = models.CleanFolder(folders[item.FolderID])
+		result.Secrets = append(result.Secrets, s)
+	}
+
+	return result, nil
+}
+
+func (imp BitwardenImporter) convert(item bwItem) (s *models.Secret, err error) {
+	var pairs []string
+	for _, f := range item.Fields {
+		pairs = append(pairs, f.Name, f.Value)
+	}
+
+	switch item.Type {
+	case bwLogin:
+		if item.Login == nil {
+			return nil, fmt.Errorf("login item without login data")
+		}
+
+		uris := make([]string, 0, len(item.Login.URIs))
+		for _, u := range item.Login.URIs {
+			uris = append(uris, u.URI)
+		}
+
+		pairs = append(pairs, "url", strings.Join(uris, " "), "totp", item.Login.Totp, "notes", item.Notes)
+
+		return newCredential(item.Name, item.Login.Username, item.Login.Password, metadata(pairs...)), nil
+	case bwSecureNote:
+		return newText(item.Name, item.Notes, metadata(pairs...)), nil
+	case bwCard:
+		if item.Card == nil {
+			return nil, fmt.Errorf("card item without card data")
+		}
+
+		c := item.Card
+		pairs = append(pairs, "cardholder", c.CardholderName, "brand", c.Brand, "notes", item.Notes)
+
+		return newCard(item.Name, c.Number, c.ExpMonth, c.ExpYear, c.Code, metadata(pairs...)), nil
+	case bwIdentity:
+		var content []string
+		for _, name := range bwIdentityFields {
+			if v := item.Identity[name]; v != nil {
+				content = append(content, name, *v)
+			}
+		}
+
+		content = append(content, "notes", item.Notes)
+
+		return newText(item.Name, metadata(content...), metadata(pairs...)), nil
+	default:
+		return nil, fmt.Errorf("unsupported item type %d", item.Type)
+	}
+}
@@ -0,0 +1,57 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const bitwardenJSON = `{
+	"encrypted": false,
+	"folders": [{"id": "f1", "name": "Personal"}],
+	"items": [
+		{
+			"type": 1, "name": "mail", "folderId": "f1", "notes": "main box",
+			"login": {"username": "alice", "password": "secret", "uris": [{"uri": "https://mail.example.com"}]},
+			"fields": [{"name": "PIN", "value": "1234"}],
+			"creationDate": "2021-05-01T10:00:00.000Z", "revisionDate": "2022-03-01T10:00:00.000Z"
+		},
+		{"type": 2, "name": "wifi", "notes": "guest network"},
+		{
+			"type": 3, "name": "visa",
+			"card": {"cardholderName": "Alice", "brand": "Visa", "number": "4111 1111 1111 1111", "expMonth": "7", "expYear": "2031", "code": "123"}
+		},
+		{"type": 4, "name": "passport", "identity": {"firstName": "Alice", "lastName": "Smith", "passportNumber": "123456", "email": null}},
+		{"type": 5, "name": "ssh"}
+	]
+}`
+
+func TestBitwardenImporter(t *testing.T) {
+	result, err := BitwardenImporter{}.Parse(strings.NewReader(bitwardenJSON))
+	require.NoError(t, err)
+	require.Len(t, result.Secrets, 4)
+
+	mail := result.Secrets[0]
+	assert.Equal(t, &models.Credentials{Login: "alice", Password: "secret"}, mail.Creds)
+	assert.Equal(t, "PIN: 1234\nurl: https://mail.example.com\nnotes: main box", mail.Metadata)
+	assert.Equal(t, "Personal", mail.Folder)
+	assert.Equal(t, 2021, mail.CreatedAt.Year())
+	assert.Equal(t, 2022, mail.UpdatedAt.Year())
+
+	assert.Equal(t, "guest network", result.Secrets[1].Text.Content)
+	assert.Equal(t, &models.Card{Number: "4111111111111111", ExpMonth: 7, ExpYear: 2031, CVV: 123}, result.Secrets[2].Card)
+	assert.Equal(t, "firstName: Alice\nlastName: Smith\npassportNumber: 123456", result.Secrets[3].Text.Content)
+
+	assert.Equal(t, []Skipped{{Title: "ssh", Reason: "unsupported item type 5"}}, result.Skipped)
+	assert.Equal(t, map[models.SecretType]int{models.CredSecret: 1, models.TextSecret: 2, models.CardSecret: 1}, result.Counts())
+}
+
+func TestBitwardenImporterEncrypted(t *testing.T) {
+	_, err := BitwardenImporter{}.Parse(strings.NewReader(`{"encrypted": true, "items": []}`))
+	assert.ErrorIs(t, err, entities.ErrEncryptedExport)
+}
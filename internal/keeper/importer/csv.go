@@ -0,0 +1,204 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"io"
+	"strings"
+)
+
+// Column of CSV export
+type column string
+
+const (
+	colTitle    column = "title"
+	colLogin    column = "login"
+	colPassword column = "password"
+	colURL      column = "url"
+	colNotes    column = "notes"
+	colNumber   column = "number"
+	colExpMonth column = "exp month"
+	colExpYear  column = "exp year"
+	colExpiry   column = "expiry"
+	colCVV      column = "cvv"
+	colType     column = "type"
+	colArchived column = "archived"
+	colOTP      column = "otp"
+	colFolder   column = "folder"
+	colTags     column = "tags"
+)
+
+// Header names recognized for each column, after normalization
+var csvAliases = map[string]column{
+	"title":             colTitle,
+	"name":              colTitle,
+	"account":           colTitle,
+	"login":             colLogin,
+	"username":          colLogin,
+	"user":              colLogin,
+	"user name":         colLogin,
+	"password":          colPassword,
+	"pass":              colPassword,
+	"url":               colURL,
+	"website":           colURL,
+	"uri":               colURL,
+	"login uri":         colURL,
+	"notes":             colNotes,
+	"note":              colNotes,
+	"comment":           colNotes,
+	"comments":          colNotes,
+	"extra":             colNotes,
+	"text":              colNotes,
+	"content":           colNotes,
+	"card number":       colNumber,
+	"number":            colNumber,
+	"cardnumber":        colNumber,
+	"exp month":         colExpMonth,
+	"expiration month":  colExpMonth,
+	"exp year":          colExpYear,
+	"expiration year":   colExpYear,
+	"expiry":            colExpiry,
+	"expiration":        colExpiry,
+	"expiration date":   colExpiry,
+	"cvv":               colCVV,
+	"cvc":               colCVV,
+	"code":              colCVV,
+	"security code":     colCVV,
+	"type":              colType,
+	"archived":          colArchived,
+	"otpauth":           colOTP,
+	"one time password": colOTP,
+	"folder":            colFolder,
+	"group":             colFolder,
+	"grouping":          colFolder,
+	"tags":              colTags,
+	"tag":               colTags,
+	"labels":            colTags,
+}
+
+// Values of type column used by other managers
+var csvTypeAliases = map[string]models.SecretType{
+	"login":             models.CredSecret,
+	"password":          models.CredSecret,
+	"note":              models.TextSecret,
+	"secure note":       models.TextSecret,
+	"credit card":       models.CardSecret,
+	"one time password": models.TOTPSecret,
+}
+
+// Imports CSV with header row. Columns are matched by common names, rows become cards if they
+// have card number, credentials if they have login or password and texts if they have only notes.
+// Folder and tags columns are kept as folder and tags of secret, unknown columns go to metadata.
+type CSVImporter struct {
+	// Row filter, rows for which it returns non-empty reason are skipped
+	skip func(row map[column]string) string
+}
+
+func NewCSVImporter() *CSVImporter {
+	return &CSVImporter{}
+}
+
+func (imp CSVImporter) Parse(r io.Reader) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("malformed CSV: %w", err)
+	}
+
+	columns := make([]column, len(header))
+	for i, name := range header {
+		columns[i] = csvAliases[normalizeHeader(name)]
+	}
+
+	result := &Result{}
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("malformed CSV: %w", err)
+		}
+
+		row := make(map[column]string)
+		var extra []string
+
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+
+			if columns[i] == "" {
+				extra = append(extra, strings.TrimSpace(header[i]), value)
+			} else if _, ok := row[columns[i]]; !ok {
+				row[columns[i]] = value
+			}
+		}
+
+		if imp.skip != nil {
+			if reason := imp.skip(row); reason != "" {
+				result.Skipped = append(result.Skipped, Skipped{Title: row[colTitle], Reason: reason})
+				continue
+			}
+		}
+
+		s, err := convertRow(row, extra)
+		if err != nil {
+			result.Skipped = append(result.Skipped, Skipped{Title: row[colTitle], Reason: err.Error()})
+			continue
+		}
+
+		s.Folder = models.CleanFolder(row[colFolder])
+		s.Tags = models.ParseTags(row[colTags])
+
+		result.Secrets = append(result.Secrets, s)
+	}
+
+	return result, nil
+}
+
+func convertRow(row map[column]string, extra []string) (*models.Secret, error) {
+	title := row[colTitle]
+	meta := metadata(append([]string{"url", row[colURL], "otp", row[colOTP]}, extra...)...)
+
+	t := models.SecretType(strings.ToLower(strings.TrimSpace(row[colType])))
+	if alias, ok := csvTypeAliases[string(t)]; ok {
+		t = alias
+	}
+
+	switch {
+	case t == models.TOTPSecret:
+		return newTOTP(title, row[colOTP], metadata(extra...))
+	case t == models.CardSecret || t == "" && strings.TrimSpace(row[colNumber]) != "":
+		month, year := row[colExpMonth], row[colExpYear]
+		if m, y, ok := strings.Cut(row[colExpiry], "/"); ok && month == "" && year == "" {
+			month, year = m, y
+		}
+
+		return newCard(title, row[colNumber], month, year, row[colCVV], metadata("", meta, "notes", row[colNotes])), nil
+	case t == models.CredSecret || t == "" && (row[colLogin] != "" || row[colPassword] != ""):
+		return newCredential(title, row[colLogin], row[colPassword], metadata("", meta, "notes", row[colNotes])), nil
+	case t == models.TextSecret || t == "" && row[colNotes] != "":
+		return newText(title, row[colNotes], meta), nil
+	case t == "":
+		return nil, fmt.Errorf("empty row")
+	default:
+		return nil, fmt.Errorf("unsupported type %q", row[colType])
+	}
+}
+
+// Lowercase header without surrounding spaces, with underscores and dashes as spaces
+func normalizeHeader(name string) string {
+	name = strings.TrimPrefix(name, "\ufeff")
+	name = strings.NewReplacer("_", " ", "-", " ").Replace(name)
+
+	return strings.ToLower(strings.TrimSpace(name))
+}
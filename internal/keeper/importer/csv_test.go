@@ -0,0 +1,105 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVImporter(t *testing.T) {
+	data := "Name,User_Name,Password,Website,Card Number,Expiry,CVC,Notes,Color\n" +
+		"mail,alice,secret,https://mail.example.com,,,,main box,red\n" +
+		"visa,,,,4111 1111 1111 1111,07/31,123,,\n" +
+		"wifi,,,,,,,guest network,\n" +
+		"blank,,,,,,,,\n"
+
+	result, err := NewCSVImporter().Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, result.Secrets, 3)
+
+	mail := result.Secrets[0]
+	assert.Equal(t, &models.Credentials{Login: "alice", Password: "secret"}, mail.Creds)
+	assert.Equal(t, "url: https://mail.example.com\nColor: red\nnotes: main box", mail.Metadata)
+
+	assert.Equal(t, &models.Card{Number: "4111111111111111", ExpMonth: 7, ExpYear: 2031, CVV: 123}, result.Secrets[1].Card)
+	assert.Equal(t, "guest network", result.Secrets[2].Text.Content)
+	assert.Equal(t, []Skipped{{Title: "blank", Reason: "empty row"}}, result.Skipped)
+}
+
+func TestCSVImporterType(t *testing.T) {
+	data := "type,name,login,notes,otpauth\n" +
+		"note,todo,,buy milk,\n" +
+		"login,empty,,,\n" +
+		"identity,me,,,\n" +
+		"totp,,,,otpauth://totp/GitHub:alice?secret=JBSWY3DPEHPK3PXP&digits=8\n" +
+		"totp,broken,,,otpauth://totp/x?secret=1\n"
+
+	result, err := NewCSVImporter().Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, result.Secrets, 3)
+
+	assert.Equal(t, string(models.TextSecret), result.Secrets[0].SecretType)
+	assert.Equal(t, string(models.CredSecret), result.Secrets[1].SecretType)
+
+	github := result.Secrets[2]
+	assert.Equal(t, "GitHub", github.Title)
+	assert.Equal(t, &models.TOTP{Secret: "JBSWY3DPEHPK3PXP", Issuer: "GitHub", Account: "alice", Digits: 8}, github.TOTP)
+
+	assert.Equal(t, []Skipped{
+		{Title: "me", Reason: `unsupported type "identity"`},
+		{Title: "broken", Reason: "secret is not valid base32"},
+	}, result.Skipped)
+}
+
+func TestOnePasswordImporter(t *testing.T) {
+	data := "Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n" +
+		"mail,https://mail.example.com,alice,secret,otpauth://totp/mail?secret=ABC,false,false,,\n" +
+		"old,,bob,old,,false,true,,\n" +
+		"tagged,,carol,pass,,false,false,\"work, Mail\",\n"
+
+	result, err := NewOnePasswordImporter().Parse(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, result.Secrets, 2)
+
+	assert.Equal(t, "url: https://mail.example.com\notp: otpauth://totp/mail?secret=ABC\nFavorite: false", result.Secrets[0].Metadata)
+	assert.Equal(t, models.Tags{"Mail", "work"}, result.Secrets[1].Tags)
+	assert.Equal(t, []Skipped{{Title: "old", Reason: "archived item"}}, result.Skipped)
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(name string, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	tests := []struct {
+		path   string
+		format Format
+	}{
+		{path: write("kp.xml", ""), format: FormatKeePass},
+		{path: write("bw.json", `{"encrypted": false, "items": []}`), format: FormatBitwarden},
+		{path: write("gk.json", "{\n  \"format\": \"gophkeeper\",\n  \"version\": 1\n}"), format: FormatJSON},
+		{path: write("gk.gpkx", ""), format: FormatArchive},
+		{path: write("op.csv", "Title,Url,Username,Password,OTPAuth,Favorite,Archived,Tags,Notes\n"), format: Format1Password},
+		{path: write("plain.csv", "name,login,password\n"), format: FormatGenericCSV},
+	}
+
+	for _, tt := range tests {
+		format, err := Detect(tt.path)
+		require.NoError(t, err)
+		assert.Equal(t, tt.format, format, tt.path)
+	}
+
+	_, err := Detect(write("vault.kdbx", ""))
+	assert.ErrorIs(t, err, entities.ErrUnknownFormat)
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/exporter"
+	"io"
+)
+
+// Imports gophkeeper JSON export
+type JSONImporter struct{}
+
+func (imp JSONImporter) Parse(r io.Reader) (*Result, error) {
+	doc, err := exporter.ReadJSON(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDocument(doc), nil
+}
+
+// Imports gophkeeper encrypted archive
+type ArchiveImporter struct {
+	Password string
+}
+
+func (imp ArchiveImporter) Parse(r io.Reader) (*Result, error) {
+	if imp.Password == "" {
+		return nil, entities.ErrEmptyPassword
+	}
+
+	doc, err := exporter.ReadArchive(r, imp.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromDocument(doc), nil
+}
+
+// Exported secrets as new ones, keeping their timestamps
+func fromDocument(doc *exporter.Document) *Result {
+	result := &Result{}
+
+	for _, s := range doc.Secrets {
+		if s.Creds == nil && s.Text == nil && s.Card == nil && s.Blob == nil && s.TOTP == nil && s.SSH == nil {
+			result.Skipped = append(result.Skipped, Skipped{Title: s.Title, Reason: "unsupported type " + s.SecretType})
+			continue
+		}
+
+		s.ID = 0
+		s.Revision = 0
+		s.Payload = nil
+		result.Secrets = append(result.Secrets, s)
+	}
+
+	return result
+}
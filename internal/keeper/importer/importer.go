@@ -0,0 +1,297 @@
+// Converts exports of other password managers into secrets
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/exporter"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/totp"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Export format
+type Format string
+
+const (
+	FormatAuto       Format = "auto"      // detect by file name and contents
+	FormatKeePass    Format = "keepass"   // KeePass 2 XML
+	FormatBitwarden  Format = "bitwarden" // Bitwarden unencrypted JSON
+	Format1Password  Format = "1password" // 1Password CSV
+	FormatGenericCSV Format = "csv"       // CSV with header row
+	FormatJSON       Format = "json"      // gophkeeper JSON export
+	FormatArchive    Format = "archive"   // gophkeeper encrypted archive
+)
+
+// Supported formats, in order shown to user
+func Formats() []Format {
+	return []Format{FormatArchive, FormatJSON, FormatKeePass, FormatBitwarden, Format1Password, FormatGenericCSV}
+}
+
+// Entry of export which was not converted
+type Skipped struct {
+	Title  string
+	Reason string
+}
+
+// Secrets converted from export
+type Result struct {
+	Secrets []*models.Secret
+	Skipped []Skipped
+}
+
+// Number of secrets of each type
+func (r Result) Counts() map[models.SecretType]int {
+	counts := make(map[models.SecretType]int)
+	for _, s := range r.Secrets {
+		counts[models.SecretType(s.SecretType)]++
+	}
+
+	return counts
+}
+
+type Importer interface {
+	Parse(r io.Reader) (*Result, error)
+}
+
+// Importer of format. Password is used by encrypted formats only.
+func New(format Format, password string) (Importer, error) {
+	switch format {
+	case FormatArchive:
+		return &ArchiveImporter{Password: password}, nil
+	case FormatJSON:
+		return &JSONImporter{}, nil
+	case FormatKeePass:
+		return &KeePassImporter{}, nil
+	case FormatBitwarden:
+		return &BitwardenImporter{}, nil
+	case Format1Password:
+		return NewOnePasswordImporter(), nil
+	case FormatGenericCSV:
+		return NewCSVImporter(), nil
+	default:
+		return nil, fmt.Errorf("%w %q", entities.ErrUnknownFormat, format)
+	}
+}
+
+// Parse export file, detecting its format if format is empty or auto
+func ParseFile(path string, format Format, password string) (*Result, Format, error) {
+	if format == "" || format == FormatAuto {
+		var err error
+		if format, err = Detect(path); err != nil {
+			return nil, "", err
+		}
+	}
+
+	imp, err := New(format, password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("ParseFile(): %w", err)
+	}
+	defer f.Close()
+
+	result, err := imp.Parse(f)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s export: %w", format, err)
+	}
+
+	return result, format, nil
+}
+
+// Guess format by file extension, JSON and CSV flavours by their beginning
+func Detect(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case exporter.FormatArchive.Ext():
+		return FormatArchive, nil
+	case ".xml":
+		return FormatKeePass, nil
+	case ".json":
+		head, err := readHead(path)
+		if err != nil {
+			return "", err
+		}
+
+		if isGophKeeperJSON(head) {
+			return FormatJSON, nil
+		}
+
+		return FormatBitwarden, nil
+	case ".csv":
+		head, err := readHead(path)
+		if err != nil {
+			return "", err
+		}
+
+		header, _, _ := strings.Cut(head, "\n")
+		if isOnePasswordHeader(header) {
+			return Format1Password, nil
+		}
+
+		return FormatGenericCSV, nil
+	default:
+		return "", fmt.Errorf("%w: can not detect format of %s", entities.ErrUnknownFormat, filepath.Base(path))
+	}
+}
+
+// Beginning of file, enough for its header
+func readHead(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("readHead(): %w", err)
+	}
+	defer f.Close()
+
+	buf := make([]byte, 4096)
+
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("readHead(): %w", err)
+	}
+
+	return string(buf[:n]), nil
+}
+
+// Export written by exporter.WriteJSON, its format field goes first
+func isGophKeeperJSON(head string) bool {
+	dec := json.NewDecoder(strings.NewReader(head))
+
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return false
+	}
+
+	if key, err := dec.Token(); err != nil || key != "format" {
+		return false
+	}
+
+	value, err := dec.Token()
+
+	return err == nil && value == "gophkeeper"
+}
+
+func newSecret(t models.SecretType, title string, metadata string) *models.Secret {
+	now := time.Now()
+
+	if title = strings.TrimSpace(title); title == "" {
+		title = "(untitled)"
+	}
+
+	return &models.Secret{
+		Title:      title,
+		Metadata:   metadata,
+		SecretType: string(t),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+}
+
+func newCredential(title string, login string, password string, metadata string) *models.Secret {
+	s := newSecret(models.CredSecret, title, metadata)
+	s.Creds = &models.Credentials{Login: login, Password: password}
+
+	return s
+}
+
+func newText(title string, content string, metadata string) *models.Secret {
+	s := newSecret(models.TextSecret, title, metadata)
+	s.Text = &models.Text{Content: content}
+
+	return s
+}
+
+func newBlob(title string, fileName string, data []byte, metadata string) *models.Secret {
+	s := newSecret(models.BlobSecret, title, metadata)
+	s.Blob = &models.Blob{FileName: fileName, FileBytes: data}
+
+	return s
+}
+
+// TOTP from otpauth:// URI
+func newTOTP(title string, uri string, metadata string) (*models.Secret, error) {
+	key, err := totp.ParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(title) == "" {
+		title = key.Issuer
+	}
+
+	s := newSecret(models.TOTPSecret, title, metadata)
+	s.TOTP = models.NewTOTP(key)
+
+	return s, nil
+}
+
+// Card from text fields. Two-digit years are taken as 20xx, unparsable numbers are left zero.
+func newCard(title string, number string, month string, year string, cvv string, metadata string) *models.Secret {
+	s := newSecret(models.CardSecret, title, metadata)
+
+	expYear := parseUint(year)
+	if expYear > 0 && expYear < 100 {
+		expYear += 2000
+	}
+
+	s.Card = &models.Card{
+		Number:   strings.ReplaceAll(strings.TrimSpace(number), " ", ""),
+		ExpMonth: parseUint(month),
+		ExpYear:  expYear,
+		CVV:      parseUint(cvv),
+	}
+
+	return s
+}
+
+func parseUint(s string) uint32 {
+	n, err := strconv.ParseUint(strings.TrimSpace(s), 10, 32)
+	if err != nil {
+		return 0
+	}
+
+	return uint32(n)
+}
+
+// Join labelled values into metadata, skipping empty ones
+func metadata(pairs ...string) string {
+	var b bytes.Buffer
+
+	for i := 0; i+1 < len(pairs); i += 2 {
+		value := strings.TrimSpace(pairs[i+1])
+		if value == "" {
+			continue
+		}
+
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+
+		if pairs[i] != "" {
+			b.WriteString(pairs[i] + ": ")
+		}
+		b.WriteString(value)
+	}
+
+	return b.String()
+}
+
+// Set timestamps of secret from export, keeping import time for unknown ones
+func setTimes(s *models.Secret, created time.Time, updated time.Time) {
+	if !created.IsZero() {
+		s.CreatedAt = created
+	}
+
+	if !updated.IsZero() {
+		s.UpdatedAt = updated
+	}
+}
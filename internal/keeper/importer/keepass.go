@@ -0,0 +1,200 @@
+package importer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/xml"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"io"
+	"strings"
+	"time"
+)
+
+// Seconds between 0001-01-01 and Unix epoch, KDBX 4 stores times since the former
+const kdbxEpochOffset = 62135596800
+
+// Imports KeePass 2 XML export. Entries become credentials, or texts if they have only notes.
+// Attachments become separate blob secrets. Group paths become folders, entry tags are kept.
+// Recycle bin and entry history are skipped.
+type KeePassImporter struct{}
+
+type kpFile struct {
+	Meta struct {
+		RecycleBinUUID string     `xml:"RecycleBinUUID"`
+		Binaries       []kpBinary `xml:"Binaries>Binary"`
+	} `xml:"Meta"`
+	Root struct {
+		Groups []kpGroup `xml:"Group"`
+	} `xml:"Root"`
+}
+
+type kpBinary struct {
+	ID         string `xml:"ID,attr"`
+	Compressed bool   `xml:"Compressed,attr"`
+	Content    string `xml:",chardata"`
+}
+
+type kpGroup struct {
+	UUID    string    `xml:"UUID"`
+	Name    string    `xml:"Name"`
+	Entries []kpEntry `xml:"Entry"`
+	Groups  []kpGroup `xml:"Group"`
+}
+
+type kpEntry struct {
+	Strings []struct {
+		Key   string `xml:"Key"`
+		Value string `xml:"Value"`
+	} `xml:"String"`
+	Binaries []struct {
+		Key   string `xml:"Key"`
+		Value struct {
+			Ref        string `xml:"Ref,attr"`
+			Compressed bool   `xml:"Compressed,attr"`
+			Content    string `xml:",chardata"`
+		} `xml:"Value"`
+	} `xml:"Binary"`
+	Tags  string `xml:"Tags"`
+	Times struct {
+		CreationTime         string `xml:"CreationTime"`
+		LastModificationTime string `xml:"LastModificationTime"`
+	} `xml:"Times"`
+}
+
+func (e kpEntry) field(key string) string {
+	for _, s := range e.Strings {
+		if s.Key == key {
+			return s.Value
+		}
+	}
+
+	return ""
+}
+
+func (imp KeePassImporter) Parse(r io.Reader) (*Result, error) {
+	var file kpFile
+
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("malformed XML: %w", err)
+	}
+
+	binaries := make(map[string]kpBinary, len(file.Meta.Binaries))
+	for _, b := range file.Meta.Binaries {
+		binaries[b.ID] = b
+	}
+
+	result := &Result{}
+
+	var walk func(g kpGroup, path []string)
+	walk = func(g kpGroup, path []string) {
+		if g.UUID != "" && g.UUID == file.Meta.RecycleBinUUID {
+			return
+		}
+
+		for _, e := range g.Entries {
+			imp.convert(e, strings.Join(path, "/"), binaries, result)
+		}
+
+		for _, sub := range g.Groups {
+			walk(sub, append(path, sub.Name))
+		}
+	}
+
+	// Root group is the database itself, its name is not part of paths
+	for _, g := range file.Root.Groups {
+		walk(g, nil)
+	}
+
+	return result, nil
+}
+
+func (imp KeePassImporter) convert(e kpEntry, group string, binaries map[string]kpBinary, result *Result) {
+	title := e.field("Title")
+	login, password, notes := e.field("UserName"), e.field("Password"), e.field("Notes")
+	created, updated := parseKeePassTime(e.Times.CreationTime), parseKeePassTime(e.Times.LastModificationTime)
+
+	var extra []string
+	for _, s := range e.Strings {
+		switch s.Key {
+		case "Title", "UserName", "Password", "URL", "Notes":
+		default:
+			extra = append(extra, s.Key, s.Value)
+		}
+	}
+
+	meta := metadata(append([]string{"url", e.field("URL")}, extra...)...)
+
+	// Group path becomes folder, attachments go to the same folder as their entry
+	add := func(s *models.Secret) {
+		setTimes(s, created, updated)
+		s.Folder = models.CleanFolder(group)
+		s.Tags = models.ParseTags(e.Tags)
+		result.Secrets = append(result.Secrets, s)
+	}
+
+	switch {
+	case login != "" || password != "":
+		add(newCredential(title, login, password, metadata("", meta, "notes", notes)))
+	case notes != "":
+		add(newText(title, notes, meta))
+	case len(e.Binaries) == 0:
+		result.Skipped = append(result.Skipped, Skipped{Title: title, Reason: "empty entry"})
+	}
+
+	for _, b := range e.Binaries {
+		content, compressed := b.Value.Content, b.Value.Compressed
+		if b.Value.Ref != "" {
+			ref, ok := binaries[b.Value.Ref]
+			if !ok {
+				result.Skipped = append(result.Skipped, Skipped{Title: title + ": " + b.Key, Reason: "attachment data is missing"})
+				continue
+			}
+			content, compressed = ref.Content, ref.Compressed
+		}
+
+		data, err := decodeKeePassBinary(content, compressed)
+		if err != nil {
+			result.Skipped = append(result.Skipped, Skipped{Title: title + ": " + b.Key, Reason: err.Error()})
+			continue
+		}
+
+		add(newBlob(title+": "+b.Key, b.Key, data, metadata("attached to", title)))
+	}
+}
+
+func decodeKeePassBinary(content string, compressed bool) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(content))
+	if err != nil {
+		return nil, fmt.Errorf("malformed attachment: %w", err)
+	}
+
+	if !compressed {
+		return data, nil
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("malformed compressed attachment: %w", err)
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+// Times are ISO 8601 in XML exports, base64 of seconds since year 1 in KDBX 4 dumps
+func parseKeePassTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil && len(b) == 8 {
+		return time.Unix(int64(binary.LittleEndian.Uint64(b))-kdbxEpochOffset, 0).UTC()
+	}
+
+	return time.Time{}
+}
@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const keePassXML = `<?xml version="1.0" encoding="utf-8" standalone="yes"?>
+<KeePassFile>
+	<Meta>
+		<RecycleBinUUID>trash</RecycleBinUUID>
+		<Binaries>
+			<Binary ID="0" Compressed="True">H4sIAAAAAAAAA8tIzcnJV0gsKUlMzshNzSsBADcSvqMQAAAA</Binary>
+		</Binaries>
+	</Meta>
+	<Root>
+		<Group>
+			<UUID>root</UUID>
+			<Name>Database</Name>
+			<Entry>
+				<String><Key>Title</Key><Value>mail</Value></String>
+				<String><Key>UserName</Key><Value>alice</Value></String>
+				<String><Key>Password</Key><Value>secret</Value></String>
+				<String><Key>URL</Key><Value>https://mail.example.com</Value></String>
+				<String><Key>PIN</Key><Value>1234</Value></String>
+				<Binary><Key>hello.txt</Key><Value Ref="0"/></Binary>
+				<Times>
+					<CreationTime>2021-05-01T10:00:00Z</CreationTime>
+					<LastModificationTime>AFmv2Q4AAAA=</LastModificationTime>
+				</Times>
+			</Entry>
+			<Group>
+				<UUID>work</UUID>
+				<Name>Work</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>wifi</Value></String>
+					<Tags>home;guest</Tags>
+					<String><Key>Notes</Key><Value>guest network</Value></String>
+					<Binary><Key>raw.bin</Key><Value>cmF3</Value></Binary>
+				</Entry>
+				<Entry>
+					<String><Key>Title</Key><Value>blank</Value></String>
+				</Entry>
+			</Group>
+			<Group>
+				<UUID>trash</UUID>
+				<Name>Recycle Bin</Name>
+				<Entry>
+					<String><Key>Title</Key><Value>deleted</Value></String>
+					<String><Key>Password</Key><Value>old</Value></String>
+				</Entry>
+			</Group>
+		</Group>
+	</Root>
+</KeePassFile>`
+
+func TestKeePassImporter(t *testing.T) {
+	result, err := KeePassImporter{}.Parse(strings.NewReader(keePassXML))
+	require.NoError(t, err)
+	require.Len(t, result.Secrets, 4)
+
+	mail := result.Secrets[0]
+	assert.Equal(t, string(models.CredSecret), mail.SecretType)
+	assert.Equal(t, &models.Credentials{Login: "alice", Password: "secret"}, mail.Creds)
+	assert.Equal(t, "url: https://mail.example.com\nPIN: 1234", mail.Metadata)
+	assert.Equal(t, 2021, mail.CreatedAt.Year())
+	assert.Equal(t, 2022, mail.UpdatedAt.Year())
+
+	attachment := result.Secrets[1]
+	assert.Equal(t, "mail: hello.txt", attachment.Title)
+	assert.Equal(t, []byte("hello attachment"), attachment.Blob.FileBytes)
+
+	wifi := result.Secrets[2]
+	assert.Equal(t, string(models.TextSecret), wifi.SecretType)
+	assert.Equal(t, "guest network", wifi.Text.Content)
+	assert.Empty(t, wifi.Metadata)
+	assert.Equal(t, "Work", wifi.Folder)
+	assert.Equal(t, models.Tags{"guest", "home"}, wifi.Tags)
+	assert.Equal(t, "Work", result.Secrets[3].Folder)
+	assert.Empty(t, mail.Folder)
+	assert.Equal(t, []byte("raw"), result.Secrets[3].Blob.FileBytes)
+
+	assert.Equal(t, []Skipped{{Title: "blank", Reason: "empty entry"}}, result.Skipped)
+}
+
+func TestKeePassImporterMalformed(t *testing.T) {
+	_, err := KeePassImporter{}.Parse(strings.NewReader("<KeePassFile><Root>"))
+	assert.Error(t, err)
+}
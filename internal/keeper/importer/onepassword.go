@@ -0,0 +1,37 @@
+package importer
+
+import (
+	"encoding/csv"
+	"strings"
+)
+
+// Imports 1Password CSV export. It is CSV with Title, Url, Username, Password, OTPAuth, Favorite,
+// Archived, Tags and Notes columns; archived items are skipped.
+func NewOnePasswordImporter() *CSVImporter {
+	return &CSVImporter{
+		skip: func(row map[column]string) string {
+			if strings.EqualFold(strings.TrimSpace(row[colArchived]), "true") {
+				return "archived item"
+			}
+
+			return ""
+		},
+	}
+}
+
+// Header has columns only 1Password writes
+func isOnePasswordHeader(line string) bool {
+	header, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return false
+	}
+
+	for _, name := range header {
+		switch normalizeHeader(name) {
+		case "otpauth", "archived":
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"gophkeeper/internal/keeper/api"
 	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/sshagent"
+	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui/app"
 	"os"
 	"os/signal"
@@ -81,6 +83,16 @@ func (k Keeper) shutdown() {
 		// Shut down seervices here, if any
 		// TODO: notification service
 
+		// Stop serving keys before storages are locked
+		if err := sshagent.Stop(); err != nil {
+			k.log.Error(err, "failed to stop ssh-agent")
+		}
+
+		// Flush local storages and release their locks
+		if err := storage.CloseAll(stopCtx); err != nil {
+			k.log.Error(err, "failed to close local storages")
+		}
+
 		close(stopped)
 	}()
 
@@ -0,0 +1,101 @@
+package passgen
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	DefaultWords     = 6
+	MinWords         = 3
+	MaxWords         = 20
+	DefaultSeparator = "-"
+)
+
+// 7776 (6^5, five dice per word) common English words, one per line
+//
+//go:embed wordlist.txt
+var wordlistFile string
+
+var wordlist = strings.Fields(wordlistFile)
+
+var ErrBadWords = fmt.Errorf("number of words must be from %d to %d", MinWords, MaxWords)
+
+// Passphrase generation options
+type PassphraseOptions struct {
+	Words      int
+	Separator  string
+	Capitalize bool // first letter of every word
+	Digit      bool // random digit appended to random word
+}
+
+// Six lowercase words joined by dashes
+func DefaultPassphraseOptions() PassphraseOptions {
+	return PassphraseOptions{
+		Words:     DefaultWords,
+		Separator: DefaultSeparator,
+	}
+}
+
+func (o PassphraseOptions) Validate() error {
+	if o.Words < MinWords || o.Words > MaxWords {
+		return ErrBadWords
+	}
+
+	return nil
+}
+
+// Bits of entropy of generated passphrase, capitalization of every word adds nothing
+func (o PassphraseOptions) Entropy() float64 {
+	bits := float64(o.Words) * math.Log2(float64(len(wordlist)))
+	if o.Digit && o.Words > 0 {
+		bits += math.Log2(10) + math.Log2(float64(o.Words))
+	}
+
+	return bits
+}
+
+// Random words of embedded wordlist, e.g. "cradle-velvet-orbit-snack-tunnel-lilac"
+func Passphrase(opts PassphraseOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	if len(wordlist) == 0 {
+		return "", errors.New("empty wordlist")
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randInt(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+
+		words[i] = wordlist[n]
+		if opts.Capitalize {
+			r := []rune(words[i])
+			r[0] = unicode.ToUpper(r[0])
+			words[i] = string(r)
+		}
+	}
+
+	if opts.Digit {
+		i, err := randInt(len(words))
+		if err != nil {
+			return "", err
+		}
+		d, err := randInt(10)
+		if err != nil {
+			return "", err
+		}
+		words[i] += strconv.Itoa(d)
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
@@ -0,0 +1,98 @@
+package passgen
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// Strength of password by its entropy
+type Strength string
+
+const (
+	VeryWeak   Strength = "very weak"
+	Weak       Strength = "weak"
+	Fair       Strength = "fair"
+	Strong     Strength = "strong"
+	VeryStrong Strength = "very strong"
+)
+
+// Bits of entropy, lower bounds of strengths
+const (
+	WeakBits       = 28
+	FairBits       = 36
+	StrongBits     = 60
+	VeryStrongBits = 100
+)
+
+func StrengthOf(bits float64) Strength {
+	switch {
+	case bits >= VeryStrongBits:
+		return VeryStrong
+	case bits >= StrongBits:
+		return Strong
+	case bits >= FairBits:
+		return Fair
+	case bits >= WeakBits:
+		return Weak
+	default:
+		return VeryWeak
+	}
+}
+
+// Rough entropy of typed password: length times size of character classes it uses, with
+// repeated characters and runs like "aaaa" or "1234" counted once
+func Estimate(password string) float64 {
+	if password == "" {
+		return 0
+	}
+
+	pool := 0
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r) && r < unicode.MaxASCII:
+			lower = true
+		case unicode.IsUpper(r) && r < unicode.MaxASCII:
+			upper = true
+		case unicode.IsDigit(r) && r < unicode.MaxASCII:
+			digit = true
+		case strings.ContainsRune(Symbols, r) || r == ' ' || r == '\'' || r == '"' || r == '`' || r == '\\':
+			symbol = true
+		default:
+			other = true
+		}
+	}
+	for _, c := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if c.used {
+			pool += c.size
+		}
+	}
+
+	return float64(effectiveLength(password)) * math.Log2(float64(pool))
+}
+
+// Length without characters repeating or continuing previous one, "aaaa1234" is 2
+func effectiveLength(password string) int {
+	runes := []rune(password)
+
+	n := 1
+	for i := 1; i < len(runes); i++ {
+		step := runes[i] - runes[i-1]
+		if step >= -1 && step <= 1 {
+			continue
+		}
+		n++
+	}
+
+	return n
+}
+
+// Entropy and strength for display, e.g. "103 bits, very strong"
+func Describe(bits float64) string {
+	return fmt.Sprintf("%.0f bits, %s", bits, StrengthOf(bits))
+}
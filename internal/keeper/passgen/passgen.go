@@ -0,0 +1,160 @@
+// Random passwords and diceware passphrases with entropy estimates
+package passgen
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	DefaultLength = 20
+	MinLength     = 4
+	MaxLength     = 128
+)
+
+// Character classes
+const (
+	Lower   = "abcdefghijklmnopqrstuvwxyz"
+	Upper   = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	Digits  = "0123456789"
+	Symbols = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+
+	// Characters easily confused with each other when read or typed by hand
+	Ambiguous = "Il1|O0oB8S5Z2`'\""
+)
+
+var (
+	ErrNoClasses = errors.New("choose at least one character class")
+	ErrBadLength = fmt.Errorf("length must be from %d to %d", MinLength, MaxLength)
+)
+
+// Password generation options
+type Options struct {
+	Length           int
+	Lower            bool
+	Upper            bool
+	Digits           bool
+	Symbols          bool
+	ExcludeAmbiguous bool
+}
+
+// All classes, ambiguous characters excluded
+func DefaultOptions() Options {
+	return Options{
+		Length:           DefaultLength,
+		Lower:            true,
+		Upper:            true,
+		Digits:           true,
+		Symbols:          true,
+		ExcludeAmbiguous: true,
+	}
+}
+
+// Characters of enabled classes
+func (o Options) classes() []string {
+	var classes []string
+	for _, c := range []struct {
+		on    bool
+		chars string
+	}{{o.Lower, Lower}, {o.Upper, Upper}, {o.Digits, Digits}, {o.Symbols, Symbols}} {
+		if !c.on {
+			continue
+		}
+
+		chars := c.chars
+		if o.ExcludeAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(Ambiguous, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+
+		classes = append(classes, chars)
+	}
+
+	return classes
+}
+
+func (o Options) Validate() error {
+	if o.Length < MinLength || o.Length > MaxLength {
+		return ErrBadLength
+	}
+	if len(o.classes()) == 0 {
+		return ErrNoClasses
+	}
+
+	return nil
+}
+
+// Bits of entropy of generated password, requirement of every class is negligible and ignored
+func (o Options) Entropy() float64 {
+	alphabet := strings.Join(o.classes(), "")
+	if alphabet == "" {
+		return 0
+	}
+
+	return float64(o.Length) * math.Log2(float64(len(alphabet)))
+}
+
+// Random password with at least one character of every enabled class
+func Password(opts Options) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	classes := opts.classes()
+	alphabet := strings.Join(classes, "")
+
+	password := make([]byte, 0, opts.Length)
+	for _, chars := range classes {
+		c, err := pick(chars)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	for len(password) < opts.Length {
+		c, err := pick(alphabet)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Required characters must not stay at the beginning
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+
+	return string(password), nil
+}
+
+func pick(chars string) (byte, error) {
+	i, err := randInt(len(chars))
+	if err != nil {
+		return 0, err
+	}
+
+	return chars[i], nil
+}
+
+// Uniform random number in [0, n)
+func randInt(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read random: %w", err)
+	}
+
+	return int(i.Int64()), nil
+}
@@ -0,0 +1,107 @@
+package passgen
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword(t *testing.T) {
+	opts := DefaultOptions()
+
+	for range 100 {
+		password, err := Password(opts)
+		require.NoError(t, err)
+		assert.Len(t, password, DefaultLength)
+
+		assert.True(t, strings.ContainsAny(password, Lower))
+		assert.True(t, strings.ContainsAny(password, Upper))
+		assert.True(t, strings.ContainsAny(password, Digits))
+		assert.True(t, strings.ContainsAny(password, Symbols))
+		assert.False(t, strings.ContainsAny(password, Ambiguous), password)
+	}
+}
+
+func TestPassword_Classes(t *testing.T) {
+	password, err := Password(Options{Length: 8, Digits: true})
+	require.NoError(t, err)
+	assert.Len(t, password, 8)
+	assert.Empty(t, strings.Trim(password, Digits))
+
+	_, err = Password(Options{Length: 8})
+	assert.ErrorIs(t, err, ErrNoClasses)
+
+	_, err = Password(Options{Length: 2, Lower: true})
+	assert.ErrorIs(t, err, ErrBadLength)
+}
+
+func TestOptions_Entropy(t *testing.T) {
+	assert.InDelta(t, 6*math.Log2(10), Options{Length: 6, Digits: true}.Entropy(), 1e-9)
+	assert.InDelta(t, 20*math.Log2(62), Options{Length: 20, Lower: true, Upper: true, Digits: true}.Entropy(), 1e-9)
+	assert.Less(t, DefaultOptions().Entropy(), Options{Length: DefaultLength, Lower: true, Upper: true, Digits: true, Symbols: true}.Entropy())
+	assert.Zero(t, Options{Length: 10}.Entropy())
+}
+
+func TestPassphrase(t *testing.T) {
+	assert.Len(t, wordlist, 7776)
+
+	phrase, err := Passphrase(DefaultPassphraseOptions())
+	require.NoError(t, err)
+
+	words := strings.Split(phrase, DefaultSeparator)
+	assert.Len(t, words, DefaultWords)
+	for _, w := range words {
+		assert.Contains(t, wordlist, w)
+	}
+
+	phrase, err = Passphrase(PassphraseOptions{Words: 4, Separator: " ", Capitalize: true, Digit: true})
+	require.NoError(t, err)
+
+	words = strings.Split(phrase, " ")
+	assert.Len(t, words, 4)
+	assert.Len(t, strings.Map(func(r rune) rune {
+		if strings.ContainsRune(Digits, r) {
+			return r
+		}
+		return -1
+	}, phrase), 1)
+	for _, w := range words {
+		assert.Equal(t, strings.ToUpper(w[:1]), w[:1])
+	}
+
+	_, err = Passphrase(PassphraseOptions{Words: 2})
+	assert.ErrorIs(t, err, ErrBadWords)
+}
+
+func TestPassphraseOptions_Entropy(t *testing.T) {
+	bits := DefaultPassphraseOptions().Entropy()
+	assert.InDelta(t, 77.5, bits, 0.1)
+
+	withDigit := PassphraseOptions{Words: DefaultWords, Digit: true}.Entropy()
+	assert.InDelta(t, bits+math.Log2(10)+math.Log2(DefaultWords), withDigit, 1e-9)
+}
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		password string
+		strength Strength
+	}{
+		{"", VeryWeak},
+		{"aaaaaaaaaaaa", VeryWeak},
+		{"12345678", VeryWeak},
+		{"qwerty", Weak},
+		{"Tr0ub4dor", Fair},
+		{"correct horse battery staple", VeryStrong},
+		{"k#8Vq!zR2m", Strong},
+		{"k#8Vq!zR2m@xP5w&", VeryStrong},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			assert.Equal(t, tt.strength, StrengthOf(Estimate(tt.password)))
+		})
+	}
+}
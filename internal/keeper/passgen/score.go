@@ -0,0 +1,421 @@
+package passgen
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Guessability of password as in zxcvbn, from ScoreTooGuessable to ScoreVeryUnguessable
+type Score int
+
+const (
+	ScoreTooGuessable      Score = iota // under 10^3 guesses
+	ScoreVeryGuessable                  // under 10^6 guesses
+	ScoreSomewhatGuessable              // under 10^8 guesses
+	ScoreSafelyUnguessable              // under 10^10 guesses
+	ScoreVeryUnguessable
+)
+
+// Lowest score of password which is not reported as weak
+const GoodScore = ScoreSafelyUnguessable
+
+func (s Score) String() string {
+	return [...]string{"too guessable", "very guessable", "somewhat guessable", "safely unguessable", "very unguessable"}[s]
+}
+
+// Estimated number of guesses for password and what made it guessable
+type Evaluation struct {
+	Guesses float64
+	Score   Score
+	Pattern string // most guessable pattern found, e.g. "dictionary", empty for random passwords
+}
+
+// Longer passwords are evaluated by their beginning, they are strong anyway
+const maxEvaluated = 64
+
+// Rank of words of diceware list, which is not ordered by frequency
+const wordRank = 3000
+
+// Common passwords, most frequent first
+//
+//go:embed common.txt
+var commonFile string
+
+var dictionary = sync.OnceValue(func() map[string]int {
+	ranks := make(map[string]int)
+	for _, w := range wordlist {
+		ranks[w] = wordRank
+	}
+	for i, w := range strings.Fields(commonFile) {
+		ranks[w] = i + 1
+	}
+
+	return ranks
+})
+
+var leet = map[rune][]rune{
+	'4': {'a'}, '@': {'a'}, '8': {'b'}, '(': {'c'}, '3': {'e'}, '6': {'g'}, '9': {'g'},
+	'1': {'i', 'l'}, '!': {'i'}, '|': {'l'}, '0': {'o'}, '$': {'s'}, '5': {'s'}, '7': {'t'}, '+': {'t'}, '2': {'z'},
+}
+
+var keyboardRows = []string{"`1234567890-=", "qwertyuiop[]\\", "asdfghjkl;'", "zxcvbnm,./"}
+
+// Guessable part of password, runes i to j inclusive
+type match struct {
+	i, j    int
+	guesses float64
+	pattern string
+}
+
+// Estimate guesses like zxcvbn: dictionary words with l33t and case variations, keyboard runs,
+// sequences, repeats and years are matched and the least guessable split of password into them
+// and brute-forced runs is found
+func Evaluate(password string) Evaluation {
+	runes := []rune(password)
+	if len(runes) > maxEvaluated {
+		runes = runes[:maxEvaluated]
+	}
+	if len(runes) == 0 {
+		return Evaluation{Guesses: 1}
+	}
+
+	guesses, pattern := mostGuessable(runes, make(map[string]float64))
+
+	return Evaluation{Guesses: guesses, Score: scoreOf(guesses), Pattern: pattern}
+}
+
+func scoreOf(guesses float64) Score {
+	const delta = 5
+	switch {
+	case guesses < 1e3+delta:
+		return ScoreTooGuessable
+	case guesses < 1e6+delta:
+		return ScoreVeryGuessable
+	case guesses < 1e8+delta:
+		return ScoreSomewhatGuessable
+	case guesses < 1e10+delta:
+		return ScoreSafelyUnguessable
+	default:
+		return ScoreVeryUnguessable
+	}
+}
+
+// Minimum over splits of password into k parts of k! times product of guesses of parts,
+// computed in log10 to stay finite. Guesses of repeated groups are memoized.
+func mostGuessable(runes []rune, memo map[string]float64) (float64, string) {
+	n := len(runes)
+	matches := findMatches(runes, memo)
+
+	// best[k][j] is log10 of guesses of first j runes split into k parts
+	best := make([][]float64, n+1)
+	pattern := make([][]string, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		pattern[k] = make([]string, n+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 0
+
+	byEnd := make([][]match, n)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	for j := 1; j <= n; j++ {
+		for k := 1; k <= j; k++ {
+			// Brute-forced run of runes i to j-1
+			for i := 0; i < j; i++ {
+				if g := best[k-1][i] + float64(j-i); g < best[k][j] {
+					best[k][j], pattern[k][j] = g, pattern[k-1][i]
+				}
+			}
+
+			for _, m := range byEnd[j-1] {
+				g := best[k-1][m.i] + math.Log10(minGuesses(m, n))
+				if g < best[k][j] {
+					best[k][j], pattern[k][j] = g, m.pattern
+				}
+			}
+		}
+	}
+
+	total, found := math.Inf(1), ""
+	factorial := 0.0
+	for k := 1; k <= n; k++ {
+		factorial += math.Log10(float64(k))
+		if g := factorial + best[k][n]; g < total {
+			total, found = g, pattern[k][n]
+		}
+	}
+
+	return math.Pow(10, total), found
+}
+
+// Matched part of longer password is not guessed faster than a few characters
+func minGuesses(m match, n int) float64 {
+	if m.j-m.i+1 == n {
+		return max(m.guesses, 1)
+	}
+	if m.i == m.j {
+		return max(m.guesses, 10)
+	}
+
+	return max(m.guesses, 50)
+}
+
+func findMatches(runes []rune, memo map[string]float64) []match {
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes)...)
+	matches = append(matches, sequenceMatches(runes)...)
+	matches = append(matches, keyboardMatches(runes)...)
+	matches = append(matches, repeatMatches(runes, memo)...)
+	matches = append(matches, yearMatches(runes)...)
+
+	return matches
+}
+
+func dictionaryMatches(runes []rune) []match {
+	ranks := dictionary()
+	lower := []rune(strings.ToLower(string(runes)))
+
+	var matches []match
+	for i := range lower {
+		for j := i + 2; j < len(lower); j++ {
+			word := lower[i : j+1]
+			cases := caseVariations(runes[i : j+1])
+
+			if rank, ok := ranks[string(word)]; ok {
+				matches = append(matches, match{i: i, j: j, guesses: float64(rank) * cases, pattern: "dictionary"})
+			}
+			if rank, ok := ranks[reverse(word)]; ok {
+				matches = append(matches, match{i: i, j: j, guesses: float64(rank) * cases * 2, pattern: "reversed word"})
+			}
+			for _, plain := range unleet(word) {
+				if rank, ok := ranks[plain.word]; ok {
+					guesses := float64(rank) * cases * math.Pow(2, float64(plain.subs))
+					matches = append(matches, match{i: i, j: j, guesses: guesses, pattern: "word with substitutions"})
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// Number of ways to capitalize word like the given one
+func caseVariations(word []rune) float64 {
+	var upper, lower int
+	for _, r := range word {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+
+	if upper == 0 {
+		return 1
+	}
+	// Capitalized, all caps or last letter uppercase
+	if lower == 0 || (upper == 1 && (unicode.IsUpper(word[0]) || unicode.IsUpper(word[len(word)-1]))) {
+		return 2
+	}
+
+	variations := 0.0
+	for i := 1; i <= min(upper, lower); i++ {
+		variations += binomial(upper+lower, i)
+	}
+
+	return variations
+}
+
+func binomial(n, k int) float64 {
+	r := 1.0
+	for i := 1; i <= k; i++ {
+		r = r * float64(n-k+i) / float64(i)
+	}
+
+	return r
+}
+
+type unleeted struct {
+	word string
+	subs int
+}
+
+// Word with l33t characters replaced back by letters, one variant per ambiguous character choice
+func unleet(word []rune) []unleeted {
+	variants := []unleeted{{}}
+	substituted := false
+
+	for _, r := range word {
+		letters, ok := leet[r]
+		if !ok {
+			for i := range variants {
+				variants[i].word += string(r)
+			}
+			continue
+		}
+
+		substituted = true
+		next := make([]unleeted, 0, len(variants)*len(letters))
+		for _, v := range variants {
+			for _, l := range letters {
+				next = append(next, unleeted{word: v.word + string(l), subs: v.subs + 1})
+			}
+		}
+		variants = next
+		if len(variants) > 16 {
+			variants = variants[:16]
+		}
+	}
+
+	if !substituted {
+		return nil
+	}
+
+	return variants
+}
+
+func reverse(word []rune) string {
+	r := make([]rune, len(word))
+	for i, c := range word {
+		r[len(word)-1-i] = c
+	}
+
+	return string(r)
+}
+
+// Runs like "abcd", "1357" or "9876"
+func sequenceMatches(runes []rune) []match {
+	var matches []match
+
+	for i := 0; i+2 < len(runes); {
+		delta := runes[i+1] - runes[i]
+		j := i + 1
+		for j+1 < len(runes) && runes[j+1]-runes[j] == delta {
+			j++
+		}
+
+		if j-i >= 2 && delta != 0 && delta >= -5 && delta <= 5 {
+			base := 26.0
+			switch {
+			case strings.ContainsRune("aAzZ019", runes[i]):
+				base = 4
+			case unicode.IsDigit(runes[i]):
+				base = 10
+			}
+			if delta < 0 {
+				base *= 2
+			}
+			matches = append(matches, match{i: i, j: j, guesses: base * float64(j-i+1), pattern: "sequence"})
+		}
+
+		i = j
+	}
+
+	return matches
+}
+
+// Runs of neighbor keys of one keyboard row, like "qwerty" or "lkjh"
+func keyboardMatches(runes []rune) []match {
+	const (
+		keys   = 47  // starting positions
+		degree = 4.6 // average number of neighbors
+	)
+
+	lower := []rune(strings.ToLower(string(runes)))
+
+	var matches []match
+	for _, row := range keyboardRows {
+		for _, line := range []string{row, reverse([]rune(row))} {
+			for i := 0; i < len(lower); i++ {
+				j := i
+				for j+1 < len(lower) {
+					pos := strings.IndexRune(line, lower[j])
+					if pos < 0 || pos+1 >= len(line) || rune(line[pos+1]) != lower[j+1] {
+						break
+					}
+					j++
+				}
+
+				if j-i >= 2 {
+					matches = append(matches, match{i: i, j: j, guesses: keys * degree * float64(j-i), pattern: "keyboard run"})
+					i = j
+				}
+			}
+		}
+	}
+
+	return matches
+}
+
+// Repeated characters or groups, like "aaaa" or "abcabc"
+func repeatMatches(runes []rune, memo map[string]float64) []match {
+	var matches []match
+
+	for i := range runes {
+		for size := 1; i+2*size <= len(runes); size++ {
+			unit := runes[i : i+size]
+
+			// Continuation of repeat matched at earlier position
+			if i >= size && string(runes[i-size:i]) == string(unit) {
+				continue
+			}
+
+			count := 1
+			for next := i + size; next+size <= len(runes) && string(runes[next:next+size]) == string(unit); next += size {
+				count++
+			}
+
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+
+			unitGuesses, ok := memo[string(unit)]
+			if !ok {
+				unitGuesses, _ = mostGuessable(unit, memo)
+				memo[string(unit)] = unitGuesses
+			}
+			matches = append(matches, match{i: i, j: i + size*count - 1, guesses: unitGuesses * float64(count), pattern: "repeat"})
+		}
+	}
+
+	return matches
+}
+
+// Years from 1900 to 2099, recent ones are guessed first
+func yearMatches(runes []rune) []match {
+	const minYearSpace = 20
+
+	now := time.Now().Year()
+
+	var matches []match
+	for i := 0; i+4 <= len(runes); i++ {
+		s := string(runes[i : i+4])
+		if (!strings.HasPrefix(s, "19") && !strings.HasPrefix(s, "20")) || strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' }) >= 0 {
+			continue
+		}
+
+		year := int(runes[i]-'0')*1000 + int(runes[i+1]-'0')*100 + int(runes[i+2]-'0')*10 + int(runes[i+3]-'0')
+		space := max(abs(year-now), minYearSpace)
+		matches = append(matches, match{i: i, j: i + 3, guesses: float64(space), pattern: "year"})
+	}
+
+	return matches
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+
+	return n
+}
@@ -0,0 +1,53 @@
+package passgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		password string
+		score    Score
+		pattern  string
+	}{
+		{"password", ScoreTooGuessable, "dictionary"},
+		{"P@ssw0rd", ScoreTooGuessable, "word with substitutions"},
+		{"qwerty", ScoreTooGuessable, "dictionary"},
+		{"zxcvbnm,./", ScoreVeryGuessable, "keyboard run"},
+		{"abcdefghij", ScoreTooGuessable, "sequence"},
+		{"aaaaaaaaaaaaaaaa", ScoreTooGuessable, "repeat"},
+		{"drowssap", ScoreTooGuessable, "reversed word"},
+		{"Monkey1987", ScoreVeryGuessable, "year"},
+		{"sunflower-mosaic", ScoreSomewhatGuessable, "dictionary"},
+		{"k#8Vq!zR2m", ScoreSafelyUnguessable, ""},
+		{"k#8Vq!zR2m@xP5", ScoreVeryUnguessable, ""},
+		{"cradle-velvet-orbit-snack-tunnel", ScoreVeryUnguessable, "dictionary"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.password, func(t *testing.T) {
+			e := Evaluate(tt.password)
+			assert.Equal(t, tt.score, e.Score, "guesses %g", e.Guesses)
+			assert.Equal(t, tt.pattern, e.Pattern)
+		})
+	}
+}
+
+func TestEvaluate_Long(t *testing.T) {
+	e := Evaluate(string(make([]byte, 1000)))
+	assert.Equal(t, ScoreTooGuessable, e.Score)
+
+	password, err := Password(Options{Length: MaxLength, Lower: true, Upper: true, Digits: true, Symbols: true})
+	assert.NoError(t, err)
+	assert.Equal(t, ScoreVeryUnguessable, Evaluate(password).Score)
+}
+
+func TestCaseVariations(t *testing.T) {
+	assert.Equal(t, 1.0, caseVariations([]rune("word")))
+	assert.Equal(t, 2.0, caseVariations([]rune("Word")))
+	assert.Equal(t, 2.0, caseVariations([]rune("WORD")))
+	assert.Equal(t, 2.0, caseVariations([]rune("worD")))
+	assert.Equal(t, 15.0, caseVariations([]rune("wOrDs"))) // 5 ways to pick 1 and 10 ways to pick 2 letters
+}
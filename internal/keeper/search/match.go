@@ -0,0 +1,160 @@
+package search
+
+import (
+	"gophkeeper/pkg/models"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Searchable field of secret with weight of its matches
+type field struct {
+	text   string
+	weight int
+}
+
+// Text fields of secret, passwords, card numbers and CVV are never searched
+func fields(s *models.Secret) []field {
+	res := []field{
+		{text: s.Title, weight: 3},
+		{text: s.Folder, weight: 2},
+		{text: strings.Join(s.Tags, " "), weight: 2},
+		{text: s.SecretType, weight: 1},
+		{text: s.Metadata, weight: 1},
+	}
+
+	if s.Creds != nil {
+		res = append(res, field{text: s.Creds.Login, weight: 2})
+	}
+	if s.Blob != nil {
+		res = append(res, field{text: s.Blob.FileName, weight: 2})
+	}
+	if s.TOTP != nil {
+		res = append(res, field{text: s.TOTP.Issuer + " " + s.TOTP.Account, weight: 2})
+	}
+	if s.SSH != nil {
+		res = append(res, field{text: s.SSH.Comment, weight: 2})
+	}
+	if s.Text != nil {
+		res = append(res, field{text: s.Text.Content, weight: 1})
+	}
+
+	return res
+}
+
+// Score of secret for query, ok is false when secret does not match
+func (q *Query) Match(s *models.Secret, now time.Time) (score int, ok bool) {
+	for _, f := range q.filters {
+		if f.match(s, now) == f.negate {
+			return 0, false
+		}
+	}
+
+	if len(q.terms) == 0 {
+		return 0, true
+	}
+
+	lowered := fields(s)
+	for i := range lowered {
+		lowered[i].text = strings.ToLower(lowered[i].text)
+	}
+
+	for _, t := range q.terms {
+		best := 0
+		for _, f := range lowered {
+			best = max(best, fuzzyScore(t.text, f.text)*f.weight)
+		}
+
+		if (best > 0) == t.negate {
+			return 0, false
+		}
+
+		score += best
+	}
+
+	return score, true
+}
+
+// Secrets matching query, best matches first, equal ones in original order
+func (q *Query) Filter(secrets []*models.Secret, now time.Time) []*models.Secret {
+	type scored struct {
+		secret *models.Secret
+		score  int
+	}
+
+	var matched []scored
+	for _, s := range secrets {
+		if score, ok := q.Match(s, now); ok {
+			matched = append(matched, scored{secret: s, score: score})
+		}
+	}
+
+	slices.SortStableFunc(matched, func(a, b scored) int { return b.score - a.score })
+
+	res := make([]*models.Secret, 0, len(matched))
+	for _, m := range matched {
+		res = append(res, m.secret)
+	}
+
+	return res
+}
+
+// Score of pattern as subsequence of text, 0 when text does not contain all its characters in order.
+// Substrings score highest, then consecutive characters and characters at word starts.
+func fuzzyScore(pattern string, text string) int {
+	if pattern == "" || text == "" {
+		return 0
+	}
+
+	runes := []rune(text)
+
+	if i := strings.Index(text, pattern); i >= 0 {
+		score := 100 + len(pattern)
+		if isBoundary(runes, utf8.RuneCountInString(text[:i])) {
+			score += 50
+		}
+		return score
+	}
+
+	var (
+		score int
+		prev  = -2 // position of previous match
+		pi    int
+	)
+
+	p := []rune(pattern)
+	for ti, r := range runes {
+		if r != p[pi] {
+			continue
+		}
+
+		score++
+		if ti == prev+1 {
+			score += 5
+		}
+		if isBoundary(runes, ti) {
+			score += 3
+		}
+
+		prev = ti
+		pi++
+		if pi == len(p) {
+			return score
+		}
+	}
+
+	return 0
+}
+
+// Character at index i starts a word
+func isBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev := runes[i-1]
+
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
@@ -0,0 +1,54 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzyScore(t *testing.T) {
+	assert.Zero(t, fuzzyScore("xyz", "gmail"))
+	assert.Zero(t, fuzzyScore("liam", "mail"))
+	assert.Positive(t, fuzzyScore("gml", "gmail"))
+
+	// Substring beats scattered letters, word start beats middle of word
+	assert.Greater(t, fuzzyScore("mail", "gmail"), fuzzyScore("mail", "my annual list"))
+	assert.Greater(t, fuzzyScore("mail", "work mail"), fuzzyScore("mail", "gmail"))
+	assert.Greater(t, fuzzyScore("почта", "рабочая почта"), fuzzyScore("почта", "опочтамт"))
+}
+
+func TestQuery_Filter(t *testing.T) {
+	now := time.Now()
+
+	secrets := []*models.Secret{
+		{Title: "Bank", SecretType: string(models.CardSecret), UpdatedAt: now, Card: &models.Card{Number: "4111111111111111"}},
+		{Title: "Work", SecretType: string(models.CredSecret), UpdatedAt: now, Creds: &models.Credentials{Login: "john@gmail.com", Password: "gmail"}},
+		{Title: "Gmail", SecretType: string(models.CredSecret), UpdatedAt: now.Add(-60 * 24 * time.Hour), Creds: &models.Credentials{Login: "john"}},
+		{Title: "Scan", SecretType: string(models.BlobSecret), UpdatedAt: now, Blob: &models.Blob{FileName: "passport.pdf"}},
+		{Title: "Note", SecretType: string(models.TextSecret), UpdatedAt: now, Metadata: "gmail recovery codes", Text: &models.Text{Content: "1234"}},
+	}
+
+	titles := func(query string) []string {
+		q, err := Parse(query)
+		require.NoError(t, err)
+
+		var res []string
+		for _, s := range q.Filter(secrets, now) {
+			res = append(res, s.Title)
+		}
+		return res
+	}
+
+	assert.Equal(t, []string{"Bank", "Work", "Gmail", "Scan", "Note"}, titles(""))
+	assert.Equal(t, []string{"Gmail", "Work", "Note"}, titles("gmail"))
+	assert.Equal(t, []string{"Work", "Note"}, titles("gmail updated:<30d"))
+	assert.Equal(t, []string{"Note"}, titles("gmail -type:cred updated:<30d"), "negated filter")
+	assert.Equal(t, []string{"Scan"}, titles("pasprt"))
+	assert.Equal(t, []string{"Bank"}, titles("type:card"))
+	assert.Empty(t, titles("4111"), "card numbers are not searched")
+	assert.Equal(t, []string{"Bank", "Scan", "Note"}, titles("-john"))
+}
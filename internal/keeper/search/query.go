@@ -0,0 +1,195 @@
+// Search of decrypted secrets by fuzzy text and filters, shared by TUI and CLI.
+//
+// Query is a list of space separated words, double quotes keep spaces inside one word.
+// Plain words are matched fuzzily against secret fields, words of form key:value are filters:
+//
+//	type:card        secret type starting with value, several types separated by comma
+//	folder:work      secrets in folder and its subfolders
+//	tag:mail         secrets with tag
+//	updated:<30d     changed within 30 days, >30d for older ones; units h, d, w, m, y
+//	created:>2024-01-01  created after date, < for before
+//
+// Words starting with "-" exclude matching secrets. Words with unknown keys, e.g. URLs, are plain words.
+package search
+
+import (
+	"errors"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrBadFilter = errors.New("bad filter")
+)
+
+// Parsed search query
+type Query struct {
+	terms   []term
+	filters []filter
+}
+
+// Fuzzy word of query
+type term struct {
+	text   string // lower case
+	negate bool
+}
+
+type filter struct {
+	match  func(s *models.Secret, now time.Time) bool
+	negate bool
+}
+
+// Parse query string, empty query matches all secrets
+func Parse(query string) (*Query, error) {
+	q := &Query{}
+
+	for _, word := range split(query) {
+		negate := false
+		if len(word) > 1 && strings.HasPrefix(word, "-") {
+			negate = true
+			word = word[1:]
+		}
+
+		key, value, ok := strings.Cut(word, ":")
+		if ok {
+			match, known, err := parseFilter(strings.ToLower(key), value)
+			if err != nil {
+				return nil, fmt.Errorf("%w %q: %w", ErrBadFilter, word, err)
+			}
+			if known {
+				q.filters = append(q.filters, filter{match: match, negate: negate})
+				continue
+			}
+		}
+
+		q.terms = append(q.terms, term{text: strings.ToLower(word), negate: negate})
+	}
+
+	return q, nil
+}
+
+// Query has neither words nor filters
+func (q *Query) Empty() bool {
+	return len(q.terms) == 0 && len(q.filters) == 0
+}
+
+// Split query by spaces, keeping quoted parts together
+func split(query string) []string {
+	var (
+		words  []string
+		word   strings.Builder
+		quoted bool
+	)
+
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range query {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			word.WriteRune(r)
+		}
+	}
+	flush()
+
+	return words
+}
+
+// Filter for key, known is false for keys which are not filters
+func parseFilter(key string, value string) (match func(s *models.Secret, now time.Time) bool, known bool, err error) {
+	switch key {
+	case "type":
+		types := strings.Split(strings.ToLower(value), ",")
+		return func(s *models.Secret, _ time.Time) bool {
+			for _, t := range types {
+				if t != "" && strings.HasPrefix(s.SecretType, t) {
+					return true
+				}
+			}
+			return false
+		}, true, nil
+	case "folder":
+		folder := models.CleanFolder(value)
+		return func(s *models.Secret, _ time.Time) bool { return s.InFolder(folder) }, true, nil
+	case "tag":
+		return func(s *models.Secret, _ time.Time) bool { return s.HasTag(strings.TrimPrefix(value, "#")) }, true, nil
+	case "updated":
+		match, err := parseTime(value, func(s *models.Secret) time.Time { return s.UpdatedAt })
+		return match, true, err
+	case "created":
+		match, err := parseTime(value, func(s *models.Secret) time.Time { return s.CreatedAt })
+		return match, true, err
+	default:
+		return nil, false, nil
+	}
+}
+
+// Parse "<30d", ">30d", "<2024-01-01" or ">2024-01-01", no sign means "<" for age and ">" for date
+func parseTime(value string, field func(s *models.Secret) time.Time) (func(s *models.Secret, now time.Time) bool, error) {
+	sign := ""
+	if strings.HasPrefix(value, "<") || strings.HasPrefix(value, ">") {
+		sign, value = value[:1], value[1:]
+	}
+
+	if date, err := time.ParseInLocation(time.DateOnly, value, time.Local); err == nil {
+		before := sign == "<"
+		return func(s *models.Secret, _ time.Time) bool {
+			if before {
+				return field(s).Before(date)
+			}
+			return !field(s).Before(date)
+		}, nil
+	}
+
+	age, err := parseAge(value)
+	if err != nil {
+		return nil, err
+	}
+
+	older := sign == ">"
+	return func(s *models.Secret, now time.Time) bool {
+		if older {
+			return field(s).Before(now.Add(-age))
+		}
+		return !field(s).Before(now.Add(-age))
+	}, nil
+}
+
+// Parse age like "12h", "30d", "2w", "6m" or "1y"
+func parseAge(value string) (time.Duration, error) {
+	units := map[byte]time.Duration{
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+		'm': 30 * 24 * time.Hour,
+		'y': 365 * 24 * time.Hour,
+	}
+
+	if value == "" {
+		return 0, errors.New("empty age")
+	}
+
+	unit, ok := units[value[len(value)-1]]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit of %q, want h, d, w, m, y or date YYYY-MM-DD", value)
+	}
+
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("bad number in %q", value)
+	}
+
+	return time.Duration(n) * unit, nil
+}
@@ -0,0 +1,81 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	q, err := Parse(`mail "bank card" -old https://example.com type:card,text -tag:#archive`)
+	require.NoError(t, err)
+
+	assert.Equal(t, []term{
+		{text: "mail"},
+		{text: "bank card"},
+		{text: "old", negate: true},
+		{text: "https://example.com"},
+	}, q.terms)
+	assert.Len(t, q.filters, 2)
+	assert.True(t, q.filters[1].negate)
+
+	q, err = Parse("  ")
+	require.NoError(t, err)
+	assert.True(t, q.Empty())
+
+	for _, bad := range []string{"updated:<30x", "updated:", "created:>-1d", "updated:<d"} {
+		_, err = Parse(bad)
+		assert.ErrorIs(t, err, ErrBadFilter, bad)
+	}
+}
+
+func TestParse_Filters(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.Local)
+
+	secret := &models.Secret{
+		SecretType: string(models.CardSecret),
+		Folder:     "work/bank",
+		Tags:       models.Tags{"Finance"},
+		CreatedAt:  time.Date(2024, 1, 15, 0, 0, 0, 0, time.Local),
+		UpdatedAt:  now.Add(-10 * 24 * time.Hour),
+	}
+
+	tests := []struct {
+		query string
+		match bool
+	}{
+		{"type:card", true},
+		{"type:cred,card", true},
+		{"type:text", false},
+		{"-type:text", true},
+		{"folder:work", true},
+		{"folder:work/mail", false},
+		{"tag:finance", true},
+		{"tag:#finance", true},
+		{"tag:mail", false},
+		{"updated:<30d", true},
+		{"updated:30d", true},
+		{"updated:<1w", false},
+		{"updated:>1w", true},
+		{"updated:>1m", false},
+		{"created:>2024-01-01", true},
+		{"created:2024-02-01", false},
+		{"created:<2024-02-01", true},
+		{"created:<1y", false},
+		{"type:card updated:<30d folder:work", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			q, err := Parse(tt.query)
+			require.NoError(t, err)
+
+			_, ok := q.Match(secret, now)
+			assert.Equal(t, tt.match, ok)
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// SSH agent serving keys of ssh_key secrets from unlocked storage, asking user to confirm every signature
+package sshagent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/sshkey"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// How long signature waits for user's answer before it is denied
+const ConfirmTimeout = time.Minute
+
+var (
+	ErrReadOnly  = errors.New("agent serves keys of keeper storage, add and remove them in keeper")
+	ErrNoKey     = errors.New("key not found in storage")
+	ErrDenied    = errors.New("signature denied by user")
+	ErrNoConfirm = errors.New("signature not confirmed in time")
+)
+
+// Signature request waiting for user's answer
+type Confirmation struct {
+	Title       string // title of secret
+	Fingerprint string // fingerprint of key
+	reply       chan bool
+}
+
+// Allow signature, answers after the first are ignored
+func (c *Confirmation) Allow() { c.answer(true) }
+
+// Deny signature
+func (c *Confirmation) Deny() { c.answer(false) }
+
+func (c *Confirmation) answer(allow bool) {
+	select {
+	case c.reply <- allow:
+	default:
+	}
+}
+
+// Asks user whether to sign with key, blocking until answer
+type ConfirmFunc func(ctx context.Context, c *Confirmation) error
+
+// Read-only agent over ssh_key secrets of storage
+type Agent struct {
+	store   storage.Storage
+	confirm ConfirmFunc
+}
+
+var _ agent.ExtendedAgent = (*Agent)(nil)
+
+func New(store storage.Storage, confirm ConfirmFunc) *Agent {
+	return &Agent{store: store, confirm: confirm}
+}
+
+// Key of secret with its parsed public key
+type entry struct {
+	secret *models.Secret
+	pub    ssh.PublicKey
+}
+
+// Keys of all ssh_key secrets, secrets with unparsable keys are skipped
+func (a *Agent) entries() ([]entry, error) {
+	secrets, err := a.store.GetAll(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	var res []entry
+	for _, s := range secrets {
+		if s.SSH == nil {
+			continue
+		}
+
+		pub, err := publicKey(s.SSH)
+		if err != nil {
+			continue
+		}
+
+		res = append(res, entry{secret: s, pub: pub})
+	}
+
+	return res, nil
+}
+
+// Stored public key, or one derived from private key for keys saved without it
+func publicKey(key *models.SSHKey) (ssh.PublicKey, error) {
+	if key.PublicKey != "" {
+		return sshkey.ParsePublic(key.PublicKey)
+	}
+
+	signer, err := sshkey.Parse(key.PrivateKey, key.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	return signer.PublicKey(), nil
+}
+
+func (a *Agent) List() ([]*agent.Key, error) {
+	entries, err := a.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*agent.Key, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, &agent.Key{
+			Format:  e.pub.Type(),
+			Blob:    e.pub.Marshal(),
+			Comment: e.secret.Title,
+		})
+	}
+
+	return keys, nil
+}
+
+func (a *Agent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	return a.SignWithFlags(key, data, 0)
+}
+
+// Sign data after user allows it. Private key is parsed for this signature only.
+func (a *Agent) SignWithFlags(key ssh.PublicKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	entries, err := a.entries()
+	if err != nil {
+		return nil, err
+	}
+
+	blob := key.Marshal()
+	for _, e := range entries {
+		if !bytes.Equal(e.pub.Marshal(), blob) {
+			continue
+		}
+
+		if err := a.ask(e); err != nil {
+			return nil, err
+		}
+
+		return sign(e.secret.SSH, data, flags)
+	}
+
+	return nil, ErrNoKey
+}
+
+func (a *Agent) ask(e entry) error {
+	if a.confirm == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ConfirmTimeout)
+	defer cancel()
+
+	return a.confirm(ctx, &Confirmation{
+		Title:       e.secret.Title,
+		Fingerprint: ssh.FingerprintSHA256(e.pub),
+		reply:       make(chan bool, 1),
+	})
+}
+
+func sign(key *models.SSHKey, data []byte, flags agent.SignatureFlags) (*ssh.Signature, error) {
+	signer, err := sshkey.Parse(key.PrivateKey, key.Passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	// RSA keys sign with SHA-2 when client asks for it
+	algo := ""
+	switch {
+	case flags&agent.SignatureFlagRsaSha256 != 0:
+		algo = ssh.KeyAlgoRSASHA256
+	case flags&agent.SignatureFlagRsaSha512 != 0:
+		algo = ssh.KeyAlgoRSASHA512
+	}
+
+	if algo != "" && signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		if as, ok := signer.(ssh.AlgorithmSigner); ok {
+			return as.SignWithAlgorithm(nil, data, algo)
+		}
+	}
+
+	return signer.Sign(nil, data)
+}
+
+func (a *Agent) Add(agent.AddedKey) error       { return ErrReadOnly }
+func (a *Agent) Remove(ssh.PublicKey) error     { return ErrReadOnly }
+func (a *Agent) RemoveAll() error               { return ErrReadOnly }
+func (a *Agent) Lock([]byte) error              { return ErrReadOnly }
+func (a *Agent) Unlock([]byte) error            { return ErrReadOnly }
+func (a *Agent) Signers() ([]ssh.Signer, error) { return nil, ErrReadOnly }
+func (a *Agent) Extension(string, []byte) ([]byte, error) {
+	return nil, agent.ErrExtensionUnsupported
+}
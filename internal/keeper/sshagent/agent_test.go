@@ -0,0 +1,165 @@
+package sshagent
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/sshkey"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func newTestVault(t *testing.T, keys map[string]sshkey.KeyType) storage.Storage {
+	encrypter := crypto.NewVaultEncrypter(crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1})
+
+	store, err := storage.NewLocal(storage.EngineVault, filepath.Join(t.TempDir(), "vault.db"), "password", encrypter)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+
+	for title, kt := range keys {
+		private, err := sshkey.Generate(kt, "")
+		require.NoError(t, err)
+
+		info, err := sshkey.Inspect(private, "", "")
+		require.NoError(t, err)
+
+		require.NoError(t, store.Create(context.Background(), &models.Secret{
+			Title:      title,
+			SecretType: string(models.SSHKeySecret),
+			SSH:        &models.SSHKey{PrivateKey: private, PublicKey: info.PublicKey},
+		}))
+	}
+
+	// Not a key, must be ignored
+	require.NoError(t, store.Create(context.Background(), &models.Secret{
+		Title:      "mail",
+		SecretType: string(models.CredSecret),
+		Creds:      &models.Credentials{Login: "alice", Password: "secret"},
+	}))
+
+	return store
+}
+
+// Socket in short temporary path, Unix socket paths are limited to about 100 bytes
+func socketPath(t *testing.T) string {
+	dir, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	return filepath.Join(dir, "agent.sock")
+}
+
+func dial(t *testing.T, path string) agent.ExtendedAgent {
+	conn, err := net.Dial("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return agent.NewClient(conn)
+}
+
+func TestAgent(t *testing.T) {
+	store := newTestVault(t, map[string]sshkey.KeyType{"github": sshkey.Ed25519, "server": sshkey.RSA})
+
+	allow := true
+	var asked []string
+	confirm := func(_ context.Context, c *Confirmation) error {
+		asked = append(asked, c.Title)
+		if !allow {
+			return ErrDenied
+		}
+		return nil
+	}
+
+	path := socketPath(t)
+	srv, err := Listen(path, New(store, confirm))
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	_, err = Listen(path, New(store, confirm))
+	assert.Error(t, err, "socket is busy")
+
+	client := dial(t, path)
+
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	data := []byte("session")
+	for _, key := range keys {
+		sig, err := client.Sign(key, data)
+		require.NoError(t, err)
+		assert.NoError(t, key.Verify(data, sig))
+	}
+	assert.ElementsMatch(t, []string{"github", "server"}, asked)
+
+	// SHA-2 signature of RSA key
+	for _, key := range keys {
+		if key.Type() != ssh.KeyAlgoRSA {
+			continue
+		}
+
+		sig, err := client.SignWithFlags(key, data, agent.SignatureFlagRsaSha512)
+		require.NoError(t, err)
+		assert.Equal(t, ssh.KeyAlgoRSASHA512, sig.Format)
+		assert.NoError(t, key.Verify(data, sig))
+	}
+
+	allow = false
+	_, err = client.Sign(keys[0], data)
+	assert.Error(t, err)
+
+	assert.Error(t, client.Add(agent.AddedKey{}))
+	assert.Error(t, client.RemoveAll())
+
+	require.NoError(t, srv.Close())
+	_, err = os.Stat(path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestStart_AskUser(t *testing.T) {
+	store := newTestVault(t, map[string]sshkey.KeyType{"github": sshkey.Ed25519})
+
+	path := socketPath(t)
+	_, err := Start(store, path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = Stop() })
+
+	assert.Equal(t, store, Serving())
+
+	go func() {
+		c := <-Confirmations()
+		if c.Title == "github" {
+			c.Allow()
+		} else {
+			c.Deny()
+		}
+	}()
+
+	client := dial(t, path)
+	keys, err := client.List()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+
+	sig, err := client.Sign(keys[0], []byte("data"))
+	require.NoError(t, err)
+	assert.NoError(t, keys[0].Verify([]byte("data"), sig))
+
+	go func() { (<-Confirmations()).Deny() }()
+	_, err = client.Sign(keys[0], []byte("data"))
+	assert.Error(t, err)
+
+	require.NoError(t, Stop())
+	assert.Nil(t, Serving())
+}
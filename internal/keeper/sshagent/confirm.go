@@ -0,0 +1,32 @@
+package sshagent
+
+import (
+	"context"
+)
+
+// Signature requests for TUI, see AskUser
+var confirmations = make(chan *Confirmation)
+
+// Signature requests waiting for user's answer
+func Confirmations() <-chan *Confirmation {
+	return confirmations
+}
+
+// Pass request to TUI through Confirmations and wait for answer
+func AskUser(ctx context.Context, c *Confirmation) error {
+	select {
+	case confirmations <- c:
+	case <-ctx.Done():
+		return ErrNoConfirm
+	}
+
+	select {
+	case allow := <-c.reply:
+		if !allow {
+			return ErrDenied
+		}
+		return nil
+	case <-ctx.Done():
+		return ErrNoConfirm
+	}
+}
@@ -0,0 +1,155 @@
+package sshagent
+
+import (
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/storage"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/ssh/agent"
+)
+
+// Agent listening on Unix socket
+type Server struct {
+	path     string
+	listener net.Listener
+	wg       sync.WaitGroup
+}
+
+// Serve agent on Unix socket at path, readable by current user only.
+// Stale socket left by crashed keeper is replaced.
+func Listen(path string, a agent.Agent) (*Server, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	if conn, err := net.Dial("unix", path); err == nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("agent is already running on %s", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = listener.Close()
+		return nil, fmt.Errorf("failed to protect socket: %w", err)
+	}
+
+	srv := &Server{path: path, listener: listener}
+
+	srv.wg.Add(1)
+	go srv.serve(a)
+
+	return srv, nil
+}
+
+func (srv *Server) serve(a agent.Agent) {
+	defer srv.wg.Done()
+
+	for {
+		conn, err := srv.listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				log.Println("ssh-agent: accept failed:", err)
+			}
+			return
+		}
+
+		go func() {
+			defer conn.Close()
+			if err := agent.ServeAgent(a, conn); err != nil && !errors.Is(err, io.EOF) {
+				log.Println("ssh-agent: connection closed:", err)
+			}
+		}()
+	}
+}
+
+// Path of socket, value for SSH_AUTH_SOCK
+func (srv *Server) Path() string {
+	return srv.path
+}
+
+// Stop accepting connections and remove socket
+func (srv *Server) Close() error {
+	err := srv.listener.Close()
+	srv.wg.Wait()
+
+	if rmErr := os.Remove(srv.path); rmErr != nil && !errors.Is(rmErr, os.ErrNotExist) {
+		err = errors.Join(err, rmErr)
+	}
+
+	return err
+}
+
+// Socket in user runtime dir, or in per-user dir under temp dir when there is none
+func DefaultSocket() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "gophkeeper", "agent.sock")
+	}
+
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gophkeeper-%d", os.Getuid()), "agent.sock")
+}
+
+// Agent started from TUI, at most one per process
+var running = struct {
+	sync.Mutex
+	srv   *Server
+	store storage.Storage
+}{}
+
+// Serve ssh_key secrets of storage on socket at path, asking user through Confirmations.
+// Agent already running for another storage is stopped first.
+func Start(store storage.Storage, path string) (*Server, error) {
+	running.Lock()
+	defer running.Unlock()
+
+	if running.srv != nil {
+		if err := running.srv.Close(); err != nil {
+			return nil, err
+		}
+		running.srv, running.store = nil, nil
+	}
+
+	srv, err := Listen(path, New(store, AskUser))
+	if err != nil {
+		return nil, err
+	}
+
+	running.srv, running.store = srv, store
+
+	return srv, nil
+}
+
+// Stop running agent, if any
+func Stop() error {
+	running.Lock()
+	defer running.Unlock()
+
+	if running.srv == nil {
+		return nil
+	}
+
+	err := running.srv.Close()
+	running.srv, running.store = nil, nil
+
+	return err
+}
+
+// Storage served by running agent, nil when agent is stopped
+func Serving() storage.Storage {
+	running.Lock()
+	defer running.Unlock()
+
+	return running.store
+}
@@ -0,0 +1,156 @@
+// Parsing and generation of SSH private keys kept in ssh_key secrets
+package sshkey
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// Type of generated key
+type KeyType string
+
+const (
+	Ed25519 KeyType = "ed25519"
+	RSA     KeyType = "rsa"
+)
+
+const RSABits = 3072
+
+var (
+	ErrPassphraseRequired = errors.New("private key is encrypted, enter passphrase")
+	ErrNoKey              = errors.New("no private key")
+)
+
+// Public part of private key
+type Info struct {
+	Type        string // e.g. ssh-ed25519
+	PublicKey   string // authorized_keys line with comment
+	Fingerprint string // SHA256:...
+}
+
+// Signer of private key in OpenSSH or PEM format, passphrase is used for encrypted keys only
+func Parse(privateKey string, passphrase string) (ssh.Signer, error) {
+	if strings.TrimSpace(privateKey) == "" {
+		return nil, ErrNoKey
+	}
+
+	signer, err := ssh.ParsePrivateKey([]byte(privateKey))
+
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		if passphrase == "" {
+			return nil, ErrPassphraseRequired
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(privateKey), []byte(passphrase))
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+
+	return signer, nil
+}
+
+// Public key and fingerprint of private key
+func Inspect(privateKey string, passphrase string, comment string) (Info, error) {
+	signer, err := Parse(privateKey, passphrase)
+	if err != nil {
+		return Info{}, err
+	}
+
+	return Describe(signer.PublicKey(), comment), nil
+}
+
+// Info of public key
+func Describe(pub ssh.PublicKey, comment string) Info {
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pub)))
+	if comment != "" {
+		line += " " + comment
+	}
+
+	return Info{
+		Type:        pub.Type(),
+		PublicKey:   line,
+		Fingerprint: ssh.FingerprintSHA256(pub),
+	}
+}
+
+// Parse authorized_keys line
+func ParsePublic(line string) (ssh.PublicKey, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(line))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key: %w", err)
+	}
+
+	return pub, nil
+}
+
+// New unencrypted private key in OpenSSH format
+func Generate(t KeyType, comment string) (string, error) {
+	var (
+		priv crypto.PrivateKey
+		err  error
+	)
+
+	switch t {
+	case Ed25519:
+		_, priv, err = ed25519.GenerateKey(rand.Reader)
+	case RSA:
+		priv, err = rsa.GenerateKey(rand.Reader, RSABits)
+	default:
+		return "", fmt.Errorf("unknown key type %q", t)
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to generate %s key: %w", t, err)
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %s key: %w", t, err)
+	}
+
+	return string(pem.EncodeToMemory(block)), nil
+}
+
+// Private key from pasted text or path to key file. Text inputs turn line breaks of pasted
+// PEM into spaces, so lines of key body are restored.
+func FromInput(value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	if !strings.HasPrefix(value, "-----BEGIN ") {
+		data, err := os.ReadFile(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to read key file: %w", err)
+		}
+		return string(data), nil
+	}
+
+	if strings.Contains(value, "\n") {
+		return value + "\n", nil
+	}
+
+	// "-----BEGIN X-----" body "-----END X-----"
+	parts := strings.SplitN(value, "-----", 5)
+	if len(parts) < 5 {
+		return "", errors.New("malformed PEM")
+	}
+
+	var b strings.Builder
+	b.WriteString("-----" + parts[1] + "-----\n")
+	for _, line := range strings.Fields(parts[2]) {
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("-----" + parts[3] + "-----" + parts[4] + "\n")
+
+	return b.String(), nil
+}
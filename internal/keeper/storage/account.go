@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gophkeeper/pkg/models"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KDF params of account are not secret and kept next to its replica: they let replica be opened offline,
+// and once saved, server claiming account is legacy is not trusted with password. Likewise once account
+// logged in with SRP, server asking for auth key is not trusted with it.
+
+// What client remembers about server account
+type AccountParams struct {
+	models.AccountKDF
+	SRP bool `json:"srp,omitempty"`
+}
+
+// Path of KDF params of user of server at address, inside dir
+func AccountKDFPath(dir string, address string, login string) string {
+	return strings.TrimSuffix(ReplicaPath(dir, address, login), ".db") + ".kdf"
+}
+
+// Params saved by SaveAccountParams, os.ErrNotExist for legacy or unknown accounts
+func LoadAccountParams(path string) (AccountParams, error) {
+	var params AccountParams
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return params, err
+	}
+
+	if err := json.Unmarshal(data, &params); err != nil {
+		return params, fmt.Errorf("failed to decode account KDF params: %w", err)
+	}
+
+	return params, params.Validate()
+}
+
+func SaveAccountParams(path string, params AccountParams) error {
+	if params.Legacy() {
+		return errors.New("no KDF params for legacy account")
+	}
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := replaceFile(path, data)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
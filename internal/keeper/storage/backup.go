@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Previous vault generations are kept next to the vault as <path>.bak.1 (newest) .. <path>.bak.N (oldest).
+// Backups are plain copies of encrypted vault file, re-encrypted when vault password changes,
+// so a leaked old password opens none of them.
+
+const DefaultBackups = 3
+
+const backupSuffix = ".bak."
+
+// Path of n-th backup generation
+func backupPath(path string, gen int) string {
+	return path + backupSuffix + strconv.Itoa(gen)
+}
+
+// List existing backups of vault at path, newest first
+func ListBackups(path string) ([]string, error) {
+	matches, err := filepath.Glob(filepath.Join(filepath.Dir(path), filepath.Base(path)+backupSuffix+"*"))
+	if err != nil {
+		return nil, fmt.Errorf("ListBackups(): %w", err)
+	}
+
+	gens := make(map[string]int, len(matches))
+	backups := make([]string, 0, len(matches))
+
+	for _, m := range matches {
+		gen, err := strconv.Atoi(strings.TrimPrefix(m, path+backupSuffix))
+		if err != nil || gen < 1 {
+			continue // not ours
+		}
+
+		gens[m] = gen
+		backups = append(backups, m)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return gens[backups[i]] < gens[backups[j]]
+	})
+
+	return backups, nil
+}
+
+// Restore vault at path from the newest backup that can be decrypted with password.
+// Damaged vault is kept as <path>.corrupt. Returns opened storage and used backup path.
+func RestoreFromBackup(path string, password string, encrypter crypto.Encrypter, opts ...LocalOption) (*FileStorage, string, error) {
+	backups, err := ListBackups(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, backup := range backups {
+		encryptedData, err := os.ReadFile(backup)
+		if err != nil {
+			continue
+		}
+
+		store := newFileStorage(password, encrypter, opts...)
+		if store.opts.readOnly {
+			return nil, "", entities.ErrReadOnly
+		}
+
+		if err := store.load(encryptedData); err != nil {
+			continue
+		}
+
+		if err := closeOpened(context.Background(), path); err != nil {
+			return nil, "", fmt.Errorf("RestoreFromBackup(): %w", err)
+		}
+
+		if err := store.restore(path, encryptedData); err != nil {
+			return nil, "", fmt.Errorf("RestoreFromBackup(): %w", err)
+		}
+
+		if err := store.watch(); err != nil {
+			log.Printf("RestoreFromBackup(): live reload disabled: %v", err)
+		}
+
+		registerOpened(path, store)
+
+		return store, backup, nil
+	}
+
+	return nil, "", entities.ErrNoBackups
+}
+
+// Lock vault at path and replace it with backup data, keeping damaged file
+func (store *FileStorage) restore(path string, encryptedData []byte) (err error) {
+	if store.lock, err = lockVault(path); err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = store.lock.unlock()
+		}
+	}()
+
+	if _, err := os.Stat(path); err == nil {
+		if err := copyFile(path, path+".corrupt"); err != nil {
+			return fmt.Errorf("failed to keep damaged vault: %w", err)
+		}
+	}
+
+	store.path = path
+	if store.file, err = replaceFile(path, encryptedData); err != nil {
+		return err
+	}
+
+	store.loaded, err = statFile(path, encryptedData)
+
+	return err
+}
+
+// Shift backup generations and make current vault file the newest one
+func rotateBackups(path string, keep int) error {
+	if keep <= 0 {
+		return nil
+	}
+
+	// Nothing to keep for new vault
+	if info, err := os.Stat(path); err != nil || info.Size() == 0 {
+		return nil
+	}
+
+	if err := os.Remove(backupPath(path, keep)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotateBackups(): failed to drop oldest backup: %w", err)
+	}
+
+	for gen := keep - 1; gen >= 1; gen-- {
+		if err := os.Rename(backupPath(path, gen), backupPath(path, gen+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("rotateBackups(): failed to shift backup: %w", err)
+		}
+	}
+
+	// Vault file is replaced by rename, so hard link keeps current generation without copying
+	if err := os.Link(path, backupPath(path, 1)); err != nil {
+		if err := copyFile(path, backupPath(path, 1)); err != nil {
+			return fmt.Errorf("rotateBackups(): failed to backup vault: %w", err)
+		}
+	}
+
+	// Vaults created by older versions were readable by everyone
+	if err := os.Chmod(backupPath(path, 1), vaultFileMode); err != nil {
+		return fmt.Errorf("rotateBackups(): %w", err)
+	}
+
+	return nil
+}
+
+// Re-encrypt backup generations of vault at path with new password.
+// Backups which old password does not open are dropped, nothing is left behind under old password.
+func reencryptBackups(path string, encrypter crypto.Encrypter, oldPassword string, newPassword string) error {
+	backups, err := ListBackups(path)
+	if err != nil {
+		return err
+	}
+
+	for _, backup := range backups {
+		if err := reencryptFile(backup, encrypter, oldPassword, newPassword); err != nil {
+			log.Printf("reencryptBackups(): dropping %s: %v", backup, err)
+
+			if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("reencryptBackups(): failed to drop backup: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func reencryptFile(path string, encrypter crypto.Encrypter, oldPassword string, newPassword string) error {
+	encryptedData, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := newFileStorage(oldPassword, encrypter).DecryptWithRecover(encryptedData, oldPassword)
+	if err != nil {
+		return err
+	}
+
+	if encryptedData, err = encrypter.Encrypt(data, newPassword); err != nil {
+		return err
+	}
+
+	file, err := replaceFile(path, encryptedData)
+	if err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+func copyFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, vaultFileMode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorageBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+	password := "password"
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, password, encrypter, WithBackups(2))
+	require.NoError(t, err)
+
+	backups, err := ListBackups(path)
+	require.NoError(t, err)
+	assert.Empty(t, backups, "new vault has no previous generations")
+
+	for _, title := range []string{"first", "second", "third"} {
+		require.NoError(t, store.Create(context.Background(), &models.Secret{Title: title}))
+	}
+	require.NoError(t, store.Close(context.Background()))
+
+	t.Run("Rotation", func(t *testing.T) {
+		backups, err := ListBackups(path)
+		require.NoError(t, err)
+		assert.Equal(t, []string{path + ".bak.1", path + ".bak.2"}, backups)
+
+		// Newest backup is the generation before last save
+		data, err := os.ReadFile(backups[0])
+		require.NoError(t, err)
+
+		prev := newFileStorage(password, encrypter)
+		require.NoError(t, prev.load(data))
+		assert.Len(t, prev.Data, 2)
+
+		for _, backup := range backups {
+			info, err := os.Stat(backup)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm(), backup)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("garbage"), 0644))
+
+		_, err := NewFileStorage(path, password, encrypter)
+		require.Error(t, err)
+
+		restored, backup, err := RestoreFromBackup(path, password, encrypter)
+		require.NoError(t, err)
+		assert.Equal(t, path+".bak.1", backup)
+
+		secrets, err := restored.GetAll(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+		require.NoError(t, restored.Close(context.Background()))
+
+		damaged, err := os.ReadFile(path + ".corrupt")
+		require.NoError(t, err)
+		assert.Equal(t, []byte("garbage"), damaged)
+
+		info, err := os.Stat(path + ".corrupt")
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+		_, err = NewFileStorage(path, password, encrypter)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Restore with bad password", func(t *testing.T) {
+		_, _, err := RestoreFromBackup(path, "wrong", encrypter)
+		assert.ErrorIs(t, err, entities.ErrNoBackups)
+	})
+}
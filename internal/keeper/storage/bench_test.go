@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/pkg/models"
+)
+
+const benchSecrets = 10_000
+
+func benchSecret(i int) *models.Secret {
+	secret := models.NewSecret(models.CredSecret)
+	secret.Title = fmt.Sprintf("secret #%d", i)
+	secret.Creds = &models.Credentials{Login: fmt.Sprintf("user%d", i), Password: "correct horse battery staple"}
+
+	return secret
+}
+
+// Update one secret in a storage holding benchSecrets secrets
+func benchmarkUpdate(b *testing.B, store Storage) {
+	ctx := context.Background()
+
+	secret, err := store.Get(ctx, 1)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		secret.Title = fmt.Sprintf("update #%d", i)
+		if err := store.Update(ctx, secret); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFileStorageUpdate10k(b *testing.B) {
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(filepath.Join(b.TempDir(), "vault.db"), "password", encrypter, WithBackups(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close(context.Background())
+
+	// Fill in memory and save once, filling via Create is quadratic
+	for i := 1; i <= benchSecrets; i++ {
+		store.Data[uint64(i)] = *benchSecret(i)
+	}
+	if err := store.dump(); err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkUpdate(b, store)
+}
+
+func BenchmarkJournalStorageUpdate10k(b *testing.B) {
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(filepath.Join(b.TempDir(), "journal.db"), "password", encrypter)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer store.Close(context.Background())
+
+	for i := 1; i <= benchSecrets; i++ {
+		store.Data[uint64(i)] = *benchSecret(i)
+	}
+	store.lastID = benchSecrets
+	if err := store.Compact(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+
+	benchmarkUpdate(b, store)
+}
+
+func BenchmarkJournalStorageOpen10k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 1; i <= benchSecrets; i++ {
+		store.Data[uint64(i)] = *benchSecret(i)
+	}
+	if err := store.Compact(context.Background()); err != nil {
+		b.Fatal(err)
+	}
+	_ = store.Close(context.Background())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store, err := NewJournalStorage(path, "password", encrypter)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = store.Close(context.Background())
+	}
+}
+
+func BenchmarkFileStorageOpen10k(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "vault.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "password", encrypter, WithBackups(0))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for i := 1; i <= benchSecrets; i++ {
+		store.Data[uint64(i)] = *benchSecret(i)
+	}
+	if err := store.dump(); err != nil {
+		b.Fatal(err)
+	}
+	_ = store.Close(context.Background())
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		store, err := NewFileStorage(path, "password", encrypter, WithBackups(0))
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = store.Close(context.Background())
+	}
+}
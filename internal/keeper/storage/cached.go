@@ -0,0 +1,993 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/utils"
+	"gophkeeper/pkg/models"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Interval between background attempts to deliver queued operations
+const DefaultSyncInterval = 10 * time.Second
+
+// IDs of secrets created offline, replaced by server IDs once delivered
+const localIDBase uint64 = 1 << 62
+
+// Random bytes in create key
+const createKeyLen = 16
+
+var (
+	_ Storage          = (*CachedStorage)(nil)
+	_ QueuedStorage    = (*CachedStorage)(nil)
+	_ ConflictResolver = (*CachedStorage)(nil)
+	_ BatchCreator     = (*CachedStorage)(nil)
+	_ HistoryKeeper    = (*CachedStorage)(nil)
+	_ TrashKeeper      = (*CachedStorage)(nil)
+	_ SessionKeeper    = (*CachedStorage)(nil)
+	_ TwoFactorKeeper  = (*CachedStorage)(nil)
+)
+
+// Kind of queued operation
+type OpKind string
+
+const (
+	OpCreate OpKind = "create"
+	OpUpdate OpKind = "update"
+	OpDelete OpKind = "delete"
+)
+
+// Delivery state of queued operation
+type OpState string
+
+const (
+	OpPending OpState = "pending" // waiting for server
+	OpFailed  OpState = "failed"  // rejected by server, kept until retried or discarded
+)
+
+// Operation waiting in outbox
+type OutboxOp struct {
+	Seq      uint64         `json:"seq"`
+	Kind     OpKind         `json:"kind"`
+	SecretID uint64         `json:"secret_id"`
+	Secret   *models.Secret `json:"secret,omitempty"`
+	State    OpState        `json:"state"`
+	Error    string         `json:"error,omitempty"`
+	Theirs   *models.Secret `json:"theirs,omitempty"` // server copy, if rejected as conflicting
+	Key      string         `json:"key,omitempty"`    // create key, server returns the secret made first when create is retried
+	Attempts int            `json:"attempts"`
+	QueuedAt time.Time      `json:"queued_at"`
+}
+
+// Storage delivering changes through a persistent queue
+type QueuedStorage interface {
+	Online() bool
+	Outbox() []OutboxOp
+	Sync(ctx context.Context) error
+	RetryFailed(ctx context.Context) error
+	DiscardFailed(ctx context.Context) error
+}
+
+// Replica file contents
+type replicaData struct {
+	Secrets     map[uint64]models.Secret `json:"secrets"`
+	Outbox      []OutboxOp               `json:"outbox"`
+	NextSeq     uint64                   `json:"next_seq"`
+	NextLocalID uint64                   `json:"next_local_id"`
+	Cursor      string                   `json:"cursor,omitempty"` // delta sync position of Secrets
+}
+
+// Remote storage with encrypted local replica.
+// Reads are served from replica, changes are applied to replica and queued in outbox,
+// which is delivered to server right away or later, when it becomes reachable.
+// Mutex guards replica and is not held during network calls, syncing lets one sync run at a time.
+type CachedStorage struct {
+	sync.Mutex
+	syncing sync.Mutex
+
+	remote *RemoteStorage
+	login  string // to log in again when token expired while offline
+
+	path      string
+	encrypter crypto.Encrypter
+	password  string
+	data      replicaData
+	lock      *vaultLock
+
+	online   bool
+	interval time.Duration
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+// Path of replica for user of server at address, inside dir
+func ReplicaPath(dir string, address string, login string) string {
+	sum := sha256.Sum256([]byte(address + "\n" + login))
+	return filepath.Join(dir, "replica-"+hex.EncodeToString(sum[:8])+".db")
+}
+
+// Whether replica exists at path
+func HasReplica(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Open or create replica at path for remote storage. Replica is encrypted with key of remote payloads.
+// Outbox left from previous runs is delivered in background every interval.
+func NewCachedStorage(remote *RemoteStorage, login string, path string, encrypter crypto.Encrypter, interval time.Duration) (*CachedStorage, error) {
+	store := &CachedStorage{
+		remote:    remote,
+		login:     login,
+		path:      path,
+		encrypter: encrypter,
+		password:  remote.password,
+		data:      replicaData{Secrets: make(map[uint64]models.Secret), NextLocalID: localIDBase},
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+
+	if err := closeOpened(context.Background(), path); err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("NewCachedStorage(): failed to create replica dir: %w", err)
+	}
+
+	var err error
+	if store.lock, err = lockVault(path); err != nil {
+		return nil, err
+	}
+
+	if err := store.load(); err != nil {
+		_ = store.lock.unlock()
+		return nil, err
+	}
+
+	registerOpened(path, store)
+
+	go store.run()
+
+	return store, nil
+}
+
+func (store *CachedStorage) Get(_ context.Context, id uint64) (*models.Secret, error) {
+	store.Lock()
+	defer store.Unlock()
+
+	secret, ok := store.data.Secrets[id]
+	if !ok {
+		return nil, entities.ErrSecretNotFound
+	}
+	secret.ID = id
+
+	return &secret, nil
+}
+
+// Refresh replica from server if reachable and return its contents
+func (store *CachedStorage) GetAll(ctx context.Context) ([]*models.Secret, error) {
+	if err := store.Sync(ctx); err != nil && !errors.Is(err, entities.ErrServerUnavailable) {
+		log.Printf("GetAll(): serving replica: %v", err)
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	arr := make([]*models.Secret, 0, len(store.data.Secrets))
+	for id, secret := range store.data.Secrets {
+		secret.ID = id
+		arr = append(arr, &secret)
+	}
+
+	return arr, nil
+}
+
+func (store *CachedStorage) Create(ctx context.Context, secret *models.Secret) error {
+	return store.CreateBatch(ctx, []*models.Secret{secret})
+}
+
+// Queue creation of secrets with a single replica write
+func (store *CachedStorage) CreateBatch(ctx context.Context, secrets []*models.Secret) error {
+	store.Lock()
+
+	for _, secret := range secrets {
+		saved := *secret
+		saved.ID = store.data.NextLocalID
+		saved.Payload = nil
+		store.data.NextLocalID++
+
+		store.data.Secrets[saved.ID] = saved
+		store.enqueue(OpCreate, saved.ID, &saved)
+	}
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return store.flush(ctx)
+}
+
+func (store *CachedStorage) Update(ctx context.Context, secret *models.Secret) error {
+	store.Lock()
+
+	saved := *secret
+	saved.Payload = nil
+
+	if _, ok := store.data.Secrets[saved.ID]; !ok {
+		store.Unlock()
+		return entities.ErrSecretNotFound
+	}
+
+	store.data.Secrets[saved.ID] = saved
+
+	// Fold into create or update still waiting in outbox
+	if op := store.queued(saved.ID, OpCreate, OpUpdate); op != nil {
+		op.Secret = &saved
+		op.State, op.Error, op.Theirs = OpPending, "", nil
+	} else {
+		store.enqueue(OpUpdate, saved.ID, &saved)
+	}
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	if err := store.flush(ctx); err != nil {
+		return err
+	}
+
+	// Server rejected the change right away, let caller resolve it
+	store.Lock()
+	defer store.Unlock()
+
+	if op := store.conflicted(saved.ID); op != nil {
+		return &entities.ConflictError{Theirs: op.Theirs}
+	}
+
+	return nil
+}
+
+func (store *CachedStorage) Delete(ctx context.Context, id uint64) error {
+	store.Lock()
+
+	delete(store.data.Secrets, id)
+
+	// Queued changes of this secret are not needed anymore
+	store.data.Outbox = slices.DeleteFunc(store.data.Outbox, func(op OutboxOp) bool {
+		return op.SecretID == id
+	})
+
+	// Secret created offline is gone with its queued create, others are deleted on server
+	if id < localIDBase {
+		store.enqueue(OpDelete, id, nil)
+	}
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return store.flush(ctx)
+}
+
+// Previous versions of secret, history is kept by server only
+func (store *CachedStorage) History(ctx context.Context, id uint64) ([]*models.Secret, error) {
+	// Secret created offline has no versions on server yet
+	if id >= localIDBase {
+		return nil, nil
+	}
+
+	return store.remote.History(ctx, id)
+}
+
+// Previous version of secret, fetched from server
+func (store *CachedStorage) Revision(ctx context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	if id >= localIDBase {
+		return nil, entities.ErrRevisionNotFound
+	}
+
+	return store.remote.Revision(ctx, id, revision)
+}
+
+// Trash is kept by server only, secrets created offline are deleted right away
+func (store *CachedStorage) Trashed(ctx context.Context) ([]*models.Secret, error) {
+	return store.remote.Trashed(ctx)
+}
+
+// Restore secret on server and pull it into replica
+func (store *CachedStorage) Restore(ctx context.Context, id uint64) error {
+	if err := store.remote.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	return store.Sync(ctx)
+}
+
+func (store *CachedStorage) Purge(ctx context.Context, id uint64) error {
+	return store.remote.Purge(ctx, id)
+}
+
+// Sessions are kept by server only
+func (store *CachedStorage) Sessions(ctx context.Context) ([]models.Session, error) {
+	return store.remote.Sessions(ctx)
+}
+
+func (store *CachedStorage) RevokeSession(ctx context.Context, id uint64) error {
+	return store.remote.RevokeSession(ctx, id)
+}
+
+// Second factor is setting of server account
+func (store *CachedStorage) TwoFactor(ctx context.Context) (models.TwoFactorStatus, error) {
+	return store.remote.TwoFactor(ctx)
+}
+
+func (store *CachedStorage) EnrollTwoFactor(ctx context.Context) (models.TOTPEnrollment, error) {
+	return store.remote.EnrollTwoFactor(ctx)
+}
+
+func (store *CachedStorage) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	return store.remote.ConfirmTwoFactor(ctx, code)
+}
+
+func (store *CachedStorage) DisableTwoFactor(ctx context.Context, code string) error {
+	return store.remote.DisableTwoFactor(ctx, code)
+}
+
+// End session on server and stop delivering outbox, queued changes stay in replica till next login
+func (store *CachedStorage) Logout(ctx context.Context) error {
+	err := store.remote.Logout(ctx)
+
+	return errors.Join(err, store.Close(ctx))
+}
+
+func (store *CachedStorage) String() string {
+	return "remote storage"
+}
+
+// Whether last attempt to reach server succeeded
+func (store *CachedStorage) Online() bool {
+	store.Lock()
+	defer store.Unlock()
+
+	return store.online
+}
+
+// Copy of operations waiting for delivery, oldest first
+func (store *CachedStorage) Outbox() []OutboxOp {
+	store.Lock()
+	defer store.Unlock()
+
+	return slices.Clone(store.data.Outbox)
+}
+
+// Deliver pending operations and refresh replica from server.
+// Returns entities.ErrServerUnavailable when server can not be reached.
+func (store *CachedStorage) Sync(ctx context.Context) error {
+	store.syncing.Lock()
+	defer store.syncing.Unlock()
+
+	return store.sync(ctx)
+}
+
+// Queue failed operations again and sync
+func (store *CachedStorage) RetryFailed(ctx context.Context) error {
+	store.Lock()
+
+	for i := range store.data.Outbox {
+		if op := &store.data.Outbox[i]; op.State == OpFailed {
+			op.State, op.Error = OpPending, ""
+		}
+	}
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return store.Sync(ctx)
+}
+
+// Drop failed operations. Replica is refreshed from server, reverting their local effect.
+func (store *CachedStorage) DiscardFailed(ctx context.Context) error {
+	store.Lock()
+
+	store.data.Outbox = slices.DeleteFunc(store.data.Outbox, func(op OutboxOp) bool {
+		return op.State == OpFailed
+	})
+
+	// Server copies of reverted secrets may be older than cursor, fetch everything
+	store.data.Cursor = ""
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return store.Sync(ctx)
+}
+
+func (store *CachedStorage) Close(_ context.Context) error {
+	select {
+	case <-store.stop:
+		return nil // already closed
+	default:
+		close(store.stop)
+	}
+	<-store.done
+
+	// Let sync started by a change finish
+	store.syncing.Lock()
+	defer store.syncing.Unlock()
+
+	store.Lock()
+	defer store.Unlock()
+
+	if err := store.lock.unlock(); err != nil {
+		log.Printf("Close(): failed to release lock: %v", err)
+	}
+	store.lock = nil
+
+	unregisterOpened(store.path, store)
+
+	return nil
+}
+
+// Deliver outbox in background until closed
+func (store *CachedStorage) run() {
+	defer close(store.done)
+
+	if store.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(store.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-store.stop:
+			return
+		case <-ticker.C:
+			store.Lock()
+			pending := store.pending() > 0
+			store.Unlock()
+
+			if !pending {
+				continue
+			}
+
+			err := store.Sync(context.Background())
+			if err == nil {
+				notifyReload(store.path)
+			} else if !errors.Is(err, entities.ErrServerUnavailable) {
+				log.Printf("sync: %v", err)
+			}
+		}
+	}
+}
+
+// Try to deliver right after change, staying queued if server is unreachable
+func (store *CachedStorage) flush(ctx context.Context) error {
+	err := store.Sync(ctx)
+	if err != nil && !errors.Is(err, entities.ErrServerUnavailable) {
+		log.Printf("flush(): %v", err)
+	}
+
+	return nil
+}
+
+// Deliver outbox and refresh replica. Called with syncing held, takes store lock only around replica changes.
+func (store *CachedStorage) sync(ctx context.Context) error {
+	err := store.deliver(ctx)
+	if err == nil {
+		err = store.refresh(ctx)
+	}
+
+	store.Lock()
+	store.online = !errors.Is(err, entities.ErrServerUnavailable)
+	store.Unlock()
+
+	return err
+}
+
+// Send pending operations in order. Stops on unreachable server,
+// operations rejected by server are marked failed. Replica is saved once when the pass ends,
+// creates sent again after crash are recognized by server by their key.
+func (store *CachedStorage) deliver(ctx context.Context) (err error) {
+	relogged, settled := false, false
+	var after uint64 // operations up to this sequence are settled in this pass
+
+	defer func() {
+		if !settled {
+			return
+		}
+
+		store.Lock()
+		serr := store.save()
+		store.Unlock()
+
+		if err == nil {
+			err = serr
+		}
+	}()
+
+	for {
+		store.Lock()
+		op := store.nextPending(after)
+		if op == nil {
+			store.Unlock()
+			return nil
+		}
+
+		op.Attempts++
+		sent := *op
+		store.Unlock()
+
+		result, err := store.send(ctx, sent)
+		switch {
+		case errors.Is(err, entities.ErrServerUnavailable), errors.Is(err, entities.ErrSessionRevoked):
+			// Operation stays queued till server is back or user logs in again
+			return err
+		case errors.Is(err, entities.ErrUnauthenticated) && !relogged:
+			// Token expired or we were never logged in, retry the same operation
+			relogged = true
+			if err := store.relogin(ctx); err != nil {
+				return err
+			}
+			continue
+		}
+
+		store.Lock()
+		again := store.settle(sent, result, err)
+		store.Unlock()
+		settled = true
+
+		if !again {
+			after = sent.Seq
+		}
+	}
+}
+
+// First pending operation queued after given sequence
+func (store *CachedStorage) nextPending(after uint64) *OutboxOp {
+	for i := range store.data.Outbox {
+		if op := &store.data.Outbox[i]; op.State == OpPending && op.Seq > after {
+			return op
+		}
+	}
+
+	return nil
+}
+
+// Make server call of operation, store is not locked. Returns secret as saved by server for creates and updates.
+func (store *CachedStorage) send(ctx context.Context, op OutboxOp) (*models.Secret, error) {
+	switch op.Kind {
+	case OpCreate:
+		secret := *op.Secret
+		secret.ID = 0 // server assigns ID
+		secret.CreateKey = op.Key
+		err := store.remote.Create(ctx, &secret)
+		return &secret, err
+	case OpUpdate:
+		secret := *op.Secret
+		err := store.remote.Update(ctx, &secret)
+		return &secret, err
+	case OpDelete:
+		return nil, store.remote.Delete(ctx, op.SecretID)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Kind)
+	}
+}
+
+// Apply server answer to sent operation. Secret may have been edited or deleted while store was unlocked:
+// edits are folded into the queued operation by replacing its secret, deletes drop the operation.
+// Reports whether operation stays pending and has to be sent again.
+func (store *CachedStorage) settle(sent OutboxOp, result *models.Secret, err error) bool {
+	op := store.queuedSeq(sent.Seq)
+	edited := op != nil && op.Secret != sent.Secret
+
+	if err != nil {
+		// Dropped meanwhile, nothing to report
+		if op == nil {
+			return false
+		}
+
+		op.State, op.Error = OpFailed, err.Error()
+
+		var conflict *entities.ConflictError
+		if errors.As(err, &conflict) && conflict.Theirs != nil {
+			theirs := *conflict.Theirs
+			theirs.Payload = nil
+			op.Theirs = &theirs
+		}
+
+		return false
+	}
+
+	switch sent.Kind {
+	case OpCreate:
+		if op == nil {
+			// Deleted while being created, server copy has to go too
+			store.enqueue(OpDelete, result.ID, nil)
+			return false
+		}
+
+		// Secret moves to server ID, next refresh brings server copy
+		delete(store.data.Secrets, sent.SecretID)
+
+		if !edited {
+			store.dequeue(sent.Seq)
+
+			secret := *result
+			secret.Payload = nil
+			store.data.Secrets[secret.ID] = secret
+
+			return false
+		}
+
+		// Edits made meanwhile go as update of the created secret
+		newer := *op.Secret
+		newer.ID, newer.Revision = result.ID, result.Revision
+		store.data.Secrets[newer.ID] = newer
+
+		op.Kind, op.SecretID, op.Secret, op.Key = OpUpdate, newer.ID, &newer, ""
+
+		return true
+	case OpUpdate:
+		if !edited {
+			store.dequeue(sent.Seq)
+			return false
+		}
+
+		// Next update is based on the revision just made
+		newer := *op.Secret
+		newer.Revision = result.Revision
+		op.Secret = &newer
+		store.data.Secrets[newer.ID] = newer
+
+		return true
+	default:
+		store.dequeue(sent.Seq)
+		return false
+	}
+}
+
+func (store *CachedStorage) relogin(ctx context.Context) error {
+	client := store.remote.client
+
+	// Client forgets password when its session is ended, user has to log in again
+	if client.GetPassword() == "" {
+		return entities.ErrSessionRevoked
+	}
+
+	token, err := client.Login(ctx, store.login, client.GetPassword())
+	if errors.Is(err, entities.ErrTOTPRequired) {
+		// Code can not be asked for in background, user logs in again from login screen
+		return entities.ErrSessionRevoked
+	}
+	if err != nil {
+		return err
+	}
+
+	client.SetToken(token)
+
+	return nil
+}
+
+// Apply server changes since last refresh to replica. Secrets with operations still in outbox keep local state,
+// full listing replaces replica and re-applies the outbox. Replica is saved if its contents changed.
+func (store *CachedStorage) refresh(ctx context.Context) error {
+	store.Lock()
+	cursor := store.data.Cursor
+	store.Unlock()
+
+	changes, err := store.remote.Changes(ctx, cursor)
+	if errors.Is(err, entities.ErrUnauthenticated) {
+		if err = store.relogin(ctx); err == nil {
+			changes, err = store.remote.Changes(ctx, cursor)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	// Cursor was reset meanwhile, changes are applied on next sync
+	if store.data.Cursor != cursor {
+		return nil
+	}
+
+	if store.apply(changes) {
+		return store.save()
+	}
+
+	return nil
+}
+
+// Apply one batch of server changes to replica, reports whether replica contents changed
+func (store *CachedStorage) apply(changes *models.SecretChanges) bool {
+	changed := store.data.Cursor != changes.Cursor
+	store.data.Cursor = changes.Cursor
+
+	if changes.Reset {
+		return store.replace(changes.Updated) || changed
+	}
+
+	for _, s := range changes.Updated {
+		if store.queued(s.ID, OpCreate, OpUpdate, OpDelete) != nil {
+			continue
+		}
+
+		secret := *s
+		secret.Payload = nil
+		store.data.Secrets[secret.ID] = secret
+		changed = true
+	}
+
+	for _, id := range changes.Deleted {
+		if _, ok := store.data.Secrets[id]; !ok || store.queued(id, OpCreate, OpUpdate, OpDelete) != nil {
+			continue
+		}
+
+		delete(store.data.Secrets, id)
+		changed = true
+	}
+
+	return changed
+}
+
+// Replace replica with full server listing, then re-apply operations still in outbox.
+// Reports whether replica contents changed.
+func (store *CachedStorage) replace(secrets models.Secrets) bool {
+	fresh := make(map[uint64]models.Secret, len(secrets))
+	for _, s := range secrets {
+		secret := *s
+		secret.Payload = nil
+		fresh[secret.ID] = secret
+	}
+
+	for _, op := range store.data.Outbox {
+		switch op.Kind {
+		case OpCreate, OpUpdate:
+			fresh[op.SecretID] = *op.Secret
+		case OpDelete:
+			delete(fresh, op.SecretID)
+		}
+	}
+
+	if reflect.DeepEqual(fresh, store.data.Secrets) {
+		return false
+	}
+
+	store.data.Secrets = fresh
+
+	return true
+}
+
+// Settle change of secret rejected as conflicting
+func (store *CachedStorage) Resolve(ctx context.Context, id uint64, how Resolution) error {
+	store.Lock()
+
+	op := store.conflicted(id)
+	if op == nil {
+		store.Unlock()
+		return fmt.Errorf("Resolve(): no conflict for secret %d", id)
+	}
+
+	mine, theirs := *op.Secret, *op.Theirs
+
+	switch how {
+	case ResolveKeepMine:
+		// Next update is based on the revision we have seen
+		mine.Revision = theirs.Revision
+		op.Secret = &mine
+		op.State, op.Error, op.Theirs = OpPending, "", nil
+		store.data.Secrets[id] = mine
+	case ResolveTakeTheirs:
+		store.dequeue(op.Seq)
+		store.data.Secrets[id] = theirs
+	case ResolveDuplicate:
+		store.dequeue(op.Seq)
+		store.data.Secrets[id] = theirs
+
+		copied := mine
+		copied.ID = store.data.NextLocalID
+		copied.Revision = 0
+		copied.Title = mine.Title + " (conflict copy)"
+		store.data.NextLocalID++
+
+		store.data.Secrets[copied.ID] = copied
+		store.enqueue(OpCreate, copied.ID, &copied)
+	default:
+		store.Unlock()
+		return fmt.Errorf("Resolve(): unknown resolution %d", how)
+	}
+
+	err := store.save()
+	store.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return store.flush(ctx)
+}
+
+// Failed operation on secret carrying server copy, if any
+func (store *CachedStorage) conflicted(id uint64) *OutboxOp {
+	for i := range store.data.Outbox {
+		op := &store.data.Outbox[i]
+		if op.SecretID == id && op.State == OpFailed && op.Theirs != nil {
+			return op
+		}
+	}
+
+	return nil
+}
+
+// Queued operation with given sequence, if it is still in outbox
+func (store *CachedStorage) queuedSeq(seq uint64) *OutboxOp {
+	for i := range store.data.Outbox {
+		if op := &store.data.Outbox[i]; op.Seq == seq {
+			return op
+		}
+	}
+
+	return nil
+}
+
+func (store *CachedStorage) dequeue(seq uint64) {
+	store.data.Outbox = slices.DeleteFunc(store.data.Outbox, func(op OutboxOp) bool {
+		return op.Seq == seq
+	})
+}
+
+// Queued create or update of secret, if any
+func (store *CachedStorage) queued(id uint64, kinds ...OpKind) *OutboxOp {
+	for i := range store.data.Outbox {
+		op := &store.data.Outbox[i]
+		if op.SecretID == id && slices.Contains(kinds, op.Kind) {
+			return op
+		}
+	}
+
+	return nil
+}
+
+func (store *CachedStorage) enqueue(kind OpKind, id uint64, secret *models.Secret) {
+	store.data.NextSeq++
+
+	op := OutboxOp{
+		Seq:      store.data.NextSeq,
+		Kind:     kind,
+		SecretID: id,
+		Secret:   secret,
+		State:    OpPending,
+		QueuedAt: time.Now(),
+	}
+	if kind == OpCreate {
+		op.Key = newCreateKey()
+	}
+
+	store.data.Outbox = append(store.data.Outbox, op)
+}
+
+// Give keys to creates queued by versions without them, reports whether any were added
+func (store *CachedStorage) keyCreates() bool {
+	added := false
+	for i := range store.data.Outbox {
+		if op := &store.data.Outbox[i]; op.Kind == OpCreate && op.Key == "" {
+			op.Key = newCreateKey()
+			added = added || op.Key != ""
+		}
+	}
+
+	return added
+}
+
+// Random create key. Create without key is still delivered, just not protected from duplicates on retry.
+func newCreateKey() string {
+	key, err := utils.GenerateRandom(createKeyLen)
+	if err != nil {
+		log.Printf("newCreateKey(): %v", err)
+		return ""
+	}
+
+	return hex.EncodeToString(key)
+}
+
+func (store *CachedStorage) pending() int {
+	n := 0
+	for _, op := range store.data.Outbox {
+		if op.State == OpPending {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Read replica from disk, new replica is empty
+func (store *CachedStorage) load() error {
+	encryptedData, err := os.ReadFile(store.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("load(): failed to read replica: %w", err)
+	}
+
+	data, err := store.encrypter.Decrypt(encryptedData, store.password)
+
+	// Replica written before account was upgraded to derived keys, e.g. on another device
+	rekey := false
+	if password := store.remote.client.GetPassword(); errors.Is(err, entities.ErrBadPassword) && password != store.password {
+		data, err = store.encrypter.Decrypt(encryptedData, password)
+		rekey = err == nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, &store.data); err != nil {
+		return fmt.Errorf("load(): failed to decode replica: %w", err)
+	}
+
+	if store.data.Secrets == nil {
+		store.data.Secrets = make(map[uint64]models.Secret)
+	}
+
+	if keyed := store.keyCreates(); rekey || keyed {
+		return store.save()
+	}
+
+	return nil
+}
+
+// Write replica atomically
+func (store *CachedStorage) save() error {
+	data, err := json.Marshal(store.data)
+	if err != nil {
+		return fmt.Errorf("save(): error serializing replica: %w", err)
+	}
+
+	encryptedData, err := store.encrypter.Encrypt(data, store.password)
+	if err != nil {
+		return fmt.Errorf("save(): error encrypting replica: %w", err)
+	}
+
+	file, err := replaceFile(store.path, encryptedData)
+	if err != nil {
+		return fmt.Errorf("save(): %w", err)
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,1019 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errRejected = errors.New("rejected")
+
+// In-memory server which can go down
+type fakeServer struct {
+	sync.Mutex
+
+	secrets map[uint64]models.Secret
+	lastID  uint64
+	down    bool
+	reject  bool
+	token   string
+
+	seq     uint64            // last change sequence
+	changed map[uint64]uint64 // secret ID -> sequence of its last change
+	deleted map[uint64]uint64 // tombstones: secret ID -> sequence of deletion
+	page    int               // max changes per sync response, unlimited when 0
+	noSync  bool              // behave as server without delta sync
+	synced  int               // changes sent by SyncSecrets
+	history map[uint64][]models.Secret
+	trash   map[uint64]models.Secret
+	kdf     models.AccountKDF // empty for legacy account
+	key     string            // encryption key of upgraded account
+
+	sessions []models.Session
+	revoked  bool // session of client was ended, client forgot password
+
+	twoFactor models.TwoFactorStatus // login asks for one-time code when enabled
+
+	createKeys   map[string]uint64 // create key -> ID of secret made with it
+	loseResponse bool              // next save is done, but client sees server unavailable
+	saving       chan struct{}     // when set, save signals it started and waits for release
+	release      chan struct{}
+}
+
+func newFakeServer() *fakeServer {
+	return &fakeServer{
+		secrets: make(map[uint64]models.Secret),
+		changed: make(map[uint64]uint64),
+		deleted: make(map[uint64]uint64),
+		history: make(map[uint64][]models.Secret),
+		trash:   make(map[uint64]models.Secret),
+		token:   "token",
+
+		createKeys: make(map[string]uint64),
+	}
+}
+
+func (f *fakeServer) touch(id uint64) {
+	f.seq++
+	f.changed[id] = f.seq
+}
+
+func (f *fakeServer) setDown(down bool) {
+	f.Lock()
+	defer f.Unlock()
+	f.down = down
+}
+
+func (f *fakeServer) check() error {
+	if f.down {
+		return entities.ErrServerUnavailable
+	}
+	if f.token == "" {
+		return entities.ErrUnauthenticated
+	}
+	return nil
+}
+
+func (f *fakeServer) Register(_ context.Context, _ string, _ string) (string, error) {
+	return "", entities.ErrNotSupported
+}
+
+func (f *fakeServer) Login(_ context.Context, login string, password string) (string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.down {
+		return "", entities.ErrServerUnavailable
+	}
+	if f.twoFactor.Enabled {
+		return "", entities.ErrTOTPRequired
+	}
+	f.token = login + ":" + password
+
+	return f.token, nil
+}
+
+func (f *fakeServer) LoadSecrets(_ context.Context) ([]*models.Secret, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*models.Secret, 0, len(f.secrets))
+	for _, s := range f.secrets {
+		secrets = append(secrets, &s)
+	}
+
+	return secrets, nil
+}
+
+func (f *fakeServer) LoadSecret(_ context.Context, id uint64) (*models.Secret, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	s, ok := f.secrets[id]
+	if !ok {
+		return nil, entities.ErrSecretNotFound
+	}
+
+	return &s, nil
+}
+
+func (f *fakeServer) SaveSecret(_ context.Context, secret *models.Secret) error {
+	if f.saving != nil {
+		f.saving <- struct{}{}
+		<-f.release
+	}
+
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+	if f.reject {
+		return errRejected
+	}
+
+	if id, ok := f.createKeys[secret.CreateKey]; ok && secret.ID == 0 {
+		secret.ID, secret.Revision = id, f.secrets[id].Revision
+		return nil
+	}
+
+	s := *secret
+	if s.ID == 0 {
+		f.lastID++
+		s.ID = f.lastID
+		if s.CreateKey != "" {
+			f.createKeys[s.CreateKey] = s.ID
+		}
+	} else if current := f.secrets[s.ID]; current.Revision != s.Revision {
+		return &entities.ConflictError{Theirs: &current}
+	} else {
+		f.history[s.ID] = append(f.history[s.ID], current)
+	}
+
+	s.Revision++
+	f.secrets[s.ID] = s
+	f.touch(s.ID)
+
+	if f.loseResponse {
+		f.loseResponse = false
+		return entities.ErrServerUnavailable
+	}
+
+	secret.ID, secret.Revision = s.ID, s.Revision
+
+	return nil
+}
+
+// Change secret as another device would
+func (f *fakeServer) edit(id uint64, title string) {
+	f.Lock()
+	defer f.Unlock()
+
+	s := f.secrets[id]
+	s.Title = title
+	s.Revision++
+	f.secrets[id] = s
+	f.touch(id)
+}
+
+func (f *fakeServer) DeleteSecret(_ context.Context, id uint64) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+	if s, ok := f.secrets[id]; ok {
+		deletedAt := time.Now()
+		s.DeletedAt = &deletedAt
+		f.trash[id] = s
+		delete(f.secrets, id)
+		delete(f.changed, id)
+		f.seq++
+		f.deleted[id] = f.seq
+	}
+
+	return nil
+}
+
+func (f *fakeServer) SyncSecrets(_ context.Context, cursor string) (*models.SecretChanges, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	if f.noSync {
+		return nil, entities.ErrNotSupported
+	}
+
+	since, err := strconv.ParseUint(cursor, 10, 64)
+	reset := err != nil
+
+	type event struct {
+		id, seq uint64
+		deleted bool
+	}
+
+	var events []event
+	for id, seq := range f.changed {
+		if seq > since {
+			events = append(events, event{id: id, seq: seq})
+		}
+	}
+	for id, seq := range f.deleted {
+		if seq > since && !reset {
+			events = append(events, event{id: id, seq: seq, deleted: true})
+		}
+	}
+	slices.SortFunc(events, func(a, b event) int { return int(a.seq) - int(b.seq) })
+
+	changes := &models.SecretChanges{Reset: reset, Cursor: strconv.FormatUint(since, 10)}
+	if f.page > 0 && len(events) > f.page {
+		events, changes.HasMore = events[:f.page], true
+	}
+
+	for _, e := range events {
+		if e.deleted {
+			changes.Deleted = append(changes.Deleted, e.id)
+		} else {
+			s := f.secrets[e.id]
+			changes.Updated = append(changes.Updated, &s)
+		}
+		changes.Cursor = strconv.FormatUint(e.seq, 10)
+	}
+	f.synced += len(events)
+
+	return changes, nil
+}
+
+func (f *fakeServer) LoadRevisions(_ context.Context, id uint64) ([]*models.Secret, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	var revisions []*models.Secret
+	for i := len(f.history[id]) - 1; i >= 0; i-- {
+		s := f.history[id][i]
+		s.Payload = nil
+		revisions = append(revisions, &s)
+	}
+
+	return revisions, nil
+}
+
+func (f *fakeServer) LoadRevision(_ context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range f.history[id] {
+		if s.Revision == revision {
+			return &s, nil
+		}
+	}
+
+	return nil, entities.ErrRevisionNotFound
+}
+
+func (f *fakeServer) LoadTrash(_ context.Context) ([]*models.Secret, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*models.Secret, 0, len(f.trash))
+	for _, s := range f.trash {
+		secrets = append(secrets, &s)
+	}
+
+	return secrets, nil
+}
+
+func (f *fakeServer) RestoreSecret(_ context.Context, id uint64) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	s, ok := f.trash[id]
+	if !ok {
+		return entities.ErrNotInTrash
+	}
+
+	s.DeletedAt = nil
+	f.secrets[id] = s
+	delete(f.trash, id)
+	delete(f.deleted, id)
+	f.touch(id)
+
+	return nil
+}
+
+func (f *fakeServer) PurgeSecret(_ context.Context, id uint64) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+	if _, ok := f.trash[id]; !ok {
+		return entities.ErrNotInTrash
+	}
+
+	delete(f.trash, id)
+	delete(f.history, id)
+
+	return nil
+}
+
+func (f *fakeServer) SetToken(token string) {
+	f.Lock()
+	defer f.Unlock()
+	f.token = token
+}
+
+func (f *fakeServer) GetToken() string {
+	f.Lock()
+	defer f.Unlock()
+	return f.token
+}
+
+func (f *fakeServer) GetLogin() string             { return "user" }
+func (f *fakeServer) SetPassword(_ string)         {}
+func (f *fakeServer) SetEncryptionKey(_ string)    {}
+func (f *fakeServer) Notifications(_ *tea.Program) {}
+
+func (f *fakeServer) GetPassword() string {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.revoked {
+		return ""
+	}
+	return "password"
+}
+
+func (f *fakeServer) LoginTOTP(_ context.Context, _ string) (string, error) {
+	return "", entities.ErrLoginExpired
+}
+
+func (f *fakeServer) TwoFactor(_ context.Context) (models.TwoFactorStatus, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	return f.twoFactor, nil
+}
+
+func (f *fakeServer) EnrollTOTP(_ context.Context) (models.TOTPEnrollment, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.twoFactor.Enabled {
+		return models.TOTPEnrollment{}, entities.ErrTwoFactorEnabled
+	}
+	return models.TOTPEnrollment{Secret: "SECRET", URI: "otpauth://totp/GophKeeper:user?secret=SECRET"}, nil
+}
+
+func (f *fakeServer) ConfirmTOTP(_ context.Context, code string) ([]string, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if code != "123456" {
+		return nil, entities.ErrBadTOTPCode
+	}
+	f.twoFactor = models.TwoFactorStatus{Enabled: true, RecoveryCodes: 1}
+
+	return []string{"abcde-fghij"}, nil
+}
+
+func (f *fakeServer) DisableTOTP(_ context.Context, _ string) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if !f.twoFactor.Enabled {
+		return entities.ErrTwoFactorDisabled
+	}
+	f.twoFactor = models.TwoFactorStatus{}
+
+	return nil
+}
+
+func (f *fakeServer) ListSessions(_ context.Context) ([]models.Session, error) {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return nil, err
+	}
+	return slices.Clone(f.sessions), nil
+}
+
+func (f *fakeServer) RevokeSession(_ context.Context, id uint64) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(f.sessions, func(s models.Session) bool { return s.ID == id })
+	if i < 0 {
+		return entities.ErrSessionNotFound
+	}
+	f.sessions = slices.Delete(f.sessions, i, i+1)
+
+	return nil
+}
+
+func (f *fakeServer) Logout(_ context.Context) error {
+	f.Lock()
+	defer f.Unlock()
+
+	f.token, f.revoked = "", true
+
+	return nil
+}
+
+// Session ended from another device: access token is refused and client forgets credentials
+func (f *fakeServer) revoke() {
+	f.Lock()
+	defer f.Unlock()
+
+	f.token, f.revoked = "", true
+}
+
+func (f *fakeServer) GetEncryptionKey() string {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.key == "" {
+		return "password"
+	}
+	return f.key
+}
+
+func (f *fakeServer) GetKDF() models.AccountKDF {
+	f.Lock()
+	defer f.Unlock()
+	return f.kdf
+}
+
+func (f *fakeServer) UpgradeAuth(_ context.Context, kdf models.AccountKDF, keys crypto.AccountKeys, secrets []models.SecretPayload) error {
+	f.Lock()
+	defer f.Unlock()
+
+	if err := f.check(); err != nil {
+		return err
+	}
+	if len(secrets) != len(f.secrets)+len(f.trash) {
+		return entities.ErrConflict
+	}
+
+	for _, p := range secrets {
+		if s, ok := f.secrets[p.ID]; ok {
+			s.Payload = p.Payload
+			s.Revision++
+			f.secrets[p.ID] = s
+		}
+	}
+	f.history = make(map[uint64][]models.Secret)
+	f.kdf, f.key = kdf, keys.Encryption
+
+	return nil
+}
+
+func newTestCached(t *testing.T, server *fakeServer, path string) *CachedStorage {
+	remote, err := NewRemoteStorage(server, &MockEncrypter{})
+	require.NoError(t, err)
+
+	store, err := NewCachedStorage(remote, "user", path, crypto.NewVaultEncrypter(testKDFParams), 0)
+	require.NoError(t, err)
+
+	return store
+}
+
+func credential(title string) *models.Secret {
+	return &models.Secret{
+		Title:      title,
+		SecretType: string(models.CredSecret),
+		Creds:      &models.Credentials{Login: "login", Password: "password"},
+	}
+}
+
+func titles(t *testing.T, store Storage) []string {
+	secrets, err := store.GetAll(context.Background())
+	require.NoError(t, err)
+
+	res := make([]string, 0, len(secrets))
+	for _, s := range secrets {
+		res = append(res, s.Title)
+	}
+
+	return res
+}
+
+func TestCachedStorageOnline(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Create(ctx, credential("first")))
+
+	assert.Empty(t, store.Outbox())
+	assert.True(t, store.Online())
+	assert.Len(t, server.secrets, 1)
+	assert.Equal(t, []string{"first"}, titles(t, store))
+
+	// Replica holds server ID after refresh
+	secret, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "login", secret.Creds.Login)
+}
+
+func TestCachedStorageOffline(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.db")
+	server := newFakeServer()
+
+	store := newTestCached(t, server, path)
+	require.NoError(t, store.Create(ctx, credential("kept")))
+	require.NoError(t, store.Create(ctx, credential("removed")))
+
+	server.setDown(true)
+
+	t.Run("Reads served from replica", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"kept", "removed"}, titles(t, store))
+		assert.False(t, store.Online())
+	})
+
+	t.Run("Changes are queued", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, credential("offline")))
+
+		var offlineID uint64
+		for id, s := range store.data.Secrets {
+			if s.Title == "offline" {
+				offlineID = id
+			}
+		}
+		require.GreaterOrEqual(t, offlineID, localIDBase)
+
+		// Edit of queued create is folded into it
+		edited := credential("offline edited")
+		edited.ID = offlineID
+		require.NoError(t, store.Update(ctx, edited))
+
+		require.NoError(t, store.Delete(ctx, 2))
+
+		outbox := store.Outbox()
+		require.Len(t, outbox, 2)
+		assert.Equal(t, OpCreate, outbox[0].Kind)
+		assert.Equal(t, "offline edited", outbox[0].Secret.Title)
+		assert.Equal(t, OpDelete, outbox[1].Kind)
+		assert.Len(t, server.secrets, 2)
+	})
+
+	t.Run("Outbox survives restart", func(t *testing.T) {
+		require.NoError(t, store.Close(ctx))
+
+		store = newTestCached(t, server, path)
+		assert.Len(t, store.Outbox(), 2)
+		assert.ElementsMatch(t, []string{"kept", "offline edited"}, titles(t, store))
+	})
+
+	t.Run("Replayed when server is back", func(t *testing.T) {
+		server.setDown(false)
+		server.SetToken("") // token expired meanwhile
+
+		require.NoError(t, store.Sync(ctx))
+
+		assert.Empty(t, store.Outbox())
+		assert.True(t, store.Online())
+		assert.ElementsMatch(t, []string{"kept", "offline edited"}, titles(t, store))
+		assert.Len(t, server.secrets, 2)
+		assert.Equal(t, "user:password", server.GetToken())
+	})
+
+	require.NoError(t, store.Close(ctx))
+}
+
+func TestCachedStorageFailed(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	server.reject = true
+	require.NoError(t, store.Create(ctx, credential("rejected")))
+
+	outbox := store.Outbox()
+	require.Len(t, outbox, 1)
+	assert.Equal(t, OpFailed, outbox[0].State)
+	assert.Equal(t, errRejected.Error(), outbox[0].Error)
+
+	t.Run("Retry", func(t *testing.T) {
+		server.reject = false
+		require.NoError(t, store.RetryFailed(ctx))
+		assert.Empty(t, store.Outbox())
+		assert.Len(t, server.secrets, 1)
+	})
+
+	t.Run("Discard", func(t *testing.T) {
+		server.reject = true
+		require.NoError(t, store.Create(ctx, credential("dropped")))
+		require.Len(t, store.Outbox(), 1)
+
+		require.NoError(t, store.DiscardFailed(ctx))
+		assert.Empty(t, store.Outbox())
+		assert.Equal(t, []string{"rejected"}, titles(t, store))
+	})
+}
+
+func TestCachedStorageRetriedCreate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.db")
+	server := newFakeServer()
+	store := newTestCached(t, server, path)
+
+	// Server made the secret, but response was lost, as if client crashed before saving server ID
+	server.loseResponse = true
+	require.NoError(t, store.Create(ctx, credential("once")))
+	require.Len(t, store.Outbox(), 1)
+	require.NotEmpty(t, store.Outbox()[0].Key)
+	require.NoError(t, store.Close(ctx))
+
+	store = newTestCached(t, server, path)
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Sync(ctx))
+
+	assert.Empty(t, store.Outbox())
+	assert.Len(t, server.secrets, 1)
+	assert.Equal(t, []string{"once"}, titles(t, store))
+}
+
+func TestCachedStorageUnlockedDuringSync(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	server.saving, server.release = make(chan struct{}), make(chan struct{})
+
+	created := make(chan error)
+	go func() { created <- store.Create(ctx, credential("draft")) }()
+
+	// Create is on its way to server
+	<-server.saving
+
+	// Replica stays usable meanwhile, edit is folded into the create being sent
+	var draftID uint64
+	for _, op := range store.Outbox() {
+		draftID = op.SecretID
+	}
+	require.GreaterOrEqual(t, draftID, localIDBase)
+
+	edited := credential("final")
+	edited.ID = draftID
+
+	updated := make(chan error)
+	go func() { updated <- store.Update(ctx, edited) }()
+
+	// Update waits for running sync only after edit is queued
+	require.Eventually(t, func() bool {
+		outbox := store.Outbox()
+		return len(outbox) == 1 && outbox[0].Secret.Title == "final"
+	}, time.Second, time.Millisecond)
+
+	// Delivered create is followed by update carrying the edit
+	server.release <- struct{}{}
+	<-server.saving
+	server.release <- struct{}{}
+	require.NoError(t, <-created)
+	require.NoError(t, <-updated)
+
+	assert.Empty(t, store.Outbox())
+	assert.Len(t, server.secrets, 1)
+	assert.Equal(t, []string{"final"}, titles(t, store))
+}
+
+func TestCachedStorageConflict(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Create(ctx, credential("original")))
+
+	// Edit secret here while it was changed on another device
+	conflict := func(t *testing.T) uint64 {
+		secrets, err := store.GetAll(ctx)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+
+		mine := secrets[0]
+
+		server.edit(mine.ID, "theirs")
+
+		mine.Title = "mine"
+		err = store.Update(ctx, mine)
+
+		var conflictErr *entities.ConflictError
+		require.ErrorAs(t, err, &conflictErr)
+		assert.Equal(t, "theirs", conflictErr.Theirs.Title)
+
+		return mine.ID
+	}
+
+	t.Run("Take theirs", func(t *testing.T) {
+		id := conflict(t)
+		require.NoError(t, store.Resolve(ctx, id, ResolveTakeTheirs))
+
+		assert.Empty(t, store.Outbox())
+		assert.Equal(t, []string{"theirs"}, titles(t, store))
+	})
+
+	t.Run("Keep mine", func(t *testing.T) {
+		id := conflict(t)
+		require.NoError(t, store.Resolve(ctx, id, ResolveKeepMine))
+
+		assert.Empty(t, store.Outbox())
+		assert.Equal(t, []string{"mine"}, titles(t, store))
+		assert.Equal(t, "mine", server.secrets[id].Title)
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		id := conflict(t)
+		require.NoError(t, store.Resolve(ctx, id, ResolveDuplicate))
+
+		assert.Empty(t, store.Outbox())
+		assert.ElementsMatch(t, []string{"theirs", "mine (conflict copy)"}, titles(t, store))
+	})
+}
+
+func TestCachedStorageDeltaSync(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.db")
+	server := newFakeServer()
+	server.page = 2
+
+	store := newTestCached(t, server, path)
+	for _, title := range []string{"first", "second", "third"} {
+		require.NoError(t, store.Create(ctx, credential(title)))
+	}
+
+	t.Run("Only changes are fetched", func(t *testing.T) {
+		server.edit(1, "first edited")
+		require.NoError(t, server.DeleteSecret(ctx, 2))
+		server.synced = 0
+
+		require.NoError(t, store.Sync(ctx))
+
+		assert.Equal(t, 2, server.synced)
+		assert.ElementsMatch(t, []string{"first edited", "third"}, titles(t, store))
+	})
+
+	t.Run("Cursor survives restart", func(t *testing.T) {
+		require.NoError(t, store.Close(ctx))
+		store = newTestCached(t, server, path)
+		server.synced = 0
+
+		require.NoError(t, store.Sync(ctx))
+
+		assert.Zero(t, server.synced)
+		assert.ElementsMatch(t, []string{"first edited", "third"}, titles(t, store))
+	})
+
+	t.Run("Server without delta sync", func(t *testing.T) {
+		server.noSync = true
+		server.edit(3, "third edited")
+
+		require.NoError(t, store.Sync(ctx))
+
+		assert.ElementsMatch(t, []string{"first edited", "third edited"}, titles(t, store))
+	})
+
+	require.NoError(t, store.Close(ctx))
+}
+
+func TestCachedStorageHistory(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Create(ctx, credential("first")))
+
+	secret, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	secret.Title = "renamed"
+	secret.Creds.Password = "changed"
+	require.NoError(t, store.Update(ctx, secret))
+
+	history, err := store.History(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "first", history[0].Title)
+
+	old, err := store.Revision(ctx, 1, history[0].Revision)
+	require.NoError(t, err)
+	assert.Equal(t, "password", old.Creds.Password)
+
+	server.setDown(true)
+	_, err = store.History(ctx, 1)
+	assert.ErrorIs(t, err, entities.ErrServerUnavailable)
+}
+
+func TestCachedStorageTrash(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Create(ctx, credential("first")))
+	require.NoError(t, store.Delete(ctx, 1))
+
+	trashed, err := store.Trashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "password", trashed[0].Creds.Password)
+
+	// Restored secret is back in replica
+	require.NoError(t, store.Restore(ctx, 1))
+	restored, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "first", restored.Title)
+
+	require.NoError(t, store.Delete(ctx, 1))
+	require.NoError(t, store.Purge(ctx, 1))
+	assert.ErrorIs(t, store.Restore(ctx, 1), entities.ErrNotInTrash)
+}
+
+func TestCachedStorageUpgradeAccount(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.db")
+	server := newFakeServer()
+
+	store := newTestCached(t, server, path)
+	require.NoError(t, store.Create(ctx, credential("first")))
+	require.NoError(t, store.Create(ctx, credential("second")))
+	require.NoError(t, store.Delete(ctx, 2))
+
+	kdf := models.AccountKDF{Salt: make([]byte, models.MinKDFSaltLen), Time: 1, Memory: models.MinKDFMemory, Threads: 1}
+	keys := crypto.AccountKeys{Auth: make([]byte, 32), Encryption: "encryption key"}
+
+	t.Run("Rejected while offline", func(t *testing.T) {
+		server.setDown(true)
+		defer server.setDown(false)
+
+		assert.ErrorIs(t, store.UpgradeAccount(ctx, kdf, keys), entities.ErrServerUnavailable)
+		assert.True(t, server.GetKDF().Legacy())
+	})
+
+	t.Run("Secrets and trash handed over", func(t *testing.T) {
+		require.NoError(t, store.UpgradeAccount(ctx, kdf, keys))
+		assert.Equal(t, kdf, server.GetKDF())
+		assert.Equal(t, "encryption key", store.password)
+		assert.Equal(t, []string{"first"}, titles(t, store))
+		require.NoError(t, store.Close(ctx))
+	})
+
+	t.Run("Replica opened with new key", func(t *testing.T) {
+		reopened := newTestCached(t, server, path)
+		defer reopened.Close(ctx)
+
+		server.setDown(true)
+		defer server.setDown(false)
+		assert.Equal(t, []string{"first"}, titles(t, reopened))
+	})
+}
+
+func TestCachedStorageRekeyReplica(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "replica.db")
+	server := newFakeServer()
+
+	store := newTestCached(t, server, path)
+	require.NoError(t, store.Create(ctx, credential("first")))
+	require.NoError(t, store.Close(ctx))
+
+	// Account upgraded on another device
+	server.key = "encryption key"
+	server.setDown(true)
+
+	reopened := newTestCached(t, server, path)
+	defer reopened.Close(ctx)
+	assert.Equal(t, []string{"first"}, titles(t, reopened))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, err = crypto.NewVaultEncrypter(testKDFParams).Decrypt(data, "encryption key")
+	assert.NoError(t, err)
+}
+
+func TestCachedStorageSessions(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	server.sessions = []models.Session{{ID: 1, Device: "laptop", Current: true}, {ID: 2, Device: "phone"}}
+
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	sessions, err := store.Sessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	require.NoError(t, store.RevokeSession(ctx, 2))
+	assert.ErrorIs(t, store.RevokeSession(ctx, 2), entities.ErrSessionNotFound)
+
+	t.Run("Ended elsewhere", func(t *testing.T) {
+		server.revoke()
+
+		// Change is kept queued, not logged in again with forgotten password
+		require.NoError(t, store.Create(ctx, credential("queued")))
+		assert.ErrorIs(t, store.Sync(ctx), entities.ErrSessionRevoked)
+		require.Len(t, store.Outbox(), 1)
+		assert.Equal(t, OpPending, store.Outbox()[0].State)
+		assert.Empty(t, server.GetToken())
+	})
+}
+
+func TestCachedStorageTwoFactor(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+	defer store.Close(ctx)
+
+	_, err := store.EnrollTwoFactor(ctx)
+	require.NoError(t, err)
+
+	_, err = store.ConfirmTwoFactor(ctx, "000000")
+	assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+
+	recoveryCodes, err := store.ConfirmTwoFactor(ctx, "123456")
+	require.NoError(t, err)
+	assert.Len(t, recoveryCodes, 1)
+
+	st, err := store.TwoFactor(ctx)
+	require.NoError(t, err)
+	assert.True(t, st.Enabled)
+
+	t.Run("Token expired", func(t *testing.T) {
+		server.SetToken("")
+
+		// Code can not be asked for in background, change stays queued
+		require.NoError(t, store.Create(ctx, credential("queued")))
+		assert.ErrorIs(t, store.Sync(ctx), entities.ErrSessionRevoked)
+		require.Len(t, store.Outbox(), 1)
+		assert.Equal(t, OpPending, store.Outbox()[0].State)
+	})
+}
+
+func TestCachedStorageLogout(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeServer()
+	store := newTestCached(t, server, filepath.Join(t.TempDir(), "replica.db"))
+
+	require.NoError(t, store.Create(ctx, credential("first")))
+	require.NoError(t, store.Logout(ctx))
+
+	assert.Empty(t, server.GetToken())
+	assert.Equal(t, []string{"first"}, titles(t, store))
+}
@@ -1,7 +1,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
 	"gophkeeper/internal/keeper/crypto"
@@ -10,48 +13,102 @@ import (
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"slices"
 	"sync"
+	"time"
 )
 
-var _ Storage = (*FileStorage)(nil)
+var (
+	_ Storage         = (*FileStorage)(nil)
+	_ PasswordChanger = (*FileStorage)(nil)
+	_ BatchCreator    = (*FileStorage)(nil)
+	_ HistoryKeeper   = (*FileStorage)(nil)
+	_ TrashKeeper     = (*FileStorage)(nil)
+)
+
+// Vault, its backups, damaged copies and lock file are readable by owner only
+const vaultFileMode = 0600
+
+// Decrypted vault contents. Vaults written before history was added hold just the secrets map.
+type vaultContents struct {
+	Secrets map[uint64]models.Secret `json:"secrets"`
+	History secretHistory            `json:"history,omitempty"`
+	Trash   map[uint64]models.Secret `json:"trash,omitempty"`
+}
 
 // File-backed storage
 type FileStorage struct {
 	sync.RWMutex
 
-	file *os.File
-	Data map[uint64]models.Secret `json:"secrets"`
+	path    string
+	file    *os.File
+	Data    map[uint64]models.Secret `json:"secrets"`
+	history secretHistory            // previous versions of updated secrets
+	trash   map[uint64]models.Secret // deleted secrets, until restored or purged
 
 	encrypter crypto.Encrypter
 	password  string
+
+	opts      localOptions
+	lock      *vaultLock
+	watcher   *fileWatcher
+	loaded    fileState // file state at last load or save, to detect external changes
+	needsDump bool      // in-memory data differs from file (legacy format or failed save)
 }
 
-func NewFileStorage(path string, password string, encrypter crypto.Encrypter) (*FileStorage, error) {
-	store := &FileStorage{
-		Data:      make(map[uint64]models.Secret),
-		encrypter: encrypter,
-		password:  password,
-	}
+// Identity of file contents
+type fileState struct {
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+func NewFileStorage(path string, password string, encrypter crypto.Encrypter, opts ...LocalOption) (*FileStorage, error) {
+	store := newFileStorage(password, encrypter, opts...)
 
 	err := store.openOrCreateFile(path)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := store.watch(); err != nil {
+		log.Printf("NewFileStorage(): live reload disabled: %v", err)
+	}
+
+	if !store.opts.readOnly {
+		registerOpened(path, store)
+	}
+
 	return store, nil
 }
 
+func newFileStorage(password string, encrypter crypto.Encrypter, opts ...LocalOption) *FileStorage {
+	store := &FileStorage{
+		Data:      make(map[uint64]models.Secret),
+		history:   make(secretHistory),
+		trash:     make(map[uint64]models.Secret),
+		encrypter: encrypter,
+		password:  password,
+		opts:      newLocalOptions(opts...),
+	}
+
+	return store
+}
+
 func (store *FileStorage) Get(_ context.Context, id uint64) (*models.Secret, error) {
 	store.Lock()
 	defer store.Unlock()
 
 	secret, ok := store.Data[id]
-	secret.ID = id
 	if !ok {
 		return nil, entities.ErrSecretNotFound
 	}
 
+	// Own copy, so edits in place do not leak into stored and previous versions
+	secret = secret.Clone()
+	secret.ID = id
+
 	return &secret, nil
 }
 
@@ -63,6 +120,7 @@ func (store *FileStorage) GetAll(_ context.Context) ([]*models.Secret, error) {
 
 	i := 0
 	for id, secret := range store.Data {
+		secret = secret.Clone()
 		secret.ID = id
 		arr[i] = &secret
 		i++
@@ -75,8 +133,28 @@ func (store *FileStorage) Create(ctx context.Context, secret *models.Secret) err
 	store.Lock()
 	defer store.Unlock()
 
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
 	id := store.nextID()
-	store.Data[id] = *secret
+	store.Data[id] = secret.Clone()
+
+	return store.dump()
+}
+
+// Add secrets with a single vault write
+func (store *FileStorage) CreateBatch(ctx context.Context, secrets []*models.Secret) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	for _, secret := range secrets {
+		store.Data[store.nextID()] = secret.Clone()
+	}
 
 	return store.dump()
 }
@@ -85,8 +163,15 @@ func (store *FileStorage) Update(ctx context.Context, secret *models.Secret) err
 	store.Lock()
 	defer store.Unlock()
 
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
 	id := secret.ID
-	store.Data[id] = *secret
+	if old, ok := store.Data[id]; ok {
+		store.history.push(id, old, store.opts.history)
+	}
+	store.Data[id] = secret.Clone()
 
 	return store.dump()
 }
@@ -95,46 +180,204 @@ func (store *FileStorage) Delete(ctx context.Context, id uint64) error {
 	store.Lock()
 	defer store.Unlock()
 
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	secret, ok := store.Data[id]
+	if !ok {
+		return nil
+	}
+
+	// History is kept, so restored secret still has its previous versions
+	deletedAt := time.Now()
+	secret.DeletedAt = &deletedAt
+	store.trash[id] = secret
 	delete(store.Data, id)
 
 	return store.dump()
 }
 
+// Deleted secrets, newest deletion first
+func (store *FileStorage) Trashed(_ context.Context) ([]*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return listTrash(store.trash), nil
+}
+
+func (store *FileStorage) Restore(_ context.Context, id uint64) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	secret, ok := store.trash[id]
+	if !ok {
+		return entities.ErrNotInTrash
+	}
+
+	secret.DeletedAt = nil
+	store.Data[id] = secret
+	delete(store.trash, id)
+
+	return store.dump()
+}
+
+func (store *FileStorage) Purge(_ context.Context, id uint64) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	if _, ok := store.trash[id]; !ok {
+		return entities.ErrNotInTrash
+	}
+
+	delete(store.trash, id)
+	delete(store.history, id)
+
+	return store.dump()
+}
+
+// Previous versions of secret, newest first
+func (store *FileStorage) History(_ context.Context, id uint64) ([]*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return store.history.list(id), nil
+}
+
+// Previous version of secret
+func (store *FileStorage) Revision(_ context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return store.history.get(id, revision)
+}
+
+// Re-encrypt storage with new password.
+// New vault is written to a temp file and renamed over the old one,
+// so on any failure the old file stays untouched.
+// Backup generations are re-encrypted with new password too, failure to do so does not fail the change.
+func (store *FileStorage) ChangePassword(_ context.Context, oldPassword string, newPassword string) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	if subtle.ConstantTimeCompare([]byte(oldPassword), []byte(store.password)) != 1 {
+		return entities.ErrBadPassword
+	}
+
+	if len(newPassword) == 0 {
+		return entities.ErrEmptyPassword
+	}
+
+	// Serialize data
+	data, err := json.Marshal(vaultContents{Secrets: store.Data, History: store.history, Trash: store.trash})
+	if err != nil {
+		return fmt.Errorf("ChangePassword(): error serializing Data: %w", err)
+	}
+
+	// Encrypt data with new password
+	encryptedData, err := store.encrypter.Encrypt(data, newPassword)
+	if err != nil {
+		return fmt.Errorf("ChangePassword(): error encrypting Data: %w", err)
+	}
+
+	if err := store.write(encryptedData); err != nil {
+		return fmt.Errorf("ChangePassword(): %w", err)
+	}
+
+	store.password = newPassword
+
+	// Password is already changed, backups left with old one are only reported
+	if err := reencryptBackups(store.path, store.encrypter, oldPassword, newPassword); err != nil {
+		log.Printf("ChangePassword(): backups keep old password: %v", err)
+	}
+
+	return nil
+}
+
 func (store *FileStorage) String() string {
-	return store.file.Name()
+	return store.path
+}
+
+// Whether storage was opened without lock and rejects changes
+func (store *FileStorage) ReadOnly() bool {
+	return store.opts.readOnly
 }
 
 func (store *FileStorage) Close(_ context.Context) error {
+	if werr := store.watcher.stop(); werr != nil {
+		log.Printf("Close(): failed to stop watcher: %v", werr)
+	}
+	store.watcher = nil
+
 	defer func() {
 		if serr := store.file.Close(); serr != nil {
 			log.Fatal("dump(): failed to close file: %w")
 		}
+
+		if lerr := store.lock.unlock(); lerr != nil {
+			log.Printf("Close(): failed to release lock: %v", lerr)
+		}
+		store.lock = nil
+
+		unregisterOpened(store.path, store)
 	}()
 
-	err := store.dump()
-	if err != nil {
-		return err
+	if !store.needsDump || store.opts.readOnly {
+		return nil
 	}
 
-	return err
+	return store.dump()
 }
 
-func (store *FileStorage) openOrCreateFile(path string) error {
+func (store *FileStorage) openOrCreateFile(path string) (err error) {
 	store.Lock()
 	defer store.Unlock()
 
-	var (
-		existedFile bool
-		err         error
-	)
+	var existedFile bool
 
 	// Check if file already existed
 	if _, err = os.Stat(path); err == nil {
 		existedFile = true
 	}
 
+	store.path = path
+
+	if store.opts.readOnly {
+		if !existedFile {
+			return entities.ErrBadFileStorePath
+		}
+	} else {
+		// Lock
+		if store.lock, err = lockVault(path); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err != nil {
+				_ = store.lock.unlock()
+			}
+		}()
+	}
+
 	// Open file
-	store.file, err = os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	flag := os.O_CREATE | os.O_RDWR
+	if store.opts.readOnly {
+		flag = os.O_RDONLY
+	}
+
+	store.file, err = os.OpenFile(path, flag, vaultFileMode)
 	if err != nil {
 		return fmt.Errorf("openOrCreateFile(): failed to open file: %w", err)
 	}
@@ -150,20 +393,12 @@ func (store *FileStorage) openOrCreateFile(path string) error {
 			return fmt.Errorf("openOrCreateFile -> ReadAll(): failed to read file: %w", err)
 		}
 
-		// Decrypt
-		decryptedData, err := store.DecryptWithRecover(encryptedData, store.password)
-		if err != nil {
-			switch err {
-			case entities.ErrBadPassword, entities.ErrBadEncryption:
-				return err
-			default:
-				return fmt.Errorf("openOrCreateFile -> Decrypt(): failed to decrypt data: %w", err)
-			}
+		if err := store.load(encryptedData); err != nil {
+			return fmt.Errorf("openOrCreateFile -> %w", err)
 		}
 
-		// Unmarshal
-		if err := json.Unmarshal(decryptedData, &store.Data); err != nil {
-			return fmt.Errorf("openOrCreateFile -> Unmarshal(): failed to decode Data: %w", err)
+		if store.loaded, err = statFile(store.path, encryptedData); err != nil {
+			return fmt.Errorf("openOrCreateFile -> %w", err)
 		}
 
 		// Reset pointer
@@ -175,6 +410,123 @@ func (store *FileStorage) openOrCreateFile(path string) error {
 	return nil
 }
 
+// Reload vault on changes made by other processes
+func (store *FileStorage) watch() (err error) {
+	store.watcher, err = watchFile(store.path, func() {
+		reloaded, err := store.reload()
+		if err != nil {
+			log.Printf("reload(): %v", err)
+			return
+		}
+
+		if reloaded {
+			notifyReload(store.path)
+		}
+	})
+
+	return err
+}
+
+// Re-read vault file if it differs from the loaded one.
+// Own saves are skipped as file state matches the one recorded by write.
+func (store *FileStorage) reload() (bool, error) {
+	store.Lock()
+	defer store.Unlock()
+
+	switch err := store.checkUnchanged(); err {
+	case nil:
+		return false, nil
+	case entities.ErrVaultChanged:
+	default:
+		return false, err
+	}
+
+	// Unsaved data would be lost, next save reports the conflict instead
+	if store.needsDump {
+		return false, fmt.Errorf("%s changed on disk, keeping unsaved data", store.path)
+	}
+
+	encryptedData, err := os.ReadFile(store.path)
+	if err != nil {
+		return false, err
+	}
+
+	loaded, err := statFile(store.path, encryptedData)
+	if err != nil {
+		return false, err
+	}
+
+	// Decode into fresh map so failed reload keeps current data
+	fresh := newFileStorage(store.password, store.encrypter)
+	if err := fresh.load(encryptedData); err != nil {
+		return false, err
+	}
+
+	store.Data = fresh.Data
+	store.history = fresh.history
+	store.trash = fresh.trash
+	store.loaded = loaded
+	store.needsDump = fresh.needsDump
+
+	return true, nil
+}
+
+// Decrypt and decode vault contents
+func (store *FileStorage) load(encryptedData []byte) error {
+	// Decrypt
+	decryptedData, err := store.DecryptWithRecover(encryptedData, store.password)
+	if err != nil {
+		switch err {
+		case entities.ErrBadPassword, entities.ErrBadEncryption, entities.ErrBadVaultFormat:
+			return err
+		default:
+			return fmt.Errorf("Decrypt(): failed to decrypt data: %w", err)
+		}
+	}
+
+	// Unmarshal
+	legacy, err := store.decode(decryptedData)
+	if err != nil {
+		return fmt.Errorf("Unmarshal(): %w: %v", entities.ErrVaultCorrupted, err)
+	}
+
+	// Legacy vault, rewrite in current format
+	store.needsDump = legacy || !crypto.IsVault(encryptedData)
+
+	return nil
+}
+
+// Decode vault contents, reports whether they are in format without history
+func (store *FileStorage) decode(data []byte) (bool, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false, err
+	}
+
+	// Secrets map is keyed by numeric IDs, so it never has the secrets field
+	if _, ok := fields["secrets"]; !ok {
+		return true, json.Unmarshal(data, &store.Data)
+	}
+
+	contents := vaultContents{Secrets: store.Data, History: store.history, Trash: store.trash}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return false, err
+	}
+
+	store.Data, store.history, store.trash = contents.Secrets, contents.History, contents.Trash
+	if store.Data == nil {
+		store.Data = make(map[uint64]models.Secret)
+	}
+	if store.history == nil {
+		store.history = make(secretHistory)
+	}
+	if store.trash == nil {
+		store.trash = make(map[uint64]models.Secret)
+	}
+
+	return false, nil
+}
+
 // Attempt to decode non-encoded file may cause panic
 func (store *FileStorage) DecryptWithRecover(data []byte, password string) (res []byte, err error) {
 	defer func() { // defer can replace named return values
@@ -189,16 +541,11 @@ func (store *FileStorage) DecryptWithRecover(data []byte, password string) (res
 // Dump storage to file
 func (store *FileStorage) dump() (err error) {
 	// Serialize data
-	data, err := json.Marshal(store.Data)
+	data, err := json.Marshal(vaultContents{Secrets: store.Data, History: store.history, Trash: store.trash})
 	if err != nil {
 		return fmt.Errorf("dump(): error serializing Data: %w", err)
 	}
 
-	// Clear any existing data
-	if err = store.file.Truncate(0); err != nil {
-		return fmt.Errorf("dump(): failed to truncate file: %w", err)
-	}
-
 	// Encrypt data
 	encryptedData, err := store.encrypter.Encrypt(data, store.password)
 	if err != nil {
@@ -206,27 +553,164 @@ func (store *FileStorage) dump() (err error) {
 	}
 
 	// Dump to file
-	if _, err = store.file.Write(encryptedData); err != nil {
-		return fmt.Errorf("dump(): error writing encrypted Data to file: %w", err)
+	if err = store.write(encryptedData); err != nil {
+		store.needsDump = true
+		return fmt.Errorf("dump(): %w", err)
 	}
 
-	// Reset pointer
-	if _, err = store.file.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("dump(): failed to reset file pointer: %w", err)
+	store.needsDump = false
+
+	return nil
+}
+
+// Replace vault file with encrypted data, rotating backups first.
+// Refuses to overwrite file changed by someone else since it was loaded.
+func (store *FileStorage) write(encryptedData []byte) error {
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
 	}
 
+	if err := store.checkUnchanged(); err != nil {
+		return err
+	}
+
+	if err := rotateBackups(store.path, store.opts.backups); err != nil {
+		return err
+	}
+
+	file, err := replaceFile(store.path, encryptedData)
+	if err != nil {
+		return err
+	}
+
+	// Old handle points to replaced file now
+	if err := store.file.Close(); err != nil {
+		log.Printf("write(): failed to close old file: %v", err)
+	}
+
+	store.file = file
+
+	loaded, err := statFile(store.path, encryptedData)
+	if err != nil {
+		return err
+	}
+	store.loaded = loaded
+
 	return nil
 }
 
+// Compare vault file with the state it had on last load or save.
+// Modification time and size are checked first, content hash only if they differ.
+func (store *FileStorage) checkUnchanged() error {
+	// Nothing loaded yet, new vault
+	if store.loaded.modTime.IsZero() {
+		return nil
+	}
+
+	info, err := os.Stat(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entities.ErrVaultChanged
+		}
+		return fmt.Errorf("checkUnchanged(): %w", err)
+	}
+
+	if info.ModTime().Equal(store.loaded.modTime) && info.Size() == store.loaded.size {
+		return nil
+	}
+
+	data, err := os.ReadFile(store.path)
+	if err != nil {
+		return fmt.Errorf("checkUnchanged(): %w", err)
+	}
+
+	hash := sha256.Sum256(data)
+	if !bytes.Equal(hash[:], store.loaded.hash[:]) {
+		return entities.ErrVaultChanged
+	}
+
+	// Touched, but same content
+	store.loaded.modTime = info.ModTime()
+
+	return nil
+}
+
+func statFile(path string, data []byte) (fileState, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}, fmt.Errorf("statFile(): %w", err)
+	}
+
+	return fileState{
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    sha256.Sum256(data),
+	}, nil
+}
+
+// Trashed secrets keep their IDs, so they are not reused
 func (store *FileStorage) nextID() uint64 {
-	if len(store.Data) == 0 {
+	if len(store.Data) == 0 && len(store.trash) == 0 {
 		return 1
 	}
 
-	ids := make([]uint64, len(store.Data))
+	ids := make([]uint64, 0, len(store.Data)+len(store.trash))
 	for k := range store.Data {
 		ids = append(ids, k)
 	}
+	for k := range store.trash {
+		ids = append(ids, k)
+	}
 
 	return slices.Max(ids) + 1
 }
+
+// Atomically replace file at path with data.
+// Data is written to a temp file in the same dir, synced and renamed over path.
+// Returns handle of the new file, positioned at start.
+func replaceFile(path string, data []byte) (_ *os.File, err error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to create temp file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if err = tmp.Chmod(vaultFileMode); err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to chmod temp file: %w", err)
+	}
+
+	if _, err = tmp.Write(data); err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to write temp file: %w", err)
+	}
+
+	if err = tmp.Sync(); err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to sync temp file: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to rename temp file: %w", err)
+	}
+
+	// Persist rename itself, best effort
+	if d, derr := os.Open(dir); derr == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+
+	if _, err = tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("replaceFile(): failed to reset file pointer: %w", err)
+	}
+
+	return tmp, nil
+}
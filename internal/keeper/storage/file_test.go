@@ -4,15 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"gophkeeper/internal/keeper/crypto"
 	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/pkg/models"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// Cheap key derivation for tests
+var testKDFParams = crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1}
+
 type MockEncrypter struct{}
 
 func (m *MockEncrypter) Encrypt(data []byte, password string) ([]byte, error) {
@@ -24,7 +30,7 @@ func (m *MockEncrypter) Decrypt(data []byte, password string) ([]byte, error) {
 }
 
 func TestFileStorage(t *testing.T) {
-	tempFile, err := os.CreateTemp("", "filestorage_test.json")
+	tempFile, err := os.CreateTemp(t.TempDir(), "filestorage_test.json")
 	assert.NoError(t, err)
 	defer os.Remove(tempFile.Name())
 
@@ -87,6 +93,13 @@ func TestFileStorage(t *testing.T) {
 
 		_, err = store.Get(context.Background(), 1)
 		assert.ErrorIs(t, err, entities.ErrSecretNotFound)
+
+		// Deleted secret waits in trash until purged
+		trashed, err := store.Trashed(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, trashed, 1)
+
+		assert.NoError(t, store.Purge(context.Background(), 1))
 	})
 
 	t.Run("Get All Secrets", func(t *testing.T) {
@@ -105,9 +118,21 @@ func TestFileStorage(t *testing.T) {
 		assert.Len(t, allSecrets, len(secrets))
 	})
 
+	t.Run("Create Batch", func(t *testing.T) {
+		err := store.CreateBatch(context.Background(), []*models.Secret{
+			{Title: "Batch 1"},
+			{Title: "Batch 2"},
+		})
+		assert.NoError(t, err)
+
+		allSecrets, err := store.GetAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, allSecrets, 4)
+	})
+
 	t.Run("Dump and Load", func(t *testing.T) {
 		secret := &models.Secret{
-			ID:         3,
+			ID:         5,
 			Title:      "Test Dump",
 			Metadata:   "dump metadata",
 			SecretType: "credential",
@@ -129,3 +154,190 @@ func TestFileStorage(t *testing.T) {
 		assert.Equal(t, secret.Title, loadedSecret.Title)
 	})
 }
+
+func TestFileStorageUpgradesLegacyVault(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	password := "testpassword"
+
+	// Legacy vault, written by pbkdf2-based encrypter
+	data, err := json.Marshal(map[uint64]models.Secret{1: {Title: "legacy"}})
+	require.NoError(t, err)
+	encrypted, err := crypto.NewKeeperEncrypter().Encrypt(data, password)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, encrypted, 0644))
+
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, password, encrypter)
+	require.NoError(t, err)
+
+	secret, err := store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", secret.Title)
+
+	require.NoError(t, store.Close(context.Background()))
+
+	// Saved in new format
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, crypto.IsVault(raw))
+
+	store, err = NewFileStorage(path, password, encrypter)
+	require.NoError(t, err)
+
+	secret, err = store.Get(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "legacy", secret.Title)
+}
+
+func TestFileStorageChangePassword(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vault.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "old", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(context.Background(), &models.Secret{Title: "secret"}))
+
+	t.Run("Bad old password", func(t *testing.T) {
+		before, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		err = store.ChangePassword(context.Background(), "wrong", "new")
+		assert.ErrorIs(t, err, entities.ErrBadPassword)
+
+		after, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, before, after)
+	})
+
+	t.Run("Empty new password", func(t *testing.T) {
+		err := store.ChangePassword(context.Background(), "old", "")
+		assert.ErrorIs(t, err, entities.ErrEmptyPassword)
+	})
+
+	t.Run("Change", func(t *testing.T) {
+		require.NoError(t, store.ChangePassword(context.Background(), "old", "new"))
+
+		// Storage keeps working with new file
+		require.NoError(t, store.Create(context.Background(), &models.Secret{Title: "after change"}))
+		require.NoError(t, store.Close(context.Background()))
+
+		_, err := NewFileStorage(path, "old", encrypter)
+		assert.ErrorIs(t, err, entities.ErrBadPassword)
+
+		reopened, err := NewFileStorage(path, "new", encrypter)
+		require.NoError(t, err)
+
+		secrets, err := reopened.GetAll(context.Background())
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+
+		// Old password opens no backup generation either
+		backups, err := ListBackups(path)
+		require.NoError(t, err)
+		require.NotEmpty(t, backups)
+		for _, backup := range backups {
+			data, err := os.ReadFile(backup)
+			require.NoError(t, err)
+
+			assert.ErrorIs(t, newFileStorage("old", encrypter).load(data), entities.ErrBadPassword, backup)
+			assert.NoError(t, newFileStorage("new", encrypter).load(data), backup)
+		}
+
+		// No temp files left
+		tmps, err := filepath.Glob(path + ".tmp*")
+		require.NoError(t, err)
+		assert.Empty(t, tmps)
+	})
+}
+
+func TestFileStorageHistory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "password", encrypter, WithHistory(2))
+	require.NoError(t, err)
+
+	secret := credential("v1")
+	require.NoError(t, store.Create(ctx, secret))
+	secret.ID = 1
+
+	for _, title := range []string{"v2", "v3", "v4"} {
+		secret.Title = title
+		require.NoError(t, store.Update(ctx, secret))
+	}
+	require.NoError(t, store.Close(ctx))
+
+	// History survives reopening, only the last versions are kept
+	store, err = NewFileStorage(path, "password", encrypter, WithHistory(2))
+	require.NoError(t, err)
+
+	history, err := store.History(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "v3", history[0].Title)
+	assert.Equal(t, uint64(3), history[0].Revision)
+	assert.Equal(t, "v2", history[1].Title)
+
+	old, err := store.Revision(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "password", old.Creds.Password)
+
+	_, err = store.Revision(ctx, 1, 1)
+	assert.ErrorIs(t, err, entities.ErrRevisionNotFound)
+
+	// Trashed secret keeps history, purged one drops it
+	require.NoError(t, store.Delete(ctx, 1))
+	history, err = store.History(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+
+	require.NoError(t, store.Purge(ctx, 1))
+	history, err = store.History(ctx, 1)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestFileStorageTrash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "trash.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Create(ctx, credential("first")))
+	require.NoError(t, store.Create(ctx, credential("second")))
+	require.NoError(t, store.Delete(ctx, 1))
+	require.NoError(t, store.Delete(ctx, 2))
+	require.NoError(t, store.Close(ctx))
+
+	// Trash survives reopening, IDs of trashed secrets are not reused
+	store, err = NewFileStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	defer store.Close(ctx)
+
+	trashed, err := store.Trashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 2)
+	assert.NotNil(t, trashed[0].DeletedAt)
+
+	require.NoError(t, store.Create(ctx, credential("third")))
+	_, err = store.Get(ctx, 3)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Restore(ctx, 1))
+	restored, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "first", restored.Title)
+	assert.Nil(t, restored.DeletedAt)
+
+	require.NoError(t, store.Purge(ctx, 2))
+	assert.ErrorIs(t, store.Purge(ctx, 2), entities.ErrNotInTrash)
+	assert.ErrorIs(t, store.Restore(ctx, 3), entities.ErrNotInTrash)
+
+	trashed, err = store.Trashed(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, trashed)
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+	"slices"
+)
+
+// Default number of previous versions kept per secret in local storages
+const DefaultHistory = 20
+
+// Previous versions of secrets by secret ID, oldest first.
+// Versions are numbered per secret in Revision, numbers are not reused when old versions are dropped.
+type secretHistory map[uint64][]models.Secret
+
+// Remember replaced version of secret, dropping the oldest ones over limit
+func (h secretHistory) push(id uint64, old models.Secret, limit int) {
+	if limit <= 0 {
+		delete(h, id)
+		return
+	}
+
+	versions := h[id]
+
+	last := uint64(0)
+	if n := len(versions); n > 0 {
+		last = versions[n-1].Revision
+	}
+
+	// Versions written back by compaction keep their numbers
+	if old.Revision <= last {
+		old.Revision = last + 1
+	}
+
+	old.ID = id
+	old.Payload = nil
+	versions = append(versions, old)
+
+	if len(versions) > limit {
+		versions = slices.Clone(versions[len(versions)-limit:])
+	}
+
+	h[id] = versions
+}
+
+// Previous versions of secret, newest first
+func (h secretHistory) list(id uint64) []*models.Secret {
+	versions := h[id]
+
+	list := make([]*models.Secret, 0, len(versions))
+	for i := len(versions) - 1; i >= 0; i-- {
+		version := versions[i].Clone()
+		list = append(list, &version)
+	}
+
+	return list
+}
+
+// Previous version of secret by its number
+func (h secretHistory) get(id uint64, revision uint64) (*models.Secret, error) {
+	for _, version := range h[id] {
+		if version.Revision == revision {
+			version = version.Clone()
+			return &version, nil
+		}
+	}
+
+	return nil, entities.ErrRevisionNotFound
+}
+
+// Total number of kept versions
+func (h secretHistory) size() int {
+	n := 0
+	for _, versions := range h {
+		n += len(versions)
+	}
+
+	return n
+}
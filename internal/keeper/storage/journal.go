@@ -0,0 +1,762 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Journal file layout (all integers are big-endian):
+//
+//	header:
+//	  4   magic "GPHJ"
+//	  1   format version
+//	  4   key block length K
+//	  K   key block: random 32-byte data key, encrypted with password (see crypto.VaultEncrypter)
+//	records, repeated until EOF:
+//	  4   record length L
+//	  L   nonce + AES-256-GCM ciphertext of JSON record, sealed with data key.
+//	      Record sequence number (uint64, starting from 0) is used as additional data,
+//	      so records can not be reordered or dropped from the middle of the journal.
+//
+// JSON record is one of:
+//
+//	{"op":"put","id":N,"secret":{...}}   create or update secret
+//	{"op":"trash","id":N,"at":"..."}     move secret to trash
+//	{"op":"restore","id":N}              move secret back from trash
+//	{"op":"del","id":N}                  delete secret for good, live or trashed
+//
+// Every change appends one record, so saving does not depend on vault size.
+// A put over existing secret moves its previous version to history.
+// When dead records outnumber live secrets and kept versions, journal is compacted: rewritten
+// atomically with put records of kept versions followed by a put record of current version,
+// and a trash record for trashed secrets. A torn record at the tail (crash during append)
+// is dropped on open, any other damage is reported as corruption and the file is left as is.
+// Journals with trash records can not be opened by versions without trash.
+
+const (
+	journalVersion = 1
+
+	// Compact when journal holds at least that many records...
+	journalCompactMin = 256
+	// ...and more than that many records per live secret
+	journalCompactRatio = 2
+
+	journalRecordHeader = 4
+	// Larger length prefix can't be written by append, so it is damage rather than a torn record
+	journalMaxRecord = 256 << 20
+)
+
+var journalMagic = []byte("GPHJ")
+
+var (
+	_ Storage         = (*JournalStorage)(nil)
+	_ PasswordChanger = (*JournalStorage)(nil)
+	_ HistoryKeeper   = (*JournalStorage)(nil)
+	_ TrashKeeper     = (*JournalStorage)(nil)
+)
+
+type journalOp string
+
+const (
+	journalPut journalOp = "put"
+	journalDel journalOp = "del"
+
+	journalTrash   journalOp = "trash"
+	journalRestore journalOp = "restore"
+)
+
+type journalRecord struct {
+	Op     journalOp      `json:"op"`
+	ID     uint64         `json:"id"`
+	Secret *models.Secret `json:"secret,omitempty"`
+	At     *time.Time     `json:"at,omitempty"` // time of trash op
+}
+
+// Append-only journal storage
+type JournalStorage struct {
+	sync.RWMutex
+
+	path string
+	file *os.File
+	Data map[uint64]models.Secret
+
+	history secretHistory            // previous versions of updated secrets
+	trash   map[uint64]models.Secret // deleted secrets, until restored or purged
+
+	encrypter crypto.Encrypter // seals data key with password
+	password  string
+	keyBlock  []byte
+	cipher    *crypto.RecordCipher
+
+	records uint64 // number of records in file, next sequence number
+	lastID  uint64 // biggest ID ever used, IDs of deleted secrets are not reused
+
+	opts localOptions
+	lock *vaultLock
+}
+
+// Whether file at path is a journal
+func IsJournal(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, len(journalMagic))
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return false, nil // too short for any header
+	}
+
+	return bytes.Equal(magic, journalMagic), nil
+}
+
+// Open or create journal at path. Backups option is ignored, journal is never rewritten in place.
+func NewJournalStorage(path string, password string, encrypter crypto.Encrypter, opts ...LocalOption) (*JournalStorage, error) {
+	store := &JournalStorage{
+		path:      path,
+		Data:      make(map[uint64]models.Secret),
+		history:   make(secretHistory),
+		trash:     make(map[uint64]models.Secret),
+		encrypter: encrypter,
+		password:  password,
+		opts:      newLocalOptions(opts...),
+	}
+
+	_, serr := os.Stat(path)
+	exists := serr == nil
+
+	if store.opts.readOnly && !exists {
+		return nil, entities.ErrBadFileStorePath
+	}
+
+	var err error
+	if !store.opts.readOnly {
+		if store.lock, err = lockVault(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if exists {
+		err = store.open()
+	} else {
+		err = store.create()
+	}
+
+	if err != nil {
+		_ = store.lock.unlock()
+		return nil, err
+	}
+
+	if !store.opts.readOnly {
+		registerOpened(path, store)
+	}
+
+	return store, nil
+}
+
+func (store *JournalStorage) Get(_ context.Context, id uint64) (*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	secret, ok := store.Data[id]
+	if !ok {
+		return nil, entities.ErrSecretNotFound
+	}
+
+	// Own copy, so edits in place do not leak into stored and previous versions
+	secret = secret.Clone()
+	secret.ID = id
+
+	return &secret, nil
+}
+
+func (store *JournalStorage) GetAll(_ context.Context) ([]*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	arr := make([]*models.Secret, 0, len(store.Data))
+	for id, secret := range store.Data {
+		secret = secret.Clone()
+		secret.ID = id
+		arr = append(arr, &secret)
+	}
+
+	return arr, nil
+}
+
+func (store *JournalStorage) Create(_ context.Context, secret *models.Secret) error {
+	store.Lock()
+	defer store.Unlock()
+
+	id := store.lastID + 1
+
+	saved := secret.Clone()
+	saved.ID = id
+
+	if err := store.append(journalRecord{Op: journalPut, ID: id, Secret: &saved}); err != nil {
+		return err
+	}
+
+	store.Data[id] = saved
+	store.lastID = id
+
+	return store.maybeCompact()
+}
+
+func (store *JournalStorage) Update(_ context.Context, secret *models.Secret) error {
+	store.Lock()
+	defer store.Unlock()
+
+	saved := secret.Clone()
+
+	if err := store.append(journalRecord{Op: journalPut, ID: saved.ID, Secret: &saved}); err != nil {
+		return err
+	}
+
+	store.put(saved.ID, saved)
+	store.lastID = max(store.lastID, saved.ID)
+
+	return store.maybeCompact()
+}
+
+func (store *JournalStorage) Delete(_ context.Context, id uint64) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if _, ok := store.Data[id]; !ok {
+		return nil
+	}
+
+	deletedAt := time.Now()
+	if err := store.append(journalRecord{Op: journalTrash, ID: id, At: &deletedAt}); err != nil {
+		return err
+	}
+
+	store.moveToTrash(id, deletedAt)
+
+	return store.maybeCompact()
+}
+
+// Deleted secrets, newest deletion first
+func (store *JournalStorage) Trashed(_ context.Context) ([]*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return listTrash(store.trash), nil
+}
+
+func (store *JournalStorage) Restore(_ context.Context, id uint64) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if _, ok := store.trash[id]; !ok {
+		return entities.ErrNotInTrash
+	}
+
+	if err := store.append(journalRecord{Op: journalRestore, ID: id}); err != nil {
+		return err
+	}
+
+	store.restoreFromTrash(id)
+
+	return store.maybeCompact()
+}
+
+func (store *JournalStorage) Purge(_ context.Context, id uint64) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if _, ok := store.trash[id]; !ok {
+		return entities.ErrNotInTrash
+	}
+
+	if err := store.append(journalRecord{Op: journalDel, ID: id}); err != nil {
+		return err
+	}
+
+	store.drop(id)
+
+	return store.maybeCompact()
+}
+
+// Previous versions of secret, newest first
+func (store *JournalStorage) History(_ context.Context, id uint64) ([]*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return store.history.list(id), nil
+}
+
+// Previous version of secret
+func (store *JournalStorage) Revision(_ context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	store.RLock()
+	defer store.RUnlock()
+
+	return store.history.get(id, revision)
+}
+
+// Re-encrypt journal with fresh data key sealed with new password, so key block kept from before
+// does not open records written after. Journal is compacted into a new file
+// and renamed over the old one, so on any failure the old file stays untouched.
+func (store *JournalStorage) ChangePassword(_ context.Context, oldPassword string, newPassword string) error {
+	store.Lock()
+	defer store.Unlock()
+
+	if subtle.ConstantTimeCompare([]byte(oldPassword), []byte(store.password)) != 1 {
+		return entities.ErrBadPassword
+	}
+
+	if len(newPassword) == 0 {
+		return entities.ErrEmptyPassword
+	}
+
+	dataKey, err := crypto.NewDataKey()
+	if err != nil {
+		return fmt.Errorf("ChangePassword(): failed to generate data key: %w", err)
+	}
+
+	keyBlock, err := store.encrypter.Encrypt(dataKey, newPassword)
+	if err != nil {
+		return fmt.Errorf("ChangePassword(): failed to encrypt data key: %w", err)
+	}
+
+	cipher, err := crypto.NewRecordCipher(dataKey)
+	if err != nil {
+		return fmt.Errorf("ChangePassword(): %w", err)
+	}
+
+	if err := store.rewrite(keyBlock, cipher); err != nil {
+		return fmt.Errorf("ChangePassword(): %w", err)
+	}
+
+	store.password = newPassword
+
+	return nil
+}
+
+// Rewrite journal with a single record per secret
+func (store *JournalStorage) Compact(_ context.Context) error {
+	store.Lock()
+	defer store.Unlock()
+
+	return store.rewrite(store.keyBlock, store.cipher)
+}
+
+func (store *JournalStorage) String() string {
+	return store.path
+}
+
+// Whether storage was opened without lock and rejects changes
+func (store *JournalStorage) ReadOnly() bool {
+	return store.opts.readOnly
+}
+
+func (store *JournalStorage) Close(_ context.Context) error {
+	defer func() {
+		if err := store.lock.unlock(); err != nil {
+			log.Printf("Close(): failed to release lock: %v", err)
+		}
+		store.lock = nil
+
+		unregisterOpened(store.path, store)
+	}()
+
+	return store.file.Close()
+}
+
+// Initialize new journal with fresh data key
+func (store *JournalStorage) create() error {
+	dataKey, err := crypto.NewDataKey()
+	if err != nil {
+		return fmt.Errorf("create(): failed to generate data key: %w", err)
+	}
+
+	keyBlock, err := store.encrypter.Encrypt(dataKey, store.password)
+	if err != nil {
+		return fmt.Errorf("create(): failed to encrypt data key: %w", err)
+	}
+
+	cipher, err := crypto.NewRecordCipher(dataKey)
+	if err != nil {
+		return fmt.Errorf("create(): %w", err)
+	}
+
+	return store.rewrite(keyBlock, cipher)
+}
+
+// Read journal and replay records
+func (store *JournalStorage) open() (err error) {
+	flag := os.O_RDWR
+	if store.opts.readOnly {
+		flag = os.O_RDONLY
+	}
+
+	store.file, err = os.OpenFile(store.path, flag, vaultFileMode)
+	if err != nil {
+		return fmt.Errorf("open(): failed to open file: %w", err)
+	}
+
+	defer func() {
+		if err != nil {
+			_ = store.file.Close()
+		}
+	}()
+
+	data, err := io.ReadAll(store.file)
+	if err != nil {
+		return fmt.Errorf("open(): failed to read file: %w", err)
+	}
+
+	offset, err := store.readHeader(data)
+	if err != nil {
+		return err
+	}
+
+	for offset < len(data) {
+		if len(data)-offset < journalRecordHeader {
+			break // torn tail
+		}
+
+		size := int(binary.BigEndian.Uint32(data[offset:]))
+		if size < store.cipher.Overhead() || size > journalMaxRecord {
+			return fmt.Errorf("open(): record %d: %w: bad record length %d", store.records, entities.ErrVaultCorrupted, size)
+		}
+
+		if len(data)-offset-journalRecordHeader < size {
+			break // torn tail
+		}
+
+		sealed := data[offset+journalRecordHeader : offset+journalRecordHeader+size]
+		if err := store.replay(sealed); err != nil {
+			return fmt.Errorf("open(): record %d: %w: %w", store.records, entities.ErrVaultCorrupted, err)
+		}
+
+		offset += journalRecordHeader + size
+	}
+
+	// Read-only journal is replayed as is, torn tail is ignored
+	if store.opts.readOnly {
+		return nil
+	}
+
+	if offset < len(data) {
+		log.Printf("open(): dropping %d bytes of incomplete record", len(data)-offset)
+
+		if err := store.file.Truncate(int64(offset)); err != nil {
+			return fmt.Errorf("open(): failed to truncate torn record: %w", err)
+		}
+	}
+
+	if _, err := store.file.Seek(int64(offset), io.SeekStart); err != nil {
+		return fmt.Errorf("open(): failed to set file pointer: %w", err)
+	}
+
+	return store.maybeCompact()
+}
+
+// Parse header, unlock data key. Returns offset of the first record
+func (store *JournalStorage) readHeader(data []byte) (int, error) {
+	fixed := len(journalMagic) + 1 + 4
+	if len(data) < fixed || !bytes.HasPrefix(data, journalMagic) {
+		return 0, entities.ErrBadVaultFormat
+	}
+
+	if version := data[len(journalMagic)]; version != journalVersion {
+		return 0, fmt.Errorf("%w: journal version %d", entities.ErrUnsupportedVault, version)
+	}
+
+	size := int(binary.BigEndian.Uint32(data[len(journalMagic)+1:]))
+	if len(data) < fixed+size {
+		return 0, entities.ErrBadVaultFormat
+	}
+
+	store.keyBlock = data[fixed : fixed+size]
+
+	dataKey, err := store.encrypter.Decrypt(store.keyBlock, store.password)
+	if err != nil {
+		return 0, err
+	}
+
+	if store.cipher, err = crypto.NewRecordCipher(dataKey); err != nil {
+		return 0, err
+	}
+
+	return fixed + size, nil
+}
+
+// Apply single sealed record to in-memory state
+func (store *JournalStorage) replay(sealed []byte) error {
+	plaintext, err := store.cipher.Open(sealed, seqAD(store.records))
+	if err != nil {
+		return err
+	}
+
+	var rec journalRecord
+	if err := json.Unmarshal(plaintext, &rec); err != nil {
+		return fmt.Errorf("failed to decode record: %w", err)
+	}
+
+	switch rec.Op {
+	case journalPut:
+		if rec.Secret == nil {
+			return fmt.Errorf("put record without secret")
+		}
+		rec.Secret.ID = rec.ID
+		store.put(rec.ID, *rec.Secret)
+	case journalTrash:
+		if rec.At == nil {
+			return fmt.Errorf("trash record without time")
+		}
+		store.moveToTrash(rec.ID, *rec.At)
+	case journalRestore:
+		store.restoreFromTrash(rec.ID)
+	case journalDel:
+		store.drop(rec.ID)
+	default:
+		return fmt.Errorf("unknown record op %q", rec.Op)
+	}
+
+	store.lastID = max(store.lastID, rec.ID)
+	store.records++
+
+	return nil
+}
+
+// Set current version of secret, moving the replaced one to history
+func (store *JournalStorage) put(id uint64, secret models.Secret) {
+	if old, ok := store.Data[id]; ok {
+		store.history.push(id, old, store.opts.history)
+	}
+
+	store.Data[id] = secret
+}
+
+// History is kept, so restored secret still has its previous versions
+func (store *JournalStorage) moveToTrash(id uint64, at time.Time) {
+	secret, ok := store.Data[id]
+	if !ok {
+		return
+	}
+
+	secret.DeletedAt = &at
+	store.trash[id] = secret
+	delete(store.Data, id)
+}
+
+func (store *JournalStorage) restoreFromTrash(id uint64) {
+	secret, ok := store.trash[id]
+	if !ok {
+		return
+	}
+
+	secret.DeletedAt = nil
+	store.Data[id] = secret
+	delete(store.trash, id)
+}
+
+// Forget secret with its history, wherever it is
+func (store *JournalStorage) drop(id uint64) {
+	delete(store.Data, id)
+	delete(store.trash, id)
+	delete(store.history, id)
+}
+
+// Append record to the end of journal
+func (store *JournalStorage) append(rec journalRecord) error {
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	frame, err := sealRecord(store.cipher, rec, store.records)
+	if err != nil {
+		return err
+	}
+
+	offset, err := store.file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("append(): failed to get file pointer: %w", err)
+	}
+
+	if err := store.checkUnchanged(offset); err != nil {
+		return err
+	}
+
+	if _, err := store.file.Write(frame); err != nil {
+		// Drop partial record, so next appends do not land after garbage
+		if terr := store.file.Truncate(offset); terr == nil {
+			_, _ = store.file.Seek(offset, io.SeekStart)
+		}
+		return fmt.Errorf("append(): failed to write record: %w", err)
+	}
+
+	if err := store.file.Sync(); err != nil {
+		return fmt.Errorf("append(): failed to sync file: %w", err)
+	}
+
+	store.records++
+
+	return nil
+}
+
+// Make sure file at path is still the one being appended to and nobody wrote to it since our last write.
+// Lock keeps other instances out, this catches editors and sync tools which don't take it.
+func (store *JournalStorage) checkUnchanged(offset int64) error {
+	info, err := os.Stat(store.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return entities.ErrVaultChanged
+		}
+		return fmt.Errorf("checkUnchanged(): %w", err)
+	}
+
+	own, err := store.file.Stat()
+	if err != nil {
+		return fmt.Errorf("checkUnchanged(): %w", err)
+	}
+
+	if !os.SameFile(info, own) || own.Size() != offset {
+		return entities.ErrVaultChanged
+	}
+
+	return nil
+}
+
+func (store *JournalStorage) maybeCompact() error {
+	if store.records < journalCompactMin || store.records <= journalCompactRatio*uint64(len(store.Data)+len(store.trash)+store.history.size()) {
+		return nil
+	}
+
+	return store.rewrite(store.keyBlock, store.cipher)
+}
+
+// Atomically replace journal with header and put records of kept versions, live and trashed secrets.
+// Records are sealed with cipher of data key in key block, both are used for appends after.
+func (store *JournalStorage) rewrite(keyBlock []byte, cipher *crypto.RecordCipher) error {
+	if store.opts.readOnly {
+		return entities.ErrReadOnly
+	}
+
+	// Compaction would silently drop changes made on disk, new journal has no file yet
+	if store.file != nil {
+		offset, err := store.file.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("rewrite(): failed to get file pointer: %w", err)
+		}
+
+		if err := store.checkUnchanged(offset); err != nil {
+			return err
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.Write(journalMagic)
+	buf.WriteByte(journalVersion)
+	_ = binary.Write(&buf, binary.BigEndian, uint32(len(keyBlock)))
+	buf.Write(keyBlock)
+
+	var seq uint64
+	write := func(rec journalRecord) error {
+		frame, err := sealRecord(cipher, rec, seq)
+		if err != nil {
+			return err
+		}
+
+		buf.Write(frame)
+		seq++
+
+		return nil
+	}
+
+	// Oldest version first, so replay rebuilds history in order
+	putVersions := func(id uint64, secret models.Secret) error {
+		secret.ID = id
+		secret.DeletedAt = nil
+
+		for _, version := range append(slices.Clone(store.history[id]), secret) {
+			if err := write(journalRecord{Op: journalPut, ID: id, Secret: &version}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for id, secret := range store.Data {
+		if err := putVersions(id, secret); err != nil {
+			return err
+		}
+	}
+
+	for id, secret := range store.trash {
+		if err := putVersions(id, secret); err != nil {
+			return err
+		}
+		if err := write(journalRecord{Op: journalTrash, ID: id, At: secret.DeletedAt}); err != nil {
+			return err
+		}
+	}
+
+	file, err := replaceFile(store.path, buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("rewrite(): %w", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("rewrite(): failed to set file pointer: %w", err)
+	}
+
+	if store.file != nil {
+		if err := store.file.Close(); err != nil {
+			log.Printf("rewrite(): failed to close old file: %v", err)
+		}
+	}
+
+	store.file = file
+	store.keyBlock = keyBlock
+	store.cipher = cipher
+	store.records = seq
+
+	return nil
+}
+
+// Encode and encrypt record, returns length-prefixed frame
+func sealRecord(cipher *crypto.RecordCipher, rec journalRecord, seq uint64) ([]byte, error) {
+	plaintext, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("sealRecord(): failed to encode record: %w", err)
+	}
+
+	sealed, err := cipher.Seal(plaintext, seqAD(seq))
+	if err != nil {
+		return nil, fmt.Errorf("sealRecord(): failed to encrypt record: %w", err)
+	}
+
+	if len(sealed) > journalMaxRecord {
+		return nil, fmt.Errorf("sealRecord(): record of %d bytes is too large", len(sealed))
+	}
+
+	frame := make([]byte, journalRecordHeader, journalRecordHeader+len(sealed))
+	binary.BigEndian.PutUint32(frame, uint32(len(sealed)))
+
+	return append(frame, sealed...), nil
+}
+
+func seqAD(seq uint64) []byte {
+	return binary.BigEndian.AppendUint64(nil, seq)
+}
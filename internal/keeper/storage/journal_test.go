@@ -0,0 +1,374 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalStorage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	password := "password"
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, password, encrypter)
+	require.NoError(t, err)
+
+	t.Run("Create and Get", func(t *testing.T) {
+		secret := models.NewSecret(models.CredSecret)
+		secret.Title = "creds"
+		secret.Creds = &models.Credentials{Login: "login", Password: "password"}
+
+		require.NoError(t, store.Create(ctx, secret))
+
+		stored, err := store.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "creds", stored.Title)
+		assert.Equal(t, "login", stored.Creds.Login)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		stored, err := store.Get(ctx, 1)
+		require.NoError(t, err)
+
+		stored.Title = "updated"
+		require.NoError(t, store.Update(ctx, stored))
+
+		stored, err = store.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", stored.Title)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		require.NoError(t, store.Create(ctx, &models.Secret{Title: "to delete"}))
+		require.NoError(t, store.Delete(ctx, 2))
+
+		_, err := store.Get(ctx, 2)
+		assert.ErrorIs(t, err, entities.ErrSecretNotFound)
+
+		// IDs are not reused
+		require.NoError(t, store.Create(ctx, &models.Secret{Title: "third"}))
+		_, err = store.Get(ctx, 3)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Reopen", func(t *testing.T) {
+		require.NoError(t, store.Close(ctx))
+
+		isJournal, err := IsJournal(path)
+		require.NoError(t, err)
+		assert.True(t, isJournal)
+
+		reopened, err := OpenLocal(path, password, encrypter)
+		require.NoError(t, err)
+		defer reopened.Close(ctx)
+
+		secrets, err := reopened.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 2)
+
+		stored, err := reopened.Get(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, "updated", stored.Title)
+	})
+
+	t.Run("Bad password", func(t *testing.T) {
+		_, err := NewJournalStorage(path, "wrong", encrypter)
+		assert.ErrorIs(t, err, entities.ErrBadPassword)
+	})
+}
+
+func TestJournalStorageTornTail(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "first"}))
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "second"}))
+	require.NoError(t, store.Close(ctx))
+
+	// Simulate crash in the middle of the last append
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-5))
+
+	store, err = NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	secrets, err := store.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets, 1)
+
+	// Journal is writable after recovery
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "after crash"}))
+	require.NoError(t, store.Close(ctx))
+
+	store, err = NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	secrets, err = store.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets, 2)
+}
+
+func TestJournalStorageCompaction(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	secret := &models.Secret{Title: "often updated"}
+	require.NoError(t, store.Create(ctx, secret))
+	secret.ID = 1
+
+	for i := 0; i < journalCompactMin*2; i++ {
+		require.NoError(t, store.Update(ctx, secret))
+	}
+
+	assert.Less(t, store.records, uint64(journalCompactMin))
+
+	require.NoError(t, store.Close(ctx))
+
+	store, err = NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	stored, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "often updated", stored.Title)
+}
+
+func TestJournalStorageHistory(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter, WithHistory(3))
+	require.NoError(t, err)
+
+	secret := &models.Secret{Title: "v1"}
+	require.NoError(t, store.Create(ctx, secret))
+	secret.ID = 1
+
+	for _, title := range []string{"v2", "v3", "v4", "v5"} {
+		secret.Title = title
+		require.NoError(t, store.Update(ctx, secret))
+	}
+
+	// Compaction writes kept versions back with their numbers
+	require.NoError(t, store.Compact(ctx))
+	require.NoError(t, store.Close(ctx))
+
+	store, err = NewJournalStorage(path, "password", encrypter, WithHistory(3))
+	require.NoError(t, err)
+
+	history, err := store.History(ctx, 1)
+	require.NoError(t, err)
+
+	revisions := make([]uint64, 0, len(history))
+	for _, version := range history {
+		revisions = append(revisions, version.Revision)
+	}
+	assert.Equal(t, []uint64{4, 3, 2}, revisions)
+
+	old, err := store.Revision(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "v2", old.Title)
+
+	current, err := store.Get(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "v5", current.Title)
+}
+
+func TestJournalStorageTrash(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	for _, title := range []string{"first", "second", "third"} {
+		require.NoError(t, store.Create(ctx, &models.Secret{Title: title}))
+	}
+	require.NoError(t, store.Delete(ctx, 1))
+	require.NoError(t, store.Delete(ctx, 2))
+	require.NoError(t, store.Delete(ctx, 3))
+	require.NoError(t, store.Restore(ctx, 3))
+	require.NoError(t, store.Purge(ctx, 2))
+	require.NoError(t, store.Close(ctx))
+
+	// Replay of trash records
+	store, err = NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+
+	trashed, err := store.Trashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.Equal(t, "first", trashed[0].Title)
+	deletedAt := trashed[0].DeletedAt
+	require.NotNil(t, deletedAt)
+
+	// Compaction writes trashed secrets back with their deletion time
+	require.NoError(t, store.Compact(ctx))
+	require.NoError(t, store.Close(ctx))
+
+	store, err = NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	defer store.Close(ctx)
+
+	trashed, err = store.Trashed(ctx)
+	require.NoError(t, err)
+	require.Len(t, trashed, 1)
+	assert.True(t, deletedAt.Equal(*trashed[0].DeletedAt))
+
+	all, err := store.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, "third", all[0].Title)
+
+	require.NoError(t, store.Restore(ctx, 1))
+	assert.ErrorIs(t, store.Restore(ctx, 1), entities.ErrNotInTrash)
+}
+
+func TestJournalStorageChangePassword(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "old", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "secret"}))
+	oldKeyBlock := slices.Clone(store.keyBlock)
+
+	assert.ErrorIs(t, store.ChangePassword(ctx, "wrong", "new"), entities.ErrBadPassword)
+	require.NoError(t, store.ChangePassword(ctx, "old", "new"))
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "after change"}))
+	require.NoError(t, store.Close(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	t.Run("Old key block", func(t *testing.T) {
+		// Key block leaked before the change still opens with old password, but not the records
+		fixed := len(journalMagic) + 1 + 4
+		size := int(binary.BigEndian.Uint32(data[len(journalMagic)+1:]))
+
+		stale := slices.Concat(data[:len(journalMagic)+1], binary.BigEndian.AppendUint32(nil, uint32(len(oldKeyBlock))), oldKeyBlock, data[fixed+size:])
+		stalePath := filepath.Join(t.TempDir(), "stale.db")
+		require.NoError(t, os.WriteFile(stalePath, stale, 0600))
+
+		_, err := NewJournalStorage(stalePath, "old", encrypter, WithReadOnly())
+		assert.ErrorIs(t, err, entities.ErrVaultCorrupted)
+	})
+
+	_, err = NewJournalStorage(path, "old", encrypter)
+	assert.ErrorIs(t, err, entities.ErrBadPassword)
+
+	store, err = NewJournalStorage(path, "new", encrypter)
+	require.NoError(t, err)
+
+	secrets, err := store.GetAll(ctx)
+	require.NoError(t, err)
+	assert.Len(t, secrets, 2)
+}
+
+func TestJournalStorageTampered(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "first"}))
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "second"}))
+	require.NoError(t, store.Close(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	data[len(data)-1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	_, err = NewJournalStorage(path, "password", encrypter)
+	assert.ErrorIs(t, err, entities.ErrBadEncryption)
+}
+
+func TestJournalStorageCorrupted(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "first"}))
+	offset, err := store.file.Seek(0, io.SeekCurrent)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "second"}))
+	require.NoError(t, store.Close(ctx))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	// Length of the last record points past the end of file, but it is no torn append
+	binary.BigEndian.PutUint32(data[offset:], math.MaxUint32)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	_, err = NewJournalStorage(path, "password", encrypter)
+	assert.ErrorIs(t, err, entities.ErrVaultCorrupted)
+
+	// Damaged journal is not truncated
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, data, after)
+}
+
+func TestJournalStorageChangedOnDisk(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "first"}))
+
+	t.Run("Appended", func(t *testing.T) {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+		require.NoError(t, err)
+		_, err = f.Write([]byte("foreign"))
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.ErrorIs(t, store.Create(ctx, &models.Secret{Title: "second"}), entities.ErrVaultChanged)
+		assert.ErrorIs(t, store.Compact(ctx), entities.ErrVaultChanged)
+	})
+
+	t.Run("Replaced", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		f, err := replaceFile(path, data)
+		require.NoError(t, err)
+		require.NoError(t, f.Close())
+
+		assert.ErrorIs(t, store.Create(ctx, &models.Secret{Title: "second"}), entities.ErrVaultChanged)
+	})
+
+	require.NoError(t, store.Close(ctx))
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"path/filepath"
+	"sync"
+)
+
+// Engine of local storage file
+type Engine string
+
+const (
+	EngineVault   Engine = "vault"   // whole vault encrypted as one blob, see FileStorage
+	EngineJournal Engine = "journal" // append-only log of encrypted records, see JournalStorage
+)
+
+type LocalOption func(opts *localOptions)
+
+type localOptions struct {
+	backups  int  // number of previous encrypted generations kept next to the vault
+	history  int  // number of previous versions kept per secret
+	readOnly bool // open without lock, reject all changes
+}
+
+func newLocalOptions(opts ...LocalOption) localOptions {
+	o := localOptions{backups: DefaultBackups, history: DefaultHistory}
+	for _, fn := range opts {
+		fn(&o)
+	}
+
+	return o
+}
+
+// Keep n previous generations of vault file, 0 disables backups
+func WithBackups(n int) LocalOption {
+	return func(opts *localOptions) {
+		opts.backups = max(n, 0)
+	}
+}
+
+// Keep n previous versions of each secret, 0 disables history
+func WithHistory(n int) LocalOption {
+	return func(opts *localOptions) {
+		opts.history = max(n, 0)
+	}
+}
+
+// Open without taking the lock, e.g. when vault is used by another process
+func WithReadOnly() LocalOption {
+	return func(opts *localOptions) {
+		opts.readOnly = true
+	}
+}
+
+// Storage which may be opened read-only
+type ReadOnlyStorage interface {
+	ReadOnly() bool
+}
+
+// Local storages opened for writing by this process, by absolute path.
+// TUI does not close storages when navigating away, so previous instance
+// of a vault is closed here before opening it again, releasing its lock.
+var opened = struct {
+	sync.Mutex
+	m map[string]Storage
+}{m: make(map[string]Storage)}
+
+func registerOpened(path string, store Storage) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	opened.Lock()
+	defer opened.Unlock()
+
+	opened.m[abs] = store
+}
+
+func unregisterOpened(path string, store Storage) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+
+	opened.Lock()
+	defer opened.Unlock()
+
+	if opened.m[abs] == store {
+		delete(opened.m, abs)
+	}
+}
+
+// Close storage at path previously opened by this process, if any
+func closeOpened(ctx context.Context, path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+
+	opened.Lock()
+	store, ok := opened.m[abs]
+	opened.Unlock()
+
+	if !ok {
+		return nil
+	}
+
+	return store.Close(ctx)
+}
+
+// Close all local storages opened by this process
+func CloseAll(ctx context.Context) error {
+	opened.Lock()
+	stores := make([]Storage, 0, len(opened.m))
+	for _, store := range opened.m {
+		stores = append(stores, store)
+	}
+	opened.Unlock()
+
+	var errs []error
+	for _, store := range stores {
+		errs = append(errs, store.Close(ctx))
+	}
+
+	return errors.Join(errs...)
+}
+
+// Open existing local storage, detecting its engine by file header
+func OpenLocal(path string, password string, encrypter crypto.Encrypter, opts ...LocalOption) (Storage, error) {
+	if !newLocalOptions(opts...).readOnly {
+		if err := closeOpened(context.Background(), path); err != nil {
+			return nil, err
+		}
+	}
+
+	journal, err := IsJournal(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if journal {
+		return NewJournalStorage(path, password, encrypter, opts...)
+	}
+
+	return NewFileStorage(path, password, encrypter, opts...)
+}
+
+// Create or open local storage of given engine
+func NewLocal(engine Engine, path string, password string, encrypter crypto.Encrypter, opts ...LocalOption) (Storage, error) {
+	if !newLocalOptions(opts...).readOnly {
+		if err := closeOpened(context.Background(), path); err != nil {
+			return nil, err
+		}
+	}
+
+	switch engine {
+	case EngineVault, "":
+		return NewFileStorage(path, password, encrypter, opts...)
+	case EngineJournal:
+		return NewJournalStorage(path, password, encrypter, opts...)
+	default:
+		return nil, fmt.Errorf("unknown storage engine %q", engine)
+	}
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"os"
+)
+
+// Vault file is replaced by rename on every save, so advisory lock is held
+// on a separate <path>.lock file, which is never removed.
+
+const lockSuffix = ".lock"
+
+var errLocked = errors.New("file is locked")
+
+// Exclusive advisory lock of a vault, held while vault is open
+type vaultLock struct {
+	file *os.File
+}
+
+// Acquire lock of vault at path without waiting.
+// Returns entities.ErrVaultInUse if another process holds it.
+func lockVault(path string) (*vaultLock, error) {
+	f, err := os.OpenFile(path+lockSuffix, os.O_CREATE|os.O_RDWR, vaultFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("lockVault(): failed to open lock file: %w", err)
+	}
+
+	if err := tryLock(f); err != nil {
+		_ = f.Close()
+
+		if errors.Is(err, errLocked) {
+			return nil, entities.ErrVaultInUse
+		}
+
+		return nil, fmt.Errorf("lockVault(): %w", err)
+	}
+
+	return &vaultLock{file: f}, nil
+}
+
+// Release lock, nil-safe
+func (l *vaultLock) unlock() error {
+	if l == nil {
+		return nil
+	}
+
+	// Closing descriptor releases the lock
+	return l.file.Close()
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd || windows)
+
+package storage
+
+import "os"
+
+// No advisory locks on this platform
+func tryLock(_ *os.File) error {
+	return nil
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorageLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "secret"}))
+
+	t.Run("Second writer", func(t *testing.T) {
+		_, err := NewFileStorage(path, "password", encrypter)
+		assert.ErrorIs(t, err, entities.ErrVaultInUse)
+	})
+
+	t.Run("Read-only", func(t *testing.T) {
+		ro, err := NewFileStorage(path, "password", encrypter, WithReadOnly())
+		require.NoError(t, err)
+		defer ro.Close(ctx)
+
+		assert.True(t, ro.ReadOnly())
+
+		secrets, err := ro.GetAll(ctx)
+		require.NoError(t, err)
+		assert.Len(t, secrets, 1)
+
+		assert.ErrorIs(t, ro.Create(ctx, &models.Secret{Title: "denied"}), entities.ErrReadOnly)
+	})
+
+	t.Run("Reopen in same process", func(t *testing.T) {
+		// Previous instance is closed, releasing the lock
+		reopened, err := OpenLocal(path, "password", encrypter)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Close(ctx))
+	})
+
+	t.Run("After close", func(t *testing.T) {
+		reopened, err := NewFileStorage(path, "password", encrypter)
+		require.NoError(t, err)
+		require.NoError(t, reopened.Close(ctx))
+	})
+}
+
+func TestJournalStorageLock(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewJournalStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	defer store.Close(ctx)
+
+	_, err = NewJournalStorage(path, "password", encrypter)
+	assert.ErrorIs(t, err, entities.ErrVaultInUse)
+
+	ro, err := NewJournalStorage(path, "password", encrypter, WithReadOnly())
+	require.NoError(t, err)
+	defer ro.Close(ctx)
+
+	assert.ErrorIs(t, ro.Create(ctx, &models.Secret{Title: "denied"}), entities.ErrReadOnly)
+}
+
+func TestFileStorageExternalChange(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	store, err := NewFileStorage(path, "password", encrypter, WithBackups(0))
+	require.NoError(t, err)
+	defer store.Close(ctx)
+
+	require.NoError(t, store.Create(ctx, &models.Secret{Title: "first"}))
+
+	t.Run("Touched with same content", func(t *testing.T) {
+		later := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(path, later, later))
+
+		assert.NoError(t, store.Create(ctx, &models.Secret{Title: "second"}))
+	})
+
+	t.Run("Replaced by someone else", func(t *testing.T) {
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+
+		data = append(data, 0x00)
+		require.NoError(t, os.WriteFile(path, data, 0644))
+
+		assert.ErrorIs(t, store.Create(ctx, &models.Secret{Title: "third"}), entities.ErrVaultChanged)
+
+		// File is left as is
+		onDisk, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, data, onDisk)
+	})
+}
@@ -3,19 +3,33 @@ package storage
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"gophkeeper/internal/keeper/api"
 	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/pkg/models"
+	"slices"
+	"sync"
 )
 
-var _ Storage = (*RemoteStorage)(nil)
+var (
+	_ Storage         = (*RemoteStorage)(nil)
+	_ HistoryKeeper   = (*RemoteStorage)(nil)
+	_ TrashKeeper     = (*RemoteStorage)(nil)
+	_ SessionKeeper   = (*RemoteStorage)(nil)
+	_ TwoFactorKeeper = (*RemoteStorage)(nil)
+)
 
 // Remote storage
 type RemoteStorage struct {
 	client    api.IApiClient
 	encrypter crypto.Encrypter
-	password  string // passw to encrypt payload
+	password  string // key to encrypt payload, see api.IApiClient.GetEncryptionKey
+
+	mu      sync.Mutex
+	cursor  string                    // delta sync position of cache
+	secrets map[uint64]*models.Secret // decrypted copy of server secrets, kept up to date by deltas
 }
 
 func NewRemoteStorage(client api.IApiClient, encrypter crypto.Encrypter) (*RemoteStorage, error) {
@@ -26,7 +40,7 @@ func NewRemoteStorage(client api.IApiClient, encrypter crypto.Encrypter) (*Remot
 	store := &RemoteStorage{
 		client:    client,
 		encrypter: encrypter,
-		password:  client.GetPassword(),
+		password:  client.GetEncryptionKey(),
 	}
 
 	return store, nil
@@ -46,20 +60,105 @@ func (store *RemoteStorage) Get(_ context.Context, id uint64) (*models.Secret, e
 	return secret, nil
 }
 
-func (store *RemoteStorage) GetAll(_ context.Context) ([]*models.Secret, error) {
-	secrets, err := store.client.LoadSecrets(context.Background())
+// Fetch changes since last call and return all secrets, most recently updated first
+func (store *RemoteStorage) GetAll(ctx context.Context) ([]*models.Secret, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	changes, err := store.Changes(ctx, store.cursor)
 	if err != nil {
 		return nil, err
 	}
 
-	for _, s := range secrets {
-		err = store.decryptPayload(s)
+	if changes.Reset || store.secrets == nil {
+		store.secrets = make(map[uint64]*models.Secret, len(changes.Updated))
+	}
+
+	for _, s := range changes.Updated {
+		store.secrets[s.ID] = s
+	}
+
+	for _, id := range changes.Deleted {
+		delete(store.secrets, id)
+	}
+
+	store.cursor = changes.Cursor
+
+	secrets := make([]*models.Secret, 0, len(store.secrets))
+	for _, s := range store.secrets {
+		secret := *s
+		secrets = append(secrets, &secret)
+	}
+
+	slices.SortFunc(secrets, func(a, b *models.Secret) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+
+	return secrets, nil
+}
+
+// Changes since cursor with decrypted payloads, all pages merged, so each secret is either updated or deleted.
+// Servers without delta sync get full listing with Reset set and empty cursor.
+func (store *RemoteStorage) Changes(ctx context.Context, cursor string) (*models.SecretChanges, error) {
+	var (
+		updated = make(map[uint64]*models.Secret)
+		deleted = make(map[uint64]struct{})
+		result  = &models.SecretChanges{Cursor: cursor}
+	)
+
+	for first := true; first || result.HasMore; first = false {
+		page, err := store.client.SyncSecrets(ctx, result.Cursor)
+		if errors.Is(err, entities.ErrNotSupported) && first {
+			page, err = store.listAll(ctx)
+		}
+
 		if err != nil {
 			return nil, err
 		}
+
+		// Listing restarted, everything collected so far is superseded
+		if page.Reset {
+			clear(updated)
+			clear(deleted)
+			result.Reset = true
+		}
+
+		for _, s := range page.Updated {
+			if err := store.decryptPayload(s); err != nil {
+				return nil, err
+			}
+
+			updated[s.ID] = s
+			delete(deleted, s.ID)
+		}
+
+		for _, id := range page.Deleted {
+			deleted[id] = struct{}{}
+			delete(updated, id)
+		}
+
+		result.Cursor, result.HasMore = page.Cursor, page.HasMore
 	}
 
-	return secrets, nil
+	for _, s := range updated {
+		result.Updated = append(result.Updated, s)
+	}
+
+	for id := range deleted {
+		result.Deleted = append(result.Deleted, id)
+	}
+
+	return result, nil
+}
+
+// Full listing in shape of delta for servers without delta sync
+func (store *RemoteStorage) listAll(ctx context.Context) (*models.SecretChanges, error) {
+	secrets, err := store.client.LoadSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SecretChanges{Updated: secrets, Reset: true}, nil
 }
 
 func (store *RemoteStorage) Create(ctx context.Context, secret *models.Secret) (err error) {
@@ -69,7 +168,7 @@ func (store *RemoteStorage) Create(ctx context.Context, secret *models.Secret) (
 	}
 
 	err = store.client.SaveSecret(context.Background(), secret)
-	return err
+	return store.conflict(err)
 }
 
 func (store *RemoteStorage) Update(ctx context.Context, secret *models.Secret) (err error) {
@@ -79,7 +178,7 @@ func (store *RemoteStorage) Update(ctx context.Context, secret *models.Secret) (
 	}
 
 	err = store.client.SaveSecret(context.Background(), secret)
-	return err
+	return store.conflict(err)
 }
 
 func (store *RemoteStorage) Delete(ctx context.Context, id uint64) (err error) {
@@ -87,6 +186,91 @@ func (store *RemoteStorage) Delete(ctx context.Context, id uint64) (err error) {
 	return err
 }
 
+// Previous versions of secret kept by server, newest first, without contents
+func (store *RemoteStorage) History(ctx context.Context, id uint64) ([]*models.Secret, error) {
+	return store.client.LoadRevisions(ctx, id)
+}
+
+// Previous version of secret with decrypted contents
+func (store *RemoteStorage) Revision(ctx context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	secret, err := store.client.LoadRevision(ctx, id, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.decryptPayload(secret); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Secrets in server trash with decrypted contents
+func (store *RemoteStorage) Trashed(ctx context.Context) ([]*models.Secret, error) {
+	secrets, err := store.client.LoadTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, secret := range secrets {
+		if err := store.decryptPayload(secret); err != nil {
+			return nil, err
+		}
+	}
+
+	return secrets, nil
+}
+
+func (store *RemoteStorage) Restore(ctx context.Context, id uint64) error {
+	return store.client.RestoreSecret(ctx, id)
+}
+
+func (store *RemoteStorage) Purge(ctx context.Context, id uint64) error {
+	return store.client.PurgeSecret(ctx, id)
+}
+
+func (store *RemoteStorage) Sessions(ctx context.Context) ([]models.Session, error) {
+	return store.client.ListSessions(ctx)
+}
+
+func (store *RemoteStorage) RevokeSession(ctx context.Context, id uint64) error {
+	return store.client.RevokeSession(ctx, id)
+}
+
+func (store *RemoteStorage) TwoFactor(ctx context.Context) (models.TwoFactorStatus, error) {
+	return store.client.TwoFactor(ctx)
+}
+
+func (store *RemoteStorage) EnrollTwoFactor(ctx context.Context) (models.TOTPEnrollment, error) {
+	return store.client.EnrollTOTP(ctx)
+}
+
+func (store *RemoteStorage) ConfirmTwoFactor(ctx context.Context, code string) ([]string, error) {
+	return store.client.ConfirmTOTP(ctx, code)
+}
+
+func (store *RemoteStorage) DisableTwoFactor(ctx context.Context, code string) error {
+	return store.client.DisableTOTP(ctx, code)
+}
+
+func (store *RemoteStorage) Logout(ctx context.Context) error {
+	return store.client.Logout(ctx)
+}
+
+// Decrypt server copy carried by conflict error
+func (store *RemoteStorage) conflict(err error) error {
+	var conflict *entities.ConflictError
+	if !errors.As(err, &conflict) || conflict.Theirs == nil {
+		return err
+	}
+
+	if derr := store.decryptPayload(conflict.Theirs); derr != nil {
+		return fmt.Errorf("%w: %w", err, derr)
+	}
+
+	return err
+}
+
 func (store *RemoteStorage) encryptPayload(secret *models.Secret) (err error) {
 	// Marshal
 	data, err := marshalSecret(secret)
@@ -137,6 +321,10 @@ func marshalSecret(secret *models.Secret) ([]byte, error) {
 		data, err = json.Marshal(secret.Card)
 	case models.BlobSecret:
 		data, err = json.Marshal(secret.Blob)
+	case models.TOTPSecret:
+		data, err = json.Marshal(secret.TOTP)
+	case models.SSHKeySecret:
+		data, err = json.Marshal(secret.SSH)
 	}
 
 	return data, err
@@ -154,6 +342,10 @@ func unmarshalSecret(secret *models.Secret, data []byte) error {
 		err = json.Unmarshal(data, &secret.Card)
 	case models.BlobSecret:
 		err = json.Unmarshal(data, &secret.Blob)
+	case models.TOTPSecret:
+		err = json.Unmarshal(data, &secret.TOTP)
+	case models.SSHKeySecret:
+		err = json.Unmarshal(data, &secret.SSH)
 	}
 
 	return err
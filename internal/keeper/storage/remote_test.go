@@ -5,6 +5,8 @@ import (
 	"testing"
 	"time"
 
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/pkg/models"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -26,6 +28,47 @@ func (m *MockApiClient) Login(ctx context.Context, login string, password string
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockApiClient) LoginTOTP(ctx context.Context, code string) (string, error) {
+	args := m.Called(ctx, code)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockApiClient) TwoFactor(ctx context.Context) (models.TwoFactorStatus, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.TwoFactorStatus), args.Error(1)
+}
+
+func (m *MockApiClient) EnrollTOTP(ctx context.Context) (models.TOTPEnrollment, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(models.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockApiClient) ConfirmTOTP(ctx context.Context, code string) ([]string, error) {
+	args := m.Called(ctx, code)
+	codes, _ := args.Get(0).([]string)
+	return codes, args.Error(1)
+}
+
+func (m *MockApiClient) DisableTOTP(ctx context.Context, code string) error {
+	args := m.Called(ctx, code)
+	return args.Error(0)
+}
+
+func (m *MockApiClient) ListSessions(ctx context.Context) ([]models.Session, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]models.Session), args.Error(1)
+}
+
+func (m *MockApiClient) RevokeSession(ctx context.Context, ID uint64) error {
+	args := m.Called(ctx, ID)
+	return args.Error(0)
+}
+
+func (m *MockApiClient) Logout(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
 func (m *MockApiClient) LoadSecrets(ctx context.Context) ([]*models.Secret, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]*models.Secret), args.Error(1)
@@ -46,6 +89,48 @@ func (m *MockApiClient) DeleteSecret(ctx context.Context, ID uint64) error {
 	return args.Error(0)
 }
 
+func (m *MockApiClient) SyncSecrets(ctx context.Context, cursor string) (*models.SecretChanges, error) {
+	args := m.Called(ctx, cursor)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SecretChanges), args.Error(1)
+}
+
+func (m *MockApiClient) LoadRevisions(ctx context.Context, id uint64) ([]*models.Secret, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Secret), args.Error(1)
+}
+
+func (m *MockApiClient) LoadRevision(ctx context.Context, id uint64, revision uint64) (*models.Secret, error) {
+	args := m.Called(ctx, id, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Secret), args.Error(1)
+}
+
+func (m *MockApiClient) LoadTrash(ctx context.Context) ([]*models.Secret, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Secret), args.Error(1)
+}
+
+func (m *MockApiClient) RestoreSecret(ctx context.Context, ID uint64) error {
+	args := m.Called(ctx, ID)
+	return args.Error(0)
+}
+
+func (m *MockApiClient) PurgeSecret(ctx context.Context, ID uint64) error {
+	args := m.Called(ctx, ID)
+	return args.Error(0)
+}
+
 func (m *MockApiClient) SetToken(token string) {
 	m.Called(token)
 }
@@ -55,6 +140,11 @@ func (m *MockApiClient) GetToken() string {
 	return args.String(0)
 }
 
+func (m *MockApiClient) GetLogin() string {
+	args := m.Called()
+	return args.String(0)
+}
+
 func (m *MockApiClient) SetPassword(password string) {
 	m.Called(password)
 }
@@ -64,6 +154,25 @@ func (m *MockApiClient) GetPassword() string {
 	return args.String(0)
 }
 
+func (m *MockApiClient) SetEncryptionKey(key string) {
+	m.Called(key)
+}
+
+func (m *MockApiClient) GetEncryptionKey() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockApiClient) GetKDF() models.AccountKDF {
+	args := m.Called()
+	return args.Get(0).(models.AccountKDF)
+}
+
+func (m *MockApiClient) UpgradeAuth(ctx context.Context, kdf models.AccountKDF, keys crypto.AccountKeys, secrets []models.SecretPayload) error {
+	args := m.Called(ctx, kdf, keys, secrets)
+	return args.Error(0)
+}
+
 func (m *MockApiClient) Notifications(p *tea.Program) {
 }
 
@@ -72,7 +181,7 @@ func TestRemoteStorage(t *testing.T) {
 	encrypter := &MockEncrypter{}
 	password := "testpassword"
 
-	mockClient.On("GetPassword").Return(password)
+	mockClient.On("GetEncryptionKey").Return(password)
 	store, err := NewRemoteStorage(mockClient, encrypter)
 	assert.NoError(t, err)
 
@@ -129,15 +238,49 @@ func TestRemoteStorage(t *testing.T) {
 	})
 
 	t.Run("Get All Secrets", func(t *testing.T) {
-		secrets := []*models.Secret{
-			{ID: 1, Title: "Secret 1"},
-			{ID: 2, Title: "Secret 2"},
-		}
-		mockClient.On("LoadSecrets", mock.Anything).Return(secrets, nil)
+		mockClient.On("SyncSecrets", mock.Anything, "").Return(&models.SecretChanges{
+			Updated: models.Secrets{{ID: 1, Title: "Secret 1"}, {ID: 2, Title: "Secret 2"}},
+			Cursor:  "2",
+			Reset:   true,
+		}, nil).Once()
+
+		result, err := store.GetAll(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, result, 2)
+	})
+
+	t.Run("Get All Applies Delta", func(t *testing.T) {
+		mockClient.On("SyncSecrets", mock.Anything, "2").Return(&models.SecretChanges{
+			Updated: models.Secrets{{ID: 3, Title: "Secret 3"}},
+			Deleted: []uint64{1},
+			Cursor:  "4",
+			HasMore: true,
+		}, nil).Once()
+		mockClient.On("SyncSecrets", mock.Anything, "4").Return(&models.SecretChanges{
+			Deleted: []uint64{3},
+			Cursor:  "5",
+		}, nil).Once()
 
 		result, err := store.GetAll(context.Background())
 		assert.NoError(t, err)
-		assert.Len(t, result, len(secrets))
-		mockClient.AssertCalled(t, "LoadSecrets", mock.Anything)
+		if assert.Len(t, result, 1) {
+			assert.Equal(t, "Secret 2", result[0].Title)
+		}
 	})
 }
+
+func TestRemoteStorage_OldServer(t *testing.T) {
+	mockClient := new(MockApiClient)
+
+	mockClient.On("GetEncryptionKey").Return("testpassword")
+	store, err := NewRemoteStorage(mockClient, &MockEncrypter{})
+	assert.NoError(t, err)
+
+	mockClient.On("SyncSecrets", mock.Anything, "").Return(nil, entities.ErrNotSupported)
+	mockClient.On("LoadSecrets", mock.Anything).Return([]*models.Secret{{ID: 1, Title: "Secret 1"}}, nil)
+
+	result, err := store.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, result, 1)
+	mockClient.AssertCalled(t, "LoadSecrets", mock.Anything)
+}
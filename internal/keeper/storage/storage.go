@@ -14,3 +14,67 @@ type Storage interface {
 	String() string
 	Close(ctx context.Context) error
 }
+
+// Storage which can be re-keyed with new password
+type PasswordChanger interface {
+	ChangePassword(ctx context.Context, oldPassword string, newPassword string) error
+}
+
+// Storage which adds many secrets at once, re-encrypting and saving itself only once
+type BatchCreator interface {
+	CreateBatch(ctx context.Context, secrets []*models.Secret) error
+}
+
+// Storage which keeps previous versions of updated secrets
+type HistoryKeeper interface {
+	// Previous versions of secret, newest first. Contents may be left out, see Revision.
+	History(ctx context.Context, id uint64) ([]*models.Secret, error)
+	// Previous version of secret with its contents
+	Revision(ctx context.Context, id uint64, revision uint64) (*models.Secret, error)
+}
+
+// Storage which moves deleted secrets to trash instead of dropping them
+type TrashKeeper interface {
+	// Secrets in trash, with DeletedAt set
+	Trashed(ctx context.Context) ([]*models.Secret, error)
+	// Move secret from trash back to live secrets
+	Restore(ctx context.Context, id uint64) error
+	// Delete secret in trash for good
+	Purge(ctx context.Context, id uint64) error
+}
+
+// Storage backed by server account, whose login sessions on devices can be listed and ended
+type SessionKeeper interface {
+	// Active sessions of account, the current one marked
+	Sessions(ctx context.Context) ([]models.Session, error)
+	// End session of another device, it has to log in again
+	RevokeSession(ctx context.Context, id uint64) error
+	// End current session, storage can not reach server afterwards
+	Logout(ctx context.Context) error
+}
+
+// Storage backed by server account which can require one-time code of authenticator app at login
+type TwoFactorKeeper interface {
+	// Whether second factor is enabled and how many recovery codes are left
+	TwoFactor(ctx context.Context) (models.TwoFactorStatus, error)
+	// New secret for authenticator app, second factor is enabled by ConfirmTwoFactor
+	EnrollTwoFactor(ctx context.Context) (models.TOTPEnrollment, error)
+	// Enable second factor with code of authenticator app, returns recovery codes to keep safe
+	ConfirmTwoFactor(ctx context.Context, code string) ([]string, error)
+	// Turn second factor off with one-time code or recovery code
+	DisableTwoFactor(ctx context.Context, code string) error
+}
+
+// How to settle a change rejected because secret was changed elsewhere
+type Resolution int
+
+const (
+	ResolveKeepMine   Resolution = iota // overwrite server copy with local change
+	ResolveTakeTheirs                   // drop local change
+	ResolveDuplicate                    // keep server copy, save local change as a new secret
+)
+
+// Storage which can settle conflicting changes reported with entities.ConflictError
+type ConflictResolver interface {
+	Resolve(ctx context.Context, id uint64, how Resolution) error
+}
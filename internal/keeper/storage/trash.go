@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"gophkeeper/pkg/models"
+	"slices"
+)
+
+// Copies of trashed secrets, newest deletion first
+func listTrash(trash map[uint64]models.Secret) []*models.Secret {
+	arr := make([]*models.Secret, 0, len(trash))
+	for id, secret := range trash {
+		secret = secret.Clone()
+		secret.ID = id
+		arr = append(arr, &secret)
+	}
+
+	slices.SortFunc(arr, func(a, b *models.Secret) int {
+		return b.DeletedAt.Compare(*a.DeletedAt)
+	})
+
+	return arr
+}
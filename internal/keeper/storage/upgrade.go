@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/pkg/models"
+)
+
+// Switch legacy account, whose payloads are encrypted with password known to server, to keys derived
+// from password: every server secret, trash included, is re-encrypted with keys.Encryption and handed
+// to server along with auth key in one request, then replica is re-encrypted too. Queued changes are kept
+// in replica as plain secrets, so they are delivered encrypted with new key. Server calls are made
+// with syncing held, so no sync sends changes with old key meanwhile, store lock is taken only to swap keys.
+func (store *CachedStorage) UpgradeAccount(ctx context.Context, kdf models.AccountKDF, keys crypto.AccountKeys) error {
+	store.syncing.Lock()
+	defer store.syncing.Unlock()
+
+	payloads, err := store.remote.reencrypt(ctx, keys.Encryption)
+	if err != nil {
+		return err
+	}
+
+	if err := store.remote.client.UpgradeAuth(ctx, kdf, keys, payloads); err != nil {
+		return fmt.Errorf("failed to upgrade account: %w", err)
+	}
+
+	store.Lock()
+	defer store.Unlock()
+
+	store.remote.password = keys.Encryption
+	store.password = keys.Encryption
+
+	return store.save()
+}
+
+// Payloads of all server secrets encrypted with key instead of current one
+func (store *RemoteStorage) reencrypt(ctx context.Context, key string) ([]models.SecretPayload, error) {
+	secrets, err := store.client.LoadSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trash, err := store.client.LoadTrash(ctx)
+	if err != nil && !errors.Is(err, entities.ErrNotSupported) {
+		return nil, err
+	}
+
+	payloads := make([]models.SecretPayload, 0, len(secrets)+len(trash))
+	for _, secret := range append(secrets, trash...) {
+		data, err := store.encrypter.Decrypt(secret.Payload, store.password)
+		if err != nil {
+			return nil, fmt.Errorf("reencrypt(): failed to decrypt %q: %w", secret.Title, err)
+		}
+
+		encrypted, err := store.encrypter.Encrypt(data, key)
+		if err != nil {
+			return nil, fmt.Errorf("reencrypt(): failed to encrypt %q: %w", secret.Title, err)
+		}
+
+		payloads = append(payloads, models.SecretPayload{ID: secret.ID, Revision: secret.Revision, Payload: encrypted})
+	}
+
+	return payloads, nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Delay after last file event before reload, sync clients may write vault in several steps
+const reloadDebounce = 200 * time.Millisecond
+
+// Paths of local storages reloaded after external change, consumed by TUI
+var reloads = make(chan string, 1)
+
+// Channel receiving path of local storage each time it was reloaded from disk.
+// Notifications are coalesced, receiver should re-read the whole list.
+func Reloads() <-chan string {
+	return reloads
+}
+
+func notifyReload(path string) {
+	select {
+	case reloads <- path:
+	default: // reload is already pending
+	}
+}
+
+// Watches vault file for changes made by other processes.
+// Vault is replaced by rename, so the directory is watched and events are filtered by name.
+type fileWatcher struct {
+	path     string
+	fsw      *fsnotify.Watcher
+	onChange func()
+	done     chan struct{}
+}
+
+func watchFile(path string, onChange func()) (*fileWatcher, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("watchFile(): %w", err)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("watchFile(): failed to create watcher: %w", err)
+	}
+
+	if err := fsw.Add(filepath.Dir(abs)); err != nil {
+		_ = fsw.Close()
+		return nil, fmt.Errorf("watchFile(): failed to watch dir: %w", err)
+	}
+
+	w := &fileWatcher{
+		path:     abs,
+		fsw:      fsw,
+		onChange: onChange,
+		done:     make(chan struct{}),
+	}
+
+	go w.run()
+
+	return w, nil
+}
+
+func (w *fileWatcher) run() {
+	defer close(w.done)
+
+	var timer <-chan time.Time
+
+	for {
+		select {
+		case event, ok := <-w.fsw.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Clean(event.Name) != w.path || !event.Has(fsnotify.Write|fsnotify.Create) {
+				continue
+			}
+
+			timer = time.After(reloadDebounce)
+		case err, ok := <-w.fsw.Errors:
+			if !ok {
+				return
+			}
+
+			log.Printf("watch %s: %v", w.path, err)
+		case <-timer:
+			timer = nil
+			w.onChange()
+		}
+	}
+}
+
+// Stop watching and wait for pending reload to finish, nil-safe
+func (w *fileWatcher) stop() error {
+	if w == nil {
+		return nil
+	}
+
+	err := w.fsw.Close()
+	<-w.done
+
+	return err
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStorageLiveReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.db")
+	encrypter := crypto.NewVaultEncrypter(testKDFParams)
+
+	writer, err := NewFileStorage(path, "password", encrypter)
+	require.NoError(t, err)
+	defer writer.Close(ctx)
+
+	reader, err := NewFileStorage(path, "password", encrypter, WithReadOnly())
+	require.NoError(t, err)
+	defer reader.Close(ctx)
+
+	// Drop notifications left by other tests
+	select {
+	case <-Reloads():
+	default:
+	}
+
+	require.NoError(t, writer.Create(ctx, &models.Secret{Title: "new one"}))
+
+	select {
+	case reloaded := <-Reloads():
+		abs, err := filepath.Abs(path)
+		require.NoError(t, err)
+		assert.Equal(t, abs, reloaded)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload notification")
+	}
+
+	secrets, err := reader.GetAll(ctx)
+	require.NoError(t, err)
+	require.Len(t, secrets, 1)
+	assert.Equal(t, "new one", secrets[0].Title)
+
+	t.Run("Own save", func(t *testing.T) {
+		reloaded, err := writer.reload()
+		require.NoError(t, err)
+		assert.False(t, reloaded)
+	})
+}
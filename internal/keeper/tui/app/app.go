@@ -5,6 +5,10 @@ import (
 	"fmt"
 	"gophkeeper/internal/keeper/api"
 	"gophkeeper/internal/keeper/config"
+	"gophkeeper/internal/keeper/sshagent"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
 	"gophkeeper/internal/keeper/tui/top"
 	"log"
 	"os"
@@ -55,6 +59,12 @@ func (a App) Start() {
 	// Run notification monitor
 	go a.client.Notifications(p)
 
+	// Run local vault reload monitor
+	go a.localReloads(p)
+
+	// Run ssh-agent signature confirmations
+	go a.agentConfirmations(p)
+
 	// Run tea program
 	go func() {
 		_, err := p.Run()
@@ -66,6 +76,20 @@ func (a App) Start() {
 	}()
 }
 
+// Reload secret list when opened local vault is changed on disk by another process
+func (a App) localReloads(p *tea.Program) {
+	for range storage.Reloads() {
+		p.Send(tui.ReloadSecretList{})
+	}
+}
+
+// Ask user to confirm signatures requested from ssh-agent
+func (a App) agentConfirmations(p *tea.Program) {
+	for c := range sshagent.Confirmations() {
+		p.Send(screens.ConfirmSignature(c)())
+	}
+}
+
 func (a App) Shutdown() {
 	err := a.logFile.Close()
 	if err != nil {
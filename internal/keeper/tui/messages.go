@@ -11,6 +11,23 @@ import (
 type NavigationCallback func(args ...any) tea.Cmd
 type ReloadSecretList struct{}
 
+// Subset of secrets listed by storage browse
+type SecretFilter struct {
+	Folder string // folder with its subfolders, all secrets when empty
+	Tag    string // secrets with tag, any when empty
+}
+
+// Storage browse was opened for storage. Folder tree answers with SecretFilterMsg.
+type StorageShownMsg struct {
+	Storage storage.Storage
+}
+
+// Filter picked in folder tree for secrets of storage
+type SecretFilterMsg struct {
+	Storage storage.Storage
+	Filter  SecretFilter
+}
+
 // NavigationMsg is an instruction to navigate to a page
 type NavigationMsg struct {
 	Screen       Screen
@@ -18,10 +35,11 @@ type NavigationMsg struct {
 	Position     Position
 	DisableFocus bool
 
-	Callback NavigationCallback
-	Client   api.IApiClient
-	Secret   *models.Secret
-	Storage  storage.Storage
+	Callback  NavigationCallback
+	Client    api.IApiClient
+	Secret    *models.Secret
+	SecretIDs []uint64
+	Storage   storage.Storage
 }
 
 func NewNavigationMsg(screen Screen, opts ...NavigateOption) NavigationMsg {
@@ -64,6 +82,12 @@ func WithSecret(sec *models.Secret) NavigateOption {
 	}
 }
 
+func WithSecretIDs(ids []uint64) NavigateOption {
+	return func(msg *NavigationMsg) {
+		msg.SecretIDs = ids
+	}
+}
+
 func DisableFocus() NavigateOption {
 	return func(msg *NavigationMsg) {
 		msg.DisableFocus = true
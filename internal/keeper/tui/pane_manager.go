@@ -24,11 +24,22 @@ const (
 	LoginScreen
 	RegisterScreen
 	RemoteOpenScreen
+	MigrateScreen
+	ImportScreen
+	ExportScreen
+	HistoryScreen
+	TrashScreen
+	FolderScreen
+	HealthScreen
+	SessionsScreen
+	TwoFactorScreen
 
 	CredentialEditScreen
 	TextEditScreen
 	CardEditScreen
 	BlobEditScreen
+	TOTPEditScreen
+	SSHKeyEditScreen
 )
 
 var (
@@ -19,6 +19,14 @@ type PromptMsg struct {
 	Key          key.Binding  // Key that when pressed triggers the action and closes the prompt
 	Cancel       key.Binding  // Cancel is a key that when pressed skips the action and closes the prompt
 	AnyCancel    bool         // If any key can cancel the prompt
+	Password     bool         // Mask user input
+	Choices      []Choice     // Keys each triggering its own action, see ChoicePrompt
+}
+
+// Answer of ChoicePrompt
+type Choice struct {
+	Key    key.Binding
+	Action func() tea.Cmd
 }
 
 type PromptAction func(text string) tea.Cmd
@@ -39,6 +47,42 @@ func StringPrompt(prompt string, action PromptAction) tea.Cmd {
 	})
 }
 
+// Same as StringPrompt, but input starts with value to edit
+func EditPrompt(prompt string, initial string, action PromptAction) tea.Cmd {
+	return CmdHandler(PromptMsg{
+		Prompt:       fmt.Sprintf("%s: ", prompt),
+		InitialValue: initial,
+		Action:       action,
+		Key: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		AnyCancel: false,
+	})
+}
+
+// Same as StringPrompt, but input is masked
+func PasswordPrompt(prompt string, action PromptAction) tea.Cmd {
+	return CmdHandler(PromptMsg{
+		Prompt: fmt.Sprintf("%s: ", prompt),
+		Action: action,
+		Key: key.NewBinding(
+			key.WithKeys("enter"),
+			key.WithHelp("enter", "confirm"),
+		),
+		Cancel: key.NewBinding(
+			key.WithKeys("esc"),
+			key.WithHelp("esc", "cancel"),
+		),
+		AnyCancel: false,
+		Password:  true,
+	})
+}
+
 // Yes/No question. If yes is given then the action is invoked.
 func YesNoPrompt(prompt string, action tea.Cmd) tea.Cmd {
 	return CmdHandler(PromptMsg{
@@ -54,12 +98,24 @@ func YesNoPrompt(prompt string, action tea.Cmd) tea.Cmd {
 	})
 }
 
+// Question with several answers, each bound to its own key. Any other key cancels.
+func ChoicePrompt(prompt string, choices ...Choice) tea.Cmd {
+	return CmdHandler(PromptMsg{
+		Prompt:    fmt.Sprintf("%s: ", prompt),
+		Choices:   choices,
+		AnyCancel: true,
+	})
+}
+
 func NewPrompt(msg PromptMsg) (*Prompt, tea.Cmd) {
 	model := textinput.New()
 	model.Prompt = msg.Prompt
 	model.SetValue(msg.InitialValue)
 	model.Placeholder = msg.Placeholder
 	model.PlaceholderStyle = styles.Regular.Faint(true)
+	if msg.Password {
+		model.EchoMode = textinput.EchoPassword
+	}
 	blink := model.Focus()
 
 	prompt := Prompt{
@@ -68,6 +124,7 @@ func NewPrompt(msg PromptMsg) (*Prompt, tea.Cmd) {
 		trigger:   msg.Key,
 		cancel:    msg.Cancel,
 		anyCancel: msg.AnyCancel,
+		choices:   msg.Choices,
 	}
 	return &prompt, blink
 }
@@ -79,11 +136,18 @@ type Prompt struct {
 	trigger   key.Binding
 	cancel    key.Binding
 	anyCancel bool
+	choices   []Choice
 }
 
 // HandleKey handles the user key press, and returns a command to be run, and
 // whether the prompt should be closed.
 func (p *Prompt) HandleKey(msg tea.KeyMsg) (closePrompt bool, cmd tea.Cmd) {
+	for _, choice := range p.choices {
+		if key.Matches(msg, choice.Key) {
+			return true, choice.Action()
+		}
+	}
+
 	switch {
 	case key.Matches(msg, p.trigger):
 		cmd = p.action(p.model.Value())
@@ -126,6 +190,9 @@ func (p *Prompt) HelpBindings() []key.Binding {
 	bindings := []key.Binding{
 		p.trigger,
 	}
+	for _, choice := range p.choices {
+		bindings = append(bindings, choice.Key)
+	}
 	if p.anyCancel {
 		bindings = append(bindings, key.NewBinding(key.WithHelp("n", "cancel")))
 	} else {
@@ -0,0 +1,32 @@
+package screens
+
+import (
+	"fmt"
+	"gophkeeper/internal/keeper/sshagent"
+	"gophkeeper/internal/keeper/tui"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Ask whether ssh-agent may sign with key. Unanswered request is denied after sshagent.ConfirmTimeout.
+func ConfirmSignature(c *sshagent.Confirmation) tea.Cmd {
+	prompt := fmt.Sprintf("ssh-agent: sign with %q (%s)? (y)es or (n)o", c.Title, c.Fingerprint)
+
+	return tui.ChoicePrompt(prompt,
+		tui.Choice{
+			Key: key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "allow signature")),
+			Action: func() tea.Cmd {
+				c.Allow()
+				return tui.ReportInfo("signature allowed")
+			},
+		},
+		tui.Choice{
+			Key: key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "deny signature")),
+			Action: func() tea.Cmd {
+				c.Deny()
+				return tui.ReportInfo("signature denied")
+			},
+		},
+	)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
 	"gophkeeper/internal/keeper/tui/components"
@@ -63,11 +64,11 @@ func NewBlobEditScreen(secret *models.Secret, strg storage.Storage) *BlobEditScr
 			}
 
 			err := m.Submit(str)
-			if err != nil {
+			if err != nil && !errors.Is(err, entities.ErrConflict) {
 				return tui.ReportError(fmt.Errorf("error uploading file: %w", err))
 			}
 
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+			return screens.AfterSave(m.storage, m.secret.ID, err)
 		}
 
 		return tui.SetBodyPane(tui.FilePickScreen, tui.WithStorage(m.storage), tui.WithCallback(f), tui.WithSecret(secret))
@@ -0,0 +1,67 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Navigate back to the list after secret was saved. Edit rejected because
+// secret was changed on another device opens resolve dialog instead.
+func AfterSave(strg storage.Storage, id uint64, err error) tea.Cmd {
+	if err == nil {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(strg))
+	}
+
+	var conflict *entities.ConflictError
+	if errors.As(err, &conflict) {
+		return ResolveConflict(strg, id, conflict)
+	}
+
+	return tui.ReportError(err)
+}
+
+// Ask whether to keep local change, take server copy or keep both
+func ResolveConflict(strg storage.Storage, id uint64, conflict *entities.ConflictError) tea.Cmd {
+	resolver, ok := strg.(storage.ConflictResolver)
+	if !ok || conflict.Theirs == nil {
+		return tui.ReportError(conflict)
+	}
+
+	resolve := func(how storage.Resolution, done string) func() tea.Cmd {
+		return func() tea.Cmd {
+			if err := resolver.Resolve(context.Background(), id, how); err != nil {
+				return AfterSave(strg, id, err)
+			}
+
+			return tea.Batch(
+				tui.ReportInfo("%s", done),
+				tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(strg)),
+			)
+		}
+	}
+
+	prompt := fmt.Sprintf("%q was changed on another device (%s). Keep (m)ine, take (t)heirs or (d)uplicate",
+		conflict.Theirs.Title, conflict.Theirs.UpdatedAt.Local().Format("02 Jan 06 15:04"))
+
+	return tui.ChoicePrompt(prompt,
+		tui.Choice{
+			Key:    key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "keep mine")),
+			Action: resolve(storage.ResolveKeepMine, "your version saved"),
+		},
+		tui.Choice{
+			Key:    key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "take theirs")),
+			Action: resolve(storage.ResolveTakeTheirs, "your change discarded"),
+		},
+		tui.Choice{
+			Key:    key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "duplicate")),
+			Action: resolve(storage.ResolveDuplicate, "your version saved as a copy"),
+		},
+	)
+}
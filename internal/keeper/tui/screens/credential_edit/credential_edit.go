@@ -3,14 +3,18 @@ package credentialedit
 import (
 	"context"
 	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/passgen"
 	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
 	"gophkeeper/internal/keeper/tui/components"
 	"gophkeeper/internal/keeper/tui/screens"
 	"gophkeeper/internal/keeper/tui/styles"
 	"gophkeeper/pkg/models"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
@@ -34,6 +38,10 @@ type CredentialEditScreen struct {
 	storage storage.Storage
 
 	inputGroup components.InputGroup
+
+	generator generator
+	generated string  // last generated password, its entropy is known exactly
+	entropy   float64 // entropy of generated password
 }
 
 func (s CredentialEditScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
@@ -42,25 +50,20 @@ func (s CredentialEditScreen) Make(msg tui.NavigationMsg, width, height int) (tu
 
 func NewCredentialEditScreen(secret *models.Secret, strg storage.Storage) *CredentialEditScreen {
 	m := CredentialEditScreen{
-		secret:  secret,
-		storage: strg,
+		secret:    secret,
+		storage:   strg,
+		generator: newGenerator(),
 	}
 
 	inputs := make([]textinput.Model, 4)
 	inputs[credTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
 	inputs[credMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
 	inputs[credLogin] = newInput(inputOpts{placeholder: "Login", charLimit: 64})
-	inputs[credPassword] = newInput(inputOpts{placeholder: "Password", charLimit: 64})
+	inputs[credPassword] = newInput(inputOpts{placeholder: "Password", charLimit: passgen.MaxLength})
 
 	buttons := []components.Button{}
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
-		err := m.Submit()
-		if err != nil {
-			return tui.ReportError(err)
-		} else {
-			// todo: invalidate or update cache ?
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
-		}
+		return screens.AfterSave(m.storage, m.secret.ID, m.Submit())
 	}})
 
 	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
@@ -89,6 +92,21 @@ func (s *CredentialEditScreen) Update(msg tea.Msg) tea.Cmd {
 		cmds []tea.Cmd
 	)
 
+	switch msg := msg.(type) {
+	case generatorMsg: // msg from generator options prompts
+		s.generator = msg.generator
+		return s.generate()
+	case tea.KeyMsg:
+		if s.inputGroup.FocusIndex == credPassword {
+			switch msg.String() {
+			case "ctrl+g": // generate password
+				return s.generate()
+			case "ctrl+o": // generator options
+				return s.generator.choose()
+			}
+		}
+	}
+
 	ig, cmd := s.inputGroup.Update(msg)
 	s.inputGroup = ig.(components.InputGroup)
 
@@ -97,6 +115,39 @@ func (s *CredentialEditScreen) Update(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+func (s *CredentialEditScreen) generate() tea.Cmd {
+	password, entropy, err := s.generator.generate()
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to generate password: %w", err))
+	}
+
+	s.generated, s.entropy = password, entropy
+	s.inputGroup.Inputs[credPassword].SetValue(password)
+
+	return nil
+}
+
+// Entropy of generated password, estimate for typed one
+func (s CredentialEditScreen) strength() string {
+	password := s.inputGroup.Inputs[credPassword].Value()
+	if password == "" {
+		return ""
+	}
+
+	if password == s.generated {
+		return passgen.Describe(s.entropy)
+	}
+
+	return passgen.Describe(passgen.Estimate(password)) + " (estimate)"
+}
+
+func (s CredentialEditScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("ctrl+g"), key.WithHelp("ctrl+g", "generate password, in password field")),
+		key.NewBinding(key.WithKeys("ctrl+o"), key.WithHelp("ctrl+o", "password generator options, in password field")),
+	}
+}
+
 func (s *CredentialEditScreen) Submit() error {
 	var (
 		err error
@@ -141,7 +192,16 @@ func (s *CredentialEditScreen) Submit() error {
 }
 
 func (s CredentialEditScreen) View() string {
-	return screens.RenderContent("Fill in credential details:", s.inputGroup.View())
+	var b strings.Builder
+
+	b.WriteString(s.inputGroup.View())
+	b.WriteString("\n")
+	if strength := s.strength(); strength != "" {
+		b.WriteString("Password strength: " + strength + "\n")
+	}
+	b.WriteString(styles.Blurred.Render(fmt.Sprintf("In password field: ctrl+g to generate %s, ctrl+o to change generator", s.generator)))
+
+	return screens.RenderContent("Fill in credential details:", b.String())
 }
 
 type inputOpts struct {
@@ -0,0 +1,116 @@
+package credentialedit
+
+import (
+	"fmt"
+	"gophkeeper/internal/keeper/passgen"
+	"gophkeeper/internal/keeper/tui"
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Options of password generator picked with ctrl+o, passphrase is generated when words is set
+type generator struct {
+	password passgen.Options
+	phrase   passgen.PassphraseOptions
+	words    bool
+}
+
+func newGenerator() generator {
+	return generator{
+		password: passgen.DefaultOptions(),
+		phrase:   passgen.DefaultPassphraseOptions(),
+	}
+}
+
+// Generator options chosen by user
+type generatorMsg struct {
+	generator generator
+}
+
+func (g generator) generate() (string, float64, error) {
+	if g.words {
+		phrase, err := passgen.Passphrase(g.phrase)
+		return phrase, g.phrase.Entropy(), err
+	}
+
+	password, err := passgen.Password(g.password)
+	return password, g.password.Entropy(), err
+}
+
+func (g generator) String() string {
+	if g.words {
+		return fmt.Sprintf("%d words", g.phrase.Words)
+	}
+
+	s := fmt.Sprintf("%d characters", g.password.Length)
+	if !g.password.ExcludeAmbiguous {
+		s += " with ambiguous"
+	}
+
+	return s
+}
+
+// Ask kind of password, then its length
+func (g generator) choose() tea.Cmd {
+	preset := func(k, help string, apply func(g *generator)) tui.Choice {
+		return tui.Choice{
+			Key: key.NewBinding(key.WithKeys(k), key.WithHelp(k, help)),
+			Action: func() tea.Cmd {
+				next := g
+				apply(&next)
+				return next.askLength()
+			},
+		}
+	}
+
+	classes := func(lower, upper, digits, symbols bool) func(g *generator) {
+		return func(g *generator) {
+			g.words = false
+			g.password.Lower, g.password.Upper, g.password.Digits, g.password.Symbols = lower, upper, digits, symbols
+		}
+	}
+
+	return tui.ChoicePrompt("Generate (a)ll characters, (l)etters and digits, (d)igits, (w)ords passphrase or toggle ambiguous (x)",
+		preset("a", "letters, digits and symbols", classes(true, true, true, true)),
+		preset("l", "letters and digits", classes(true, true, true, false)),
+		preset("d", "digits only, e.g. PIN", classes(false, false, true, false)),
+		preset("w", "diceware passphrase", func(g *generator) { g.words = true }),
+		tui.Choice{
+			Key: key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "allow or exclude ambiguous characters like l, 1, O, 0")),
+			Action: func() tea.Cmd {
+				next := g
+				next.password.ExcludeAmbiguous = !next.password.ExcludeAmbiguous
+				return tui.CmdHandler(generatorMsg{generator: next})
+			},
+		},
+	)
+}
+
+func (g generator) askLength() tea.Cmd {
+	prompt, current := fmt.Sprintf("password length, %d-%d", passgen.MinLength, passgen.MaxLength), g.password.Length
+	if g.words {
+		prompt, current = fmt.Sprintf("number of words, %d-%d", passgen.MinWords, passgen.MaxWords), g.phrase.Words
+	}
+
+	return tui.EditPrompt(prompt, strconv.Itoa(current), func(value string) tea.Cmd {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return tui.ReportError(fmt.Errorf("invalid number %q", value))
+		}
+
+		if g.words {
+			g.phrase.Words = n
+			err = g.phrase.Validate()
+		} else {
+			g.password.Length = n
+			err = g.password.Validate()
+		}
+		if err != nil {
+			return tui.ReportError(err)
+		}
+
+		return tui.CmdHandler(generatorMsg{generator: g})
+	})
+}
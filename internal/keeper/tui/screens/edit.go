@@ -0,0 +1,27 @@
+package screens
+
+import (
+	"fmt"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/pkg/models"
+)
+
+// Edit screen of secret type
+func EditScreenFor(secret *models.Secret) (tui.Screen, error) {
+	switch secret.SecretType {
+	case string(models.CredSecret):
+		return tui.CredentialEditScreen, nil
+	case string(models.TextSecret):
+		return tui.TextEditScreen, nil
+	case string(models.BlobSecret):
+		return tui.BlobEditScreen, nil
+	case string(models.CardSecret):
+		return tui.CardEditScreen, nil
+	case string(models.TOTPSecret):
+		return tui.TOTPEditScreen, nil
+	case string(models.SSHKeySecret):
+		return tui.SSHKeyEditScreen, nil
+	default:
+		return -1, fmt.Errorf("unknown secret type")
+	}
+}
@@ -0,0 +1,203 @@
+package exportsecrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/exporter"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/components"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	errPathEmpty        = errors.New("please enter path of export file")
+	errPasswordMismatch = errors.New("passwords do not match")
+)
+
+const (
+	posPath = iota
+	posFormat
+	posPassword
+	posRepeat
+)
+
+// Plaintext export or overwrite of existing file confirmed by user
+type confirmExportMsg struct {
+	overwrite bool
+}
+
+// Writes secrets of browsed storage to JSON, CSV or encrypted archive
+type ExportScreen struct {
+	storage storage.Storage
+	ids     []uint64 // selected secrets, all when empty
+	export  *usecase.ExportSecretsUseCase
+
+	inputGroup components.InputGroup
+}
+
+type ExportScreenMaker struct {
+	Encrypter crypto.Encrypter
+}
+
+func (m ExportScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewExportScreen(msg.Storage, msg.SecretIDs, m.Encrypter), nil
+}
+
+func NewExportScreen(store storage.Storage, ids []uint64, encrypter crypto.Encrypter) *ExportScreen {
+	scr := &ExportScreen{
+		storage: store,
+		ids:     ids,
+		export:  usecase.NewExportSecretsUseCase(encrypter),
+	}
+
+	formats := make([]string, 0, len(exporter.Formats()))
+	for _, f := range exporter.Formats() {
+		formats = append(formats, string(f))
+	}
+
+	inputs := make([]textinput.Model, 4)
+	inputs[posPath] = newInput(inputOpts{placeholder: "Export file path", charLimit: 256, focus: true, value: "gophkeeper-export" + exporter.FormatArchive.Ext()})
+	inputs[posFormat] = newInput(inputOpts{placeholder: "Format: " + strings.Join(formats, ", "), charLimit: 16, value: string(exporter.FormatArchive)})
+	inputs[posPassword] = newInput(inputOpts{placeholder: "Archive password", charLimit: 64})
+	inputs[posPassword].EchoMode = textinput.EchoPassword
+	inputs[posRepeat] = newInput(inputOpts{placeholder: "Repeat archive password", charLimit: 64})
+	inputs[posRepeat].EchoMode = textinput.EchoPassword
+
+	buttons := []components.Button{}
+	buttons = append(buttons, components.Button{Title: "[ Export ]", Cmd: func() tea.Cmd {
+		return scr.Submit()
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(scr.storage))
+	}})
+
+	scr.inputGroup = components.NewInputGroup(inputs, buttons)
+
+	return scr
+}
+
+func (s ExportScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+func (s *ExportScreen) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(confirmExportMsg); ok {
+		return s.write(msg.overwrite)
+	}
+
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	return cmd
+}
+
+func (s ExportScreen) View() string {
+	what := "all secrets"
+	if len(s.ids) > 0 {
+		what = fmt.Sprintf("%d selected secret(s)", len(s.ids))
+	}
+
+	header := fmt.Sprintf("Export %s of %s", what, s.storage.String())
+
+	return screens.RenderContent(header, s.inputGroup.View())
+}
+
+// Validate inputs, asking for confirmation before writing secrets unencrypted or replacing existing file
+func (s *ExportScreen) Submit() tea.Cmd {
+	path := strings.TrimSpace(s.inputGroup.Inputs[posPath].Value())
+	if path == "" {
+		return tui.ReportError(errPathEmpty)
+	}
+
+	format, err := exporter.ParseFormat(s.inputGroup.Inputs[posFormat].Value())
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	_, err = os.Stat(path)
+	exists := err == nil
+
+	var prompt string
+
+	switch {
+	case !format.Plaintext():
+		if s.inputGroup.Inputs[posPassword].Value() != s.inputGroup.Inputs[posRepeat].Value() {
+			return tui.ReportError(errPasswordMismatch)
+		}
+
+		if !exists {
+			return s.write(false)
+		}
+
+		prompt = fmt.Sprintf("%s exists and will be overwritten. Continue?", path)
+	case exists:
+		prompt = fmt.Sprintf("%s exists and will be overwritten with UNENCRYPTED secrets. Continue?", path)
+	default:
+		prompt = fmt.Sprintf("Secrets will be written UNENCRYPTED to %s, anyone with the file can read them. Continue?", path)
+	}
+
+	return tui.YesNoPrompt(prompt, func() tea.Msg {
+		return confirmExportMsg{overwrite: exists}
+	})
+}
+
+// Write export file, existing one is replaced only if user agreed to overwrite it
+func (s *ExportScreen) write(overwrite bool) tea.Cmd {
+	format, err := exporter.ParseFormat(s.inputGroup.Inputs[posFormat].Value())
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	path := strings.TrimSpace(s.inputGroup.Inputs[posPath].Value())
+
+	n, err := s.export.Call(context.Background(), s.storage, path, usecase.ExportOptions{
+		Format:    format,
+		IDs:       s.ids,
+		Password:  s.inputGroup.Inputs[posPassword].Value(),
+		Overwrite: overwrite,
+	})
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	return tea.Batch(
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)),
+		tui.ReportInfo("%d secrets exported to %s", n, path),
+	)
+}
+
+type inputOpts struct {
+	placeholder string
+	charLimit   int
+	focus       bool
+	value       string
+}
+
+func newInput(opts inputOpts) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = opts.charLimit
+	t.Placeholder = opts.placeholder
+
+	if len(opts.value) > 0 {
+		t.SetValue(opts.value)
+	}
+
+	if opts.focus {
+		t.Focus()
+		t.PromptStyle = styles.Focused
+		t.TextStyle = styles.Focused
+	}
+
+	return t
+}
@@ -0,0 +1,201 @@
+package foldertree
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/usecase"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const indent = "  "
+
+// Row of tree, picks folder or tag of filter
+type item struct {
+	label  string
+	count  int
+	depth  int
+	folder string
+	tag    string // set for tag rows only
+}
+
+// Folder tree and tags of storage shown in left pane next to storage browse
+type FolderTreeScreen struct {
+	storage storage.Storage
+	filter  tui.SecretFilter // filter applied to storage browse
+
+	folders []item
+	tags    []item
+	cursor  int
+	height  int
+}
+
+func (s FolderTreeScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewFolderTreeScreen(msg.Storage), nil
+}
+
+func NewFolderTreeScreen(strg storage.Storage) *FolderTreeScreen {
+	scr := &FolderTreeScreen{storage: strg}
+	scr.updateItems()
+
+	return scr
+}
+
+func (s FolderTreeScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *FolderTreeScreen) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case tui.StorageShownMsg: // storage browse opened, possibly for another storage
+		if msg.Storage != s.storage {
+			s.storage = msg.Storage
+			s.filter = tui.SecretFilter{}
+			s.cursor = 0
+		}
+		s.updateItems()
+
+		return s.applyFilter()
+	case tui.ReloadSecretList:
+		s.updateItems()
+	case tea.WindowSizeMsg:
+		s.height = msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "up", "k":
+			s.cursor = max(s.cursor-1, 0)
+		case "down", "j":
+			s.cursor = min(s.cursor+1, len(s.items())-1)
+		case "enter":
+			s.pick(s.items()[s.cursor])
+
+			return tea.Batch(
+				s.applyFilter(),
+				tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage), tui.DisableFocus()),
+			)
+		case "b":
+			return tui.SetLeftPane(tui.MenuScreen)
+		}
+	}
+
+	return nil
+}
+
+func (s FolderTreeScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString(sectionStyle.Render("Folders"))
+	b.WriteString("\n")
+
+	items := s.items()
+	for i, it := range items {
+		if i == len(s.folders) {
+			b.WriteString("\n")
+			b.WriteString(sectionStyle.Render("Tags"))
+			b.WriteString("\n")
+		}
+
+		b.WriteString(s.renderItem(i, it))
+		b.WriteString("\n")
+	}
+
+	if len(s.tags) == 0 {
+		b.WriteString("\n")
+		b.WriteString(countStyle.Render("no tags"))
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(countStyle.Render("enter to filter, (b)ack"))
+
+	return screenStyle.Render(b.String())
+}
+
+func (s *FolderTreeScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("up", "k"), key.WithHelp("↑/k", "move up")),
+		key.NewBinding(key.WithKeys("down", "j"), key.WithHelp("↓/j", "move down")),
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "show secrets")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to menu")),
+	}
+}
+
+func (s FolderTreeScreen) renderItem(i int, it item) string {
+	line := strings.Repeat(indent, it.depth) + it.label + " " + countStyle.Render(fmt.Sprintf("(%d)", it.count))
+
+	style := itemStyle
+	if s.active(it) {
+		style = style.Inherit(activeItemStyle)
+	}
+	if i == s.cursor {
+		return selectedItemStyle.Render("> ") + style.UnsetPaddingLeft().Render(line)
+	}
+
+	return style.Render(line)
+}
+
+// Folder rows followed by tag rows
+func (s FolderTreeScreen) items() []item {
+	return append(s.folders[:len(s.folders):len(s.folders)], s.tags...)
+}
+
+func (s *FolderTreeScreen) updateItems() {
+	if s.storage == nil {
+		return
+	}
+
+	secrets, _ := s.storage.GetAll(context.Background())
+
+	s.folders = []item{{label: "All secrets", count: len(secrets)}}
+	for _, node := range usecase.FolderTree(secrets) {
+		s.folders = append(s.folders, item{
+			label:  node.Name,
+			count:  node.Count,
+			depth:  node.Depth + 1,
+			folder: node.Path,
+		})
+	}
+
+	s.tags = nil
+	for _, tag := range usecase.TagCounts(secrets) {
+		s.tags = append(s.tags, item{
+			label: "#" + tag.Tag,
+			count: tag.Count,
+			tag:   tag.Tag,
+		})
+	}
+
+	s.cursor = min(s.cursor, len(s.items())-1)
+}
+
+// Folder rows replace folder of filter and keep tag, "All secrets" clears both.
+// Tag rows narrow current folder down to tag, picking active tag again clears it.
+func (s *FolderTreeScreen) pick(it item) {
+	switch {
+	case it.tag == "" && it.folder == "":
+		s.filter = tui.SecretFilter{}
+	case it.tag == "":
+		s.filter.Folder = it.folder
+	case strings.EqualFold(s.filter.Tag, it.tag):
+		s.filter.Tag = ""
+	default:
+		s.filter.Tag = it.tag
+	}
+}
+
+// Row matches current filter
+func (s FolderTreeScreen) active(it item) bool {
+	if it.tag != "" {
+		return strings.EqualFold(s.filter.Tag, it.tag)
+	}
+
+	return s.filter.Folder == it.folder
+}
+
+func (s FolderTreeScreen) applyFilter() tea.Cmd {
+	return tui.CmdHandler(tui.SecretFilterMsg{Storage: s.storage, Filter: s.filter})
+}
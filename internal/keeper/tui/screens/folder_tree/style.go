@@ -0,0 +1,18 @@
+package foldertree
+
+import (
+	"gophkeeper/internal/keeper/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	screenStyle = styles.Regular.Padding(0, 1)
+
+	itemStyle         = styles.Regular.PaddingLeft(2)
+	selectedItemStyle = styles.Regular.Foreground(lipgloss.Color("170"))
+	activeItemStyle   = styles.Bold
+
+	sectionStyle = styles.HeaderStyle
+	countStyle   = styles.Blurred
+)
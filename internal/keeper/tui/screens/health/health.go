@@ -0,0 +1,194 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/health"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const tableHeight = 12
+
+// Security dashboard: weak, reused, old and breached passwords and expired cards of storage
+type HealthScreen struct {
+	storage  storage.Storage
+	hibpPath string
+	maxAge   int
+
+	table  table.Model
+	report health.Report
+	note   string // why breach check was skipped
+}
+
+type HealthScreenMaker struct {
+	HIBPPath string // downloaded Have I Been Pwned passwords, empty disables breach check
+	MaxAge   int    // months after which password is old
+}
+
+func (m HealthScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewHealthScreen(msg.Storage, m.HIBPPath, m.MaxAge)
+}
+
+func NewHealthScreen(strg storage.Storage, hibpPath string, maxAge int) (*HealthScreen, error) {
+	scr := &HealthScreen{
+		storage:  strg,
+		hibpPath: hibpPath,
+		maxAge:   maxAge,
+		table:    prepareTable(),
+	}
+
+	if err := scr.check(); err != nil {
+		return nil, err
+	}
+
+	return scr, nil
+}
+
+func (s HealthScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *HealthScreen) Update(msg tea.Msg) tea.Cmd {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "e", "enter":
+			return s.handleEdit()
+		case "r":
+			if err := s.check(); err != nil {
+				return tui.ReportError(err)
+			}
+			return tui.ReportInfo("%s", s.report.Summary())
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+
+	return cmd
+}
+
+func (s HealthScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString("Use ↑↓ to navigate, (e)dit secret, (r)echeck, (b)ack\n\n")
+	b.WriteString(fmt.Sprintf("%d credentials and cards checked: ", s.report.Checked))
+	if len(s.report.Issues) == 0 {
+		b.WriteString(s.report.Summary())
+	} else {
+		b.WriteString(summaryStyle.Render(s.report.Summary()))
+	}
+	b.WriteString("\n")
+	if s.note != "" {
+		b.WriteString(noteStyle.Render(s.note))
+		b.WriteString("\n")
+	}
+	b.WriteString(tableStyle.Render(s.table.View()))
+
+	return screens.RenderContent(fmt.Sprintf("Health of %s", s.storage.String()), b.String())
+}
+
+func (s *HealthScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit secret")),
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "check again")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to list")),
+	}
+}
+
+// Run checks and fill table with found issues
+func (s *HealthScreen) check() error {
+	opts := health.DefaultOptions()
+	opts.MaxAge = s.maxAge
+
+	s.note = ""
+	switch {
+	case s.hibpPath == "":
+		s.note = "breach check is off, set GOPH_HIBP_PATH to downloaded HIBP passwords"
+	default:
+		hibp, err := health.OpenHIBP(s.hibpPath)
+		if err != nil {
+			s.note = fmt.Sprintf("breach check is off: %v", err)
+			break
+		}
+		defer hibp.Close()
+		opts.Breaches = hibp
+	}
+
+	report, err := health.Check(context.Background(), s.storage, opts, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to check storage: %w", err)
+	}
+
+	rows := make([]table.Row, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		rows = append(rows, table.Row{
+			strconv.FormatUint(issue.SecretID, 10),
+			issue.Title,
+			string(issue.Kind),
+			issue.Detail,
+		})
+	}
+
+	s.table.SetRows(rows)
+	s.report = report
+
+	return nil
+}
+
+func (s HealthScreen) handleEdit() tea.Cmd {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return tui.ReportInfo("%s", "no issues found")
+	}
+
+	id, err := strconv.ParseUint(row[0], 10, 64)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	secret, err := s.storage.Get(context.Background(), id)
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load secret: %w", err))
+	}
+
+	screen, err := screens.EditScreenFor(secret)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	return tui.SetBodyPane(screen, tui.WithSecret(secret), tui.WithStorage(s.storage))
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "id", Width: 5},
+		{Title: "Title", Width: 25},
+		{Title: "Issue", Width: 12},
+		{Title: "Detail", Width: 40},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(tableHeight),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = tableHeaderStyle
+	st.Selected = tableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
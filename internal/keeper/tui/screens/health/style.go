@@ -0,0 +1,27 @@
+package health
+
+import (
+	"gophkeeper/internal/keeper/tui/styles"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	tableStyle = styles.Border.BorderForeground(lipgloss.Color("240"))
+
+	tableSelectedStyle = styles.Regular.
+				Foreground(lipgloss.Color("229")).
+				Background(lipgloss.Color("57")).
+				Bold(false)
+
+	tableHeaderStyle = styles.Padded.
+				BorderStyle(lipgloss.NormalBorder()).
+				BorderForeground(lipgloss.Color("240")).
+				BorderBottom(true).
+				Bold(false)
+)
+
+var (
+	summaryStyle = styles.Regular.Foreground(styles.Red)
+	noteStyle    = styles.Blurred
+)
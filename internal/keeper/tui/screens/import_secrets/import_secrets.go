@@ -0,0 +1,197 @@
+package importsecrets
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/importer"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/components"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Number of parsed secrets listed in preview
+const previewLimit = 10
+
+const (
+	posPath = iota
+	posFormat
+	posPassword
+	posDuplicates
+)
+
+// Imports export of gophkeeper or another password manager into browsed storage
+type ImportScreen struct {
+	storage storage.Storage
+	imports *usecase.ImportSecretsUseCase
+
+	preview    *usecase.ImportReport
+	inputGroup components.InputGroup
+}
+
+func (s ImportScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewImportScreen(msg.Storage), nil
+}
+
+func NewImportScreen(store storage.Storage) *ImportScreen {
+	scr := &ImportScreen{
+		storage: store,
+		imports: usecase.NewImportSecretsUseCase(),
+	}
+
+	formats := []string{string(importer.FormatAuto)}
+	for _, f := range importer.Formats() {
+		formats = append(formats, string(f))
+	}
+
+	inputs := make([]textinput.Model, 4)
+	inputs[posPath] = newInput(inputOpts{placeholder: "Export file path", charLimit: 256, focus: true})
+	inputs[posFormat] = newInput(inputOpts{placeholder: "Format: " + strings.Join(formats, ", "), charLimit: 16, value: string(importer.FormatAuto)})
+	inputs[posPassword] = newInput(inputOpts{placeholder: "Password (gophkeeper archive)", charLimit: 64})
+	inputs[posPassword].EchoMode = textinput.EchoPassword
+	inputs[posDuplicates] = newInput(inputOpts{placeholder: "Duplicates: skip, overwrite or keep", charLimit: 16, value: string(usecase.DuplicateSkip)})
+
+	buttons := []components.Button{}
+	buttons = append(buttons, components.Button{Title: "[ Preview ]", Cmd: func() tea.Cmd {
+		return scr.Submit(true)
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Import ]", Cmd: func() tea.Cmd {
+		return scr.Submit(false)
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(scr.storage))
+	}})
+
+	scr.inputGroup = components.NewInputGroup(inputs, buttons)
+
+	return scr
+}
+
+func (s ImportScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+func (s *ImportScreen) Update(msg tea.Msg) tea.Cmd {
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	return cmd
+}
+
+func (s ImportScreen) View() string {
+	header := fmt.Sprintf("Import secrets to %s", s.storage.String())
+
+	content := s.inputGroup.View()
+	if s.preview != nil {
+		content += "\n\n" + renderPreview(s.preview)
+	}
+
+	return screens.RenderContent(header, content)
+}
+
+// Parse export and either show what would be imported or import it
+func (s *ImportScreen) Submit(dryRun bool) tea.Cmd {
+	path := strings.TrimSpace(s.inputGroup.Inputs[posPath].Value())
+	if path == "" {
+		return tui.ReportError(fmt.Errorf("please enter path of export file"))
+	}
+
+	policy, err := usecase.ParseDuplicatePolicy(s.inputGroup.Inputs[posDuplicates].Value())
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	report, err := s.imports.Call(context.Background(), path, s.storage, usecase.ImportOptions{
+		Format:     importer.Format(strings.ToLower(strings.TrimSpace(s.inputGroup.Inputs[posFormat].Value()))),
+		Password:   s.inputGroup.Inputs[posPassword].Value(),
+		DryRun:     dryRun,
+		Duplicates: policy,
+	})
+	if err != nil {
+		s.preview = nil
+		return tui.ReportError(err)
+	}
+
+	if dryRun {
+		s.preview = report
+		return tui.ReportInfo("%s", report)
+	}
+
+	for _, failure := range report.Failed {
+		log.Printf("import %q: %v", failure.Title, failure.Err)
+	}
+
+	cmds := []tea.Cmd{tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))}
+
+	if len(report.Failed) > 0 {
+		first := report.Failed[0]
+		cmds = append(cmds, tui.ReportError(fmt.Errorf("%s; %q: %w", report, first.Title, first.Err)))
+	} else {
+		cmds = append(cmds, tui.ReportInfo("%s", report))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// First parsed secrets and all entries which can not be imported
+func renderPreview(report *usecase.ImportReport) string {
+	var b strings.Builder
+
+	b.WriteString(styles.HeaderStyle.Render(fmt.Sprintf("Preview of %s export", report.Format)))
+	b.WriteString("\n")
+
+	for i, secret := range report.Parsed.Secrets {
+		if i == previewLimit {
+			b.WriteString(fmt.Sprintf("  ... and %d more\n", len(report.Parsed.Secrets)-previewLimit))
+			break
+		}
+
+		b.WriteString(fmt.Sprintf("  %-10s %s\n", secret.SecretType, secret.Title))
+	}
+
+	if len(report.Parsed.Skipped) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.HeaderStyle.Render("Unsupported"))
+		b.WriteString("\n")
+
+		for _, skipped := range report.Parsed.Skipped {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", skipped.Title, skipped.Reason))
+		}
+	}
+
+	return b.String()
+}
+
+type inputOpts struct {
+	placeholder string
+	charLimit   int
+	focus       bool
+	value       string
+}
+
+func newInput(opts inputOpts) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = opts.charLimit
+	t.Placeholder = opts.placeholder
+
+	if len(opts.value) > 0 {
+		t.SetValue(opts.value)
+	}
+
+	if opts.focus {
+		t.Focus()
+		t.PromptStyle = styles.Focused
+		t.TextStyle = styles.Focused
+	}
+
+	return t
+}
@@ -3,13 +3,15 @@ package login
 import (
 	"context"
 	"errors"
+	"fmt"
 	"gophkeeper/internal/keeper/api"
-	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
 	"gophkeeper/internal/keeper/tui/components"
 	"gophkeeper/internal/keeper/tui/screens"
 	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
@@ -18,6 +20,7 @@ import (
 var (
 	errLoginEmpty    = errors.New("please enter login")
 	errPasswordEmpty = errors.New("please enter password")
+	errCodeEmpty     = errors.New("please enter code")
 )
 
 const (
@@ -33,19 +36,27 @@ const (
 )
 
 type LoginScreen struct {
-	client    api.IApiClient
-	encrypter crypto.Encrypter
+	client     api.IApiClient
+	openRemote *usecase.OpenRemoteStoreUseCase
 
 	inputGroup components.InputGroup
+
+	codeGroup components.InputGroup // one-time code step, shown when askCode is set
+	askCode   bool
+}
+
+type LoginScreenMaker struct {
+	OpenRemote *usecase.OpenRemoteStoreUseCase
 }
 
-func (s LoginScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
-	return NewLoginScreen(msg.Client), nil
+func (m LoginScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewLoginScreen(msg.Client, m.OpenRemote), nil
 }
 
-func NewLoginScreen(client api.IApiClient) *LoginScreen {
+func NewLoginScreen(client api.IApiClient, openRemote *usecase.OpenRemoteStoreUseCase) *LoginScreen {
 	m := LoginScreen{
-		client: client,
+		client:     client,
+		openRemote: openRemote,
 	}
 
 	inputs := make([]textinput.Model, 2)
@@ -80,6 +91,13 @@ func (s *LoginScreen) Update(msg tea.Msg) tea.Cmd {
 		cmds []tea.Cmd
 	)
 
+	if s.askCode {
+		ig, cmd := s.codeGroup.Update(msg)
+		s.codeGroup = ig.(components.InputGroup)
+
+		return cmd
+	}
+
 	ig, cmd := s.inputGroup.Update(msg)
 	s.inputGroup = ig.(components.InputGroup)
 
@@ -113,26 +131,116 @@ func (s *LoginScreen) Submit(mode Mode) tea.Cmd {
 		token, err = s.client.Register(context.Background(), login, password)
 	}
 
+	if errors.Is(err, entities.ErrServerUnavailable) && mode == modeLogin {
+		return s.openOffline(login, password)
+	}
+
+	if errors.Is(err, entities.ErrTOTPRequired) {
+		return s.showCodeStep()
+	}
+
+	var locked *entities.LoginLockedError
+	if errors.As(err, &locked) {
+		return tui.ReportError(fmt.Errorf("%w (at %s)", err, locked.Until.Format("15:04:05")))
+	}
+
 	if err != nil {
 		cmds = append(cmds, tui.ReportError(err))
 	} else {
-		s.client.SetToken(token)
-		s.client.SetPassword(password)
-
-		// create storage instance
-		storage, err := storage.NewRemoteStorage(s.client, s.encrypter)
-		if err != nil {
-			cmds = append(cmds, tui.ReportError(err))
-		} else {
-			cmds = append(cmds, tui.ReportInfo("success!"))
-			cmds = append(cmds, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(storage)))
-		}
+		cmds = append(cmds, s.open(token))
 	}
 
 	return tea.Batch(cmds...)
 }
 
+// Password was accepted, account asks for code of authenticator app
+func (s *LoginScreen) showCodeStep() tea.Cmd {
+	inputs := []textinput.Model{newInput(inputOpts{placeholder: "Code or recovery code", charLimit: 32})}
+
+	buttons := []components.Button{
+		{Title: "[ Verify ]", Cmd: s.SubmitCode},
+		{Title: "[ Back ]", Cmd: func() tea.Cmd {
+			s.askCode = false
+			return nil
+		}},
+	}
+
+	s.codeGroup = components.NewInputGroup(inputs, buttons)
+	s.askCode = true
+
+	return tea.Batch(s.codeGroup.Init(), tui.ReportInfo("enter code from authenticator app"))
+}
+
+// Finish login with one-time code, wrong code may be entered again
+func (s *LoginScreen) SubmitCode() tea.Cmd {
+	code := s.codeGroup.Inputs[0].Value()
+	if len(code) == 0 {
+		return tui.ReportError(errCodeEmpty)
+	}
+
+	token, err := s.client.LoginTOTP(context.Background(), code)
+	if errors.Is(err, entities.ErrLoginExpired) {
+		s.askCode = false
+		return tui.ReportError(err)
+	}
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	s.askCode = false
+
+	return s.open(token)
+}
+
+// Open storage of logged in account
+func (s *LoginScreen) open(token string) tea.Cmd {
+	s.client.SetToken(token)
+
+	storage, err := s.openRemote.Call(s.client)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	return tea.Batch(
+		s.upgrade(storage),
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(storage)),
+	)
+}
+
+// Legacy account is switched to derived keys right after login, storage stays usable if that fails
+func (s *LoginScreen) upgrade(strg storage.Storage) tea.Cmd {
+	upgraded, err := s.openRemote.Upgrade(context.Background(), s.client, strg)
+	if errors.Is(err, entities.ErrNotSupported) {
+		return tui.ReportInfo("success!")
+	}
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("logged in, but failed to upgrade account: %w", err))
+	}
+	if upgraded {
+		return tui.ReportInfo("success! account upgraded, server no longer receives your password")
+	}
+
+	return tui.ReportInfo("success!")
+}
+
+// Server is down, work with local replica if there is one
+func (s *LoginScreen) openOffline(login string, password string) tea.Cmd {
+	storage, err := s.openRemote.CallOffline(s.client, login, password)
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	return tea.Batch(
+		tui.ReportInfo("server unavailable, working offline"),
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(storage)),
+	)
+}
+
 func (s LoginScreen) View() string {
+	if s.askCode {
+		return screens.RenderContent("Two-factor authentication is on, enter one-time code:", s.codeGroup.View())
+	}
+
 	return screens.RenderContent("Fill in credentials:", s.inputGroup.View())
 }
 
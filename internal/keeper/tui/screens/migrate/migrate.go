@@ -0,0 +1,253 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/api"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/components"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	errSameStorage  = errors.New("target is the storage being browsed")
+	errRemoteSource = errors.New("secrets are already in remote storage, enter path of local vault")
+	errLoginEmpty   = errors.New("please enter login")
+	errCodeRequired = errors.New("account requires one-time code, log in from login screen first")
+)
+
+const (
+	posPath = iota
+	posLogin
+	posPassword
+	posDuplicates
+)
+
+// Copies or moves secrets of browsed storage to local vault or remote account
+type MigrateScreen struct {
+	source storage.Storage
+	ids    []uint64 // selected secrets, all when empty
+
+	client     api.IApiClient
+	openRemote *usecase.OpenRemoteStoreUseCase
+	encrypter  crypto.Encrypter
+	options    []storage.LocalOption
+	migrate    *usecase.MigrateSecretsUseCase
+
+	inputGroup components.InputGroup
+}
+
+type MigrateScreenMaker struct {
+	Client     api.IApiClient
+	OpenRemote *usecase.OpenRemoteStoreUseCase
+	Encrypter  crypto.Encrypter
+	Options    []storage.LocalOption
+}
+
+func (m MigrateScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewMigrateScreen(msg.Storage, msg.SecretIDs, m.Client, m.OpenRemote, m.Encrypter, m.Options...), nil
+}
+
+func NewMigrateScreen(source storage.Storage, ids []uint64, client api.IApiClient, openRemote *usecase.OpenRemoteStoreUseCase, encrypter crypto.Encrypter, opts ...storage.LocalOption) *MigrateScreen {
+	scr := &MigrateScreen{
+		source:     source,
+		ids:        ids,
+		client:     client,
+		openRemote: openRemote,
+		encrypter:  encrypter,
+		options:    opts,
+		migrate:    usecase.NewMigrateSecretsUseCase(),
+	}
+
+	inputs := make([]textinput.Model, 4)
+	inputs[posPath] = newInput(inputOpts{placeholder: "Target vault path, empty for remote account", charLimit: 256})
+	inputs[posLogin] = newInput(inputOpts{placeholder: "Login (remote account)", charLimit: 64})
+	inputs[posPassword] = newInput(inputOpts{placeholder: "Password", charLimit: 64})
+	inputs[posPassword].EchoMode = textinput.EchoPassword
+	inputs[posDuplicates] = newInput(inputOpts{placeholder: "Duplicates: skip, overwrite or keep", charLimit: 16, value: string(usecase.DuplicateSkip)})
+
+	if client != nil {
+		inputs[posLogin].SetValue(client.GetLogin())
+	}
+
+	buttons := []components.Button{}
+	buttons = append(buttons, components.Button{Title: "[ Copy ]", Cmd: func() tea.Cmd {
+		return scr.Submit(false)
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Move ]", Cmd: func() tea.Cmd {
+		return scr.Submit(true)
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(scr.source))
+	}})
+
+	scr.inputGroup = components.NewInputGroup(inputs, buttons)
+
+	return scr
+}
+
+func (s MigrateScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+func (s *MigrateScreen) Update(msg tea.Msg) tea.Cmd {
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	return cmd
+}
+
+func (s MigrateScreen) View() string {
+	what := "all secrets"
+	if len(s.ids) > 0 {
+		what = fmt.Sprintf("%d selected secret(s)", len(s.ids))
+	}
+
+	header := fmt.Sprintf("Copy or move %s of %s to:", what, s.source.String())
+
+	return screens.RenderContent(header, s.inputGroup.View())
+}
+
+func (s *MigrateScreen) Submit(move bool) tea.Cmd {
+	policy, err := usecase.ParseDuplicatePolicy(s.inputGroup.Inputs[posDuplicates].Value())
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	target, err := s.openTarget()
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to open target: %w", err))
+	}
+
+	report, err := s.migrate.Call(context.Background(), s.source, target, usecase.MigrateOptions{
+		IDs:        s.ids,
+		Move:       move,
+		Duplicates: policy,
+	})
+
+	if cerr := target.Close(context.Background()); cerr != nil {
+		log.Printf("Submit(): failed to close target: %v", cerr)
+	}
+
+	if err != nil {
+		return tui.ReportError(err)
+	}
+
+	for _, failure := range report.Failed {
+		log.Printf("migrate %q (id=%d): %v", failure.Title, failure.ID, failure.Err)
+	}
+
+	cmds := []tea.Cmd{tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.source))}
+
+	if len(report.Failed) > 0 {
+		first := report.Failed[0]
+		cmds = append(cmds, tui.ReportError(fmt.Errorf("%s; %q: %w", report, first.Title, first.Err)))
+	} else {
+		cmds = append(cmds, tui.ReportInfo("%s", report))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+// Open existing vault or create new one at path, or log in to remote account when path is empty
+func (s *MigrateScreen) openTarget() (storage.Storage, error) {
+	path := s.inputGroup.Inputs[posPath].Value()
+	password := s.inputGroup.Inputs[posPassword].Value()
+
+	if path == "" {
+		return s.openRemoteTarget(password)
+	}
+
+	if len(password) == 0 {
+		return nil, entities.ErrEmptyPassword
+	}
+
+	if samePath(path, s.source.String()) {
+		return nil, errSameStorage
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return storage.OpenLocal(path, password, s.encrypter, s.options...)
+	}
+
+	return storage.NewLocal(storage.EngineVault, path, password, s.encrypter, s.options...)
+}
+
+func (s *MigrateScreen) openRemoteTarget(password string) (storage.Storage, error) {
+	if _, ok := s.source.(storage.QueuedStorage); ok {
+		return nil, errRemoteSource
+	}
+
+	login := s.inputGroup.Inputs[posLogin].Value()
+
+	// Already logged in as this user
+	if s.client.GetToken() != "" && s.client.GetLogin() == login && (password == "" || password == s.client.GetPassword()) {
+		return s.openRemote.Call(s.client)
+	}
+
+	if len(login) == 0 {
+		return nil, errLoginEmpty
+	}
+
+	if len(password) == 0 {
+		return nil, entities.ErrEmptyPassword
+	}
+
+	token, err := s.client.Login(context.Background(), login, password)
+	if errors.Is(err, entities.ErrTOTPRequired) {
+		return nil, errCodeRequired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.client.SetToken(token)
+
+	return s.openRemote.Call(s.client)
+}
+
+func samePath(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
+
+type inputOpts struct {
+	placeholder string
+	charLimit   int
+	focus       bool
+	value       string
+}
+
+func newInput(opts inputOpts) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = opts.charLimit
+	t.Placeholder = opts.placeholder
+
+	if len(opts.value) > 0 {
+		t.SetValue(opts.value)
+	}
+
+	if opts.focus {
+		t.Focus()
+		t.PromptStyle = styles.Focused
+		t.TextStyle = styles.Focused
+	}
+
+	return t
+}
@@ -2,32 +2,34 @@ package remoteeopen
 
 import (
 	"gophkeeper/internal/keeper/api"
-	"gophkeeper/internal/keeper/crypto"
-	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/usecase"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 type RemoteOpenScreen struct {
-	client api.IApiClient
+	client     api.IApiClient
+	openRemote *usecase.OpenRemoteStoreUseCase
 }
 
 type RemoteOpenScreenMaker struct {
-	Client api.IApiClient
+	Client     api.IApiClient
+	OpenRemote *usecase.OpenRemoteStoreUseCase
 }
 
 func (m RemoteOpenScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
-	return NewRemoteOpenScreen(m.Client), nil
+	return NewRemoteOpenScreen(m.Client, m.OpenRemote), nil
 }
 
 func (s RemoteOpenScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
-	return NewRemoteOpenScreen(msg.Client), nil
+	return NewRemoteOpenScreen(msg.Client, s.openRemote), nil
 }
 
-func NewRemoteOpenScreen(client api.IApiClient) *RemoteOpenScreen {
+func NewRemoteOpenScreen(client api.IApiClient, openRemote *usecase.OpenRemoteStoreUseCase) *RemoteOpenScreen {
 	return &RemoteOpenScreen{
-		client: client,
+		client:     client,
+		openRemote: openRemote,
 	}
 }
 
@@ -36,8 +38,7 @@ func (s RemoteOpenScreen) Init() tea.Cmd {
 
 	if len(s.client.GetToken()) > 0 {
 		// already authorized
-		encrypter := crypto.NewKeeperEncrypter()
-		strg, err := storage.NewRemoteStorage(s.client, encrypter)
+		strg, err := s.openRemote.Call(s.client)
 		if err != nil {
 			cmds = append(cmds, tui.ReportError(err))
 		} else {
@@ -0,0 +1,254 @@
+package secrethistory
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
+	"gophkeeper/pkg/models"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	tableHeight = 8
+
+	masked = "••••••"
+)
+
+// Restore of selected revision confirmed by user
+type confirmRestoreMsg struct {
+	revision uint64
+}
+
+// Lists previous versions of secret, shows what changed since each of them and restores them
+type HistoryScreen struct {
+	storage storage.Storage
+	keeper  storage.HistoryKeeper
+	current *models.Secret
+	restore *usecase.RestoreRevisionUseCase
+
+	table    table.Model
+	revision *models.Secret // selected revision with contents
+	changes  []usecase.FieldChange
+	reveal   bool // show sensitive values in diff
+}
+
+func (s HistoryScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewHistoryScreen(msg.Storage, msg.Secret)
+}
+
+func NewHistoryScreen(strg storage.Storage, secret *models.Secret) (*HistoryScreen, error) {
+	keeper, ok := strg.(storage.HistoryKeeper)
+	if !ok || secret == nil {
+		return nil, fmt.Errorf("failed to open history: %w", entities.ErrNotSupported)
+	}
+
+	scr := &HistoryScreen{
+		storage: strg,
+		keeper:  keeper,
+		current: secret,
+		restore: usecase.NewRestoreRevisionUseCase(),
+		table:   prepareTable(),
+	}
+
+	if err := scr.updateRows(); err != nil {
+		return nil, fmt.Errorf("failed to load history: %w", err)
+	}
+
+	return scr, nil
+}
+
+func (s HistoryScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *HistoryScreen) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case confirmRestoreMsg: // msg from restore confirmation
+		return s.restoreRevision(msg.revision)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "enter": // show changes since selected revision
+			cmds = append(cmds, s.handleSelect())
+		case "r":
+			cmds = append(cmds, s.handleRestore())
+		case "v": // show or mask sensitive values
+			s.reveal = !s.reveal
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return tea.Batch(cmds...)
+}
+
+func (s HistoryScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString("Use ↑↓ to navigate, enter to compare with current version, (r)estore, (v)iew hidden values, (b)ack\n")
+	b.WriteString(tableStyle.Render(s.table.View()))
+
+	if s.revision != nil {
+		b.WriteString("\n\n")
+		b.WriteString(s.renderChanges())
+	}
+
+	return screens.RenderContent(fmt.Sprintf("History of %q", s.current.Title), b.String())
+}
+
+func (s *HistoryScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "compare with current")),
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore revision")),
+		key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "show hidden values")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to list")),
+	}
+}
+
+func (s *HistoryScreen) updateRows() error {
+	versions, err := s.keeper.History(context.Background(), s.current.ID)
+	if err != nil {
+		return err
+	}
+
+	rows := make([]table.Row, 0, len(versions))
+	for _, v := range versions {
+		rows = append(rows, table.Row{
+			strconv.FormatUint(v.Revision, 10),
+			v.Title,
+			v.UpdatedAt.Local().Format("02 Jan 06 15:04"),
+		})
+	}
+
+	s.table.SetRows(rows)
+
+	return nil
+}
+
+// Load contents of revision under cursor and compare it with current version
+func (s *HistoryScreen) handleSelect() tea.Cmd {
+	revision, ok := s.selectedRevision()
+	if !ok {
+		return tui.ReportInfo("%s", "no previous versions")
+	}
+
+	old, err := s.keeper.Revision(context.Background(), s.current.ID, revision)
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load revision %d: %w", revision, err))
+	}
+
+	s.revision = old
+	s.changes = usecase.DiffSecrets(old, s.current)
+
+	return nil
+}
+
+func (s *HistoryScreen) handleRestore() tea.Cmd {
+	revision, ok := s.selectedRevision()
+	if !ok {
+		return tui.ReportInfo("%s", "no previous versions")
+	}
+
+	prompt := fmt.Sprintf("Restore revision %d of %q? Current version stays in history.", revision, s.current.Title)
+
+	return tui.YesNoPrompt(prompt, func() tea.Msg {
+		return confirmRestoreMsg{revision: revision}
+	})
+}
+
+func (s *HistoryScreen) restoreRevision(revision uint64) tea.Cmd {
+	_, err := s.restore.Call(context.Background(), s.storage, s.current.ID, revision)
+	if err != nil {
+		return screens.AfterSave(s.storage, s.current.ID, err)
+	}
+
+	return tea.Batch(
+		tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)),
+		tui.ReportInfo("revision %d restored", revision),
+	)
+}
+
+func (s HistoryScreen) selectedRevision() (uint64, bool) {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return 0, false
+	}
+
+	revision, err := strconv.ParseUint(row[0], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return revision, true
+}
+
+// Changed fields, old value in red, current in green
+func (s HistoryScreen) renderChanges() string {
+	var b strings.Builder
+
+	b.WriteString(styles.HeaderStyle.Render(fmt.Sprintf("Changes since revision %d", s.revision.Revision)))
+	b.WriteString("\n")
+
+	if len(s.changes) == 0 {
+		b.WriteString("  same as current version\n")
+		return b.String()
+	}
+
+	for _, c := range s.changes {
+		o, n := c.Old, c.New
+		if c.Sensitive && !s.reveal {
+			o, n = mask(o), mask(n)
+		}
+
+		b.WriteString(fmt.Sprintf("  %s:\n", c.Field))
+		b.WriteString("    " + oldStyle.Render("- "+o) + "\n")
+		b.WriteString("    " + newStyle.Render("+ "+n) + "\n")
+	}
+
+	return b.String()
+}
+
+func mask(value string) string {
+	if value == "" {
+		return ""
+	}
+
+	return masked
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "Revision", Width: 10},
+		{Title: "Title", Width: 30},
+		{Title: "Saved", Width: 20},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(tableHeight),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = tableHeaderStyle
+	st.Selected = tableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
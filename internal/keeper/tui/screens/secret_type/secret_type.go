@@ -19,6 +19,8 @@ const (
 	selectText
 	selectCard
 	selectBlob
+	selectTOTP
+	selectSSHKey
 )
 
 // Model which renders selection list of different commands
@@ -49,6 +51,8 @@ func (s *SecretTypeScreen) prepareSecretListModel() {
 		selectText:       "Add text",
 		selectCard:       "Add card info",
 		selectBlob:       "Upload file",
+		selectTOTP:       "Add TOTP (2FA codes)",
+		selectSSHKey:     "Add SSH key",
 	}
 
 	keys := slices.Collect(maps.Keys(choices))
@@ -135,6 +139,22 @@ func (s *SecretTypeScreen) Update(msg tea.Msg) tea.Cmd {
 					tui.WithStorage(s.storage),
 					tui.WithSecret(sec),
 				)
+			case selectTOTP:
+				sec := models.NewSecret(models.TOTPSecret)
+
+				cmd = tui.SetBodyPane(
+					tui.TOTPEditScreen,
+					tui.WithStorage(s.storage),
+					tui.WithSecret(sec),
+				)
+			case selectSSHKey:
+				sec := models.NewSecret(models.SSHKeySecret)
+
+				cmd = tui.SetBodyPane(
+					tui.SSHKeyEditScreen,
+					tui.WithStorage(s.storage),
+					tui.WithSecret(sec),
+				)
 			}
 
 			cmds = append(cmds, cmd)
@@ -0,0 +1,228 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	tableHeight = 10
+	timeFormat  = "02 Jan 06 15:04"
+)
+
+// Ending session of another device confirmed by user
+type confirmRevokeMsg struct {
+	id uint64
+}
+
+// Logout confirmed by user
+type confirmLogoutMsg struct{}
+
+// Lists devices logged in to account, ends their sessions
+type SessionsScreen struct {
+	storage storage.Storage
+	keeper  storage.SessionKeeper
+
+	table   table.Model
+	current uint64 // session of this device
+	count   int
+}
+
+func (s SessionsScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewSessionsScreen(msg.Storage)
+}
+
+func NewSessionsScreen(strg storage.Storage) (*SessionsScreen, error) {
+	keeper, ok := strg.(storage.SessionKeeper)
+	if !ok {
+		return nil, fmt.Errorf("failed to list devices: %w", entities.ErrNotSupported)
+	}
+
+	scr := &SessionsScreen{
+		storage: strg,
+		keeper:  keeper,
+		table:   prepareTable(),
+	}
+
+	if err := scr.updateRows(); err != nil {
+		return nil, fmt.Errorf("failed to list devices: %w", err)
+	}
+
+	return scr, nil
+}
+
+func (s SessionsScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *SessionsScreen) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case confirmRevokeMsg: // msg from revoke confirmation
+		return s.revoke(msg.id)
+	case confirmLogoutMsg: // msg from logout confirmation
+		return s.logout()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "d":
+			return s.handleRevoke()
+		case "l":
+			return s.handleLogout()
+		case "t":
+			return tui.SetBodyPane(tui.TwoFactorScreen, tui.WithStorage(s.storage))
+		case "u":
+			if err := s.updateRows(); err != nil {
+				return tui.ReportError(fmt.Errorf("failed to list devices: %w", err))
+			}
+			return nil
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return tea.Batch(cmds...)
+}
+
+func (s SessionsScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString("Use ↑↓ to navigate, (d)isconnect device, (l)og out this device, (t)wo-factor auth, (u)pdate, (b)ack\n")
+	b.WriteString(tableStyle.Render(s.table.View()))
+
+	return screens.RenderContent(fmt.Sprintf("Devices logged in to %s (%d)", s.storage.String(), s.count), b.String())
+}
+
+func (s *SessionsScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "disconnect device")),
+		key.NewBinding(key.WithKeys("l"), key.WithHelp("l", "log out this device")),
+		key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "two-factor authentication")),
+		key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "update list")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to list")),
+	}
+}
+
+func (s *SessionsScreen) updateRows() error {
+	sessions, err := s.keeper.Sessions(context.Background())
+	if err != nil {
+		return err
+	}
+
+	rows := make([]table.Row, 0, len(sessions))
+	for _, sess := range sessions {
+		device := sess.Device
+		if sess.Current {
+			device += " (this device)"
+			s.current = sess.ID
+		}
+
+		rows = append(rows, table.Row{
+			strconv.FormatUint(sess.ID, 10),
+			device,
+			sess.Address,
+			sess.LastSeenAt.Local().Format(timeFormat),
+			sess.CreatedAt.Local().Format(timeFormat),
+		})
+	}
+
+	s.table.SetRows(rows)
+	s.count = len(rows)
+
+	return nil
+}
+
+func (s *SessionsScreen) handleRevoke() tea.Cmd {
+	id, device, ok := s.selected()
+	if !ok {
+		return tui.ReportInfo("%s", "no devices logged in")
+	}
+	if id == s.current {
+		return tui.ReportInfo("%s", "this is the current device, (l)og out instead")
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Disconnect %q? It will have to log in again.", device), func() tea.Msg {
+		return confirmRevokeMsg{id: id}
+	})
+}
+
+func (s *SessionsScreen) revoke(id uint64) tea.Cmd {
+	err := s.keeper.RevokeSession(context.Background(), id)
+	if err != nil && !errors.Is(err, entities.ErrSessionNotFound) {
+		return tui.ReportError(fmt.Errorf("failed to disconnect device: %w", err))
+	}
+
+	if err := s.updateRows(); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to list devices: %w", err))
+	}
+
+	return tui.ReportInfo("%s", "device disconnected")
+}
+
+func (s *SessionsScreen) handleLogout() tea.Cmd {
+	return tui.YesNoPrompt("Log out this device? Queued changes stay on disk till next login.", func() tea.Msg {
+		return confirmLogoutMsg{}
+	})
+}
+
+func (s *SessionsScreen) logout() tea.Cmd {
+	if err := s.keeper.Logout(context.Background()); err != nil {
+		return tea.Batch(tui.GoToStart(), tui.ReportError(fmt.Errorf("failed to log out: %w", err)))
+	}
+
+	return tea.Batch(tui.GoToStart(), tui.ReportInfo("%s", "logged out"))
+}
+
+// ID and device name of session under cursor
+func (s SessionsScreen) selected() (uint64, string, bool) {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return 0, "", false
+	}
+
+	id, err := strconv.ParseUint(row[0], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return id, row[1], true
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "id", Width: 5},
+		{Title: "Device", Width: 35},
+		{Title: "Address", Width: 22},
+		{Title: "Last seen", Width: 16},
+		{Title: "Logged in", Width: 16},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(tableHeight),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = tableHeaderStyle
+	st.Selected = tableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
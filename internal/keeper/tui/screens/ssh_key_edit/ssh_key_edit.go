@@ -0,0 +1,258 @@
+package sshkeyedit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/sshkey"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/components"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/pkg/models"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	errTitleEmpty = errors.New("please enter title")
+
+	errorStyle = styles.Regular.Foreground(styles.Red)
+)
+
+const (
+	sshTitle = iota
+	sshMetadata
+	sshPrivateKey
+	sshPassphrase
+	sshComment
+)
+
+type SSHKeyEditScreen struct {
+	secret  *models.Secret
+	storage storage.Storage
+
+	// Private key of secret or generated one, replaced by value of key input when it is filled
+	privateKey string
+	err        error
+
+	// Description of key, parsing of encrypted keys is slow so it is redone on change only
+	info      string
+	infoInput string
+
+	inputGroup components.InputGroup
+}
+
+func (s SSHKeyEditScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewSSHKeyEditScreen(msg.Secret, msg.Storage), nil
+}
+
+func NewSSHKeyEditScreen(secret *models.Secret, strg storage.Storage) *SSHKeyEditScreen {
+	m := SSHKeyEditScreen{
+		secret:  secret,
+		storage: strg,
+	}
+
+	inputs := make([]textinput.Model, 5)
+	inputs[sshTitle] = newInput(inputOpts{placeholder: "Title", charLimit: 64})
+	inputs[sshMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
+	inputs[sshPrivateKey] = newInput(inputOpts{placeholder: "Private key, paste or path to key file", charLimit: 16384})
+	inputs[sshPassphrase] = newInput(inputOpts{placeholder: "Passphrase", charLimit: 128})
+	inputs[sshPassphrase].EchoMode = textinput.EchoPassword
+	inputs[sshComment] = newInput(inputOpts{placeholder: "Comment", charLimit: 128})
+
+	buttons := []components.Button{}
+	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
+		return screens.AfterSave(m.storage, m.secret.ID, m.Submit())
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Generate ed25519 ]", Cmd: func() tea.Cmd {
+		m.generate(sshkey.Ed25519)
+		return nil
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Generate RSA ]", Cmd: func() tea.Cmd {
+		m.generate(sshkey.RSA)
+		return nil
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+	}})
+
+	if secret.ID > 0 {
+		inputs[sshTitle].SetValue(secret.Title)
+		inputs[sshMetadata].SetValue(secret.Metadata)
+		inputs[sshPassphrase].SetValue(secret.SSH.Passphrase)
+		inputs[sshComment].SetValue(secret.SSH.Comment)
+		m.privateKey = secret.SSH.PrivateKey
+	}
+
+	m.inputGroup = components.NewInputGroup(inputs, buttons)
+	m.refreshInfo()
+
+	return &m
+}
+
+func (s SSHKeyEditScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+func (s *SSHKeyEditScreen) Update(msg tea.Msg) tea.Cmd {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	cmds = append(cmds, cmd)
+
+	s.refreshInfo()
+
+	return tea.Batch(cmds...)
+}
+
+// Replace key with new unencrypted one, typed key and passphrase are dropped
+func (s *SSHKeyEditScreen) generate(t sshkey.KeyType) {
+	key, err := sshkey.Generate(t, s.value(sshComment))
+	if err != nil {
+		s.err = err
+		return
+	}
+
+	s.privateKey = key
+	s.err = nil
+	s.inputGroup.Inputs[sshPrivateKey].SetValue("")
+	s.inputGroup.Inputs[sshPassphrase].SetValue("")
+}
+
+func (s *SSHKeyEditScreen) Submit() error {
+	title := s.value(sshTitle)
+	if title == "" {
+		return errTitleEmpty
+	}
+
+	privateKey, err := s.key()
+	if err != nil {
+		return err
+	}
+
+	passphrase := s.inputGroup.Inputs[sshPassphrase].Value()
+	comment := s.value(sshComment)
+
+	info, err := sshkey.Inspect(privateKey, passphrase, comment)
+	if err != nil {
+		return err
+	}
+
+	s.secret.Title = title
+	s.secret.Metadata = s.inputGroup.Inputs[sshMetadata].Value()
+	s.secret.SSH = &models.SSHKey{
+		PrivateKey: privateKey,
+		Passphrase: passphrase,
+		PublicKey:  info.PublicKey,
+		Comment:    comment,
+	}
+	s.secret.UpdatedAt = time.Now()
+
+	// Save secret
+	if s.secret.ID == 0 {
+		s.secret.CreatedAt = time.Now()
+		err = s.storage.Create(context.Background(), s.secret)
+	} else {
+		err = s.storage.Update(context.Background(), s.secret)
+	}
+
+	return err
+}
+
+// Typed key when present, otherwise kept or generated one
+func (s SSHKeyEditScreen) key() (string, error) {
+	if value := s.value(sshPrivateKey); value != "" {
+		return sshkey.FromInput(value)
+	}
+
+	if s.privateKey == "" {
+		return "", sshkey.ErrNoKey
+	}
+
+	return s.privateKey, nil
+}
+
+func (s SSHKeyEditScreen) value(i int) string {
+	return strings.TrimSpace(s.inputGroup.Inputs[i].Value())
+}
+
+func (s SSHKeyEditScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString(s.inputGroup.View())
+	b.WriteString("\n" + s.info + "\n")
+
+	return screens.RenderContent("Fill in SSH key details, paste key or generate new one:", b.String())
+}
+
+func (s *SSHKeyEditScreen) refreshInfo() {
+	input := strings.Join([]string{
+		s.privateKey,
+		s.inputGroup.Inputs[sshPrivateKey].Value(),
+		s.inputGroup.Inputs[sshPassphrase].Value(),
+		s.inputGroup.Inputs[sshComment].Value(),
+		fmt.Sprint(s.err),
+	}, "\x00")
+
+	if input == s.infoInput && s.info != "" {
+		return
+	}
+
+	s.info = s.describe()
+	s.infoInput = input
+}
+
+// Public key and fingerprint of current key or the reason they can't be shown
+func (s SSHKeyEditScreen) describe() string {
+	if s.err != nil {
+		return errorStyle.Render(s.err.Error())
+	}
+
+	key, err := s.key()
+	if errors.Is(err, sshkey.ErrNoKey) {
+		return styles.Blurred.Render("No key yet")
+	}
+	if err != nil {
+		return errorStyle.Render(err.Error())
+	}
+
+	info, err := sshkey.Inspect(key, s.inputGroup.Inputs[sshPassphrase].Value(), s.value(sshComment))
+	if err != nil {
+		return errorStyle.Render(err.Error())
+	}
+
+	return fmt.Sprintf("Fingerprint: %s\n%s", info.Fingerprint, info.PublicKey)
+}
+
+type inputOpts struct {
+	placeholder string
+	charLimit   int
+	focus       bool
+}
+
+func newInput(opts inputOpts) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = opts.charLimit
+	t.Placeholder = opts.placeholder
+
+	if opts.focus {
+		t.Focus()
+		t.PromptStyle = styles.Focused
+		t.TextStyle = styles.Focused
+	}
+
+	return t
+}
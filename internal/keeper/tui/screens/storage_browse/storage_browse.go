@@ -3,18 +3,28 @@ package storagebrowse
 import (
 	"context"
 	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/search"
+	"gophkeeper/internal/keeper/sshagent"
+	"gophkeeper/internal/keeper/sshkey"
 	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
 	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/internal/keeper/usecase"
 	"gophkeeper/pkg/models"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 )
 
@@ -27,9 +37,68 @@ type savePathMsg = struct {
 	secret *models.Secret
 }
 
+type discardFailedMsg struct{}
+
+// Permanent deletion confirmed by user, for storages without trash
+type confirmDeleteMsg struct {
+	id uint64
+}
+
+// Folder entered for marked secrets
+type moveSecretsMsg struct {
+	ids    []uint64
+	folder string
+}
+
+// Tags entered for marked secrets
+type tagSecretsMsg struct {
+	ids  []uint64
+	tags string
+}
+
+// Once a second refresh of TOTP code, id tells tick chains of rebuilt screens apart
+type totpTickMsg struct {
+	id int64
+}
+
+var lastTickID atomic.Int64
+
+type changePasswordMsg struct {
+	oldPassword string
+	newPassword string
+	repeated    string
+}
+
 type StorageBrowseScreen struct {
-	storage storage.Storage
-	table   table.Model
+	storage  storage.Storage
+	table    table.Model
+	status   string           // sync status of queued storage, refreshed with rows
+	selected map[uint64]bool  // secrets marked for migration
+	filter   tui.SecretFilter // folder and tag picked in folder tree
+	organize *usecase.OrganizeSecretsUseCase
+
+	search    textinput.Model // query typed after "/"
+	searching bool            // keys go to search input
+	query     *search.Query   // last valid query, nil when empty
+	queryErr  error           // parse error of search input
+
+	tickID int64  // id of own TOTP ticks
+	code   string // TOTP code or SSH key fingerprint of secret under cursor, empty for other secrets
+
+	agentSocket string // path of ssh-agent socket
+}
+
+type StorageBrowseScreenMaker struct {
+	AgentSocket string
+}
+
+func (m StorageBrowseScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	scr := NewStorageBrowseScreenScreen(msg.Storage)
+	if m.AgentSocket != "" {
+		scr.agentSocket = m.AgentSocket
+	}
+
+	return scr, nil
 }
 
 func (s StorageBrowseScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
@@ -38,8 +107,13 @@ func (s StorageBrowseScreen) Make(msg tui.NavigationMsg, width, height int) (tui
 
 func NewStorageBrowseScreenScreen(strg storage.Storage) *StorageBrowseScreen {
 	scr := &StorageBrowseScreen{
-		storage: strg,
-		table:   prepareTable(),
+		storage:     strg,
+		table:       prepareTable(),
+		selected:    make(map[uint64]bool),
+		organize:    usecase.NewOrganizeSecretsUseCase(),
+		search:      newSearchInput(),
+		tickID:      lastTickID.Add(1),
+		agentSocket: sshagent.DefaultSocket(),
 	}
 
 	scr.updateRows()
@@ -47,9 +121,14 @@ func NewStorageBrowseScreenScreen(strg storage.Storage) *StorageBrowseScreen {
 	return scr
 }
 
+// Show folder tree of storage in left pane, it replies with current filter
 func (s StorageBrowseScreen) Init() tea.Cmd {
 	s.updateRows()
-	return nil
+	return tea.Batch(
+		tui.NavigateTo(tui.FolderScreen, tui.WithPosition(tui.LeftPane), tui.WithStorage(s.storage), tui.DisableFocus()),
+		tui.CmdHandler(tui.StorageShownMsg{Storage: s.storage}),
+		s.tick(),
+	)
 }
 
 func (s *StorageBrowseScreen) Update(msg tea.Msg) tea.Cmd {
@@ -61,6 +140,22 @@ func (s *StorageBrowseScreen) Update(msg tea.Msg) tea.Cmd {
 	switch msg := msg.(type) {
 	case tui.ReloadSecretList:
 		s.updateRows()
+	case totpTickMsg:
+		if msg.id != s.tickID {
+			return nil // tick of screen made before this one
+		}
+		s.refreshCode()
+		return s.tick()
+	case tui.SecretFilterMsg: // msg from folder tree
+		if msg.Storage == s.storage {
+			s.filter = msg.Filter
+			s.updateRows()
+			s.table.SetCursor(0)
+		}
+	case moveSecretsMsg: // msg from folder prompt
+		cmds = append(cmds, s.moveSecrets(msg))
+	case tagSecretsMsg: // msg from tags prompt
+		cmds = append(cmds, s.tagSecrets(msg))
 	case savePathMsg: // msg from prompt for blob-secret copy-hotkey
 		err := os.WriteFile(msg.path, msg.secret.Blob.FileBytes, 0644)
 		if err != nil {
@@ -68,24 +163,62 @@ func (s *StorageBrowseScreen) Update(msg tea.Msg) tea.Cmd {
 		} else {
 			cmds = append(cmds, infoCmd("file saved successfully"))
 		}
+	case changePasswordMsg: // msg from password prompts
+		cmds = append(cmds, s.changePassword(msg))
+	case discardFailedMsg: // msg from discard confirmation
+		cmds = append(cmds, s.discardFailed())
+	case confirmDeleteMsg: // msg from delete confirmation
+		cmds = append(cmds, s.deleteSecret(msg.id))
 	case tea.WindowSizeMsg:
 		s.table.SetWidth(min(msg.Width, s.colsWidth()))
 		s.table.SetHeight(msg.Height - tableBorderSize)
 	case tea.KeyMsg:
+		if s.searching {
+			return s.handleSearchKey(msg)
+		}
+
 		switch msg.String() {
+		case "/": // fuzzy search
+			return s.startSearch()
 		case "a": // add
 			cmd = tui.SetBodyPane(tui.SecretTypeScreen, tui.WithStorage(s.storage))
 			cmds = append(cmds, cmd)
 		case "e", "enter": // edit
 			cmds = append(cmds, s.handleEdit())
-		case "c":
+		case "c": // copy, current code for TOTP
 			cmds = append(cmds, s.handleCopy())
+		case "h": // previous versions
+			cmds = append(cmds, s.handleHistory())
+		case "p": // change password
+			cmds = append(cmds, s.handleChangePassword())
+		case "s": // sync queued changes
+			cmds = append(cmds, s.handleSync())
+		case "x": // discard failed changes
+			cmds = append(cmds, s.handleDiscardFailed())
+		case " ": // mark for migration, table would page down on space
+			s.handleSelect()
+			s.table.MoveDown(1)
+			return tea.Batch(cmds...)
+		case "m": // copy or move to another storage
+			cmds = append(cmds, s.handleMigrate())
+		case "i": // import export of another password manager
+			cmds = append(cmds, tui.SetBodyPane(tui.ImportScreen, tui.WithStorage(s.storage)))
+		case "o": // export to file
+			cmds = append(cmds, tui.SetBodyPane(tui.ExportScreen, tui.WithStorage(s.storage), tui.WithSecretIDs(s.selectedIDs())))
 		case "d": // delete
 			cmds = append(cmds, s.handleDelete())
-
-			// update table
-			s.updateRows()
-			cmds = append(cmds, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage)))
+		case "r": // security report
+			cmds = append(cmds, tui.SetBodyPane(tui.HealthScreen, tui.WithStorage(s.storage)))
+		case "t": // deleted secrets
+			cmds = append(cmds, s.handleTrash())
+		case "f": // move to folder
+			cmds = append(cmds, s.handleMove())
+		case "g": // edit tags
+			cmds = append(cmds, s.handleTags())
+		case "v": // devices logged in to account
+			cmds = append(cmds, s.handleSessions())
+		case "A": // start or stop ssh-agent
+			cmds = append(cmds, s.handleAgent())
 		}
 	}
 
@@ -93,39 +226,172 @@ func (s *StorageBrowseScreen) Update(msg tea.Msg) tea.Cmd {
 	s.table, cmd = s.table.Update(msg)
 	cmds = append(cmds, cmd)
 
+	s.refreshCode()
+
 	return tea.Batch(cmds...)
 }
 
 func (s StorageBrowseScreen) View() string {
 	var b strings.Builder
 
-	b.WriteString(fmt.Sprintf("Operating storage %s\n", styles.Highlighted.Render(s.storage.String())))
-	b.WriteString("Use ↑↓ to navigate, (a)dd, (e)dit, (d)elete, (c)opy\n")
+	name := s.storage.String()
+	if ro, ok := s.storage.(storage.ReadOnlyStorage); ok && ro.ReadOnly() {
+		name += " (read-only)"
+	}
+
+	b.WriteString(fmt.Sprintf("Operating storage %s", styles.Highlighted.Render(name)))
+	if s.status != "" {
+		b.WriteString(" " + s.status)
+	}
+	if sshagent.Serving() == s.storage {
+		b.WriteString(" [ssh-agent]")
+	}
+	if filter := filterTitle(s.filter); filter != "" {
+		b.WriteString(" in " + styles.Highlighted.Render(filter))
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Use ↑↓ to navigate, / to search, (a)dd, (e)dit, (d)elete, (t)rash, health (r)eport, (c)opy, (h)istory, (f)older, ta(g)s, change (p)assword, space to select, (m)igrate, (i)mport, exp(o)rt, ssh-(A)gent")
+	if _, ok := s.storage.(storage.QueuedStorage); ok {
+		b.WriteString(", (s)ync, discard failed (x)")
+	}
+	if _, ok := s.storage.(storage.SessionKeeper); ok {
+		b.WriteString(", de(v)ices")
+	}
+	b.WriteString("\n")
+	if s.code != "" {
+		b.WriteString(s.code + "\n")
+	}
+	if s.searching || s.search.Value() != "" {
+		b.WriteString(s.search.View())
+		if s.queryErr != nil {
+			b.WriteString(" " + errorStyle.Render(s.queryErr.Error()))
+		}
+		b.WriteString("\n")
+	}
 	b.WriteString(tableStyle.Render(s.table.View()))
 
 	return screenStyle.Render(b.String())
 }
 
 func (s *StorageBrowseScreen) HelpBindings() []key.Binding {
+	if s.searching {
+		return []key.Binding{
+			key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "finish search, empty to clear")),
+			key.NewBinding(key.WithKeys("up", "down"), key.WithHelp("↑↓", "navigate results")),
+		}
+	}
+
 	return []key.Binding{
+		key.NewBinding(key.WithKeys("/"), key.WithHelp("/", "search, e.g. mail type:cred updated:<30d")),
 		key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "add secret")),
 		key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit secret")),
 		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete secret")),
+		key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "open trash")),
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "weak, reused and breached passwords")),
 		key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "copy/save secret")),
+		key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "secret history")),
+		key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "move to folder")),
+		key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "edit tags")),
+		key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "change password")),
+		key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sync queued changes")),
+		key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "discard failed changes")),
+		key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select secret")),
+		key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "copy/move to another storage")),
+		key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "import from other manager")),
+		key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "export to file")),
+		key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "start/stop ssh-agent with keys of storage")),
+		key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "devices logged in, logout")),
 	}
 }
 
+// Connection and outbox summary, e.g. "[offline, 2 pending, 1 failed]"
+func queueStatus(queued storage.QueuedStorage) string {
+	parts := []string{"online"}
+	if !queued.Online() {
+		parts[0] = "offline"
+	}
+
+	var pending, failed int
+	for _, op := range queued.Outbox() {
+		switch op.State {
+		case storage.OpPending:
+			pending++
+		case storage.OpFailed:
+			failed++
+		}
+	}
+
+	if pending > 0 {
+		parts = append(parts, fmt.Sprintf("%d pending", pending))
+	}
+	if failed > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", failed))
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// Delivery state of secrets with queued changes
+func queuedStates(strg storage.Storage) map[uint64]storage.OpState {
+	queued, ok := strg.(storage.QueuedStorage)
+	if !ok {
+		return nil
+	}
+
+	states := make(map[uint64]storage.OpState)
+	for _, op := range queued.Outbox() {
+		if states[op.SecretID] != storage.OpFailed {
+			states[op.SecretID] = op.State
+		}
+	}
+
+	return states
+}
+
+// Folder and tag of filter, e.g. "work/mail #urgent"
+func filterTitle(filter tui.SecretFilter) string {
+	var parts []string
+	if filter.Folder != "" {
+		parts = append(parts, filter.Folder)
+	}
+	if filter.Tag != "" {
+		parts = append(parts, "#"+filter.Tag)
+	}
+
+	return strings.Join(parts, " ")
+}
+
 func (s *StorageBrowseScreen) updateRows() {
 	secrets, _ := s.storage.GetAll(context.Background())
 
+	secrets = usecase.FilterSecrets(secrets, s.filter.Folder, s.filter.Tag)
 	sortSecrets(secrets)
+	if s.query != nil {
+		secrets = s.query.Filter(secrets, time.Now())
+	}
+
+	states := queuedStates(s.storage)
+	if queued, ok := s.storage.(storage.QueuedStorage); ok {
+		s.status = queueStatus(queued)
+	}
 
 	rows := []table.Row{}
 	for _, sec := range secrets {
+		title := sec.Title
+		if state, ok := states[sec.ID]; ok {
+			title = fmt.Sprintf("%s (%s)", title, state)
+		}
+		if s.selected[sec.ID] {
+			title = "* " + title
+		}
+
 		rows = append(rows, table.Row{
-			strconv.Itoa(int(sec.ID)),
-			sec.Title,
+			strconv.FormatUint(sec.ID, 10),
+			title,
 			sec.SecretType,
+			sec.Folder,
+			sec.Tags.String(),
 			sec.CreatedAt.Format("02 Jan 06 15:04"),
 			sec.UpdatedAt.Format("02 Jan 06 15:04"),
 		})
@@ -134,13 +400,125 @@ func (s *StorageBrowseScreen) updateRows() {
 	s.table.SetRows(rows)
 }
 
+// Focus search input, keeping previous query for refinement
+func (s *StorageBrowseScreen) startSearch() tea.Cmd {
+	s.searching = true
+	s.search.CursorEnd()
+
+	return s.search.Focus()
+}
+
+// Type into search input, filtering table on every change
+func (s *StorageBrowseScreen) handleSearchKey(msg tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+
+	switch msg.String() {
+	case "enter":
+		s.searching = false
+		s.search.Blur()
+		return nil
+	case "up", "down":
+		s.table, cmd = s.table.Update(msg)
+		return cmd
+	}
+
+	prev := s.search.Value()
+	s.search, cmd = s.search.Update(msg)
+	if s.search.Value() == prev {
+		return cmd
+	}
+
+	query, err := search.Parse(s.search.Value())
+	s.queryErr = err
+	if err != nil {
+		return cmd // keep results of last valid query while filter is being typed
+	}
+
+	s.query = query
+	if query.Empty() {
+		s.query = nil
+	}
+
+	s.updateRows()
+	s.table.SetCursor(0)
+
+	return cmd
+}
+
+func newSearchInput() textinput.Model {
+	t := textinput.New()
+	t.Prompt = "/ "
+	t.Placeholder = "title, login, file name, type:card, tag:mail, updated:<30d"
+	t.PromptStyle = styles.Focused
+	t.TextStyle = styles.Focused
+
+	return t
+}
+
+func (s StorageBrowseScreen) tick() tea.Cmd {
+	id := s.tickID
+	return tea.Every(time.Second, func(time.Time) tea.Msg { return totpTickMsg{id: id} })
+}
+
+// Show code of TOTP secret under cursor with seconds left, e.g. "GitHub: 123 456 (17s)",
+// or fingerprint of SSH key, e.g. "Server: ssh-ed25519 SHA256:..."
+func (s *StorageBrowseScreen) refreshCode() {
+	s.code = ""
+
+	row := s.table.SelectedRow()
+	if row == nil || (row[2] != string(models.TOTPSecret) && row[2] != string(models.SSHKeySecret)) {
+		return
+	}
+
+	secret, err := s.loadSecret(row[0])
+	if err != nil {
+		return
+	}
+
+	if secret.SSH != nil {
+		s.code = sshKeyInfo(secret)
+		return
+	}
+
+	if secret.TOTP == nil {
+		return
+	}
+
+	now := time.Now()
+	code, err := secret.TOTP.Code(now)
+	if err != nil {
+		s.code = errorStyle.Render(fmt.Sprintf("%s: %s", secret.Title, err))
+		return
+	}
+
+	left := secret.TOTP.Remaining(now).Round(time.Second)
+	s.code = fmt.Sprintf("%s: %s (%ds), (c)opy", secret.Title, styles.Highlighted.Render(groupDigits(code)), int(left.Seconds()))
+}
+
+func sshKeyInfo(secret *models.Secret) string {
+	pub, err := sshkey.ParsePublic(secret.SSH.PublicKey)
+	if err != nil {
+		return errorStyle.Render(fmt.Sprintf("%s: %s", secret.Title, err))
+	}
+
+	info := sshkey.Describe(pub, secret.SSH.Comment)
+
+	return fmt.Sprintf("%s: %s %s, (c)opy public key", secret.Title, info.Type, styles.Highlighted.Render(info.Fingerprint))
+}
+
+// Split code in halves for reading, "123456" to "123 456"
+func groupDigits(code string) string {
+	half := len(code) / 2
+	return code[:half] + " " + code[half:]
+}
+
 func (s StorageBrowseScreen) handleEdit() tea.Cmd {
 	secret, err := s.getSelectedSecret()
 	if err != nil {
 		return errCmd("failed to load secret: %w", err)
 	}
 
-	screen, err := s.getScreenForSecret(secret)
+	screen, err := screens.EditScreenFor(secret)
 	if err != nil {
 		return errCmd("failed to get screen: %w", err)
 	}
@@ -159,27 +537,307 @@ func (s StorageBrowseScreen) handleCopy() tea.Cmd {
 		return tui.StringPrompt("choose path to save", func(str string) tea.Cmd { return func() tea.Msg { return savePathMsg{path: str, secret: secret} } })
 	}
 
+	if secret.SecretType == string(models.TOTPSecret) {
+		if _, err := secret.TOTP.Code(time.Now()); err != nil {
+			return errCmd("failed to generate code", err)
+		}
+	}
+
 	if err := clipboard.WriteAll(secret.ToClipboard()); err != nil {
 		return errCmd("failed to copy to clipboard: %w", err)
 	}
 
+	if secret.SecretType == string(models.TOTPSecret) {
+		return infoCmd("code copied successfully")
+	}
+
+	if secret.SecretType == string(models.SSHKeySecret) {
+		return infoCmd("public key copied successfully")
+	}
+
 	return infoCmd("secret copied successfully")
 }
 
-func (s StorageBrowseScreen) handleDelete() tea.Cmd {
+// Serve SSH keys of storage to ssh clients, or stop serving them
+func (s StorageBrowseScreen) handleAgent() tea.Cmd {
+	if sshagent.Serving() == s.storage {
+		if err := sshagent.Stop(); err != nil {
+			return errCmd("failed to stop ssh-agent", err)
+		}
+
+		return infoCmd("ssh-agent stopped")
+	}
+
+	srv, err := sshagent.Start(s.storage, s.agentSocket)
+	if err != nil {
+		return errCmd("failed to start ssh-agent", err)
+	}
+
+	return infoCmd(fmt.Sprintf("ssh-agent listening, export SSH_AUTH_SOCK=%s", srv.Path()))
+}
+
+func (s StorageBrowseScreen) handleHistory() tea.Cmd {
+	if _, ok := s.storage.(storage.HistoryKeeper); !ok {
+		return errCmd("failed to open history", entities.ErrNotSupported)
+	}
+
 	secret, err := s.getSelectedSecret()
 	if err != nil {
 		return errCmd("failed to load secret", err)
 	}
 
-	err = s.storage.Delete(context.Background(), secret.ID)
+	return tui.SetBodyPane(tui.HistoryScreen, tui.WithSecret(secret), tui.WithStorage(s.storage))
+}
+
+// Move secret to trash right away, storages without trash ask for confirmation first
+func (s *StorageBrowseScreen) handleDelete() tea.Cmd {
+	secret, err := s.getSelectedSecret()
+	if err != nil {
+		return errCmd("failed to load secret", err)
+	}
+
+	if _, ok := s.storage.(storage.TrashKeeper); ok {
+		return s.deleteSecret(secret.ID)
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Delete %q permanently? This can not be undone.", secret.Title), func() tea.Msg {
+		return confirmDeleteMsg{id: secret.ID}
+	})
+}
+
+func (s *StorageBrowseScreen) deleteSecret(id uint64) tea.Cmd {
+	err := s.storage.Delete(context.Background(), id)
+	delete(s.selected, id)
+	s.updateRows()
+
 	if err != nil {
 		return errCmd("failed to delete secret", err)
 	}
 
+	if _, ok := s.storage.(storage.TrashKeeper); ok {
+		return infoCmd("secret moved to trash, (t) to open trash")
+	}
+
 	return infoCmd("secret deleted")
 }
 
+func (s StorageBrowseScreen) handleTrash() tea.Cmd {
+	if _, ok := s.storage.(storage.TrashKeeper); !ok {
+		return errCmd("failed to open trash", entities.ErrNotSupported)
+	}
+
+	return tui.SetBodyPane(tui.TrashScreen, tui.WithStorage(s.storage))
+}
+
+func (s StorageBrowseScreen) handleSessions() tea.Cmd {
+	if _, ok := s.storage.(storage.SessionKeeper); !ok {
+		return errCmd("failed to list devices", entities.ErrNotSupported)
+	}
+
+	return tui.SetBodyPane(tui.SessionsScreen, tui.WithStorage(s.storage))
+}
+
+// Ask folder for marked secrets, or the one under cursor when none marked
+func (s StorageBrowseScreen) handleMove() tea.Cmd {
+	ids, current, err := s.organized(func(sec *models.Secret) string { return sec.Folder })
+	if err != nil {
+		return errCmd("failed to load secret", err)
+	}
+
+	return tui.EditPrompt(fmt.Sprintf("folder for %d secret(s), empty for root", len(ids)), current, func(folder string) tea.Cmd {
+		return func() tea.Msg { return moveSecretsMsg{ids: ids, folder: folder} }
+	})
+}
+
+func (s *StorageBrowseScreen) moveSecrets(msg moveSecretsMsg) tea.Cmd {
+	moved, err := s.organize.Move(context.Background(), s.storage, msg.ids, msg.folder)
+	if err != nil {
+		return tea.Batch(errCmd("failed to move secrets", err), tui.CmdHandler(tui.ReloadSecretList{}))
+	}
+
+	return tea.Batch(infoCmd(fmt.Sprintf("%d secret(s) moved", moved)), tui.CmdHandler(tui.ReloadSecretList{}))
+}
+
+// Ask tags for marked secrets, or the one under cursor when none marked
+func (s StorageBrowseScreen) handleTags() tea.Cmd {
+	ids, current, err := s.organized(func(sec *models.Secret) string { return sec.Tags.String() })
+	if err != nil {
+		return errCmd("failed to load secret", err)
+	}
+
+	return tui.EditPrompt(fmt.Sprintf("comma separated tags for %d secret(s)", len(ids)), current, func(tags string) tea.Cmd {
+		return func() tea.Msg { return tagSecretsMsg{ids: ids, tags: tags} }
+	})
+}
+
+func (s *StorageBrowseScreen) tagSecrets(msg tagSecretsMsg) tea.Cmd {
+	changed, err := s.organize.SetTags(context.Background(), s.storage, msg.ids, models.ParseTags(msg.tags))
+	if err != nil {
+		return tea.Batch(errCmd("failed to tag secrets", err), tui.CmdHandler(tui.ReloadSecretList{}))
+	}
+
+	return tea.Batch(infoCmd(fmt.Sprintf("%d secret(s) tagged", changed)), tui.CmdHandler(tui.ReloadSecretList{}))
+}
+
+// Secrets to organize and prompt value taken from the first of them
+func (s StorageBrowseScreen) organized(value func(sec *models.Secret) string) ([]uint64, string, error) {
+	ids := s.selectedIDs()
+	if len(ids) == 0 {
+		secret, err := s.getSelectedSecret()
+		if err != nil {
+			return nil, "", err
+		}
+
+		return []uint64{secret.ID}, value(secret), nil
+	}
+
+	secret, err := s.storage.Get(context.Background(), ids[0])
+	if err != nil {
+		return nil, "", err
+	}
+
+	return ids, value(secret), nil
+}
+
+func (s StorageBrowseScreen) handleChangePassword() tea.Cmd {
+	if _, ok := s.storage.(storage.PasswordChanger); !ok {
+		return errCmd("failed to change password", entities.ErrNotSupported)
+	}
+
+	return tui.PasswordPrompt("current password", func(oldPassword string) tea.Cmd {
+		return tui.PasswordPrompt("new password", func(newPassword string) tea.Cmd {
+			return tui.PasswordPrompt("repeat new password", func(repeated string) tea.Cmd {
+				return func() tea.Msg {
+					return changePasswordMsg{oldPassword: oldPassword, newPassword: newPassword, repeated: repeated}
+				}
+			})
+		})
+	})
+}
+
+func (s StorageBrowseScreen) changePassword(msg changePasswordMsg) tea.Cmd {
+	changer, ok := s.storage.(storage.PasswordChanger)
+	if !ok {
+		return errCmd("failed to change password", entities.ErrNotSupported)
+	}
+
+	if msg.newPassword != msg.repeated {
+		return errCmd("failed to change password", entities.ErrPasswordMismatch)
+	}
+
+	err := changer.ChangePassword(context.Background(), msg.oldPassword, msg.newPassword)
+	if err != nil {
+		return errCmd("failed to change password", err)
+	}
+
+	return infoCmd("password changed")
+}
+
+func (s *StorageBrowseScreen) handleSync() tea.Cmd {
+	queued, ok := s.storage.(storage.QueuedStorage)
+	if !ok {
+		return errCmd("failed to sync", entities.ErrNotSupported)
+	}
+
+	err := queued.RetryFailed(context.Background())
+	s.updateRows()
+
+	if err != nil {
+		return errCmd("failed to sync", err)
+	}
+
+	for _, op := range queued.Outbox() {
+		if op.State != storage.OpFailed {
+			continue
+		}
+
+		// Changed on another device while we were offline
+		if op.Theirs != nil {
+			return screens.ResolveConflict(s.storage, op.SecretID, &entities.ConflictError{Theirs: op.Theirs})
+		}
+
+		return errCmd("failed to sync", fmt.Errorf("%s of secret %d: %s", op.Kind, op.SecretID, op.Error))
+	}
+
+	return infoCmd("synced")
+}
+
+func (s StorageBrowseScreen) handleDiscardFailed() tea.Cmd {
+	queued, ok := s.storage.(storage.QueuedStorage)
+	if !ok {
+		return errCmd("failed to discard changes", entities.ErrNotSupported)
+	}
+
+	failed := 0
+	for _, op := range queued.Outbox() {
+		if op.State == storage.OpFailed {
+			failed++
+		}
+	}
+
+	if failed == 0 {
+		return infoCmd("no failed changes")
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Discard %d failed change(s)?", failed), func() tea.Msg {
+		return discardFailedMsg{}
+	})
+}
+
+func (s *StorageBrowseScreen) discardFailed() tea.Cmd {
+	queued, ok := s.storage.(storage.QueuedStorage)
+	if !ok {
+		return errCmd("failed to discard changes", entities.ErrNotSupported)
+	}
+
+	err := queued.DiscardFailed(context.Background())
+	s.updateRows()
+
+	if err != nil {
+		return errCmd("failed to discard changes", err)
+	}
+
+	return infoCmd("failed changes discarded")
+}
+
+// Toggle mark of secret under cursor
+func (s *StorageBrowseScreen) handleSelect() {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return
+	}
+
+	id, err := strconv.ParseUint(row[0], 10, 64)
+	if err != nil {
+		return
+	}
+
+	if s.selected[id] {
+		delete(s.selected, id)
+	} else {
+		s.selected[id] = true
+	}
+
+	s.updateRows()
+}
+
+// Open migration of marked secrets, or all secrets when none marked
+func (s StorageBrowseScreen) handleMigrate() tea.Cmd {
+	return tui.SetBodyPane(tui.MigrateScreen, tui.WithStorage(s.storage), tui.WithSecretIDs(s.selectedIDs()))
+}
+
+// IDs of marked secrets in ascending order
+func (s StorageBrowseScreen) selectedIDs() []uint64 {
+	ids := make([]uint64, 0, len(s.selected))
+	for id := range s.selected {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+
+	return ids
+}
+
 func errCmd(msg string, err error) tea.Cmd {
 	return tui.ReportError(fmt.Errorf("%s: %w", msg, err))
 }
@@ -215,21 +873,6 @@ func (s StorageBrowseScreen) loadSecret(rawID string) (*models.Secret, error) {
 	return sec, err
 }
 
-func (s StorageBrowseScreen) getScreenForSecret(secret *models.Secret) (tui.Screen, error) {
-	switch secret.SecretType {
-	case string(models.CredSecret):
-		return tui.CredentialEditScreen, nil
-	case string(models.TextSecret):
-		return tui.TextEditScreen, nil
-	case string(models.BlobSecret):
-		return tui.BlobEditScreen, nil
-	case string(models.CardSecret):
-		return tui.CardEditScreen, nil
-	default:
-		return -1, fmt.Errorf("unknown secret type")
-	}
-}
-
 func (s StorageBrowseScreen) colsWidth() int {
 	cols := s.table.Columns()
 	total := tableBorderSize
@@ -250,7 +893,9 @@ func prepareTable() table.Model {
 	columns := []table.Column{
 		{Title: "id", Width: 5},
 		{Title: "Title", Width: 20},
-		{Title: "SecretType", Width: 20},
+		{Title: "SecretType", Width: 12},
+		{Title: "Folder", Width: 15},
+		{Title: "Tags", Width: 15},
 		{Title: "Created", Width: 20},
 		{Title: "Updated", Width: 20},
 	}
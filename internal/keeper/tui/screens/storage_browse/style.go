@@ -9,6 +9,7 @@ import (
 var (
 	screenStyle = styles.Regular.PaddingLeft(2)
 	tableStyle  = styles.Border.BorderForeground(lipgloss.Color("240"))
+	errorStyle  = styles.Regular.Foreground(styles.Red)
 
 	tableSelectedStyle = styles.Regular.
 				Foreground(lipgloss.Color("229")).
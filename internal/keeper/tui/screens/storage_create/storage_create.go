@@ -18,6 +18,7 @@ import (
 const (
 	createPath = iota
 	createPassword
+	createEngine
 )
 
 type storageCreatedMsg struct{}
@@ -28,17 +29,23 @@ type StorageCreateScreen struct {
 	pathInput     textinput.Model
 	passwordInput textinput.Model
 
-	storage   storage.Storage
-	encrypter crypto.Encrypter
+	storage     storage.Storage
+	encrypter   crypto.Encrypter
+	storageOpts []storage.LocalOption
 
 	createStorageUC *usecase.CreateLocalStoreUseCase
 }
 
-func (s StorageCreateScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
-	return NewStorageCreateScreen()
+type StorageCreateScreenMaker struct {
+	Encrypter crypto.Encrypter
+	Options   []storage.LocalOption
 }
 
-func NewStorageCreateScreen() (*StorageCreateScreen, error) {
+func (m StorageCreateScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewStorageCreateScreen(m.Encrypter, m.Options...)
+}
+
+func NewStorageCreateScreen(encrypter crypto.Encrypter, opts ...storage.LocalOption) (*StorageCreateScreen, error) {
 	var err error
 
 	hdir, err := os.UserHomeDir()
@@ -47,13 +54,15 @@ func NewStorageCreateScreen() (*StorageCreateScreen, error) {
 	}
 
 	scr := &StorageCreateScreen{
-		encrypter:       crypto.NewKeeperEncrypter(),
+		encrypter:       encrypter,
+		storageOpts:     opts,
 		createStorageUC: usecase.NewCreateStorageUsecase(),
 	}
 
-	inputs := make([]textinput.Model, 2)
+	inputs := make([]textinput.Model, 3)
 	inputs[createPath] = newInput(inputOpts{placeholder: "Path to store", charLimit: 256, value: fmt.Sprintf("%s/%s", hdir, "secret.db")})
 	inputs[createPassword] = newInput(inputOpts{placeholder: "Password", charLimit: 64})
+	inputs[createEngine] = newInput(inputOpts{placeholder: "Engine: vault or journal (for large storages)", charLimit: 16, value: string(storage.EngineVault)})
 
 	buttons := []components.Button{}
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
@@ -98,10 +107,11 @@ func (s *StorageCreateScreen) Submit() tea.Cmd {
 
 	path := s.inputGroup.Inputs[createPath].Value()
 	password := s.inputGroup.Inputs[createPassword].Value()
+	engine := storage.Engine(s.inputGroup.Inputs[createEngine].Value())
 
 	// TODO: validate inputs
 
-	s.storage, err = s.createStorageUC.Call(path, password, s.encrypter)
+	s.storage, err = s.createStorageUC.Call(engine, path, password, s.encrypter, s.storageOpts...)
 	if err != nil {
 		cmds = append(cmds, tui.ReportInfo("Error: %v", err))
 	} else {
@@ -1,14 +1,17 @@
 package storageopen
 
 import (
+	"errors"
 	"fmt"
 	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
 	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
 	"gophkeeper/internal/keeper/tui/screens"
 	"gophkeeper/internal/keeper/tui/styles"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/filepicker"
@@ -20,17 +23,33 @@ type passwordProvidedMsg struct {
 	password string
 }
 
+type restoreBackupMsg struct {
+	path     string
+	password string
+}
+
+type openReadOnlyMsg struct {
+	path     string
+	password string
+}
+
 type StorageOpenScreen struct {
-	filePicker filepicker.Model
-	encrypter  crypto.Encrypter
-	selected   string
+	filePicker  filepicker.Model
+	encrypter   crypto.Encrypter
+	storageOpts []storage.LocalOption
+	selected    string
+}
+
+type StorageOpenScreenMaker struct {
+	Encrypter crypto.Encrypter
+	Options   []storage.LocalOption
 }
 
-func (s StorageOpenScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
-	return NewStorageOpenScreen(), nil
+func (m StorageOpenScreenMaker) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewStorageOpenScreen(m.Encrypter, m.Options...), nil
 }
 
-func NewStorageOpenScreen() *StorageOpenScreen {
+func NewStorageOpenScreen(encrypter crypto.Encrypter, opts ...storage.LocalOption) *StorageOpenScreen {
 	defaultPath, err := os.UserHomeDir()
 	if err != nil {
 		panic("Error getting working directory: %v\n")
@@ -42,8 +61,9 @@ func NewStorageOpenScreen() *StorageOpenScreen {
 	fp.Height = 10 // default height
 
 	return &StorageOpenScreen{
-		filePicker: fp,
-		encrypter:  crypto.NewKeeperEncrypter(),
+		filePicker:  fp,
+		encrypter:   encrypter,
+		storageOpts: opts,
 	}
 }
 
@@ -59,13 +79,38 @@ func (s *StorageOpenScreen) Update(msg tea.Msg) tea.Cmd {
 
 	switch msg := msg.(type) {
 	case passwordProvidedMsg: // msg from prompt for password
-		strg, err := storage.NewFileStorage(msg.path, msg.password, s.encrypter)
-		if err != nil {
+		strg, err := storage.OpenLocal(msg.path, msg.password, s.encrypter, s.storageOpts...)
+		switch {
+		case errors.Is(err, entities.ErrVaultInUse):
 			cmds = append(cmds, tui.ReportError(err))
-		} else {
+			cmds = append(cmds, tui.YesNoPrompt("Storage is in use by another process, open read-only?", func() tea.Msg {
+				return openReadOnlyMsg{path: msg.path, password: msg.password}
+			}))
+		case err != nil:
+			cmds = append(cmds, tui.ReportError(err))
+			cmds = append(cmds, s.offerBackup(msg.path, msg.password, err))
+		default:
 			cmd = tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(strg))
 			cmds = append(cmds, cmd)
 		}
+	case openReadOnlyMsg: // msg from prompt for read-only open
+		opts := append(slices.Clone(s.storageOpts), storage.WithReadOnly())
+
+		strg, err := storage.OpenLocal(msg.path, msg.password, s.encrypter, opts...)
+		if err != nil {
+			cmds = append(cmds, tui.ReportError(err))
+		} else {
+			cmds = append(cmds, tui.ReportInfo("Opened read-only: %v", msg.path))
+			cmds = append(cmds, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(strg)))
+		}
+	case restoreBackupMsg: // msg from prompt for backup restore
+		strg, backup, err := storage.RestoreFromBackup(msg.path, msg.password, s.encrypter, s.storageOpts...)
+		if err != nil {
+			cmds = append(cmds, tui.ReportError(err))
+		} else {
+			cmds = append(cmds, tui.ReportInfo("Restored from backup: %v", backup))
+			cmds = append(cmds, tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(strg)))
+		}
 	default:
 		s.filePicker, cmd = s.filePicker.Update(msg)
 		cmds = append(cmds, cmd)
@@ -91,6 +136,26 @@ func (s *StorageOpenScreen) Update(msg tea.Msg) tea.Cmd {
 	return tea.Batch(cmds...)
 }
 
+// Ask to open vault from backup, if there are any and vault itself is damaged.
+// Wrong password is never a reason to roll back.
+func (s StorageOpenScreen) offerBackup(path, password string, openErr error) tea.Cmd {
+	damaged := errors.Is(openErr, entities.ErrBadEncryption) ||
+		errors.Is(openErr, entities.ErrBadVaultFormat) ||
+		errors.Is(openErr, entities.ErrVaultCorrupted)
+	if !damaged {
+		return nil
+	}
+
+	backups, err := storage.ListBackups(path)
+	if err != nil || len(backups) == 0 {
+		return nil
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Failed to open storage, open from backup (%d found)?", len(backups)), func() tea.Msg {
+		return restoreBackupMsg{path: path, password: password}
+	})
+}
+
 func (s StorageOpenScreen) View() string {
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("%20s%s:\n", "", s.filePicker.CurrentDirectory))
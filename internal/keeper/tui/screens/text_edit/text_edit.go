@@ -51,13 +51,7 @@ func NewTextEditScreen(secret *models.Secret, strg storage.Storage) *TextEditScr
 
 	buttons := []components.Button{}
 	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
-		err := m.Submit()
-		if err != nil {
-			return tui.ReportError(err)
-		} else {
-			// todo: invalidate or update cache ?
-			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
-		}
+		return screens.AfterSave(m.storage, m.secret.ID, m.Submit())
 	}})
 
 	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
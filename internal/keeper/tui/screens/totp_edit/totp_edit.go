@@ -0,0 +1,215 @@
+package totpedit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/components"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/internal/keeper/tui/styles"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/totp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+var (
+	errTitleEmpty  = errors.New("please enter title")
+	errSecretEmpty = errors.New("please enter secret or otpauth:// URI")
+)
+
+const (
+	totpTitle = iota
+	totpMetadata
+	totpSecret
+	totpIssuer
+	totpAccount
+	totpAlgorithm
+	totpDigits
+	totpPeriod
+)
+
+type TOTPEditScreen struct {
+	secret  *models.Secret
+	storage storage.Storage
+
+	inputGroup components.InputGroup
+}
+
+func (s TOTPEditScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewTOTPEditScreen(msg.Secret, msg.Storage), nil
+}
+
+func NewTOTPEditScreen(secret *models.Secret, strg storage.Storage) *TOTPEditScreen {
+	m := TOTPEditScreen{
+		secret:  secret,
+		storage: strg,
+	}
+
+	inputs := make([]textinput.Model, 8)
+	inputs[totpTitle] = newInput(inputOpts{placeholder: "Title, issuer when empty", charLimit: 64})
+	inputs[totpMetadata] = newInput(inputOpts{placeholder: "Metadata", charLimit: 64})
+	inputs[totpSecret] = newInput(inputOpts{placeholder: "Base32 secret or otpauth://totp/... URI", charLimit: 512})
+	inputs[totpIssuer] = newInput(inputOpts{placeholder: "Issuer", charLimit: 64})
+	inputs[totpAccount] = newInput(inputOpts{placeholder: "Account", charLimit: 64})
+	inputs[totpAlgorithm] = newInput(inputOpts{placeholder: "Algorithm: SHA1, SHA256 or SHA512 (SHA1)", charLimit: 6})
+	inputs[totpDigits] = newInput(inputOpts{placeholder: "Digits: 6 or 8 (6)", charLimit: 1})
+	inputs[totpPeriod] = newInput(inputOpts{placeholder: "Period, seconds (30)", charLimit: 4})
+
+	buttons := []components.Button{}
+	buttons = append(buttons, components.Button{Title: "[ Submit ]", Cmd: func() tea.Cmd {
+		return screens.AfterSave(m.storage, m.secret.ID, m.Submit())
+	}})
+
+	buttons = append(buttons, components.Button{Title: "[ Back ]", Cmd: func() tea.Cmd {
+		return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(m.storage))
+	}})
+
+	if secret.ID > 0 {
+		inputs[totpTitle].SetValue(secret.Title)
+		inputs[totpMetadata].SetValue(secret.Metadata)
+		inputs[totpSecret].SetValue(secret.TOTP.Secret)
+		inputs[totpIssuer].SetValue(secret.TOTP.Issuer)
+		inputs[totpAccount].SetValue(secret.TOTP.Account)
+		inputs[totpAlgorithm].SetValue(secret.TOTP.Algorithm)
+		if secret.TOTP.Digits > 0 {
+			inputs[totpDigits].SetValue(strconv.Itoa(secret.TOTP.Digits))
+		}
+		if secret.TOTP.Period > 0 {
+			inputs[totpPeriod].SetValue(strconv.Itoa(secret.TOTP.Period))
+		}
+	}
+
+	m.inputGroup = components.NewInputGroup(inputs, buttons)
+
+	return &m
+}
+
+func (s TOTPEditScreen) Init() tea.Cmd {
+	return s.inputGroup.Init()
+}
+
+func (s *TOTPEditScreen) Update(msg tea.Msg) tea.Cmd {
+	var (
+		cmd  tea.Cmd
+		cmds []tea.Cmd
+	)
+
+	ig, cmd := s.inputGroup.Update(msg)
+	s.inputGroup = ig.(components.InputGroup)
+
+	cmds = append(cmds, cmd)
+
+	return tea.Batch(cmds...)
+}
+
+func (s *TOTPEditScreen) Submit() error {
+	key, err := s.key()
+	if err != nil {
+		return err
+	}
+
+	title := s.inputGroup.Inputs[totpTitle].Value()
+	if title == "" {
+		title = key.Issuer
+	}
+	if title == "" {
+		return errTitleEmpty
+	}
+
+	s.secret.Title = title
+	s.secret.Metadata = s.inputGroup.Inputs[totpMetadata].Value()
+	s.secret.TOTP = models.NewTOTP(key)
+	s.secret.UpdatedAt = time.Now()
+
+	// Save secret
+	if s.secret.ID == 0 {
+		s.secret.CreatedAt = time.Now()
+		err = s.storage.Create(context.Background(), s.secret)
+	} else {
+		err = s.storage.Update(context.Background(), s.secret)
+	}
+
+	return err
+}
+
+// Key from otpauth:// URI, filling in issuer and account typed separately, or from fields
+func (s TOTPEditScreen) key() (totp.Key, error) {
+	value := func(i int) string { return strings.TrimSpace(s.inputGroup.Inputs[i].Value()) }
+
+	secret := value(totpSecret)
+	if secret == "" {
+		return totp.Key{}, errSecretEmpty
+	}
+
+	if strings.HasPrefix(secret, "otpauth:") {
+		key, err := totp.ParseURI(secret)
+		if err != nil {
+			return totp.Key{}, err
+		}
+
+		if issuer := value(totpIssuer); issuer != "" {
+			key.Issuer = issuer
+		}
+		if account := value(totpAccount); account != "" {
+			key.Account = account
+		}
+
+		return key, nil
+	}
+
+	key := totp.Key{
+		Secret:  strings.ToUpper(strings.Join(strings.Fields(secret), "")),
+		Issuer:  value(totpIssuer),
+		Account: value(totpAccount),
+		Params:  totp.Params{Algorithm: totp.Algorithm(strings.ToUpper(value(totpAlgorithm)))},
+	}
+
+	var err error
+	if digits := value(totpDigits); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return totp.Key{}, totp.ErrBadDigits
+		}
+	}
+	if period := value(totpPeriod); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return totp.Key{}, totp.ErrBadPeriod
+		}
+	}
+
+	if err := key.Validate(); err != nil {
+		return totp.Key{}, fmt.Errorf("invalid TOTP: %w", err)
+	}
+
+	return key, nil
+}
+
+func (s TOTPEditScreen) View() string {
+	return screens.RenderContent("Fill in TOTP details or paste otpauth:// URI:", s.inputGroup.View())
+}
+
+type inputOpts struct {
+	placeholder string
+	charLimit   int
+	focus       bool
+}
+
+func newInput(opts inputOpts) textinput.Model {
+	t := textinput.New()
+	t.CharLimit = opts.charLimit
+	t.Placeholder = opts.placeholder
+
+	if opts.focus {
+		t.Focus()
+		t.PromptStyle = styles.Focused
+		t.TextStyle = styles.Focused
+	}
+
+	return t
+}
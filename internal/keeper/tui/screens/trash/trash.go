@@ -0,0 +1,245 @@
+package trash
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const tableHeight = 10
+
+// Permanent deletion of secret confirmed by user
+type confirmPurgeMsg struct {
+	id uint64
+}
+
+// Emptying trash confirmed by user
+type confirmEmptyMsg struct{}
+
+// Lists deleted secrets, restores them or deletes them for good
+type TrashScreen struct {
+	storage storage.Storage
+	keeper  storage.TrashKeeper
+
+	table table.Model
+	count int
+}
+
+func (s TrashScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewTrashScreen(msg.Storage)
+}
+
+func NewTrashScreen(strg storage.Storage) (*TrashScreen, error) {
+	keeper, ok := strg.(storage.TrashKeeper)
+	if !ok {
+		return nil, fmt.Errorf("failed to open trash: %w", entities.ErrNotSupported)
+	}
+
+	scr := &TrashScreen{
+		storage: strg,
+		keeper:  keeper,
+		table:   prepareTable(),
+	}
+
+	if err := scr.updateRows(); err != nil {
+		return nil, fmt.Errorf("failed to load trash: %w", err)
+	}
+
+	return scr, nil
+}
+
+func (s TrashScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *TrashScreen) Update(msg tea.Msg) tea.Cmd {
+	var cmds []tea.Cmd
+
+	switch msg := msg.(type) {
+	case confirmPurgeMsg: // msg from delete confirmation
+		return s.purge(msg.id)
+	case confirmEmptyMsg: // msg from empty trash confirmation
+		return s.empty()
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "r":
+			return s.handleRestore()
+		case "d":
+			return s.handlePurge()
+		case "x":
+			return s.handleEmpty()
+		case "b":
+			return tui.SetBodyPane(tui.StorageBrowseScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	var cmd tea.Cmd
+	s.table.Focus()
+	s.table, cmd = s.table.Update(msg)
+	cmds = append(cmds, cmd)
+
+	return tea.Batch(cmds...)
+}
+
+func (s TrashScreen) View() string {
+	var b strings.Builder
+
+	b.WriteString("Use ↑↓ to navigate, (r)estore, (d)elete permanently, empty trash (x), (b)ack\n")
+	b.WriteString(tableStyle.Render(s.table.View()))
+
+	return screens.RenderContent(fmt.Sprintf("Trash of %s (%d)", s.storage.String(), s.count), b.String())
+}
+
+func (s *TrashScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("r"), key.WithHelp("r", "restore secret")),
+		key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete permanently")),
+		key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "empty trash")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to list")),
+	}
+}
+
+func (s *TrashScreen) updateRows() error {
+	secrets, err := s.keeper.Trashed(context.Background())
+	if err != nil {
+		return err
+	}
+
+	rows := make([]table.Row, 0, len(secrets))
+	for _, sec := range secrets {
+		deleted := ""
+		if sec.DeletedAt != nil {
+			deleted = sec.DeletedAt.Local().Format("02 Jan 06 15:04")
+		}
+
+		rows = append(rows, table.Row{
+			strconv.FormatUint(sec.ID, 10),
+			sec.Title,
+			sec.SecretType,
+			deleted,
+		})
+	}
+
+	s.table.SetRows(rows)
+	s.count = len(rows)
+
+	return nil
+}
+
+func (s *TrashScreen) handleRestore() tea.Cmd {
+	id, title, ok := s.selected()
+	if !ok {
+		return tui.ReportInfo("%s", "trash is empty")
+	}
+
+	if err := s.keeper.Restore(context.Background(), id); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to restore secret: %w", err))
+	}
+
+	if err := s.updateRows(); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load trash: %w", err))
+	}
+
+	return tui.ReportInfo("%q restored", title)
+}
+
+func (s *TrashScreen) handlePurge() tea.Cmd {
+	id, title, ok := s.selected()
+	if !ok {
+		return tui.ReportInfo("%s", "trash is empty")
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Delete %q permanently? This can not be undone.", title), func() tea.Msg {
+		return confirmPurgeMsg{id: id}
+	})
+}
+
+func (s *TrashScreen) purge(id uint64) tea.Cmd {
+	if err := s.keeper.Purge(context.Background(), id); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to delete secret: %w", err))
+	}
+
+	if err := s.updateRows(); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load trash: %w", err))
+	}
+
+	return tui.ReportInfo("%s", "secret deleted permanently")
+}
+
+func (s *TrashScreen) handleEmpty() tea.Cmd {
+	if s.count == 0 {
+		return tui.ReportInfo("%s", "trash is empty")
+	}
+
+	return tui.YesNoPrompt(fmt.Sprintf("Delete all %d secret(s) in trash permanently? This can not be undone.", s.count), func() tea.Msg {
+		return confirmEmptyMsg{}
+	})
+}
+
+func (s *TrashScreen) empty() tea.Cmd {
+	secrets, err := s.keeper.Trashed(context.Background())
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load trash: %w", err))
+	}
+
+	purged := 0
+	for _, sec := range secrets {
+		if err := s.keeper.Purge(context.Background(), sec.ID); err != nil {
+			_ = s.updateRows()
+			return tui.ReportError(fmt.Errorf("failed to delete %q: %w", sec.Title, err))
+		}
+		purged++
+	}
+
+	if err := s.updateRows(); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load trash: %w", err))
+	}
+
+	return tui.ReportInfo("%d secret(s) deleted permanently", purged)
+}
+
+// ID and title of secret under cursor
+func (s TrashScreen) selected() (uint64, string, bool) {
+	row := s.table.SelectedRow()
+	if row == nil {
+		return 0, "", false
+	}
+
+	id, err := strconv.ParseUint(row[0], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+
+	return id, row[1], true
+}
+
+func prepareTable() table.Model {
+	columns := []table.Column{
+		{Title: "id", Width: 5},
+		{Title: "Title", Width: 30},
+		{Title: "SecretType", Width: 15},
+		{Title: "Deleted", Width: 20},
+	}
+
+	t := table.New(
+		table.WithColumns(columns),
+		table.WithFocused(true),
+		table.WithHeight(tableHeight),
+	)
+
+	st := table.DefaultStyles()
+	st.Header = tableHeaderStyle
+	st.Selected = tableSelectedStyle
+	t.SetStyles(st)
+
+	return t
+}
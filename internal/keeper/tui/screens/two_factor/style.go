@@ -0,0 +1,13 @@
+package twofactor
+
+import (
+	"gophkeeper/internal/keeper/tui/styles"
+)
+
+var (
+	qrStyle = styles.Regular.
+		Foreground(styles.White).
+		Background(styles.Black)
+
+	codeStyle = styles.Bold.Foreground(styles.Yellow)
+)
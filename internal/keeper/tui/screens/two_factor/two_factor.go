@@ -0,0 +1,217 @@
+package twofactor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/tui/screens"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/qr"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Code of authenticator app entered by user
+type confirmCodeMsg struct {
+	code string
+}
+
+// Code entered to turn second factor off
+type disableCodeMsg struct {
+	code string
+}
+
+// Turns second factor of account on and off. Secret of authenticator app is shown as QR code,
+// recovery codes are shown once right after second factor is enabled.
+type TwoFactorScreen struct {
+	storage storage.Storage
+	keeper  storage.TwoFactorKeeper
+
+	status        models.TwoFactorStatus
+	enrollment    *models.TOTPEnrollment // secret waiting for code of authenticator app
+	recoveryCodes []string               // just issued, never shown again
+}
+
+func (s TwoFactorScreen) Make(msg tui.NavigationMsg, width, height int) (tui.Teable, error) {
+	return NewTwoFactorScreen(msg.Storage)
+}
+
+func NewTwoFactorScreen(strg storage.Storage) (*TwoFactorScreen, error) {
+	keeper, ok := strg.(storage.TwoFactorKeeper)
+	if !ok {
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", entities.ErrNotSupported)
+	}
+
+	scr := &TwoFactorScreen{
+		storage: strg,
+		keeper:  keeper,
+	}
+
+	if err := scr.updateStatus(); err != nil {
+		return nil, fmt.Errorf("failed to load two-factor settings: %w", err)
+	}
+
+	return scr, nil
+}
+
+func (s TwoFactorScreen) Init() tea.Cmd {
+	return nil
+}
+
+func (s *TwoFactorScreen) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case confirmCodeMsg: // msg from code prompt
+		return s.confirm(msg.code)
+	case disableCodeMsg: // msg from code prompt
+		return s.disable(msg.code)
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "e":
+			return s.handleEnroll()
+		case "c":
+			return s.handleConfirm()
+		case "x":
+			return s.handleDisable()
+		case "b":
+			return tui.SetBodyPane(tui.SessionsScreen, tui.WithStorage(s.storage))
+		}
+	}
+
+	return nil
+}
+
+func (s TwoFactorScreen) View() string {
+	var b strings.Builder
+
+	switch {
+	case s.recoveryCodes != nil:
+		b.WriteString("Two-factor authentication is on. Keep recovery codes below in a safe place,\n")
+		b.WriteString("each one logs in once without authenticator app. They are not shown again.\n\n")
+		for _, code := range s.recoveryCodes {
+			b.WriteString(codeStyle.Render(code))
+			b.WriteRune('\n')
+		}
+		b.WriteString("\n(b)ack\n")
+	case s.enrollment != nil:
+		b.WriteString("Scan QR code with authenticator app or enter secret manually, then (c)onfirm with its code.\n\n")
+		b.WriteString(s.renderQR())
+		b.WriteString(fmt.Sprintf("\nSecret: %s\n\n", codeStyle.Render(s.enrollment.Secret)))
+		b.WriteString("(c)onfirm, (e)nroll again with new secret, (b)ack\n")
+	case s.status.Enabled:
+		b.WriteString("Two-factor authentication is on, login asks for code of authenticator app.\n")
+		b.WriteString(fmt.Sprintf("Recovery codes left: %d\n\n", s.status.RecoveryCodes))
+		b.WriteString("(x) turn off, (b)ack\n")
+	default:
+		b.WriteString("Two-factor authentication is off, password alone logs in.\n\n")
+		b.WriteString("(e)nroll authenticator app, (b)ack\n")
+	}
+
+	return screens.RenderContent(fmt.Sprintf("Two-factor authentication of %s", s.storage.String()), b.String())
+}
+
+func (s *TwoFactorScreen) HelpBindings() []key.Binding {
+	return []key.Binding{
+		key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "enroll authenticator app")),
+		key.NewBinding(key.WithKeys("c"), key.WithHelp("c", "confirm with code")),
+		key.NewBinding(key.WithKeys("x"), key.WithHelp("x", "turn off")),
+		key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "back to devices")),
+	}
+}
+
+func (s *TwoFactorScreen) updateStatus() error {
+	status, err := s.keeper.TwoFactor(context.Background())
+	if err != nil {
+		return err
+	}
+
+	s.status = status
+
+	return nil
+}
+
+func (s *TwoFactorScreen) handleEnroll() tea.Cmd {
+	if s.status.Enabled {
+		return tui.ReportInfo("%s", "two-factor authentication is on already")
+	}
+
+	enrollment, err := s.keeper.EnrollTwoFactor(context.Background())
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to enroll authenticator app: %w", err))
+	}
+
+	s.enrollment = &enrollment
+	s.recoveryCodes = nil
+
+	return nil
+}
+
+func (s *TwoFactorScreen) handleConfirm() tea.Cmd {
+	if s.enrollment == nil {
+		return tui.ReportInfo("%s", "(e)nroll authenticator app first")
+	}
+
+	return tui.EditPrompt("code from authenticator app", "", func(code string) tea.Cmd {
+		return func() tea.Msg { return confirmCodeMsg{code: code} }
+	})
+}
+
+func (s *TwoFactorScreen) confirm(code string) tea.Cmd {
+	recoveryCodes, err := s.keeper.ConfirmTwoFactor(context.Background(), strings.TrimSpace(code))
+	if errors.Is(err, entities.ErrBadTOTPCode) {
+		return tui.ReportError(fmt.Errorf("%w, check clock of the device with authenticator app", err))
+	}
+	if err != nil {
+		return tui.ReportError(fmt.Errorf("failed to turn on two-factor authentication: %w", err))
+	}
+
+	s.enrollment = nil
+	s.recoveryCodes = recoveryCodes
+	s.status = models.TwoFactorStatus{Enabled: true, RecoveryCodes: len(recoveryCodes)}
+
+	return tui.ReportInfo("%s", "two-factor authentication is on")
+}
+
+func (s *TwoFactorScreen) handleDisable() tea.Cmd {
+	if !s.status.Enabled {
+		return tui.ReportInfo("%s", "two-factor authentication is off already")
+	}
+
+	return tui.EditPrompt("code from authenticator app or recovery code", "", func(code string) tea.Cmd {
+		return func() tea.Msg { return disableCodeMsg{code: code} }
+	})
+}
+
+func (s *TwoFactorScreen) disable(code string) tea.Cmd {
+	err := s.keeper.DisableTwoFactor(context.Background(), strings.TrimSpace(code))
+	if err != nil && !errors.Is(err, entities.ErrTwoFactorDisabled) {
+		return tui.ReportError(fmt.Errorf("failed to turn off two-factor authentication: %w", err))
+	}
+
+	s.recoveryCodes = nil
+	if err := s.updateStatus(); err != nil {
+		return tui.ReportError(fmt.Errorf("failed to load two-factor settings: %w", err))
+	}
+
+	return tui.ReportInfo("%s", "two-factor authentication is off")
+}
+
+// QR code of otpauth URI, light on dark as scanners expect regardless of terminal theme
+func (s TwoFactorScreen) renderQR() string {
+	code, err := qr.Encode(s.enrollment.URI)
+	if err != nil {
+		return fmt.Sprintf("QR code is not available: %v\n", err)
+	}
+
+	var b strings.Builder
+	for _, line := range strings.Split(code.String(), "\n") {
+		b.WriteString(qrStyle.Render(line))
+		b.WriteRune('\n')
+	}
+
+	return b.String()
+}
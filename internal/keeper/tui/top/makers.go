@@ -1,37 +1,66 @@
 package top
 
 import (
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/storage"
 	"gophkeeper/internal/keeper/tui"
+	"gophkeeper/internal/keeper/usecase"
 
 	blobEdit "gophkeeper/internal/keeper/tui/screens/blob_edit"
 	cardEdit "gophkeeper/internal/keeper/tui/screens/card_edit"
 	credentialEdit "gophkeeper/internal/keeper/tui/screens/credential_edit"
+	exportSecrets "gophkeeper/internal/keeper/tui/screens/export_secrets"
+	folderTree "gophkeeper/internal/keeper/tui/screens/folder_tree"
+	"gophkeeper/internal/keeper/tui/screens/health"
+	importSecrets "gophkeeper/internal/keeper/tui/screens/import_secrets"
 	"gophkeeper/internal/keeper/tui/screens/login"
 	"gophkeeper/internal/keeper/tui/screens/menu"
+	"gophkeeper/internal/keeper/tui/screens/migrate"
 	remoteeopen "gophkeeper/internal/keeper/tui/screens/remote_open"
+	secretHistory "gophkeeper/internal/keeper/tui/screens/secret_history"
 	secretType "gophkeeper/internal/keeper/tui/screens/secret_type"
+	"gophkeeper/internal/keeper/tui/screens/sessions"
+	sshKeyEdit "gophkeeper/internal/keeper/tui/screens/ssh_key_edit"
 	storageBrowse "gophkeeper/internal/keeper/tui/screens/storage_browse"
 	storageCreate "gophkeeper/internal/keeper/tui/screens/storage_create"
 	storageOpen "gophkeeper/internal/keeper/tui/screens/storage_open"
 	textEdit "gophkeeper/internal/keeper/tui/screens/text_edit"
+	totpEdit "gophkeeper/internal/keeper/tui/screens/totp_edit"
+	"gophkeeper/internal/keeper/tui/screens/trash"
+	twoFactor "gophkeeper/internal/keeper/tui/screens/two_factor"
 	"gophkeeper/internal/keeper/tui/screens/welcome"
 )
 
 // Screen constructors. Inject dependencies if any
 func prepareMakers(deps ModelDependencies) map[tui.Screen]tui.ScreenMaker {
+	vaultEncrypter := crypto.NewVaultEncrypter(deps.Config.KDF)
+	fileOpts := []storage.LocalOption{storage.WithBackups(deps.Config.Backups), storage.WithHistory(deps.Config.History)}
+	openRemote := usecase.NewOpenRemoteStoreUseCase(deps.Config.ReplicaDir, string(deps.Config.ServerAddress), vaultEncrypter, deps.Config.KDF)
+
 	return map[tui.Screen]tui.ScreenMaker{
 		tui.WelcomeScreen:        &welcome.WelcomeScreen{},
 		tui.MenuScreen:           &menu.MenuScreen{},
-		tui.StorageCreateScreen:  &storageCreate.StorageCreateScreen{},
-		tui.StorageOpenScreen:    &storageOpen.StorageOpenScreen{},
-		tui.StorageBrowseScreen:  &storageBrowse.StorageBrowseScreen{},
+		tui.StorageCreateScreen:  &storageCreate.StorageCreateScreenMaker{Encrypter: vaultEncrypter, Options: fileOpts},
+		tui.StorageOpenScreen:    &storageOpen.StorageOpenScreenMaker{Encrypter: vaultEncrypter, Options: fileOpts},
+		tui.StorageBrowseScreen:  &storageBrowse.StorageBrowseScreenMaker{AgentSocket: deps.Config.SSHAgentSocket},
 		tui.SecretTypeScreen:     &secretType.SecretTypeScreen{},
 		tui.CredentialEditScreen: &credentialEdit.CredentialEditScreen{},
 		tui.TextEditScreen:       &textEdit.TextEditScreen{},
 		tui.CardEditScreen:       &cardEdit.CardEditScreen{},
 		tui.BlobEditScreen:       &blobEdit.BlobEditScreen{},
+		tui.TOTPEditScreen:       &totpEdit.TOTPEditScreen{},
+		tui.SSHKeyEditScreen:     &sshKeyEdit.SSHKeyEditScreen{},
 		tui.FilePickScreen:       &blobEdit.FilePickScreen{},
-		tui.LoginScreen:          &login.LoginScreen{},
-		tui.RemoteOpenScreen:     &remoteeopen.RemoteOpenScreenMaker{Client: deps.Client},
+		tui.LoginScreen:          &login.LoginScreenMaker{OpenRemote: openRemote},
+		tui.RemoteOpenScreen:     &remoteeopen.RemoteOpenScreenMaker{Client: deps.Client, OpenRemote: openRemote},
+		tui.MigrateScreen:        &migrate.MigrateScreenMaker{Client: deps.Client, OpenRemote: openRemote, Encrypter: vaultEncrypter, Options: fileOpts},
+		tui.ImportScreen:         &importSecrets.ImportScreen{},
+		tui.ExportScreen:         &exportSecrets.ExportScreenMaker{Encrypter: vaultEncrypter},
+		tui.HistoryScreen:        &secretHistory.HistoryScreen{},
+		tui.TrashScreen:          &trash.TrashScreen{},
+		tui.FolderScreen:         &folderTree.FolderTreeScreen{},
+		tui.SessionsScreen:       &sessions.SessionsScreen{},
+		tui.TwoFactorScreen:      &twoFactor.TwoFactorScreen{},
+		tui.HealthScreen:         &health.HealthScreenMaker{HIBPPath: deps.Config.HIBPPath, MaxAge: deps.Config.PasswordMaxAge},
 	}
 }
@@ -13,11 +13,11 @@ func NewCreateStorageUsecase() *CreateLocalStoreUseCase {
 	return &CreateLocalStoreUseCase{}
 }
 
-func (uc CreateLocalStoreUseCase) Call(path string, password string, encrypter crypto.Encrypter) (*storage.FileStorage, error) {
+func (uc CreateLocalStoreUseCase) Call(engine storage.Engine, path string, password string, encrypter crypto.Encrypter, opts ...storage.LocalOption) (storage.Storage, error) {
 	if path == "" {
 		return nil, fmt.Errorf("no path provided")
 	}
 
-	fs, err := storage.NewFileStorage(path, password, encrypter)
+	fs, err := storage.NewLocal(engine, path, password, encrypter, opts...)
 	return fs, err
 }
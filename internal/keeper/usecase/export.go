@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/exporter"
+	"gophkeeper/internal/keeper/storage"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+type ExportOptions struct {
+	Format    exporter.Format
+	IDs       []uint64 // secrets to export, all when empty
+	Password  string   // password of encrypted archive
+	Overwrite bool     // replace existing file at path, otherwise export fails with ErrFileExists
+}
+
+// Writes secrets of storage to a portable file
+type ExportSecretsUseCase struct {
+	encrypter crypto.Encrypter
+}
+
+func NewExportSecretsUseCase(encrypter crypto.Encrypter) *ExportSecretsUseCase {
+	return &ExportSecretsUseCase{
+		encrypter: encrypter,
+	}
+}
+
+// Export secrets to file at path, returns number of exported secrets
+func (uc ExportSecretsUseCase) Call(ctx context.Context, from storage.Storage, path string, opts ExportOptions) (int, error) {
+	format, err := exporter.ParseFormat(string(opts.Format))
+	if err != nil {
+		return 0, err
+	}
+	opts.Format = format
+
+	if opts.Format == exporter.FormatArchive && opts.Password == "" {
+		return 0, entities.ErrEmptyPassword
+	}
+
+	if samePath(path, from.String()) {
+		return 0, fmt.Errorf("export would overwrite the storage itself")
+	}
+
+	secrets, err := selectSecrets(ctx, from, opts.IDs)
+	if err != nil {
+		return 0, err
+	}
+
+	write := func(w io.Writer) error {
+		switch opts.Format {
+		case exporter.FormatJSON:
+			return exporter.WriteJSON(w, secrets)
+		case exporter.FormatCSV:
+			return exporter.WriteCSV(w, secrets)
+		default:
+			return exporter.WriteArchive(w, secrets, opts.Password, uc.encrypter)
+		}
+	}
+
+	if opts.Overwrite {
+		err = replaceExport(path, write)
+	} else {
+		err = createExport(path, write)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(secrets), nil
+}
+
+func samePath(a string, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+
+	return errA == nil && errB == nil && absA == absB
+}
+
+// Write new export file, existing file at path is never touched
+func createExport(path string, write func(w io.Writer) error) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s: %w", path, entities.ErrFileExists)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	err = write(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(path)
+		return fmt.Errorf("failed to export secrets: %w", err)
+	}
+
+	return nil
+}
+
+// Write export file through temp file in the same directory renamed over path,
+// so existing file stays intact if export fails
+func replaceExport(path string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	err = write(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to export secrets: %w", err)
+	}
+
+	return nil
+}
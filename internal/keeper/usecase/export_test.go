@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/exporter"
+	"gophkeeper/internal/keeper/importer"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportSecrets(t *testing.T) {
+	ctx := context.Background()
+	uc := NewExportSecretsUseCase(crypto.NewVaultEncrypter(crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1}))
+
+	from := newTestVault(t, "from.db", "mail")
+	require.NoError(t, from.Create(ctx, &models.Secret{
+		Title:      "photo",
+		SecretType: string(models.BlobSecret),
+		Blob:       &models.Blob{FileName: "me.jpg", FileBytes: []byte{0xff, 0xd8, 0x00}},
+	}))
+
+	t.Run("Archive round trip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "export.gpkx")
+
+		n, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatArchive, Password: "archive"})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		to := newTestVault(t, "to.db")
+		report, err := NewImportSecretsUseCase().Call(ctx, path, to, ImportOptions{Password: "archive"})
+		require.NoError(t, err)
+		assert.Equal(t, importer.FormatArchive, report.Format)
+		assert.Equal(t, 2, report.Imported)
+
+		imported := secretsByTitle(t, to)
+		assert.Equal(t, []byte{0xff, 0xd8, 0x00}, imported["photo"].Blob.FileBytes)
+		assert.Equal(t, "secret", imported["mail"].Creds.Password)
+		assert.Equal(t, 2024, imported["mail"].CreatedAt.Year())
+	})
+
+	t.Run("JSON round trip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "export.json")
+
+		_, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatJSON})
+		require.NoError(t, err)
+
+		to := newTestVault(t, "to.db")
+		report, err := NewImportSecretsUseCase().Call(ctx, path, to, ImportOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, importer.FormatJSON, report.Format)
+		assert.Len(t, secretsByTitle(t, to), 2)
+	})
+
+	t.Run("Selected to CSV", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "export.csv")
+		id := secretsByTitle(t, from)["mail"].ID
+
+		n, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatCSV, IDs: []uint64{id}})
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "credential,mail,mail,secret")
+	})
+
+	t.Run("Archive without password", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "export.gpkx")
+
+		_, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatArchive})
+		assert.ErrorIs(t, err, entities.ErrEmptyPassword)
+		assert.NoFileExists(t, path)
+	})
+	t.Run("Existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "export.json")
+		require.NoError(t, os.WriteFile(path, []byte("keep me"), 0600))
+
+		_, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatJSON})
+		assert.ErrorIs(t, err, entities.ErrFileExists)
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, "keep me", string(data))
+
+		// Replaced only when asked
+		n, err := uc.Call(ctx, from, path, ExportOptions{Format: exporter.FormatJSON, Overwrite: true})
+		require.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		data, err = os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "mail")
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temp file left behind")
+	})
+}
@@ -0,0 +1,160 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"hash/crc32"
+	"strconv"
+	"time"
+)
+
+// Field which differs between two versions of secret
+type FieldChange struct {
+	Field     string
+	Old       string
+	New       string
+	Sensitive bool // values should be masked when shown
+}
+
+// Field-level differences between old and newer version of secret
+func DiffSecrets(old *models.Secret, newer *models.Secret) []FieldChange {
+	var changes []FieldChange
+
+	add := func(field string, o string, n string, sensitive bool) {
+		if o != n {
+			changes = append(changes, FieldChange{Field: field, Old: o, New: n, Sensitive: sensitive})
+		}
+	}
+
+	add("title", old.Title, newer.Title, false)
+	add("type", old.SecretType, newer.SecretType, false)
+	add("metadata", old.Metadata, newer.Metadata, false)
+	add("folder", old.Folder, newer.Folder, false)
+	add("tags", old.Tags.String(), newer.Tags.String(), false)
+
+	o, n := secretFields(old), secretFields(newer)
+	for _, f := range o {
+		add(f.name, f.value, n.get(f.name), f.sensitive)
+	}
+	for _, f := range n {
+		if !o.has(f.name) {
+			add(f.name, "", f.value, f.sensitive)
+		}
+	}
+
+	return changes
+}
+
+type secretField struct {
+	name      string
+	value     string
+	sensitive bool
+}
+
+type fieldList []secretField
+
+func (l fieldList) get(name string) string {
+	for _, f := range l {
+		if f.name == name {
+			return f.value
+		}
+	}
+
+	return ""
+}
+
+func (l fieldList) has(name string) bool {
+	for _, f := range l {
+		if f.name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Contents of secret as named fields
+func secretFields(s *models.Secret) fieldList {
+	switch {
+	case s.Creds != nil:
+		return fieldList{
+			{name: "login", value: s.Creds.Login},
+			{name: "password", value: s.Creds.Password, sensitive: true},
+		}
+	case s.Card != nil:
+		return fieldList{
+			{name: "card number", value: s.Card.Number, sensitive: true},
+			{name: "expiration", value: fmt.Sprintf("%02d/%d", s.Card.ExpMonth, s.Card.ExpYear)},
+			{name: "cvv", value: strconv.FormatUint(uint64(s.Card.CVV), 10), sensitive: true},
+		}
+	case s.Text != nil:
+		return fieldList{{name: "text", value: s.Text.Content}}
+	case s.TOTP != nil:
+		p := s.TOTP.Key().Params
+		return fieldList{
+			{name: "totp secret", value: s.TOTP.Secret, sensitive: true},
+			{name: "issuer", value: s.TOTP.Issuer},
+			{name: "account", value: s.TOTP.Account},
+			{name: "code parameters", value: fmt.Sprintf("%s, %d digits, %ds", p.Algorithm, p.Digits, p.Period)},
+		}
+	case s.SSH != nil:
+		return fieldList{
+			{name: "private key", value: s.SSH.PrivateKey, sensitive: true},
+			{name: "passphrase", value: s.SSH.Passphrase, sensitive: true},
+			{name: "public key", value: s.SSH.PublicKey},
+			{name: "comment", value: s.SSH.Comment},
+		}
+	case s.Blob != nil:
+		// File contents are not shown, only size and checksum to tell versions apart
+		return fieldList{
+			{name: "file name", value: s.Blob.FileName},
+			{name: "file", value: fmt.Sprintf("%d bytes, crc %08x", len(s.Blob.FileBytes), crc32.ChecksumIEEE(s.Blob.FileBytes))},
+		}
+	default:
+		return nil
+	}
+}
+
+type RestoreRevisionUseCase struct{}
+
+func NewRestoreRevisionUseCase() *RestoreRevisionUseCase {
+	return &RestoreRevisionUseCase{}
+}
+
+// Save contents of previous version as a new version of secret, so restore itself can be undone
+func (uc *RestoreRevisionUseCase) Call(ctx context.Context, store storage.Storage, id uint64, revision uint64) (*models.Secret, error) {
+	keeper, ok := store.(storage.HistoryKeeper)
+	if !ok {
+		return nil, entities.ErrNotSupported
+	}
+
+	current, err := store.Get(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secret: %w", err)
+	}
+
+	old, err := keeper.Revision(ctx, id, revision)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revision %d: %w", revision, err)
+	}
+
+	if len(DiffSecrets(old, current)) == 0 {
+		return current, nil
+	}
+
+	restored := *old
+	restored.ID = current.ID
+	restored.Revision = current.Revision // server checks it against the latest version
+	restored.CreatedAt = current.CreatedAt
+	restored.UpdatedAt = time.Now()
+	restored.Payload = nil
+
+	if err := store.Update(ctx, &restored); err != nil {
+		return nil, err
+	}
+
+	return &restored, nil
+}
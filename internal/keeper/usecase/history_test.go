@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffSecrets(t *testing.T) {
+	old := &models.Secret{Title: "mail", SecretType: string(models.CredSecret), Metadata: "work",
+		Creds: &models.Credentials{Login: "alice", Password: "old"}}
+	current := &models.Secret{Title: "mail", SecretType: string(models.CredSecret), Metadata: "personal",
+		Folder: "work/mail", Tags: models.Tags{"mail", "work"},
+		Creds: &models.Credentials{Login: "alice", Password: "new"}}
+
+	assert.Equal(t, []FieldChange{
+		{Field: "metadata", Old: "work", New: "personal"},
+		{Field: "folder", Old: "", New: "work/mail"},
+		{Field: "tags", Old: "", New: "mail, work"},
+		{Field: "password", Old: "old", New: "new", Sensitive: true},
+	}, DiffSecrets(old, current))
+
+	note := &models.Secret{Title: "mail", SecretType: string(models.TextSecret), Text: &models.Text{Content: "hi"}}
+	changes := DiffSecrets(old, note)
+	require.Len(t, changes, 5)
+	assert.Equal(t, FieldChange{Field: "text", Old: "", New: "hi"}, changes[4])
+
+	assert.Empty(t, DiffSecrets(current, current))
+}
+
+func TestRestoreRevision(t *testing.T) {
+	ctx := context.Background()
+	uc := NewRestoreRevisionUseCase()
+
+	store := newTestVault(t, "vault.db", "mail")
+	secret := secretsByTitle(t, store)["mail"]
+
+	secret.Creds.Password = "changed"
+	require.NoError(t, store.Update(ctx, secret))
+
+	history, err := store.(storage.HistoryKeeper).History(ctx, secret.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+
+	restored, err := uc.Call(ctx, store, secret.ID, history[0].Revision)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", restored.Creds.Password)
+
+	stored, err := store.Get(ctx, secret.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", stored.Creds.Password)
+
+	// Restore is a new version, so it can be undone
+	history, err = store.(storage.HistoryKeeper).History(ctx, secret.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "changed", history[0].Creds.Password)
+}
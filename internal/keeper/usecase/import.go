@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/importer"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"sort"
+	"strings"
+)
+
+type ImportOptions struct {
+	Format     importer.Format // detected from file when empty or auto
+	Password   string          // password of encrypted archive
+	DryRun     bool            // only parse and count, do not write
+	Duplicates DuplicatePolicy
+}
+
+// Outcome of import
+type ImportReport struct {
+	Format      importer.Format
+	DryRun      bool
+	Parsed      *importer.Result
+	Total       int
+	Imported    int
+	Overwritten int
+	Skipped     int // duplicates of existing secrets
+	Failed      []MigrateFailure
+}
+
+// Summary, e.g. "5 secrets found (3 credential, 2 card), 4 imported, 1 skipped as duplicate, 2 unsupported"
+func (r ImportReport) String() string {
+	verb := "imported"
+	if r.DryRun {
+		verb = "would be imported"
+	}
+
+	found := fmt.Sprintf("%d secrets found", r.Total)
+
+	if r.Parsed != nil {
+		if counts := r.Parsed.Counts(); len(counts) > 0 {
+			types := make([]string, 0, len(counts))
+			for t, n := range counts {
+				types = append(types, fmt.Sprintf("%d %s", n, t))
+			}
+			sort.Strings(types)
+
+			found += " (" + strings.Join(types, ", ") + ")"
+		}
+	}
+
+	parts := []string{found, fmt.Sprintf("%d %s", r.Imported+r.Overwritten, verb)}
+
+	if r.Overwritten > 0 {
+		parts = append(parts, fmt.Sprintf("%d overwritten", r.Overwritten))
+	}
+	if r.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped as duplicate", r.Skipped))
+	}
+	if r.Parsed != nil && len(r.Parsed.Skipped) > 0 {
+		parts = append(parts, fmt.Sprintf("%d unsupported", len(r.Parsed.Skipped)))
+	}
+	if len(r.Failed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", len(r.Failed)))
+	}
+
+	result := strings.Join(parts, ", ")
+	if r.DryRun {
+		result = "dry run: " + result
+	}
+
+	return result
+}
+
+// Imports exports of other password managers into storage. New secrets are written
+// in one batch when storage supports it, so vault is re-encrypted only once.
+type ImportSecretsUseCase struct {
+}
+
+func NewImportSecretsUseCase() *ImportSecretsUseCase {
+	return &ImportSecretsUseCase{}
+}
+
+func (uc ImportSecretsUseCase) Call(ctx context.Context, path string, to storage.Storage, opts ImportOptions) (*ImportReport, error) {
+	if opts.Duplicates == "" {
+		opts.Duplicates = DuplicateSkip
+	}
+
+	parsed, format, err := importer.ParseFile(path, opts.Format, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := to.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target storage: %w", err)
+	}
+
+	targets := make(map[string]*models.Secret, len(existing))
+	for _, s := range existing {
+		targets[duplicateKey(s)] = s
+	}
+
+	report := &ImportReport{Format: format, DryRun: opts.DryRun, Parsed: parsed, Total: len(parsed.Secrets)}
+
+	var created []*models.Secret
+
+	for _, s := range parsed.Secrets {
+		duplicate := targets[duplicateKey(s)]
+
+		switch {
+		case duplicate == nil || opts.Duplicates == DuplicateKeep:
+			created = append(created, s)
+		case opts.Duplicates == DuplicateSkip:
+			report.Skipped++
+		case opts.DryRun:
+			report.Overwritten++
+		default:
+			s.ID = duplicate.ID
+			s.Revision = duplicate.Revision
+
+			if err := to.Update(ctx, s); err != nil {
+				report.Failed = append(report.Failed, MigrateFailure{Title: s.Title, Err: err})
+				continue
+			}
+
+			report.Overwritten++
+		}
+	}
+
+	if opts.DryRun {
+		report.Imported = len(created)
+		return report, nil
+	}
+
+	if err := uc.create(ctx, to, created, report); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (uc ImportSecretsUseCase) create(ctx context.Context, to storage.Storage, secrets []*models.Secret, report *ImportReport) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+
+	if batch, ok := to.(storage.BatchCreator); ok {
+		if err := batch.CreateBatch(ctx, secrets); err != nil {
+			return fmt.Errorf("failed to save imported secrets: %w", err)
+		}
+
+		report.Imported += len(secrets)
+		return nil
+	}
+
+	for _, s := range secrets {
+		if err := to.Create(ctx, s); err != nil {
+			report.Failed = append(report.Failed, MigrateFailure{Title: s.Title, Err: err})
+			continue
+		}
+
+		report.Imported++
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gophkeeper/internal/keeper/importer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportSecrets(t *testing.T) {
+	ctx := context.Background()
+	uc := NewImportSecretsUseCase()
+
+	path := filepath.Join(t.TempDir(), "export.csv")
+	require.NoError(t, os.WriteFile(path, []byte("name,login,password,card number,notes\n"+
+		"mail,alice,new,,\n"+
+		"bank,bob,secret,,\n"+
+		"visa,,,4111111111111111,\n"+
+		"blank,,,,\n"), 0600))
+
+	t.Run("Dry run", func(t *testing.T) {
+		to := newTestVault(t, "vault.db", "mail")
+
+		report, err := uc.Call(ctx, path, to, ImportOptions{DryRun: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, importer.FormatGenericCSV, report.Format)
+		assert.Equal(t, 2, report.Imported)
+		assert.Equal(t, "dry run: 3 secrets found (1 card, 2 credential), 2 would be imported, 1 skipped as duplicate, 1 unsupported", report.String())
+		assert.Len(t, secretsByTitle(t, to), 1)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		to := newTestVault(t, "vault.db", "mail")
+
+		report, err := uc.Call(ctx, path, to, ImportOptions{Duplicates: DuplicateOverwrite})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Imported)
+		assert.Equal(t, 1, report.Overwritten)
+
+		imported := secretsByTitle(t, to)
+		assert.Len(t, imported, 3)
+		assert.Equal(t, "new", imported["mail"].Creds.Password)
+		assert.Equal(t, "4111111111111111", imported["visa"].Card.Number)
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		to := newTestVault(t, "vault.db")
+
+		_, err := uc.Call(ctx, path, to, ImportOptions{Format: "lastpass"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"strings"
+)
+
+// What to do with secret when target already has one with same title and type
+type DuplicatePolicy string
+
+const (
+	DuplicateSkip      DuplicatePolicy = "skip"      // leave target secret as is
+	DuplicateOverwrite DuplicatePolicy = "overwrite" // replace target secret contents
+	DuplicateKeep      DuplicatePolicy = "keep"      // add secret next to existing one
+)
+
+// Parse policy name as typed by user
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(strings.TrimSpace(s)); p {
+	case DuplicateSkip, DuplicateOverwrite, DuplicateKeep:
+		return p, nil
+	case "":
+		return DuplicateSkip, nil
+	default:
+		return "", fmt.Errorf("unknown duplicate policy %q, use skip, overwrite or keep", s)
+	}
+}
+
+type MigrateOptions struct {
+	IDs        []uint64 // secrets to migrate, all when empty
+	Move       bool     // delete migrated secrets from source
+	Duplicates DuplicatePolicy
+}
+
+// Secret which could not be migrated
+type MigrateFailure struct {
+	ID    uint64
+	Title string
+	Err   error
+}
+
+// Outcome of migration
+type MigrateReport struct {
+	Total       int
+	Copied      int
+	Overwritten int
+	Skipped     int
+	Removed     int // deleted from source after move
+	Failed      []MigrateFailure
+}
+
+// Summary, e.g. "4 of 5 secrets copied, 1 skipped as duplicate, 4 removed from source"
+func (r MigrateReport) String() string {
+	parts := []string{fmt.Sprintf("%d of %d secrets copied", r.Copied+r.Overwritten, r.Total)}
+
+	if r.Overwritten > 0 {
+		parts = append(parts, fmt.Sprintf("%d overwritten", r.Overwritten))
+	}
+	if r.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped as duplicate", r.Skipped))
+	}
+	if len(r.Failed) > 0 {
+		parts = append(parts, fmt.Sprintf("%d failed", len(r.Failed)))
+	}
+	if r.Removed > 0 {
+		parts = append(parts, fmt.Sprintf("%d removed from source", r.Removed))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// Copies or moves secrets between storages, e.g. local vault and remote account.
+// Each storage encrypts secrets with its own key, so secrets are re-encrypted on the way.
+type MigrateSecretsUseCase struct {
+}
+
+func NewMigrateSecretsUseCase() *MigrateSecretsUseCase {
+	return &MigrateSecretsUseCase{}
+}
+
+func (uc MigrateSecretsUseCase) Call(ctx context.Context, from storage.Storage, to storage.Storage, opts MigrateOptions) (*MigrateReport, error) {
+	if from == to {
+		return nil, fmt.Errorf("source and target are the same storage")
+	}
+
+	if opts.Duplicates == "" {
+		opts.Duplicates = DuplicateSkip
+	}
+
+	secrets, err := selectSecrets(ctx, from, opts.IDs)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := to.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target storage: %w", err)
+	}
+
+	targets := make(map[string]*models.Secret, len(existing))
+	for _, s := range existing {
+		targets[duplicateKey(s)] = s
+	}
+
+	report := &MigrateReport{Total: len(secrets)}
+
+	for _, s := range secrets {
+		migrated, err := uc.migrate(ctx, to, s, targets[duplicateKey(s)], opts.Duplicates, report)
+		if err != nil {
+			report.Failed = append(report.Failed, MigrateFailure{ID: s.ID, Title: s.Title, Err: err})
+			continue
+		}
+
+		if !migrated || !opts.Move {
+			continue
+		}
+
+		if err := from.Delete(ctx, s.ID); err != nil {
+			report.Failed = append(report.Failed, MigrateFailure{ID: s.ID, Title: s.Title, Err: fmt.Errorf("copied, but not removed from source: %w", err)})
+			continue
+		}
+
+		report.Removed++
+	}
+
+	return report, nil
+}
+
+// Store one secret in target according to duplicate policy. Reports whether secret ended up in target.
+func (uc MigrateSecretsUseCase) migrate(ctx context.Context, to storage.Storage, s *models.Secret, duplicate *models.Secret, policy DuplicatePolicy, report *MigrateReport) (bool, error) {
+	secret := *s
+	secret.Payload = nil // target encrypts decrypted contents with its own key
+	secret.Revision = 0
+
+	if duplicate != nil {
+		switch policy {
+		case DuplicateSkip:
+			report.Skipped++
+			return false, nil
+		case DuplicateOverwrite:
+			secret.ID = duplicate.ID
+			secret.Revision = duplicate.Revision
+
+			if err := to.Update(ctx, &secret); err != nil {
+				return false, err
+			}
+
+			report.Overwritten++
+			return true, nil
+		}
+	}
+
+	secret.ID = 0
+	if err := to.Create(ctx, &secret); err != nil {
+		return false, err
+	}
+
+	report.Copied++
+
+	return true, nil
+}
+
+// Full secrets with given IDs, or all secrets
+func selectSecrets(ctx context.Context, from storage.Storage, ids []uint64) ([]*models.Secret, error) {
+	if len(ids) == 0 {
+		secrets, err := from.GetAll(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source storage: %w", err)
+		}
+
+		return secrets, nil
+	}
+
+	secrets := make([]*models.Secret, 0, len(ids))
+	for _, id := range ids {
+		secret, err := from.Get(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read secret %d: %w", id, err)
+		}
+
+		secret.ID = id
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+func duplicateKey(s *models.Secret) string {
+	return s.SecretType + "\n" + s.Title
+}
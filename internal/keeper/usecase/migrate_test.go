@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestVault(t *testing.T, name string, titles ...string) storage.Storage {
+	encrypter := crypto.NewVaultEncrypter(crypto.KDFParams{Time: 1, Memory: 1024, Threads: 1})
+
+	store, err := storage.NewLocal(storage.EngineVault, filepath.Join(t.TempDir(), name), "password", encrypter)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = store.Close(context.Background()) })
+
+	for _, title := range titles {
+		require.NoError(t, store.Create(context.Background(), &models.Secret{
+			Title:      title,
+			SecretType: string(models.CredSecret),
+			Creds:      &models.Credentials{Login: title, Password: "secret"},
+			CreatedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+			UpdatedAt:  time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+		}))
+	}
+
+	return store
+}
+
+func secretsByTitle(t *testing.T, store storage.Storage) map[string]*models.Secret {
+	secrets, err := store.GetAll(context.Background())
+	require.NoError(t, err)
+
+	byTitle := make(map[string]*models.Secret)
+	for _, s := range secrets {
+		byTitle[s.Title] = s
+	}
+
+	return byTitle
+}
+
+func TestMigrateSecrets(t *testing.T) {
+	ctx := context.Background()
+	uc := NewMigrateSecretsUseCase()
+
+	t.Run("Copy keeps contents and timestamps", func(t *testing.T) {
+		from := newTestVault(t, "from.db", "mail", "bank")
+		to := newTestVault(t, "to.db")
+
+		report, err := uc.Call(ctx, from, to, MigrateOptions{})
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, report.Copied)
+		assert.Equal(t, "2 of 2 secrets copied", report.String())
+
+		copied := secretsByTitle(t, to)
+		require.Contains(t, copied, "mail")
+		assert.Equal(t, "mail", copied["mail"].Creds.Login)
+		assert.Equal(t, 2024, copied["mail"].CreatedAt.Year())
+		assert.Equal(t, time.February, copied["mail"].UpdatedAt.Month())
+		assert.Len(t, secretsByTitle(t, from), 2)
+	})
+
+	t.Run("Move selected", func(t *testing.T) {
+		from := newTestVault(t, "from.db", "mail", "bank")
+		to := newTestVault(t, "to.db")
+
+		id := secretsByTitle(t, from)["bank"].ID
+		report, err := uc.Call(ctx, from, to, MigrateOptions{IDs: []uint64{id}, Move: true})
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, report.Removed)
+		assert.Equal(t, []string{"mail"}, keys(secretsByTitle(t, from)))
+		assert.Equal(t, []string{"bank"}, keys(secretsByTitle(t, to)))
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		tests := []struct {
+			policy DuplicatePolicy
+			count  int
+			login  string
+			report MigrateReport
+		}{
+			{policy: DuplicateSkip, count: 1, login: "old", report: MigrateReport{Total: 1, Skipped: 1}},
+			{policy: DuplicateOverwrite, count: 1, login: "mail", report: MigrateReport{Total: 1, Overwritten: 1}},
+			{policy: DuplicateKeep, count: 2, report: MigrateReport{Total: 1, Copied: 1}},
+		}
+
+		for _, tt := range tests {
+			t.Run(string(tt.policy), func(t *testing.T) {
+				from := newTestVault(t, "from.db", "mail")
+				to := newTestVault(t, "to.db")
+				require.NoError(t, to.Create(ctx, &models.Secret{
+					Title:      "mail",
+					SecretType: string(models.CredSecret),
+					Creds:      &models.Credentials{Login: "old"},
+				}))
+
+				report, err := uc.Call(ctx, from, to, MigrateOptions{Duplicates: tt.policy})
+				require.NoError(t, err)
+				assert.Equal(t, tt.report, *report)
+
+				secrets, err := to.GetAll(ctx)
+				require.NoError(t, err)
+				assert.Len(t, secrets, tt.count)
+
+				if tt.login != "" {
+					assert.Equal(t, tt.login, secrets[0].Creds.Login)
+				}
+			})
+		}
+	})
+
+	t.Run("Same storage", func(t *testing.T) {
+		store := newTestVault(t, "vault.db")
+
+		_, err := uc.Call(ctx, store, store, MigrateOptions{})
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDuplicatePolicy(t *testing.T) {
+	policy, err := ParseDuplicatePolicy(" overwrite ")
+	assert.NoError(t, err)
+	assert.Equal(t, DuplicateOverwrite, policy)
+
+	policy, err = ParseDuplicatePolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, DuplicateSkip, policy)
+
+	_, err = ParseDuplicatePolicy("merge")
+	assert.Error(t, err)
+}
+
+func keys(m map[string]*models.Secret) []string {
+	var result []string
+	for k := range m {
+		result = append(result, k)
+	}
+
+	return result
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/keeper/api"
+	"gophkeeper/internal/keeper/crypto"
+	"gophkeeper/internal/keeper/entities"
+	"gophkeeper/internal/keeper/storage"
+	"os"
+)
+
+// Opens remote storage of logged in user through its local replica
+type OpenRemoteStoreUseCase struct {
+	replicaDir string
+	address    string
+	encrypter  crypto.Encrypter // encrypts replica file
+	kdf        crypto.KDFParams // work factor of keys of upgraded legacy accounts
+}
+
+func NewOpenRemoteStoreUseCase(replicaDir string, address string, encrypter crypto.Encrypter, kdf crypto.KDFParams) *OpenRemoteStoreUseCase {
+	return &OpenRemoteStoreUseCase{
+		replicaDir: replicaDir,
+		address:    address,
+		encrypter:  encrypter,
+		kdf:        kdf,
+	}
+}
+
+// Open storage of user authenticated by client
+func (uc OpenRemoteStoreUseCase) Call(client api.IApiClient) (storage.Storage, error) {
+	return uc.open(client, client.GetLogin())
+}
+
+// Open replica without reaching server, password is checked by decrypting it.
+// Encryption key is derived with KDF params saved at last login, legacy accounts use password itself.
+// Queued changes are delivered once server is back.
+func (uc OpenRemoteStoreUseCase) CallOffline(client api.IApiClient, login string, password string) (storage.Storage, error) {
+	if !storage.HasReplica(storage.ReplicaPath(uc.replicaDir, uc.address, login)) {
+		return nil, fmt.Errorf("no offline copy for %s: %w", login, entities.ErrServerUnavailable)
+	}
+
+	key := password
+
+	account, err := storage.LoadAccountParams(storage.AccountKDFPath(uc.replicaDir, uc.address, login))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		keys, err := crypto.DeriveAccountKeys(password, account.AccountKDF)
+		if err != nil {
+			return nil, err
+		}
+		key = keys.Encryption
+	}
+
+	client.SetPassword(password)
+	client.SetEncryptionKey(key)
+
+	return uc.open(client, login)
+}
+
+// Switch legacy account of logged in user to auth and encryption keys derived from password,
+// so server no longer sees password. Reports whether account was upgraded, accounts using derived keys are left as is.
+func (uc OpenRemoteStoreUseCase) Upgrade(ctx context.Context, client api.IApiClient, strg storage.Storage) (bool, error) {
+	cached, ok := strg.(*storage.CachedStorage)
+	if !ok || !client.GetKDF().Legacy() {
+		return false, nil
+	}
+
+	kdf, err := crypto.NewAccountKDF(uc.kdf)
+	if err != nil {
+		return false, err
+	}
+
+	keys, err := crypto.DeriveAccountKeys(client.GetPassword(), kdf)
+	if err != nil {
+		return false, err
+	}
+
+	if err := cached.UpgradeAccount(ctx, kdf, keys); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (uc OpenRemoteStoreUseCase) open(client api.IApiClient, login string) (storage.Storage, error) {
+	remote, err := storage.NewRemoteStorage(client, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	path := storage.ReplicaPath(uc.replicaDir, uc.address, login)
+
+	return storage.NewCachedStorage(remote, login, path, uc.encrypter, storage.DefaultSyncInterval)
+}
@@ -0,0 +1,155 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/keeper/storage"
+	"gophkeeper/pkg/models"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Folder in tree built from secret folders
+type FolderNode struct {
+	Path  string // full path, e.g. "work/mail"
+	Name  string // last path element
+	Depth int    // 0 for top level folders
+	Count int    // secrets in folder and its subfolders
+}
+
+// Folders of secrets with all their parents, parent before children, siblings by name
+func FolderTree(secrets []*models.Secret) []FolderNode {
+	counts := make(map[string]int)
+	for _, s := range secrets {
+		for _, path := range models.FolderPath(s.Folder) {
+			counts[path]++
+		}
+	}
+
+	paths := make([]string, 0, len(counts))
+	for path := range counts {
+		paths = append(paths, path)
+	}
+
+	// Compare element-wise, so "a/b" goes right after "a" and before "a b"
+	slices.SortFunc(paths, func(a, b string) int {
+		return slices.Compare(strings.Split(a, models.FolderSeparator), strings.Split(b, models.FolderSeparator))
+	})
+
+	nodes := make([]FolderNode, 0, len(paths))
+	for _, path := range paths {
+		parts := strings.Split(path, models.FolderSeparator)
+		nodes = append(nodes, FolderNode{
+			Path:  path,
+			Name:  parts[len(parts)-1],
+			Depth: len(parts) - 1,
+			Count: counts[path],
+		})
+	}
+
+	return nodes
+}
+
+// Tag with number of secrets tagged with it
+type TagCount struct {
+	Tag   string
+	Count int
+}
+
+// Tags of secrets ordered by name, tags differing only in case are counted together
+func TagCounts(secrets []*models.Secret) []TagCount {
+	var tags []TagCount
+
+	for _, s := range secrets {
+		for _, tag := range s.Tags {
+			i := slices.IndexFunc(tags, func(t TagCount) bool { return strings.EqualFold(t.Tag, tag) })
+			if i < 0 {
+				tags = append(tags, TagCount{Tag: tag})
+				i = len(tags) - 1
+			}
+			tags[i].Count++
+		}
+	}
+
+	slices.SortFunc(tags, func(a, b TagCount) int {
+		return strings.Compare(strings.ToLower(a.Tag), strings.ToLower(b.Tag))
+	})
+
+	return tags
+}
+
+// Secrets in folder (with subfolders) and with tag, empty folder and tag match all
+func FilterSecrets(secrets []*models.Secret, folder string, tag string) []*models.Secret {
+	var res []*models.Secret
+	for _, s := range secrets {
+		if s.InFolder(folder) && (tag == "" || s.HasTag(tag)) {
+			res = append(res, s)
+		}
+	}
+
+	return res
+}
+
+type OrganizeSecretsUseCase struct{}
+
+func NewOrganizeSecretsUseCase() *OrganizeSecretsUseCase {
+	return &OrganizeSecretsUseCase{}
+}
+
+// Put secrets into folder, root when folder is empty. Returns number of moved secrets.
+func (uc *OrganizeSecretsUseCase) Move(ctx context.Context, store storage.Storage, ids []uint64, folder string) (int, error) {
+	folder = models.CleanFolder(folder)
+
+	return uc.change(ctx, store, ids, func(s *models.Secret) bool {
+		if s.Folder == folder {
+			return false
+		}
+
+		s.Folder = folder
+
+		return true
+	})
+}
+
+// Replace tags of secrets. Returns number of changed secrets.
+func (uc *OrganizeSecretsUseCase) SetTags(ctx context.Context, store storage.Storage, ids []uint64, tags models.Tags) (int, error) {
+	tags = models.NormalizeTags(tags)
+
+	return uc.change(ctx, store, ids, func(s *models.Secret) bool {
+		if slices.Equal(s.Tags, tags) {
+			return false
+		}
+
+		s.Tags = tags
+
+		return true
+	})
+}
+
+// Apply fn to each secret and save ones it changed, stopping on first failure
+func (uc *OrganizeSecretsUseCase) change(ctx context.Context, store storage.Storage, ids []uint64, fn func(s *models.Secret) bool) (int, error) {
+	changed := 0
+
+	for _, id := range ids {
+		secret, err := store.Get(ctx, id)
+		if err != nil {
+			return changed, fmt.Errorf("failed to load secret %d: %w", id, err)
+		}
+
+		if !fn(secret) {
+			continue
+		}
+
+		secret.UpdatedAt = time.Now()
+		secret.Payload = nil
+
+		if err := store.Update(ctx, secret); err != nil {
+			return changed, fmt.Errorf("failed to save %q: %w", secret.Title, err)
+		}
+
+		changed++
+	}
+
+	return changed, nil
+}
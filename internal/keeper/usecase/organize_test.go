@@ -0,0 +1,74 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFolderTree(t *testing.T) {
+	secrets := []*models.Secret{
+		{Folder: "work/mail"},
+		{Folder: "work"},
+		{Folder: "work b"},
+		{Folder: "home/bank/cards"},
+		{},
+	}
+
+	assert.Equal(t, []FolderNode{
+		{Path: "home", Name: "home", Depth: 0, Count: 1},
+		{Path: "home/bank", Name: "bank", Depth: 1, Count: 1},
+		{Path: "home/bank/cards", Name: "cards", Depth: 2, Count: 1},
+		{Path: "work", Name: "work", Depth: 0, Count: 2},
+		{Path: "work/mail", Name: "mail", Depth: 1, Count: 1},
+		{Path: "work b", Name: "work b", Depth: 0, Count: 1},
+	}, FolderTree(secrets))
+}
+
+func TestTagCounts(t *testing.T) {
+	secrets := []*models.Secret{
+		{Tags: models.Tags{"mail", "Work"}},
+		{Tags: models.Tags{"work"}},
+		{},
+	}
+
+	assert.Equal(t, []TagCount{{Tag: "mail", Count: 1}, {Tag: "Work", Count: 2}}, TagCounts(secrets))
+
+	filtered := FilterSecrets(append(secrets, &models.Secret{Folder: "work/mail", Tags: models.Tags{"work"}}), "work", "WORK")
+	require.Len(t, filtered, 1)
+	assert.Equal(t, "work/mail", filtered[0].Folder)
+}
+
+func TestOrganizeSecrets(t *testing.T) {
+	ctx := context.Background()
+	uc := NewOrganizeSecretsUseCase()
+
+	store := newTestVault(t, "vault.db", "mail", "bank")
+	byTitle := secretsByTitle(t, store)
+	ids := []uint64{byTitle["mail"].ID, byTitle["bank"].ID}
+
+	moved, err := uc.Move(ctx, store, ids, "/work//personal/")
+	require.NoError(t, err)
+	assert.Equal(t, 2, moved)
+
+	// Already there
+	moved, err = uc.Move(ctx, store, ids[:1], "work/personal")
+	require.NoError(t, err)
+	assert.Equal(t, 0, moved)
+
+	changed, err := uc.SetTags(ctx, store, ids[:1], models.ParseTags("#urgent, mail urgent"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, changed)
+
+	mail := secretsByTitle(t, store)["mail"]
+	assert.Equal(t, "work/personal", mail.Folder)
+	assert.Equal(t, models.Tags{"mail", "urgent"}, mail.Tags)
+	assert.Equal(t, "secret", mail.Creds.Password)
+
+	_, err = uc.Move(ctx, store, []uint64{100}, "work")
+	assert.Error(t, err)
+}
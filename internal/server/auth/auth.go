@@ -2,6 +2,9 @@
 package auth
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
 	"time"
 
@@ -24,13 +27,17 @@ func CheckPassword(passwordHash, password string) bool {
 	return err == nil
 }
 
-// Creates JWT token
-func CreateToken(userID int, expireDate time.Time, secretKey []byte) (string, error) {
+// Length of random part of refresh token
+const refreshTokenLen = 32
+
+// Creates JWT access token of user's login session
+func CreateToken(userID int, sessionID uint64, expireDate time.Time, secretKey []byte) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"iss":     "gophkeeper",
-		"exp":     expireDate.Unix(),
-		"iat":     time.Now().Unix(),
+		"user_id":    userID,
+		"session_id": sessionID,
+		"iss":        "gophkeeper",
+		"exp":        expireDate.Unix(),
+		"iat":        time.Now().Unix(),
 	})
 
 	tokenString, err := token.SignedString(secretKey)
@@ -41,6 +48,22 @@ func CreateToken(userID int, expireDate time.Time, secretKey []byte) (string, er
 	return tokenString, nil
 }
 
+// Creates random opaque refresh token, server keeps only its hash
+func CreateRefreshToken() (string, error) {
+	buf := make([]byte, refreshTokenLen)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// Hash of refresh token to store and look it up by
+func HashRefreshToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
 // Verifies validity of token and returns claims
 func VerifyToken(tokenText string, secretKey []byte) (jwt.MapClaims, error) {
 	token, err := ParseToken(tokenText, secretKey)
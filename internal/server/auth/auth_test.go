@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPasswords(t *testing.T) {
@@ -53,14 +54,14 @@ func TestTokens(t *testing.T) {
 	expireDate := time.Now().Add(time.Hour)
 
 	t.Run("create token", func(t *testing.T) {
-		token, err := CreateToken(int(testUserID), expireDate, secret)
+		token, err := CreateToken(int(testUserID), 7, expireDate, secret)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, token)
 	})
 
 	t.Run("successful token verification", func(t *testing.T) {
-		tokenString, err := CreateToken(int(testUserID), expireDate, secret)
+		tokenString, err := CreateToken(int(testUserID), 7, expireDate, secret)
 
 		assert.NoError(t, err)
 		assert.NotEmpty(t, tokenString)
@@ -74,6 +75,20 @@ func TestTokens(t *testing.T) {
 		claimedUserID := uint64(claims["user_id"].(float64))
 
 		assert.Equal(t, testUserID, claimedUserID)
+		assert.Equal(t, float64(7), claims["session_id"])
+	})
+
+	t.Run("refresh tokens", func(t *testing.T) {
+		token, err := CreateRefreshToken()
+		require.NoError(t, err)
+
+		other, err := CreateRefreshToken()
+		require.NoError(t, err)
+
+		assert.NotEqual(t, token, other)
+		assert.Len(t, HashRefreshToken(token), 32)
+		assert.Equal(t, HashRefreshToken(token), HashRefreshToken(token))
+		assert.NotEqual(t, HashRefreshToken(token), HashRefreshToken(other))
 	})
 
 	t.Run("verify invalid token", func(t *testing.T) {
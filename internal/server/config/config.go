@@ -4,7 +4,9 @@ package config
 import (
 	"fmt"
 	"gophkeeper/internal/server/entities"
+	"gophkeeper/pkg/models"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 	"go.uber.org/dig"
@@ -17,8 +19,25 @@ type Config struct {
 	LogLevel    string
 	SecretKey   string // key to sign jwt
 	EnableTLS   bool
+
+	TrashRetention time.Duration // how long deleted secrets stay in trash, purging disabled when zero
+
+	// Profile new accounts are registered with by default, unknown logins are reported with it
+	// so they look like fresh accounts. KDF params should match those of clients, salt is not used.
+	AccountKDF models.AccountKDF
+	AccountSRP bool // new accounts log in with SRP
 }
 
+// Default number of days deleted secrets stay in trash
+const DefaultTrashDays = 30
+
+// Default Argon2id params of accounts, same as keeper defaults
+const (
+	DefaultKDFTime    = 3
+	DefaultKDFMemory  = 64 * 1024 // KiB
+	DefaultKDFThreads = 4
+)
+
 // Shortcut to use with dig
 type Dependency struct {
 	dig.In
@@ -32,6 +51,11 @@ func New() *Config {
 	viper.SetDefault("verbose", false)
 	viper.SetDefault("log-level", "INFO")
 	viper.SetDefault("secret-key", "123456") // TODO: remove default, add warning
+	viper.SetDefault("trash-days", DefaultTrashDays)
+	viper.SetDefault("kdf-time", DefaultKDFTime)
+	viper.SetDefault("kdf-memory", DefaultKDFMemory)
+	viper.SetDefault("kdf-threads", DefaultKDFThreads)
+	viper.SetDefault("srp", true)
 
 	viper.SetEnvPrefix("GOPH")
 	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
@@ -41,7 +65,24 @@ func New() *Config {
 		Address:     viper.GetString("address"),
 		PostgresDSN: entities.SecretConnURI(viper.GetString("postgres-dsn")),
 		LogLevel:    viper.GetString("log-level"),
+		SecretKey:   viper.GetString("secret-key"),
 		EnableTLS:   true,
+
+		TrashRetention: time.Duration(viper.GetInt("trash-days")) * 24 * time.Hour,
+
+		AccountKDF: models.AccountKDF{
+			Time:    viper.GetUint32("kdf-time"),
+			Memory:  viper.GetUint32("kdf-memory"),
+			Threads: uint8(viper.GetUint("kdf-threads")),
+		},
+		AccountSRP: viper.GetBool("srp"),
+	}
+
+	// Params clients refuse would give unknown logins away, defaults are kept instead
+	check := cfg.AccountKDF
+	check.Salt = make([]byte, models.MinKDFSaltLen)
+	if check.Validate() != nil {
+		cfg.AccountKDF = models.AccountKDF{Time: DefaultKDFTime, Memory: DefaultKDFMemory, Threads: DefaultKDFThreads}
 	}
 
 	return cfg
@@ -53,6 +94,8 @@ func (c *Config) String() string {
 	sb.WriteString("Configuration:\n")
 	sb.WriteString(fmt.Sprintf("\t\tServer address: %s\n", c.Address))
 	sb.WriteString(fmt.Sprintf("\t\tPostgres DSN: %s\n", c.Address))
+	sb.WriteString(fmt.Sprintf("\t\tTrash retention: %s\n", c.TrashRetention))
+	sb.WriteString(fmt.Sprintf("\t\tNew accounts: argon2id t=%d m=%dKiB p=%d, SRP %t\n", c.AccountKDF.Time, c.AccountKDF.Memory, c.AccountKDF.Threads, c.AccountSRP))
 
 	return sb.String()
 }
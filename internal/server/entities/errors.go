@@ -3,6 +3,7 @@ package entities
 import (
 	"errors"
 	"fmt"
+	"time"
 )
 
 var (
@@ -12,14 +13,51 @@ var (
 	ErrUnexpected        = errors.New("unexpected error")
 	ErrBadAddressFormat  = errors.New("bad net address format")
 
-	ErrSecretNotFound = errors.New("secret not found")
-	ErrNoSecrets      = errors.New("no secrets found")
+	ErrSecretNotFound   = errors.New("secret not found")
+	ErrNoSecrets        = errors.New("no secrets found")
+	ErrStaleRevision    = errors.New("secret was changed by another client")
+	ErrRevisionNotFound = errors.New("secret revision not found")
+	ErrNotInTrash       = errors.New("secret not found in trash")
+	ErrCreateReplayed   = errors.New("secret was already created with this key")
 
 	ErrUserNotFound      = errors.New("user not found")
 	ErrUserAlreadyExists = errors.New("user already exists")
 	ErrNoSubscribers     = errors.New("no subscribers")
+
+	ErrAlreadyUpgraded   = errors.New("account already authenticates with auth key")
+	ErrIncompleteUpgrade = errors.New("secrets were changed during upgrade, try again")
+	ErrLegacyAccount     = errors.New("account must be upgraded to auth key first")
+	ErrHandshakeNotFound = errors.New("login handshake not found or expired")
+
+	ErrSessionNotFound = errors.New("session not found")
+	ErrSessionRevoked  = errors.New("session was ended, log in again")
+	ErrRefreshReused   = errors.New("refresh token was already used, session ended")
+
+	ErrTwoFactorNotFound = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrBadTOTPCode       = errors.New("wrong one-time code")
+	ErrChallengeExpired  = errors.New("login took too long or too many wrong codes, log in again")
+
+	ErrLoginLocked = errors.New("too many failed logins")
 )
 
+// Login refused till lockout of login or client address ends
+type LoginLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return fmt.Sprintf("%v, try again in %s", ErrLoginLocked, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginLockedError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+func ErrorLoginLocked(retryAfter time.Duration) error {
+	return &LoginLockedError{RetryAfter: retryAfter}
+}
+
 func ErrorUserAlreadyExists(login string) error {
 	return fmt.Errorf("%w (%s)", ErrUserAlreadyExists, login)
 }
@@ -27,3 +65,15 @@ func ErrorUserAlreadyExists(login string) error {
 func ErrorSecretNotFound(secretID uint64) error {
 	return fmt.Errorf("%w (id=%d)", ErrSecretNotFound, secretID)
 }
+
+func ErrorStaleRevision(secretID uint64, revision uint64) error {
+	return fmt.Errorf("%w (id=%d, revision=%d)", ErrStaleRevision, secretID, revision)
+}
+
+func ErrorRevisionNotFound(secretID uint64, revision uint64) error {
+	return fmt.Errorf("%w (id=%d, revision=%d)", ErrRevisionNotFound, secretID, revision)
+}
+
+func ErrorNotInTrash(secretID uint64) error {
+	return fmt.Errorf("%w (id=%d)", ErrNotInTrash, secretID)
+}
@@ -8,6 +8,7 @@ import (
 	"gophkeeper/internal/server/config"
 	grpchandlers "gophkeeper/internal/server/grpcbackend/handlers"
 	"gophkeeper/internal/server/grpcbackend/interceptor"
+	"gophkeeper/internal/server/service"
 	"gophkeeper/pkg/proto/keeper/grpcapi"
 
 	"go.uber.org/dig"
@@ -30,12 +31,13 @@ type BackendDependencies struct {
 	UsersServer        *grpchandlers.UsersServer
 	SecretsServer      *grpchandlers.SecretsServer
 	NotificationServer *grpchandlers.NotificationServer
+	SessionsManager    service.SessionsManager
 }
 
 // Backend constructor
 func NewBackend(deps BackendDependencies) (*Backend, error) {
 	iceps := make([]grpc.UnaryServerInterceptor, 0, 2)
-	iceps = append(iceps, interceptor.Authentication([]byte(deps.Config.SecretKey)))
+	iceps = append(iceps, interceptor.Authentication([]byte(deps.Config.SecretKey), deps.SessionsManager))
 	iceps = append(iceps, interceptor.Logger(deps.Logger))
 
 	grpcOpts := []grpc.ServerOption{
@@ -57,7 +59,7 @@ func NewBackend(deps BackendDependencies) (*Backend, error) {
 	grpcOpts = append(
 		grpcOpts,
 		grpc.StreamInterceptor(
-			interceptor.StreamAuthentication([]byte(deps.Config.SecretKey)),
+			interceptor.StreamAuthentication([]byte(deps.Config.SecretKey), deps.SessionsManager),
 		),
 	)
 
@@ -7,6 +7,7 @@ import (
 	"gophkeeper/internal/server/service"
 	"gophkeeper/pkg/constants"
 	"gophkeeper/pkg/convert"
+	"gophkeeper/pkg/models"
 
 	"go.uber.org/dig"
 	"go.uber.org/zap"
@@ -17,6 +18,9 @@ import (
 	pb "gophkeeper/pkg/proto/keeper/grpcapi"
 )
 
+// Longest create key stored by server
+const maxCreateKey = 64
+
 type SecretsServer struct {
 	pb.UnimplementedSecretsServer
 
@@ -41,24 +45,44 @@ func NewSecretsServer(deps SecretsServerDependencies) *SecretsServer {
 	}
 }
 
-// Saves new secret or updates existing one
-func (s *SecretsServer) SaveUserSecretV1(ctx context.Context, in *pb.SaveUserSecretRequestV1) (*emptypb.Empty, error) {
-	var err error
+// Saves new secret or updates existing one.
+// Update of stale revision fails with codes.Aborted, current secret is attached to status details.
+func (s *SecretsServer) SaveUserSecretV1(ctx context.Context, in *pb.SaveUserSecretRequestV1) (*pb.SaveUserSecretResponseV1, error) {
+	var (
+		saved *models.Secret
+		err   error
+	)
 
 	userID, err := extractUserID(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	if len(in.CreateKey) > maxCreateKey {
+		return nil, status.Errorf(codes.InvalidArgument, "create key longer than %d bytes", maxCreateKey)
+	}
+
 	secret := convert.ProtoToSecret(in.Secret)
 	secret.UserID = int(userID)
+	secret.CreateKey = in.CreateKey
+
+	isUpdated := secret.ID > 0
 
 	// Save secret
-	if secret.ID > 0 {
-		_, err = s.secretsManager.UpdateSecret(ctx, secret)
+	if isUpdated {
+		saved, err = s.secretsManager.UpdateSecret(ctx, secret)
 	} else {
-		_, err = s.secretsManager.CreateSecret(ctx, secret)
+		saved, err = s.secretsManager.CreateSecret(ctx, secret)
 	}
+
+	if errors.Is(err, entities.ErrStaleRevision) {
+		return nil, conflictError(err, saved)
+	}
+
+	if errors.Is(err, entities.ErrSecretNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -66,15 +90,31 @@ func (s *SecretsServer) SaveUserSecretV1(ctx context.Context, in *pb.SaveUserSec
 	// Send notifications
 	clientID, err := extractClientID(ctx)
 	if err == nil {
-		isUpdated := secret.ID > 0
-		err = s.notificationServer.notifyClients(userID, clientID, secret.ID, isUpdated)
+		err = s.notificationServer.notifyClients(userID, clientID, saved.ID, isUpdated)
 
 		if err != nil {
 			s.logger.Error("failed to notify clients: ", err)
 		}
 	}
 
-	return &emptypb.Empty{}, nil
+	// Client already has the payload
+	response := convert.SecretToProto(saved)
+	response.Payload = nil
+
+	return &pb.SaveUserSecretResponseV1{Secret: response}, nil
+}
+
+// Aborted status carrying current copy of secret
+func conflictError(err error, current *models.Secret) error {
+	st := status.New(codes.Aborted, err.Error())
+
+	if current != nil {
+		if detailed, derr := st.WithDetails(convert.SecretToProto(current)); derr == nil {
+			st = detailed
+		}
+	}
+
+	return st.Err()
 }
 
 func (s *SecretsServer) GetUserSecretV1(ctx context.Context, in *pb.GetUserSecretRequestV1) (*pb.GetUserSecretResponseV1, error) {
@@ -122,6 +162,7 @@ func (s *SecretsServer) GetUserSecretsV1(ctx context.Context, in *emptypb.Empty)
 	return &response, nil
 }
 
+// Moves secret to trash
 func (s *SecretsServer) DeleteUserSecretV1(ctx context.Context, in *pb.DeleteUserSecretRequestV1) (*emptypb.Empty, error) {
 
 	userID, err := extractUserID(ctx)
@@ -143,6 +184,123 @@ func (s *SecretsServer) DeleteUserSecretV1(ctx context.Context, in *pb.DeleteUse
 	return &emptypb.Empty{}, nil
 }
 
+// Returns changes since client's cursor: updated secrets and IDs of deleted ones.
+// Empty or unknown cursor returns full listing with reset flag set.
+func (s *SecretsServer) SyncV1(ctx context.Context, in *pb.SyncRequestV1) (*pb.SyncResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	changes, err := s.secretsManager.SyncSecrets(ctx, userID, in.Cursor, int(in.Limit))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.SyncResponseV1{
+		Secrets:    convert.SecretsToProto(changes.Updated),
+		DeletedIds: changes.Deleted,
+		Cursor:     changes.Cursor,
+		HasMore:    changes.HasMore,
+		FullResync: changes.Reset,
+	}, nil
+}
+
+// Returns previous revisions of secret, newest first, without payloads
+func (s *SecretsServer) ListSecretRevisionsV1(ctx context.Context, in *pb.ListSecretRevisionsRequestV1) (*pb.ListSecretRevisionsResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	revisions, err := s.secretsManager.GetSecretRevisions(ctx, in.Id, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ListSecretRevisionsResponseV1{Revisions: convert.SecretsToProto(revisions)}, nil
+}
+
+// Returns previous revision of secret with its payload
+func (s *SecretsServer) GetSecretRevisionV1(ctx context.Context, in *pb.GetSecretRevisionRequestV1) (*pb.GetSecretRevisionResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secret, err := s.secretsManager.GetSecretRevision(ctx, in.Id, userID, in.Revision)
+	if errors.Is(err, entities.ErrRevisionNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.GetSecretRevisionResponseV1{Secret: convert.SecretToProto(secret)}, nil
+}
+
+// Returns secrets in trash, most recently deleted first
+func (s *SecretsServer) ListTrashV1(ctx context.Context, in *emptypb.Empty) (*pb.ListTrashResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secrets, err := s.secretsManager.GetTrash(ctx, userID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ListTrashResponseV1{Secrets: convert.SecretsToProto(secrets)}, nil
+}
+
+// Takes secret out of trash, other clients get it with the next sync
+func (s *SecretsServer) RestoreSecretV1(ctx context.Context, in *pb.RestoreSecretRequestV1) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.secretsManager.RestoreSecret(ctx, in.Id, userID)
+	if errors.Is(err, entities.ErrNotInTrash) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Send notifications
+	clientID, err := extractClientID(ctx)
+	if err == nil {
+		if err = s.notificationServer.notifyClients(userID, clientID, in.Id, true); err != nil {
+			s.logger.Error("failed to notify clients: ", err)
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Permanently deletes secret in trash
+func (s *SecretsServer) PurgeSecretV1(ctx context.Context, in *pb.PurgeSecretRequestV1) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.secretsManager.PurgeSecret(ctx, in.Id, userID)
+	if errors.Is(err, entities.ErrNotInTrash) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
 func extractUserID(ctx context.Context) (uint64, error) {
 	uid := ctx.Value(constants.CtxUserIDKey)
 
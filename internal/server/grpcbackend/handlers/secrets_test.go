@@ -2,7 +2,10 @@ package grpchandlers
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/pkg/constants"
@@ -46,6 +49,42 @@ func (m *MockSecretsManager) DeleteSecret(ctx context.Context, id, userID uint64
 	return args.Error(0)
 }
 
+func (m *MockSecretsManager) SyncSecrets(ctx context.Context, userID uint64, cursor string, limit int) (*models.SecretChanges, error) {
+	args := m.Called(ctx, userID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.SecretChanges), args.Error(1)
+}
+
+func (m *MockSecretsManager) GetSecretRevisions(ctx context.Context, id, userID uint64) (models.Secrets, error) {
+	args := m.Called(ctx, id, userID)
+	return args.Get(0).(models.Secrets), args.Error(1)
+}
+
+func (m *MockSecretsManager) GetSecretRevision(ctx context.Context, id, userID uint64, revision uint64) (*models.Secret, error) {
+	args := m.Called(ctx, id, userID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Secret), args.Error(1)
+}
+
+func (m *MockSecretsManager) GetTrash(ctx context.Context, userID uint64) (models.Secrets, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Secrets), args.Error(1)
+}
+
+func (m *MockSecretsManager) RestoreSecret(ctx context.Context, id, userID uint64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
+func (m *MockSecretsManager) PurgeSecret(ctx context.Context, id, userID uint64) error {
+	args := m.Called(ctx, id, userID)
+	return args.Error(0)
+}
+
 func TestSecretsServer_SaveUserSecretV1(t *testing.T) {
 	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
 
@@ -64,14 +103,32 @@ func TestSecretsServer_SaveUserSecretV1(t *testing.T) {
 		mockSecretsManager.On("CreateSecret", ctx, mock.Anything).Return(&models.Secret{ID: 1}, nil)
 
 		request := &grpcapi.SaveUserSecretRequestV1{
-			Secret: secretProto,
+			Secret:    secretProto,
+			CreateKey: "key",
 		}
 
 		response, err := secretsServer.SaveUserSecretV1(ctx, request)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
-		mockSecretsManager.AssertCalled(t, "CreateSecret", ctx, mock.Anything)
+		mockSecretsManager.AssertCalled(t, "CreateSecret", ctx, mock.MatchedBy(func(s *models.Secret) bool {
+			return s.CreateKey == "key"
+		}))
+	})
+
+	t.Run("Create key too long", func(t *testing.T) {
+		secretsServer := NewSecretsServer(SecretsServerDependencies{
+			SecretsManager: new(MockSecretsManager),
+		})
+
+		request := &grpcapi.SaveUserSecretRequestV1{
+			Secret:    secretProto,
+			CreateKey: strings.Repeat("k", maxCreateKey+1),
+		}
+
+		_, err := secretsServer.SaveUserSecretV1(ctx, request)
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
 	})
 
 	t.Run("Update existing secret", func(t *testing.T) {
@@ -93,6 +150,36 @@ func TestSecretsServer_SaveUserSecretV1(t *testing.T) {
 		assert.NotNil(t, response)
 		mockSecretsManager.AssertCalled(t, "UpdateSecret", ctx, mock.Anything)
 	})
+
+	t.Run("Stale revision", func(t *testing.T) {
+		mockSecretsManager := new(MockSecretsManager)
+		secretsServer := NewSecretsServer(SecretsServerDependencies{
+			SecretsManager: mockSecretsManager,
+		})
+
+		current := &models.Secret{ID: 1, Title: "theirs", Payload: []byte("their data"), Revision: 5}
+		mockSecretsManager.On("UpdateSecret", ctx, mock.Anything).Return(current, entities.ErrorStaleRevision(1, 5))
+
+		request := &grpcapi.SaveUserSecretRequestV1{
+			Secret: &grpcapi.Secret{Id: 1, Title: "mine", Revision: 4},
+		}
+
+		response, err := secretsServer.SaveUserSecretV1(ctx, request)
+
+		assert.Nil(t, response)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Aborted, st.Code())
+
+		details := st.Details()
+		if assert.Len(t, details, 1) {
+			theirs, ok := details[0].(*grpcapi.Secret)
+			assert.True(t, ok)
+			assert.Equal(t, "theirs", theirs.Title)
+			assert.Equal(t, uint64(5), theirs.Revision)
+		}
+	})
 }
 
 func TestSecretsServer_GetUserSecretV1(t *testing.T) {
@@ -177,6 +264,77 @@ func TestSecretsServer_GetUserSecretsV1(t *testing.T) {
 	})
 }
 
+func TestSecretsServer_SyncV1(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+
+	mockSecretsManager := new(MockSecretsManager)
+	secretsServer := NewSecretsServer(SecretsServerDependencies{
+		SecretsManager: mockSecretsManager,
+	})
+
+	t.Run("Delta", func(t *testing.T) {
+		mockSecretsManager.On("SyncSecrets", ctx, uint64(1), "7", 100).Return(&models.SecretChanges{
+			Updated: models.Secrets{{ID: 1, Title: "changed", UserID: 1}},
+			Deleted: []uint64{2},
+			Cursor:  "9",
+			HasMore: true,
+		}, nil)
+
+		response, err := secretsServer.SyncV1(ctx, &grpcapi.SyncRequestV1{Cursor: "7", Limit: 100})
+
+		assert.NoError(t, err)
+		assert.Len(t, response.Secrets, 1)
+		assert.Equal(t, []uint64{2}, response.DeletedIds)
+		assert.Equal(t, "9", response.Cursor)
+		assert.True(t, response.HasMore)
+		assert.False(t, response.FullResync)
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		mockSecretsManager.On("SyncSecrets", ctx, uint64(1), "broken", 0).Return(nil, errors.New("db error"))
+
+		_, err := secretsServer.SyncV1(ctx, &grpcapi.SyncRequestV1{Cursor: "broken"})
+
+		assert.Equal(t, codes.Internal, status.Code(err))
+	})
+}
+
+func TestSecretsServer_Revisions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+
+	mockSecretsManager := new(MockSecretsManager)
+	secretsServer := NewSecretsServer(SecretsServerDependencies{
+		SecretsManager: mockSecretsManager,
+	})
+
+	t.Run("List", func(t *testing.T) {
+		mockSecretsManager.On("GetSecretRevisions", ctx, uint64(5), uint64(1)).Return(models.Secrets{{ID: 5, Revision: 2}, {ID: 5, Revision: 1}}, nil)
+
+		response, err := secretsServer.ListSecretRevisionsV1(ctx, &grpcapi.ListSecretRevisionsRequestV1{Id: 5})
+
+		assert.NoError(t, err)
+		assert.Len(t, response.Revisions, 2)
+		assert.Equal(t, uint64(2), response.Revisions[0].Revision)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		mockSecretsManager.On("GetSecretRevision", ctx, uint64(5), uint64(1), uint64(1)).Return(&models.Secret{ID: 5, Revision: 1, Payload: []byte("old")}, nil)
+
+		response, err := secretsServer.GetSecretRevisionV1(ctx, &grpcapi.GetSecretRevisionRequestV1{Id: 5, Revision: 1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("old"), response.Secret.Payload)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockSecretsManager.On("GetSecretRevision", ctx, uint64(5), uint64(1), uint64(9)).Return(nil, entities.ErrorRevisionNotFound(5, 9))
+
+		_, err := secretsServer.GetSecretRevisionV1(ctx, &grpcapi.GetSecretRevisionRequestV1{Id: 5, Revision: 9})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
+
 func TestSecretsServer_DeleteUserSecretV1(t *testing.T) {
 	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
 
@@ -214,3 +372,39 @@ func TestSecretsServer_DeleteUserSecretV1(t *testing.T) {
 		mockSecretsManager.AssertCalled(t, "DeleteSecret", ctx, uint64(2), uint64(1))
 	})
 }
+
+func TestSecretsServer_Trash(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+
+	mockSecretsManager := new(MockSecretsManager)
+	secretsServer := NewSecretsServer(SecretsServerDependencies{
+		SecretsManager: mockSecretsManager,
+	})
+
+	t.Run("List", func(t *testing.T) {
+		deletedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		mockSecretsManager.On("GetTrash", ctx, uint64(1)).Return(models.Secrets{{ID: 5, DeletedAt: &deletedAt}}, nil)
+
+		response, err := secretsServer.ListTrashV1(ctx, &emptypb.Empty{})
+
+		assert.NoError(t, err)
+		assert.Len(t, response.Secrets, 1)
+		assert.Equal(t, deletedAt, response.Secrets[0].DeletedAt.AsTime())
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		mockSecretsManager.On("RestoreSecret", ctx, uint64(5), uint64(1)).Return(nil)
+
+		_, err := secretsServer.RestoreSecretV1(ctx, &grpcapi.RestoreSecretRequestV1{Id: 5})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("Purge not in trash", func(t *testing.T) {
+		mockSecretsManager.On("PurgeSecret", ctx, uint64(6), uint64(1)).Return(entities.ErrorNotInTrash(6))
+
+		_, err := secretsServer.PurgeSecretV1(ctx, &grpcapi.PurgeSecretRequestV1{Id: 6})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+}
@@ -0,0 +1,129 @@
+package grpchandlers
+
+import (
+	"context"
+	"errors"
+	"gophkeeper/internal/server/entities"
+
+	pb "gophkeeper/pkg/proto/keeper/grpcapi"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// Second step of login to account with TOTP second factor. Wrong code may be retried a few times,
+// after that or once challenge expires codes.FailedPrecondition tells client to start over.
+// Wrong codes count as failed logins too.
+func (s *UsersServer) LoginTOTPV1(ctx context.Context, in *pb.LoginTOTPRequestV1) (*pb.LoginResponseV1, error) {
+	user, err := s.twoFactorManager.Verify(ctx, in.Challenge, in.Code)
+
+	switch {
+	case errors.Is(err, entities.ErrBadTOTPCode):
+		return nil, s.failAttempt(ctx, user.Login, err)
+	case errors.Is(err, entities.ErrChallengeExpired):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.succeedAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.authUser(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to auth: %v", err)
+	}
+
+	return &pb.LoginResponseV1{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// Whether second factor of user is enabled
+func (s *UsersServer) GetTwoFactorV1(ctx context.Context, _ *emptypb.Empty) (*pb.TwoFactorStatusV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	tf, err := s.twoFactorManager.Status(ctx, int(userID))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.TwoFactorStatusV1{Enabled: tf.Enabled, RecoveryCodes: uint32(tf.RecoveryCodes)}, nil
+}
+
+// New TOTP secret of user, second factor is enabled once ConfirmTOTPV1 gets code of it
+func (s *UsersServer) EnrollTOTPV1(ctx context.Context, _ *emptypb.Empty) (*pb.EnrollTOTPResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	enrollment, err := s.twoFactorManager.Enroll(ctx, int(userID))
+	if err != nil {
+		return nil, twoFactorError(err)
+	}
+
+	return &pb.EnrollTOTPResponseV1{Secret: enrollment.Secret, Uri: enrollment.URI}, nil
+}
+
+// Enable second factor with code of pending secret, recovery codes are returned this once.
+// Wrong codes count as failed logins, so they can not be guessed with stolen session.
+func (s *UsersServer) ConfirmTOTPV1(ctx context.Context, in *pb.ConfirmTOTPRequestV1) (*pb.ConfirmTOTPResponseV1, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAttempts(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	recoveryCodes, err := s.twoFactorManager.Confirm(ctx, user.ID, in.Code)
+	if err != nil {
+		return nil, twoFactorError(err)
+	}
+
+	if err := s.releaseAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	return &pb.ConfirmTOTPResponseV1{RecoveryCodes: recoveryCodes}, nil
+}
+
+// Turn second factor off, with TOTP code or recovery code. Wrong codes count as failed logins.
+func (s *UsersServer) DisableTOTPV1(ctx context.Context, in *pb.DisableTOTPRequestV1) (*emptypb.Empty, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAttempts(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactorManager.Disable(ctx, user.ID, in.Code); err != nil {
+		return nil, twoFactorError(err)
+	}
+
+	if err := s.releaseAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func twoFactorError(err error) error {
+	switch {
+	case errors.Is(err, entities.ErrBadTOTPCode):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entities.ErrTwoFactorEnabled):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, entities.ErrTwoFactorNotFound):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
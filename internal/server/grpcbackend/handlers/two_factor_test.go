@@ -0,0 +1,151 @@
+package grpchandlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/pkg/constants"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/proto/keeper/grpcapi"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func newTwoFactorServer() (*UsersServer, *MockUsersManager, *MockSessionsManager, *MockTwoFactorManager) {
+	usersManager, sessions, twoFactor := new(MockUsersManager), testSessions(), new(MockTwoFactorManager)
+
+	return NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     usersManager,
+		SessionsManager:  sessions,
+		TwoFactorManager: twoFactor,
+		LoginAttempts:    testLoginAttempts(),
+	}), usersManager, sessions, twoFactor
+}
+
+func TestUsersServer_LoginChallenge(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Password login", func(t *testing.T) {
+		server, usersManager, sessions, twoFactor := newTwoFactorServer()
+		usersManager.On("LoginUser", ctx, "testuser", testAuthKey).Return(&models.User{ID: 1}, nil)
+		twoFactor.On("Challenge", ctx, 1).Return("challenge", nil)
+
+		response, err := server.LoginV1(ctx, &grpcapi.LoginRequestV1{Login: "testuser", AuthKey: testAuthKey})
+		require.NoError(t, err)
+		assert.Equal(t, "challenge", response.TotpChallenge)
+		assert.Empty(t, response.AccessToken)
+		assert.Empty(t, response.RefreshToken)
+		sessions.AssertNotCalled(t, "Open", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SRP login keeps server proof", func(t *testing.T) {
+		server, usersManager, sessions, twoFactor := newTwoFactorServer()
+		usersManager.On("FinishSRP", ctx, "session", []byte("M1")).Return(&models.User{ID: 1}, []byte("M2"), nil)
+		twoFactor.On("Challenge", ctx, 1).Return("challenge", nil)
+
+		response, err := server.LoginSRPFinishV1(ctx, &grpcapi.LoginSRPFinishRequestV1{SessionId: "session", M1: []byte("M1")})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("M2"), response.M2)
+		assert.Equal(t, "challenge", response.TotpChallenge)
+		assert.Empty(t, response.AccessToken)
+		sessions.AssertNotCalled(t, "Open", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Challenge not issued", func(t *testing.T) {
+		server, usersManager, sessions, twoFactor := newTwoFactorServer()
+		usersManager.On("LoginUser", ctx, "testuser", testAuthKey).Return(&models.User{ID: 1}, nil)
+		twoFactor.On("Challenge", ctx, 1).Return("", errors.New("connection refused"))
+
+		_, err := server.LoginV1(ctx, &grpcapi.LoginRequestV1{Login: "testuser", AuthKey: testAuthKey})
+		assert.Equal(t, codes.Internal, status.Code(err))
+		sessions.AssertNotCalled(t, "Open", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestUsersServer_LoginTOTPV1(t *testing.T) {
+	ctx := context.Background()
+	server, _, _, twoFactor := newTwoFactorServer()
+
+	twoFactor.On("Verify", ctx, "challenge", "123456").Return(&models.User{ID: 1, Login: "testuser"}, nil)
+	twoFactor.On("Verify", ctx, "challenge", "000000").Return(&models.User{ID: 1, Login: "testuser"}, entities.ErrBadTOTPCode)
+	twoFactor.On("Verify", ctx, "stale", mock.Anything).Return(nil, entities.ErrChallengeExpired)
+
+	response, err := server.LoginTOTPV1(ctx, &grpcapi.LoginTOTPRequestV1{Challenge: "challenge", Code: "123456"})
+	require.NoError(t, err)
+	assert.Equal(t, testTokens.AccessToken, response.AccessToken)
+	assert.Equal(t, testTokens.RefreshToken, response.RefreshToken)
+
+	_, err = server.LoginTOTPV1(ctx, &grpcapi.LoginTOTPRequestV1{Challenge: "challenge", Code: "000000"})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	_, err = server.LoginTOTPV1(ctx, &grpcapi.LoginTOTPRequestV1{Challenge: "stale", Code: "123456"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestUsersServer_TwoFactorSettings(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+	server, usersManager, _, twoFactor := newTwoFactorServer()
+	attempts := server.loginAttempts.(*MockLoginAttemptsManager)
+
+	usersManager.On("GetUser", ctx, 1).Return(&models.User{ID: 1, Login: "testuser"}, nil)
+	twoFactor.On("Status", ctx, 1).Return(models.TwoFactorStatus{Enabled: true, RecoveryCodes: 9}, nil)
+	twoFactor.On("Enroll", ctx, 1).Return(models.TOTPEnrollment{}, entities.ErrTwoFactorEnabled)
+	twoFactor.On("Confirm", ctx, 1, "123456").Return([]string{"abcde-fghij"}, nil)
+	twoFactor.On("Confirm", ctx, 1, "000000").Return(nil, entities.ErrBadTOTPCode)
+	twoFactor.On("Disable", ctx, 1, "123456").Return(entities.ErrTwoFactorNotFound)
+	twoFactor.On("Disable", ctx, 1, "000000").Return(entities.ErrBadTOTPCode)
+
+	st, err := server.GetTwoFactorV1(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.True(t, st.Enabled)
+	assert.Equal(t, uint32(9), st.RecoveryCodes)
+
+	_, err = server.EnrollTOTPV1(ctx, &emptypb.Empty{})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// Codes are counted against login of the caller, right one is taken back
+	confirmed, err := server.ConfirmTOTPV1(ctx, &grpcapi.ConfirmTOTPRequestV1{Code: "123456"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"abcde-fghij"}, confirmed.RecoveryCodes)
+	attempts.AssertCalled(t, "Check", ctx, "testuser", "")
+	attempts.AssertNumberOfCalls(t, "Release", 1)
+
+	_, err = server.DisableTOTPV1(ctx, &grpcapi.DisableTOTPRequestV1{Code: "123456"})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = server.ConfirmTOTPV1(ctx, &grpcapi.ConfirmTOTPRequestV1{Code: "000000"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = server.DisableTOTPV1(ctx, &grpcapi.DisableTOTPRequestV1{Code: "000000"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	attempts.AssertNumberOfCalls(t, "Check", 4)
+	attempts.AssertNumberOfCalls(t, "Release", 1)
+}
+
+func TestUsersServer_TwoFactorSettingsLocked(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+	usersManager, twoFactor, attempts := new(MockUsersManager), new(MockTwoFactorManager), new(MockLoginAttemptsManager)
+	server := NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     usersManager,
+		SessionsManager:  testSessions(),
+		TwoFactorManager: twoFactor,
+		LoginAttempts:    attempts,
+	})
+
+	usersManager.On("GetUser", ctx, 1).Return(&models.User{ID: 1, Login: "testuser"}, nil)
+	attempts.On("Check", ctx, "testuser", "").Return(entities.ErrorLoginLocked(time.Minute))
+
+	_, err := server.DisableTOTPV1(ctx, &grpcapi.DisableTOTPRequestV1{Code: "123456"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	twoFactor.AssertNotCalled(t, "Disable", mock.Anything, mock.Anything, mock.Anything)
+}
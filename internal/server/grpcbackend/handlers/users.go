@@ -2,78 +2,143 @@ package grpchandlers
 
 import (
 	"context"
+	"crypto/hmac"
+	"crypto/sha256"
 	"errors"
-	"gophkeeper/internal/server/auth"
 	"gophkeeper/internal/server/config"
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/internal/server/service"
 	"gophkeeper/pkg/constants"
+	"gophkeeper/pkg/convert"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/srp"
 	"strconv"
-	"time"
+	"strings"
 
 	pb "gophkeeper/pkg/proto/keeper/grpcapi"
 
 	"go.uber.org/dig"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-const tokenLifetime = time.Hour * 24
+// Longest device name kept for session
+const maxDeviceLen = 255
+
+// Length of salts made up for unknown logins
+const fakeSaltLen = 16
 
 type UsersServer struct {
 	pb.UnimplementedUsersServer
 
-	config       *config.Config
-	usersManager service.UsersManager
+	config           *config.Config
+	usersManager     service.UsersManager
+	sessionsManager  service.SessionsManager
+	twoFactorManager service.TwoFactorManager
+	loginAttempts    service.LoginAttemptsManager
 }
 
 type UsersServerDependencies struct {
 	dig.In
 
-	Config       *config.Config
-	UsersManager service.UsersManager
+	Config           *config.Config
+	UsersManager     service.UsersManager
+	SessionsManager  service.SessionsManager
+	TwoFactorManager service.TwoFactorManager
+	LoginAttempts    service.LoginAttemptsManager
 }
 
 func NewUsersServer(deps UsersServerDependencies) *UsersServer {
 	return &UsersServer{
-		config:       deps.Config,
-		usersManager: deps.UsersManager,
+		config:           deps.Config,
+		usersManager:     deps.UsersManager,
+		sessionsManager:  deps.SessionsManager,
+		twoFactorManager: deps.TwoFactorManager,
+		loginAttempts:    deps.LoginAttempts,
 	}
 }
 
+// KDF params of account, requested before login. Unknown logins get stable fake params of account the server
+// would register now (see config.AccountKDF and config.AccountSRP), so the answer does not tell whether account
+// exists. Legacy accounts are reported to let client upgrade them.
+func (s *UsersServer) GetKDFV1(ctx context.Context, in *pb.GetKDFRequestV1) (*pb.GetKDFResponseV1, error) {
+	user, err := s.findUser(ctx, in.Login)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if user.Legacy() {
+		return &pb.GetKDFResponseV1{Legacy: true}, nil
+	}
+
+	return &pb.GetKDFResponseV1{Kdf: convert.KDFToProto(user.AccountKDF), Srp: user.UsesSRP()}, nil
+}
+
 func (s *UsersServer) RegisterV1(ctx context.Context, in *pb.RegisterRequestV1) (*pb.RegisterResponseV1, error) {
 	var response pb.RegisterResponseV1
 
-	// Register user
-	user, err := s.usersManager.RegisterUser(ctx, in.Login, in.Password)
+	var (
+		user *models.User
+		err  error
+	)
+
+	// Register user, with SRP verifier if client sent one
+	if in.Srp != nil {
+		user, err = s.usersManager.RegisterSRPUser(ctx, in.Login, convert.ProtoToKDF(in.Kdf), convert.ProtoToSRP(in.Srp))
+	} else {
+		user, err = s.usersManager.RegisterUser(ctx, in.Login, in.AuthKey, convert.ProtoToKDF(in.Kdf))
+	}
 
 	// Check if user exists
 	if errors.Is(err, entities.ErrUserAlreadyExists) {
 		return nil, status.Error(codes.AlreadyExists, err.Error())
 	}
 
+	// Missing auth key, e.g. from client sending password
+	if errors.Is(err, service.ErrBadAuthKey) || errors.Is(err, models.ErrBadKDF) || errors.Is(err, models.ErrBadSRP) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
 	// Other errors
 	if err != nil && !errors.Is(err, entities.ErrUserNotFound) {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Generate access token
-	token, err := s.authUser(user.ID)
+	// Start session
+	tokens, err := s.authUser(ctx, user.ID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to auth: %v", err)
 	}
 
-	response.AccessToken = token
+	response.AccessToken, response.RefreshToken = tokens.AccessToken, tokens.RefreshToken
 
 	return &response, nil
 }
 
+// Login with auth key, or with password for legacy accounts. Accounts with second factor get challenge
+// for LoginTOTPV1 instead of tokens. Locked out login or address gets codes.ResourceExhausted with retry delay.
 func (s *UsersServer) LoginV1(ctx context.Context, in *pb.LoginRequestV1) (*pb.LoginResponseV1, error) {
-	var response pb.LoginResponseV1
+	var (
+		response pb.LoginResponseV1
+		user     *models.User
+		err      error
+	)
+
+	if err := s.checkAttempts(ctx, in.Login); err != nil {
+		return nil, err
+	}
 
 	// Login user
-	user, err := s.usersManager.LoginUser(ctx, in.Login, in.Password)
+	if len(in.AuthKey) > 0 {
+		user, err = s.usersManager.LoginUser(ctx, in.Login, in.AuthKey)
+	} else {
+		user, err = s.usersManager.LoginLegacyUser(ctx, in.Login, in.Password)
+	}
 
 	// Check credentials
 	if errors.Is(err, entities.ErrBadCredentials) {
@@ -85,24 +150,389 @@ func (s *UsersServer) LoginV1(ctx context.Context, in *pb.LoginRequestV1) (*pb.L
 		return nil, status.Error(codes.Unauthenticated, err.Error())
 	}
 
-	// Generate access token
-	token, err := s.authUser(user.ID)
+	// Ask for one-time code, failures are forgotten once it is accepted
+	challenge, err := s.twoFactorManager.Challenge(ctx, user)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if challenge != "" {
+		return &pb.LoginResponseV1{TotpChallenge: challenge}, nil
+	}
+
+	if err := s.succeedAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	// Start session
+	tokens, err := s.authUser(ctx, user.ID)
 	if err != nil {
 		return nil, status.Errorf(codes.Internal, "failed to auth: %v", err)
 	}
 
-	response.AccessToken = token
+	response.AccessToken, response.RefreshToken = tokens.AccessToken, tokens.RefreshToken
 
 	return &response, nil
 }
 
-func (s *UsersServer) authUser(userID int) (string, error) {
-	token, err := auth.CreateToken(userID, time.Now().Add(tokenLifetime), []byte(s.config.SecretKey))
+// Switch legacy account of logged in user to auth key. Current password is required and counted like a login,
+// so stolen access token cannot take account over. All secrets come re-encrypted with new encryption key,
+// changed or missing ones abort upgrade with codes.Aborted. Sessions on other devices are ended.
+func (s *UsersServer) UpgradeAuthV1(ctx context.Context, in *pb.UpgradeAuthRequestV1) (*pb.UpgradeAuthResponseV1, error) {
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAttempts(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	err = s.usersManager.UpgradeAuth(ctx, user, in.Password, in.AuthKey, convert.ProtoToKDF(in.Kdf), convert.ProtoToPayloads(in.Secrets))
+
+	switch {
+	case errors.Is(err, entities.ErrBadCredentials):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, service.ErrBadAuthKey), errors.Is(err, models.ErrBadKDF):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entities.ErrAlreadyUpgraded):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, entities.ErrIncompleteUpgrade):
+		return nil, status.Error(codes.Aborted, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.succeedAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	sessionID := extractSessionID(ctx)
+	if err := s.sessionsManager.RevokeOthers(ctx, user.ID, sessionID); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Old access token carries no proof of new auth key, session goes on with a fresh one
+	token, err := s.sessionsManager.Reissue(user.ID, sessionID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to auth: %v", err)
+	}
+
+	return &pb.UpgradeAuthResponseV1{AccessToken: token}, nil
+}
+
+// First step of SRP login. Unknown logins and accounts not on SRP go through it with made-up verifier
+// and fail at second step, like wrong password.
+func (s *UsersServer) LoginSRPStartV1(ctx context.Context, in *pb.LoginSRPStartRequestV1) (*pb.LoginSRPStartResponseV1, error) {
+	if err := s.checkAttempts(ctx, in.Login); err != nil {
+		return nil, err
+	}
+
+	user, err := s.findUser(ctx, in.Login)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Accounts not on SRP fail the same way as unknown logins, not right away
+	if !user.UsesSRP() {
+		user = s.fakeUser(in.Login, true)
+	}
+
+	challenge, err := s.usersManager.StartSRP(ctx, user, in.A)
+
+	switch {
+	case errors.Is(err, srp.ErrBadPublicKey):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entities.ErrBadCredentials):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.LoginSRPStartResponseV1{SessionId: challenge.SessionID, Salt: challenge.Salt, B: challenge.ServerKey}, nil
+}
+
+// Second step of SRP login: client proof is checked, server proof lets client make sure it talks to real server
+func (s *UsersServer) LoginSRPFinishV1(ctx context.Context, in *pb.LoginSRPFinishRequestV1) (*pb.LoginSRPFinishResponseV1, error) {
+	user, proof, err := s.usersManager.FinishSRP(ctx, in.SessionId, in.M1)
+	if errors.Is(err, entities.ErrBadCredentials) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// Server proof goes along with challenge, so client checks it before sending code
+	challenge, err := s.twoFactorManager.Challenge(ctx, user)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if challenge != "" {
+		return &pb.LoginSRPFinishResponseV1{M2: proof, TotpChallenge: challenge}, nil
+	}
+
+	if err := s.succeedAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.authUser(ctx, user.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to auth: %v", err)
+	}
+
+	return &pb.LoginSRPFinishResponseV1{M2: proof, AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// Switch account of logged in user to SRP. Caller proves it knows current auth key, or for SRP account sends
+// client proof for fresh handshake; wrong proof is counted like failed login. Sessions on other devices are ended.
+func (s *UsersServer) EnrollSRPV1(ctx context.Context, in *pb.EnrollSRPRequestV1) (*pb.EnrollSRPResponseV1, error) {
+	// Access token alone never replaces credentials
+	if len(in.AuthKey) == 0 && in.SessionId == "" {
+		return nil, status.Error(codes.Unauthenticated, entities.ErrBadCredentials.Error())
+	}
+
+	user, err := s.currentUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAttempts(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	proof := service.CredentialProof{AuthKey: in.AuthKey, SessionID: in.SessionId, ClientProof: in.M1}
+	serverProof, err := s.usersManager.EnrollSRP(ctx, user, proof, convert.ProtoToSRP(in.Srp))
+
+	switch {
+	case errors.Is(err, models.ErrBadSRP):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, entities.ErrLegacyAccount):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, entities.ErrBadCredentials):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if err := s.succeedAttempt(ctx, user.Login); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionsManager.RevokeOthers(ctx, user.ID, extractSessionID(ctx)); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.EnrollSRPResponseV1{M2: serverProof}, nil
+}
+
+// Exchange refresh token for new token pair. Ended sessions and reuse of replaced refresh token, which
+// ends session too, yield codes.PermissionDenied so client does not silently log in again.
+func (s *UsersServer) RefreshV1(ctx context.Context, in *pb.RefreshRequestV1) (*pb.RefreshResponseV1, error) {
+	_, address := sessionInfo(ctx)
+
+	tokens, err := s.sessionsManager.Refresh(ctx, in.RefreshToken, address)
+
+	switch {
+	case errors.Is(err, entities.ErrSessionRevoked), errors.Is(err, entities.ErrRefreshReused):
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, entities.ErrSessionNotFound):
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.RefreshResponseV1{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}, nil
+}
+
+// Active sessions of user, the caller's one marked
+func (s *UsersServer) ListSessionsV1(ctx context.Context, _ *emptypb.Empty) (*pb.ListSessionsResponseV1, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	sessions, err := s.sessionsManager.List(ctx, int(userID), extractSessionID(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.ListSessionsResponseV1{Sessions: convert.SessionsToProto(sessions)}, nil
+}
+
+// End session of user on another device
+func (s *UsersServer) RevokeSessionV1(ctx context.Context, in *pb.RevokeSessionRequestV1) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.revokeSession(ctx, int(userID), in.Id)
+}
+
+// End session of the caller
+func (s *UsersServer) LogoutV1(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return s.revokeSession(ctx, int(userID), extractSessionID(ctx))
+}
+
+func (s *UsersServer) revokeSession(ctx context.Context, userID int, sessionID uint64) (*emptypb.Empty, error) {
+	err := s.sessionsManager.Revoke(ctx, userID, sessionID)
+	if errors.Is(err, entities.ErrSessionNotFound) {
+		return nil, status.Error(codes.NotFound, err.Error())
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// Count attempt of the caller before credentials are checked, refused while login or address is locked out
+func (s *UsersServer) checkAttempts(ctx context.Context, login string) error {
+	_, address := sessionInfo(ctx)
+
+	err := s.loginAttempts.Check(ctx, login, address)
+
+	var locked *entities.LoginLockedError
+	if errors.As(err, &locked) {
+		return lockedError(locked)
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+// Count failed login of the caller that checkAttempts did not count, returns error for the client
+func (s *UsersServer) failAttempt(ctx context.Context, login string, failure error) error {
+	_, address := sessionInfo(ctx)
+
+	if err := s.loginAttempts.Fail(ctx, login, address); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return status.Error(codes.Unauthenticated, failure.Error())
+}
+
+// Caller proved credentials, attempt counted by checkAttempts is not a failure
+func (s *UsersServer) succeedAttempt(ctx context.Context, login string) error {
+	_, address := sessionInfo(ctx)
+
+	if err := s.loginAttempts.Succeed(ctx, login, address); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+// Logged in user passed check without proving credentials, its attempt is not a failure
+func (s *UsersServer) releaseAttempt(ctx context.Context, login string) error {
+	_, address := sessionInfo(ctx)
+
+	if err := s.loginAttempts.Release(ctx, login, address); err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	return nil
+}
+
+// Locked out login, client learns how long to wait from RetryInfo details
+func lockedError(locked *entities.LoginLockedError) error {
+	st := status.New(codes.ResourceExhausted, locked.Error())
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(locked.RetryAfter)}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+// Start session of logged in user on device of the caller
+func (s *UsersServer) authUser(ctx context.Context, userID int) (service.Tokens, error) {
+	device, address := sessionInfo(ctx)
+
+	return s.sessionsManager.Open(ctx, userID, device, address)
+}
+
+// Account of the caller's access token
+func (s *UsersServer) currentUser(ctx context.Context) (*models.User, error) {
+	userID, err := extractUserID(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	user, err := s.usersManager.GetUser(ctx, int(userID))
+	if errors.Is(err, entities.ErrUserNotFound) {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
 	if err != nil {
-		return "", err
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return token, nil
+	return user, nil
+}
+
+// User of login, or stand-in with ID 0 for unknown login
+func (s *UsersServer) findUser(ctx context.Context, login string) (*models.User, error) {
+	user, err := s.usersManager.GetKDF(ctx, login)
+	if errors.Is(err, entities.ErrUserNotFound) {
+		return s.fakeUser(login, s.config.AccountSRP), nil
+	}
+
+	return user, err
+}
+
+// Account that would be registered with login, with SRP verifier if withSRP. Its salts and verifier are derived
+// from server secret so they stay the same between requests.
+func (s *UsersServer) fakeUser(login string, withSRP bool) *models.User {
+	derive := func(purpose string) []byte {
+		mac := hmac.New(sha256.New, []byte(s.config.SecretKey))
+		mac.Write([]byte(purpose + "\n" + login))
+		return mac.Sum(nil)
+	}
+
+	kdf := s.config.AccountKDF
+	kdf.Salt = derive("kdf-salt")[:fakeSaltLen]
+
+	user := &models.User{Login: login, AccountKDF: kdf}
+
+	if withSRP {
+		user.SRPSalt = derive("srp-salt")[:fakeSaltLen]
+		user.SRPVerifier = srp.Verifier(login, derive("srp-key"), user.SRPSalt)
+	}
+
+	return user
+}
+
+// Device name reported by client and its network address
+func sessionInfo(ctx context.Context) (string, string) {
+	var device, address string
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(constants.DeviceHeader); len(values) > 0 {
+			device = values[0]
+		}
+	}
+
+	if len(device) > maxDeviceLen {
+		device = strings.ToValidUTF8(device[:maxDeviceLen], "")
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		address = p.Addr.String()
+	}
+
+	return device, address
+}
+
+// Session of access token, 0 when request was not authenticated
+func extractSessionID(ctx context.Context) uint64 {
+	sid, _ := ctx.Value(constants.CtxSessionIDKey).(uint64)
+
+	return sid
 }
 
 func extractClientID(ctx context.Context) (uint64, error) {
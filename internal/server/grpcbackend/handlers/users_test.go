@@ -1,18 +1,31 @@
 package grpchandlers
 
 import (
+	"bytes"
 	"context"
+	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"gophkeeper/internal/server/config"
 	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/service"
+	"gophkeeper/pkg/constants"
+	"gophkeeper/pkg/convert"
 	"gophkeeper/pkg/models"
 	"gophkeeper/pkg/proto/keeper/grpcapi"
+	"gophkeeper/pkg/srp"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 // MockUsersManager is a mock implementation of the UsersManager interface.
@@ -20,20 +33,305 @@ type MockUsersManager struct {
 	mock.Mock
 }
 
-func (m *MockUsersManager) RegisterUser(ctx context.Context, login, password string) (*models.User, error) {
-	args := m.Called(ctx, login, password)
+func (m *MockUsersManager) GetKDF(ctx context.Context, login string) (*models.User, error) {
+	args := m.Called(ctx, login)
 	user, _ := args.Get(0).(*models.User)
 
 	return user, args.Error(1)
 }
 
-func (m *MockUsersManager) LoginUser(ctx context.Context, login, password string) (*models.User, error) {
+func (m *MockUsersManager) RegisterUser(ctx context.Context, login string, authKey []byte, kdf models.AccountKDF) (*models.User, error) {
+	args := m.Called(ctx, login, authKey, kdf)
+	user, _ := args.Get(0).(*models.User)
+
+	return user, args.Error(1)
+}
+
+func (m *MockUsersManager) LoginUser(ctx context.Context, login string, authKey []byte) (*models.User, error) {
+	args := m.Called(ctx, login, authKey)
+	user, _ := args.Get(0).(*models.User)
+
+	return user, args.Error(1)
+}
+
+func (m *MockUsersManager) LoginLegacyUser(ctx context.Context, login, password string) (*models.User, error) {
 	args := m.Called(ctx, login, password)
 	user, _ := args.Get(0).(*models.User)
 
 	return user, args.Error(1)
 }
 
+func (m *MockUsersManager) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	args := m.Called(ctx, userID)
+	user, _ := args.Get(0).(*models.User)
+
+	return user, args.Error(1)
+}
+
+func (m *MockUsersManager) UpgradeAuth(ctx context.Context, user *models.User, password string, authKey []byte, kdf models.AccountKDF, secrets []models.SecretPayload) error {
+	args := m.Called(ctx, user, password, authKey, kdf, secrets)
+
+	return args.Error(0)
+}
+
+func (m *MockUsersManager) RegisterSRPUser(ctx context.Context, login string, kdf models.AccountKDF, verifier models.SRPVerifier) (*models.User, error) {
+	args := m.Called(ctx, login, kdf, verifier)
+	user, _ := args.Get(0).(*models.User)
+
+	return user, args.Error(1)
+}
+
+func (m *MockUsersManager) StartSRP(ctx context.Context, user *models.User, clientKey []byte) (service.SRPChallenge, error) {
+	args := m.Called(ctx, user, clientKey)
+
+	return args.Get(0).(service.SRPChallenge), args.Error(1)
+}
+
+func (m *MockUsersManager) FinishSRP(ctx context.Context, sessionID string, proof []byte) (*models.User, []byte, error) {
+	args := m.Called(ctx, sessionID, proof)
+	user, _ := args.Get(0).(*models.User)
+	serverProof, _ := args.Get(1).([]byte)
+
+	return user, serverProof, args.Error(2)
+}
+
+func (m *MockUsersManager) EnrollSRP(ctx context.Context, user *models.User, proof service.CredentialProof, verifier models.SRPVerifier) ([]byte, error) {
+	args := m.Called(ctx, user, proof, verifier)
+	serverProof, _ := args.Get(0).([]byte)
+
+	return serverProof, args.Error(1)
+}
+
+// MockSessionsManager is a mock implementation of the SessionsManager interface.
+type MockSessionsManager struct {
+	mock.Mock
+}
+
+func (m *MockSessionsManager) Open(ctx context.Context, userID int, device string, address string) (service.Tokens, error) {
+	args := m.Called(ctx, userID, device, address)
+
+	return args.Get(0).(service.Tokens), args.Error(1)
+}
+
+func (m *MockSessionsManager) Refresh(ctx context.Context, refreshToken string, address string) (service.Tokens, error) {
+	args := m.Called(ctx, refreshToken, address)
+
+	return args.Get(0).(service.Tokens), args.Error(1)
+}
+
+func (m *MockSessionsManager) Reissue(userID int, sessionID uint64) (string, error) {
+	args := m.Called(userID, sessionID)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSessionsManager) Check(ctx context.Context, userID int, sessionID uint64) error {
+	args := m.Called(ctx, userID, sessionID)
+
+	return args.Error(0)
+}
+
+func (m *MockSessionsManager) List(ctx context.Context, userID int, currentID uint64) ([]models.Session, error) {
+	args := m.Called(ctx, userID, currentID)
+	sessions, _ := args.Get(0).([]models.Session)
+
+	return sessions, args.Error(1)
+}
+
+func (m *MockSessionsManager) Revoke(ctx context.Context, userID int, sessionID uint64) error {
+	args := m.Called(ctx, userID, sessionID)
+
+	return args.Error(0)
+}
+
+func (m *MockSessionsManager) RevokeOthers(ctx context.Context, userID int, keepID uint64) error {
+	args := m.Called(ctx, userID, keepID)
+
+	return args.Error(0)
+}
+
+// MockTwoFactorManager is a mock implementation of the TwoFactorManager interface.
+type MockTwoFactorManager struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorManager) Status(ctx context.Context, userID int) (models.TwoFactorStatus, error) {
+	args := m.Called(ctx, userID)
+
+	return args.Get(0).(models.TwoFactorStatus), args.Error(1)
+}
+
+func (m *MockTwoFactorManager) Enroll(ctx context.Context, userID int) (models.TOTPEnrollment, error) {
+	args := m.Called(ctx, userID)
+
+	return args.Get(0).(models.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockTwoFactorManager) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	codes, _ := args.Get(0).([]string)
+
+	return codes, args.Error(1)
+}
+
+func (m *MockTwoFactorManager) Disable(ctx context.Context, userID int, code string) error {
+	args := m.Called(ctx, userID, code)
+
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorManager) Challenge(ctx context.Context, user *models.User) (string, error) {
+	args := m.Called(ctx, user.ID)
+
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockTwoFactorManager) Verify(ctx context.Context, challenge string, code string) (*models.User, error) {
+	args := m.Called(ctx, challenge, code)
+	user, _ := args.Get(0).(*models.User)
+
+	return user, args.Error(1)
+}
+
+// Second factor manager of accounts without second factor
+func testTwoFactor() *MockTwoFactorManager {
+	twoFactor := new(MockTwoFactorManager)
+	twoFactor.On("Challenge", mock.Anything, mock.Anything).Return("", nil)
+
+	return twoFactor
+}
+
+type MockLoginAttemptsManager struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptsManager) Check(ctx context.Context, login string, address string) error {
+	args := m.Called(ctx, login, address)
+
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsManager) Fail(ctx context.Context, login string, address string) error {
+	args := m.Called(ctx, login, address)
+
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsManager) Succeed(ctx context.Context, login string, address string) error {
+	args := m.Called(ctx, login, address)
+
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsManager) Release(ctx context.Context, login string, address string) error {
+	args := m.Called(ctx, login, address)
+
+	return args.Error(0)
+}
+
+// Brute-force protection letting every login through
+func testLoginAttempts() *MockLoginAttemptsManager {
+	attempts := new(MockLoginAttemptsManager)
+	attempts.On("Check", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	attempts.On("Fail", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	attempts.On("Succeed", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	attempts.On("Release", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	return attempts
+}
+
+// Server config registering SRP accounts with default params
+func testConfig() *config.Config {
+	return &config.Config{
+		SecretKey:  "test-secret-key",
+		AccountKDF: models.AccountKDF{Time: config.DefaultKDFTime, Memory: config.DefaultKDFMemory, Threads: config.DefaultKDFThreads},
+		AccountSRP: true,
+	}
+}
+
+var testTokens = service.Tokens{AccessToken: "access", RefreshToken: "refresh"}
+
+// Sessions manager opening sessions for any user
+func testSessions() *MockSessionsManager {
+	sessions := new(MockSessionsManager)
+	sessions.On("Open", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(testTokens, nil)
+	sessions.On("Reissue", mock.Anything, mock.Anything).Return("reissued", nil)
+	sessions.On("RevokeOthers", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+
+	return sessions
+}
+
+var (
+	testAuthKey = bytes.Repeat([]byte{1}, service.AuthKeyLen)
+	testKDF     = models.AccountKDF{Salt: make([]byte, models.MinKDFSaltLen), Time: 3, Memory: models.MinKDFMemory, Threads: 1}
+)
+
+func TestUsersServer_GetKDFV1(t *testing.T) {
+	ctx := context.Background()
+	mockUsersManager := new(MockUsersManager)
+	usersServer := NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     mockUsersManager,
+		SessionsManager:  testSessions(),
+		TwoFactorManager: testTwoFactor(),
+		LoginAttempts:    testLoginAttempts(),
+	})
+
+	mockUsersManager.On("GetKDF", ctx, "testuser").Return(&models.User{ID: 1, AccountKDF: testKDF}, nil)
+	mockUsersManager.On("GetKDF", ctx, "legacy").Return(&models.User{ID: 2}, nil)
+	mockUsersManager.On("GetKDF", ctx, mock.Anything).Return(nil, entities.ErrUserNotFound)
+
+	t.Run("Account params", func(t *testing.T) {
+		response, err := usersServer.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "testuser"})
+		assert.NoError(t, err)
+		assert.False(t, response.Legacy)
+		assert.False(t, response.Srp)
+		assert.Equal(t, testKDF, convert.ProtoToKDF(response.Kdf))
+	})
+
+	t.Run("Legacy account", func(t *testing.T) {
+		response, err := usersServer.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "legacy"})
+		assert.NoError(t, err)
+		assert.True(t, response.Legacy)
+		assert.Nil(t, response.Kdf)
+	})
+
+	t.Run("Unknown login gets stable valid params", func(t *testing.T) {
+		first, err := usersServer.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "nobody"})
+		assert.NoError(t, err)
+		assert.False(t, first.Legacy)
+		assert.True(t, first.Srp)
+		assert.NoError(t, convert.ProtoToKDF(first.Kdf).Validate())
+
+		again, err := usersServer.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "nobody"})
+		assert.NoError(t, err)
+		assert.Equal(t, first.Kdf.Salt, again.Kdf.Salt)
+
+		other, err := usersServer.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "somebody"})
+		assert.NoError(t, err)
+		assert.NotEqual(t, first.Kdf.Salt, other.Kdf.Salt)
+	})
+
+	t.Run("Unknown login follows server profile", func(t *testing.T) {
+		cfg := testConfig()
+		cfg.AccountSRP = false
+		cfg.AccountKDF.Time = 5
+
+		server := NewUsersServer(UsersServerDependencies{
+			Config:           cfg,
+			UsersManager:     mockUsersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    testLoginAttempts(),
+		})
+
+		response, err := server.GetKDFV1(ctx, &grpcapi.GetKDFRequestV1{Login: "nobody"})
+		assert.NoError(t, err)
+		assert.False(t, response.Srp)
+		assert.Equal(t, uint32(5), response.Kdf.Time)
+	})
+}
+
 func TestUsersServer_RegisterV1(t *testing.T) {
 	ctx := context.Background()
 
@@ -42,21 +340,26 @@ func TestUsersServer_RegisterV1(t *testing.T) {
 		mockConfig := &config.Config{SecretKey: "test-secret-key"}
 
 		usersServer := NewUsersServer(UsersServerDependencies{
-			Config:       mockConfig,
-			UsersManager: mockUsersManager,
+			Config:           mockConfig,
+			UsersManager:     mockUsersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    testLoginAttempts(),
 		})
 
-		mockUsersManager.On("RegisterUser", ctx, "testuser", "password").Return(&models.User{ID: 1}, nil)
+		mockUsersManager.On("RegisterUser", ctx, "testuser", testAuthKey, testKDF).Return(&models.User{ID: 1}, nil)
 
 		response, err := usersServer.RegisterV1(ctx, &grpcapi.RegisterRequestV1{
-			Login:    "testuser",
-			Password: "password",
+			Login:   "testuser",
+			AuthKey: testAuthKey,
+			Kdf:     convert.KDFToProto(testKDF),
 		})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
-		assert.NotEmpty(t, response.AccessToken)
-		mockUsersManager.AssertCalled(t, "RegisterUser", ctx, "testuser", "password")
+		assert.Equal(t, testTokens.AccessToken, response.AccessToken)
+		assert.Equal(t, testTokens.RefreshToken, response.RefreshToken)
+		mockUsersManager.AssertCalled(t, "RegisterUser", ctx, "testuser", testAuthKey, testKDF)
 	})
 
 	t.Run("User already exists", func(t *testing.T) {
@@ -64,21 +367,197 @@ func TestUsersServer_RegisterV1(t *testing.T) {
 		mockConfig := &config.Config{SecretKey: "test-secret-key"}
 
 		usersServer := NewUsersServer(UsersServerDependencies{
-			Config:       mockConfig,
-			UsersManager: mockUsersManager,
+			Config:           mockConfig,
+			UsersManager:     mockUsersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    testLoginAttempts(),
 		})
 
-		mockUsersManager.On("RegisterUser", ctx, "testuser", "password").Return(nil, entities.ErrUserAlreadyExists)
+		mockUsersManager.On("RegisterUser", ctx, "testuser", testAuthKey, testKDF).Return(nil, entities.ErrUserAlreadyExists)
 
 		response, err := usersServer.RegisterV1(ctx, &grpcapi.RegisterRequestV1{
-			Login:    "testuser",
-			Password: "password",
+			Login:   "testuser",
+			AuthKey: testAuthKey,
+			Kdf:     convert.KDFToProto(testKDF),
 		})
 
 		assert.Error(t, err)
 		assert.Nil(t, response)
 		assert.Equal(t, codes.AlreadyExists, status.Code(err))
-		mockUsersManager.AssertCalled(t, "RegisterUser", ctx, "testuser", "password")
+		mockUsersManager.AssertCalled(t, "RegisterUser", ctx, "testuser", testAuthKey, testKDF)
+	})
+}
+
+func TestUsersServer_RegisterSRP(t *testing.T) {
+	ctx := context.Background()
+	mockUsersManager := new(MockUsersManager)
+	usersServer := NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     mockUsersManager,
+		SessionsManager:  testSessions(),
+		TwoFactorManager: testTwoFactor(),
+		LoginAttempts:    testLoginAttempts(),
+	})
+
+	verifier := models.SRPVerifier{Salt: []byte("0123456789abcdef"), Verifier: []byte{2}}
+	mockUsersManager.On("RegisterSRPUser", ctx, "testuser", testKDF, verifier).Return(&models.User{ID: 1}, nil)
+	mockUsersManager.On("RegisterSRPUser", ctx, "baduser", testKDF, mock.Anything).Return(nil, models.ErrBadSRP)
+
+	response, err := usersServer.RegisterV1(ctx, &grpcapi.RegisterRequestV1{
+		Login: "testuser",
+		Kdf:   convert.KDFToProto(testKDF),
+		Srp:   convert.SRPToProto(verifier),
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, response.AccessToken)
+	mockUsersManager.AssertNotCalled(t, "RegisterUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	_, err = usersServer.RegisterV1(ctx, &grpcapi.RegisterRequestV1{
+		Login: "baduser",
+		Kdf:   convert.KDFToProto(testKDF),
+		Srp:   &grpcapi.SRPVerifier{},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUsersServer_LoginSRP(t *testing.T) {
+	ctx := context.Background()
+	mockUsersManager := new(MockUsersManager)
+	usersServer := NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     mockUsersManager,
+		SessionsManager:  testSessions(),
+		TwoFactorManager: testTwoFactor(),
+		LoginAttempts:    testLoginAttempts(),
+	})
+
+	user := &models.User{ID: 1, Login: "testuser", AccountKDF: testKDF, SRPSalt: []byte("salt"), SRPVerifier: []byte{2}}
+	challenge := service.SRPChallenge{SessionID: "session", Salt: []byte("salt"), ServerKey: []byte("B")}
+
+	mockUsersManager.On("GetKDF", ctx, "testuser").Return(user, nil)
+	mockUsersManager.On("GetKDF", ctx, "authkey").Return(&models.User{ID: 2, Login: "authkey", Password: "hash", AccountKDF: testKDF}, nil)
+	mockUsersManager.On("GetKDF", ctx, mock.Anything).Return(nil, entities.ErrUserNotFound)
+	mockUsersManager.On("StartSRP", ctx, user, []byte("A")).Return(challenge, nil)
+	mockUsersManager.On("StartSRP", ctx, mock.Anything, []byte("A")).Return(challenge, nil)
+	mockUsersManager.On("StartSRP", ctx, mock.Anything, []byte{0}).Return(service.SRPChallenge{}, srp.ErrBadPublicKey)
+	mockUsersManager.On("FinishSRP", ctx, "session", []byte("M1")).Return(user, []byte("M2"), nil)
+	mockUsersManager.On("FinishSRP", ctx, "session", mock.Anything).Return(nil, nil, entities.ErrBadCredentials)
+
+	t.Run("Start", func(t *testing.T) {
+		response, err := usersServer.LoginSRPStartV1(ctx, &grpcapi.LoginSRPStartRequestV1{Login: "testuser", A: []byte("A")})
+		require.NoError(t, err)
+		assert.Equal(t, "session", response.SessionId)
+		assert.Equal(t, []byte("B"), response.B)
+	})
+
+	t.Run("Unknown login goes through stand-in", func(t *testing.T) {
+		_, err := usersServer.LoginSRPStartV1(ctx, &grpcapi.LoginSRPStartRequestV1{Login: "nobody", A: []byte("A")})
+		require.NoError(t, err)
+
+		mockUsersManager.AssertCalled(t, "StartSRP", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.ID == 0 && u.Login == "nobody" && u.UsesSRP()
+		}), []byte("A"))
+	})
+
+	t.Run("Account not on SRP goes through stand-in", func(t *testing.T) {
+		_, err := usersServer.LoginSRPStartV1(ctx, &grpcapi.LoginSRPStartRequestV1{Login: "authkey", A: []byte("A")})
+		require.NoError(t, err)
+
+		mockUsersManager.AssertCalled(t, "StartSRP", ctx, mock.MatchedBy(func(u *models.User) bool {
+			return u.ID == 0 && u.Login == "authkey" && u.UsesSRP()
+		}), []byte("A"))
+	})
+
+	t.Run("Bad client key", func(t *testing.T) {
+		_, err := usersServer.LoginSRPStartV1(ctx, &grpcapi.LoginSRPStartRequestV1{Login: "testuser", A: []byte{0}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Finish", func(t *testing.T) {
+		response, err := usersServer.LoginSRPFinishV1(ctx, &grpcapi.LoginSRPFinishRequestV1{SessionId: "session", M1: []byte("M1")})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("M2"), response.M2)
+		assert.NotEmpty(t, response.AccessToken)
+	})
+
+	t.Run("Wrong proof", func(t *testing.T) {
+		_, err := usersServer.LoginSRPFinishV1(ctx, &grpcapi.LoginSRPFinishRequestV1{SessionId: "session", M1: []byte("wrong")})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestUsersServer_EnrollSRPV1(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+	ctx = context.WithValue(ctx, constants.CtxSessionIDKey, uint64(5))
+	user := &models.User{ID: 1, Login: "testuser", Password: "hash", AccountKDF: testKDF}
+
+	good := models.SRPVerifier{Salt: []byte("0123456789abcdef"), Verifier: []byte{2}}
+	legacy := models.SRPVerifier{Salt: []byte("fedcba9876543210"), Verifier: []byte{2}}
+	withKey := service.CredentialProof{AuthKey: testAuthKey}
+
+	newServer := func() (*UsersServer, *MockUsersManager, *MockSessionsManager, *MockLoginAttemptsManager) {
+		mockUsersManager := new(MockUsersManager)
+		sessions := testSessions()
+		attempts := testLoginAttempts()
+		usersServer := NewUsersServer(UsersServerDependencies{
+			Config:           testConfig(),
+			UsersManager:     mockUsersManager,
+			SessionsManager:  sessions,
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    attempts,
+		})
+
+		mockUsersManager.On("GetUser", ctx, 1).Return(user, nil)
+
+		return usersServer, mockUsersManager, sessions, attempts
+	}
+
+	t.Run("Enrolled", func(t *testing.T) {
+		usersServer, mockUsersManager, sessions, _ := newServer()
+		mockUsersManager.On("EnrollSRP", ctx, user, withKey, good).Return(nil, nil)
+
+		_, err := usersServer.EnrollSRPV1(ctx, &grpcapi.EnrollSRPRequestV1{Srp: convert.SRPToProto(good), AuthKey: testAuthKey})
+		assert.NoError(t, err)
+		sessions.AssertCalled(t, "RevokeOthers", ctx, 1, uint64(5))
+	})
+
+	t.Run("Token alone is rejected", func(t *testing.T) {
+		usersServer, mockUsersManager, sessions, _ := newServer()
+
+		_, err := usersServer.EnrollSRPV1(ctx, &grpcapi.EnrollSRPRequestV1{Srp: convert.SRPToProto(good)})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		mockUsersManager.AssertNotCalled(t, "EnrollSRP", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		sessions.AssertNotCalled(t, "RevokeOthers", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Wrong auth key is counted", func(t *testing.T) {
+		usersServer, mockUsersManager, sessions, attempts := newServer()
+		mockUsersManager.On("EnrollSRP", ctx, user, withKey, good).Return(nil, entities.ErrBadCredentials)
+
+		_, err := usersServer.EnrollSRPV1(ctx, &grpcapi.EnrollSRPRequestV1{Srp: convert.SRPToProto(good), AuthKey: testAuthKey})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+		attempts.AssertCalled(t, "Check", ctx, "testuser", mock.Anything)
+		attempts.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything, mock.Anything)
+		sessions.AssertNotCalled(t, "RevokeOthers", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("SRP handshake", func(t *testing.T) {
+		usersServer, mockUsersManager, _, _ := newServer()
+		proof := service.CredentialProof{SessionID: "session", ClientProof: []byte("M1")}
+		mockUsersManager.On("EnrollSRP", ctx, user, proof, good).Return([]byte("M2"), nil)
+
+		response, err := usersServer.EnrollSRPV1(ctx, &grpcapi.EnrollSRPRequestV1{Srp: convert.SRPToProto(good), SessionId: "session", M1: []byte("M1")})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("M2"), response.M2)
+	})
+
+	t.Run("Legacy account", func(t *testing.T) {
+		usersServer, mockUsersManager, _, _ := newServer()
+		mockUsersManager.On("EnrollSRP", ctx, user, withKey, legacy).Return(nil, entities.ErrLegacyAccount)
+
+		_, err := usersServer.EnrollSRPV1(ctx, &grpcapi.EnrollSRPRequestV1{Srp: convert.SRPToProto(legacy), AuthKey: testAuthKey})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
 	})
 }
 
@@ -90,21 +569,24 @@ func TestUsersServer_LoginV1(t *testing.T) {
 		mockConfig := &config.Config{SecretKey: "test-secret-key"}
 
 		usersServer := NewUsersServer(UsersServerDependencies{
-			Config:       mockConfig,
-			UsersManager: mockUsersManager,
+			Config:           mockConfig,
+			UsersManager:     mockUsersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    testLoginAttempts(),
 		})
 
-		mockUsersManager.On("LoginUser", ctx, "testuser", "password").Return(&models.User{ID: 1}, nil)
+		mockUsersManager.On("LoginUser", ctx, "testuser", testAuthKey).Return(&models.User{ID: 1}, nil)
 
 		response, err := usersServer.LoginV1(ctx, &grpcapi.LoginRequestV1{
-			Login:    "testuser",
-			Password: "password",
+			Login:   "testuser",
+			AuthKey: testAuthKey,
 		})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, response)
 		assert.NotEmpty(t, response.AccessToken)
-		mockUsersManager.AssertCalled(t, "LoginUser", ctx, "testuser", "password")
+		mockUsersManager.AssertCalled(t, "LoginUser", ctx, "testuser", testAuthKey)
 	})
 
 	t.Run("Invalid credentials", func(t *testing.T) {
@@ -112,11 +594,14 @@ func TestUsersServer_LoginV1(t *testing.T) {
 		mockConfig := &config.Config{SecretKey: "test-secret-key"}
 
 		usersServer := NewUsersServer(UsersServerDependencies{
-			Config:       mockConfig,
-			UsersManager: mockUsersManager,
+			Config:           mockConfig,
+			UsersManager:     mockUsersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: testTwoFactor(),
+			LoginAttempts:    testLoginAttempts(),
 		})
 
-		mockUsersManager.On("LoginUser", ctx, "testuser", "wrongpassword").Return(nil, entities.ErrBadCredentials)
+		mockUsersManager.On("LoginLegacyUser", ctx, "testuser", "wrongpassword").Return(nil, entities.ErrBadCredentials)
 
 		response, err := usersServer.LoginV1(ctx, &grpcapi.LoginRequestV1{
 			Login:    "testuser",
@@ -126,6 +611,232 @@ func TestUsersServer_LoginV1(t *testing.T) {
 		assert.Error(t, err)
 		assert.Nil(t, response)
 		assert.Equal(t, codes.Unauthenticated, status.Code(err))
-		mockUsersManager.AssertCalled(t, "LoginUser", ctx, "testuser", "wrongpassword")
+		mockUsersManager.AssertCalled(t, "LoginLegacyUser", ctx, "testuser", "wrongpassword")
+	})
+}
+
+func TestUsersServer_LoginAttempts(t *testing.T) {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 5000}})
+
+	newServer := func(attempts *MockLoginAttemptsManager) (*UsersServer, *MockUsersManager, *MockTwoFactorManager) {
+		usersManager, twoFactor := new(MockUsersManager), new(MockTwoFactorManager)
+
+		return NewUsersServer(UsersServerDependencies{
+			Config:           testConfig(),
+			UsersManager:     usersManager,
+			SessionsManager:  testSessions(),
+			TwoFactorManager: twoFactor,
+			LoginAttempts:    attempts,
+		}), usersManager, twoFactor
+	}
+
+	t.Run("Locked out", func(t *testing.T) {
+		attempts := new(MockLoginAttemptsManager)
+		attempts.On("Check", ctx, "testuser", "10.0.0.1:5000").Return(entities.ErrorLoginLocked(90 * time.Second))
+		server, usersManager, _ := newServer(attempts)
+
+		_, err := server.LoginV1(ctx, &grpcapi.LoginRequestV1{Login: "testuser", AuthKey: testAuthKey})
+		require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		// Client learns how long to wait
+		details := status.Convert(err).Details()
+		require.Len(t, details, 1)
+		assert.Equal(t, 90*time.Second, details[0].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+
+		// Credentials are not even checked
+		usersManager.AssertNotCalled(t, "LoginUser", mock.Anything, mock.Anything, mock.Anything)
+
+		_, err = server.LoginSRPStartV1(ctx, &grpcapi.LoginSRPStartRequestV1{Login: "testuser", A: []byte{1}})
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	})
+
+	t.Run("Failures stay counted", func(t *testing.T) {
+		attempts := testLoginAttempts()
+		server, usersManager, twoFactor := newServer(attempts)
+
+		usersManager.On("LoginUser", ctx, "testuser", testAuthKey).Return(nil, entities.ErrBadCredentials)
+		usersManager.On("FinishSRP", ctx, "session", []byte("M1")).Return(&models.User{ID: 1, Login: "srpuser"}, nil, entities.ErrBadCredentials)
+		usersManager.On("FinishSRP", ctx, "expired", []byte("M1")).Return(nil, nil, entities.ErrBadCredentials)
+		twoFactor.On("Verify", ctx, "challenge", "000000").Return(&models.User{ID: 2, Login: "totpuser"}, entities.ErrBadTOTPCode)
+
+		_, err := server.LoginV1(ctx, &grpcapi.LoginRequestV1{Login: "testuser", AuthKey: testAuthKey})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = server.LoginSRPFinishV1(ctx, &grpcapi.LoginSRPFinishRequestV1{SessionId: "session", M1: []byte("M1")})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = server.LoginSRPFinishV1(ctx, &grpcapi.LoginSRPFinishRequestV1{SessionId: "expired", M1: []byte("M1")})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		_, err = server.LoginTOTPV1(ctx, &grpcapi.LoginTOTPRequestV1{Challenge: "challenge", Code: "000000"})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+		// Password and SRP attempts were counted before credentials were checked, one-time code after
+		attempts.AssertCalled(t, "Check", ctx, "testuser", "10.0.0.1:5000")
+		attempts.AssertCalled(t, "Fail", ctx, "totpuser", "10.0.0.1:5000")
+		attempts.AssertNumberOfCalls(t, "Fail", 1)
+		attempts.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Reset once fully logged in", func(t *testing.T) {
+		attempts := testLoginAttempts()
+		server, usersManager, twoFactor := newServer(attempts)
+
+		usersManager.On("LoginUser", ctx, "testuser", testAuthKey).Return(&models.User{ID: 1, Login: "testuser"}, nil)
+		twoFactor.On("Challenge", ctx, 1).Return("challenge", nil)
+		twoFactor.On("Verify", ctx, "challenge", "123456").Return(&models.User{ID: 1, Login: "testuser"}, nil)
+
+		// Password alone does not reset failures of account with second factor
+		_, err := server.LoginV1(ctx, &grpcapi.LoginRequestV1{Login: "testuser", AuthKey: testAuthKey})
+		require.NoError(t, err)
+		attempts.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything, mock.Anything)
+
+		_, err = server.LoginTOTPV1(ctx, &grpcapi.LoginTOTPRequestV1{Challenge: "challenge", Code: "123456"})
+		require.NoError(t, err)
+		attempts.AssertCalled(t, "Succeed", ctx, "testuser", "10.0.0.1:5000")
+	})
+}
+
+func TestUsersServer_UpgradeAuthV1(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+	ctx = context.WithValue(ctx, constants.CtxSessionIDKey, uint64(5))
+	user := &models.User{ID: 1, Login: "legacy"}
+	secrets := []models.SecretPayload{{ID: 1, Revision: 2, Payload: []byte("payload")}}
+	request := &grpcapi.UpgradeAuthRequestV1{
+		AuthKey:  testAuthKey,
+		Kdf:      convert.KDFToProto(testKDF),
+		Secrets:  convert.PayloadsToProto(secrets),
+		Password: "password",
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "Upgraded", code: codes.OK},
+		{name: "Wrong password", err: entities.ErrBadCredentials, code: codes.Unauthenticated},
+		{name: "Bad KDF", err: models.ErrBadKDF, code: codes.InvalidArgument},
+		{name: "Already upgraded", err: entities.ErrAlreadyUpgraded, code: codes.FailedPrecondition},
+		{name: "Secrets changed meanwhile", err: entities.ErrIncompleteUpgrade, code: codes.Aborted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockUsersManager := new(MockUsersManager)
+			sessions := testSessions()
+			attempts := testLoginAttempts()
+			usersServer := NewUsersServer(UsersServerDependencies{
+				Config:           testConfig(),
+				UsersManager:     mockUsersManager,
+				SessionsManager:  sessions,
+				TwoFactorManager: testTwoFactor(),
+				LoginAttempts:    attempts,
+			})
+
+			mockUsersManager.On("GetUser", ctx, 1).Return(user, nil)
+			mockUsersManager.On("UpgradeAuth", ctx, user, "password", testAuthKey, testKDF, secrets).Return(tt.err)
+
+			response, err := usersServer.UpgradeAuthV1(ctx, request)
+			assert.Equal(t, tt.code, status.Code(err))
+			attempts.AssertCalled(t, "Check", ctx, "legacy", mock.Anything)
+
+			// Attempt stays counted unless password was right
+			if tt.err == nil {
+				assert.NotEmpty(t, response.AccessToken)
+				sessions.AssertCalled(t, "RevokeOthers", ctx, 1, uint64(5))
+				attempts.AssertCalled(t, "Succeed", ctx, "legacy", mock.Anything)
+			} else {
+				sessions.AssertNotCalled(t, "RevokeOthers", mock.Anything, mock.Anything, mock.Anything)
+				attempts.AssertNotCalled(t, "Succeed", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestUsersServer_RefreshV1(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		err  error
+		code codes.Code
+	}{
+		{name: "Rotated", code: codes.OK},
+		{name: "Expired", err: entities.ErrSessionNotFound, code: codes.Unauthenticated},
+		{name: "Ended", err: entities.ErrSessionRevoked, code: codes.PermissionDenied},
+		{name: "Reused", err: entities.ErrRefreshReused, code: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessions := new(MockSessionsManager)
+			usersServer := NewUsersServer(UsersServerDependencies{
+				Config:           testConfig(),
+				UsersManager:     new(MockUsersManager),
+				SessionsManager:  sessions,
+				TwoFactorManager: testTwoFactor(),
+				LoginAttempts:    testLoginAttempts(),
+			})
+
+			sessions.On("Refresh", ctx, "old", "").Return(testTokens, tt.err)
+
+			response, err := usersServer.RefreshV1(ctx, &grpcapi.RefreshRequestV1{RefreshToken: "old"})
+			assert.Equal(t, tt.code, status.Code(err))
+			if tt.err == nil {
+				assert.Equal(t, testTokens.RefreshToken, response.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestUsersServer_Sessions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), constants.CtxUserIDKey, uint64(1))
+	ctx = context.WithValue(ctx, constants.CtxSessionIDKey, uint64(5))
+
+	sessions := new(MockSessionsManager)
+	usersServer := NewUsersServer(UsersServerDependencies{
+		Config:           testConfig(),
+		UsersManager:     new(MockUsersManager),
+		SessionsManager:  sessions,
+		TwoFactorManager: testTwoFactor(),
+		LoginAttempts:    testLoginAttempts(),
+	})
+
+	t.Run("List", func(t *testing.T) {
+		sessions.On("List", ctx, 1, uint64(5)).Return([]models.Session{{ID: 5, Device: "laptop", Current: true}, {ID: 6, Device: "phone"}}, nil)
+
+		response, err := usersServer.ListSessionsV1(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+		require.Len(t, response.Sessions, 2)
+		assert.True(t, response.Sessions[0].Current)
+		assert.Equal(t, "phone", response.Sessions[1].Device)
+	})
+
+	t.Run("Revoke other device", func(t *testing.T) {
+		sessions.On("Revoke", ctx, 1, uint64(6)).Return(nil)
+		sessions.On("Revoke", ctx, 1, uint64(7)).Return(entities.ErrSessionNotFound)
+
+		_, err := usersServer.RevokeSessionV1(ctx, &grpcapi.RevokeSessionRequestV1{Id: 6})
+		assert.NoError(t, err)
+
+		_, err = usersServer.RevokeSessionV1(ctx, &grpcapi.RevokeSessionRequestV1{Id: 7})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Logout ends current session", func(t *testing.T) {
+		sessions.On("Revoke", ctx, 1, uint64(5)).Return(nil)
+
+		_, err := usersServer.LogoutV1(ctx, &emptypb.Empty{})
+		assert.NoError(t, err)
+		sessions.AssertCalled(t, "Revoke", ctx, 1, uint64(5))
 	})
 }
+
+func TestSessionInfo(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(constants.DeviceHeader, strings.Repeat("d", maxDeviceLen+10)))
+
+	device, address := sessionInfo(ctx)
+	assert.Len(t, device, maxDeviceLen)
+	assert.Empty(t, address)
+}
@@ -12,8 +12,13 @@ import (
 	"google.golang.org/grpc/status"
 )
 
-// Checks auth token passed from context, returns new context with user id embedded
-func authContext(secretKey []byte, ctx context.Context) (context.Context, error) {
+// Tells whether login session of access token is still active
+type SessionChecker interface {
+	Check(ctx context.Context, userID int, sessionID uint64) error
+}
+
+// Checks auth token passed from context and its session, returns new context with user and session ids embedded
+func authContext(secretKey []byte, sessions SessionChecker, ctx context.Context) (context.Context, error) {
 	// Get token from metadata
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
@@ -46,24 +51,38 @@ func authContext(secretKey []byte, ctx context.Context) (context.Context, error)
 		return nil, status.Error(codes.Unauthenticated, "invalid user id in claims")
 	}
 
-	// Store user ID in context
+	// Tokens issued before sessions have no session id, client logs in again
+	sid, ok := tokenMap["session_id"].(float64)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "no session id in claims")
+	}
+
+	// Ended session makes its tokens invalid before they expire
+	if err := sessions.Check(ctx, int(userID), uint64(sid)); err != nil {
+		return nil, status.Errorf(codes.Unauthenticated, "session is not active: %s", err.Error())
+	}
+
+	// Store user and session IDs in context
 	ctx = context.WithValue(ctx, constants.CtxUserIDKey, uint64(userID))
+	ctx = context.WithValue(ctx, constants.CtxSessionIDKey, uint64(sid))
 
 	return ctx, nil
 }
 
 // Unary auth interceptor checks provided in metadata token
-func Authentication(secretKey []byte) grpc.UnaryServerInterceptor {
+func Authentication(secretKey []byte, sessions SessionChecker) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 
-		// Allow login and register methods
-		if strings.Contains(info.FullMethod, "RegisterV1") || strings.Contains(info.FullMethod, "LoginV1") {
+		// Allow login and register methods, KDF params needed before them and refresh of expired token
+		if strings.Contains(info.FullMethod, "RegisterV1") || strings.Contains(info.FullMethod, "LoginV1") ||
+			strings.Contains(info.FullMethod, "LoginSRP") || strings.Contains(info.FullMethod, "LoginTOTPV1") ||
+			strings.Contains(info.FullMethod, "GetKDFV1") || strings.Contains(info.FullMethod, "RefreshV1") {
 			return handler(ctx, req)
 		}
 
 		var err error
 
-		ctx, err = authContext(secretKey, ctx)
+		ctx, err = authContext(secretKey, sessions, ctx)
 		if err != nil {
 			return nil, err
 		}
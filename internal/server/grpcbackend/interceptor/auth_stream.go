@@ -6,10 +6,10 @@ import (
 )
 
 // Stream auth interceptor checks provided in metadata token
-func StreamAuthentication(secretKey []byte) grpc.StreamServerInterceptor {
+func StreamAuthentication(secretKey []byte, sessions SessionChecker) grpc.StreamServerInterceptor {
 	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		// Check auth token and store user id in ctx
-		ctx, err := authContext(secretKey, ss.Context())
+		ctx, err := authContext(secretKey, sessions, ss.Context())
 		if err != nil {
 			return err
 		}
@@ -3,6 +3,7 @@ package interceptor
 import (
 	"context"
 	"gophkeeper/internal/server/auth"
+	"gophkeeper/internal/server/entities"
 	"gophkeeper/pkg/constants"
 	"testing"
 	"time"
@@ -10,13 +11,22 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
+// Sessions ended by user, others are active
+type fakeSessions map[uint64]error
+
+func (f fakeSessions) Check(_ context.Context, _ int, sessionID uint64) error {
+	return f[sessionID]
+}
+
 func TestAuthentication(t *testing.T) {
 	secretKey := "test"
 
-	authInterceptor := Authentication([]byte(secretKey))
+	authInterceptor := Authentication([]byte(secretKey), fakeSessions{2: entities.ErrSessionRevoked})
 
 	handler := func(ctx context.Context, req any) (any, error) {
 		var auth bool
@@ -40,7 +50,7 @@ func TestAuthentication(t *testing.T) {
 
 	t.Run("valid auth", func(t *testing.T) {
 		userID := uint64(111)
-		token, err := auth.CreateToken(int(userID), time.Now().Add(time.Hour), []byte(secretKey))
+		token, err := auth.CreateToken(int(userID), 1, time.Now().Add(time.Hour), []byte(secretKey))
 		require.NoError(t, err)
 
 		md := metadata.New(map[string]string{
@@ -57,6 +67,37 @@ func TestAuthentication(t *testing.T) {
 		assert.True(t, res.(bool))
 	})
 
+	t.Run("ended session", func(t *testing.T) {
+		token, err := auth.CreateToken(111, 2, time.Now().Add(time.Hour), []byte(secretKey))
+		require.NoError(t, err)
+
+		mdCtx := metadata.NewIncomingContext(context.Background(), metadata.New(map[string]string{
+			constants.AccessTokenHeader: token,
+		}))
+
+		_, err = authInterceptor(mdCtx, nil, &grpc.UnaryServerInfo{FullMethod: "SomeMethod"}, handler)
+
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+
+	t.Run("refresh skips", func(t *testing.T) {
+		res, err := authInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{
+			FullMethod: "/proto.keeper.grpcapi.Users/RefreshV1",
+		}, handler)
+
+		assert.NoError(t, err)
+		assert.False(t, res.(bool))
+	})
+
+	t.Run("second login step skips", func(t *testing.T) {
+		res, err := authInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{
+			FullMethod: "/proto.keeper.grpcapi.Users/LoginTOTPV1",
+		}, handler)
+
+		assert.NoError(t, err)
+		assert.False(t, res.(bool))
+	})
+
 	t.Run("failed auth", func(t *testing.T) {
 		_, err := authInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{
 			FullMethod: "SomeMethod",
@@ -74,7 +115,7 @@ func TestAuthContext(t *testing.T) {
 
 		mdCtx := metadata.NewIncomingContext(context.Background(), md)
 
-		_, err := authContext(secretKey, mdCtx)
+		_, err := authContext(secretKey, fakeSessions{}, mdCtx)
 
 		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = missing access token")
 	})
@@ -86,7 +127,7 @@ func TestAuthContext(t *testing.T) {
 
 		mdCtx := metadata.NewIncomingContext(context.Background(), md)
 
-		_, err := authContext(secretKey, mdCtx)
+		_, err := authContext(secretKey, fakeSessions{}, mdCtx)
 
 		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = failed to verify token: token contains an invalid number of segments")
 	})
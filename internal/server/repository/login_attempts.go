@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+//go:generate mockgen -source login_attempts.go -destination mocks/mock_login_attempts.go -package repository
+type LoginAttemptsRepository interface {
+	Attempt(ctx context.Context, key string, now time.Time, resetBefore time.Time) (int, time.Time, error)
+	Lock(ctx context.Context, key string, until time.Time, keep int) error
+	Release(ctx context.Context, key string) error
+	Reset(ctx context.Context, key string) error
+}
@@ -0,0 +1,77 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gophkeeper/internal/server/repository"
+	strg "gophkeeper/internal/server/storage/postgres"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/dig"
+)
+
+var _ repository.LoginAttemptsRepository = LoginAttemptsRepository{}
+
+// Failed login counters using PostgreSQL
+type LoginAttemptsRepository struct {
+	db *sqlx.DB
+}
+
+type LoginAttemptsRepositoryDependencies struct {
+	dig.In
+	PostgresConn *strg.PostgresConn
+}
+
+// Create new postgresql login attempts repository
+func NewLoginAttemptsRepository(deps LoginAttemptsRepositoryDependencies) *LoginAttemptsRepository {
+	return &LoginAttemptsRepository{
+		db: deps.PostgresConn.DB,
+	}
+}
+
+// Count attempt for key in one statement, so parallel attempts get their own numbers. Failures before resetBefore
+// are forgotten. Returns attempts in a row and end of lockout; key that is locked out is not counted.
+func (r LoginAttemptsRepository) Attempt(ctx context.Context, key string, now time.Time, resetBefore time.Time) (int, time.Time, error) {
+	var (
+		failures int
+		until    sql.NullTime
+	)
+
+	err := r.db.QueryRowxContext(ctx,
+		"INSERT INTO login_attempts AS a (key, failures, last_failed_at) VALUES ($1, 1, $2) "+
+			"ON CONFLICT (key) DO UPDATE SET "+
+			"failures = CASE WHEN a.locked_until > $2 THEN a.failures WHEN a.last_failed_at < $3 THEN 1 ELSE a.failures + 1 END, "+
+			"last_failed_at = CASE WHEN a.locked_until > $2 THEN a.last_failed_at ELSE $2 END "+
+			"RETURNING failures, locked_until",
+		key, now, resetBefore,
+	).Scan(&failures, &until)
+
+	return failures, until.Time, err
+}
+
+// Refuse attempts of key till given time, earlier end of lockout does not shorten later one.
+// Failures above keep are dropped, they were attempts refused past lockout.
+func (r LoginAttemptsRepository) Lock(ctx context.Context, key string, until time.Time, keep int) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE login_attempts SET locked_until = GREATEST(locked_until, $2), failures = LEAST(failures, $3) WHERE key = $1",
+		key, until, keep,
+	)
+
+	return err
+}
+
+// Take back attempt that was not a failure
+func (r LoginAttemptsRepository) Release(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE login_attempts SET failures = failures - 1 WHERE key = $1 AND failures > 0", key)
+
+	return err
+}
+
+// Forget failures of key after successful login
+func (r LoginAttemptsRepository) Reset(ctx context.Context, key string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM login_attempts WHERE key = $1", key)
+
+	return err
+}
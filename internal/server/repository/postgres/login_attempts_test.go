@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/storage/postgres"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLoginAttemptsRepo(t *testing.T) (*LoginAttemptsRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewLoginAttemptsRepository(LoginAttemptsRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlx.NewDb(db, "postgres")},
+	}), mock
+}
+
+func TestLoginAttemptsRepository_Attempt(t *testing.T) {
+	query := `INSERT INTO login_attempts AS a \(key, failures, last_failed_at\) VALUES \(\$1, 1, \$2\) ON CONFLICT \(key\) DO UPDATE SET ` +
+		`failures = CASE WHEN a.locked_until > \$2 THEN a.failures .* RETURNING failures, locked_until`
+	now := time.Now()
+	resetBefore := now.Add(-time.Hour)
+
+	t.Run("Counted", func(t *testing.T) {
+		repo, mock := newLoginAttemptsRepo(t)
+		mock.ExpectQuery(query).WithArgs("login:alice", now, resetBefore).
+			WillReturnRows(sqlmock.NewRows([]string{"failures", "locked_until"}).AddRow(4, nil))
+
+		failures, until, err := repo.Attempt(context.Background(), "login:alice", now, resetBefore)
+		require.NoError(t, err)
+		assert.Equal(t, 4, failures)
+		assert.True(t, until.IsZero())
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		repo, mock := newLoginAttemptsRepo(t)
+		locked := now.Add(time.Minute)
+		mock.ExpectQuery(query).WithArgs("login:alice", now, resetBefore).
+			WillReturnRows(sqlmock.NewRows([]string{"failures", "locked_until"}).AddRow(10, locked))
+
+		_, until, err := repo.Attempt(context.Background(), "login:alice", now, resetBefore)
+		require.NoError(t, err)
+		assert.Equal(t, locked, until)
+	})
+}
+
+func TestLoginAttemptsRepository_Update(t *testing.T) {
+	repo, mock := newLoginAttemptsRepo(t)
+	now := time.Now()
+
+	mock.ExpectExec(`UPDATE login_attempts SET locked_until = GREATEST\(locked_until, \$2\), failures = LEAST\(failures, \$3\) WHERE key = \$1`).
+		WithArgs("login:alice", now.Add(time.Second), 9).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE login_attempts SET failures = failures - 1 WHERE key = \$1 AND failures > 0`).
+		WithArgs("addr:10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM login_attempts WHERE key = \$1`).WithArgs("login:alice").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+
+	require.NoError(t, repo.Lock(ctx, "login:alice", now.Add(time.Second), 9))
+	require.NoError(t, repo.Release(ctx, "addr:10.0.0.1"))
+	require.NoError(t, repo.Reset(ctx, "login:alice"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
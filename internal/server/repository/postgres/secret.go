@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/internal/server/repository"
@@ -17,6 +18,9 @@ import (
 
 var _ repository.SecretsRepository = SecretsRepository{}
 
+// Number of previous revisions kept per secret
+const HistoryLimit = 50
+
 type SecretsRepositoryDependencies struct {
 	dig.In
 	PostgresConn *strg.PostgresConn
@@ -38,7 +42,7 @@ func NewSecretsRepository(deps SecretsRepositoryDependencies) *SecretsRepository
 func (r SecretsRepository) GetSecret(ctx context.Context, secretID uint64, userID uint64) (*models.Secret, error) {
 	var secret models.Secret
 
-	query := `SELECT * FROM secrets WHERE id = $1 AND user_id = $2`
+	query := `SELECT * FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`
 
 	err := r.db.QueryRowxContext(ctx, query, secretID, userID).StructScan(&secret)
 	if errors.Is(err, sql.ErrNoRows) {
@@ -52,7 +56,7 @@ func (r SecretsRepository) GetSecret(ctx context.Context, secretID uint64, userI
 func (r SecretsRepository) GetUserSecrets(ctx context.Context, userID uint64) (models.Secrets, error) {
 	var secrets models.Secrets
 
-	query := "SELECT * FROM secrets WHERE user_id = $1 ORDER BY updated_at DESC"
+	query := "SELECT * FROM secrets WHERE user_id = $1 AND deleted_at IS NULL ORDER BY updated_at DESC"
 	err := r.db.SelectContext(ctx, &secrets, query, userID)
 	if err != nil {
 		return nil, err
@@ -61,27 +65,137 @@ func (r SecretsRepository) GetUserSecrets(ctx context.Context, userID uint64) (m
 	return secrets, nil
 }
 
+// Find user's changes after given change sequence, oldest first: secrets created, updated or restored
+// from trash and, unless skipped, tombstones of secrets moved to trash. Both are read from one snapshot,
+// and writers take sequences under the user's lock (see lockChanges), so every change of the user
+// with sequence up to the last one returned is already committed and a cursor never skips one.
+func (r SecretsRepository) GetChanges(ctx context.Context, userID uint64, since uint64, limit int, withTombstones bool) (models.Secrets, []models.Tombstone, error) {
+	var (
+		secrets    models.Secrets
+		tombstones []models.Tombstone
+	)
+
+	tx, err := r.db.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback() // no-op once committed
+
+	query := "SELECT * FROM secrets WHERE user_id = $1 AND change_seq > $2 AND deleted_at IS NULL ORDER BY change_seq LIMIT $3"
+	if err := tx.SelectContext(ctx, &secrets, query, userID, since, limit); err != nil {
+		return nil, nil, err
+	}
+
+	if withTombstones {
+		query := "SELECT secret_id, change_seq FROM secret_tombstones WHERE user_id = $1 AND change_seq > $2 ORDER BY change_seq LIMIT $3"
+		if err := tx.SelectContext(ctx, &tombstones, query, userID, since, limit); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return secrets, tombstones, tx.Commit()
+}
+
+// Find previous revisions of user's secret, newest first. Payloads are not loaded.
+func (r SecretsRepository) GetSecretRevisions(ctx context.Context, secretID uint64, userID uint64) (models.Secrets, error) {
+	var secrets models.Secrets
+
+	query := `SELECT secret_id AS id, user_id, revision, title, metadata, secret_type, folder, tags, created_at, updated_at
+		FROM secret_revisions WHERE secret_id = $1 AND user_id = $2 ORDER BY revision DESC`
+	err := r.db.SelectContext(ctx, &secrets, query, secretID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Find previous revision of user's secret with its payload
+func (r SecretsRepository) GetSecretRevision(ctx context.Context, secretID uint64, userID uint64, revision uint64) (*models.Secret, error) {
+	var secret models.Secret
+
+	query := `SELECT secret_id AS id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at
+		FROM secret_revisions WHERE secret_id = $1 AND user_id = $2 AND revision = $3`
+
+	err := r.db.QueryRowxContext(ctx, query, secretID, userID, revision).StructScan(&secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrorRevisionNotFound(secretID, revision)
+	}
+
+	return &secret, err
+}
+
 // Create new secret
 func (r SecretsRepository) Create(ctx context.Context, secret *models.Secret) (uint64, error) {
 	var newSecretID uint64
 
-	query := `INSERT INTO secrets (user_id, title, metadata, secret_type, payload)
-		VALUES ($1, $2, $3, $4, $5)
+	query := `INSERT INTO secrets (user_id, title, metadata, secret_type, folder, tags, payload, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id`
 
-	result := r.db.QueryRowxContext(ctx, query, secret.UserID, secret.Title, secret.Metadata, secret.SecretType, secret.Payload)
-	err := result.Scan(&newSecretID)
-	if err != nil {
-		return 0, err
-	}
+	err := runInTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockChanges(ctx, tx, secret.UserID); err != nil {
+			return err
+		}
 
-	return newSecretID, nil
+		if secret.CreateKey == "" {
+			return tx.QueryRowxContext(ctx, query, secret.UserID, secret.Title, secret.Metadata, secret.SecretType, secret.Folder, secret.Tags, secret.Payload, secret.CreatedAt, secret.UpdatedAt).Scan(&newSecretID)
+		}
+
+		// Key is claimed first, concurrent retry waits on it and then sees the secret made by this transaction
+		res, err := tx.ExecContext(ctx, "INSERT INTO secret_create_keys (user_id, key) VALUES ($1, $2) ON CONFLICT DO NOTHING", secret.UserID, secret.CreateKey)
+		if err != nil {
+			return err
+		}
+
+		claimed, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if claimed == 0 {
+			var existingID sql.NullInt64
+			err := tx.QueryRowxContext(ctx, "SELECT secret_id FROM secret_create_keys WHERE user_id = $1 AND key = $2", secret.UserID, secret.CreateKey).Scan(&existingID)
+			if err != nil {
+				return err
+			}
+
+			// Secret made with this key was purged since
+			if !existingID.Valid {
+				return entities.ErrorSecretNotFound(0)
+			}
+
+			newSecretID = uint64(existingID.Int64)
+
+			return entities.ErrCreateReplayed
+		}
+
+		err = tx.QueryRowxContext(ctx, query, secret.UserID, secret.Title, secret.Metadata, secret.SecretType, secret.Folder, secret.Tags, secret.Payload, secret.CreatedAt, secret.UpdatedAt).Scan(&newSecretID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE secret_create_keys SET secret_id = $1 WHERE user_id = $2 AND key = $3", newSecretID, secret.UserID, secret.CreateKey)
+
+		return err
+	})
+
+	return newSecretID, err
 }
 
-// Ensure secret exists and update secret (in one transaction)
+// Ensure secret exists and was not changed since client read it, then move current revision
+// to history and update secret (in one transaction). Revisions older than HistoryLimit are dropped.
+// Revision 0 comes from clients unaware of revisions and overwrites unconditionally.
+// On success secret.Revision holds the new revision.
 func (r SecretsRepository) Update(ctx context.Context, secret *models.Secret) error {
 	return runInTx(r.db, func(tx *sqlx.Tx) error {
-		err := tx.QueryRowxContext(ctx, "SELECT 1 FROM secrets WHERE id = $1 FOR UPDATE", secret.ID).Scan(new(int))
+		if err := lockChanges(ctx, tx, secret.UserID); err != nil {
+			return err
+		}
+
+		var revision uint64
+
+		err := tx.QueryRowxContext(ctx, "SELECT revision FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR UPDATE", secret.ID, secret.UserID).Scan(&revision)
 		if err != nil {
 			if err == sql.ErrNoRows {
 				return fmt.Errorf("secret with ID %d not found: %w", secret.ID, err)
@@ -89,31 +203,146 @@ func (r SecretsRepository) Update(ctx context.Context, secret *models.Secret) er
 			return err
 		}
 
-		sql := `UPDATE secrets SET updated_at = $1, title = $2, metadata = $3, secret_type = $4, payload = $5 WHERE id = $6;`
-		_, err = tx.ExecContext(ctx, sql,
+		if secret.Revision != 0 && secret.Revision != revision {
+			return entities.ErrorStaleRevision(secret.ID, revision)
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO secret_revisions (secret_id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at)
+			SELECT id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at FROM secrets WHERE id = $1`, secret.ID)
+		if err != nil {
+			return err
+		}
+
+		sql := `UPDATE secrets SET updated_at = $1, title = $2, metadata = $3, secret_type = $4, folder = $5, tags = $6, payload = $7, revision = revision + 1, change_seq = nextval('secret_change_seq') WHERE id = $8 RETURNING revision;`
+		err = tx.QueryRowxContext(ctx, sql,
 			secret.UpdatedAt,
 			secret.Title,
 			secret.Metadata,
 			secret.SecretType,
+			secret.Folder,
+			secret.Tags,
 			secret.Payload,
 			secret.ID,
-		)
+		).Scan(&secret.Revision)
+		if err != nil {
+			return err
+		}
 
+		_, err = tx.ExecContext(ctx, `DELETE FROM secret_revisions WHERE secret_id = $1 AND revision < $2`, secret.ID, int64(secret.Revision)-HistoryLimit)
 		return err
 	})
 }
 
+// Move secret to trash and leave a tombstone for delta sync (in one transaction)
 func (r SecretsRepository) Delete(ctx context.Context, secretID uint64, userID uint64) error {
-	query := `DELETE FROM secrets WHERE id = $1 AND user_id = $2`
-	_, err := r.db.ExecContext(ctx, query, secretID, userID)
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockChanges(ctx, tx, int(userID)); err != nil {
+			return err
+		}
 
-	return err
+		res, err := tx.ExecContext(ctx, `UPDATE secrets SET deleted_at = NOW() WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL`, secretID, userID)
+		if err != nil {
+			return err
+		}
+
+		deleted, err := res.RowsAffected()
+		if err != nil || deleted == 0 {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `INSERT INTO secret_tombstones (secret_id, user_id) VALUES ($1, $2)`, secretID, userID)
+		return err
+	})
+}
+
+// Find user's secrets in trash, most recently deleted first
+func (r SecretsRepository) GetTrash(ctx context.Context, userID uint64) (models.Secrets, error) {
+	var secrets models.Secrets
+
+	query := "SELECT * FROM secrets WHERE user_id = $1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC"
+	err := r.db.SelectContext(ctx, &secrets, query, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return secrets, nil
+}
+
+// Take secret out of trash. It gets a new change sequence and its tombstone is dropped,
+// so clients see it as updated (in one transaction).
+func (r SecretsRepository) Restore(ctx context.Context, secretID uint64, userID uint64) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockChanges(ctx, tx, int(userID)); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `UPDATE secrets SET deleted_at = NULL, change_seq = nextval('secret_change_seq')
+			WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`, secretID, userID)
+		if err != nil {
+			return err
+		}
+
+		restored, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			return entities.ErrorNotInTrash(secretID)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM secret_tombstones WHERE secret_id = $1`, secretID)
+		return err
+	})
+}
+
+// Permanently delete secret in trash with its revisions. Tombstone left by trashing stays.
+func (r SecretsRepository) Purge(ctx context.Context, secretID uint64, userID uint64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM secrets WHERE id = $1 AND user_id = $2 AND deleted_at IS NOT NULL`, secretID, userID)
+	if err != nil {
+		return err
+	}
+
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if purged == 0 {
+		return entities.ErrorNotInTrash(secretID)
+	}
+
+	return nil
+}
+
+// Permanently delete secrets of all users moved to trash before given time,
+// along with create keys old enough that no client retries them
+func (r SecretsRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM secrets WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM secret_create_keys WHERE created_at < $1`, before); err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
 }
 
 func (r SecretsRepository) Pong() {
 	fmt.Println("alive")
 }
 
+// Class of advisory locks serializing writers of user's change feed
+const changesLockClass = 1
+
+// Take lock of user's change feed until the end of transaction. Writers take change sequence under it,
+// so sequences of one user commit in order and a reader never sees a later one while an earlier is pending.
+// Must come before the transaction takes its change sequence.
+func lockChanges(ctx context.Context, tx *sqlx.Tx, userID int) error {
+	_, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1, $2)", changesLockClass, userID)
+	return err
+}
+
 func runInTx(db *sqlx.DB, fn func(tx *sqlx.Tx) error) error {
 	tx, err := db.Beginx()
 	if err != nil {
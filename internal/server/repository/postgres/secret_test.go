@@ -4,7 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"os"
 	"testing"
+	"time"
 
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/internal/server/storage/postgres"
@@ -57,21 +60,61 @@ func TestSecretsRepository_Create(t *testing.T) {
 	})
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectQuery(`INSERT INTO secrets \(user_id, title, metadata, secret_type, payload\) VALUES \(\$1, \$2, \$3, \$4, \$5\) RETURNING id`).
-			WithArgs(1, "Test Title", "{}", "credential", []byte("payload")).
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectQuery(`INSERT INTO secrets \(user_id, title, metadata, secret_type, folder, tags, payload, created_at, updated_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8, \$9\) RETURNING id`).
+			WithArgs(1, "Test Title", "{}", "credential", "work/mail", `["mail","personal"]`, []byte("payload"), createdAt, createdAt).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
 
 		id, err := repo.Create(context.Background(), &models.Secret{
 			UserID:     1,
 			Title:      "Test Title",
 			Metadata:   "{}",
 			SecretType: "credential",
+			Folder:     "work/mail",
+			Tags:       models.Tags{"mail", "personal"},
 			Payload:    []byte("payload"),
+			CreatedAt:  createdAt,
+			UpdatedAt:  createdAt,
 		})
 
 		assert.NoError(t, err)
 		assert.Equal(t, uint64(1), id)
 	})
+
+	insertKey := `INSERT INTO secret_create_keys \(user_id, key\) VALUES \(\$1, \$2\) ON CONFLICT DO NOTHING`
+
+	t.Run("With create key", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(insertKey).WithArgs(1, "key").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`INSERT INTO secrets`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+		mock.ExpectExec(`UPDATE secret_create_keys SET secret_id = \$1 WHERE user_id = \$2 AND key = \$3`).WithArgs(7, 1, "key").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		id, err := repo.Create(context.Background(), &models.Secret{UserID: 1, Title: "Test Title", CreateKey: "key"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(7), id)
+	})
+
+	t.Run("Replayed create key", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(insertKey).WithArgs(1, "key").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT secret_id FROM secret_create_keys WHERE user_id = \$1 AND key = \$2`).WithArgs(1, "key").
+			WillReturnRows(sqlmock.NewRows([]string{"secret_id"}).AddRow(7))
+		mock.ExpectRollback()
+
+		id, err := repo.Create(context.Background(), &models.Secret{UserID: 1, Title: "Test Title", CreateKey: "key"})
+
+		assert.ErrorIs(t, err, entities.ErrCreateReplayed)
+		assert.Equal(t, uint64(7), id)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestSecretsRepository_Update(t *testing.T) {
@@ -86,21 +129,48 @@ func TestSecretsRepository_Update(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		mock.ExpectBegin()
-		mock.ExpectQuery(`SELECT 1 FROM secrets WHERE id = \$1 FOR UPDATE`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"1"}).AddRow(1))
-		mock.ExpectExec(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, payload = \$5 WHERE id = \$6`).
-			WithArgs(sqlmock.AnyArg(), "Updated Title", "{}", "credential", []byte("new_payload"), 1).
-			WillReturnResult(sqlmock.NewResult(1, 1))
+		expectChangesLock(mock, 1)
+		mock.ExpectQuery(`SELECT revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(3))
+		mock.ExpectExec(`INSERT INTO secret_revisions \(secret_id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at\) SELECT id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at FROM secrets WHERE id = \$1`).
+			WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(`UPDATE secrets SET updated_at = \$1, title = \$2, metadata = \$3, secret_type = \$4, folder = \$5, tags = \$6, payload = \$7, revision = revision \+ 1, change_seq = nextval\('secret_change_seq'\) WHERE id = \$8 RETURNING revision`).
+			WithArgs(sqlmock.AnyArg(), "Updated Title", "{}", "credential", "", "[]", []byte("new_payload"), 1).
+			WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(4))
+		mock.ExpectExec(`DELETE FROM secret_revisions WHERE secret_id = \$1 AND revision < \$2`).WithArgs(1, 4-HistoryLimit).WillReturnResult(sqlmock.NewResult(0, 0))
 		mock.ExpectCommit()
 
-		err := repo.Update(context.Background(), &models.Secret{
+		secret := &models.Secret{
 			ID:         1,
+			UserID:     1,
 			Title:      "Updated Title",
 			Metadata:   "{}",
 			SecretType: "credential",
 			Payload:    []byte("new_payload"),
-		})
+			Revision:   3,
+		}
+
+		err := repo.Update(context.Background(), secret)
 
 		assert.NoError(t, err)
+		assert.Equal(t, uint64(4), secret.Revision)
+	})
+
+	t.Run("Stale revision", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectQuery(`SELECT revision FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL FOR UPDATE`).WithArgs(1, 1).WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(4))
+		mock.ExpectRollback()
+
+		err := repo.Update(context.Background(), &models.Secret{
+			ID:         1,
+			UserID:     1,
+			Title:      "Updated Title",
+			SecretType: "credential",
+			Revision:   3,
+		})
+
+		assert.ErrorIs(t, err, entities.ErrStaleRevision)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
 }
 
@@ -115,9 +185,242 @@ func TestSecretsRepository_Delete(t *testing.T) {
 	})
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectExec(`DELETE FROM secrets WHERE id = \$1 AND user_id = \$2`).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(`UPDATE secrets SET deleted_at = NOW\(\) WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NULL`).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO secret_tombstones \(secret_id, user_id\) VALUES \(\$1, \$2\)`).WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
 
 		err := repo.Delete(context.Background(), 1, 1)
 		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
 	})
+
+	t.Run("Not Found", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(`UPDATE secrets SET deleted_at = NOW\(\)`).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := repo.Delete(context.Background(), 2, 1)
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSecretsRepository_Changes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	repo := NewSecretsRepository(SecretsRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlxDB},
+	})
+
+	t.Run("Secrets and tombstones", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM secrets WHERE user_id = \$1 AND change_seq > \$2 AND deleted_at IS NULL ORDER BY change_seq LIMIT \$3`).WithArgs(1, 10, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "change_seq"}).AddRow(3, 1, "Changed", 12))
+		mock.ExpectQuery(`SELECT secret_id, change_seq FROM secret_tombstones WHERE user_id = \$1 AND change_seq > \$2 ORDER BY change_seq LIMIT \$3`).WithArgs(1, 10, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"secret_id", "change_seq"}).AddRow(2, 11))
+		mock.ExpectCommit()
+
+		secrets, tombstones, err := repo.GetChanges(context.Background(), 1, 10, 100, true)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, uint64(12), secrets[0].ChangeSeq)
+		assert.Equal(t, []models.Tombstone{{SecretID: 2, ChangeSeq: 11}}, tombstones)
+	})
+
+	t.Run("Without tombstones", func(t *testing.T) {
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT \* FROM secrets WHERE user_id = \$1`).WithArgs(1, 0, 100).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "change_seq"}))
+		mock.ExpectCommit()
+
+		_, tombstones, err := repo.GetChanges(context.Background(), 1, 0, 100, false)
+		require.NoError(t, err)
+		assert.Empty(t, tombstones)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Two writers of one user interleave: the first takes its change sequence and commits late.
+// Needs real PostgreSQL, set GOPHKEEPER_TEST_DSN to an empty database to run.
+func TestSecretsRepository_ChangesInterleaved(t *testing.T) {
+	dsn := os.Getenv("GOPHKEEPER_TEST_DSN")
+	if dsn == "" {
+		t.Skip("GOPHKEEPER_TEST_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	conn := postgres.NewPostgresConn(postgres.PostgresConnDependencies{DSN: postgres.PostgresDSN(dsn)})
+	require.NoError(t, conn.Err)
+	t.Cleanup(func() { conn.DB.Close() })
+
+	_, err := postgres.NewPostgresStorage(postgres.PostgresStorageDependencies{PostgresConn: conn})
+	require.NoError(t, err)
+
+	users := NewUsersRepository(UsersRepositoryDependencies{PostgresConn: conn})
+	repo := NewSecretsRepository(SecretsRepositoryDependencies{PostgresConn: conn})
+
+	userID, err := users.Create(ctx, models.User{Login: fmt.Sprintf("interleaved-%d", time.Now().UnixNano())})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.DB.Exec("DELETE FROM secrets WHERE user_id = $1", userID)
+		conn.DB.Exec("DELETE FROM users WHERE id = $1", userID)
+	})
+
+	// First writer takes its sequence and holds the transaction open
+	first, err := conn.DB.Beginx()
+	require.NoError(t, err)
+	require.NoError(t, lockChanges(ctx, first, userID))
+
+	var firstID uint64
+	err = first.QueryRowxContext(ctx, "INSERT INTO secrets (user_id, title, secret_type, payload) VALUES ($1, 'first', 'text', '') RETURNING id", userID).Scan(&firstID)
+	require.NoError(t, err)
+
+	// Second writer must not commit a later sequence meanwhile
+	created := make(chan error, 1)
+	go func() {
+		_, err := repo.Create(ctx, &models.Secret{UserID: userID, Title: "second", SecretType: "text", Payload: []byte{}})
+		created <- err
+	}()
+
+	select {
+	case err := <-created:
+		t.Fatalf("second writer committed while first is pending: %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	// Reader in between sees nothing, so its cursor stays put
+	secrets, _, err := repo.GetChanges(ctx, uint64(userID), 0, 10, true)
+	require.NoError(t, err)
+	assert.Empty(t, secrets)
+
+	require.NoError(t, first.Commit())
+	require.NoError(t, <-created)
+
+	// Both changes follow the cursor, first one first
+	secrets, _, err = repo.GetChanges(ctx, uint64(userID), 0, 10, true)
+	require.NoError(t, err)
+	require.Len(t, secrets, 2)
+	assert.Equal(t, firstID, secrets[0].ID)
+	assert.Equal(t, "second", secrets[1].Title)
+	assert.Less(t, secrets[0].ChangeSeq, secrets[1].ChangeSeq)
+}
+
+func TestSecretsRepository_Revisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	repo := NewSecretsRepository(SecretsRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlxDB},
+	})
+
+	t.Run("List", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "revision", "title", "folder", "tags"}).
+			AddRow(1, 1, 2, "Second", "work", []byte(`["mail"]`)).
+			AddRow(1, 1, 1, "First", "", []byte(`[]`))
+		mock.ExpectQuery(`SELECT secret_id AS id, user_id, revision, title, metadata, secret_type, folder, tags, created_at, updated_at FROM secret_revisions WHERE secret_id = \$1 AND user_id = \$2 ORDER BY revision DESC`).
+			WithArgs(1, 1).WillReturnRows(rows)
+
+		revisions, err := repo.GetSecretRevisions(context.Background(), 1, 1)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, uint64(2), revisions[0].Revision)
+		assert.Nil(t, revisions[0].Payload)
+		assert.Equal(t, "work", revisions[0].Folder)
+		assert.Equal(t, models.Tags{"mail"}, revisions[0].Tags)
+		assert.Empty(t, revisions[1].Tags)
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		rows := sqlmock.NewRows([]string{"id", "user_id", "revision", "title", "payload"}).AddRow(1, 1, 1, "First", []byte("old"))
+		mock.ExpectQuery(`SELECT secret_id AS id, user_id, revision, title, metadata, secret_type, folder, tags, payload, created_at, updated_at FROM secret_revisions WHERE secret_id = \$1 AND user_id = \$2 AND revision = \$3`).
+			WithArgs(1, 1, 1).WillReturnRows(rows)
+
+		secret, err := repo.GetSecretRevision(context.Background(), 1, 1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("old"), secret.Payload)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mock.ExpectQuery(`SELECT secret_id AS id`).WithArgs(1, 1, 9).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.GetSecretRevision(context.Background(), 1, 1, 9)
+		assert.ErrorIs(t, err, entities.ErrRevisionNotFound)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSecretsRepository_Trash(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	repo := NewSecretsRepository(SecretsRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlxDB},
+	})
+
+	t.Run("List", func(t *testing.T) {
+		deletedAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+		rows := sqlmock.NewRows([]string{"id", "user_id", "title", "deleted_at"}).AddRow(3, 1, "Trashed", deletedAt)
+		mock.ExpectQuery(`SELECT \* FROM secrets WHERE user_id = \$1 AND deleted_at IS NOT NULL ORDER BY deleted_at DESC`).WithArgs(1).WillReturnRows(rows)
+
+		secrets, err := repo.GetTrash(context.Background(), 1)
+		require.NoError(t, err)
+		require.Len(t, secrets, 1)
+		assert.Equal(t, deletedAt, *secrets[0].DeletedAt)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(`UPDATE secrets SET deleted_at = NULL, change_seq = nextval\('secret_change_seq'\) WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL`).
+			WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM secret_tombstones WHERE secret_id = \$1`).WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.Restore(context.Background(), 3, 1))
+	})
+
+	t.Run("Restore not in trash", func(t *testing.T) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(`UPDATE secrets SET deleted_at = NULL`).WithArgs(4, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Restore(context.Background(), 4, 1), entities.ErrNotInTrash)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		mock.ExpectExec(`DELETE FROM secrets WHERE id = \$1 AND user_id = \$2 AND deleted_at IS NOT NULL`).WithArgs(3, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.Purge(context.Background(), 3, 1))
+	})
+
+	t.Run("Purge expired", func(t *testing.T) {
+		before := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+		mock.ExpectExec(`DELETE FROM secrets WHERE deleted_at < \$1`).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 5))
+		mock.ExpectExec(`DELETE FROM secret_create_keys WHERE created_at < \$1`).WithArgs(before).WillReturnResult(sqlmock.NewResult(0, 2))
+
+		purged, err := repo.PurgeTrash(context.Background(), before)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), purged)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Writers of change feed lock it for the user before taking change sequence
+func expectChangesLock(mock sqlmock.Sqlmock, userID int) {
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock\(\$1, \$2\)`).WithArgs(changesLockClass, userID).WillReturnResult(sqlmock.NewResult(0, 0))
 }
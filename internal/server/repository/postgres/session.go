@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	strg "gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/dig"
+)
+
+var _ repository.SessionsRepository = SessionsRepository{}
+
+const sessionColumns = "id, user_id, device, address, created_at, last_seen_at, expires_at, revoked_at"
+
+// Session repository using PostgreSQL
+type SessionsRepository struct {
+	db *sqlx.DB
+}
+
+type SessionsRepositoryDependencies struct {
+	dig.In
+	PostgresConn *strg.PostgresConn
+}
+
+// Create new postgresql session repository
+func NewSessionsRepository(deps SessionsRepositoryDependencies) *SessionsRepository {
+	return &SessionsRepository{
+		db: deps.PostgresConn.DB,
+	}
+}
+
+// Create session with hash of its refresh token, expired sessions of user are dropped on the way
+func (r SessionsRepository) Create(ctx context.Context, session models.Session, refreshHash []byte) (uint64, error) {
+	var id uint64
+
+	err := runInTx(r.db, func(tx *sqlx.Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM sessions WHERE user_id = $1 AND expires_at < $2", session.UserID, session.CreatedAt)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRowxContext(ctx,
+			"INSERT INTO sessions (user_id, refresh_hash, device, address, created_at, last_seen_at, expires_at) VALUES ($1, $2, $3, $4, $5, $5, $6) RETURNING id",
+			session.UserID, refreshHash, session.Device, session.Address, session.CreatedAt, session.ExpiresAt,
+		).Scan(&id)
+	})
+
+	return id, err
+}
+
+// Replace refresh token of active session, returns the session. Token replaced before is a sign it was stolen:
+// session is revoked and ErrRefreshReused returned. Revoked sessions yield ErrSessionRevoked,
+// unknown or expired ones ErrSessionNotFound.
+func (r SessionsRepository) Rotate(ctx context.Context, refreshHash []byte, newHash []byte, address string, now time.Time, expiresAt time.Time) (*models.Session, error) {
+	var session models.Session
+
+	err := r.db.QueryRowxContext(ctx,
+		"UPDATE sessions SET previous_hash = refresh_hash, refresh_hash = $1, address = $2, last_seen_at = $3, expires_at = $4 "+
+			"WHERE refresh_hash = $5 AND revoked_at IS NULL AND expires_at > $3 RETURNING "+sessionColumns,
+		newHash, address, now, expiresAt, refreshHash,
+	).StructScan(&session)
+	if err == nil {
+		return &session, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	err = r.db.QueryRowxContext(ctx,
+		"SELECT "+sessionColumns+" FROM sessions WHERE refresh_hash = $1 OR previous_hash = $1 LIMIT 1", refreshHash,
+	).StructScan(&session)
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, entities.ErrSessionNotFound
+	case err != nil:
+		return nil, err
+	case session.RevokedAt != nil:
+		return nil, entities.ErrSessionRevoked
+	case !session.Active(now):
+		return nil, entities.ErrSessionNotFound
+	}
+
+	if err := r.Revoke(ctx, session.UserID, session.ID); err != nil {
+		return nil, err
+	}
+
+	return nil, entities.ErrRefreshReused
+}
+
+// Get session by ID, revoked and expired ones included
+func (r SessionsRepository) Get(ctx context.Context, sessionID uint64) (*models.Session, error) {
+	var session models.Session
+
+	err := r.db.QueryRowxContext(ctx, "SELECT "+sessionColumns+" FROM sessions WHERE id = $1", sessionID).StructScan(&session)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Active sessions of user, most recently seen first
+func (r SessionsRepository) List(ctx context.Context, userID int, now time.Time) ([]models.Session, error) {
+	sessions := []models.Session{}
+
+	err := r.db.SelectContext(ctx, &sessions,
+		"SELECT "+sessionColumns+" FROM sessions WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2 ORDER BY last_seen_at DESC",
+		userID, now,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// End session of user, its tokens are no longer accepted
+func (r SessionsRepository) Revoke(ctx context.Context, userID int, sessionID uint64) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL", sessionID, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// End every session of user except keepID, e.g. after credentials changed
+func (r SessionsRepository) RevokeOthers(ctx context.Context, userID int, keepID uint64) error {
+	_, err := r.db.ExecContext(ctx,
+		"UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL", userID, keepID,
+	)
+
+	return err
+}
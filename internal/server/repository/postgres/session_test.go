@@ -0,0 +1,133 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var sessionRowColumns = []string{"id", "user_id", "device", "address", "created_at", "last_seen_at", "expires_at", "revoked_at"}
+
+func newSessionsRepo(t *testing.T) (*SessionsRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewSessionsRepository(SessionsRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlx.NewDb(db, "postgres")},
+	}), mock
+}
+
+func TestSessionsRepository_Create(t *testing.T) {
+	repo, mock := newSessionsRepo(t)
+	now := time.Now()
+	session := models.Session{UserID: 1, Device: "laptop", Address: "addr", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM sessions WHERE user_id = \$1 AND expires_at < \$2`).WithArgs(1, now).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectQuery(`INSERT INTO sessions \(user_id, refresh_hash, device, address, created_at, last_seen_at, expires_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$5, \$6\) RETURNING id`).
+		WithArgs(1, []byte("hash"), "laptop", "addr", now, session.ExpiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
+
+	id, err := repo.Create(context.Background(), session, []byte("hash"))
+	require.NoError(t, err)
+	assert.Equal(t, uint64(7), id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSessionsRepository_Rotate(t *testing.T) {
+	now := time.Now()
+	expires := now.Add(time.Hour)
+	rotate := `UPDATE sessions SET previous_hash = refresh_hash, refresh_hash = \$1, address = \$2, last_seen_at = \$3, expires_at = \$4 WHERE refresh_hash = \$5 AND revoked_at IS NULL AND expires_at > \$3 RETURNING`
+	lookup := `SELECT id, user_id, device, address, created_at, last_seen_at, expires_at, revoked_at FROM sessions WHERE refresh_hash = \$1 OR previous_hash = \$1 LIMIT 1`
+
+	t.Run("Rotated", func(t *testing.T) {
+		repo, mock := newSessionsRepo(t)
+		mock.ExpectQuery(rotate).WithArgs([]byte("new"), "addr", now, expires, []byte("old")).
+			WillReturnRows(sqlmock.NewRows(sessionRowColumns).AddRow(7, 1, "laptop", "addr", now, now, expires, nil))
+
+		session, err := repo.Rotate(context.Background(), []byte("old"), []byte("new"), "addr", now, expires)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(7), session.ID)
+		assert.Equal(t, 1, session.UserID)
+	})
+
+	t.Run("Unknown token", func(t *testing.T) {
+		repo, mock := newSessionsRepo(t)
+		mock.ExpectQuery(rotate).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(lookup).WithArgs([]byte("old")).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Rotate(context.Background(), []byte("old"), []byte("new"), "addr", now, expires)
+		assert.ErrorIs(t, err, entities.ErrSessionNotFound)
+	})
+
+	t.Run("Revoked session", func(t *testing.T) {
+		repo, mock := newSessionsRepo(t)
+		mock.ExpectQuery(rotate).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(lookup).WithArgs([]byte("old")).
+			WillReturnRows(sqlmock.NewRows(sessionRowColumns).AddRow(7, 1, "laptop", "addr", now, now, expires, now))
+
+		_, err := repo.Rotate(context.Background(), []byte("old"), []byte("new"), "addr", now, expires)
+		assert.ErrorIs(t, err, entities.ErrSessionRevoked)
+	})
+
+	t.Run("Reused token ends session", func(t *testing.T) {
+		repo, mock := newSessionsRepo(t)
+		mock.ExpectQuery(rotate).WillReturnError(sql.ErrNoRows)
+		mock.ExpectQuery(lookup).WithArgs([]byte("old")).
+			WillReturnRows(sqlmock.NewRows(sessionRowColumns).AddRow(7, 1, "laptop", "addr", now, now, expires, nil))
+		mock.ExpectExec(`UPDATE sessions SET revoked_at = NOW\(\) WHERE id = \$1 AND user_id = \$2 AND revoked_at IS NULL`).
+			WithArgs(7, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := repo.Rotate(context.Background(), []byte("old"), []byte("new"), "addr", now, expires)
+		assert.ErrorIs(t, err, entities.ErrRefreshReused)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestSessionsRepository_List(t *testing.T) {
+	repo, mock := newSessionsRepo(t)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT .* FROM sessions WHERE user_id = \$1 AND revoked_at IS NULL AND expires_at > \$2 ORDER BY last_seen_at DESC`).
+		WithArgs(1, now).
+		WillReturnRows(sqlmock.NewRows(sessionRowColumns).
+			AddRow(7, 1, "laptop", "addr", now, now, now.Add(time.Hour), nil).
+			AddRow(8, 1, "phone", "addr", now, now, now.Add(time.Hour), nil))
+
+	sessions, err := repo.List(context.Background(), 1, now)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "phone", sessions[1].Device)
+}
+
+func TestSessionsRepository_Revoke(t *testing.T) {
+	repo, mock := newSessionsRepo(t)
+	revoke := `UPDATE sessions SET revoked_at = NOW\(\) WHERE id = \$1 AND user_id = \$2 AND revoked_at IS NULL`
+
+	mock.ExpectExec(revoke).WithArgs(7, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Revoke(context.Background(), 1, 7))
+
+	mock.ExpectExec(revoke).WithArgs(8, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Revoke(context.Background(), 1, 8), entities.ErrSessionNotFound)
+}
+
+func TestSessionsRepository_RevokeOthers(t *testing.T) {
+	repo, mock := newSessionsRepo(t)
+
+	mock.ExpectExec(`UPDATE sessions SET revoked_at = NOW\(\) WHERE user_id = \$1 AND id <> \$2 AND revoked_at IS NULL`).
+		WithArgs(1, 7).WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, repo.RevokeOthers(context.Background(), 1, 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
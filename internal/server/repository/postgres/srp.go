@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	strg "gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/dig"
+)
+
+var _ repository.SRPHandshakesRepository = SRPHandshakesRepository{}
+
+// SRP handshake repository using PostgreSQL
+type SRPHandshakesRepository struct {
+	db *sqlx.DB
+}
+
+type SRPHandshakesRepositoryDependencies struct {
+	dig.In
+	PostgresConn *strg.PostgresConn
+}
+
+// Create new postgresql SRP handshake repository
+func NewSRPHandshakesRepository(deps SRPHandshakesRepositoryDependencies) *SRPHandshakesRepository {
+	return &SRPHandshakesRepository{
+		db: deps.PostgresConn.DB,
+	}
+}
+
+// Save handshake, only latest ones of its login are kept. Expired handshakes are dropped on the way.
+func (r SRPHandshakesRepository) Create(ctx context.Context, handshake models.SRPHandshake, keep int) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM srp_handshakes WHERE expires_at < $1", handshake.CreatedAt); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO srp_handshakes (id, user_id, login, state, created_at, expires_at) VALUES ($1, $2, $3, $4, $5, $6)",
+			handshake.ID, handshake.UserID, handshake.Login, handshake.State, handshake.CreatedAt, handshake.ExpiresAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM srp_handshakes WHERE login = $1 AND id NOT IN "+
+				"(SELECT id FROM srp_handshakes WHERE login = $1 ORDER BY created_at DESC LIMIT $2)",
+			handshake.Login, keep,
+		)
+
+		return err
+	})
+}
+
+// Remove handshake and return it, so it is used once. ErrHandshakeNotFound if it is unknown or expired.
+func (r SRPHandshakesRepository) Take(ctx context.Context, id string, now time.Time) (*models.SRPHandshake, error) {
+	var handshake models.SRPHandshake
+
+	err := r.db.QueryRowxContext(ctx,
+		"DELETE FROM srp_handshakes WHERE id = $1 RETURNING id, user_id, login, state, created_at, expires_at", id,
+	).StructScan(&handshake)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrHandshakeNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if !now.Before(handshake.ExpiresAt) {
+		return nil, entities.ErrHandshakeNotFound
+	}
+
+	return &handshake, nil
+}
@@ -0,0 +1,80 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var handshakeRowColumns = []string{"id", "user_id", "login", "state", "created_at", "expires_at"}
+
+func newSRPHandshakesRepo(t *testing.T) (*SRPHandshakesRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewSRPHandshakesRepository(SRPHandshakesRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlx.NewDb(db, "postgres")},
+	}), mock
+}
+
+func TestSRPHandshakesRepository_Create(t *testing.T) {
+	repo, mock := newSRPHandshakesRepo(t)
+	now := time.Now()
+	hs := models.SRPHandshake{ID: "abc", UserID: 1, Login: "alice", State: []byte("state"), CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM srp_handshakes WHERE expires_at < \$1`).WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec(`INSERT INTO srp_handshakes \(id, user_id, login, state, created_at, expires_at\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6\)`).
+		WithArgs("abc", 1, "alice", []byte("state"), now, hs.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM srp_handshakes WHERE login = \$1 AND id NOT IN \(SELECT id FROM srp_handshakes WHERE login = \$1 ORDER BY created_at DESC LIMIT \$2\)`).
+		WithArgs("alice", 5).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Create(context.Background(), hs, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSRPHandshakesRepository_Take(t *testing.T) {
+	query := `DELETE FROM srp_handshakes WHERE id = \$1 RETURNING id, user_id, login, state, created_at, expires_at`
+	now := time.Now()
+
+	t.Run("Taken", func(t *testing.T) {
+		repo, mock := newSRPHandshakesRepo(t)
+		mock.ExpectQuery(query).WithArgs("abc").
+			WillReturnRows(sqlmock.NewRows(handshakeRowColumns).AddRow("abc", 1, "alice", []byte("state"), now, now.Add(time.Minute)))
+
+		hs, err := repo.Take(context.Background(), "abc", now)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", hs.Login)
+		assert.Equal(t, []byte("state"), hs.State)
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		repo, mock := newSRPHandshakesRepo(t)
+		mock.ExpectQuery(query).WithArgs("abc").
+			WillReturnRows(sqlmock.NewRows(handshakeRowColumns).AddRow("abc", 1, "alice", []byte("state"), now.Add(-time.Minute), now))
+
+		_, err := repo.Take(context.Background(), "abc", now)
+		assert.ErrorIs(t, err, entities.ErrHandshakeNotFound)
+	})
+
+	t.Run("Unknown", func(t *testing.T) {
+		repo, mock := newSRPHandshakesRepo(t)
+		mock.ExpectQuery(query).WithArgs("abc").WillReturnRows(sqlmock.NewRows(handshakeRowColumns))
+
+		_, err := repo.Take(context.Background(), "abc", now)
+		assert.ErrorIs(t, err, entities.ErrHandshakeNotFound)
+	})
+}
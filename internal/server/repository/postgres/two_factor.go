@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	strg "gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/dig"
+)
+
+var _ repository.TwoFactorRepository = TwoFactorRepository{}
+
+// Second factor repository using PostgreSQL
+type TwoFactorRepository struct {
+	db *sqlx.DB
+}
+
+type TwoFactorRepositoryDependencies struct {
+	dig.In
+	PostgresConn *strg.PostgresConn
+}
+
+// Create new postgresql second factor repository
+func NewTwoFactorRepository(deps TwoFactorRepositoryDependencies) *TwoFactorRepository {
+	return &TwoFactorRepository{
+		db: deps.PostgresConn.DB,
+	}
+}
+
+// Second factor of user, pending one included. ErrTwoFactorNotFound if user never enrolled.
+func (r TwoFactorRepository) Get(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	var tf models.TwoFactor
+
+	err := r.db.QueryRowxContext(ctx,
+		"SELECT user_id, secret, confirmed_at, last_step FROM user_totp WHERE user_id = $1", userID,
+	).StructScan(&tf)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrTwoFactorNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &tf, nil
+}
+
+// Save secret waiting for confirmation, replacing pending one. Enabled second factor is kept with ErrTwoFactorEnabled.
+func (r TwoFactorRepository) SetPending(ctx context.Context, userID int, secret []byte) error {
+	res, err := r.db.ExecContext(ctx,
+		"INSERT INTO user_totp (user_id, secret) VALUES ($1, $2) "+
+			"ON CONFLICT (user_id) DO UPDATE SET secret = EXCLUDED.secret, last_step = 0 WHERE user_totp.confirmed_at IS NULL",
+		userID, secret,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrTwoFactorEnabled
+	}
+
+	return nil
+}
+
+// Enable pending second factor with step of confirming code, recovery codes replace earlier ones
+func (r TwoFactorRepository) Confirm(ctx context.Context, userID int, step int64, recoveryHashes [][]byte) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		res, err := tx.ExecContext(ctx,
+			"UPDATE user_totp SET confirmed_at = NOW(), last_step = $2 WHERE user_id = $1 AND confirmed_at IS NULL", userID, step,
+		)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return entities.ErrTwoFactorNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+
+		for _, hash := range recoveryHashes {
+			if _, err := tx.ExecContext(ctx, "INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2)", userID, hash); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// Mark time step as used, ErrBadTOTPCode if code of it or a later one was accepted already
+func (r TwoFactorRepository) UseStep(ctx context.Context, userID int, step int64) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE user_totp SET last_step = $2 WHERE user_id = $1 AND last_step < $2", userID, step,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrBadTOTPCode
+	}
+
+	return nil
+}
+
+// Spend recovery code, ErrBadTOTPCode if it is unknown or used
+func (r TwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL", userID, codeHash,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrBadTOTPCode
+	}
+
+	return nil
+}
+
+// Unused recovery codes of user
+func (r TwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	var n int
+
+	err := r.db.QueryRowxContext(ctx, "SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL", userID).Scan(&n)
+
+	return n, err
+}
+
+// Drop second factor with its recovery codes
+func (r TwoFactorRepository) Delete(ctx context.Context, userID int) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM recovery_codes WHERE user_id = $1", userID); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, "DELETE FROM user_totp WHERE user_id = $1", userID)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return entities.ErrTwoFactorNotFound
+		}
+
+		return nil
+	})
+}
+
+// Save challenge, only latest ones of its user are kept. Expired challenges are dropped on the way.
+func (r TwoFactorRepository) CreateChallenge(ctx context.Context, challenge models.TOTPChallenge, keep int) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM totp_challenges WHERE expires_at < $1", challenge.CreatedAt); err != nil {
+			return err
+		}
+
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO totp_challenges (id, user_id, login, created_at, expires_at) VALUES ($1, $2, $3, $4, $5)",
+			challenge.ID, challenge.UserID, challenge.Login, challenge.CreatedAt, challenge.ExpiresAt,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"DELETE FROM totp_challenges WHERE user_id = $1 AND id NOT IN "+
+				"(SELECT id FROM totp_challenges WHERE user_id = $1 ORDER BY created_at DESC LIMIT $2)",
+			challenge.UserID, keep,
+		)
+
+		return err
+	})
+}
+
+// Spend one attempt of challenge before code is checked, so parallel codes can not exceed maxAttempts.
+// ErrChallengeExpired if challenge is unknown, expired or used up.
+func (r TwoFactorRepository) UseChallenge(ctx context.Context, id string, now time.Time, maxAttempts int) (*models.TOTPChallenge, error) {
+	var challenge models.TOTPChallenge
+
+	err := r.db.QueryRowxContext(ctx,
+		"UPDATE totp_challenges SET attempts = attempts + 1 WHERE id = $1 AND expires_at > $2 AND attempts < $3 "+
+			"RETURNING id, user_id, login, attempts, created_at, expires_at",
+		id, now, maxAttempts,
+	).StructScan(&challenge)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, entities.ErrChallengeExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// Drop challenge once login is done, ErrChallengeExpired if it is gone already, so it is used once
+func (r TwoFactorRepository) DeleteChallenge(ctx context.Context, id string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM totp_challenges WHERE id = $1", id)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrChallengeExpired
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/storage/postgres"
+	"gophkeeper/pkg/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTwoFactorRepo(t *testing.T) (*TwoFactorRepository, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	return NewTwoFactorRepository(TwoFactorRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlx.NewDb(db, "postgres")},
+	}), mock
+}
+
+func TestTwoFactorRepository_Get(t *testing.T) {
+	get := `SELECT user_id, secret, confirmed_at, last_step FROM user_totp WHERE user_id = \$1`
+
+	t.Run("Enabled", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		now := time.Now()
+		mock.ExpectQuery(get).WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "secret", "confirmed_at", "last_step"}).AddRow(1, []byte("secret"), now, 42))
+
+		tf, err := repo.Get(context.Background(), 1)
+		require.NoError(t, err)
+		assert.True(t, tf.Enabled())
+		assert.Equal(t, []byte("secret"), tf.Secret)
+		assert.Equal(t, int64(42), tf.LastStep)
+	})
+
+	t.Run("Not enrolled", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectQuery(get).WithArgs(1).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.Get(context.Background(), 1)
+		assert.ErrorIs(t, err, entities.ErrTwoFactorNotFound)
+	})
+}
+
+func TestTwoFactorRepository_SetPending(t *testing.T) {
+	upsert := `INSERT INTO user_totp \(user_id, secret\) VALUES \(\$1, \$2\) ON CONFLICT \(user_id\) DO UPDATE SET secret = EXCLUDED.secret, last_step = 0 WHERE user_totp.confirmed_at IS NULL`
+
+	t.Run("Saved", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectExec(upsert).WithArgs(1, []byte("secret")).WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetPending(context.Background(), 1, []byte("secret")))
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectExec(upsert).WithArgs(1, []byte("secret")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetPending(context.Background(), 1, []byte("secret")), entities.ErrTwoFactorEnabled)
+	})
+}
+
+func TestTwoFactorRepository_Confirm(t *testing.T) {
+	confirm := `UPDATE user_totp SET confirmed_at = NOW\(\), last_step = \$2 WHERE user_id = \$1 AND confirmed_at IS NULL`
+
+	t.Run("Confirmed", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(confirm).WithArgs(1, int64(42)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM recovery_codes WHERE user_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO recovery_codes \(user_id, code_hash\) VALUES \(\$1, \$2\)`).WithArgs(1, []byte("a")).WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectExec(`INSERT INTO recovery_codes \(user_id, code_hash\) VALUES \(\$1, \$2\)`).WithArgs(1, []byte("b")).WillReturnResult(sqlmock.NewResult(2, 1))
+		mock.ExpectCommit()
+
+		require.NoError(t, repo.Confirm(context.Background(), 1, 42, [][]byte{[]byte("a"), []byte("b")}))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Nothing pending", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(confirm).WithArgs(1, int64(42)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		assert.ErrorIs(t, repo.Confirm(context.Background(), 1, 42, nil), entities.ErrTwoFactorNotFound)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestTwoFactorRepository_UseCodes(t *testing.T) {
+	useStep := `UPDATE user_totp SET last_step = \$2 WHERE user_id = \$1 AND last_step < \$2`
+	useRecovery := `UPDATE recovery_codes SET used_at = NOW\(\) WHERE user_id = \$1 AND code_hash = \$2 AND used_at IS NULL`
+
+	repo, mock := newTwoFactorRepo(t)
+	mock.ExpectExec(useStep).WithArgs(1, int64(43)).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(useStep).WithArgs(1, int64(43)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(useRecovery).WithArgs(1, []byte("hash")).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(useRecovery).WithArgs(1, []byte("hash")).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	ctx := context.Background()
+	assert.NoError(t, repo.UseStep(ctx, 1, 43))
+	assert.ErrorIs(t, repo.UseStep(ctx, 1, 43), entities.ErrBadTOTPCode, "code replayed")
+	assert.NoError(t, repo.UseRecoveryCode(ctx, 1, []byte("hash")))
+	assert.ErrorIs(t, repo.UseRecoveryCode(ctx, 1, []byte("hash")), entities.ErrBadTOTPCode, "recovery code used twice")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_Delete(t *testing.T) {
+	repo, mock := newTwoFactorRepo(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM recovery_codes WHERE user_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 10))
+	mock.ExpectExec(`DELETE FROM user_totp WHERE user_id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Delete(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_CreateChallenge(t *testing.T) {
+	repo, mock := newTwoFactorRepo(t)
+	now := time.Now()
+	challenge := models.TOTPChallenge{ID: "abc", UserID: 1, Login: "alice", CreatedAt: now, ExpiresAt: now.Add(time.Minute)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM totp_challenges WHERE expires_at < \$1`).WithArgs(now).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO totp_challenges \(id, user_id, login, created_at, expires_at\) VALUES \(\$1, \$2, \$3, \$4, \$5\)`).
+		WithArgs("abc", 1, "alice", now, challenge.ExpiresAt).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM totp_challenges WHERE user_id = \$1 AND id NOT IN \(SELECT id FROM totp_challenges WHERE user_id = \$1 ORDER BY created_at DESC LIMIT \$2\)`).
+		WithArgs(1, 5).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.CreateChallenge(context.Background(), challenge, 5))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTwoFactorRepository_UseChallenge(t *testing.T) {
+	use := `UPDATE totp_challenges SET attempts = attempts \+ 1 WHERE id = \$1 AND expires_at > \$2 AND attempts < \$3 ` +
+		`RETURNING id, user_id, login, attempts, created_at, expires_at`
+	now := time.Now()
+
+	t.Run("Attempt left", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectQuery(use).WithArgs("abc", now, 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "login", "attempts", "created_at", "expires_at"}).
+				AddRow("abc", 1, "alice", 3, now, now.Add(time.Minute)))
+
+		challenge, err := repo.UseChallenge(context.Background(), "abc", now, 5)
+		require.NoError(t, err)
+		assert.Equal(t, "alice", challenge.Login)
+		assert.Equal(t, 3, challenge.Attempts)
+	})
+
+	t.Run("Expired or used up", func(t *testing.T) {
+		repo, mock := newTwoFactorRepo(t)
+		mock.ExpectQuery(use).WithArgs("abc", now, 5).WillReturnError(sql.ErrNoRows)
+
+		_, err := repo.UseChallenge(context.Background(), "abc", now, 5)
+		assert.ErrorIs(t, err, entities.ErrChallengeExpired)
+	})
+}
+
+func TestTwoFactorRepository_DeleteChallenge(t *testing.T) {
+	repo, mock := newTwoFactorRepo(t)
+	del := `DELETE FROM totp_challenges WHERE id = \$1`
+	mock.ExpectExec(del).WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(del).WithArgs("abc").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	require.NoError(t, repo.DeleteChallenge(context.Background(), "abc"))
+
+	// Used by another request meanwhile
+	assert.ErrorIs(t, repo.DeleteChallenge(context.Background(), "abc"), entities.ErrChallengeExpired)
+}
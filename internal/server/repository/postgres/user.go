@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/internal/server/repository"
@@ -16,6 +17,8 @@ import (
 
 var _ repository.UsersRepository = UsersRepository{}
 
+const userColumns = "id, login, created_at, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier"
+
 // User repository using PostgreSQL
 type UsersRepository struct {
 	db *sqlx.DB
@@ -38,9 +41,15 @@ func (r UsersRepository) Create(ctx context.Context, user models.User) (int, err
 	var newUserID int
 
 	result := r.db.QueryRowContext(ctx,
-		"INSERT INTO users (login, password) VALUES ($1, $2) RETURNING id",
+		"INSERT INTO users (login, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier) VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id",
 		user.Login,
 		user.Password,
+		user.Salt,
+		user.Time,
+		user.Memory,
+		user.Threads,
+		user.SRPSalt,
+		user.SRPVerifier,
 	)
 
 	err := result.Scan(&newUserID)
@@ -55,7 +64,7 @@ func (r UsersRepository) Create(ctx context.Context, user models.User) (int, err
 func (r UsersRepository) GetUserByID(ctx context.Context, ID int) (*models.User, error) {
 	var user models.User
 
-	err := r.db.QueryRowxContext(ctx, "SELECT id, login, created_at, password FROM users WHERE id = $1", ID).StructScan(&user)
+	err := r.db.QueryRowxContext(ctx, "SELECT "+userColumns+" FROM users WHERE id = $1", ID).StructScan(&user)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, entities.ErrUserNotFound
 	}
@@ -67,10 +76,77 @@ func (r UsersRepository) GetUserByID(ctx context.Context, ID int) (*models.User,
 func (r UsersRepository) GetUserByLogin(ctx context.Context, login string) (*models.User, error) {
 	var user models.User
 
-	err := r.db.QueryRowxContext(ctx, "SELECT id, login, created_at, password FROM users WHERE login = $1", login).StructScan(&user)
+	err := r.db.QueryRowxContext(ctx, "SELECT "+userColumns+" FROM users WHERE login = $1", login).StructScan(&user)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, entities.ErrUserNotFound
 	}
 
 	return &user, err
 }
+
+// Switch legacy account to auth key: store hash of auth key and KDF params, replace payloads of all secrets
+// of user with ones re-encrypted by client and drop history encrypted with old key (in one transaction).
+// Secrets missing from the list or changed since client read them abort upgrade with ErrIncompleteUpgrade.
+func (r UsersRepository) UpgradeAuth(ctx context.Context, userID int, password string, kdf models.AccountKDF, secrets []models.SecretPayload) error {
+	return runInTx(r.db, func(tx *sqlx.Tx) error {
+		if err := lockChanges(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx,
+			"UPDATE users SET password = $1, kdf_salt = $2, kdf_time = $3, kdf_memory = $4, kdf_threads = $5 WHERE id = $6 AND kdf_salt IS NULL",
+			password, kdf.Salt, kdf.Time, kdf.Memory, kdf.Threads, userID,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return errors.Join(entities.ErrAlreadyUpgraded, err)
+		}
+
+		var total int
+		if err := tx.QueryRowxContext(ctx, "SELECT count(*) FROM secrets WHERE user_id = $1", userID).Scan(&total); err != nil {
+			return err
+		}
+		if total != len(secrets) {
+			return fmt.Errorf("%w: %d of %d secrets re-encrypted", entities.ErrIncompleteUpgrade, len(secrets), total)
+		}
+
+		for _, s := range secrets {
+			res, err := tx.ExecContext(ctx,
+				"UPDATE secrets SET payload = $1, revision = revision + 1, change_seq = nextval('secret_change_seq') WHERE id = $2 AND user_id = $3 AND revision = $4",
+				s.Payload, s.ID, userID, s.Revision,
+			)
+			if err != nil {
+				return err
+			}
+			if n, err := res.RowsAffected(); err != nil || n == 0 {
+				return errors.Join(fmt.Errorf("%w: secret %d", entities.ErrIncompleteUpgrade, s.ID), err)
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "DELETE FROM secret_revisions WHERE user_id = $1", userID)
+		return err
+	})
+}
+
+// Switch account to SRP: store verifier and forget hash of auth key. Legacy accounts must be upgraded first.
+func (r UsersRepository) SetSRPVerifier(ctx context.Context, userID int, srp models.SRPVerifier) error {
+	res, err := r.db.ExecContext(ctx,
+		"UPDATE users SET password = '', srp_salt = $1, srp_verifier = $2 WHERE id = $3 AND kdf_salt IS NOT NULL",
+		srp.Salt, srp.Verifier, userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return entities.ErrLegacyAccount
+	}
+
+	return nil
+}
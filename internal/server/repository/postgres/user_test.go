@@ -17,6 +17,8 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+var testKDF = models.AccountKDF{Salt: []byte("0123456789abcdef"), Time: 3, Memory: models.MinKDFMemory, Threads: 1}
+
 func TestUsersRepository_Create(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	require.NoError(t, err)
@@ -28,13 +30,14 @@ func TestUsersRepository_Create(t *testing.T) {
 	})
 
 	t.Run("Success", func(t *testing.T) {
-		mock.ExpectQuery(`INSERT INTO users \(login, password\) VALUES \(\$1, \$2\) RETURNING id`).
-			WithArgs("testuser", "hashedpassword").
+		mock.ExpectQuery(`INSERT INTO users \(login, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier\) VALUES \(\$1, \$2, \$3, \$4, \$5, \$6, \$7, \$8\) RETURNING id`).
+			WithArgs("testuser", "hashedpassword", testKDF.Salt, testKDF.Time, testKDF.Memory, testKDF.Threads, []byte(nil), []byte(nil)).
 			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 		id, err := repo.Create(context.Background(), models.User{
-			Login:    "testuser",
-			Password: "hashedpassword",
+			Login:      "testuser",
+			Password:   "hashedpassword",
+			AccountKDF: testKDF,
 		})
 
 		assert.NoError(t, err)
@@ -54,9 +57,9 @@ func TestUsersRepository_GetUserByID(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		createdAt := time.Now()
-		rows := sqlmock.NewRows([]string{"id", "login", "created_at", "password"}).
-			AddRow(1, "testuser", createdAt, "hashedpassword")
-		mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE id = \$1`).
+		rows := sqlmock.NewRows([]string{"id", "login", "created_at", "password", "kdf_salt", "kdf_time", "kdf_memory", "kdf_threads", "srp_salt", "srp_verifier"}).
+			AddRow(1, "testuser", createdAt, "hashedpassword", testKDF.Salt, testKDF.Time, testKDF.Memory, testKDF.Threads, nil, nil)
+		mock.ExpectQuery(`SELECT id, login, created_at, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier FROM users WHERE id = \$1`).
 			WithArgs(1).
 			WillReturnRows(rows)
 
@@ -66,10 +69,11 @@ func TestUsersRepository_GetUserByID(t *testing.T) {
 		assert.NotNil(t, user)
 		assert.Equal(t, "testuser", user.Login)
 		assert.Equal(t, createdAt, user.CreatedAt)
+		assert.Equal(t, testKDF, user.AccountKDF)
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE id = \$1`).
+		mock.ExpectQuery(`SELECT id, login, created_at, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier FROM users WHERE id = \$1`).
 			WithArgs(1).
 			WillReturnError(sql.ErrNoRows)
 
@@ -93,9 +97,9 @@ func TestUsersRepository_GetUserByLogin(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
 		createdAt := time.Now()
-		rows := sqlmock.NewRows([]string{"id", "login", "created_at", "password"}).
-			AddRow(1, "testuser", createdAt, "hashedpassword")
-		mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE login = \$1`).
+		rows := sqlmock.NewRows([]string{"id", "login", "created_at", "password", "kdf_salt", "kdf_time", "kdf_memory", "kdf_threads", "srp_salt", "srp_verifier"}).
+			AddRow(1, "testuser", createdAt, "hashedpassword", testKDF.Salt, testKDF.Time, testKDF.Memory, testKDF.Threads, nil, nil)
+		mock.ExpectQuery(`SELECT id, login, created_at, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier FROM users WHERE login = \$1`).
 			WithArgs("testuser").
 			WillReturnRows(rows)
 
@@ -105,10 +109,11 @@ func TestUsersRepository_GetUserByLogin(t *testing.T) {
 		assert.NotNil(t, user)
 		assert.Equal(t, "testuser", user.Login)
 		assert.Equal(t, createdAt, user.CreatedAt)
+		assert.Equal(t, testKDF, user.AccountKDF)
 	})
 
 	t.Run("Not Found", func(t *testing.T) {
-		mock.ExpectQuery(`SELECT id, login, created_at, password FROM users WHERE login = \$1`).
+		mock.ExpectQuery(`SELECT id, login, created_at, password, kdf_salt, kdf_time, kdf_memory, kdf_threads, srp_salt, srp_verifier FROM users WHERE login = \$1`).
 			WithArgs("testuser").
 			WillReturnError(sql.ErrNoRows)
 
@@ -119,3 +124,107 @@ func TestUsersRepository_GetUserByLogin(t *testing.T) {
 		assert.True(t, errors.Is(err, entities.ErrUserNotFound))
 	})
 }
+
+func TestUsersRepository_UpgradeAuth(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	repo := NewUsersRepository(UsersRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlxDB},
+	})
+
+	secrets := []models.SecretPayload{{ID: 7, Revision: 2, Payload: []byte("payload")}}
+	expectUser := func(affected int64) {
+		mock.ExpectBegin()
+		expectChangesLock(mock, 1)
+		mock.ExpectExec(`UPDATE users SET password = \$1, kdf_salt = \$2, kdf_time = \$3, kdf_memory = \$4, kdf_threads = \$5 WHERE id = \$6 AND kdf_salt IS NULL`).
+			WithArgs("hashedkey", testKDF.Salt, testKDF.Time, testKDF.Memory, testKDF.Threads, 1).
+			WillReturnResult(sqlmock.NewResult(0, affected))
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		expectUser(1)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM secrets WHERE user_id = \$1`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectExec(`UPDATE secrets SET payload = \$1, revision = revision \+ 1, .* WHERE id = \$2 AND user_id = \$3 AND revision = \$4`).
+			WithArgs([]byte("payload"), uint64(7), 1, uint64(2)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM secret_revisions WHERE user_id = \$1`).
+			WithArgs(1).
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectCommit()
+
+		assert.NoError(t, repo.UpgradeAuth(context.Background(), 1, "hashedkey", testKDF, secrets))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Already Upgraded", func(t *testing.T) {
+		expectUser(0)
+		mock.ExpectRollback()
+
+		err := repo.UpgradeAuth(context.Background(), 1, "hashedkey", testKDF, secrets)
+		assert.ErrorIs(t, err, entities.ErrAlreadyUpgraded)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Secret Missing", func(t *testing.T) {
+		expectUser(1)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM secrets WHERE user_id = \$1`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectRollback()
+
+		err := repo.UpgradeAuth(context.Background(), 1, "hashedkey", testKDF, secrets)
+		assert.ErrorIs(t, err, entities.ErrIncompleteUpgrade)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Secret Changed", func(t *testing.T) {
+		expectUser(1)
+		mock.ExpectQuery(`SELECT count\(\*\) FROM secrets WHERE user_id = \$1`).
+			WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectExec(`UPDATE secrets SET payload`).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := repo.UpgradeAuth(context.Background(), 1, "hashedkey", testKDF, secrets)
+		assert.ErrorIs(t, err, entities.ErrIncompleteUpgrade)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestUsersRepository_SetSRPVerifier(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	sqlxDB := sqlx.NewDb(db, "postgres")
+	repo := NewUsersRepository(UsersRepositoryDependencies{
+		PostgresConn: &postgres.PostgresConn{DB: sqlxDB},
+	})
+
+	verifier := models.SRPVerifier{Salt: []byte("0123456789abcdef"), Verifier: []byte("verifier")}
+	query := `UPDATE users SET password = '', srp_salt = \$1, srp_verifier = \$2 WHERE id = \$3 AND kdf_salt IS NOT NULL`
+
+	t.Run("Success", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(verifier.Salt, verifier.Verifier, 1).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		assert.NoError(t, repo.SetSRPVerifier(context.Background(), 1, verifier))
+	})
+
+	t.Run("Legacy Account", func(t *testing.T) {
+		mock.ExpectExec(query).
+			WithArgs(verifier.Salt, verifier.Verifier, 1).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+
+		assert.ErrorIs(t, repo.SetSRPVerifier(context.Background(), 1, verifier), entities.ErrLegacyAccount)
+	})
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"gophkeeper/pkg/models"
 )
@@ -10,7 +11,14 @@ import (
 type SecretsRepository interface {
 	GetSecret(ctx context.Context, secretID uint64, userID uint64) (*models.Secret, error)
 	GetUserSecrets(ctx context.Context, userID uint64) (models.Secrets, error)
+	GetChanges(ctx context.Context, userID uint64, since uint64, limit int, withTombstones bool) (models.Secrets, []models.Tombstone, error)
+	GetSecretRevisions(ctx context.Context, secretID uint64, userID uint64) (models.Secrets, error)
+	GetSecretRevision(ctx context.Context, secretID uint64, userID uint64, revision uint64) (*models.Secret, error)
 	Create(ctx context.Context, secret *models.Secret) (uint64, error)
 	Update(ctx context.Context, secret *models.Secret) error
 	Delete(ctx context.Context, secretID uint64, userID uint64) error
+	GetTrash(ctx context.Context, userID uint64) (models.Secrets, error)
+	Restore(ctx context.Context, secretID uint64, userID uint64) error
+	Purge(ctx context.Context, secretID uint64, userID uint64) error
+	PurgeTrash(ctx context.Context, before time.Time) (int64, error)
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+	"gophkeeper/pkg/models"
+	"time"
+)
+
+//go:generate mockgen -source session.go -destination mocks/mock_session.go -package repository
+type SessionsRepository interface {
+	Create(ctx context.Context, session models.Session, refreshHash []byte) (uint64, error)
+	Rotate(ctx context.Context, refreshHash []byte, newHash []byte, address string, now time.Time, expiresAt time.Time) (*models.Session, error)
+	Get(ctx context.Context, sessionID uint64) (*models.Session, error)
+	List(ctx context.Context, userID int, now time.Time) ([]models.Session, error)
+	Revoke(ctx context.Context, userID int, sessionID uint64) error
+	RevokeOthers(ctx context.Context, userID int, keepID uint64) error
+}
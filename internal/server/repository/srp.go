@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"gophkeeper/pkg/models"
+	"time"
+)
+
+//go:generate mockgen -source srp.go -destination mocks/mock_srp.go -package repository
+type SRPHandshakesRepository interface {
+	Create(ctx context.Context, handshake models.SRPHandshake, keep int) error
+	Take(ctx context.Context, id string, now time.Time) (*models.SRPHandshake, error)
+}
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"gophkeeper/pkg/models"
+	"time"
+)
+
+//go:generate mockgen -source two_factor.go -destination mocks/mock_two_factor.go -package repository
+type TwoFactorRepository interface {
+	Get(ctx context.Context, userID int) (*models.TwoFactor, error)
+	SetPending(ctx context.Context, userID int, secret []byte) error
+	Confirm(ctx context.Context, userID int, step int64, recoveryHashes [][]byte) error
+	UseStep(ctx context.Context, userID int, step int64) error
+	UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error
+	CountRecoveryCodes(ctx context.Context, userID int) (int, error)
+	Delete(ctx context.Context, userID int) error
+	CreateChallenge(ctx context.Context, challenge models.TOTPChallenge, keep int) error
+	UseChallenge(ctx context.Context, id string, now time.Time, maxAttempts int) (*models.TOTPChallenge, error)
+	DeleteChallenge(ctx context.Context, id string) error
+}
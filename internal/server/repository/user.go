@@ -10,4 +10,6 @@ type UsersRepository interface {
 	Create(ctx context.Context, user models.User) (int, error)
 	GetUserByID(ctx context.Context, ID int) (*models.User, error)
 	GetUserByLogin(ctx context.Context, login string) (*models.User, error)
+	UpgradeAuth(ctx context.Context, userID int, password string, kdf models.AccountKDF, secrets []models.SecretPayload) error
+	SetSRPVerifier(ctx context.Context, userID int, srp models.SRPVerifier) error
 }
@@ -6,6 +6,7 @@ import (
 
 	"gophkeeper/internal/server/config"
 	"gophkeeper/internal/server/grpcbackend"
+	"gophkeeper/internal/server/service"
 
 	"gophkeeper/internal/server/storage"
 
@@ -28,16 +29,18 @@ type Server struct {
 	deps    *dig.Container
 	storage storage.ServerStorage
 
-	grpcServer *grpcbackend.GRPCServer
+	grpcServer  *grpcbackend.GRPCServer
+	trashPurger *service.TrashPurger
 }
 
 type ServerDependencies struct {
 	dig.In
 
-	Config     *config.Config
-	Storage    storage.ServerStorage
-	GRPCServer *grpcbackend.GRPCServer
-	Logger     *zap.SugaredLogger
+	Config      *config.Config
+	Storage     storage.ServerStorage
+	GRPCServer  *grpcbackend.GRPCServer
+	TrashPurger *service.TrashPurger
+	Logger      *zap.SugaredLogger
 }
 
 // Create new Server
@@ -47,7 +50,8 @@ func New(deps ServerDependencies) *Server {
 		log:     deps.Logger,
 		storage: deps.Storage,
 
-		grpcServer: deps.GRPCServer,
+		grpcServer:  deps.GRPCServer,
+		trashPurger: deps.TrashPurger,
 	}
 
 	return server
@@ -56,6 +60,7 @@ func New(deps ServerDependencies) *Server {
 // Start all subservices
 func (s *Server) Start() error {
 	s.grpcServer.Start()
+	s.trashPurger.Start()
 
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
@@ -102,6 +107,11 @@ func (s *Server) shutdown() {
 			s.log.Error(err)
 		}
 
+		s.log.Info("stopping trash purge...")
+		if err := s.trashPurger.Shutdown(stopCtx); err != nil {
+			s.log.Error(err)
+		}
+
 		close(stopped)
 	}()
 
@@ -122,4 +132,7 @@ type ServerService interface {
 	String() string
 }
 
-var _ ServerService = (*grpcbackend.GRPCServer)(nil)
+var (
+	_ ServerService = (*grpcbackend.GRPCServer)(nil)
+	_ ServerService = (*service.TrashPurger)(nil)
+)
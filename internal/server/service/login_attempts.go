@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	"net"
+	"time"
+
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+)
+
+//go:generate mockgen -source login_attempts.go -destination mocks/mock_login_attempts.go -package service
+
+var _ LoginAttemptsManager = LoginAttemptsService{}
+
+// Lockout after too many failures in a row, the longest delay between attempts
+const LoginLockout = 15 * time.Minute
+
+const (
+	loginBackoffBase = time.Second
+	loginFailWindow  = time.Hour // failures are forgotten after that long without new ones
+)
+
+// How many failures in a row key takes before logins are delayed and before it is locked out
+type attemptPolicy struct {
+	free   int
+	lockAt int
+}
+
+// Address is shared by users behind NAT, so it gets more attempts than single login
+var (
+	loginPolicy   = attemptPolicy{free: 3, lockAt: 10}
+	addressPolicy = attemptPolicy{free: 10, lockAt: 50}
+)
+
+// Delay before next attempt after failures in a row, doubled by every failure past free ones
+func (p attemptPolicy) delay(failures int) time.Duration {
+	if failures <= p.free {
+		return 0
+	}
+	if failures >= p.lockAt {
+		return LoginLockout
+	}
+
+	shift := failures - p.free - 1
+	if shift >= 32 {
+		return LoginLockout
+	}
+
+	return min(loginBackoffBase<<shift, LoginLockout)
+}
+
+// Brute-force protection of login interface. Every attempt is counted by Check before credentials are checked
+// and stays counted as failure unless Succeed or Release takes it back.
+type LoginAttemptsManager interface {
+	Check(ctx context.Context, login string, address string) error
+	Fail(ctx context.Context, login string, address string) error
+	Succeed(ctx context.Context, login string, address string) error
+	Release(ctx context.Context, login string, address string) error
+}
+
+type LoginAttemptsManagerDependencies struct {
+	dig.In
+
+	Repo   repository.LoginAttemptsRepository
+	Logger *zap.SugaredLogger
+}
+
+// Brute-force protection implementation, counters are kept in database and shared by server instances
+type LoginAttemptsService struct {
+	repo repository.LoginAttemptsRepository
+	log  *zap.SugaredLogger
+}
+
+// Create new LoginAttemptsService
+func NewLoginAttemptsService(deps LoginAttemptsManagerDependencies) *LoginAttemptsService {
+	return &LoginAttemptsService{repo: deps.Repo, log: deps.Logger}
+}
+
+// Counter of failures with its policy
+type attemptKey struct {
+	key    string
+	policy attemptPolicy
+}
+
+// Count attempt against login and address before credentials are checked, so parallel attempts can not slip
+// past the limit. LoginLockedError with time left if login or address is locked out, nothing is counted then.
+func (s LoginAttemptsService) Check(ctx context.Context, login string, address string) error {
+	now := time.Now()
+	keys := attemptKeys(login, address)
+
+	for i, k := range keys {
+		retryAfter, err := s.count(ctx, k, now)
+		if err != nil {
+			return err
+		}
+		if retryAfter == 0 {
+			continue
+		}
+
+		// Attempt does not happen, keys counted before take it back
+		for _, counted := range keys[:i] {
+			if err := s.repo.Release(ctx, counted.key); err != nil {
+				return err
+			}
+		}
+
+		return entities.ErrorLoginLocked(retryAfter)
+	}
+
+	return nil
+}
+
+// Count failure that was not counted by Check, like wrong one-time code after password
+func (s LoginAttemptsService) Fail(ctx context.Context, login string, address string) error {
+	now := time.Now()
+
+	for _, k := range attemptKeys(login, address) {
+		if _, err := s.count(ctx, k, now); err != nil {
+			return err
+		}
+	}
+
+	s.log.Infow("failed login", "login", login, "address", address)
+
+	return nil
+}
+
+// Forget failures of login once user proved credentials, attempt of address is taken back and its failures stay
+func (s LoginAttemptsService) Succeed(ctx context.Context, login string, address string) error {
+	keys := attemptKeys(login, address)
+
+	if err := s.repo.Reset(ctx, keys[0].key); err != nil {
+		return err
+	}
+
+	for _, k := range keys[1:] {
+		if err := s.repo.Release(ctx, k.key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Take back attempt of logged in user that succeeded without proving credentials, failures stay
+func (s LoginAttemptsService) Release(ctx context.Context, login string, address string) error {
+	for _, k := range attemptKeys(login, address) {
+		if err := s.repo.Release(ctx, k.key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count attempt for key and delay the next one, returns time left if key is locked out and attempt is refused
+func (s LoginAttemptsService) count(ctx context.Context, k attemptKey, now time.Time) (time.Duration, error) {
+	failures, until, err := s.repo.Attempt(ctx, k.key, now, now.Add(-loginFailWindow))
+	if err != nil {
+		return 0, err
+	}
+	if until.After(now) {
+		return until.Sub(now), nil
+	}
+
+	// Parallel attempt past the one that locks key out, it is refused and does not count
+	if failures > k.policy.lockAt {
+		return LoginLockout, s.repo.Release(ctx, k.key)
+	}
+
+	delay := k.policy.delay(failures)
+	if delay == 0 {
+		return 0, nil
+	}
+
+	if err := s.repo.Lock(ctx, k.key, now.Add(delay), k.policy.lockAt-1); err != nil {
+		return 0, err
+	}
+
+	if delay == LoginLockout {
+		s.log.Warnw("login locked out", "key", k.key, "failures", failures, "until", now.Add(delay))
+	}
+
+	return 0, nil
+}
+
+// Counters of login and of address host, port differs between connections
+func attemptKeys(login string, address string) []attemptKey {
+	keys := []attemptKey{{key: "login:" + login, policy: loginPolicy}}
+
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		address = host
+	}
+	if address != "" {
+		keys = append(keys, attemptKey{key: "addr:" + address, policy: addressPolicy})
+	}
+
+	return keys
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type MockLoginAttemptsRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginAttemptsRepository) Attempt(ctx context.Context, key string, now time.Time, resetBefore time.Time) (int, time.Time, error) {
+	args := m.Called(ctx, key, now, resetBefore)
+	return args.Int(0), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *MockLoginAttemptsRepository) Lock(ctx context.Context, key string, until time.Time, keep int) error {
+	args := m.Called(ctx, key, until, keep)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsRepository) Release(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockLoginAttemptsRepository) Reset(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func newLoginAttemptsService() (*LoginAttemptsService, *MockLoginAttemptsRepository) {
+	repo := new(MockLoginAttemptsRepository)
+
+	return NewLoginAttemptsService(LoginAttemptsManagerDependencies{Repo: repo, Logger: zap.NewNop().Sugar()}), repo
+}
+
+func TestAttemptPolicy_Delay(t *testing.T) {
+	for _, tt := range []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 1, want: 0},
+		{failures: 3, want: 0},
+		{failures: 4, want: time.Second},
+		{failures: 5, want: 2 * time.Second},
+		{failures: 9, want: 32 * time.Second},
+		{failures: 10, want: LoginLockout},
+		{failures: 1000, want: LoginLockout},
+	} {
+		assert.Equal(t, tt.want, loginPolicy.delay(tt.failures), "failures %d", tt.failures)
+	}
+
+	// Long backoff of address never outgrows lockout
+	assert.Equal(t, LoginLockout, addressPolicy.delay(addressPolicy.lockAt-1))
+}
+
+func TestLoginAttemptsService_Check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Counted up front", func(t *testing.T) {
+		service, repo := newLoginAttemptsService()
+		repo.On("Attempt", ctx, "login:alice", mock.Anything, mock.Anything).Return(loginPolicy.lockAt, time.Time{}, nil)
+		repo.On("Attempt", ctx, "addr:10.0.0.1", mock.Anything, mock.Anything).Return(1, time.Time{}, nil)
+		repo.On("Lock", ctx, "login:alice", mock.Anything, loginPolicy.lockAt-1).Return(nil)
+
+		// Attempt that reaches limit goes on, the next ones are locked out
+		require.NoError(t, service.Check(ctx, "alice", "10.0.0.1:5000"))
+
+		now := repo.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, now.Add(-loginFailWindow), repo.Calls[0].Arguments.Get(3), "failures in a row are counted within window")
+		assert.Equal(t, now.Add(LoginLockout), repo.Calls[1].Arguments.Get(2))
+		repo.AssertNotCalled(t, "Lock", ctx, "addr:10.0.0.1", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Locked", func(t *testing.T) {
+		service, repo := newLoginAttemptsService()
+		repo.On("Attempt", ctx, "login:alice", mock.Anything, mock.Anything).Return(1, time.Time{}, nil)
+		repo.On("Attempt", ctx, "addr:10.0.0.1", mock.Anything, mock.Anything).Return(60, time.Now().Add(time.Minute), nil)
+		repo.On("Release", ctx, "login:alice").Return(nil)
+
+		err := service.Check(ctx, "alice", "10.0.0.1:5000")
+
+		var locked *entities.LoginLockedError
+		require.ErrorAs(t, err, &locked)
+		assert.ErrorIs(t, err, entities.ErrLoginLocked)
+		assert.InDelta(t, time.Minute, locked.RetryAfter, float64(time.Second))
+
+		// Refused attempt is not counted against login either
+		repo.AssertCalled(t, "Release", ctx, "login:alice")
+	})
+
+	t.Run("Parallel attempt past limit", func(t *testing.T) {
+		service, repo := newLoginAttemptsService()
+		repo.On("Attempt", ctx, "login:alice", mock.Anything, mock.Anything).Return(loginPolicy.lockAt+1, time.Time{}, nil)
+		repo.On("Release", ctx, "login:alice").Return(nil)
+
+		// Requests without peer are tracked by login only
+		err := service.Check(ctx, "alice", "")
+		assert.ErrorIs(t, err, entities.ErrLoginLocked)
+		repo.AssertCalled(t, "Release", ctx, "login:alice")
+		repo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Delayed", func(t *testing.T) {
+		service, repo := newLoginAttemptsService()
+		repo.On("Attempt", ctx, "login:alice", mock.Anything, mock.Anything).Return(loginPolicy.free+2, time.Time{}, nil)
+		repo.On("Lock", ctx, "login:alice", mock.Anything, loginPolicy.lockAt-1).Return(nil)
+
+		require.NoError(t, service.Check(ctx, "alice", ""))
+
+		now := repo.Calls[0].Arguments.Get(2).(time.Time)
+		assert.Equal(t, now.Add(2*time.Second), repo.Calls[1].Arguments.Get(2))
+	})
+}
+
+func TestLoginAttemptsService_Fail(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newLoginAttemptsService()
+
+	repo.On("Attempt", ctx, "login:alice", mock.Anything, mock.Anything).Return(2, time.Time{}, nil)
+	repo.On("Attempt", ctx, "addr:10.0.0.1", mock.Anything, mock.Anything).Return(1, time.Time{}, nil)
+
+	require.NoError(t, service.Fail(ctx, "alice", "10.0.0.1:5000"))
+	repo.AssertNumberOfCalls(t, "Attempt", 2)
+	repo.AssertNotCalled(t, "Lock", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginAttemptsService_Succeed(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newLoginAttemptsService()
+	repo.On("Reset", ctx, "login:alice").Return(nil)
+	repo.On("Release", ctx, "addr:10.0.0.1").Return(nil)
+
+	// Failures of address stay, only this attempt is taken back
+	require.NoError(t, service.Succeed(ctx, "alice", "10.0.0.1:5000"))
+	repo.AssertExpectations(t)
+}
+
+func TestLoginAttemptsService_Release(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newLoginAttemptsService()
+	repo.On("Release", ctx, "login:alice").Return(nil)
+	repo.On("Release", ctx, "addr:10.0.0.1").Return(nil)
+
+	require.NoError(t, service.Release(ctx, "alice", "10.0.0.1:5000"))
+	repo.AssertExpectations(t)
+	repo.AssertNotCalled(t, "Reset", mock.Anything, mock.Anything)
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strconv"
+	"time"
+
 	"gophkeeper/internal/server/entities"
 	"gophkeeper/internal/server/repository"
 
@@ -24,8 +27,20 @@ type SecretsManager interface {
 	CreateSecret(ctx context.Context, secret *models.Secret) (*models.Secret, error)
 	UpdateSecret(ctx context.Context, secret *models.Secret) (*models.Secret, error)
 	DeleteSecret(ctx context.Context, ID uint64, userID uint64) error
+	SyncSecrets(ctx context.Context, userID uint64, cursor string, limit int) (*models.SecretChanges, error)
+	GetSecretRevisions(ctx context.Context, ID uint64, userID uint64) (models.Secrets, error)
+	GetSecretRevision(ctx context.Context, ID uint64, userID uint64, revision uint64) (*models.Secret, error)
+	GetTrash(ctx context.Context, userID uint64) (models.Secrets, error)
+	RestoreSecret(ctx context.Context, ID uint64, userID uint64) error
+	PurgeSecret(ctx context.Context, ID uint64, userID uint64) error
 }
 
+// Page size limits for delta sync
+const (
+	DefaultSyncLimit = 500
+	MaxSyncLimit     = 1000
+)
+
 type SecretsManagerDependencies struct {
 	dig.In
 	Repo repository.SecretsRepository
@@ -69,26 +84,47 @@ func (s SecretsService) GetUserSecrets(ctx context.Context, userID uint64) (mode
 	return secrets, nil
 }
 
-// Try create secret
+// Try create secret. Timestamps set by client are kept, e.g. for secrets migrated from local vault.
+// Create retried with the same key returns the secret made by the first one.
 func (s SecretsService) CreateSecret(ctx context.Context, secret *models.Secret) (*models.Secret, error) {
 	var err error
 
+	now := time.Now()
+	secret.CreatedAt = orNow(secret.CreatedAt, now)
+	secret.UpdatedAt = orNow(secret.UpdatedAt, now)
+
 	secret.ID, err = s.repo.Create(ctx, secret)
 
+	if errors.Is(err, entities.ErrCreateReplayed) {
+		return s.GetSecret(ctx, secret.ID, uint64(secret.UserID))
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to create secret: %w", err)
 	}
 
+	secret.Revision = 1
+
 	return secret, nil
 }
 
-// Try update secret
+// Try update secret. If secret was changed since client read it,
+// current stored copy is returned along with entities.ErrStaleRevision
 func (s SecretsService) UpdateSecret(ctx context.Context, secret *models.Secret) (*models.Secret, error) {
 	err := s.repo.Update(ctx, secret)
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, entities.ErrorSecretNotFound(secret.ID)
 	}
 
+	if errors.Is(err, entities.ErrStaleRevision) {
+		current, gerr := s.repo.GetSecret(ctx, secret.ID, uint64(secret.UserID))
+		if gerr != nil {
+			return nil, fmt.Errorf("failed to load current secret: %w", gerr)
+		}
+
+		return current, err
+	}
+
 	if err != nil {
 		return nil, fmt.Errorf("failed to store secret: %w", err)
 	}
@@ -96,8 +132,100 @@ func (s SecretsService) UpdateSecret(ctx context.Context, secret *models.Secret)
 	return secret, nil
 }
 
-// Delete secret
+// Move secret to trash
 func (s SecretsService) DeleteSecret(ctx context.Context, secretID uint64, userID uint64) error {
 	err := s.repo.Delete(ctx, secretID, userID)
 	return err
 }
+
+// Get changes of user's secrets since cursor, oldest first and at most limit per call.
+// Empty or unknown cursor yields a full listing with Reset set.
+func (s SecretsService) SyncSecrets(ctx context.Context, userID uint64, cursor string, limit int) (*models.SecretChanges, error) {
+	if limit <= 0 || limit > MaxSyncLimit {
+		limit = DefaultSyncLimit
+	}
+
+	since, err := strconv.ParseUint(cursor, 10, 64)
+	reset := err != nil
+
+	if reset {
+		since = 0
+	}
+
+	// One extra row tells whether there is another page, full listing has nothing to delete
+	secrets, tombstones, err := s.repo.GetChanges(ctx, userID, since, limit+1, !reset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load changes: %w", err)
+	}
+
+	changes := &models.SecretChanges{Reset: reset}
+	last := since
+
+	// Merge both feeds by change sequence
+	i, j := 0, 0
+	for n := 0; n < limit && (i < len(secrets) || j < len(tombstones)); n++ {
+		if j == len(tombstones) || (i < len(secrets) && secrets[i].ChangeSeq < tombstones[j].ChangeSeq) {
+			changes.Updated = append(changes.Updated, secrets[i])
+			last = secrets[i].ChangeSeq
+			i++
+		} else {
+			changes.Deleted = append(changes.Deleted, tombstones[j].SecretID)
+			last = tombstones[j].ChangeSeq
+			j++
+		}
+	}
+
+	changes.HasMore = i < len(secrets) || j < len(tombstones)
+	changes.Cursor = strconv.FormatUint(last, 10)
+
+	return changes, nil
+}
+
+// Get previous revisions of secret, newest first, without payloads
+func (s SecretsService) GetSecretRevisions(ctx context.Context, secretID uint64, userID uint64) (models.Secrets, error) {
+	revisions, err := s.repo.GetSecretRevisions(ctx, secretID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load revisions: %w", err)
+	}
+
+	return revisions, nil
+}
+
+// Get previous revision of secret with its payload
+func (s SecretsService) GetSecretRevision(ctx context.Context, secretID uint64, userID uint64, revision uint64) (*models.Secret, error) {
+	secret, err := s.repo.GetSecretRevision(ctx, secretID, userID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+// Get user's secrets in trash, most recently deleted first
+func (s SecretsService) GetTrash(ctx context.Context, userID uint64) (models.Secrets, error) {
+	secrets, err := s.repo.GetTrash(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trash: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// Take secret out of trash
+func (s SecretsService) RestoreSecret(ctx context.Context, secretID uint64, userID uint64) error {
+	return s.repo.Restore(ctx, secretID, userID)
+}
+
+// Permanently delete secret in trash
+func (s SecretsService) PurgeSecret(ctx context.Context, secretID uint64, userID uint64) error {
+	return s.repo.Purge(ctx, secretID, userID)
+}
+
+// Unset timestamp (zero or epoch from empty protobuf timestamp) replaced with now
+func orNow(t time.Time, now time.Time) time.Time {
+	if t.Unix() <= 0 {
+		return now
+	}
+
+	return t
+}
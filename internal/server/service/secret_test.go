@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
+	"gophkeeper/internal/server/entities"
 	"gophkeeper/pkg/models"
 
 	"github.com/stretchr/testify/assert"
@@ -30,6 +32,24 @@ func (m *MockSecretsRepository) GetUserSecrets(ctx context.Context, userID uint6
 	return args.Get(0).(models.Secrets), args.Error(1)
 }
 
+func (m *MockSecretsRepository) GetChanges(ctx context.Context, userID uint64, since uint64, limit int, withTombstones bool) (models.Secrets, []models.Tombstone, error) {
+	args := m.Called(ctx, userID, since, limit, withTombstones)
+	return args.Get(0).(models.Secrets), args.Get(1).([]models.Tombstone), args.Error(2)
+}
+
+func (m *MockSecretsRepository) GetSecretRevisions(ctx context.Context, ID uint64, userID uint64) (models.Secrets, error) {
+	args := m.Called(ctx, ID, userID)
+	return args.Get(0).(models.Secrets), args.Error(1)
+}
+
+func (m *MockSecretsRepository) GetSecretRevision(ctx context.Context, ID uint64, userID uint64, revision uint64) (*models.Secret, error) {
+	args := m.Called(ctx, ID, userID, revision)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Secret), args.Error(1)
+}
+
 func (m *MockSecretsRepository) Create(ctx context.Context, secret *models.Secret) (uint64, error) {
 	args := m.Called(ctx, secret)
 	return args.Get(0).(uint64), args.Error(1)
@@ -45,6 +65,26 @@ func (m *MockSecretsRepository) Delete(ctx context.Context, ID uint64, userID ui
 	return args.Error(0)
 }
 
+func (m *MockSecretsRepository) GetTrash(ctx context.Context, userID uint64) (models.Secrets, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(models.Secrets), args.Error(1)
+}
+
+func (m *MockSecretsRepository) Restore(ctx context.Context, ID uint64, userID uint64) error {
+	args := m.Called(ctx, ID, userID)
+	return args.Error(0)
+}
+
+func (m *MockSecretsRepository) Purge(ctx context.Context, ID uint64, userID uint64) error {
+	args := m.Called(ctx, ID, userID)
+	return args.Error(0)
+}
+
+func (m *MockSecretsRepository) PurgeTrash(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
 func TestSecretsService_GetSecret(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockSecretsRepository)
@@ -94,6 +134,30 @@ func TestSecretsService_CreateSecret(t *testing.T) {
 		mockRepo.AssertCalled(t, "Create", ctx, mockSecret)
 	})
 
+	t.Run("Keeps client timestamps", func(t *testing.T) {
+		createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockSecret := &models.Secret{UserID: 1, Title: "Migrated", CreatedAt: createdAt, UpdatedAt: time.Unix(0, 0)}
+		mockRepo.On("Create", ctx, mockSecret).Return(uint64(2), nil)
+
+		createdSecret, err := service.CreateSecret(ctx, mockSecret)
+
+		assert.NoError(t, err)
+		assert.Equal(t, createdAt, createdSecret.CreatedAt)
+		assert.WithinDuration(t, time.Now(), createdSecret.UpdatedAt, time.Minute)
+	})
+
+	t.Run("Replayed create key", func(t *testing.T) {
+		mockSecret := &models.Secret{UserID: 1, Title: "Retried", CreateKey: "key"}
+		stored := &models.Secret{ID: 3, UserID: 1, Title: "Retried", Revision: 2}
+		mockRepo.On("Create", ctx, mockSecret).Return(uint64(3), entities.ErrCreateReplayed)
+		mockRepo.On("GetSecret", ctx, uint64(3), uint64(1)).Return(stored, nil)
+
+		createdSecret, err := service.CreateSecret(ctx, mockSecret)
+
+		assert.NoError(t, err)
+		assert.Equal(t, stored, createdSecret)
+	})
+
 	t.Run("Failure", func(t *testing.T) {
 		mockSecret := &models.Secret{UserID: 1, Title: "Test Secret"}
 		mockRepo.On("Create", ctx, mockSecret).Return(uint64(0), errors.New("create error"))
@@ -106,6 +170,28 @@ func TestSecretsService_CreateSecret(t *testing.T) {
 	})
 }
 
+func TestSecretsService_UpdateSecret(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSecretsRepository)
+
+	service := NewSecretsService(SecretsManagerDependencies{
+		Repo: mockRepo,
+	})
+
+	t.Run("Stale revision", func(t *testing.T) {
+		mine := &models.Secret{ID: 1, UserID: 1, Title: "mine", Revision: 2}
+		theirs := &models.Secret{ID: 1, UserID: 1, Title: "theirs", Revision: 3}
+
+		mockRepo.On("Update", ctx, mine).Return(entities.ErrorStaleRevision(1, 3))
+		mockRepo.On("GetSecret", ctx, uint64(1), uint64(1)).Return(theirs, nil)
+
+		current, err := service.UpdateSecret(ctx, mine)
+
+		assert.ErrorIs(t, err, entities.ErrStaleRevision)
+		assert.Equal(t, theirs, current)
+	})
+}
+
 func TestSecretsService_DeleteSecret(t *testing.T) {
 	ctx := context.Background()
 	mockRepo := new(MockSecretsRepository)
@@ -123,3 +209,109 @@ func TestSecretsService_DeleteSecret(t *testing.T) {
 		mockRepo.AssertCalled(t, "Delete", ctx, uint64(1), uint64(1))
 	})
 }
+
+func TestSecretsService_SyncSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Full listing", func(t *testing.T) {
+		mockRepo := new(MockSecretsRepository)
+		service := NewSecretsService(SecretsManagerDependencies{Repo: mockRepo})
+
+		mockRepo.On("GetChanges", ctx, uint64(1), uint64(0), 3, false).Return(models.Secrets{
+			{ID: 1, ChangeSeq: 4},
+			{ID: 2, ChangeSeq: 7},
+		}, []models.Tombstone(nil), nil)
+
+		changes, err := service.SyncSecrets(ctx, 1, "", 2)
+
+		assert.NoError(t, err)
+		assert.True(t, changes.Reset)
+		assert.False(t, changes.HasMore)
+		assert.Len(t, changes.Updated, 2)
+		assert.Equal(t, "7", changes.Cursor)
+	})
+
+	t.Run("Delta with tombstones", func(t *testing.T) {
+		mockRepo := new(MockSecretsRepository)
+		service := NewSecretsService(SecretsManagerDependencies{Repo: mockRepo})
+
+		mockRepo.On("GetChanges", ctx, uint64(1), uint64(7), 3, true).Return(models.Secrets{
+			{ID: 1, ChangeSeq: 8},
+			{ID: 3, ChangeSeq: 11},
+		}, []models.Tombstone{
+			{SecretID: 2, ChangeSeq: 9},
+		}, nil)
+
+		changes, err := service.SyncSecrets(ctx, 1, "7", 2)
+
+		assert.NoError(t, err)
+		assert.False(t, changes.Reset)
+		assert.True(t, changes.HasMore)
+		assert.Equal(t, uint64(1), changes.Updated[0].ID)
+		assert.Len(t, changes.Updated, 1)
+		assert.Equal(t, []uint64{2}, changes.Deleted)
+		assert.Equal(t, "9", changes.Cursor)
+	})
+
+	t.Run("No changes", func(t *testing.T) {
+		mockRepo := new(MockSecretsRepository)
+		service := NewSecretsService(SecretsManagerDependencies{Repo: mockRepo})
+
+		mockRepo.On("GetChanges", ctx, uint64(1), uint64(9), DefaultSyncLimit+1, true).Return(models.Secrets{}, []models.Tombstone{}, nil)
+
+		changes, err := service.SyncSecrets(ctx, 1, "9", 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, changes.Updated)
+		assert.Empty(t, changes.Deleted)
+		assert.Equal(t, "9", changes.Cursor)
+	})
+}
+
+func TestSecretsService_Revisions(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSecretsRepository)
+	service := NewSecretsService(SecretsManagerDependencies{Repo: mockRepo})
+
+	t.Run("List", func(t *testing.T) {
+		mockRepo.On("GetSecretRevisions", ctx, uint64(1), uint64(1)).Return(models.Secrets{{ID: 1, Revision: 2}, {ID: 1, Revision: 1}}, nil).Once()
+
+		revisions, err := service.GetSecretRevisions(ctx, 1, 1)
+		assert.NoError(t, err)
+		assert.Len(t, revisions, 2)
+	})
+
+	t.Run("Not found", func(t *testing.T) {
+		mockRepo.On("GetSecretRevision", ctx, uint64(1), uint64(1), uint64(7)).Return(nil, entities.ErrorRevisionNotFound(1, 7)).Once()
+
+		_, err := service.GetSecretRevision(ctx, 1, 1, 7)
+		assert.ErrorIs(t, err, entities.ErrRevisionNotFound)
+	})
+}
+
+func TestSecretsService_Trash(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSecretsRepository)
+	service := NewSecretsService(SecretsManagerDependencies{Repo: mockRepo})
+
+	t.Run("List", func(t *testing.T) {
+		deletedAt := time.Now()
+		mockRepo.On("GetTrash", ctx, uint64(1)).Return(models.Secrets{{ID: 3, DeletedAt: &deletedAt}}, nil).Once()
+
+		secrets, err := service.GetTrash(ctx, 1)
+		assert.NoError(t, err)
+		assert.Len(t, secrets, 1)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		mockRepo.On("Restore", ctx, uint64(3), uint64(1)).Return(nil).Once()
+
+		assert.NoError(t, service.RestoreSecret(ctx, 3, 1))
+	})
+
+	t.Run("Purge not trashed", func(t *testing.T) {
+		mockRepo.On("Purge", ctx, uint64(4), uint64(1)).Return(entities.ErrorNotInTrash(4)).Once()
+
+		assert.ErrorIs(t, service.PurgeSecret(ctx, 4, 1), entities.ErrNotInTrash)
+	})
+}
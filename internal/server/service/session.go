@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"gophkeeper/internal/server/auth"
+	"gophkeeper/internal/server/config"
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	"gophkeeper/pkg/models"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+//go:generate mockgen -source session.go -destination mocks/mock_session.go -package service
+
+var _ SessionsManager = SessionsService{}
+
+// How long access token is accepted, client refreshes it afterwards
+const AccessTokenLifetime = 15 * time.Minute
+
+// Session not refreshed for that long expires, every refresh extends it
+const SessionLifetime = 30 * 24 * time.Hour
+
+// Token pair issued at login and on refresh
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// Login sessions service interface
+type SessionsManager interface {
+	Open(ctx context.Context, userID int, device string, address string) (Tokens, error)
+	Refresh(ctx context.Context, refreshToken string, address string) (Tokens, error)
+	Reissue(userID int, sessionID uint64) (string, error)
+	Check(ctx context.Context, userID int, sessionID uint64) error
+	List(ctx context.Context, userID int, currentID uint64) ([]models.Session, error)
+	Revoke(ctx context.Context, userID int, sessionID uint64) error
+	RevokeOthers(ctx context.Context, userID int, keepID uint64) error
+}
+
+type SessionsManagerDependencies struct {
+	dig.In
+
+	Repo   repository.SessionsRepository
+	Config *config.Config
+}
+
+// Sessions service implementation
+type SessionsService struct {
+	repo      repository.SessionsRepository
+	secretKey []byte
+}
+
+// Create new SessionsService
+func NewSessionsService(deps SessionsManagerDependencies) *SessionsService {
+	return &SessionsService{repo: deps.Repo, secretKey: []byte(deps.Config.SecretKey)}
+}
+
+// Start session of logged in user on device
+func (s SessionsService) Open(ctx context.Context, userID int, device string, address string) (Tokens, error) {
+	refresh, err := auth.CreateRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:    userID,
+		Device:    device,
+		Address:   address,
+		CreatedAt: now,
+		ExpiresAt: now.Add(SessionLifetime),
+	}
+
+	id, err := s.repo.Create(ctx, session, auth.HashRefreshToken(refresh))
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	access, err := s.Reissue(userID, id)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+// Exchange refresh token for new pair, the old one stops working.
+// Reuse of replaced token ends session with ErrRefreshReused.
+func (s SessionsService) Refresh(ctx context.Context, refreshToken string, address string) (Tokens, error) {
+	refresh, err := auth.CreateRefreshToken()
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	now := time.Now()
+	session, err := s.repo.Rotate(ctx, auth.HashRefreshToken(refreshToken), auth.HashRefreshToken(refresh), address, now, now.Add(SessionLifetime))
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	access, err := s.Reissue(session.UserID, session.ID)
+	if err != nil {
+		return Tokens{}, err
+	}
+
+	return Tokens{AccessToken: access, RefreshToken: refresh}, nil
+}
+
+// New access token for session, refresh token stays the same
+func (s SessionsService) Reissue(userID int, sessionID uint64) (string, error) {
+	return auth.CreateToken(userID, sessionID, time.Now().Add(AccessTokenLifetime), s.secretKey)
+}
+
+// Session of access token is still active: ErrSessionRevoked if it was ended, ErrSessionNotFound if it expired
+// or is not of user
+func (s SessionsService) Check(ctx context.Context, userID int, sessionID uint64) error {
+	session, err := s.repo.Get(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case session.UserID != userID:
+		return entities.ErrSessionNotFound
+	case session.RevokedAt != nil:
+		return entities.ErrSessionRevoked
+	case !session.Active(time.Now()):
+		return entities.ErrSessionNotFound
+	default:
+		return nil
+	}
+}
+
+// Active sessions of user, the one with currentID marked
+func (s SessionsService) List(ctx context.Context, userID int, currentID uint64) ([]models.Session, error) {
+	sessions, err := s.repo.List(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentID
+	}
+
+	return sessions, nil
+}
+
+// End session of user, device has to log in again
+func (s SessionsService) Revoke(ctx context.Context, userID int, sessionID uint64) error {
+	return s.repo.Revoke(ctx, userID, sessionID)
+}
+
+// End sessions of user on every other device, they have to log in again with new credentials
+func (s SessionsService) RevokeOthers(ctx context.Context, userID int, keepID uint64) error {
+	return s.repo.RevokeOthers(ctx, userID, keepID)
+}
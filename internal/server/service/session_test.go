@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/auth"
+	"gophkeeper/internal/server/config"
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/pkg/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockSessionsRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionsRepository) Create(ctx context.Context, session models.Session, refreshHash []byte) (uint64, error) {
+	args := m.Called(ctx, session, refreshHash)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (m *MockSessionsRepository) Rotate(ctx context.Context, refreshHash []byte, newHash []byte, address string, now time.Time, expiresAt time.Time) (*models.Session, error) {
+	args := m.Called(ctx, refreshHash, newHash, address, now, expiresAt)
+	session, _ := args.Get(0).(*models.Session)
+	return session, args.Error(1)
+}
+
+func (m *MockSessionsRepository) Get(ctx context.Context, sessionID uint64) (*models.Session, error) {
+	args := m.Called(ctx, sessionID)
+	session, _ := args.Get(0).(*models.Session)
+	return session, args.Error(1)
+}
+
+func (m *MockSessionsRepository) List(ctx context.Context, userID int, now time.Time) ([]models.Session, error) {
+	args := m.Called(ctx, userID, now)
+	sessions, _ := args.Get(0).([]models.Session)
+	return sessions, args.Error(1)
+}
+
+func (m *MockSessionsRepository) Revoke(ctx context.Context, userID int, sessionID uint64) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionsRepository) RevokeOthers(ctx context.Context, userID int, keepID uint64) error {
+	args := m.Called(ctx, userID, keepID)
+	return args.Error(0)
+}
+
+var testSecretKey = []byte("test-secret-key")
+
+// Claims of access token signed with test key
+func tokenClaims(t *testing.T, token string) (int, uint64) {
+	claims, err := auth.VerifyToken(token, testSecretKey)
+	require.NoError(t, err)
+
+	return int(claims["user_id"].(float64)), uint64(claims["session_id"].(float64))
+}
+
+func TestSessionsService_Open(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionsRepository)
+	service := NewSessionsService(SessionsManagerDependencies{Repo: mockRepo, Config: &config.Config{SecretKey: string(testSecretKey)}})
+
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(s models.Session) bool {
+		return s.UserID == 1 && s.Device == "laptop" && s.Address == "10.0.0.1:5000" && s.ExpiresAt.Sub(s.CreatedAt) == SessionLifetime
+	}), mock.Anything).Return(uint64(7), nil)
+
+	tokens, err := service.Open(ctx, 1, "laptop", "10.0.0.1:5000")
+	require.NoError(t, err)
+
+	userID, sessionID := tokenClaims(t, tokens.AccessToken)
+	assert.Equal(t, 1, userID)
+	assert.Equal(t, uint64(7), sessionID)
+
+	// Only hash of refresh token is stored
+	mockRepo.AssertCalled(t, "Create", ctx, mock.Anything, auth.HashRefreshToken(tokens.RefreshToken))
+}
+
+func TestSessionsService_Refresh(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionsRepository)
+	service := NewSessionsService(SessionsManagerDependencies{Repo: mockRepo, Config: &config.Config{SecretKey: string(testSecretKey)}})
+
+	var newHash []byte
+	mockRepo.On("Rotate", ctx, auth.HashRefreshToken("old"), mock.Anything, "addr", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { newHash = args.Get(2).([]byte) }).
+		Return(&models.Session{ID: 7, UserID: 1}, nil)
+	mockRepo.On("Rotate", ctx, auth.HashRefreshToken("stolen"), mock.Anything, "addr", mock.Anything, mock.Anything).
+		Return(nil, entities.ErrRefreshReused)
+
+	tokens, err := service.Refresh(ctx, "old", "addr")
+	require.NoError(t, err)
+	assert.Equal(t, auth.HashRefreshToken(tokens.RefreshToken), newHash)
+
+	userID, sessionID := tokenClaims(t, tokens.AccessToken)
+	assert.Equal(t, 1, userID)
+	assert.Equal(t, uint64(7), sessionID)
+
+	_, err = service.Refresh(ctx, "stolen", "addr")
+	assert.ErrorIs(t, err, entities.ErrRefreshReused)
+}
+
+func TestSessionsService_Check(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionsRepository)
+	service := NewSessionsService(SessionsManagerDependencies{Repo: mockRepo, Config: &config.Config{SecretKey: string(testSecretKey)}})
+
+	revoked := time.Now()
+	mockRepo.On("Get", ctx, uint64(1)).Return(&models.Session{ID: 1, UserID: 1, ExpiresAt: time.Now().Add(time.Hour)}, nil)
+	mockRepo.On("Get", ctx, uint64(2)).Return(&models.Session{ID: 2, UserID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: &revoked}, nil)
+	mockRepo.On("Get", ctx, uint64(3)).Return(&models.Session{ID: 3, UserID: 1, ExpiresAt: time.Now().Add(-time.Hour)}, nil)
+	mockRepo.On("Get", ctx, uint64(4)).Return(nil, entities.ErrSessionNotFound)
+
+	assert.NoError(t, service.Check(ctx, 1, 1))
+	assert.ErrorIs(t, service.Check(ctx, 2, 1), entities.ErrSessionNotFound, "session of another user")
+	assert.ErrorIs(t, service.Check(ctx, 1, 2), entities.ErrSessionRevoked)
+	assert.ErrorIs(t, service.Check(ctx, 1, 3), entities.ErrSessionNotFound)
+	assert.ErrorIs(t, service.Check(ctx, 1, 4), entities.ErrSessionNotFound)
+}
+
+func TestSessionsService_List(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockSessionsRepository)
+	service := NewSessionsService(SessionsManagerDependencies{Repo: mockRepo, Config: &config.Config{SecretKey: string(testSecretKey)}})
+
+	mockRepo.On("List", ctx, 1, mock.Anything).Return([]models.Session{{ID: 1}, {ID: 2}}, nil)
+
+	sessions, err := service.List(ctx, 1, 2)
+	require.NoError(t, err)
+	assert.False(t, sessions[0].Current)
+	assert.True(t, sessions[1].Current)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/utils"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/srp"
+	"time"
+)
+
+// How long server waits for client proof
+const srpHandshakeTTL = time.Minute
+
+// Handshakes of one login kept at once, older ones are dropped so nobody can pile them up
+const srpHandshakesPerLogin = 5
+
+// Server answer to first step of SRP login
+type SRPChallenge struct {
+	SessionID string
+	Salt      []byte
+	ServerKey []byte
+}
+
+// Proof that logged in user knows current credentials: auth key, or for SRP accounts
+// client proof for handshake started with StartSRP
+type CredentialProof struct {
+	AuthKey     []byte
+	SessionID   string
+	ClientProof []byte
+}
+
+// Random ID of login waiting for next step
+func newHandshakeID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// Start SRP login of user with client public key. User of unknown login is a stand-in with ID 0 made up by caller,
+// so the answer looks the same, and its login never succeeds.
+func (s UsersService) StartSRP(ctx context.Context, user *models.User, clientKey []byte) (SRPChallenge, error) {
+	if !user.UsesSRP() {
+		return SRPChallenge{}, entities.ErrBadCredentials
+	}
+
+	server, err := srp.NewServer(user.Login, user.SRPSalt, user.SRPVerifier, clientKey)
+	if err != nil {
+		return SRPChallenge{}, err
+	}
+
+	state, err := server.MarshalBinary()
+	if err != nil {
+		return SRPChallenge{}, err
+	}
+
+	id, err := newHandshakeID()
+	if err != nil {
+		return SRPChallenge{}, err
+	}
+
+	now := time.Now()
+	handshake := models.SRPHandshake{
+		ID:        id,
+		UserID:    user.ID,
+		Login:     user.Login,
+		State:     state,
+		CreatedAt: now,
+		ExpiresAt: now.Add(srpHandshakeTTL),
+	}
+
+	if err := s.handshakes.Create(ctx, handshake, srpHandshakesPerLogin); err != nil {
+		return SRPChallenge{}, err
+	}
+
+	return SRPChallenge{SessionID: id, Salt: user.SRPSalt, ServerKey: server.B}, nil
+}
+
+// Finish SRP login with client proof, returns user and server proof. Unknown or expired handshake yields no user.
+func (s UsersService) FinishSRP(ctx context.Context, sessionID string, proof []byte) (*models.User, []byte, error) {
+	hs, err := s.handshakes.Take(ctx, sessionID, time.Now())
+	if errors.Is(err, entities.ErrHandshakeNotFound) {
+		return nil, nil, entities.ErrBadCredentials
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var server srp.Server
+	if err := server.UnmarshalBinary(hs.State); err != nil {
+		return nil, nil, err
+	}
+
+	// User of handshake comes along with wrong proof, so failure is counted against its login
+	user := &models.User{ID: hs.UserID, Login: hs.Login}
+
+	serverProof, err := server.Verify(proof)
+	if err != nil || user.ID == 0 {
+		return user, nil, entities.ErrBadCredentials
+	}
+
+	return user, serverProof, nil
+}
+
+// Switch account of logged in user to SRP, hash of auth key is dropped. Accounts on SRP already get new verifier.
+// Proof of current credentials is required, ErrBadCredentials without it. Returns server proof when
+// SRP handshake was used.
+func (s UsersService) EnrollSRP(ctx context.Context, user *models.User, proof CredentialProof, verifier models.SRPVerifier) ([]byte, error) {
+	if err := validateSRP(verifier); err != nil {
+		return nil, err
+	}
+
+	var serverProof []byte
+
+	switch {
+	case user.Legacy():
+		return nil, entities.ErrLegacyAccount
+	case user.UsesSRP():
+		proven, m2, err := s.FinishSRP(ctx, proof.SessionID, proof.ClientProof)
+		if err != nil {
+			return nil, err
+		}
+		if proven.ID != user.ID {
+			return nil, entities.ErrBadCredentials
+		}
+		serverProof = m2
+	default:
+		if len(proof.AuthKey) == 0 || !utils.ComparePassword(user.Password, hex.EncodeToString(proof.AuthKey)) {
+			return nil, entities.ErrBadCredentials
+		}
+	}
+
+	if err := s.repo.SetSRPVerifier(ctx, user.ID, verifier); err != nil {
+		return nil, err
+	}
+
+	return serverProof, nil
+}
+
+func validateSRP(verifier models.SRPVerifier) error {
+	if err := verifier.Validate(); err != nil {
+		return err
+	}
+
+	if err := srp.CheckVerifier(verifier.Verifier); err != nil {
+		return fmt.Errorf("%w: %w", models.ErrBadSRP, err)
+	}
+
+	return nil
+}
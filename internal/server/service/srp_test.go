@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/srp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Handshakes kept in memory the way the table keeps them
+type testSRPHandshakes struct {
+	byID map[string]models.SRPHandshake
+}
+
+func newTestSRPHandshakes() *testSRPHandshakes {
+	return &testSRPHandshakes{byID: make(map[string]models.SRPHandshake)}
+}
+
+func (h *testSRPHandshakes) Create(_ context.Context, handshake models.SRPHandshake, _ int) error {
+	h.byID[handshake.ID] = handshake
+	return nil
+}
+
+func (h *testSRPHandshakes) Take(_ context.Context, id string, now time.Time) (*models.SRPHandshake, error) {
+	hs, ok := h.byID[id]
+	delete(h.byID, id)
+
+	if !ok || !now.Before(hs.ExpiresAt) {
+		return nil, entities.ErrHandshakeNotFound
+	}
+
+	return &hs, nil
+}
+
+func newSRPService(repo *MockUsersRepository) (*UsersService, *testSRPHandshakes) {
+	handshakes := newTestSRPHandshakes()
+
+	return NewUsersService(UsersManagerDependencies{Repo: repo, Handshakes: handshakes}), handshakes
+}
+
+func TestUsersService_SRP(t *testing.T) {
+	ctx := context.Background()
+	service, handshakes := newSRPService(new(MockUsersRepository))
+
+	salt, verifier, err := srp.NewVerifier("testuser", testAuthKey)
+	require.NoError(t, err)
+	user := &models.User{ID: 1, Login: "testuser", AccountKDF: testKDF, SRPSalt: salt, SRPVerifier: verifier}
+
+	// Client side of login, returns its proof and SRP state
+	start := func(t *testing.T, user *models.User, authKey []byte) (string, []byte, *srp.Client) {
+		client, err := srp.NewClient("testuser", authKey)
+		require.NoError(t, err)
+
+		challenge, err := service.StartSRP(ctx, user, client.A)
+		require.NoError(t, err)
+		assert.Equal(t, user.SRPSalt, challenge.Salt)
+
+		proof, err := client.Proof(challenge.Salt, challenge.ServerKey)
+		require.NoError(t, err)
+
+		return challenge.SessionID, proof, client
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		session, proof, client := start(t, user, testAuthKey)
+
+		logged, serverProof, err := service.FinishSRP(ctx, session, proof)
+		require.NoError(t, err)
+		assert.Equal(t, &models.User{ID: 1, Login: "testuser"}, logged)
+		assert.True(t, client.Verify(serverProof))
+
+		// Handshake is used once
+		_, _, err = service.FinishSRP(ctx, session, proof)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Wrong Auth Key", func(t *testing.T) {
+		session, proof, _ := start(t, user, []byte("wrong"))
+
+		failed, _, err := service.FinishSRP(ctx, session, proof)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+		assert.Equal(t, user.Login, failed.Login, "failure is counted against login")
+	})
+
+	t.Run("Stand-in For Unknown Login", func(t *testing.T) {
+		fake := *user
+		fake.ID = 0
+
+		session, proof, _ := start(t, &fake, testAuthKey)
+
+		_, _, err := service.FinishSRP(ctx, session, proof)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Expired Handshake", func(t *testing.T) {
+		session, proof, _ := start(t, user, testAuthKey)
+
+		hs := handshakes.byID[session]
+		hs.ExpiresAt = time.Now().Add(-time.Second)
+		handshakes.byID[session] = hs
+
+		_, _, err := service.FinishSRP(ctx, session, proof)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Account Without SRP", func(t *testing.T) {
+		_, err := service.StartSRP(ctx, &models.User{ID: 2, Login: "testuser", AccountKDF: testKDF}, []byte{2})
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Bad Client Key", func(t *testing.T) {
+		_, err := service.StartSRP(ctx, user, []byte{0})
+		assert.ErrorIs(t, err, srp.ErrBadPublicKey)
+	})
+}
+
+func TestUsersService_RegisterSRPUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUsersRepository)
+	service, _ := newSRPService(mockRepo)
+
+	salt, verifier, err := srp.NewVerifier("testuser", testAuthKey)
+	require.NoError(t, err)
+
+	mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, entities.ErrUserNotFound)
+	mockRepo.On("Create", ctx, mock.MatchedBy(func(user models.User) bool {
+		return user.Password == "" && user.UsesSRP()
+	})).Return(1, nil)
+
+	user, err := service.RegisterSRPUser(ctx, "testuser", testKDF, models.SRPVerifier{Salt: salt, Verifier: verifier})
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.ID)
+
+	_, err = service.RegisterSRPUser(ctx, "testuser", testKDF, models.SRPVerifier{Salt: salt, Verifier: []byte{1}})
+	assert.ErrorIs(t, err, models.ErrBadSRP)
+}
+
+func TestUsersService_EnrollSRP(t *testing.T) {
+	ctx := context.Background()
+
+	salt, verifier, err := srp.NewVerifier("testuser", testAuthKey)
+	require.NoError(t, err)
+	enrolled := models.SRPVerifier{Salt: salt, Verifier: verifier}
+
+	hash, err := hashAuthKey(testAuthKey)
+	require.NoError(t, err)
+	user := &models.User{ID: 1, Login: "testuser", Password: hash, AccountKDF: testKDF}
+
+	t.Run("Auth key account", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service, _ := newSRPService(mockRepo)
+		mockRepo.On("SetSRPVerifier", ctx, 1, enrolled).Return(nil)
+
+		_, err := service.EnrollSRP(ctx, user, CredentialProof{AuthKey: testAuthKey}, enrolled)
+		assert.NoError(t, err)
+
+		_, err = service.EnrollSRP(ctx, user, CredentialProof{}, enrolled)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+
+		_, err = service.EnrollSRP(ctx, user, CredentialProof{AuthKey: make([]byte, AuthKeyLen)}, enrolled)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+		mockRepo.AssertNumberOfCalls(t, "SetSRPVerifier", 1)
+	})
+
+	t.Run("SRP account needs fresh handshake", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service, _ := newSRPService(mockRepo)
+		mockRepo.On("SetSRPVerifier", ctx, 1, enrolled).Return(nil)
+
+		srpUser := &models.User{ID: 1, Login: "testuser", AccountKDF: testKDF, SRPSalt: salt, SRPVerifier: verifier}
+
+		// Auth key is not a proof, server does not know it
+		_, err := service.EnrollSRP(ctx, srpUser, CredentialProof{AuthKey: testAuthKey}, enrolled)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+
+		client, err := srp.NewClient("testuser", testAuthKey)
+		require.NoError(t, err)
+		challenge, err := service.StartSRP(ctx, srpUser, client.A)
+		require.NoError(t, err)
+		m1, err := client.Proof(challenge.Salt, challenge.ServerKey)
+		require.NoError(t, err)
+
+		m2, err := service.EnrollSRP(ctx, srpUser, CredentialProof{SessionID: challenge.SessionID, ClientProof: m1}, enrolled)
+		require.NoError(t, err)
+		assert.True(t, client.Verify(m2))
+		mockRepo.AssertNumberOfCalls(t, "SetSRPVerifier", 1)
+	})
+
+	t.Run("Handshake of other account", func(t *testing.T) {
+		service, _ := newSRPService(new(MockUsersRepository))
+
+		other := &models.User{ID: 2, Login: "testuser", AccountKDF: testKDF, SRPSalt: salt, SRPVerifier: verifier}
+		client, err := srp.NewClient("testuser", testAuthKey)
+		require.NoError(t, err)
+		challenge, err := service.StartSRP(ctx, other, client.A)
+		require.NoError(t, err)
+		m1, err := client.Proof(challenge.Salt, challenge.ServerKey)
+		require.NoError(t, err)
+
+		srpUser := &models.User{ID: 1, Login: "testuser", AccountKDF: testKDF, SRPSalt: salt, SRPVerifier: verifier}
+		_, err = service.EnrollSRP(ctx, srpUser, CredentialProof{SessionID: challenge.SessionID, ClientProof: m1}, enrolled)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		service, _ := newSRPService(new(MockUsersRepository))
+
+		_, err := service.EnrollSRP(ctx, &models.User{ID: 2, Login: "legacy"}, CredentialProof{AuthKey: testAuthKey}, enrolled)
+		assert.ErrorIs(t, err, entities.ErrLegacyAccount)
+
+		_, err = service.EnrollSRP(ctx, user, CredentialProof{AuthKey: testAuthKey}, models.SRPVerifier{Verifier: verifier})
+		assert.ErrorIs(t, err, models.ErrBadSRP)
+	})
+}
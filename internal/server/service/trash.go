@@ -0,0 +1,117 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gophkeeper/internal/server/config"
+	"gophkeeper/internal/server/repository"
+	"sync"
+	"time"
+
+	"go.uber.org/dig"
+	"go.uber.org/zap"
+)
+
+// How often trash is checked for expired secrets
+const trashPurgeInterval = time.Hour
+
+type TrashPurgerDependencies struct {
+	dig.In
+
+	Repo   repository.SecretsRepository
+	Config *config.Config
+	Logger *zap.SugaredLogger
+}
+
+// Background job deleting secrets which stayed in trash longer than configured retention
+type TrashPurger struct {
+	repo      repository.SecretsRepository
+	log       *zap.SugaredLogger
+	retention time.Duration
+	interval  time.Duration
+
+	stop   chan struct{}
+	done   chan struct{}
+	notify chan error
+	once   sync.Once
+}
+
+// Purger constructor, zero retention disables purging
+func NewTrashPurger(deps TrashPurgerDependencies) *TrashPurger {
+	return &TrashPurger{
+		repo:      deps.Repo,
+		log:       deps.Logger,
+		retention: deps.Config.TrashRetention,
+		interval:  trashPurgeInterval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		notify:    make(chan error, 1),
+	}
+}
+
+// Run purge right away and then periodically in a goroutine
+func (p *TrashPurger) Start() {
+	if p.retention <= 0 {
+		p.log.Info("trash purge disabled")
+		close(p.done)
+		return
+	}
+
+	go func() {
+		defer close(p.done)
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			if _, err := p.Purge(context.Background(), time.Now()); err != nil {
+				p.log.Error("trash purge failed: ", err)
+			}
+
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Delete secrets moved to trash before now minus retention
+func (p *TrashPurger) Purge(ctx context.Context, now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultTimeout)
+	defer cancel()
+
+	purged, err := p.repo.PurgeTrash(ctx, now.Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		p.log.Infof("purged %d secret(s) from trash", purged)
+	}
+
+	return purged, nil
+}
+
+// Purger never fails on its own, errors are logged
+func (p *TrashPurger) Notify() <-chan error {
+	return p.notify
+}
+
+// Stop purging and wait for current run to finish
+func (p *TrashPurger) Shutdown(ctx context.Context) error {
+	p.once.Do(func() { close(p.stop) })
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Describe itself
+func (p *TrashPurger) String() string {
+	return fmt.Sprintf("TrashPurger [retention=%s, interval=%s]", p.retention, p.interval)
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestTrashPurger(t *testing.T) {
+	now := time.Date(2026, 10, 31, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Purge expired", func(t *testing.T) {
+		mockRepo := new(MockSecretsRepository)
+		purger := NewTrashPurger(TrashPurgerDependencies{
+			Repo:   mockRepo,
+			Config: &config.Config{TrashRetention: 30 * 24 * time.Hour},
+			Logger: zap.NewNop().Sugar(),
+		})
+
+		mockRepo.On("PurgeTrash", mock.Anything, time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)).Return(int64(2), nil)
+
+		purged, err := purger.Purge(context.Background(), now)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		mockRepo := new(MockSecretsRepository)
+		purger := NewTrashPurger(TrashPurgerDependencies{
+			Repo:   mockRepo,
+			Config: &config.Config{},
+			Logger: zap.NewNop().Sugar(),
+		})
+
+		purger.Start()
+		assert.NoError(t, purger.Shutdown(context.Background()))
+		mockRepo.AssertNotCalled(t, "PurgeTrash", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/internal/server/repository"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/totp"
+	"strings"
+	"time"
+
+	"go.uber.org/dig"
+)
+
+//go:generate mockgen -source two_factor.go -destination mocks/mock_two_factor.go -package service
+
+var _ TwoFactorManager = TwoFactorService{}
+
+// Issuer shown by authenticator apps
+const TOTPIssuer = "GophKeeper"
+
+const (
+	totpSecretLen = 20 // bytes, as RFC 4226 recommends for SHA1
+	totpSkew      = 1  // steps before and after current one accepted, for clock drift
+
+	recoveryCodeCount = 10
+	recoveryCodeLen   = 10 // base32 characters, 50 bits
+)
+
+// How long server waits for code after password, and how many wrong codes it takes
+const (
+	totpChallengeTTL = 5 * time.Minute
+	maxTOTPAttempts  = 5
+)
+
+// Challenges of one user kept at once, older ones are dropped
+const totpChallengesPerUser = 5
+
+var recoveryEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// Second factor service interface
+type TwoFactorManager interface {
+	Status(ctx context.Context, userID int) (models.TwoFactorStatus, error)
+	Enroll(ctx context.Context, userID int) (models.TOTPEnrollment, error)
+	Confirm(ctx context.Context, userID int, code string) ([]string, error)
+	Disable(ctx context.Context, userID int, code string) error
+	Challenge(ctx context.Context, user *models.User) (string, error)
+	Verify(ctx context.Context, challenge string, code string) (*models.User, error)
+}
+
+type TwoFactorManagerDependencies struct {
+	dig.In
+
+	Repo      repository.TwoFactorRepository
+	UsersRepo repository.UsersRepository
+}
+
+// Second factor service implementation
+type TwoFactorService struct {
+	repo      repository.TwoFactorRepository
+	usersRepo repository.UsersRepository
+}
+
+// Create new TwoFactorService
+func NewTwoFactorService(deps TwoFactorManagerDependencies) *TwoFactorService {
+	return &TwoFactorService{repo: deps.Repo, usersRepo: deps.UsersRepo}
+}
+
+// Whether second factor is enabled and how many recovery codes are left
+func (s TwoFactorService) Status(ctx context.Context, userID int) (models.TwoFactorStatus, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	if errors.Is(err, entities.ErrTwoFactorNotFound) {
+		return models.TwoFactorStatus{}, nil
+	}
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	if !tf.Enabled() {
+		return models.TwoFactorStatus{}, nil
+	}
+
+	left, err := s.repo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+
+	return models.TwoFactorStatus{Enabled: true, RecoveryCodes: left}, nil
+}
+
+// New pending secret for user, replacing unconfirmed one. ErrTwoFactorEnabled if second factor is on already.
+func (s TwoFactorService) Enroll(ctx context.Context, userID int) (models.TOTPEnrollment, error) {
+	user, err := s.usersRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	secret := make([]byte, totpSecretLen)
+	if _, err := rand.Read(secret); err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	if err := s.repo.SetPending(ctx, userID, secret); err != nil {
+		return models.TOTPEnrollment{}, err
+	}
+
+	key := totp.Key{Secret: totp.EncodeSecret(secret), Issuer: TOTPIssuer, Account: user.Login}
+
+	return models.TOTPEnrollment{Secret: key.Secret, URI: key.URI()}, nil
+}
+
+// Enable pending second factor with code of authenticator app, returns recovery codes to show once
+func (s TwoFactorService) Confirm(ctx context.Context, userID int, code string) ([]string, error) {
+	tf, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tf.Enabled() {
+		return nil, entities.ErrTwoFactorEnabled
+	}
+
+	step, ok := matchTOTP(tf.Secret, code, time.Now())
+	if !ok {
+		return nil, entities.ErrBadTOTPCode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([][]byte, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = newRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.repo.Confirm(ctx, userID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Turn second factor off, code proves user still has authenticator or recovery code
+func (s TwoFactorService) Disable(ctx context.Context, userID int, code string) error {
+	tf, err := s.repo.Get(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !tf.Enabled() {
+		return entities.ErrTwoFactorNotFound
+	}
+
+	if err := s.checkCode(ctx, tf, code); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, userID)
+}
+
+// Challenge for user who passed password check, empty when account has no second factor
+func (s TwoFactorService) Challenge(ctx context.Context, user *models.User) (string, error) {
+	tf, err := s.repo.Get(ctx, user.ID)
+	if errors.Is(err, entities.ErrTwoFactorNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if !tf.Enabled() {
+		return "", nil
+	}
+
+	id, err := newHandshakeID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	challenge := models.TOTPChallenge{
+		ID:        id,
+		UserID:    user.ID,
+		Login:     user.Login,
+		CreatedAt: now,
+		ExpiresAt: now.Add(totpChallengeTTL),
+	}
+
+	if err := s.repo.CreateChallenge(ctx, challenge, totpChallengesPerUser); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// Complete login with code for challenge, returns ID and login of user. Every code uses up attempt of challenge
+// before it is checked, user comes along with ErrBadTOTPCode so failure can be counted against login.
+// ErrChallengeExpired means login has to start over.
+func (s TwoFactorService) Verify(ctx context.Context, challenge string, code string) (*models.User, error) {
+	ch, err := s.repo.UseChallenge(ctx, challenge, time.Now(), maxTOTPAttempts)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{ID: ch.UserID, Login: ch.Login}
+
+	tf, err := s.repo.Get(ctx, ch.UserID)
+	if errors.Is(err, entities.ErrTwoFactorNotFound) {
+		// Disabled from another device meanwhile, password was checked already
+		if err := s.repo.DeleteChallenge(ctx, challenge); err != nil {
+			return nil, err
+		}
+		return user, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.checkCode(ctx, tf, code)
+	if errors.Is(err, entities.ErrBadTOTPCode) {
+		return user, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Challenge is used once, even if two codes are checked at once
+	if err := s.repo.DeleteChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// TOTP code is accepted once per time step, anything else is taken for recovery code
+func (s TwoFactorService) checkCode(ctx context.Context, tf *models.TwoFactor, code string) error {
+	code = strings.TrimSpace(code)
+
+	if !isTOTPCode(code) {
+		return s.repo.UseRecoveryCode(ctx, tf.UserID, hashRecoveryCode(code))
+	}
+
+	step, ok := matchTOTP(tf.Secret, code, time.Now())
+	if !ok || step <= tf.LastStep {
+		return entities.ErrBadTOTPCode
+	}
+
+	return s.repo.UseStep(ctx, tf.UserID, step)
+}
+
+// Time step code was generated for, current one or its neighbours
+func matchTOTP(secret []byte, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+
+	for skew := -totpSkew; skew <= totpSkew; skew++ {
+		at := now.Add(time.Duration(skew*totp.DefaultPeriod) * time.Second)
+
+		want, err := totp.Generate(secret, at, totp.Params{})
+		if err != nil {
+			return 0, false
+		}
+
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return at.Unix() / totp.DefaultPeriod, true
+		}
+	}
+
+	return 0, false
+}
+
+func isTOTPCode(code string) bool {
+	if len(code) != totp.DefaultDigits {
+		return false
+	}
+
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Recovery code like "abcde-fghij"
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, recoveryCodeLen*5/8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	code := recoveryEncoding.EncodeToString(buf)
+
+	return code[:recoveryCodeLen/2] + "-" + code[recoveryCodeLen/2:], nil
+}
+
+// Codes are random enough for plain hash, case, dashes and spaces do not matter
+func hashRecoveryCode(code string) []byte {
+	code = strings.ToLower(strings.Join(strings.Fields(code), ""))
+	code = strings.ReplaceAll(code, "-", "")
+
+	sum := sha256.Sum256([]byte(code))
+
+	return sum[:]
+}
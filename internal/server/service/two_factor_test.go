@@ -0,0 +1,315 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gophkeeper/internal/server/entities"
+	"gophkeeper/pkg/models"
+	"gophkeeper/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+func (m *MockTwoFactorRepository) Get(ctx context.Context, userID int) (*models.TwoFactor, error) {
+	args := m.Called(ctx, userID)
+	tf, _ := args.Get(0).(*models.TwoFactor)
+	return tf, args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) SetPending(ctx context.Context, userID int, secret []byte) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) Confirm(ctx context.Context, userID int, step int64, recoveryHashes [][]byte) error {
+	args := m.Called(ctx, userID, step, recoveryHashes)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseStep(ctx context.Context, userID int, step int64) error {
+	args := m.Called(ctx, userID, step)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseRecoveryCode(ctx context.Context, userID int, codeHash []byte) error {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) CountRecoveryCodes(ctx context.Context, userID int) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) Delete(ctx context.Context, userID int) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) CreateChallenge(ctx context.Context, challenge models.TOTPChallenge, keep int) error {
+	args := m.Called(ctx, challenge, keep)
+	return args.Error(0)
+}
+
+func (m *MockTwoFactorRepository) UseChallenge(ctx context.Context, id string, now time.Time, maxAttempts int) (*models.TOTPChallenge, error) {
+	args := m.Called(ctx, id, now, maxAttempts)
+	ch, _ := args.Get(0).(*models.TOTPChallenge)
+	return ch, args.Error(1)
+}
+
+func (m *MockTwoFactorRepository) DeleteChallenge(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+var (
+	testTOTPSecret = []byte("12345678901234567890")
+	testTOTPUser   = &models.User{ID: 1, Login: "alice"}
+)
+
+// Challenge of test user is issued and found again by its ID
+func expectChallenge(repo *MockTwoFactorRepository) {
+	var id string
+
+	repo.On("CreateChallenge", mock.Anything, mock.Anything, totpChallengesPerUser).Run(func(args mock.Arguments) {
+		id = args.Get(1).(models.TOTPChallenge).ID
+	}).Return(nil)
+	repo.On("UseChallenge", mock.Anything, mock.MatchedBy(func(challenge string) bool { return challenge == id }), mock.Anything, maxTOTPAttempts).
+		Return(&models.TOTPChallenge{ID: id, UserID: 1, Login: "alice"}, nil)
+}
+
+func enabledTwoFactor(lastStep int64) *models.TwoFactor {
+	confirmed := time.Now()
+	return &models.TwoFactor{UserID: 1, Secret: testTOTPSecret, ConfirmedAt: &confirmed, LastStep: lastStep}
+}
+
+// Code of current step, not about to change before service checks it
+func currentCode(t *testing.T) (string, int64) {
+	if left := totp.Remaining(time.Now(), totp.Params{}); left < time.Second {
+		time.Sleep(left)
+	}
+
+	now := time.Now()
+	code, err := totp.Generate(testTOTPSecret, now, totp.Params{})
+	require.NoError(t, err)
+
+	return code, now.Unix() / totp.DefaultPeriod
+}
+
+func newTwoFactorService() (*TwoFactorService, *MockTwoFactorRepository, *MockUsersRepository) {
+	repo, usersRepo := new(MockTwoFactorRepository), new(MockUsersRepository)
+
+	return NewTwoFactorService(TwoFactorManagerDependencies{Repo: repo, UsersRepo: usersRepo}), repo, usersRepo
+}
+
+func TestTwoFactorService_Enroll(t *testing.T) {
+	ctx := context.Background()
+	service, repo, usersRepo := newTwoFactorService()
+
+	usersRepo.On("GetUserByID", ctx, 1).Return(&models.User{ID: 1, Login: "alice"}, nil)
+	repo.On("SetPending", ctx, 1, mock.Anything).Return(nil)
+
+	enrollment, err := service.Enroll(ctx, 1)
+	require.NoError(t, err)
+
+	key, err := totp.ParseURI(enrollment.URI)
+	require.NoError(t, err)
+	assert.Equal(t, enrollment.Secret, key.Secret)
+	assert.Equal(t, TOTPIssuer, key.Issuer)
+	assert.Equal(t, "alice", key.Account)
+
+	// Server keeps the same secret it showed
+	secret, err := totp.DecodeSecret(enrollment.Secret)
+	require.NoError(t, err)
+	repo.AssertCalled(t, "SetPending", ctx, 1, secret)
+}
+
+func TestTwoFactorService_Confirm(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Enabled with recovery codes", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		code, step := currentCode(t)
+
+		repo.On("Get", ctx, 1).Return(&models.TwoFactor{UserID: 1, Secret: testTOTPSecret}, nil)
+		repo.On("Confirm", ctx, 1, step, mock.Anything).Return(nil)
+
+		codes, err := service.Confirm(ctx, 1, code)
+		require.NoError(t, err)
+		require.Len(t, codes, recoveryCodeCount)
+		assert.Len(t, codes[0], recoveryCodeLen+1)
+
+		// Only hashes are stored, in the same order
+		hashes := repo.Calls[1].Arguments.Get(3).([][]byte)
+		assert.Equal(t, hashRecoveryCode(codes[0]), hashes[0])
+		assert.Equal(t, hashRecoveryCode(strings.ToUpper(codes[0])), hashes[0])
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(&models.TwoFactor{UserID: 1, Secret: testTOTPSecret}, nil)
+
+		_, err := service.Confirm(ctx, 1, "000000x")
+		assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+		repo.AssertNotCalled(t, "Confirm", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already enabled", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		code, _ := currentCode(t)
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+
+		_, err := service.Confirm(ctx, 1, code)
+		assert.ErrorIs(t, err, entities.ErrTwoFactorEnabled)
+	})
+}
+
+func TestTwoFactorService_Login(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("No second factor", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(nil, entities.ErrTwoFactorNotFound)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+		assert.Empty(t, challenge)
+	})
+
+	t.Run("Pending second factor", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(&models.TwoFactor{UserID: 1, Secret: testTOTPSecret}, nil)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+		assert.Empty(t, challenge)
+	})
+
+	t.Run("TOTP code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		code, step := currentCode(t)
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(step-1), nil)
+		repo.On("UseStep", ctx, 1, step).Return(nil)
+		expectChallenge(repo)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+		require.NotEmpty(t, challenge)
+
+		issued := repo.Calls[1].Arguments.Get(1).(models.TOTPChallenge)
+		assert.Equal(t, 1, issued.UserID)
+		assert.WithinDuration(t, time.Now().Add(totpChallengeTTL), issued.ExpiresAt, time.Second)
+
+		// Challenge is used once
+		repo.On("DeleteChallenge", ctx, challenge).Return(nil)
+
+		user, err := service.Verify(ctx, challenge, code)
+		require.NoError(t, err)
+		assert.Equal(t, testTOTPUser, user)
+		repo.AssertCalled(t, "DeleteChallenge", ctx, challenge)
+	})
+
+	t.Run("Expired challenge", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("UseChallenge", ctx, "stale", mock.Anything, maxTOTPAttempts).Return(nil, entities.ErrChallengeExpired)
+
+		_, err := service.Verify(ctx, "stale", "123456")
+		assert.ErrorIs(t, err, entities.ErrChallengeExpired)
+	})
+
+	t.Run("Replayed code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		code, step := currentCode(t)
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(step), nil)
+		expectChallenge(repo)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+
+		_, err = service.Verify(ctx, challenge, code)
+		assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+		repo.AssertNotCalled(t, "UseStep", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Recovery code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+		repo.On("UseRecoveryCode", ctx, 1, hashRecoveryCode("abcdefghij")).Return(nil)
+		expectChallenge(repo)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+		repo.On("DeleteChallenge", ctx, challenge).Return(nil)
+
+		user, err := service.Verify(ctx, challenge, " ABCDE-FGHIJ ")
+		require.NoError(t, err)
+		assert.Equal(t, 1, user.ID)
+	})
+
+	t.Run("Wrong codes use up attempts", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+		repo.On("UseRecoveryCode", ctx, 1, mock.Anything).Return(entities.ErrBadTOTPCode)
+		expectChallenge(repo)
+
+		challenge, err := service.Challenge(ctx, testTOTPUser)
+		require.NoError(t, err)
+
+		user, err := service.Verify(ctx, challenge, "wrong-code")
+		assert.ErrorIs(t, err, entities.ErrBadTOTPCode)
+		assert.Equal(t, "alice", user.Login, "failure is counted against login")
+		repo.AssertCalled(t, "UseChallenge", ctx, challenge, mock.Anything, maxTOTPAttempts)
+		repo.AssertNotCalled(t, "DeleteChallenge", mock.Anything, mock.Anything)
+	})
+}
+
+func TestTwoFactorService_Disable(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Disabled with code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		code, step := currentCode(t)
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+		repo.On("UseStep", ctx, 1, step).Return(nil)
+		repo.On("Delete", ctx, 1).Return(nil)
+
+		require.NoError(t, service.Disable(ctx, 1, code))
+		repo.AssertCalled(t, "Delete", ctx, 1)
+	})
+
+	t.Run("Wrong code", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+		repo.On("UseRecoveryCode", ctx, 1, mock.Anything).Return(entities.ErrBadTOTPCode)
+
+		assert.ErrorIs(t, service.Disable(ctx, 1, "nope"), entities.ErrBadTOTPCode)
+		repo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Not enabled", func(t *testing.T) {
+		service, repo, _ := newTwoFactorService()
+		repo.On("Get", ctx, 1).Return(&models.TwoFactor{UserID: 1, Secret: testTOTPSecret}, nil)
+
+		assert.ErrorIs(t, service.Disable(ctx, 1, "123456"), entities.ErrTwoFactorNotFound)
+	})
+}
+
+func TestTwoFactorService_Status(t *testing.T) {
+	ctx := context.Background()
+	service, repo, _ := newTwoFactorService()
+	repo.On("Get", ctx, 1).Return(enabledTwoFactor(0), nil)
+	repo.On("CountRecoveryCodes", ctx, 1).Return(7, nil)
+
+	status, err := service.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.TwoFactorStatus{Enabled: true, RecoveryCodes: 7}, status)
+}
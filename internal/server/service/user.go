@@ -2,7 +2,7 @@ package service
 
 import (
 	"context"
-	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"gophkeeper/internal/server/entities"
@@ -17,74 +17,173 @@ import (
 
 var _ UsersManager = UsersService{}
 
+// Length of auth key client derives from master password
+const AuthKeyLen = 32
+
+var ErrBadAuthKey = fmt.Errorf("auth key must be %d bytes", AuthKeyLen)
+
 // User service interface
 type UsersManager interface {
-	RegisterUser(ctx context.Context, login string, password string) (*models.User, error)
-	LoginUser(ctx context.Context, login string, password string) (*models.User, error)
+	GetKDF(ctx context.Context, login string) (*models.User, error)
+	GetUser(ctx context.Context, userID int) (*models.User, error)
+	RegisterUser(ctx context.Context, login string, authKey []byte, kdf models.AccountKDF) (*models.User, error)
+	LoginUser(ctx context.Context, login string, authKey []byte) (*models.User, error)
+	LoginLegacyUser(ctx context.Context, login string, password string) (*models.User, error)
+	UpgradeAuth(ctx context.Context, user *models.User, password string, authKey []byte, kdf models.AccountKDF, secrets []models.SecretPayload) error
+	RegisterSRPUser(ctx context.Context, login string, kdf models.AccountKDF, verifier models.SRPVerifier) (*models.User, error)
+	StartSRP(ctx context.Context, user *models.User, clientKey []byte) (SRPChallenge, error)
+	FinishSRP(ctx context.Context, sessionID string, proof []byte) (*models.User, []byte, error)
+	EnrollSRP(ctx context.Context, user *models.User, proof CredentialProof, verifier models.SRPVerifier) ([]byte, error)
 }
 
 type UsersManagerDependencies struct {
 	dig.In
-	Repo repository.UsersRepository
+	Repo       repository.UsersRepository
+	Handshakes repository.SRPHandshakesRepository
 }
 
 // User service implementation
 type UsersService struct {
-	repo repository.UsersRepository
+	repo       repository.UsersRepository
+	handshakes repository.SRPHandshakesRepository
 }
 
 // Create new UserService
 func NewUsersService(deps UsersManagerDependencies) *UsersService {
-	return &UsersService{repo: deps.Repo}
+	return &UsersService{repo: deps.Repo, handshakes: deps.Handshakes}
+}
+
+// User whose KDF params are requested before login, ErrUserNotFound for unknown login
+func (s UsersService) GetKDF(ctx context.Context, login string) (*models.User, error) {
+	user, err := s.repo.GetUserByLogin(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Logged in user, ErrUserNotFound if account is gone
+func (s UsersService) GetUser(ctx context.Context, userID int) (*models.User, error) {
+	return s.repo.GetUserByID(ctx, userID)
+}
+
+// Register new User, server keeps only hash of auth key
+func (s UsersService) RegisterUser(ctx context.Context, login string, authKey []byte, kdf models.AccountKDF) (*models.User, error) {
+	if len(authKey) != AuthKeyLen {
+		return nil, ErrBadAuthKey
+	}
+	if err := kdf.Validate(); err != nil {
+		return nil, err
+	}
+
+	hashedKey, err := hashAuthKey(authKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate auth key hash: %w", err)
+	}
+
+	return s.createUser(ctx, models.User{Login: login, Password: hashedKey, AccountKDF: kdf})
 }
 
-// Register new User
-func (s UsersService) RegisterUser(ctx context.Context, login string, password string) (*models.User, error) {
-	var newUser models.User
+// Register new User logging in with SRP, server keeps only verifier of auth key
+func (s UsersService) RegisterSRPUser(ctx context.Context, login string, kdf models.AccountKDF, verifier models.SRPVerifier) (*models.User, error) {
+	if err := kdf.Validate(); err != nil {
+		return nil, err
+	}
+	if err := validateSRP(verifier); err != nil {
+		return nil, err
+	}
+
+	return s.createUser(ctx, models.User{Login: login, AccountKDF: kdf, SRPSalt: verifier.Salt, SRPVerifier: verifier.Verifier})
+}
 
+func (s UsersService) createUser(ctx context.Context, newUser models.User) (*models.User, error) {
 	// ensure we have no same login
-	user, err := s.repo.GetUserByLogin(ctx, login)
+	user, err := s.repo.GetUserByLogin(ctx, newUser.Login)
 
 	if err != nil && !errors.Is(err, entities.ErrUserNotFound) {
 		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
 	if user != nil {
-		return nil, entities.ErrorUserAlreadyExists(login)
+		return nil, entities.ErrorUserAlreadyExists(newUser.Login)
 	}
 
-	hashedPassword, err := utils.HashPassword(password)
+	// create new user
+	newUser.ID, err = s.repo.Create(ctx, newUser)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate password hash: %w", err)
+		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
-	// create new user
-	newUser = models.User{Login: login, Password: hashedPassword}
+	return &newUser, nil
+}
 
-	var newUserID int
-	newUserID, err = s.repo.Create(ctx, newUser)
+// Login user with auth key
+func (s UsersService) LoginUser(ctx context.Context, login string, authKey []byte) (*models.User, error) {
+	user, err := s.findUser(ctx, login)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, err
 	}
 
-	newUser.ID = newUserID
+	if user.Legacy() || user.UsesSRP() || !utils.ComparePassword(user.Password, hex.EncodeToString(authKey)) {
+		return nil, entities.ErrBadCredentials
+	}
 
-	return &newUser, nil
+	return user, nil
 }
 
-// Login user
-func (s UsersService) LoginUser(ctx context.Context, login string, password string) (*models.User, error) {
-	user, err := s.repo.GetUserByLogin(ctx, login)
-	if errors.Is(err, sql.ErrNoRows) {
-		return user, entities.ErrBadCredentials
+// Login user of legacy account with password, accounts upgraded to auth key refuse it
+func (s UsersService) LoginLegacyUser(ctx context.Context, login string, password string) (*models.User, error) {
+	user, err := s.findUser(ctx, login)
+	if err != nil {
+		return nil, err
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("failed to authenticate user: %w", err)
+	if !user.Legacy() || !utils.ComparePassword(user.Password, password) {
+		return nil, entities.ErrBadCredentials
 	}
 
+	return user, nil
+}
+
+// Switch legacy account to auth key, along with all its secrets re-encrypted by client.
+// Access token alone is not enough: current password is checked again, ErrBadCredentials if it is wrong.
+func (s UsersService) UpgradeAuth(ctx context.Context, user *models.User, password string, authKey []byte, kdf models.AccountKDF, secrets []models.SecretPayload) error {
+	if !user.Legacy() {
+		return entities.ErrAlreadyUpgraded
+	}
 	if !utils.ComparePassword(user.Password, password) {
+		return entities.ErrBadCredentials
+	}
+
+	if len(authKey) != AuthKeyLen {
+		return ErrBadAuthKey
+	}
+	if err := kdf.Validate(); err != nil {
+		return err
+	}
+
+	hashedKey, err := hashAuthKey(authKey)
+	if err != nil {
+		return fmt.Errorf("failed to generate auth key hash: %w", err)
+	}
+
+	return s.repo.UpgradeAuth(ctx, user.ID, hashedKey, kdf, secrets)
+}
+
+func (s UsersService) findUser(ctx context.Context, login string) (*models.User, error) {
+	user, err := s.repo.GetUserByLogin(ctx, login)
+	if errors.Is(err, entities.ErrUserNotFound) {
 		return nil, entities.ErrBadCredentials
 	}
 
+	if err != nil {
+		return nil, fmt.Errorf("failed to authenticate user: %w", err)
+	}
+
 	return user, nil
 }
+
+// Auth key is hashed like password, so leaked table does not let anyone log in
+func hashAuthKey(authKey []byte) (string, error) {
+	return utils.HashPassword(hex.EncodeToString(authKey))
+}
@@ -1,8 +1,9 @@
 package service
 
 import (
+	"bytes"
 	"context"
-	"database/sql"
+	"encoding/hex"
 	"errors"
 	"testing"
 
@@ -40,6 +41,21 @@ func (m *MockUsersRepository) Create(ctx context.Context, user models.User) (int
 	return args.Int(0), args.Error(1)
 }
 
+func (m *MockUsersRepository) UpgradeAuth(ctx context.Context, userID int, password string, kdf models.AccountKDF, secrets []models.SecretPayload) error {
+	args := m.Called(ctx, userID, password, kdf, secrets)
+	return args.Error(0)
+}
+
+func (m *MockUsersRepository) SetSRPVerifier(ctx context.Context, userID int, srp models.SRPVerifier) error {
+	args := m.Called(ctx, userID, srp)
+	return args.Error(0)
+}
+
+var (
+	testAuthKey = bytes.Repeat([]byte{1}, AuthKeyLen)
+	testKDF     = models.AccountKDF{Salt: make([]byte, models.MinKDFSaltLen), Time: 3, Memory: models.MinKDFMemory, Threads: 1}
+)
+
 func TestUsersService_RegisterUser(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
@@ -53,7 +69,7 @@ func TestUsersService_RegisterUser(t *testing.T) {
 		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, entities.ErrUserNotFound)
 		mockRepo.On("Create", ctx, mock.Anything).Return(1, nil)
 
-		user, err := service.RegisterUser(ctx, "testuser", "password")
+		user, err := service.RegisterUser(ctx, "testuser", testAuthKey, testKDF)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -72,7 +88,7 @@ func TestUsersService_RegisterUser(t *testing.T) {
 
 		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(&models.User{Login: "testuser"}, nil)
 
-		user, err := service.RegisterUser(ctx, "testuser", "password")
+		user, err := service.RegisterUser(ctx, "testuser", testAuthKey, testKDF)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -91,7 +107,7 @@ func TestUsersService_RegisterUser(t *testing.T) {
 		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, entities.ErrUserNotFound)
 		mockRepo.On("Create", ctx, mock.Anything).Return(0, errors.New("create error"))
 
-		user, err := service.RegisterUser(ctx, "testuser", "password")
+		user, err := service.RegisterUser(ctx, "testuser", testAuthKey, testKDF)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -101,6 +117,23 @@ func TestUsersService_RegisterUser(t *testing.T) {
 	})
 }
 
+func TestUsersService_RegisterUserBadParams(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUsersRepository)
+
+	service := NewUsersService(UsersManagerDependencies{
+		Repo: mockRepo,
+	})
+
+	_, err := service.RegisterUser(ctx, "testuser", []byte("password"), testKDF)
+	assert.ErrorIs(t, err, ErrBadAuthKey)
+
+	_, err = service.RegisterUser(ctx, "testuser", testAuthKey, models.AccountKDF{})
+	assert.ErrorIs(t, err, models.ErrBadKDF)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
 func TestUsersService_LoginUser(t *testing.T) {
 
 	t.Run("Success", func(t *testing.T) {
@@ -111,10 +144,10 @@ func TestUsersService_LoginUser(t *testing.T) {
 			Repo: mockRepo,
 		})
 
-		pw, _ := utils.HashPassword("password")
-		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(&models.User{Login: "testuser", Password: pw}, nil)
+		pw, _ := hashAuthKey(testAuthKey)
+		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(&models.User{Login: "testuser", Password: pw, AccountKDF: testKDF}, nil)
 
-		user, err := service.LoginUser(ctx, "testuser", "password")
+		user, err := service.LoginUser(ctx, "testuser", testAuthKey)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, user)
@@ -130,9 +163,9 @@ func TestUsersService_LoginUser(t *testing.T) {
 			Repo: mockRepo,
 		})
 
-		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(&models.User{Login: "testuser", Password: "$2a$12$EXAMPLE"}, nil)
+		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(&models.User{Login: "testuser", Password: "$2a$12$EXAMPLE", AccountKDF: testKDF}, nil)
 
-		user, err := service.LoginUser(ctx, "testuser", "wrongpassword")
+		user, err := service.LoginUser(ctx, "testuser", testAuthKey)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -148,9 +181,9 @@ func TestUsersService_LoginUser(t *testing.T) {
 			Repo: mockRepo,
 		})
 
-		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, sql.ErrNoRows)
+		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, entities.ErrUserNotFound)
 
-		user, err := service.LoginUser(ctx, "testuser", "password")
+		user, err := service.LoginUser(ctx, "testuser", testAuthKey)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -168,7 +201,7 @@ func TestUsersService_LoginUser(t *testing.T) {
 
 		mockRepo.On("GetUserByLogin", ctx, "testuser").Return(nil, errors.New("repo error"))
 
-		user, err := service.LoginUser(ctx, "testuser", "password")
+		user, err := service.LoginUser(ctx, "testuser", testAuthKey)
 
 		assert.Error(t, err)
 		assert.Nil(t, user)
@@ -176,3 +209,90 @@ func TestUsersService_LoginUser(t *testing.T) {
 		mockRepo.AssertCalled(t, "GetUserByLogin", ctx, "testuser")
 	})
 }
+
+func TestUsersService_LoginLegacyUser(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockUsersRepository)
+
+	service := NewUsersService(UsersManagerDependencies{
+		Repo: mockRepo,
+	})
+
+	pw, _ := utils.HashPassword("password")
+	mockRepo.On("GetUserByLogin", ctx, "legacy").Return(&models.User{Login: "legacy", Password: pw}, nil)
+	mockRepo.On("GetUserByLogin", ctx, "upgraded").Return(&models.User{Login: "upgraded", Password: pw, AccountKDF: testKDF}, nil)
+
+	t.Run("Success", func(t *testing.T) {
+		user, err := service.LoginLegacyUser(ctx, "legacy", "password")
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy", user.Login)
+	})
+
+	t.Run("Upgraded Account Refuses Password", func(t *testing.T) {
+		_, err := service.LoginLegacyUser(ctx, "upgraded", "password")
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+
+	t.Run("Legacy Account Refuses Auth Key", func(t *testing.T) {
+		_, err := service.LoginUser(ctx, "legacy", testAuthKey)
+		assert.ErrorIs(t, err, entities.ErrBadCredentials)
+	})
+}
+
+func TestUsersService_UpgradeAuth(t *testing.T) {
+	ctx := context.Background()
+	secrets := []models.SecretPayload{{ID: 1, Revision: 2, Payload: []byte("payload")}}
+
+	pw, _ := utils.HashPassword("password")
+	legacy := &models.User{ID: 1, Login: "legacy", Password: pw}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service := NewUsersService(UsersManagerDependencies{
+			Repo: mockRepo,
+		})
+
+		mockRepo.On("UpgradeAuth", ctx, 1, mock.MatchedBy(func(hash string) bool {
+			return utils.ComparePassword(hash, hex.EncodeToString(testAuthKey))
+		}), testKDF, secrets).Return(nil)
+
+		assert.NoError(t, service.UpgradeAuth(ctx, legacy, "password", testAuthKey, testKDF, secrets))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Wrong Password", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service := NewUsersService(UsersManagerDependencies{
+			Repo: mockRepo,
+		})
+
+		assert.ErrorIs(t, service.UpgradeAuth(ctx, legacy, "guess", testAuthKey, testKDF, secrets), entities.ErrBadCredentials)
+		mockRepo.AssertNotCalled(t, "UpgradeAuth", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Already Upgraded", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service := NewUsersService(UsersManagerDependencies{
+			Repo: mockRepo,
+		})
+
+		upgraded := &models.User{ID: 1, Login: "upgraded", Password: pw, AccountKDF: testKDF}
+		assert.ErrorIs(t, service.UpgradeAuth(ctx, upgraded, "password", testAuthKey, testKDF, secrets), entities.ErrAlreadyUpgraded)
+
+		// Concurrent upgrade won the race
+		mockRepo.On("UpgradeAuth", ctx, 1, mock.Anything, testKDF, secrets).Return(entities.ErrAlreadyUpgraded)
+		assert.ErrorIs(t, service.UpgradeAuth(ctx, legacy, "password", testAuthKey, testKDF, secrets), entities.ErrAlreadyUpgraded)
+	})
+
+	t.Run("Bad KDF", func(t *testing.T) {
+		mockRepo := new(MockUsersRepository)
+		service := NewUsersService(UsersManagerDependencies{
+			Repo: mockRepo,
+		})
+
+		kdf := testKDF
+		kdf.Memory = 1024
+		assert.ErrorIs(t, service.UpgradeAuth(ctx, legacy, "password", testAuthKey, kdf, secrets), models.ErrBadKDF)
+		mockRepo.AssertNotCalled(t, "UpgradeAuth", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
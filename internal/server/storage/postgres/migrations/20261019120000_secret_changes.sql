@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE SEQUENCE secret_change_seq;
+
+ALTER TABLE secrets ADD COLUMN change_seq bigint NOT NULL DEFAULT nextval('secret_change_seq');
+CREATE INDEX secrets_user_id_change_seq_idx ON secrets (user_id, change_seq);
+
+CREATE TABLE secret_tombstones (
+    secret_id bigint PRIMARY KEY,
+    user_id integer NOT NULL,
+    change_seq bigint NOT NULL DEFAULT nextval('secret_change_seq'),
+    deleted_at timestamp NOT NULL DEFAULT NOW()
+);
+CREATE INDEX secret_tombstones_user_id_change_seq_idx ON secret_tombstones (user_id, change_seq);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE secret_tombstones;
+ALTER TABLE secrets DROP COLUMN change_seq;
+DROP SEQUENCE secret_change_seq;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE secret_revisions (
+    secret_id integer NOT NULL REFERENCES secrets (id) ON DELETE CASCADE,
+    user_id integer NOT NULL,
+    revision bigint NOT NULL,
+    title varchar(255) NOT NULL,
+    metadata TEXT,
+    secret_type secret_type NOT NULL,
+    payload bytea NOT NULL,
+    created_at timestamp NOT NULL,
+    updated_at timestamp NOT NULL,
+    PRIMARY KEY (secret_id, revision)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE secret_revisions;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE secrets ADD COLUMN deleted_at timestamp;
+CREATE INDEX secrets_deleted_at_idx ON secrets (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM secrets WHERE deleted_at IS NOT NULL;
+ALTER TABLE secrets DROP COLUMN deleted_at;
+-- +goose StatementEnd
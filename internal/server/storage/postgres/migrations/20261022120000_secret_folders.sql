@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE secrets ADD COLUMN folder TEXT NOT NULL DEFAULT '';
+ALTER TABLE secrets ADD COLUMN tags jsonb NOT NULL DEFAULT '[]';
+ALTER TABLE secret_revisions ADD COLUMN folder TEXT NOT NULL DEFAULT '';
+ALTER TABLE secret_revisions ADD COLUMN tags jsonb NOT NULL DEFAULT '[]';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE secret_revisions DROP COLUMN tags;
+ALTER TABLE secret_revisions DROP COLUMN folder;
+ALTER TABLE secrets DROP COLUMN tags;
+ALTER TABLE secrets DROP COLUMN folder;
+-- +goose StatementEnd
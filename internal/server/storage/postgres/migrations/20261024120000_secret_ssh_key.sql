@@ -0,0 +1,16 @@
+-- +goose NO TRANSACTION
+
+-- +goose Up
+ALTER TYPE secret_type ADD VALUE IF NOT EXISTS 'ssh_key';
+
+-- +goose Down
+-- Enum values can not be dropped, so type is recreated without 'ssh_key'
+-- +goose StatementBegin
+DELETE FROM secret_revisions WHERE secret_type = 'ssh_key';
+DELETE FROM secrets WHERE secret_type = 'ssh_key';
+ALTER TYPE secret_type RENAME TO secret_type_old;
+CREATE TYPE secret_type AS ENUM ('credential', 'text', 'blob', 'card', 'totp');
+ALTER TABLE secrets ALTER COLUMN secret_type TYPE secret_type USING secret_type::text::secret_type;
+ALTER TABLE secret_revisions ALTER COLUMN secret_type TYPE secret_type USING secret_type::text::secret_type;
+DROP TYPE secret_type_old;
+-- +goose StatementEnd
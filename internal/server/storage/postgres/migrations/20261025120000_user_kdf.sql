@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts without salt are legacy ones, password column keeps bcrypt of password until client upgrades them
+ALTER TABLE users ADD COLUMN kdf_salt bytea;
+ALTER TABLE users ADD COLUMN kdf_time integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN kdf_memory integer NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN kdf_threads smallint NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN kdf_salt;
+ALTER TABLE users DROP COLUMN kdf_time;
+ALTER TABLE users DROP COLUMN kdf_memory;
+ALTER TABLE users DROP COLUMN kdf_threads;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts with verifier log in with SRP and keep empty password
+ALTER TABLE users ADD COLUMN srp_salt bytea;
+ALTER TABLE users ADD COLUMN srp_verifier bytea;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN srp_salt;
+ALTER TABLE users DROP COLUMN srp_verifier;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Login sessions, one per device. Only hashes of refresh tokens are kept, previous one detects reuse of stolen token.
+CREATE TABLE sessions (
+    id bigserial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    refresh_hash bytea NOT NULL UNIQUE,
+    previous_hash bytea,
+    device varchar(255) NOT NULL DEFAULT '',
+    address varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    last_seen_at timestamp NOT NULL DEFAULT NOW(),
+    expires_at timestamp NOT NULL,
+    revoked_at timestamp
+);
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+CREATE INDEX sessions_previous_hash_idx ON sessions (previous_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE sessions;
+-- +goose StatementEnd
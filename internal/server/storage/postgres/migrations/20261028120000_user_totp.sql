@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- TOTP second factor of account, pending until confirmed with a code. last_step keeps codes from being replayed.
+CREATE TABLE user_totp (
+    user_id integer PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret bytea NOT NULL,
+    confirmed_at timestamp,
+    last_step bigint NOT NULL DEFAULT 0
+);
+-- One-time recovery codes issued when second factor is enabled, only their hashes are kept
+CREATE TABLE recovery_codes (
+    id bigserial PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash bytea NOT NULL,
+    used_at timestamp
+);
+CREATE INDEX recovery_codes_user_id_idx ON recovery_codes (user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE recovery_codes;
+DROP TABLE user_totp;
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Failed logins in a row per login and per client address, shared by all server instances.
+-- Key is "login:<login>" or "addr:<ip>", unknown logins are tracked the same way as existing ones.
+CREATE TABLE login_attempts (
+    key varchar(320) PRIMARY KEY,
+    failures integer NOT NULL DEFAULT 0,
+    last_failed_at timestamp NOT NULL,
+    locked_until timestamp
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE login_attempts;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Client-generated keys of creates, so a create retried after lost response returns the secret made first.
+-- Key outlives purged secret, retry must not bring it back. Keys expire with trash retention.
+CREATE TABLE secret_create_keys (
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    key varchar(64) NOT NULL,
+    secret_id integer REFERENCES secrets (id) ON DELETE SET NULL,
+    created_at timestamp NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE secret_create_keys;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SRP logins waiting for client proof, so the proof may come to any server instance. State holds secret
+-- of server side, user_id is 0 for stand-ins of unknown logins. Rows live for a minute.
+CREATE TABLE srp_handshakes (
+    id varchar(32) PRIMARY KEY,
+    user_id integer NOT NULL,
+    login text NOT NULL,
+    state bytea NOT NULL,
+    created_at timestamp NOT NULL,
+    expires_at timestamp NOT NULL
+);
+CREATE INDEX srp_handshakes_login_idx ON srp_handshakes (login, created_at);
+CREATE INDEX srp_handshakes_expires_at_idx ON srp_handshakes (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE srp_handshakes;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Logins that passed password check and wait for TOTP code, so the code may come to any server instance.
+-- Wrong codes are counted in attempts, rows live for five minutes.
+CREATE TABLE totp_challenges (
+    id varchar(32) PRIMARY KEY,
+    user_id integer NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    login varchar(100) NOT NULL,
+    attempts integer NOT NULL DEFAULT 0,
+    created_at timestamp NOT NULL,
+    expires_at timestamp NOT NULL
+);
+CREATE INDEX totp_challenges_user_id_idx ON totp_challenges (user_id, created_at);
+CREATE INDEX totp_challenges_expires_at_idx ON totp_challenges (expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE totp_challenges;
+-- +goose StatementEnd
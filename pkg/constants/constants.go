@@ -10,6 +10,12 @@ var (
 	// Header for client ID
 	ClientIDHeader = "Client-ID"
 
+	// Header for device name shown in list of sessions
+	DeviceHeader = "Device"
+
 	// Context key name for user_id storage
 	CtxUserIDKey CtxKey = "user_id"
+
+	// Context key name for session_id of access token
+	CtxSessionIDKey CtxKey = "session_id"
 )
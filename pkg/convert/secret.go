@@ -20,6 +20,10 @@ func ProtoToType(pbType pb.SecretType) models.SecretType {
 		return models.BlobSecret
 	case pb.SecretType_SECRET_TYPE_CARD:
 		return models.CardSecret
+	case pb.SecretType_SECRET_TYPE_TOTP:
+		return models.TOTPSecret
+	case pb.SecretType_SECRET_TYPE_SSH_KEY:
+		return models.SSHKeySecret
 	default:
 		return models.UnknownSecret
 	}
@@ -36,6 +40,10 @@ func TypeToProto(sType string) pb.SecretType {
 		return pb.SecretType_SECRET_TYPE_BLOB
 	case string(models.CardSecret):
 		return pb.SecretType_SECRET_TYPE_CARD
+	case string(models.TOTPSecret):
+		return pb.SecretType_SECRET_TYPE_TOTP
+	case string(models.SSHKeySecret):
+		return pb.SecretType_SECRET_TYPE_SSH_KEY
 	default:
 		return pb.SecretType_SECRET_TYPE_UNSPECIFIED
 	}
@@ -51,6 +59,13 @@ func SecretToProto(secret *models.Secret) *pb.Secret {
 		SecretType: TypeToProto(secret.SecretType),
 		CreatedAt:  timestamppb.New(secret.CreatedAt),
 		UpdatedAt:  timestamppb.New(secret.UpdatedAt),
+		Revision:   secret.Revision,
+		Folder:     secret.Folder,
+		Tags:       secret.Tags,
+	}
+
+	if secret.DeletedAt != nil {
+		pbSecret.DeletedAt = timestamppb.New(*secret.DeletedAt)
 	}
 
 	return pbSecret
@@ -66,6 +81,14 @@ func ProtoToSecret(pbSecret *pb.Secret) *models.Secret {
 		Payload:    pbSecret.Payload,
 		CreatedAt:  pbSecret.CreatedAt.AsTime(),
 		UpdatedAt:  pbSecret.UpdatedAt.AsTime(),
+		Revision:   pbSecret.Revision,
+		Folder:     models.CleanFolder(pbSecret.Folder),
+		Tags:       models.NormalizeTags(pbSecret.Tags),
+	}
+
+	if pbSecret.DeletedAt != nil {
+		deletedAt := pbSecret.DeletedAt.AsTime()
+		secret.DeletedAt = &deletedAt
 	}
 
 	return secret
@@ -21,6 +21,8 @@ func TestProtoToType(t *testing.T) {
 		{"Text", grpcapi.SecretType_SECRET_TYPE_TEXT, models.TextSecret},
 		{"Blob", grpcapi.SecretType_SECRET_TYPE_BLOB, models.BlobSecret},
 		{"Card", grpcapi.SecretType_SECRET_TYPE_CARD, models.CardSecret},
+		{"TOTP", grpcapi.SecretType_SECRET_TYPE_TOTP, models.TOTPSecret},
+		{"SSH key", grpcapi.SecretType_SECRET_TYPE_SSH_KEY, models.SSHKeySecret},
 		{"Unknown", grpcapi.SecretType_SECRET_TYPE_UNSPECIFIED, models.UnknownSecret},
 	}
 
@@ -42,6 +44,8 @@ func TestTypeToProto(t *testing.T) {
 		{"Text", string(models.TextSecret), grpcapi.SecretType_SECRET_TYPE_TEXT},
 		{"Blob", string(models.BlobSecret), grpcapi.SecretType_SECRET_TYPE_BLOB},
 		{"Card", string(models.CardSecret), grpcapi.SecretType_SECRET_TYPE_CARD},
+		{"TOTP", string(models.TOTPSecret), grpcapi.SecretType_SECRET_TYPE_TOTP},
+		{"SSH key", string(models.SSHKeySecret), grpcapi.SecretType_SECRET_TYPE_SSH_KEY},
 		{"Unknown", "unknown", grpcapi.SecretType_SECRET_TYPE_UNSPECIFIED},
 	}
 
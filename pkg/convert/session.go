@@ -0,0 +1,43 @@
+package convert
+
+import (
+	"gophkeeper/pkg/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "gophkeeper/pkg/proto/keeper/grpcapi"
+)
+
+// Returns protobuf sessions
+func SessionsToProto(sessions []models.Session) []*pb.Session {
+	res := make([]*pb.Session, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, &pb.Session{
+			Id:         s.ID,
+			Device:     s.Device,
+			Address:    s.Address,
+			CreatedAt:  timestamppb.New(s.CreatedAt),
+			LastSeenAt: timestamppb.New(s.LastSeenAt),
+			Current:    s.Current,
+		})
+	}
+
+	return res
+}
+
+// Returns sessions
+func ProtoToSessions(sessions []*pb.Session) []models.Session {
+	res := make([]models.Session, 0, len(sessions))
+	for _, s := range sessions {
+		res = append(res, models.Session{
+			ID:         s.Id,
+			Device:     s.Device,
+			Address:    s.Address,
+			CreatedAt:  s.CreatedAt.AsTime(),
+			LastSeenAt: s.LastSeenAt.AsTime(),
+			Current:    s.Current,
+		})
+	}
+
+	return res
+}
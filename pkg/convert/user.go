@@ -0,0 +1,66 @@
+package convert
+
+import (
+	"gophkeeper/pkg/models"
+	"math"
+
+	pb "gophkeeper/pkg/proto/keeper/grpcapi"
+)
+
+// Returns account KDF params, threads above 255 are clamped
+func ProtoToKDF(kdf *pb.AccountKDF) models.AccountKDF {
+	if kdf == nil {
+		return models.AccountKDF{}
+	}
+
+	return models.AccountKDF{
+		Salt:    kdf.Salt,
+		Time:    kdf.Time,
+		Memory:  kdf.Memory,
+		Threads: uint8(min(kdf.Threads, math.MaxUint8)),
+	}
+}
+
+// Returns protobuf account KDF params
+func KDFToProto(kdf models.AccountKDF) *pb.AccountKDF {
+	return &pb.AccountKDF{
+		Salt:    kdf.Salt,
+		Time:    kdf.Time,
+		Memory:  kdf.Memory,
+		Threads: uint32(kdf.Threads),
+	}
+}
+
+// Returns SRP verifier, empty if not set
+func ProtoToSRP(srp *pb.SRPVerifier) models.SRPVerifier {
+	if srp == nil {
+		return models.SRPVerifier{}
+	}
+
+	return models.SRPVerifier{Salt: srp.Salt, Verifier: srp.Verifier}
+}
+
+// Returns protobuf SRP verifier
+func SRPToProto(srp models.SRPVerifier) *pb.SRPVerifier {
+	return &pb.SRPVerifier{Salt: srp.Salt, Verifier: srp.Verifier}
+}
+
+// Returns re-encrypted payloads
+func ProtoToPayloads(payloads []*pb.SecretPayload) []models.SecretPayload {
+	res := make([]models.SecretPayload, 0, len(payloads))
+	for _, p := range payloads {
+		res = append(res, models.SecretPayload{ID: p.Id, Revision: p.Revision, Payload: p.Payload})
+	}
+
+	return res
+}
+
+// Returns protobuf re-encrypted payloads
+func PayloadsToProto(payloads []models.SecretPayload) []*pb.SecretPayload {
+	res := make([]*pb.SecretPayload, 0, len(payloads))
+	for _, p := range payloads {
+		res = append(res, &pb.SecretPayload{Id: p.ID, Revision: p.Revision, Payload: p.Payload})
+	}
+
+	return res
+}
@@ -0,0 +1,136 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Separator of nested folders, e.g. "work/mail"
+const FolderSeparator = "/"
+
+// Normalized folder path: no leading, trailing or repeated separators, empty for the root
+func CleanFolder(folder string) string {
+	parts := strings.Split(folder, FolderSeparator)
+
+	clean := parts[:0]
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			clean = append(clean, part)
+		}
+	}
+
+	return strings.Join(clean, FolderSeparator)
+}
+
+// Folder and all folders it is nested in, outermost first: "a/b" gives "a", "a/b"
+func FolderPath(folder string) []string {
+	folder = CleanFolder(folder)
+	if folder == "" {
+		return nil
+	}
+
+	parts := strings.Split(folder, FolderSeparator)
+
+	path := make([]string, 0, len(parts))
+	for i := range parts {
+		path = append(path, strings.Join(parts[:i+1], FolderSeparator))
+	}
+
+	return path
+}
+
+// Whether secret is in folder or any of its subfolders, every secret is in the root
+func (s Secret) InFolder(folder string) bool {
+	folder = CleanFolder(folder)
+
+	return folder == "" || s.Folder == folder || strings.HasPrefix(s.Folder, folder+FolderSeparator)
+}
+
+// Whether secret is tagged with tag, case-insensitive
+func (s Secret) HasTag(tag string) bool {
+	return slices.ContainsFunc(s.Tags, func(t string) bool {
+		return strings.EqualFold(t, tag)
+	})
+}
+
+// Secret labels, stored in Postgres as jsonb array
+type Tags []string
+
+// Tags from list separated by commas, semicolons or spaces, duplicates dropped, sorted
+func ParseTags(list string) Tags {
+	fields := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+
+	return NormalizeTags(fields)
+}
+
+// Trimmed, deduplicated case-insensitively and sorted, nil when empty
+func NormalizeTags(tags []string) Tags {
+	var res Tags
+	for _, tag := range tags {
+		tag = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+		if tag == "" || res.has(tag) {
+			continue
+		}
+
+		res = append(res, tag)
+	}
+
+	slices.SortFunc(res, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+
+	return res
+}
+
+func (t Tags) has(tag string) bool {
+	return slices.ContainsFunc(t, func(s string) bool { return strings.EqualFold(s, tag) })
+}
+
+func (t Tags) String() string {
+	return strings.Join(t, ", ")
+}
+
+// Store as jsonb array
+func (t Tags) Value() (driver.Value, error) {
+	if t == nil {
+		return "[]", nil
+	}
+
+	data, err := json.Marshal([]string(t))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(data), nil
+}
+
+// Load from jsonb array
+func (t *Tags) Scan(src any) error {
+	var data []byte
+
+	switch v := src.(type) {
+	case nil:
+		*t = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported tags type %T", src)
+	}
+
+	var tags []string
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return fmt.Errorf("failed to decode tags: %w", err)
+	}
+
+	*t = NormalizeTags(tags)
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanFolder(t *testing.T) {
+	assert.Equal(t, "work/mail", CleanFolder(" /work// mail /"))
+	assert.Equal(t, "", CleanFolder("/"))
+	assert.Equal(t, []string{"a", "a/b", "a/b/c"}, FolderPath("a/b/c/"))
+	assert.Nil(t, FolderPath(""))
+}
+
+func TestSecret_InFolder(t *testing.T) {
+	s := Secret{Folder: "work/mail", Tags: Tags{"Urgent"}}
+
+	assert.True(t, s.InFolder(""))
+	assert.True(t, s.InFolder("work"))
+	assert.True(t, s.InFolder("work/mail/"))
+	assert.False(t, s.InFolder("wor"))
+	assert.False(t, s.InFolder("work/mail/old"))
+
+	assert.True(t, s.HasTag("urgent"))
+	assert.False(t, s.HasTag("mail"))
+}
+
+func TestTags(t *testing.T) {
+	tags := ParseTags("work, #Mail;mail  home")
+	assert.Equal(t, Tags{"home", "Mail", "work"}, tags)
+	assert.Equal(t, "home, Mail, work", tags.String())
+	assert.Nil(t, ParseTags(" , "))
+
+	value, err := tags.Value()
+	require.NoError(t, err)
+	assert.Equal(t, `["home","Mail","work"]`, value)
+
+	var scanned Tags
+	require.NoError(t, scanned.Scan([]byte(`["b","a"]`)))
+	assert.Equal(t, Tags{"a", "b"}, scanned)
+
+	assert.Error(t, scanned.Scan(42))
+}
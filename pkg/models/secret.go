@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -18,15 +19,40 @@ type Secret struct {
 	Payload    []byte    `db:"payload" json:"payload"`
 	CreatedAt  time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+	Revision   uint64    `db:"revision" json:"revision"` // server-side version for optimistic concurrency
+	ChangeSeq  uint64    `db:"change_seq" json:"-"`      // server-side position in user's change feed
+	CreateKey  string    `db:"-" json:"-"`               // client-generated key of create, retried create returns secret made first
+
+	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at"` // moved to trash at, nil for live secrets
+
+	Folder string `db:"folder" json:"folder"` // path of nested folders, empty for the root
+	Tags   Tags   `db:"tags" json:"tags"`
 
 	Creds *Credentials `db:"-"`
 	Text  *Text        `db:"-"`
 	Blob  *Blob        `db:"-"`
 	Card  *Card        `db:"-"`
+	TOTP  *TOTP        `db:"-"`
+	SSH   *SSHKey      `db:"-"`
 }
 
 type Secrets []*Secret
 
+// Marker of deleted secret kept for delta sync
+type Tombstone struct {
+	SecretID  uint64 `db:"secret_id"`
+	ChangeSeq uint64 `db:"change_seq"`
+}
+
+// Changes of user's secrets since sync cursor
+type SecretChanges struct {
+	Updated Secrets  // created or updated secrets
+	Deleted []uint64 // IDs of deleted secrets (tombstones)
+	Cursor  string   // cursor to pass to the next sync
+	HasMore bool     // more changes are available with Cursor
+	Reset   bool     // full listing, client must drop its copy before applying
+}
+
 func NewSecret(t SecretType) *Secret {
 	s := Secret{SecretType: string(t)}
 
@@ -41,6 +67,8 @@ const (
 	TextSecret    SecretType = "text"
 	BlobSecret    SecretType = "blob"
 	CardSecret    SecretType = "card"
+	TOTPSecret    SecretType = "totp"
+	SSHKeySecret  SecretType = "ssh_key"
 	UnknownSecret SecretType = "unknown"
 )
 
@@ -58,6 +86,14 @@ type Blob struct {
 	FileBytes []byte `json:"file_bytes"`
 }
 
+// SSH private key in OpenSSH or PEM format
+type SSHKey struct {
+	PrivateKey string `json:"private_key"`
+	Passphrase string `json:"passphrase,omitempty"` // of encrypted private key, kept to sign without asking
+	PublicKey  string `json:"public_key"`           // authorized_keys line, derived from private key on save
+	Comment    string `json:"comment,omitempty"`
+}
+
 type Card struct {
 	Number   string `json:"number"`
 	ExpYear  uint32 `json:"exp_year"`
@@ -65,6 +101,43 @@ type Card struct {
 	CVV      uint32 `json:"cvv"`
 }
 
+// Copy with own contents, so changing it does not affect the original
+func (s Secret) Clone() Secret {
+	if s.Creds != nil {
+		creds := *s.Creds
+		s.Creds = &creds
+	}
+	if s.Text != nil {
+		text := *s.Text
+		s.Text = &text
+	}
+	if s.Blob != nil {
+		blob := *s.Blob
+		blob.FileBytes = bytes.Clone(blob.FileBytes)
+		s.Blob = &blob
+	}
+	if s.Card != nil {
+		card := *s.Card
+		s.Card = &card
+	}
+	if s.TOTP != nil {
+		otp := *s.TOTP
+		s.TOTP = &otp
+	}
+	if s.SSH != nil {
+		key := *s.SSH
+		s.SSH = &key
+	}
+	if s.DeletedAt != nil {
+		deletedAt := *s.DeletedAt
+		s.DeletedAt = &deletedAt
+	}
+	s.Tags = slices.Clone(s.Tags)
+	s.Payload = bytes.Clone(s.Payload)
+
+	return s
+}
+
 func (s Secret) ToClipboard() string {
 	var b bytes.Buffer
 
@@ -78,6 +151,11 @@ func (s Secret) ToClipboard() string {
 		b.WriteString(fmt.Sprintf("CVV: %d", s.Card.CVV))
 	case TextSecret:
 		b.WriteString(fmt.Sprintf("Text: %s\n", s.Text.Content))
+	case TOTPSecret:
+		code, _ := s.TOTP.Code(time.Now())
+		b.WriteString(code)
+	case SSHKeySecret:
+		b.WriteString(s.SSH.PublicKey) // private key never goes to clipboard
 	case BlobSecret:
 		// do nothing, file should be saved
 	}
@@ -103,13 +181,17 @@ func (s Secret) MarshalJSON() ([]byte, error) {
 		payload, err = json.Marshal(s.Text)
 	case BlobSecret:
 		payload, err = json.Marshal(s.Blob)
+	case TOTPSecret:
+		payload, err = json.Marshal(s.TOTP)
+	case SSHKeySecret:
+		payload, err = json.Marshal(s.SSH)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("secret payload marshaling failed: %w", err)
 	}
 
-	jv, err := json.Marshal(map[string]string{
+	fields := map[string]string{
 		"id":          strconv.FormatUint(s.ID, 10),
 		"title":       s.Title,
 		"secret_type": s.SecretType,
@@ -117,7 +199,30 @@ func (s Secret) MarshalJSON() ([]byte, error) {
 		"payload":     string(payload),
 		"created_at":  s.CreatedAt.Format(timeFormat),
 		"updated_at":  s.UpdatedAt.Format(timeFormat),
-	})
+	}
+
+	// Only server secrets and kept previous versions have revisions
+	if s.Revision > 0 {
+		fields["revision"] = strconv.FormatUint(s.Revision, 10)
+	}
+
+	if s.DeletedAt != nil {
+		fields["deleted_at"] = s.DeletedAt.Format(timeFormat)
+	}
+
+	if s.Folder != "" {
+		fields["folder"] = s.Folder
+	}
+
+	if len(s.Tags) > 0 {
+		tags, err := json.Marshal([]string(s.Tags))
+		if err != nil {
+			return nil, fmt.Errorf("secret tags marshaling failed: %w", err)
+		}
+		fields["tags"] = string(tags)
+	}
+
+	jv, err := json.Marshal(fields)
 
 	if err != nil {
 		return nil, fmt.Errorf("secret marshaling failed: %w", err)
@@ -138,12 +243,24 @@ func (s *Secret) UnmarshalJSON(src []byte) error {
 	}
 
 	s.ID, _ = strconv.ParseUint(data["id"], 10, 64)
+	s.Revision, _ = strconv.ParseUint(data["revision"], 10, 64)
 	s.Title = data["title"]
 	s.SecretType = data["secret_type"]
 	s.Metadata = data["metadata"]
 	s.CreatedAt, _ = time.Parse(timeFormat, data["created_at"])
 	s.UpdatedAt, _ = time.Parse(timeFormat, data["updated_at"])
 
+	if deletedAt, err := time.Parse(timeFormat, data["deleted_at"]); err == nil {
+		s.DeletedAt = &deletedAt
+	}
+
+	s.Folder = data["folder"]
+	if tags, ok := data["tags"]; ok {
+		if err = s.Tags.Scan(tags); err != nil {
+			return fmt.Errorf("secret unmarshaling failed: %w", err)
+		}
+	}
+
 	switch SecretType(data["secret_type"]) {
 	case CredSecret:
 		s.Creds = &Credentials{}
@@ -157,6 +274,12 @@ func (s *Secret) UnmarshalJSON(src []byte) error {
 	case BlobSecret:
 		s.Blob = &Blob{}
 		err = json.Unmarshal([]byte(data["payload"]), s.Blob)
+	case TOTPSecret:
+		s.TOTP = &TOTP{}
+		err = json.Unmarshal([]byte(data["payload"]), s.TOTP)
+	case SSHKeySecret:
+		s.SSH = &SSHKey{}
+		err = json.Unmarshal([]byte(data["payload"]), s.SSH)
 	}
 
 	if err != nil {
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Login session of one device. Server keeps hash of its refresh token, access tokens carry its ID.
+type Session struct {
+	ID         uint64     `json:"id" db:"id"`
+	UserID     int        `json:"user_id" db:"user_id"`
+	Device     string     `json:"device" db:"device"`   // name reported by client
+	Address    string     `json:"address" db:"address"` // address of last login or refresh
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"` // last login or refresh
+	ExpiresAt  time.Time  `json:"expires_at" db:"expires_at"`     // refresh token can not be used after it
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`     // set when session is ended, before it expires
+
+	Current bool `json:"current" db:"-"` // session of the caller
+}
+
+// Session can still be refreshed and its access tokens are accepted
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// SRP login waiting for client proof. UserID is 0 for stand-in of unknown login,
+// State is server side of exchange and never leaves server.
+type SRPHandshake struct {
+	ID        string    `db:"id"`
+	UserID    int       `db:"user_id"`
+	Login     string    `db:"login"`
+	State     []byte    `db:"state"`
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"gophkeeper/pkg/totp"
+	"time"
+)
+
+// Seed of time-based one-time passwords (RFC 6238)
+type TOTP struct {
+	Secret    string `json:"secret"` // base32 shared secret
+	Issuer    string `json:"issuer"`
+	Account   string `json:"account"`
+	Algorithm string `json:"algorithm"` // SHA1, SHA256 or SHA512, SHA1 when empty
+	Digits    int    `json:"digits"`    // 6 or 8, 6 when zero
+	Period    int    `json:"period"`    // seconds, 30 when zero
+}
+
+// TOTP from otpauth:// key
+func NewTOTP(key totp.Key) *TOTP {
+	return &TOTP{
+		Secret:    key.Secret,
+		Issuer:    key.Issuer,
+		Account:   key.Account,
+		Algorithm: string(key.Algorithm),
+		Digits:    key.Digits,
+		Period:    key.Period,
+	}
+}
+
+func (t TOTP) Key() totp.Key {
+	return totp.Key{
+		Secret:  t.Secret,
+		Issuer:  t.Issuer,
+		Account: t.Account,
+		Params:  t.params(),
+	}
+}
+
+func (t TOTP) params() totp.Params {
+	return totp.Params{Algorithm: totp.Algorithm(t.Algorithm), Digits: t.Digits, Period: t.Period}
+}
+
+// Code valid at time
+func (t TOTP) Code(at time.Time) (string, error) {
+	key, err := totp.DecodeSecret(t.Secret)
+	if err != nil {
+		return "", err
+	}
+
+	return totp.Generate(key, at, t.params())
+}
+
+// Time left until code valid at time changes
+func (t TOTP) Remaining(at time.Time) time.Duration {
+	return totp.Remaining(at, t.params())
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gophkeeper/pkg/totp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOTP(t *testing.T) {
+	// RFC 6238 SHA1 seed "12345678901234567890"
+	otp := TOTP{Secret: totp.EncodeSecret([]byte("12345678901234567890")), Digits: 8}
+
+	code, err := otp.Code(time.Unix(1111111109, 0))
+	require.NoError(t, err)
+	assert.Equal(t, "07081804", code)
+	assert.Equal(t, time.Second, otp.Remaining(time.Unix(1111111109, 0)))
+
+	_, err = TOTP{Secret: "?"}.Code(time.Now())
+	assert.ErrorIs(t, err, totp.ErrBadSecret)
+
+	key, err := totp.ParseURI("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP&digits=8")
+	require.NoError(t, err)
+	assert.Equal(t, key, NewTOTP(key).Key())
+}
+
+func TestTOTP_MarshalJSON(t *testing.T) {
+	s := Secret{
+		ID:         1,
+		Title:      "Example",
+		SecretType: string(TOTPSecret),
+		CreatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		UpdatedAt:  time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		TOTP:       &TOTP{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example", Account: "alice", Algorithm: "SHA256", Digits: 8, Period: 60},
+	}
+
+	data, err := json.Marshal(s)
+	require.NoError(t, err)
+
+	var got Secret
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, s.TOTP, got.TOTP)
+
+	clone := s.Clone()
+	clone.TOTP.Secret = "changed"
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", s.TOTP.Secret)
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// TOTP second factor of account. Server keeps the shared secret, it is pending until user confirms it with a code.
+type TwoFactor struct {
+	UserID      int        `json:"user_id" db:"user_id"`
+	Secret      []byte     `json:"-" db:"secret"`
+	ConfirmedAt *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	LastStep    int64      `json:"-" db:"last_step"` // time step of last accepted code, codes up to it are refused
+}
+
+// Login requires code
+func (t TwoFactor) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// Second factor of account as reported to its owner
+type TwoFactorStatus struct {
+	Enabled       bool
+	RecoveryCodes int // unused recovery codes left
+}
+
+// Pending TOTP secret shown to user, URI is for authenticator apps
+type TOTPEnrollment struct {
+	Secret string // base32
+	URI    string
+}
+
+// Login that passed password check and waits for TOTP code
+type TOTPChallenge struct {
+	ID        string    `db:"id"`
+	UserID    int       `db:"user_id"`
+	Login     string    `db:"login"`
+	Attempts  int       `db:"attempts"` // wrong codes so far
+	CreatedAt time.Time `db:"created_at"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -1,7 +1,31 @@
 // Models used by server
 package models
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Bounds of account KDF params. Client checks params received from server against them too,
+// so server can not weaken key derivation or make it endless.
+const (
+	MinKDFSaltLen = 16
+	MinKDFMemory  = 19 * 1024 // KiB, OWASP minimum for argon2id
+	MaxKDFMemory  = 4 * 1024 * 1024
+	MaxKDFTime    = 64
+)
+
+var (
+	ErrBadKDF = errors.New("bad account KDF params")
+	ErrBadSRP = errors.New("bad SRP verifier")
+)
+
+// Bounds of SRP verifier, 2048-bit group
+const (
+	MinSRPSaltLen  = 16
+	MaxSRPVerifier = 256
+)
 
 // Beloved one
 type User struct {
@@ -9,5 +33,68 @@ type User struct {
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 	Login     string    `json:"login" db:"login"`
-	Password  string    `json:"-" db:"password"`
+	Password  string    `json:"-" db:"password"` // hash of auth key, or of password for legacy accounts, empty with SRP
+
+	AccountKDF
+
+	SRPSalt     []byte `json:"-" db:"srp_salt"`
+	SRPVerifier []byte `json:"-" db:"srp_verifier"`
+}
+
+// Account logs in with SRP, server keeps only verifier of auth key
+func (u User) UsesSRP() bool {
+	return len(u.SRPVerifier) > 0
+}
+
+// Argon2id parameters and salt client derives auth and encryption keys with from master password.
+// Empty salt marks legacy account authenticated with password itself.
+type AccountKDF struct {
+	Salt    []byte `json:"kdf_salt" db:"kdf_salt"`
+	Time    uint32 `json:"kdf_time" db:"kdf_time"`
+	Memory  uint32 `json:"kdf_memory" db:"kdf_memory"` // KiB
+	Threads uint8  `json:"kdf_threads" db:"kdf_threads"`
+}
+
+func (k AccountKDF) Validate() error {
+	switch {
+	case len(k.Salt) < MinKDFSaltLen:
+		return fmt.Errorf("%w: salt shorter than %d bytes", ErrBadKDF, MinKDFSaltLen)
+	case k.Time == 0 || k.Time > MaxKDFTime:
+		return fmt.Errorf("%w: time must be 1-%d", ErrBadKDF, MaxKDFTime)
+	case k.Memory < MinKDFMemory || k.Memory > MaxKDFMemory:
+		return fmt.Errorf("%w: memory must be %d-%d KiB", ErrBadKDF, MinKDFMemory, MaxKDFMemory)
+	case k.Threads == 0:
+		return fmt.Errorf("%w: threads must be positive", ErrBadKDF)
+	default:
+		return nil
+	}
+}
+
+// Account still authenticates with password known to server
+func (k AccountKDF) Legacy() bool {
+	return len(k.Salt) == 0
+}
+
+// SRP salt and verifier of auth key sent by client on registration
+type SRPVerifier struct {
+	Salt     []byte
+	Verifier []byte
+}
+
+func (v SRPVerifier) Validate() error {
+	switch {
+	case len(v.Salt) < MinSRPSaltLen:
+		return fmt.Errorf("%w: salt shorter than %d bytes", ErrBadSRP, MinSRPSaltLen)
+	case len(v.Verifier) == 0 || len(v.Verifier) > MaxSRPVerifier:
+		return fmt.Errorf("%w: verifier must be 1-%d bytes", ErrBadSRP, MaxSRPVerifier)
+	default:
+		return nil
+	}
+}
+
+// Payload of secret re-encrypted by client
+type SecretPayload struct {
+	ID       uint64
+	Revision uint64
+	Payload  []byte
 }
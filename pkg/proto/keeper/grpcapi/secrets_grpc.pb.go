@@ -20,10 +20,16 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Secrets_GetUserSecretsV1_FullMethodName   = "/proto.keeper.grpcapi.Secrets/GetUserSecretsV1"
-	Secrets_GetUserSecretV1_FullMethodName    = "/proto.keeper.grpcapi.Secrets/GetUserSecretV1"
-	Secrets_SaveUserSecretV1_FullMethodName   = "/proto.keeper.grpcapi.Secrets/SaveUserSecretV1"
-	Secrets_DeleteUserSecretV1_FullMethodName = "/proto.keeper.grpcapi.Secrets/DeleteUserSecretV1"
+	Secrets_GetUserSecretsV1_FullMethodName      = "/proto.keeper.grpcapi.Secrets/GetUserSecretsV1"
+	Secrets_GetUserSecretV1_FullMethodName       = "/proto.keeper.grpcapi.Secrets/GetUserSecretV1"
+	Secrets_SaveUserSecretV1_FullMethodName      = "/proto.keeper.grpcapi.Secrets/SaveUserSecretV1"
+	Secrets_DeleteUserSecretV1_FullMethodName    = "/proto.keeper.grpcapi.Secrets/DeleteUserSecretV1"
+	Secrets_SyncV1_FullMethodName                = "/proto.keeper.grpcapi.Secrets/SyncV1"
+	Secrets_ListSecretRevisionsV1_FullMethodName = "/proto.keeper.grpcapi.Secrets/ListSecretRevisionsV1"
+	Secrets_GetSecretRevisionV1_FullMethodName   = "/proto.keeper.grpcapi.Secrets/GetSecretRevisionV1"
+	Secrets_ListTrashV1_FullMethodName           = "/proto.keeper.grpcapi.Secrets/ListTrashV1"
+	Secrets_RestoreSecretV1_FullMethodName       = "/proto.keeper.grpcapi.Secrets/RestoreSecretV1"
+	Secrets_PurgeSecretV1_FullMethodName         = "/proto.keeper.grpcapi.Secrets/PurgeSecretV1"
 )
 
 // SecretsClient is the client API for Secrets service.
@@ -32,8 +38,14 @@ const (
 type SecretsClient interface {
 	GetUserSecretsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*GetUserSecretsResponseV1, error)
 	GetUserSecretV1(ctx context.Context, in *GetUserSecretRequestV1, opts ...grpc.CallOption) (*GetUserSecretResponseV1, error)
-	SaveUserSecretV1(ctx context.Context, in *SaveUserSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SaveUserSecretV1(ctx context.Context, in *SaveUserSecretRequestV1, opts ...grpc.CallOption) (*SaveUserSecretResponseV1, error)
 	DeleteUserSecretV1(ctx context.Context, in *DeleteUserSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SyncV1(ctx context.Context, in *SyncRequestV1, opts ...grpc.CallOption) (*SyncResponseV1, error)
+	ListSecretRevisionsV1(ctx context.Context, in *ListSecretRevisionsRequestV1, opts ...grpc.CallOption) (*ListSecretRevisionsResponseV1, error)
+	GetSecretRevisionV1(ctx context.Context, in *GetSecretRevisionRequestV1, opts ...grpc.CallOption) (*GetSecretRevisionResponseV1, error)
+	ListTrashV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListTrashResponseV1, error)
+	RestoreSecretV1(ctx context.Context, in *RestoreSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PurgeSecretV1(ctx context.Context, in *PurgeSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type secretsClient struct {
@@ -64,9 +76,9 @@ func (c *secretsClient) GetUserSecretV1(ctx context.Context, in *GetUserSecretRe
 	return out, nil
 }
 
-func (c *secretsClient) SaveUserSecretV1(ctx context.Context, in *SaveUserSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *secretsClient) SaveUserSecretV1(ctx context.Context, in *SaveUserSecretRequestV1, opts ...grpc.CallOption) (*SaveUserSecretResponseV1, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	out := new(SaveUserSecretResponseV1)
 	err := c.cc.Invoke(ctx, Secrets_SaveUserSecretV1_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -84,14 +96,80 @@ func (c *secretsClient) DeleteUserSecretV1(ctx context.Context, in *DeleteUserSe
 	return out, nil
 }
 
+func (c *secretsClient) SyncV1(ctx context.Context, in *SyncRequestV1, opts ...grpc.CallOption) (*SyncResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SyncResponseV1)
+	err := c.cc.Invoke(ctx, Secrets_SyncV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) ListSecretRevisionsV1(ctx context.Context, in *ListSecretRevisionsRequestV1, opts ...grpc.CallOption) (*ListSecretRevisionsResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSecretRevisionsResponseV1)
+	err := c.cc.Invoke(ctx, Secrets_ListSecretRevisionsV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) GetSecretRevisionV1(ctx context.Context, in *GetSecretRevisionRequestV1, opts ...grpc.CallOption) (*GetSecretRevisionResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetSecretRevisionResponseV1)
+	err := c.cc.Invoke(ctx, Secrets_GetSecretRevisionV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) ListTrashV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListTrashResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrashResponseV1)
+	err := c.cc.Invoke(ctx, Secrets_ListTrashV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) RestoreSecretV1(ctx context.Context, in *RestoreSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Secrets_RestoreSecretV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *secretsClient) PurgeSecretV1(ctx context.Context, in *PurgeSecretRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Secrets_PurgeSecretV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SecretsServer is the server API for Secrets service.
 // All implementations must embed UnimplementedSecretsServer
 // for forward compatibility.
 type SecretsServer interface {
 	GetUserSecretsV1(context.Context, *emptypb.Empty) (*GetUserSecretsResponseV1, error)
 	GetUserSecretV1(context.Context, *GetUserSecretRequestV1) (*GetUserSecretResponseV1, error)
-	SaveUserSecretV1(context.Context, *SaveUserSecretRequestV1) (*emptypb.Empty, error)
+	SaveUserSecretV1(context.Context, *SaveUserSecretRequestV1) (*SaveUserSecretResponseV1, error)
 	DeleteUserSecretV1(context.Context, *DeleteUserSecretRequestV1) (*emptypb.Empty, error)
+	SyncV1(context.Context, *SyncRequestV1) (*SyncResponseV1, error)
+	ListSecretRevisionsV1(context.Context, *ListSecretRevisionsRequestV1) (*ListSecretRevisionsResponseV1, error)
+	GetSecretRevisionV1(context.Context, *GetSecretRevisionRequestV1) (*GetSecretRevisionResponseV1, error)
+	ListTrashV1(context.Context, *emptypb.Empty) (*ListTrashResponseV1, error)
+	RestoreSecretV1(context.Context, *RestoreSecretRequestV1) (*emptypb.Empty, error)
+	PurgeSecretV1(context.Context, *PurgeSecretRequestV1) (*emptypb.Empty, error)
 	mustEmbedUnimplementedSecretsServer()
 }
 
@@ -108,12 +186,30 @@ func (UnimplementedSecretsServer) GetUserSecretsV1(context.Context, *emptypb.Emp
 func (UnimplementedSecretsServer) GetUserSecretV1(context.Context, *GetUserSecretRequestV1) (*GetUserSecretResponseV1, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserSecretV1 not implemented")
 }
-func (UnimplementedSecretsServer) SaveUserSecretV1(context.Context, *SaveUserSecretRequestV1) (*emptypb.Empty, error) {
+func (UnimplementedSecretsServer) SaveUserSecretV1(context.Context, *SaveUserSecretRequestV1) (*SaveUserSecretResponseV1, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SaveUserSecretV1 not implemented")
 }
 func (UnimplementedSecretsServer) DeleteUserSecretV1(context.Context, *DeleteUserSecretRequestV1) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserSecretV1 not implemented")
 }
+func (UnimplementedSecretsServer) SyncV1(context.Context, *SyncRequestV1) (*SyncResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SyncV1 not implemented")
+}
+func (UnimplementedSecretsServer) ListSecretRevisionsV1(context.Context, *ListSecretRevisionsRequestV1) (*ListSecretRevisionsResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSecretRevisionsV1 not implemented")
+}
+func (UnimplementedSecretsServer) GetSecretRevisionV1(context.Context, *GetSecretRevisionRequestV1) (*GetSecretRevisionResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSecretRevisionV1 not implemented")
+}
+func (UnimplementedSecretsServer) ListTrashV1(context.Context, *emptypb.Empty) (*ListTrashResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTrashV1 not implemented")
+}
+func (UnimplementedSecretsServer) RestoreSecretV1(context.Context, *RestoreSecretRequestV1) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreSecretV1 not implemented")
+}
+func (UnimplementedSecretsServer) PurgeSecretV1(context.Context, *PurgeSecretRequestV1) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PurgeSecretV1 not implemented")
+}
 func (UnimplementedSecretsServer) mustEmbedUnimplementedSecretsServer() {}
 func (UnimplementedSecretsServer) testEmbeddedByValue()                 {}
 
@@ -207,6 +303,114 @@ func _Secrets_DeleteUserSecretV1_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Secrets_SyncV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SyncRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).SyncV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_SyncV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).SyncV1(ctx, req.(*SyncRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_ListSecretRevisionsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSecretRevisionsRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListSecretRevisionsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListSecretRevisionsV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListSecretRevisionsV1(ctx, req.(*ListSecretRevisionsRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_GetSecretRevisionV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSecretRevisionRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).GetSecretRevisionV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_GetSecretRevisionV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).GetSecretRevisionV1(ctx, req.(*GetSecretRevisionRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_ListTrashV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).ListTrashV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_ListTrashV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).ListTrashV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_RestoreSecretV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreSecretRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).RestoreSecretV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_RestoreSecretV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).RestoreSecretV1(ctx, req.(*RestoreSecretRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Secrets_PurgeSecretV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PurgeSecretRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SecretsServer).PurgeSecretV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Secrets_PurgeSecretV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SecretsServer).PurgeSecretV1(ctx, req.(*PurgeSecretRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Secrets_ServiceDesc is the grpc.ServiceDesc for Secrets service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -230,6 +434,30 @@ var Secrets_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUserSecretV1",
 			Handler:    _Secrets_DeleteUserSecretV1_Handler,
 		},
+		{
+			MethodName: "SyncV1",
+			Handler:    _Secrets_SyncV1_Handler,
+		},
+		{
+			MethodName: "ListSecretRevisionsV1",
+			Handler:    _Secrets_ListSecretRevisionsV1_Handler,
+		},
+		{
+			MethodName: "GetSecretRevisionV1",
+			Handler:    _Secrets_GetSecretRevisionV1_Handler,
+		},
+		{
+			MethodName: "ListTrashV1",
+			Handler:    _Secrets_ListTrashV1_Handler,
+		},
+		{
+			MethodName: "RestoreSecretV1",
+			Handler:    _Secrets_RestoreSecretV1_Handler,
+		},
+		{
+			MethodName: "PurgeSecretV1",
+			Handler:    _Secrets_PurgeSecretV1_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "secrets.proto",
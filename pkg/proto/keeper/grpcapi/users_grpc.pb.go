@@ -11,6 +11,7 @@ import (
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
 )
 
 // This is a compile-time assertion to ensure that this generated file
@@ -19,16 +20,44 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Users_LoginV1_FullMethodName    = "/proto.keeper.grpcapi.Users/LoginV1"
-	Users_RegisterV1_FullMethodName = "/proto.keeper.grpcapi.Users/RegisterV1"
+	Users_GetKDFV1_FullMethodName         = "/proto.keeper.grpcapi.Users/GetKDFV1"
+	Users_LoginV1_FullMethodName          = "/proto.keeper.grpcapi.Users/LoginV1"
+	Users_RegisterV1_FullMethodName       = "/proto.keeper.grpcapi.Users/RegisterV1"
+	Users_UpgradeAuthV1_FullMethodName    = "/proto.keeper.grpcapi.Users/UpgradeAuthV1"
+	Users_LoginSRPStartV1_FullMethodName  = "/proto.keeper.grpcapi.Users/LoginSRPStartV1"
+	Users_LoginSRPFinishV1_FullMethodName = "/proto.keeper.grpcapi.Users/LoginSRPFinishV1"
+	Users_EnrollSRPV1_FullMethodName      = "/proto.keeper.grpcapi.Users/EnrollSRPV1"
+	Users_RefreshV1_FullMethodName        = "/proto.keeper.grpcapi.Users/RefreshV1"
+	Users_ListSessionsV1_FullMethodName   = "/proto.keeper.grpcapi.Users/ListSessionsV1"
+	Users_RevokeSessionV1_FullMethodName  = "/proto.keeper.grpcapi.Users/RevokeSessionV1"
+	Users_LogoutV1_FullMethodName         = "/proto.keeper.grpcapi.Users/LogoutV1"
+	Users_LoginTOTPV1_FullMethodName      = "/proto.keeper.grpcapi.Users/LoginTOTPV1"
+	Users_GetTwoFactorV1_FullMethodName   = "/proto.keeper.grpcapi.Users/GetTwoFactorV1"
+	Users_EnrollTOTPV1_FullMethodName     = "/proto.keeper.grpcapi.Users/EnrollTOTPV1"
+	Users_ConfirmTOTPV1_FullMethodName    = "/proto.keeper.grpcapi.Users/ConfirmTOTPV1"
+	Users_DisableTOTPV1_FullMethodName    = "/proto.keeper.grpcapi.Users/DisableTOTPV1"
 )
 
 // UsersClient is the client API for Users service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type UsersClient interface {
+	GetKDFV1(ctx context.Context, in *GetKDFRequestV1, opts ...grpc.CallOption) (*GetKDFResponseV1, error)
 	LoginV1(ctx context.Context, in *LoginRequestV1, opts ...grpc.CallOption) (*LoginResponseV1, error)
 	RegisterV1(ctx context.Context, in *RegisterRequestV1, opts ...grpc.CallOption) (*RegisterResponseV1, error)
+	UpgradeAuthV1(ctx context.Context, in *UpgradeAuthRequestV1, opts ...grpc.CallOption) (*UpgradeAuthResponseV1, error)
+	LoginSRPStartV1(ctx context.Context, in *LoginSRPStartRequestV1, opts ...grpc.CallOption) (*LoginSRPStartResponseV1, error)
+	LoginSRPFinishV1(ctx context.Context, in *LoginSRPFinishRequestV1, opts ...grpc.CallOption) (*LoginSRPFinishResponseV1, error)
+	EnrollSRPV1(ctx context.Context, in *EnrollSRPRequestV1, opts ...grpc.CallOption) (*EnrollSRPResponseV1, error)
+	RefreshV1(ctx context.Context, in *RefreshRequestV1, opts ...grpc.CallOption) (*RefreshResponseV1, error)
+	ListSessionsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponseV1, error)
+	RevokeSessionV1(ctx context.Context, in *RevokeSessionRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	LogoutV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	LoginTOTPV1(ctx context.Context, in *LoginTOTPRequestV1, opts ...grpc.CallOption) (*LoginResponseV1, error)
+	GetTwoFactorV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TwoFactorStatusV1, error)
+	EnrollTOTPV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTOTPResponseV1, error)
+	ConfirmTOTPV1(ctx context.Context, in *ConfirmTOTPRequestV1, opts ...grpc.CallOption) (*ConfirmTOTPResponseV1, error)
+	DisableTOTPV1(ctx context.Context, in *DisableTOTPRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type usersClient struct {
@@ -39,6 +68,16 @@ func NewUsersClient(cc grpc.ClientConnInterface) UsersClient {
 	return &usersClient{cc}
 }
 
+func (c *usersClient) GetKDFV1(ctx context.Context, in *GetKDFRequestV1, opts ...grpc.CallOption) (*GetKDFResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetKDFResponseV1)
+	err := c.cc.Invoke(ctx, Users_GetKDFV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *usersClient) LoginV1(ctx context.Context, in *LoginRequestV1, opts ...grpc.CallOption) (*LoginResponseV1, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(LoginResponseV1)
@@ -59,12 +98,156 @@ func (c *usersClient) RegisterV1(ctx context.Context, in *RegisterRequestV1, opt
 	return out, nil
 }
 
+func (c *usersClient) UpgradeAuthV1(ctx context.Context, in *UpgradeAuthRequestV1, opts ...grpc.CallOption) (*UpgradeAuthResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpgradeAuthResponseV1)
+	err := c.cc.Invoke(ctx, Users_UpgradeAuthV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) LoginSRPStartV1(ctx context.Context, in *LoginSRPStartRequestV1, opts ...grpc.CallOption) (*LoginSRPStartResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginSRPStartResponseV1)
+	err := c.cc.Invoke(ctx, Users_LoginSRPStartV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) LoginSRPFinishV1(ctx context.Context, in *LoginSRPFinishRequestV1, opts ...grpc.CallOption) (*LoginSRPFinishResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginSRPFinishResponseV1)
+	err := c.cc.Invoke(ctx, Users_LoginSRPFinishV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) EnrollSRPV1(ctx context.Context, in *EnrollSRPRequestV1, opts ...grpc.CallOption) (*EnrollSRPResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollSRPResponseV1)
+	err := c.cc.Invoke(ctx, Users_EnrollSRPV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) RefreshV1(ctx context.Context, in *RefreshRequestV1, opts ...grpc.CallOption) (*RefreshResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshResponseV1)
+	err := c.cc.Invoke(ctx, Users_RefreshV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) ListSessionsV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSessionsResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponseV1)
+	err := c.cc.Invoke(ctx, Users_ListSessionsV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) RevokeSessionV1(ctx context.Context, in *RevokeSessionRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_RevokeSessionV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) LogoutV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_LogoutV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) LoginTOTPV1(ctx context.Context, in *LoginTOTPRequestV1, opts ...grpc.CallOption) (*LoginResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LoginResponseV1)
+	err := c.cc.Invoke(ctx, Users_LoginTOTPV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) GetTwoFactorV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*TwoFactorStatusV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TwoFactorStatusV1)
+	err := c.cc.Invoke(ctx, Users_GetTwoFactorV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) EnrollTOTPV1(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*EnrollTOTPResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EnrollTOTPResponseV1)
+	err := c.cc.Invoke(ctx, Users_EnrollTOTPV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) ConfirmTOTPV1(ctx context.Context, in *ConfirmTOTPRequestV1, opts ...grpc.CallOption) (*ConfirmTOTPResponseV1, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfirmTOTPResponseV1)
+	err := c.cc.Invoke(ctx, Users_ConfirmTOTPV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) DisableTOTPV1(ctx context.Context, in *DisableTOTPRequestV1, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_DisableTOTPV1_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UsersServer is the server API for Users service.
 // All implementations must embed UnimplementedUsersServer
 // for forward compatibility.
 type UsersServer interface {
+	GetKDFV1(context.Context, *GetKDFRequestV1) (*GetKDFResponseV1, error)
 	LoginV1(context.Context, *LoginRequestV1) (*LoginResponseV1, error)
 	RegisterV1(context.Context, *RegisterRequestV1) (*RegisterResponseV1, error)
+	UpgradeAuthV1(context.Context, *UpgradeAuthRequestV1) (*UpgradeAuthResponseV1, error)
+	LoginSRPStartV1(context.Context, *LoginSRPStartRequestV1) (*LoginSRPStartResponseV1, error)
+	LoginSRPFinishV1(context.Context, *LoginSRPFinishRequestV1) (*LoginSRPFinishResponseV1, error)
+	EnrollSRPV1(context.Context, *EnrollSRPRequestV1) (*EnrollSRPResponseV1, error)
+	RefreshV1(context.Context, *RefreshRequestV1) (*RefreshResponseV1, error)
+	ListSessionsV1(context.Context, *emptypb.Empty) (*ListSessionsResponseV1, error)
+	RevokeSessionV1(context.Context, *RevokeSessionRequestV1) (*emptypb.Empty, error)
+	LogoutV1(context.Context, *emptypb.Empty) (*emptypb.Empty, error)
+	LoginTOTPV1(context.Context, *LoginTOTPRequestV1) (*LoginResponseV1, error)
+	GetTwoFactorV1(context.Context, *emptypb.Empty) (*TwoFactorStatusV1, error)
+	EnrollTOTPV1(context.Context, *emptypb.Empty) (*EnrollTOTPResponseV1, error)
+	ConfirmTOTPV1(context.Context, *ConfirmTOTPRequestV1) (*ConfirmTOTPResponseV1, error)
+	DisableTOTPV1(context.Context, *DisableTOTPRequestV1) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUsersServer()
 }
 
@@ -75,12 +258,54 @@ type UsersServer interface {
 // pointer dereference when methods are called.
 type UnimplementedUsersServer struct{}
 
+func (UnimplementedUsersServer) GetKDFV1(context.Context, *GetKDFRequestV1) (*GetKDFResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetKDFV1 not implemented")
+}
 func (UnimplementedUsersServer) LoginV1(context.Context, *LoginRequestV1) (*LoginResponseV1, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method LoginV1 not implemented")
 }
 func (UnimplementedUsersServer) RegisterV1(context.Context, *RegisterRequestV1) (*RegisterResponseV1, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterV1 not implemented")
 }
+func (UnimplementedUsersServer) UpgradeAuthV1(context.Context, *UpgradeAuthRequestV1) (*UpgradeAuthResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpgradeAuthV1 not implemented")
+}
+func (UnimplementedUsersServer) LoginSRPStartV1(context.Context, *LoginSRPStartRequestV1) (*LoginSRPStartResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginSRPStartV1 not implemented")
+}
+func (UnimplementedUsersServer) LoginSRPFinishV1(context.Context, *LoginSRPFinishRequestV1) (*LoginSRPFinishResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginSRPFinishV1 not implemented")
+}
+func (UnimplementedUsersServer) EnrollSRPV1(context.Context, *EnrollSRPRequestV1) (*EnrollSRPResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollSRPV1 not implemented")
+}
+func (UnimplementedUsersServer) RefreshV1(context.Context, *RefreshRequestV1) (*RefreshResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshV1 not implemented")
+}
+func (UnimplementedUsersServer) ListSessionsV1(context.Context, *emptypb.Empty) (*ListSessionsResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSessionsV1 not implemented")
+}
+func (UnimplementedUsersServer) RevokeSessionV1(context.Context, *RevokeSessionRequestV1) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeSessionV1 not implemented")
+}
+func (UnimplementedUsersServer) LogoutV1(context.Context, *emptypb.Empty) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LogoutV1 not implemented")
+}
+func (UnimplementedUsersServer) LoginTOTPV1(context.Context, *LoginTOTPRequestV1) (*LoginResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginTOTPV1 not implemented")
+}
+func (UnimplementedUsersServer) GetTwoFactorV1(context.Context, *emptypb.Empty) (*TwoFactorStatusV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTwoFactorV1 not implemented")
+}
+func (UnimplementedUsersServer) EnrollTOTPV1(context.Context, *emptypb.Empty) (*EnrollTOTPResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTPV1 not implemented")
+}
+func (UnimplementedUsersServer) ConfirmTOTPV1(context.Context, *ConfirmTOTPRequestV1) (*ConfirmTOTPResponseV1, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTPV1 not implemented")
+}
+func (UnimplementedUsersServer) DisableTOTPV1(context.Context, *DisableTOTPRequestV1) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTOTPV1 not implemented")
+}
 func (UnimplementedUsersServer) mustEmbedUnimplementedUsersServer() {}
 func (UnimplementedUsersServer) testEmbeddedByValue()               {}
 
@@ -102,6 +327,24 @@ func RegisterUsersServer(s grpc.ServiceRegistrar, srv UsersServer) {
 	s.RegisterService(&Users_ServiceDesc, srv)
 }
 
+func _Users_GetKDFV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetKDFRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetKDFV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_GetKDFV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).GetKDFV1(ctx, req.(*GetKDFRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Users_LoginV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(LoginRequestV1)
 	if err := dec(in); err != nil {
@@ -138,6 +381,240 @@ func _Users_RegisterV1_Handler(srv interface{}, ctx context.Context, dec func(in
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Users_UpgradeAuthV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpgradeAuthRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).UpgradeAuthV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_UpgradeAuthV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).UpgradeAuthV1(ctx, req.(*UpgradeAuthRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_LoginSRPStartV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginSRPStartRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).LoginSRPStartV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_LoginSRPStartV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).LoginSRPStartV1(ctx, req.(*LoginSRPStartRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_LoginSRPFinishV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginSRPFinishRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).LoginSRPFinishV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_LoginSRPFinishV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).LoginSRPFinishV1(ctx, req.(*LoginSRPFinishRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_EnrollSRPV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollSRPRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).EnrollSRPV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_EnrollSRPV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).EnrollSRPV1(ctx, req.(*EnrollSRPRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_RefreshV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).RefreshV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_RefreshV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).RefreshV1(ctx, req.(*RefreshRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_ListSessionsV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).ListSessionsV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_ListSessionsV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).ListSessionsV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_RevokeSessionV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeSessionRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).RevokeSessionV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_RevokeSessionV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).RevokeSessionV1(ctx, req.(*RevokeSessionRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_LogoutV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).LogoutV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_LogoutV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).LogoutV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_LoginTOTPV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginTOTPRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).LoginTOTPV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_LoginTOTPV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).LoginTOTPV1(ctx, req.(*LoginTOTPRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_GetTwoFactorV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetTwoFactorV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_GetTwoFactorV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).GetTwoFactorV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_EnrollTOTPV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).EnrollTOTPV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_EnrollTOTPV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).EnrollTOTPV1(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_ConfirmTOTPV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTOTPRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).ConfirmTOTPV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_ConfirmTOTPV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).ConfirmTOTPV1(ctx, req.(*ConfirmTOTPRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_DisableTOTPV1_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTOTPRequestV1)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).DisableTOTPV1(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_DisableTOTPV1_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).DisableTOTPV1(ctx, req.(*DisableTOTPRequestV1))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Users_ServiceDesc is the grpc.ServiceDesc for Users service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -145,6 +622,10 @@ var Users_ServiceDesc = grpc.ServiceDesc{
 	ServiceName: "proto.keeper.grpcapi.Users",
 	HandlerType: (*UsersServer)(nil),
 	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetKDFV1",
+			Handler:    _Users_GetKDFV1_Handler,
+		},
 		{
 			MethodName: "LoginV1",
 			Handler:    _Users_LoginV1_Handler,
@@ -153,6 +634,58 @@ var Users_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RegisterV1",
 			Handler:    _Users_RegisterV1_Handler,
 		},
+		{
+			MethodName: "UpgradeAuthV1",
+			Handler:    _Users_UpgradeAuthV1_Handler,
+		},
+		{
+			MethodName: "LoginSRPStartV1",
+			Handler:    _Users_LoginSRPStartV1_Handler,
+		},
+		{
+			MethodName: "LoginSRPFinishV1",
+			Handler:    _Users_LoginSRPFinishV1_Handler,
+		},
+		{
+			MethodName: "EnrollSRPV1",
+			Handler:    _Users_EnrollSRPV1_Handler,
+		},
+		{
+			MethodName: "RefreshV1",
+			Handler:    _Users_RefreshV1_Handler,
+		},
+		{
+			MethodName: "ListSessionsV1",
+			Handler:    _Users_ListSessionsV1_Handler,
+		},
+		{
+			MethodName: "RevokeSessionV1",
+			Handler:    _Users_RevokeSessionV1_Handler,
+		},
+		{
+			MethodName: "LogoutV1",
+			Handler:    _Users_LogoutV1_Handler,
+		},
+		{
+			MethodName: "LoginTOTPV1",
+			Handler:    _Users_LoginTOTPV1_Handler,
+		},
+		{
+			MethodName: "GetTwoFactorV1",
+			Handler:    _Users_GetTwoFactorV1_Handler,
+		},
+		{
+			MethodName: "EnrollTOTPV1",
+			Handler:    _Users_EnrollTOTPV1_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTPV1",
+			Handler:    _Users_ConfirmTOTPV1_Handler,
+		},
+		{
+			MethodName: "DisableTOTPV1",
+			Handler:    _Users_DisableTOTPV1_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "users.proto",
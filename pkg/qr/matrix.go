@@ -0,0 +1,265 @@
+package qr
+
+// Modules being laid out, function ones are never masked
+type matrix struct {
+	size     int
+	dark     [][]bool
+	function [][]bool
+}
+
+func (m *matrix) set(x, y int, dark bool) {
+	m.dark[y][x] = dark
+	m.function[y][x] = true
+}
+
+// Finder, timing and alignment patterns, version info and room for format info
+func (m *matrix) drawFunctionPatterns(ver int) {
+	for i := range m.size {
+		m.set(6, i, i%2 == 0)
+		m.set(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	align := versions[ver].align
+	last := len(align) - 1
+	for i, x := range align {
+		for j, y := range align {
+			// Corners taken by finders
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	m.drawFormat(0)
+	m.drawVersion(ver)
+}
+
+func (m *matrix) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= m.size || y >= m.size {
+				continue
+			}
+
+			dist := max(abs(dx), abs(dy))
+			m.set(x, y, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.set(cx+dx, cy+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// Two copies of level M and mask, BCH protected
+func (m *matrix) drawFormat(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+	for i := range 6 {
+		m.set(8, i, bit(i))
+	}
+	m.set(8, 7, bit(6))
+	m.set(8, 8, bit(7))
+	m.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.set(14-i, 8, bit(i))
+	}
+
+	for i := range 8 {
+		m.set(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.set(8, m.size-15+i, bit(i))
+	}
+	m.set(8, m.size-8, true) // always dark
+}
+
+// Level M is 00, so format data is mask alone
+func formatBits(mask int) int {
+	data := mask
+	rem := data
+	for range 10 {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// Version blocks of versions 7 and up
+func (m *matrix) drawVersion(ver int) {
+	if ver < 7 {
+		return
+	}
+
+	bits := versionBits(ver)
+	for i := range 18 {
+		dark := (bits>>i)&1 == 1
+		a, b := m.size-11+i%3, i/3
+		m.set(a, b, dark)
+		m.set(b, a, dark)
+	}
+}
+
+func versionBits(ver int) int {
+	rem := ver
+	for range 12 {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+
+	return ver<<12 | rem
+}
+
+// Codewords in two-column zigzag from bottom right corner, skipping function modules.
+// Leftover modules stay light.
+func (m *matrix) drawCodewords(data []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // vertical timing pattern
+		}
+
+		upward := (right+1)&2 == 0
+		for vert := range m.size {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+
+			for j := range 2 {
+				x := right - j
+				if m.function[y][x] || i >= len(data)*8 {
+					continue
+				}
+
+				m.dark[y][x] = (data[i/8]>>(7-i%8))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// Flip data modules picked by mask pattern
+func (m *matrix) applyMask(mask int) {
+	for y := range m.size {
+		for x := range m.size {
+			if m.function[y][x] {
+				continue
+			}
+
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+
+			m.dark[y][x] = m.dark[y][x] != flip
+		}
+	}
+}
+
+// Penalty of the standard: long runs, 2x2 blocks, finder-like patterns and dark/light imbalance
+func (m *matrix) penalty() int {
+	result := 0
+	at := func(x, y int, vertical bool) bool {
+		if vertical {
+			return m.dark[x][y]
+		}
+		return m.dark[y][x]
+	}
+
+	for _, vertical := range []bool{false, true} {
+		for y := range m.size {
+			run := 1
+			for x := 1; x < m.size; x++ {
+				if at(x, y, vertical) == at(x-1, y, vertical) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += run - 2
+				}
+				run = 1
+			}
+			if run >= 5 {
+				result += run - 2
+			}
+
+			for x := 0; x+7 <= m.size; x++ {
+				if !finderLike(func(i int) bool { return at(x+i, y, vertical) }) {
+					continue
+				}
+
+				lightBefore := x >= 4 && !at(x-1, y, vertical) && !at(x-2, y, vertical) && !at(x-3, y, vertical) && !at(x-4, y, vertical)
+				lightAfter := x+11 <= m.size && !at(x+7, y, vertical) && !at(x+8, y, vertical) && !at(x+9, y, vertical) && !at(x+10, y, vertical)
+				if lightBefore || lightAfter {
+					result += 40
+				}
+			}
+		}
+	}
+
+	dark := 0
+	for y := range m.size {
+		for x := range m.size {
+			if m.dark[y][x] {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size {
+				c := m.dark[y][x]
+				if c == m.dark[y][x+1] && c == m.dark[y+1][x] && c == m.dark[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+
+	total := m.size * m.size
+	result += abs(dark*20-total*10) / total * 10
+
+	return result
+}
+
+// Dark-light-dark-dark-dark-light-dark, 1:1:3:1:1 like finder center row
+func finderLike(at func(i int) bool) bool {
+	pattern := [7]bool{true, false, true, true, true, false, true}
+	for i, dark := range pattern {
+		if at(i) != dark {
+			return false
+		}
+	}
+
+	return true
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+
+	return x
+}
@@ -0,0 +1,224 @@
+// QR codes (ISO/IEC 18004) of short texts such as otpauth:// URIs, drawn with terminal block characters.
+// Only byte mode and error correction level M, versions 1-10, are supported.
+package qr
+
+import (
+	"errors"
+	"strings"
+)
+
+// Longest text that fits version 10 at level M
+const MaxLen = 213
+
+var ErrTooLong = errors.New("text is too long for QR code")
+
+// Modules of quiet zone around code, scanners need some light space to find it
+const quietZone = 2
+
+// Layout of version at level M
+type version struct {
+	ecLen  int   // error correction codewords per block
+	blocks []int // data codewords of each block
+	align  []int // alignment pattern centers
+}
+
+var versions = []version{
+	1:  {ecLen: 10, blocks: []int{16}},
+	2:  {ecLen: 16, blocks: []int{28}, align: []int{6, 18}},
+	3:  {ecLen: 26, blocks: []int{44}, align: []int{6, 22}},
+	4:  {ecLen: 18, blocks: []int{32, 32}, align: []int{6, 26}},
+	5:  {ecLen: 24, blocks: []int{43, 43}, align: []int{6, 30}},
+	6:  {ecLen: 16, blocks: []int{27, 27, 27, 27}, align: []int{6, 34}},
+	7:  {ecLen: 18, blocks: []int{31, 31, 31, 31}, align: []int{6, 22, 38}},
+	8:  {ecLen: 22, blocks: []int{38, 38, 39, 39}, align: []int{6, 24, 42}},
+	9:  {ecLen: 22, blocks: []int{36, 36, 36, 37, 37}, align: []int{6, 26, 46}},
+	10: {ecLen: 26, blocks: []int{43, 43, 43, 43, 44}, align: []int{6, 28, 50}},
+}
+
+func (v version) dataLen() int {
+	n := 0
+	for _, b := range v.blocks {
+		n += b
+	}
+
+	return n
+}
+
+// Encoded QR code
+type Code struct {
+	Version int
+	size    int
+	modules [][]bool // [y][x], true is dark
+}
+
+// Encode text in smallest version it fits
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+
+	for ver := 1; ver < len(versions); ver++ {
+		if len(data) <= capacity(ver) {
+			return encode(ver, data), nil
+		}
+	}
+
+	return nil, ErrTooLong
+}
+
+// Bytes that fit version: data codewords minus 4-bit mode and length field
+func capacity(ver int) int {
+	return versions[ver].dataLen() - (4+countBits(ver)+7)/8
+}
+
+func countBits(ver int) int {
+	if ver < 10 {
+		return 8
+	}
+
+	return 16
+}
+
+// Size of code in modules, quiet zone excluded
+func (c *Code) Size() int {
+	return c.size
+}
+
+// Module at column x and row y is dark
+func (c *Code) Dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= c.size || y >= c.size {
+		return false
+	}
+
+	return c.modules[y][x]
+}
+
+// Code drawn with half blocks, two rows of modules per line, quiet zone included. Light modules are drawn
+// and dark ones left blank, so it is meant to be rendered light on dark background.
+func (c *Code) String() string {
+	var b strings.Builder
+
+	for y := -quietZone; y < c.size+quietZone; y += 2 {
+		for x := -quietZone; x < c.size+quietZone; x++ {
+			top, bottom := !c.Dark(x, y), !c.Dark(x, y+1)
+			if y+1 >= c.size+quietZone {
+				bottom = false
+			}
+
+			switch {
+			case top && bottom:
+				b.WriteString("█")
+			case top:
+				b.WriteString("▀")
+			case bottom:
+				b.WriteString("▄")
+			default:
+				b.WriteString(" ")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func encode(ver int, data []byte) *Code {
+	size := 17 + 4*ver
+	m := &matrix{size: size, dark: grid(size), function: grid(size)}
+
+	m.drawFunctionPatterns(ver)
+	m.drawCodewords(interleave(ver, dataCodewords(ver, data)))
+
+	// Mask giving the lowest penalty wins
+	best, bestPenalty := 0, -1
+	for mask := range 8 {
+		m.applyMask(mask)
+		m.drawFormat(mask)
+		if p := m.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		m.applyMask(mask) // masking twice undoes it
+	}
+
+	m.applyMask(best)
+	m.drawFormat(best)
+
+	return &Code{Version: ver, size: size, modules: m.dark}
+}
+
+// Mode, length, text, terminator and padding, split in bytes
+func dataCodewords(ver int, data []byte) []byte {
+	var bits bitBuffer
+
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(data), countBits(ver))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+
+	total := versions[ver].dataLen() * 8
+	bits.append(0, min(4, total-len(bits)))
+	bits.append(0, (8-len(bits)%8)%8)
+
+	codewords := bits.bytes()
+	for pad := byte(0xEC); len(codewords) < total/8; pad ^= 0xEC ^ 0x11 {
+		codewords = append(codewords, pad)
+	}
+
+	return codewords
+}
+
+// Data split in blocks with error correction of each, interleaved as the standard lays them out
+func interleave(ver int, data []byte) []byte {
+	v := versions[ver]
+	divisor := rsDivisor(v.ecLen)
+
+	var blocks, ecs [][]byte
+	for _, n := range v.blocks {
+		blocks = append(blocks, data[:n])
+		ecs = append(ecs, rsRemainder(data[:n], divisor))
+		data = data[n:]
+	}
+
+	var out []byte
+	for i := range v.blocks[len(v.blocks)-1] {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := range v.ecLen {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+
+	return out
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>i)&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	return out
+}
+
+func grid(size int) [][]bool {
+	g := make([][]bool, size)
+	for i := range g {
+		g[i] = make([]bool, size)
+	}
+
+	return g
+}
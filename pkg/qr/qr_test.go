@@ -0,0 +1,169 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRSRemainder(t *testing.T) {
+	// HELLO WORLD at 1-M, from the standard's worked example
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	assert.Equal(t, want, rsRemainder(data, rsDivisor(10)))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	assert.Equal(t, 0b101010000010010, formatBits(0))
+	assert.Equal(t, 0b100000011001110, formatBits(5))
+	assert.Equal(t, 0b000111110010010100, versionBits(7))
+	assert.Equal(t, 0b001010010011010011, versionBits(10))
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		version int
+	}{
+		{name: "Empty", text: "", version: 1},
+		{name: "Short", text: "hello", version: 1},
+		{name: "Fills version 1", text: strings.Repeat("a", 14), version: 1},
+		{name: "Version 2", text: strings.Repeat("a", 15), version: 2},
+		{name: "otpauth URI", text: "otpauth://totp/GophKeeper:alice?algorithm=SHA1&digits=6&issuer=GophKeeper&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP", version: 8},
+		{name: "Longest", text: strings.Repeat("z", MaxLen), version: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Encode(tt.text)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.version, code.Version)
+			assert.Equal(t, 17+4*tt.version, code.Size())
+			assert.Equal(t, tt.text, decode(t, code))
+		})
+	}
+
+	t.Run("Too long", func(t *testing.T) {
+		_, err := Encode(strings.Repeat("z", MaxLen+1))
+		assert.ErrorIs(t, err, ErrTooLong)
+	})
+}
+
+func TestString(t *testing.T) {
+	code, err := Encode("hello")
+	require.NoError(t, err)
+
+	lines := strings.Split(code.String(), "\n")
+	assert.Len(t, lines, (code.Size()+2*quietZone+1)/2)
+	for _, line := range lines {
+		assert.Equal(t, code.Size()+2*quietZone, len([]rune(line)))
+	}
+
+	// Quiet zone on top is light, finder pattern starts dark
+	assert.Equal(t, strings.Repeat("█", code.Size()+2*quietZone), lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "██ "))
+}
+
+// Read text back: mask from format info, data codewords from zigzag, blocks de-interleaved
+func decode(t *testing.T, code *Code) string {
+	t.Helper()
+
+	m := &matrix{size: code.size, dark: grid(code.size), function: grid(code.size)}
+	m.drawFunctionPatterns(code.Version)
+	for y := range code.size {
+		copy(m.dark[y], code.modules[y])
+	}
+
+	format := 0
+	for i := range 6 {
+		if code.Dark(8, i) {
+			format |= 1 << i
+		}
+	}
+	mask := -1
+	for candidate := range 8 {
+		if formatBits(candidate)&0x3F == format {
+			mask = candidate
+		}
+	}
+	require.GreaterOrEqual(t, mask, 0, "format info not found")
+	m.applyMask(mask)
+
+	v := versions[code.Version]
+	raw := make([]byte, v.dataLen()+v.ecLen*len(v.blocks))
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := range m.size {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := range 2 {
+				x := right - j
+				if m.function[y][x] || i >= len(raw)*8 {
+					continue
+				}
+				if m.dark[y][x] {
+					raw[i/8] |= 0x80 >> (i % 8)
+				}
+				i++
+			}
+		}
+	}
+
+	blocks := make([][]byte, len(v.blocks))
+	pos := 0
+	for k := range v.blocks[len(v.blocks)-1] {
+		for b, n := range v.blocks {
+			if k < n {
+				blocks[b] = append(blocks[b], raw[pos])
+				pos++
+			}
+		}
+	}
+	for b, block := range blocks {
+		ec := raw[pos:]
+		got := make([]byte, v.ecLen)
+		for k := range v.ecLen {
+			got[k] = ec[k*len(v.blocks)+b]
+		}
+		require.Equal(t, rsRemainder(block, rsDivisor(v.ecLen)), got, "error correction of block %d", b)
+	}
+
+	var bits bitBuffer
+	for _, block := range blocks {
+		for _, c := range block {
+			bits.append(int(c), 8)
+		}
+	}
+
+	read := func(n int) int {
+		value := 0
+		for _, bit := range bits[:n] {
+			value <<= 1
+			if bit {
+				value |= 1
+			}
+		}
+		bits = bits[n:]
+		return value
+	}
+
+	require.Equal(t, 0b0100, read(4), "byte mode")
+	n := read(countBits(code.Version))
+	text := make([]byte, n)
+	for k := range text {
+		text[k] = byte(read(8))
+	}
+
+	return string(text)
+}
@@ -0,0 +1,283 @@
+// SRP-6a password-authenticated key exchange (RFC 5054 2048-bit group, SHA-256): server keeps only
+// a verifier, client and server prove to each other they know password without sending it
+package srp
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+// Length of modulus in bytes, public keys and verifiers are at most this long
+const GroupSize = 256
+
+const (
+	SaltLen   = 16
+	secretLen = 32
+)
+
+var (
+	ErrBadPublicKey = errors.New("bad SRP public key")
+	ErrBadVerifier  = errors.New("bad SRP verifier")
+	ErrBadProof     = errors.New("SRP proof does not match")
+	ErrBadState     = errors.New("bad SRP server state")
+)
+
+// RFC 5054 appendix A, 2048-bit group
+var (
+	groupN, _ = new(big.Int).SetString(strings.Join(strings.Fields(`
+		AC6BDB41 324A9A9B F166DE5E 1389582F AF72B665 1987EE07 FC319294 3DB56050 A37329CB B4A099ED
+		8193E075 7767A13D D52312AB 4B03310D CD7F48A9 DA04FD50 E8083969 EDB767B0 CF609517 9A163AB3
+		661A05FB D5FAAAE8 2918A996 2F0B93B8 55F97993 EC975EEA A80D740A DBF4FF74 7359D041 D5C33EA7
+		1D281E44 6B14773B CA97B43A 23FB8016 76BD207A 436C6481 F1D2B907 8717461A 5B9D32E6 88F87748
+		544523B5 24B0D57D 5EA77A27 75D2ECFA 032CFBDB F52FB378 61602790 04E57AE6 AF874E73 03CE5329
+		9CCC041C 7BC308D8 2A5698F3 A8D0C382 71AE35F8 E9DBFBB6 94B5C803 D89F7AE4 35DE236D 525F5475
+		9B65E372 FCD68EF2 0FA7111F 9E4AFF73`), ""), 16)
+	groupG = big.NewInt(2)
+
+	// k = H(N | PAD(g))
+	multiplier = new(big.Int).SetBytes(hash(groupN.Bytes(), pad(groupG)))
+)
+
+// Random salt and verifier v = g^x of password, sent to server on registration
+func NewVerifier(login string, password []byte) (salt []byte, verifier []byte, err error) {
+	salt = make([]byte, SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+
+	return salt, Verifier(login, password, salt), nil
+}
+
+// Verifier of password with given salt
+func Verifier(login string, password []byte, salt []byte) []byte {
+	x := privateKey(login, password, salt)
+
+	return new(big.Int).Exp(groupG, x, groupN).Bytes()
+}
+
+// Verifier must be element of group other than 0 and 1
+func CheckVerifier(verifier []byte) error {
+	v := new(big.Int).SetBytes(verifier)
+	if len(verifier) > GroupSize || v.Cmp(big.NewInt(1)) <= 0 || v.Cmp(groupN) >= 0 {
+		return ErrBadVerifier
+	}
+
+	return nil
+}
+
+// Client side of one login
+type Client struct {
+	login    string
+	password []byte
+	a        *big.Int
+	A        []byte // public key sent to server
+
+	m2  []byte // expected server proof
+	key []byte
+}
+
+func NewClient(login string, password []byte) (*Client, error) {
+	a, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		login:    login,
+		password: password,
+		a:        a,
+		A:        new(big.Int).Exp(groupG, a, groupN).Bytes(),
+	}, nil
+}
+
+// Proof of password M1 for salt and public key of server
+func (c *Client) Proof(salt []byte, serverKey []byte) ([]byte, error) {
+	B := new(big.Int).SetBytes(serverKey)
+	if len(serverKey) > GroupSize || new(big.Int).Mod(B, groupN).Sign() == 0 {
+		return nil, ErrBadPublicKey
+	}
+
+	A := new(big.Int).SetBytes(c.A)
+	u := scrambler(A, B)
+	if u.Sign() == 0 {
+		return nil, ErrBadPublicKey
+	}
+
+	// S = (B - k * g^x) ^ (a + u * x) mod N
+	x := privateKey(c.login, c.password, salt)
+	base := new(big.Int).Sub(B, new(big.Int).Mul(multiplier, new(big.Int).Exp(groupG, x, groupN)))
+	base.Mod(base, groupN)
+	exp := new(big.Int).Add(c.a, new(big.Int).Mul(u, x))
+	S := new(big.Int).Exp(base, exp, groupN)
+
+	c.key = hash(pad(S))
+	m1 := clientProof(c.login, salt, A, B, c.key)
+	c.m2 = hash(pad(A), m1, c.key)
+
+	return m1, nil
+}
+
+// Check proof M2 of server, false means server does not know verifier
+func (c *Client) Verify(serverProof []byte) bool {
+	return c.m2 != nil && subtle.ConstantTimeCompare(c.m2, serverProof) == 1
+}
+
+// Session key shared with server, after Proof
+func (c *Client) Key() []byte {
+	return c.key
+}
+
+// Server side of one login
+type Server struct {
+	login    string
+	salt     []byte
+	verifier *big.Int
+	A        *big.Int
+	b        *big.Int
+	B        []byte // public key sent to client
+
+	key []byte
+}
+
+// Server side for client public key, which must not be 0 mod N
+func NewServer(login string, salt []byte, verifier []byte, clientKey []byte) (*Server, error) {
+	if err := CheckVerifier(verifier); err != nil {
+		return nil, err
+	}
+
+	A := new(big.Int).SetBytes(clientKey)
+	if len(clientKey) > GroupSize || new(big.Int).Mod(A, groupN).Sign() == 0 {
+		return nil, ErrBadPublicKey
+	}
+
+	b, err := randomSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	// B = k * v + g^b mod N
+	v := new(big.Int).SetBytes(verifier)
+	B := new(big.Int).Mul(multiplier, v)
+	B.Add(B, new(big.Int).Exp(groupG, b, groupN))
+	B.Mod(B, groupN)
+
+	return &Server{login: login, salt: salt, verifier: v, A: A, b: b, B: B.Bytes()}, nil
+}
+
+// Check client proof M1, returns server proof M2
+func (s *Server) Verify(proof []byte) ([]byte, error) {
+	B := new(big.Int).SetBytes(s.B)
+	u := scrambler(s.A, B)
+	if u.Sign() == 0 {
+		return nil, ErrBadPublicKey
+	}
+
+	// S = (A * v^u) ^ b mod N
+	base := new(big.Int).Mul(s.A, new(big.Int).Exp(s.verifier, u, groupN))
+	S := new(big.Int).Exp(base.Mod(base, groupN), s.b, groupN)
+
+	key := hash(pad(S))
+	m1 := clientProof(s.login, s.salt, s.A, B, key)
+	if subtle.ConstantTimeCompare(m1, proof) != 1 {
+		return nil, ErrBadProof
+	}
+
+	s.key = key
+
+	return hash(pad(s.A), m1, key), nil
+}
+
+// Session key shared with client, after Verify
+func (s *Server) Key() []byte {
+	return s.key
+}
+
+// State of server waiting for client proof, so the proof may come to another server instance.
+// It includes secret b and must not leave server.
+func (s *Server) MarshalBinary() ([]byte, error) {
+	var buf []byte
+	for _, field := range [][]byte{[]byte(s.login), s.salt, s.verifier.Bytes(), s.A.Bytes(), s.b.Bytes(), s.B} {
+		buf = binary.AppendUvarint(buf, uint64(len(field)))
+		buf = append(buf, field...)
+	}
+
+	return buf, nil
+}
+
+// Restore server saved by MarshalBinary
+func (s *Server) UnmarshalBinary(data []byte) error {
+	fields := make([][]byte, 6)
+	for i := range fields {
+		n, read := binary.Uvarint(data)
+		if read <= 0 || n > uint64(len(data)-read) {
+			return ErrBadState
+		}
+
+		end := read + int(n)
+		fields[i], data = data[read:end:end], data[end:]
+	}
+	if len(data) != 0 {
+		return ErrBadState
+	}
+
+	*s = Server{
+		login:    string(fields[0]),
+		salt:     fields[1],
+		verifier: new(big.Int).SetBytes(fields[2]),
+		A:        new(big.Int).SetBytes(fields[3]),
+		b:        new(big.Int).SetBytes(fields[4]),
+		B:        fields[5],
+	}
+
+	return nil
+}
+
+// x = H(s | H(I | ":" | P))
+func privateKey(login string, password []byte, salt []byte) *big.Int {
+	inner := hash([]byte(login), []byte(":"), password)
+
+	return new(big.Int).SetBytes(hash(salt, inner))
+}
+
+// u = H(PAD(A) | PAD(B))
+func scrambler(A, B *big.Int) *big.Int {
+	return new(big.Int).SetBytes(hash(pad(A), pad(B)))
+}
+
+// M1 = H(H(N) xor H(g) | H(I) | s | A | B | K)
+func clientProof(login string, salt []byte, A, B *big.Int, key []byte) []byte {
+	hN, hg := hash(groupN.Bytes()), hash(pad(groupG))
+	for i := range hN {
+		hN[i] ^= hg[i]
+	}
+
+	return hash(hN, hash([]byte(login)), salt, pad(A), pad(B), key)
+}
+
+func randomSecret() (*big.Int, error) {
+	buf := make([]byte, secretLen)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(buf), nil
+}
+
+// Number as big-endian bytes of modulus length
+func pad(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, GroupSize))
+}
+
+func hash(parts ...[]byte) []byte {
+	h := sha256.New()
+	for _, p := range parts {
+		h.Write(p)
+	}
+
+	return h.Sum(nil)
+}
@@ -0,0 +1,104 @@
+package srp
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExchange(t *testing.T) {
+	salt, verifier, err := NewVerifier("user", []byte("auth key"))
+	require.NoError(t, err)
+	require.NoError(t, CheckVerifier(verifier))
+
+	login := func(password string) (*Client, *Server, []byte, error) {
+		client, err := NewClient("user", []byte(password))
+		require.NoError(t, err)
+
+		server, err := NewServer("user", salt, verifier, client.A)
+		require.NoError(t, err)
+
+		m1, err := client.Proof(salt, server.B)
+		require.NoError(t, err)
+
+		m2, err := server.Verify(m1)
+		return client, server, m2, err
+	}
+
+	t.Run("Mutual authentication", func(t *testing.T) {
+		client, server, m2, err := login("auth key")
+		require.NoError(t, err)
+		assert.True(t, client.Verify(m2))
+		assert.Equal(t, client.Key(), server.Key())
+	})
+
+	t.Run("Wrong password", func(t *testing.T) {
+		_, _, _, err := login("other key")
+		assert.ErrorIs(t, err, ErrBadProof)
+	})
+
+	t.Run("Restored server", func(t *testing.T) {
+		client, err := NewClient("user", []byte("auth key"))
+		require.NoError(t, err)
+		server, err := NewServer("user", salt, verifier, client.A)
+		require.NoError(t, err)
+
+		state, err := server.MarshalBinary()
+		require.NoError(t, err)
+
+		var restored Server
+		require.NoError(t, restored.UnmarshalBinary(state))
+
+		m1, err := client.Proof(salt, restored.B)
+		require.NoError(t, err)
+		m2, err := restored.Verify(m1)
+		require.NoError(t, err)
+		assert.True(t, client.Verify(m2))
+
+		assert.ErrorIs(t, restored.UnmarshalBinary(state[:len(state)-1]), ErrBadState)
+	})
+
+	t.Run("Impostor server", func(t *testing.T) {
+		// Server without verifier of password makes one up
+		fakeSalt, fakeVerifier, err := NewVerifier("user", []byte("guess"))
+		require.NoError(t, err)
+
+		client, err := NewClient("user", []byte("auth key"))
+		require.NoError(t, err)
+		server, err := NewServer("user", fakeSalt, fakeVerifier, client.A)
+		require.NoError(t, err)
+
+		m1, err := client.Proof(fakeSalt, server.B)
+		require.NoError(t, err)
+
+		_, err = server.Verify(m1)
+		assert.ErrorIs(t, err, ErrBadProof)
+		assert.False(t, client.Verify(make([]byte, 32)))
+	})
+}
+
+func TestBadKeys(t *testing.T) {
+	salt, verifier, err := NewVerifier("user", []byte("auth key"))
+	require.NoError(t, err)
+
+	for name, key := range map[string][]byte{
+		"Zero":     {0},
+		"Modulus":  groupN.Bytes(),
+		"Too long": new(big.Int).Lsh(groupN, 8).Bytes(),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewServer("user", salt, verifier, key)
+			assert.ErrorIs(t, err, ErrBadPublicKey)
+
+			client, err := NewClient("user", []byte("auth key"))
+			require.NoError(t, err)
+			_, err = client.Proof(salt, key)
+			assert.ErrorIs(t, err, ErrBadPublicKey)
+		})
+	}
+
+	assert.ErrorIs(t, CheckVerifier([]byte{1}), ErrBadVerifier)
+	assert.ErrorIs(t, CheckVerifier(groupN.Bytes()), ErrBadVerifier)
+}
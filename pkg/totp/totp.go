@@ -0,0 +1,149 @@
+// Time-based one-time passwords (RFC 6238) and otpauth:// key URIs
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	SHA1   Algorithm = "SHA1"
+	SHA256 Algorithm = "SHA256"
+	SHA512 Algorithm = "SHA512"
+)
+
+const (
+	DefaultDigits = 6
+	DefaultPeriod = 30 // seconds
+)
+
+var (
+	ErrBadSecret    = errors.New("secret is not valid base32")
+	ErrBadAlgorithm = errors.New("algorithm must be SHA1, SHA256 or SHA512")
+	ErrBadDigits    = errors.New("digits must be 6 or 8")
+	ErrBadPeriod    = errors.New("period must be positive")
+)
+
+// Code parameters, zero values mean defaults
+type Params struct {
+	Algorithm Algorithm
+	Digits    int
+	Period    int // seconds
+}
+
+// Params with defaults filled in
+func (p Params) withDefaults() Params {
+	if p.Algorithm == "" {
+		p.Algorithm = SHA1
+	}
+	if p.Digits == 0 {
+		p.Digits = DefaultDigits
+	}
+	if p.Period == 0 {
+		p.Period = DefaultPeriod
+	}
+
+	return p
+}
+
+// Check params after defaults are applied
+func (p Params) Validate() error {
+	p = p.withDefaults()
+
+	if _, err := hashFunc(p.Algorithm); err != nil {
+		return err
+	}
+	if p.Digits != 6 && p.Digits != 8 {
+		return ErrBadDigits
+	}
+	if p.Period < 0 {
+		return ErrBadPeriod
+	}
+
+	return nil
+}
+
+// Code for shared secret at time
+func Generate(secret []byte, at time.Time, p Params) (string, error) {
+	p = p.withDefaults()
+	if err := p.Validate(); err != nil {
+		return "", err
+	}
+
+	h, err := hashFunc(p.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(h, secret, uint64(at.Unix())/uint64(p.Period), p.Digits), nil
+}
+
+// Time left until code generated at time changes
+func Remaining(at time.Time, p Params) time.Duration {
+	period := time.Duration(p.withDefaults().Period) * time.Second
+
+	return period - time.Duration(at.UnixNano())%period
+}
+
+// HOTP value (RFC 4226) for counter
+func hotp(h func() hash.Hash, secret []byte, counter uint64, digits int) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(h, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for range digits {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", digits, value%mod)
+}
+
+func hashFunc(a Algorithm) (func() hash.Hash, error) {
+	switch Algorithm(strings.ToUpper(string(a))) {
+	case SHA1:
+		return sha1.New, nil
+	case SHA256:
+		return sha256.New, nil
+	case SHA512:
+		return sha512.New, nil
+	default:
+		return nil, ErrBadAlgorithm
+	}
+}
+
+// Decode base32 secret as shown by services, ignoring case, spaces and padding
+func DecodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.Join(strings.Fields(secret), ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrBadSecret
+	}
+
+	return key, nil
+}
+
+// Encode secret as unpadded base32
+func EncodeSecret(key []byte) string {
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key)
+}
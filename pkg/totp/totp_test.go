@@ -0,0 +1,69 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors of RFC 6238, appendix B
+func TestGenerate_RFC6238(t *testing.T) {
+	seeds := map[Algorithm][]byte{
+		SHA1:   []byte("12345678901234567890"),
+		SHA256: []byte("12345678901234567890123456789012"),
+		SHA512: []byte("1234567890123456789012345678901234567890123456789012345678901234"),
+	}
+
+	tests := []struct {
+		unix  int64
+		codes map[Algorithm]string
+	}{
+		{59, map[Algorithm]string{SHA1: "94287082", SHA256: "46119246", SHA512: "90693936"}},
+		{1111111109, map[Algorithm]string{SHA1: "07081804", SHA256: "68084774", SHA512: "25091201"}},
+		{1111111111, map[Algorithm]string{SHA1: "14050471", SHA256: "67062674", SHA512: "99943326"}},
+		{1234567890, map[Algorithm]string{SHA1: "89005924", SHA256: "91819424", SHA512: "93441116"}},
+		{2000000000, map[Algorithm]string{SHA1: "69279037", SHA256: "90698825", SHA512: "38618901"}},
+		{20000000000, map[Algorithm]string{SHA1: "65353130", SHA256: "77737706", SHA512: "47863826"}},
+	}
+
+	for _, tt := range tests {
+		for algo, want := range tt.codes {
+			code, err := Generate(seeds[algo], time.Unix(tt.unix, 0), Params{Algorithm: algo, Digits: 8})
+			require.NoError(t, err)
+			assert.Equal(t, want, code, "%s at %d", algo, tt.unix)
+		}
+	}
+}
+
+func TestGenerate_Defaults(t *testing.T) {
+	code, err := Generate([]byte("12345678901234567890"), time.Unix(59, 0), Params{})
+	require.NoError(t, err)
+	assert.Equal(t, "287082", code)
+
+	_, err = Generate([]byte("x"), time.Now(), Params{Digits: 7})
+	assert.ErrorIs(t, err, ErrBadDigits)
+
+	_, err = Generate([]byte("x"), time.Now(), Params{Algorithm: "MD5"})
+	assert.ErrorIs(t, err, ErrBadAlgorithm)
+}
+
+func TestRemaining(t *testing.T) {
+	assert.Equal(t, 30*time.Second, Remaining(time.Unix(60, 0), Params{}))
+	assert.Equal(t, time.Second, Remaining(time.Unix(89, 0), Params{}))
+	assert.Equal(t, 50*time.Second, Remaining(time.Unix(70, 0), Params{Period: 60}))
+}
+
+func TestDecodeSecret(t *testing.T) {
+	key, err := DecodeSecret("jbsw y3dp ehpk 3pxp")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello!\xde\xad\xbe\xef", string(key))
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", EncodeSecret(key))
+
+	_, err = DecodeSecret("not base32!")
+	assert.ErrorIs(t, err, ErrBadSecret)
+
+	_, err = DecodeSecret("")
+	assert.ErrorIs(t, err, ErrBadSecret)
+}
@@ -0,0 +1,99 @@
+package totp
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrBadURI = errors.New("not an otpauth://totp/ URI")
+)
+
+// Key of otpauth:// URI, e.g. otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example
+type Key struct {
+	Secret  string // base32
+	Issuer  string
+	Account string
+	Params
+}
+
+// Parse otpauth://totp/ URI
+func ParseURI(raw string) (Key, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Scheme != "otpauth" {
+		return Key{}, ErrBadURI
+	}
+	if !strings.EqualFold(u.Host, "totp") {
+		return Key{}, fmt.Errorf("%w: %s codes are not supported", ErrBadURI, u.Host)
+	}
+
+	q := u.Query()
+	key := Key{
+		Secret: q.Get("secret"),
+		Issuer: q.Get("issuer"),
+		Params: Params{Algorithm: Algorithm(strings.ToUpper(q.Get("algorithm")))},
+	}
+
+	// Label is "issuer:account" or just "account"
+	label := strings.TrimPrefix(u.Path, "/")
+	if issuer, account, ok := strings.Cut(label, ":"); ok {
+		key.Account = strings.TrimSpace(account)
+		if key.Issuer == "" {
+			key.Issuer = issuer
+		}
+	} else {
+		key.Account = label
+	}
+
+	if digits := q.Get("digits"); digits != "" {
+		if key.Digits, err = strconv.Atoi(digits); err != nil {
+			return Key{}, ErrBadDigits
+		}
+	}
+	if period := q.Get("period"); period != "" {
+		if key.Period, err = strconv.Atoi(period); err != nil || key.Period <= 0 {
+			return Key{}, ErrBadPeriod
+		}
+	}
+
+	if err := key.Validate(); err != nil {
+		return Key{}, err
+	}
+
+	return key, nil
+}
+
+// Check secret and params
+func (k Key) Validate() error {
+	if _, err := DecodeSecret(k.Secret); err != nil {
+		return err
+	}
+
+	return k.Params.Validate()
+}
+
+// otpauth:// URI of key, accepted by authenticator apps
+func (k Key) URI() string {
+	p := k.withDefaults()
+
+	q := url.Values{}
+	q.Set("secret", k.Secret)
+	if k.Issuer != "" {
+		q.Set("issuer", k.Issuer)
+	}
+	q.Set("algorithm", string(p.Algorithm))
+	q.Set("digits", strconv.Itoa(p.Digits))
+	q.Set("period", strconv.Itoa(p.Period))
+
+	label := k.Account
+	if k.Issuer != "" {
+		label = k.Issuer + ":" + k.Account
+	}
+
+	u := url.URL{Scheme: "otpauth", Host: "totp", Path: "/" + label, RawQuery: q.Encode()}
+
+	return u.String()
+}
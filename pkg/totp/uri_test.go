@@ -0,0 +1,42 @@
+package totp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURI(t *testing.T) {
+	key, err := ParseURI("otpauth://totp/ACME%20Co:john.doe@email.com?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&algorithm=SHA256&digits=8&period=60")
+	require.NoError(t, err)
+	assert.Equal(t, Key{
+		Secret:  "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ",
+		Issuer:  "ACME Co",
+		Account: "john.doe@email.com",
+		Params:  Params{Algorithm: SHA256, Digits: 8, Period: 60},
+	}, key)
+
+	// Round trip
+	again, err := ParseURI(key.URI())
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+
+	// Issuer from label, defaults for missing params
+	key, err = ParseURI("otpauth://totp/Example:alice?secret=JBSWY3DPEHPK3PXP")
+	require.NoError(t, err)
+	assert.Equal(t, Key{Secret: "JBSWY3DPEHPK3PXP", Issuer: "Example", Account: "alice"}, key)
+	assert.Equal(t, "otpauth://totp/Example:alice?algorithm=SHA1&digits=6&issuer=Example&period=30&secret=JBSWY3DPEHPK3PXP", key.URI())
+
+	for uri, wantErr := range map[string]error{
+		"https://example.com":                                    ErrBadURI,
+		"otpauth://hotp/x?secret=JBSWY3DPEHPK3PXP":               ErrBadURI,
+		"otpauth://totp/x?secret=":                               ErrBadSecret,
+		"otpauth://totp/x?secret=JBSWY3DPEHPK3PXP&digits=7":      ErrBadDigits,
+		"otpauth://totp/x?secret=JBSWY3DPEHPK3PXP&period=0":      ErrBadPeriod,
+		"otpauth://totp/x?secret=JBSWY3DPEHPK3PXP&algorithm=MD5": ErrBadAlgorithm,
+	} {
+		_, err := ParseURI(uri)
+		assert.ErrorIs(t, err, wantErr, uri)
+	}
+}